@@ -17,32 +17,48 @@ package main
 import (
 	"fmt"
 	"net"
+	"os"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver"
+	"github.com/vmware-tanzu/antrea/pkg/agent/cniconflict"
 	"github.com/vmware-tanzu/antrea/pkg/agent/cniserver"
 	_ "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/ipam"
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/bandwidth"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/egress"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/multicast"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/networkpolicy"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/noderoute"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/traceflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/drain"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/connections"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/exporter"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/flowrecords"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowlimit"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipsec"
+	"github.com/vmware-tanzu/antrea/pkg/agent/memorypressure"
 	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
 	npl "github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy"
 	"github.com/vmware-tanzu/antrea/pkg/agent/querier"
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
+	"github.com/vmware-tanzu/antrea/pkg/agent/spoofguard"
 	"github.com/vmware-tanzu/antrea/pkg/agent/stats"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/debugapi"
 	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
 	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/k8s"
@@ -52,6 +68,8 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	"github.com/vmware-tanzu/antrea/pkg/signals"
 	"github.com/vmware-tanzu/antrea/pkg/util/cipher"
+	"github.com/vmware-tanzu/antrea/pkg/util/watchrecord"
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 	"github.com/vmware-tanzu/antrea/pkg/version"
 	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 )
@@ -61,6 +79,9 @@ import (
 // https://github.com/kubernetes/kubernetes/blob/release-1.17/pkg/controller/apis/config/v1alpha1/defaults.go#L120
 const informerDefaultResync = 12 * time.Hour
 
+// dropMetricsInterval is the interval at which the dropped packet metrics are updated.
+const dropMetricsInterval = 1 * time.Minute
+
 // run starts Antrea agent with the given options and waits for termination signal.
 func run(o *Options) error {
 	klog.Infof("Starting Antrea agent (version %s)", version.GetFullVersion())
@@ -95,7 +116,10 @@ func run(o *Options) error {
 	ovsBridgeMgmtAddr := ofconfig.GetMgmtAddress(o.config.OVSRunDir, o.config.OVSBridge)
 	ofClient := openflow.NewClient(o.config.OVSBridge, ovsBridgeMgmtAddr,
 		features.DefaultFeatureGate.Enabled(features.AntreaProxy),
-		features.DefaultFeatureGate.Enabled(features.AntreaPolicy))
+		features.DefaultFeatureGate.Enabled(features.AntreaPolicy),
+		features.DefaultFeatureGate.Enabled(features.Egress),
+		o.config.Multicast.Enable,
+		o.config.EnableHardwareOffload)
 
 	_, serviceCIDRNet, _ := net.ParseCIDR(o.config.ServiceCIDR)
 	var serviceCIDRNetv6 *net.IPNet
@@ -105,12 +129,25 @@ func run(o *Options) error {
 	}
 
 	_, encapMode := config.GetTrafficEncapModeFromStr(o.config.TrafficEncapMode)
+	_, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	var fallbackTunnelTypes []ovsconfig.TunnelType
+	for _, tunnelType := range o.config.FallbackTunnelTypes {
+		fallbackTunnelTypes = append(fallbackTunnelTypes, ovsconfig.TunnelType(tunnelType))
+	}
 	networkConfig := &config.NetworkConfig{
-		TunnelType:        ovsconfig.TunnelType(o.config.TunnelType),
-		TrafficEncapMode:  encapMode,
-		EnableIPSecTunnel: o.config.EnableIPSecTunnel}
-
-	routeClient, err := route.NewClient(serviceCIDRNet, networkConfig, o.config.NoSNAT)
+		TunnelType:            ovsconfig.TunnelType(o.config.TunnelType),
+		FallbackTunnelTypes:   fallbackTunnelTypes,
+		TrafficEncapMode:      encapMode,
+		TrafficEncryptionMode: encryptionMode}
+
+	// eventBroadcaster is used to record Events for the local Node, e.g. when a route installed by Antrea is
+	// overridden by another agent, and for the local Pods, e.g. when they fail the spoof guard checks.
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: k8sClient.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "antrea-agent"})
+
+	routeClient, err := route.NewClient(serviceCIDRNet, networkConfig, o.config.NoSNAT, eventRecorder)
 	if err != nil {
 		return fmt.Errorf("error creating route client: %v", err)
 	}
@@ -135,13 +172,44 @@ func run(o *Options) error {
 		serviceCIDRNetv6,
 		networkConfig,
 		networkReadyCh,
-		features.DefaultFeatureGate.Enabled(features.AntreaProxy))
+		features.DefaultFeatureGate.Enabled(features.AntreaProxy),
+		o.config.EnableHardwareOffload,
+		features.DefaultFeatureGate.Enabled(features.PodQoS))
 	err = agentInitializer.Initialize()
 	if err != nil {
 		return fmt.Errorf("error initializing agent: %v", err)
 	}
 	nodeConfig := agentInitializer.GetNodeConfig()
 
+	// pskManager watches the IPsec PSK and coordinates its rotations with the other Nodes.
+	var pskManager *ipsec.PSKManager
+	if networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
+		pskManager = ipsec.NewPSKManager(
+			k8sClient,
+			informerFactory.Core().V1().Nodes(),
+			ifaceStore,
+			nodeConfig.Name,
+			ipsec.DefaultPSKFile,
+			networkConfig.IPSecPSK)
+	}
+
+	// wireGuardClient configures the WireGuard interface which encrypts the Pod traffic to the other Nodes.
+	var wireGuardClient wireguard.Interface
+	if networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		nodeConfig.WireGuardConfig = &config.WireGuardConfig{
+			Name: wireguard.DefaultLinkName,
+			Port: o.config.WireGuardPort,
+			MTU:  nodeConfig.NodeMTU,
+		}
+		wireGuardClient, err = wireguard.NewClient(k8sClient, nodeConfig.Name, nodeConfig.WireGuardConfig)
+		if err != nil {
+			return fmt.Errorf("error creating WireGuard client: %v", err)
+		}
+		if err := wireGuardClient.Init(); err != nil {
+			return fmt.Errorf("error initializing WireGuard: %v", err)
+		}
+	}
+
 	nodeRouteController := noderoute.NewNodeRouteController(
 		k8sClient,
 		informerFactory,
@@ -150,7 +218,9 @@ func run(o *Options) error {
 		routeClient,
 		ifaceStore,
 		networkConfig,
-		nodeConfig)
+		nodeConfig,
+		pskManager,
+		wireGuardClient)
 
 	// entityUpdates is a channel for receiving entity updates from CNIServer and
 	// notifying NetworkPolicyController to reconcile rules related to the
@@ -179,25 +249,62 @@ func run(o *Options) error {
 	if err != nil {
 		return fmt.Errorf("error creating new NetworkPolicy controller: %v", err)
 	}
+	if o.networkPolicyRecordFile != "" {
+		f, err := os.Create(o.networkPolicyRecordFile)
+		if err != nil {
+			return fmt.Errorf("error creating NetworkPolicy record file: %v", err)
+		}
+		defer f.Close()
+		klog.Infof("Recording NetworkPolicy watch streams to %s", o.networkPolicyRecordFile)
+		networkPolicyController.RecordWatchStreams(watchrecord.NewRecorder(f))
+	} else if o.networkPolicyReplayFile != "" {
+		f, err := os.Open(o.networkPolicyReplayFile)
+		if err != nil {
+			return fmt.Errorf("error opening NetworkPolicy replay file: %v", err)
+		}
+		replayer, err := watchrecord.NewReplayer(f, o.networkPolicyReplaySpeed)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("error loading NetworkPolicy replay file: %v", err)
+		}
+		klog.Infof("Replaying NetworkPolicy watch streams from %s", o.networkPolicyReplayFile)
+		networkPolicyController.ReplayWatchStreams(replayer)
+	}
+
+	// memoryMonitor detects when the agent gets close to its memory limit, in which case non-essential features are
+	// paused until the memory usage decreases, to avoid the agent being OOM-killed.
+	memoryMonitor := memorypressure.NewMonitor()
 
 	// statsCollector collects stats and reports to the antrea-controller periodically. For now it's only used for
 	// NetworkPolicy stats.
 	var statsCollector *stats.Collector
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
 		statsCollector = stats.NewCollector(antreaClientProvider, ofClient, networkPolicyController)
+		memoryMonitor.AddEventHandler(statsCollector.SetPaused)
+	}
+
+	// webhookNotifier is nil if no webhook is configured, in which case the drop rates are not monitored.
+	webhookNotifier, err := webhook.NewNotifier(o.config.Webhook.Sinks)
+	if err != nil {
+		return fmt.Errorf("error creating webhook notifier: %v", err)
+	}
+	var dropRateMonitor *stats.DropRateMonitor
+	if webhookNotifier != nil {
+		dropRateMonitor = stats.NewDropRateMonitor(nodeConfig.Name, webhookNotifier, o.config.Webhook.PolicyDropRateThreshold)
 	}
 
 	var proxier k8sproxy.Provider
 	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 		v4Enabled := config.IsIPv4Enabled(nodeConfig, networkConfig.TrafficEncapMode)
 		v6Enabled := config.IsIPv6Enabled(nodeConfig, networkConfig.TrafficEncapMode)
+		proxyNodePort := features.DefaultFeatureGate.Enabled(features.AntreaProxyNodePort)
 		switch {
 		case v4Enabled && v6Enabled:
-			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient)
+			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, proxyNodePort)
 		case v4Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, false)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, false, proxyNodePort)
 		case v6Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, true)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, true, proxyNodePort)
 		default:
 			return fmt.Errorf("at least one of IPv4 or IPv6 should be enabled")
 		}
@@ -236,6 +343,78 @@ func run(o *Options) error {
 			serviceCIDRNet)
 	}
 
+	// The packets failing the spoof guard checks are only sent to the agent when they need to be reported,
+	// otherwise they are dropped in the OVS pipeline and only counted by the dropped packet metric.
+	if o.config.SpoofGuardMode == spoofguard.ModeLogOnly || o.config.EnableSpoofGuardEvents {
+		var spoofGuardRecorder record.EventRecorder
+		if o.config.EnableSpoofGuardEvents {
+			spoofGuardRecorder = eventRecorder
+		}
+		logOnly := o.config.SpoofGuardMode == spoofguard.ModeLogOnly
+		spoofGuardReporter := spoofguard.NewReporter(ifaceStore, spoofGuardRecorder, logOnly)
+		ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonSG), "spoofguard", spoofGuardReporter)
+		if err := ofClient.InstallSpoofGuardViolationFlows(logOnly); err != nil {
+			return fmt.Errorf("error installing spoof guard violation flows: %v", err)
+		}
+	}
+
+	var multicastController *multicast.Controller
+	if o.config.Multicast.Enable {
+		multicastController = multicast.NewMulticastController(ofClient, ifaceStore)
+		if err := ofClient.InstallMulticastInitialFlows(); err != nil {
+			return fmt.Errorf("error installing multicast flows: %v", err)
+		}
+	}
+
+	var egressController *egress.Controller
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
+		egressController = egress.NewEgressController(
+			k8sClient,
+			informerFactory,
+			crdInformerFactory.Core().V1alpha2().Egresses(),
+			ofClient,
+			routeClient,
+			ifaceStore,
+			nodeConfig.Name)
+	}
+
+	var bandwidthController *bandwidth.Controller
+	if features.DefaultFeatureGate.Enabled(features.PodBandwidth) {
+		bandwidthController = bandwidth.NewBandwidthController(
+			k8sClient,
+			ofClient,
+			ifaceStore,
+			nodeConfig.Name)
+	}
+
+	var quotaController *bandwidth.QuotaController
+	if features.DefaultFeatureGate.Enabled(features.BandwidthQuota) {
+		quotaController = bandwidth.NewQuotaController(
+			k8sClient,
+			informerFactory,
+			crdInformerFactory.Core().V1alpha2().BandwidthQuotas(),
+			ofClient,
+			ifaceStore,
+			eventRecorder,
+			nodeConfig.Name)
+	}
+
+	var qosController *bandwidth.QoSController
+	if features.DefaultFeatureGate.Enabled(features.PodQoS) {
+		qosController = bandwidth.NewQoSController(
+			k8sClient,
+			ofClient,
+			ovsBridgeClient,
+			ifaceStore,
+			o.podQoSMaxRate,
+			o.podQoSClasses,
+			nodeConfig.Name)
+	} else if err := bandwidth.DeleteEgressPortsQoS(ovsBridgeClient, ifaceStore); err != nil {
+		// The queues left by a previous run with the PodQoS feature enabled are no longer used, but still limit the
+		// rate of the egress ports.
+		klog.Errorf("Failed to delete the QoS of the egress ports: %v", err)
+	}
+
 	// TODO: we should call this after installing flows for initial node routes
 	//  and initial NetworkPolicies so that no packets will be mishandled.
 	if err := agentInitializer.FlowRestoreComplete(); err != nil {
@@ -276,16 +455,83 @@ func run(o *Options) error {
 
 	go nodeRouteController.Run(stopCh)
 
+	if networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
+		go pskManager.Run(stopCh)
+	}
+
 	go networkPolicyController.Run(stopCh)
 
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
 		go statsCollector.Run(stopCh)
 	}
 
+	if webhookNotifier != nil {
+		go webhookNotifier.Run(stopCh)
+	}
+
+	if o.config.EnablePrometheusMetrics || dropRateMonitor != nil {
+		// The packet counters of the drop flows are read from OVS periodically.
+		go wait.Until(func() {
+			counts := ofClient.DroppedPacketCounts()
+			if o.config.EnablePrometheusMetrics {
+				for reason, count := range counts {
+					metrics.OVSDroppedPackets.WithLabelValues(reason).Set(float64(count))
+				}
+			}
+			if dropRateMonitor != nil {
+				dropRateMonitor.Check(counts, time.Now())
+			}
+		}, dropMetricsInterval, stopCh)
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
 		go traceflowController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
+		go egressController.Run(stopCh)
+	}
+
+	if o.config.Multicast.Enable {
+		go multicastController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.PodBandwidth) {
+		go bandwidthController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.BandwidthQuota) {
+		go quotaController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.PodQoS) {
+		go qosController.Run(stopCh)
+	}
+
+	// cniConflictScanner detects the resources left on the Node by other CNIs or by previous Antrea installations, which
+	// are only removed when requested through the agent API. It is not used when Antrea is chained with another CNI,
+	// whose resources are expected on the Node.
+	var cniConflictScanner *cniconflict.Scanner
+	if !isChaining {
+		cniConflictScanner = cniconflict.NewScanner(cniconflict.DefaultCNIConfDir, o.config.OVSBridge)
+		if conflicts, err := cniConflictScanner.Scan(); err != nil {
+			klog.Errorf("Failed to scan the Node for resources of other CNIs: %v", err)
+		} else {
+			for _, c := range conflicts {
+				klog.Warningf("Found %s %s of %s which may conflict with Antrea, it can be removed with \"antctl cniconflicts cleanup\"", c.Type, c.Name, c.Owner)
+			}
+		}
+	}
+
+	// connTrackDumper dumps the connections of the Antrea conntrack zones, for the flow exporter and for the
+	// connection dumps of the agent API. The conntrack accounting it needs for the counters of the connections is
+	// only enabled with the flow exporter.
+	connTrackDumper := connections.InitializeConnTrackDumper(nodeConfig, serviceCIDRNet, serviceCIDRNetv6, ovsDatapathType, features.DefaultFeatureGate.Enabled(features.AntreaProxy))
+
+	// drainController puts the datapath into drain mode before a maintenance of the Node, when requested through the
+	// agent API, and uses connTrackDumper to detect when the established Service connections are closed.
+	drainController := drain.NewController(routeClient, connTrackDumper, nodeConfig)
+
 	agentQuerier := querier.NewAgentQuerier(
 		nodeConfig,
 		networkConfig,
@@ -294,6 +540,12 @@ func run(o *Options) error {
 		ofClient,
 		ovsBridgeClient,
 		networkPolicyController,
+		pskManager,
+		wireGuardClient,
+		memoryMonitor,
+		drainController,
+		cniConflictScanner,
+		connTrackDumper,
 		o.config.APIPort)
 
 	agentMonitor := monitor.NewAgentMonitor(crdClient, agentQuerier)
@@ -315,7 +567,14 @@ func run(o *Options) error {
 		o.config.EnablePrometheusMetrics,
 		o.config.ClientConnection.Kubeconfig,
 		cipherSuites,
-		cipher.TLSVersionMap[o.config.TLSMinVersion])
+		cipher.TLSVersionMap[o.config.TLSMinVersion],
+		debugapi.Config{
+			QPS:          o.config.DebugAPI.QPS,
+			Burst:        o.config.DebugAPI.Burst,
+			PerUserQPS:   o.config.DebugAPI.PerUserQPS,
+			PerUserBurst: o.config.DebugAPI.PerUserBurst,
+			AuditLogFile: o.config.DebugAPI.AuditLogFile,
+		})
 	if err != nil {
 		return fmt.Errorf("error when creating agent API server: %v", err)
 	}
@@ -329,34 +588,68 @@ func run(o *Options) error {
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonNP))
 	}
+	if o.config.SpoofGuardMode == spoofguard.ModeLogOnly || o.config.EnableSpoofGuardEvents {
+		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonSG))
+	}
+	if o.config.Multicast.Enable {
+		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonMC))
+	}
 	if len(packetInReasons) > 0 {
 		go ofClient.StartPacketInHandler(packetInReasons, stopCh)
 	}
 
 	// Initialize flow exporter to start go routines to poll conntrack flows and export IPFIX flow records
 	if features.DefaultFeatureGate.Enabled(features.FlowExporter) {
+		if ovsDatapathType == ovsconfig.OVSDatapathSystem {
+			if err := connections.SetupConntrackParameters(); err != nil {
+				// Do not fail, but continue after logging an error as we can still dump flows with missing information.
+				klog.Errorf("Error when setting up conntrack parameters, some information may be missing from exported flows: %v", err)
+			}
+		}
 		v4Enabled := config.IsIPv4Enabled(nodeConfig, networkConfig.TrafficEncapMode)
 		v6Enabled := config.IsIPv6Enabled(nodeConfig, networkConfig.TrafficEncapMode)
 
 		connStore := connections.NewConnectionStore(
-			connections.InitializeConnTrackDumper(nodeConfig, serviceCIDRNet, serviceCIDRNetv6, ovsDatapathType, features.DefaultFeatureGate.Enabled(features.AntreaProxy)),
+			connTrackDumper,
 			ifaceStore,
 			v4Enabled,
 			v6Enabled,
 			proxier,
 			networkPolicyController,
 			o.pollInterval)
+		memoryMonitor.AddEventHandler(connStore.SetPaused)
 		pollDone := make(chan struct{})
 		go connStore.Run(stopCh, pollDone)
 
-		flowExporter := exporter.NewFlowExporter(
+		flowExporter, err := exporter.NewFlowExporter(
 			flowrecords.NewFlowRecords(connStore),
 			o.config.FlowExportFrequency,
 			v4Enabled,
-			v6Enabled)
+			v6Enabled,
+			exporter.DefaultRecordBufferPath,
+			o.config.FlowRecordBufferSize)
+		if err != nil {
+			return fmt.Errorf("error when creating flow exporter: %v", err)
+		}
 		go wait.Until(func() { flowExporter.Export(o.flowCollectorAddr, o.flowCollectorProto, stopCh, pollDone) }, 0, stopCh)
 	}
 
+	if o.config.MaxFlowsPerNode > 0 {
+		// flowLimitMonitor warns when the number of OVS flows gets close to the configured maximum, and optionally
+		// prevents new Pods from being scheduled on the Node before flows start failing to be installed.
+		flowLimitMonitor := flowlimit.NewMonitor(k8sClient, nodeConfig.Name, o.config.MaxFlowsPerNode, o.config.CordonOnFlowLimit, func() int {
+			count := 0
+			for _, table := range ofClient.GetFlowTableStatus() {
+				count += int(table.FlowCount)
+			}
+			return count
+		})
+		go flowLimitMonitor.Run(stopCh)
+	}
+
+	// The event handlers of memoryMonitor must be registered before it is started.
+	go memoryMonitor.Run(stopCh)
+
 	<-stopCh
 	klog.Info("Stopping Antrea agent")
 	return nil
@@ -16,6 +16,8 @@ package main
 
 import (
 	componentbaseconfig "k8s.io/component-base/config"
+
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 )
 
 type AgentConfig struct {
@@ -39,6 +41,12 @@ type AgentConfig struct {
 	// 'system' is the default value and corresponds to the kernel datapath. Use 'netdev' to run
 	// OVS in userspace mode. Userspace mode requires the tun device driver to be available.
 	OVSDatapathType string `yaml:"ovsDatapathType,omitempty"`
+	// Whether or not to enable OVS hardware offload, to offload the datapath flows to a SmartNIC with TC. The
+	// agent sets "other_config:hw-offload" in OVSDB, which only takes effect when ovs-vswitchd is (re)started,
+	// and the pipeline avoids the actions which cannot be offloaded, i.e. the OpenFlow meters of the Pod
+	// bandwidth limits and BandwidthQuotas, and the QoS queues of the PodQoS feature. It requires the 'system'
+	// datapath type and is not supported on Windows. Defaults to false.
+	EnableHardwareOffload bool `yaml:"enableHardwareOffload,omitempty"`
 	// Runtime data directory used by Open vSwitch.
 	// Default value:
 	// - On Linux platform: /var/run/openvswitch
@@ -70,6 +78,14 @@ type AgentConfig struct {
 	// - gre
 	// - stt
 	TunnelType string `yaml:"tunnelType,omitempty"`
+	// Alternative tunnel protocols which can be used for encapsulating traffic to a peer Node
+	// when the default tunnelType is blocked by the underlay network (e.g. Geneve and VXLAN UDP
+	// ports are firewalled). The list is ordered by preference and is advertised to other
+	// Nodes through a Node annotation; each pair of Nodes uses the first protocol supported by
+	// both Nodes, and uses tunnelType if there is none. Supported values are the same as for
+	// tunnelType, e.g. "gre" can be used as an IP-level (no UDP) encapsulation. This option
+	// cannot be used together with enableIPSecTunnel.
+	FallbackTunnelTypes []string `yaml:"fallbackTunnelTypes,omitempty"`
 	// Default MTU to use for the host gateway interface and the network interface of each
 	// Pod. If omitted, antrea-agent will default this value to 1450 to accommodate for tunnel
 	// encapsulate overhead.
@@ -94,13 +110,57 @@ type AgentConfig struct {
 	// authentication. When IPSec tunnel is enabled, the PSK value must be passed to Antrea Agent
 	// through an environment variable: ANTREA_IPSEC_PSK.
 	// Defaults to false.
+	// Deprecated: use trafficEncryptionMode "ipsec" instead.
 	EnableIPSecTunnel bool `yaml:"enableIPSecTunnel,omitempty"`
+	// Determines how Pod traffic across Nodes is encrypted. It has the following options:
+	// none(default): Pod traffic across Nodes is not encrypted.
+	// ipsec:         IPSec (ESP) encryption, see enableIPSecTunnel for its requirements.
+	// wireGuard:     WireGuard encryption. Pod traffic across Nodes is routed through a WireGuard
+	//                interface instead of being tunneled by OVS. The public key of each Node is
+	//                generated by antrea-agent and advertised through a Node annotation. The
+	//                wg command must be installed in the antrea-agent container.
+	// Encryption is supported only for the encap trafficEncapMode.
+	TrafficEncryptionMode string `yaml:"trafficEncryptionMode,omitempty"`
+	// The UDP port used by WireGuard, which must be the same on all Nodes. It is only used when
+	// trafficEncryptionMode is wireGuard.
+	// Defaults to 51820.
+	WireGuardPort int `yaml:"wireGuardPort,omitempty"`
+	// Determines what happens to the packets sent by a Pod with a source MAC or IP address which
+	// does not belong to the Pod. It has the following options:
+	// drop(default): The packets are dropped.
+	// logOnly:       The packets are forwarded as if they were sent with the addresses of the Pod,
+	//                and the violations are logged and counted. This mode is meant to help
+	//                migrating existing workloads, and should not be kept once they are fixed.
+	SpoofGuardMode string `yaml:"spoofGuardMode,omitempty"`
+	// Whether or not to record a Kubernetes Event for the Pods which send packets failing the
+	// spoof guard checks. At most one Event is recorded per Pod per minute.
+	// Defaults to false.
+	EnableSpoofGuardEvents bool `yaml:"enableSpoofGuardEvents,omitempty"`
 	// APIPort is the port for the antrea-agent APIServer to serve on.
 	// Defaults to 10350.
 	APIPort int `yaml:"apiPort,omitempty"`
 	// Enable metrics exposure via Prometheus. Initializes Prometheus metrics listener
 	// Defaults to true.
 	EnablePrometheusMetrics bool `yaml:"enablePrometheusMetrics,omitempty"`
+	// The maximum number of OVS flows on the Node. When the number of flows exceeds 90% of this value, a warning
+	// is logged and the antrea_agent_ovs_flow_limit_exceeded metric is set to 1, until the number of flows goes
+	// back below 80% of it. 0 (default) disables the limit.
+	MaxFlowsPerNode int `yaml:"maxFlowsPerNode,omitempty"`
+	// Whether or not to taint the Node with "node.antrea.io/flow-limit-exceeded:NoSchedule" while the number of
+	// flows exceeds 90% of maxFlowsPerNode, so that no new Pods are scheduled on the Node. Requires maxFlowsPerNode
+	// to be set. Defaults to false.
+	CordonOnFlowLimit bool `yaml:"cordonOnFlowLimit,omitempty"`
+	// The QoS classes of the Pods, used when the PodQoS feature gate is enabled. The egress traffic of the Pods of
+	// each class leaving the OVS bridge through the uplink port is sent to a dedicated OVS QoS queue of the port,
+	// which guarantees the minimum rate of the class under congestion. The queues are not set to the host gateway
+	// and tunnel ports, whose linux-htb QoS does not shape the traffic. A Pod
+	// belongs to the class set with its "qos.antrea.io/class" annotation, or else to the class of its
+	// PriorityClass, if any.
+	PodQoSClasses []PodQoSClass `yaml:"podQoSClasses,omitempty"`
+	// The maximum rate shared by all the QoS queues of the uplink port, in bits per second, e.g. "10G". It should
+	// be set to the bandwidth of the uplink of the Node.
+	// Defaults to "10G".
+	PodQoSMaxRate string `yaml:"podQoSMaxRate,omitempty"`
 	// Provide the IPFIX collector address as a string with format <HOST>:[<PORT>][:<PROTO>].
 	// HOST can either be the DNS name or the IP of the Flow Collector. For example,
 	// "flow-aggregator.flow-aggregator.svc" can be provided as DNS name to connect
@@ -120,6 +180,12 @@ type AgentConfig struct {
 	// Flow export frequency should be greater than or equal to 1.
 	// Defaults to "12".
 	FlowExportFrequency uint `yaml:"flowExportFrequency,omitempty"`
+	// Provide the maximum number of flow records of terminated connections which are buffered on disk while the
+	// flow collector cannot be reached. The buffered records are exported once the flow collector can be reached
+	// again, and records are dropped once the buffer is full. A negative value disables buffering, in which case
+	// the records are kept in memory until they can be exported.
+	// Defaults to 10000.
+	FlowRecordBufferSize int `yaml:"flowRecordBufferSize,omitempty"`
 	// Provide the port range used by NodePortLocal. When the NodePortLocal feature is enabled, a port from that range will be assigned
 	// whenever a Pod's container defines a specific port to be exposed (each container can define a list of ports as pod.spec.containers[].ports),
 	// and all Node traffic directed to that port will be forwarded to the Pod.
@@ -131,4 +197,68 @@ type AgentConfig struct {
 	TLSCipherSuites string `yaml:"tlsCipherSuites,omitempty"`
 	// TLS min version.
 	TLSMinVersion string `yaml:"tlsMinVersion,omitempty"`
+	// DebugAPI configures the rate limiting and the audit log of the debug APIs.
+	DebugAPI DebugAPIConfig `yaml:"debugAPI,omitempty"`
+	// Multicast configures the forwarding of the IPv4 multicast traffic of the Pods.
+	Multicast MulticastConfig `yaml:"multicast,omitempty"`
+	// Webhook configures the HTTP endpoints notified of the events of antrea-agent.
+	Webhook WebhookConfig `yaml:"webhook,omitempty"`
+}
+
+type PodQoSClass struct {
+	// Name of the class, which is the value of the "qos.antrea.io/class" annotation of its Pods.
+	Name string `yaml:"name"`
+	// Names of the PriorityClasses of the Pods of the class, for the Pods without the "qos.antrea.io/class"
+	// annotation.
+	PriorityClassNames []string `yaml:"priorityClassNames,omitempty"`
+	// Bandwidth guaranteed to the Pods of the class, in bits per second, e.g. "100M". Defaults to no guarantee.
+	MinRate string `yaml:"minRate,omitempty"`
+	// Maximum bandwidth of the Pods of the class, in bits per second. Defaults to podQoSMaxRate.
+	MaxRate string `yaml:"maxRate,omitempty"`
+	// DSCP value, between 1 and 63, set in the IP headers of the egress traffic of the Pods of the class, and
+	// copied to the outer IP header of the tunneled traffic, so that the underlay network can prioritize it. The
+	// values whose 2 least significant bits are set are reserved for Traceflow. Defaults to 0, which leaves the
+	// DSCP unchanged.
+	DSCP int `yaml:"dscp,omitempty"`
+}
+
+type DebugAPIConfig struct {
+	// Maximum number of requests per second to the debug APIs of antrea-agent, e.g. the APIs dumping
+	// OVS flows and the support bundles. A negative value disables the limit. Defaults to 10.
+	QPS float32 `yaml:"qps,omitempty"`
+	// Maximum burst of requests to the debug APIs. Defaults to 20.
+	Burst int `yaml:"burst,omitempty"`
+	// Maximum number of requests per second to the debug APIs from each user. A negative value
+	// disables the limit. Defaults to 2.
+	PerUserQPS float32 `yaml:"perUserQPS,omitempty"`
+	// Maximum burst of requests to the debug APIs from each user. Defaults to 10.
+	PerUserBurst int `yaml:"perUserBurst,omitempty"`
+	// Path of the file to which the requests to the debug APIs are recorded, with the user, the
+	// request URI and the response code. The requests are recorded in the log of antrea-agent if
+	// empty. Defaults to "".
+	AuditLogFile string `yaml:"auditLogFile,omitempty"`
+}
+
+type MulticastConfig struct {
+	// Enable forwarding the IPv4 multicast traffic sent by the Pods to the Pods which joined the multicast groups,
+	// on the local Node and on the other Nodes. The memberships of the Pods are learnt by snooping their IGMP
+	// messages. The traffic is tunnelled to the other Nodes in encap mode, and is sent to the underlay network in
+	// noEncap mode. Defaults to false.
+	Enable bool `yaml:"enable,omitempty"`
+}
+
+type WebhookConfig struct {
+	// Webhooks to which the events are sent with HTTP POST requests. antrea-agent sends a
+	// "PolicyDropRateExceeded" event when the rate of the packets dropped by the NetworkPolicies on
+	// the Node exceeds policyDropRateThreshold. Each webhook has the following fields:
+	//   url: the URL of the webhook.
+	//   events: the types of the events sent to the webhook. All the events are sent if empty.
+	//   template: the Go template of the JSON body of the requests, executed with the event, which has
+	//     the fields Type, Time, Source, Message and Details. The "json" function encodes a value as
+	//     JSON. The event is encoded as JSON if empty.
+	Sinks []webhook.SinkConfig `yaml:"sinks,omitempty"`
+	// Rate of the packets dropped by the NetworkPolicies on the Node, in packets per second, above
+	// which a "PolicyDropRateExceeded" event is sent. The rate is computed every minute for the
+	// ingress and the egress drops separately. Defaults to 100.
+	PolicyDropRateThreshold float64 `yaml:"policyDropRateThreshold,omitempty"`
 }
@@ -22,9 +22,14 @@ import (
 
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/bandwidth"
+	"github.com/vmware-tanzu/antrea/pkg/agent/spoofguard"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/debugapi"
 	"github.com/vmware-tanzu/antrea/pkg/cni"
 	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
@@ -42,9 +47,15 @@ const (
 	defaultFlowCollectorPort      = "4739"
 	defaultFlowPollInterval       = 5 * time.Second
 	defaultFlowExportFrequency    = 12
+	defaultFlowRecordBufferSize   = 10000
 	defaultNPLPortRange           = "40000-41000"
+	defaultPodQoSMaxRate          = "10G"
 )
 
+// defaultPolicyDropRateThreshold is the default rate of the packets dropped by the NetworkPolicies, in packets per
+// second, above which the webhooks are notified.
+const defaultPolicyDropRateThreshold = 100
+
 type Options struct {
 	// The path of configuration file.
 	configFile string
@@ -56,6 +67,15 @@ type Options struct {
 	flowCollectorProto string
 	// Flow exporter poll interval
 	pollInterval time.Duration
+	// The parsed QoS classes of the Pods and the maximum rate shared by their queues, in bits per second.
+	podQoSClasses []bandwidth.QoSClass
+	podQoSMaxRate uint64
+	// The path of the file to which the NetworkPolicy watch streams are recorded.
+	networkPolicyRecordFile string
+	// The path of the file from which the NetworkPolicy watch streams are replayed.
+	networkPolicyReplayFile string
+	// The factor by which the replay of the NetworkPolicy watch streams is accelerated.
+	networkPolicyReplaySpeed float64
 }
 
 func newOptions() *Options {
@@ -63,12 +83,16 @@ func newOptions() *Options {
 		config: &AgentConfig{
 			EnablePrometheusMetrics: true,
 		},
+		networkPolicyReplaySpeed: 1,
 	}
 }
 
 // addFlags adds flags to fs and binds them to options.
 func (o *Options) addFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.configFile, "config", o.configFile, "The path to the configuration file")
+	fs.StringVar(&o.networkPolicyRecordFile, "networkpolicy-record-file", o.networkPolicyRecordFile, "The path of the file to which the NetworkPolicy, AppliedToGroup and AddressGroup watch streams received from antrea-controller are recorded")
+	fs.StringVar(&o.networkPolicyReplayFile, "networkpolicy-replay-file", o.networkPolicyReplayFile, "The path of a file recorded with --networkpolicy-record-file, whose watch streams are replayed instead of watching antrea-controller")
+	fs.Float64Var(&o.networkPolicyReplaySpeed, "networkpolicy-replay-speed", o.networkPolicyReplaySpeed, "The factor by which the replay of the NetworkPolicy watch streams is accelerated, 0 means without delay")
 }
 
 // complete completes all the required options.
@@ -92,6 +116,13 @@ func (o *Options) validate(args []string) error {
 		return fmt.Errorf("no positional arguments are supported")
 	}
 
+	if o.networkPolicyRecordFile != "" && o.networkPolicyReplayFile != "" {
+		return fmt.Errorf("--networkpolicy-record-file and --networkpolicy-replay-file cannot be set at the same time")
+	}
+	if o.networkPolicyReplaySpeed < 0 {
+		return fmt.Errorf("--networkpolicy-replay-speed cannot be negative")
+	}
+
 	// Validate service CIDR configuration
 	_, _, err := net.ParseCIDR(o.config.ServiceCIDR)
 	if err != nil {
@@ -107,9 +138,25 @@ func (o *Options) validate(args []string) error {
 		o.config.TunnelType != ovsconfig.GRETunnel && o.config.TunnelType != ovsconfig.STTTunnel {
 		return fmt.Errorf("tunnel type %s is invalid", o.config.TunnelType)
 	}
-	if o.config.EnableIPSecTunnel && o.config.TunnelType != ovsconfig.GRETunnel {
+	ok, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	if !ok {
+		return fmt.Errorf("TrafficEncryptionMode %s is unknown", o.config.TrafficEncryptionMode)
+	}
+	if o.config.EnableIPSecTunnel && encryptionMode != config.TrafficEncryptionModeIPSec {
+		return fmt.Errorf("enableIPSecTunnel cannot be used together with TrafficEncryptionMode %s", encryptionMode)
+	}
+	if encryptionMode == config.TrafficEncryptionModeIPSec && o.config.TunnelType != ovsconfig.GRETunnel {
 		return fmt.Errorf("IPSec encyption is supported only for GRE tunnel")
 	}
+	if encryptionMode == config.TrafficEncryptionModeWireGuard && (o.config.WireGuardPort <= 0 || o.config.WireGuardPort > 65535) {
+		return fmt.Errorf("WireGuard port %d is invalid", o.config.WireGuardPort)
+	}
+	if err := o.validateFallbackTunnelTypes(); err != nil {
+		return err
+	}
+	if o.config.SpoofGuardMode != spoofguard.ModeDrop && o.config.SpoofGuardMode != spoofguard.ModeLogOnly {
+		return fmt.Errorf("spoof guard mode %s is invalid", o.config.SpoofGuardMode)
+	}
 	if o.config.OVSDatapathType != string(ovsconfig.OVSDatapathSystem) && o.config.OVSDatapathType != string(ovsconfig.OVSDatapathNetdev) {
 		return fmt.Errorf("OVS datapath type %s is not supported", o.config.OVSDatapathType)
 	}
@@ -117,6 +164,29 @@ func (o *Options) validate(args []string) error {
 	if !ok {
 		return fmt.Errorf("TrafficEncapMode %s is unknown", o.config.TrafficEncapMode)
 	}
+	if o.config.EnableHardwareOffload {
+		if o.config.OVSDatapathType != string(ovsconfig.OVSDatapathSystem) {
+			return fmt.Errorf("enableHardwareOffload requires the %s OVS datapath type", ovsconfig.OVSDatapathSystem)
+		}
+		if features.DefaultFeatureGate.Enabled(features.PodQoS) {
+			return fmt.Errorf("PodQoS is not supported with enableHardwareOffload, as the QoS queues cannot be offloaded")
+		}
+		// Both features are implemented with OpenFlow meters, which cannot be offloaded.
+		if features.DefaultFeatureGate.Enabled(features.PodBandwidth) {
+			return fmt.Errorf("PodBandwidth is not supported with enableHardwareOffload, as the OpenFlow meters cannot be offloaded")
+		}
+		if features.DefaultFeatureGate.Enabled(features.BandwidthQuota) {
+			return fmt.Errorf("BandwidthQuota is not supported with enableHardwareOffload, as the OpenFlow meters cannot be offloaded")
+		}
+	}
+	if o.config.OVSDatapathType == string(ovsconfig.OVSDatapathNetdev) {
+		if encryptionMode == config.TrafficEncryptionModeIPSec {
+			return fmt.Errorf("IPSec encryption is not supported with the %s OVS datapath type", ovsconfig.OVSDatapathNetdev)
+		}
+		if features.DefaultFeatureGate.Enabled(features.PodQoS) {
+			return fmt.Errorf("PodQoS is not supported with the %s OVS datapath type, as the Linux HTB QoS of the queues is not available in userspace", ovsconfig.OVSDatapathNetdev)
+		}
+	}
 
 	// Check if the enabled features are supported on the OS.
 	err = o.checkUnsupportedFeatures()
@@ -128,10 +198,37 @@ func (o *Options) validate(args []string) error {
 		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 			return fmt.Errorf("TrafficEncapMode %s requires AntreaProxy to be enabled", o.config.TrafficEncapMode)
 		}
-		if o.config.EnableIPSecTunnel {
-			return fmt.Errorf("IPsec tunnel may only be enabled in %s mode", config.TrafficEncapModeEncap)
+		if encryptionMode != config.TrafficEncryptionModeNone {
+			return fmt.Errorf("TrafficEncryptionMode %s may only be used in %s mode", encryptionMode, config.TrafficEncapModeEncap)
+		}
+	}
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
+		if encapMode != config.TrafficEncapModeEncap {
+			return fmt.Errorf("Egress is only supported in %s mode", config.TrafficEncapModeEncap)
+		}
+		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
+			return fmt.Errorf("Egress requires AntreaProxy to be enabled")
 		}
 	}
+	if features.DefaultFeatureGate.Enabled(features.AntreaProxyNodePort) {
+		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
+			return fmt.Errorf("AntreaProxyNodePort requires AntreaProxy to be enabled")
+		}
+		if encapMode.IsNetworkPolicyOnly() {
+			return fmt.Errorf("AntreaProxyNodePort is not supported in %s mode", config.TrafficEncapModeNetworkPolicyOnly)
+		}
+	}
+	if o.config.Multicast.Enable {
+		if encapMode.IsNetworkPolicyOnly() {
+			return fmt.Errorf("multicast is not supported in %s mode", config.TrafficEncapModeNetworkPolicyOnly)
+		}
+		if encryptionMode == config.TrafficEncryptionModeWireGuard {
+			return fmt.Errorf("multicast is not supported with TrafficEncryptionMode %s", encryptionMode)
+		}
+	}
+	if o.config.Webhook.PolicyDropRateThreshold < 0 {
+		return fmt.Errorf("webhook.policyDropRateThreshold must not be negative")
+	}
 	if o.config.NoSNAT && !(encapMode == config.TrafficEncapModeNoEncap || encapMode == config.TrafficEncapModeNetworkPolicyOnly) {
 		return fmt.Errorf("noSNAT is only applicable to the %s mode", config.TrafficEncapModeNoEncap)
 	}
@@ -140,9 +237,18 @@ func (o *Options) validate(args []string) error {
 		// (but SNAT can be done by the primary CNI).
 		o.config.NoSNAT = true
 	}
+	if o.config.MaxFlowsPerNode < 0 {
+		return fmt.Errorf("maxFlowsPerNode %d is invalid", o.config.MaxFlowsPerNode)
+	}
+	if o.config.CordonOnFlowLimit && o.config.MaxFlowsPerNode == 0 {
+		return fmt.Errorf("cordonOnFlowLimit requires maxFlowsPerNode to be set")
+	}
 	if err := o.validateFlowExporterConfig(); err != nil {
 		return fmt.Errorf("failed to validate flow exporter config: %v", err)
 	}
+	if err := o.validatePodQoSConfig(); err != nil {
+		return fmt.Errorf("failed to validate Pod QoS config: %v", err)
+	}
 	return nil
 }
 
@@ -177,15 +283,43 @@ func (o *Options) setDefaults() {
 	if o.config.TunnelType == "" {
 		o.config.TunnelType = defaultTunnelType
 	}
+	if o.config.TrafficEncryptionMode == "" {
+		if o.config.EnableIPSecTunnel {
+			o.config.TrafficEncryptionMode = config.TrafficEncryptionModeIPSec.String()
+		} else {
+			o.config.TrafficEncryptionMode = config.TrafficEncryptionModeNone.String()
+		}
+	}
+	if o.config.WireGuardPort == 0 {
+		o.config.WireGuardPort = wireguard.DefaultPort
+	}
 	if o.config.HostProcPathPrefix == "" {
 		o.config.HostProcPathPrefix = defaultHostProcPathPrefix
 	}
 	if o.config.ServiceCIDR == "" {
 		o.config.ServiceCIDR = defaultServiceCIDR
 	}
+	if o.config.SpoofGuardMode == "" {
+		o.config.SpoofGuardMode = spoofguard.ModeDrop
+	}
 	if o.config.APIPort == 0 {
 		o.config.APIPort = apis.AntreaAgentAPIPort
 	}
+	if o.config.DebugAPI.QPS == 0 {
+		o.config.DebugAPI.QPS = debugapi.DefaultQPS
+	}
+	if o.config.DebugAPI.Burst == 0 {
+		o.config.DebugAPI.Burst = debugapi.DefaultBurst
+	}
+	if o.config.DebugAPI.PerUserQPS == 0 {
+		o.config.DebugAPI.PerUserQPS = debugapi.DefaultPerUserQPS
+	}
+	if o.config.DebugAPI.PerUserBurst == 0 {
+		o.config.DebugAPI.PerUserBurst = debugapi.DefaultPerUserBurst
+	}
+	if o.config.Webhook.PolicyDropRateThreshold == 0 {
+		o.config.Webhook.PolicyDropRateThreshold = defaultPolicyDropRateThreshold
+	}
 
 	if features.DefaultFeatureGate.Enabled(features.FlowExporter) {
 		if o.config.FlowCollectorAddr == "" {
@@ -198,6 +332,9 @@ func (o *Options) setDefaults() {
 			// This frequency value makes flow export interval as 60s by default.
 			o.config.FlowExportFrequency = defaultFlowExportFrequency
 		}
+		if o.config.FlowRecordBufferSize == 0 {
+			o.config.FlowRecordBufferSize = defaultFlowRecordBufferSize
+		}
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
@@ -205,6 +342,12 @@ func (o *Options) setDefaults() {
 			o.config.NPLPortRange = defaultNPLPortRange
 		}
 	}
+
+	if features.DefaultFeatureGate.Enabled(features.PodQoS) {
+		if o.config.PodQoSMaxRate == "" {
+			o.config.PodQoSMaxRate = defaultPodQoSMaxRate
+		}
+	}
 }
 
 func (o *Options) validateFlowExporterConfig() error {
@@ -227,3 +370,77 @@ func (o *Options) validateFlowExporterConfig() error {
 	}
 	return nil
 }
+
+func (o *Options) validateFallbackTunnelTypes() error {
+	if len(o.config.FallbackTunnelTypes) == 0 {
+		return nil
+	}
+	if _, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode); encryptionMode != config.TrafficEncryptionModeNone {
+		return fmt.Errorf("fallback tunnel types cannot be used together with TrafficEncryptionMode %s", encryptionMode)
+	}
+	seen := sets.NewString(o.config.TunnelType)
+	for _, tunnelType := range o.config.FallbackTunnelTypes {
+		if tunnelType != ovsconfig.VXLANTunnel && tunnelType != ovsconfig.GeneveTunnel &&
+			tunnelType != ovsconfig.GRETunnel && tunnelType != ovsconfig.STTTunnel {
+			return fmt.Errorf("fallback tunnel type %s is invalid", tunnelType)
+		}
+		if seen.Has(tunnelType) {
+			return fmt.Errorf("fallback tunnel type %s is duplicated or equal to the default tunnel type", tunnelType)
+		}
+		seen.Insert(tunnelType)
+	}
+	return nil
+}
+
+func (o *Options) validatePodQoSConfig() error {
+	if !features.DefaultFeatureGate.Enabled(features.PodQoS) {
+		return nil
+	}
+	maxRate, err := bandwidth.ParseRate(o.config.PodQoSMaxRate)
+	if err != nil {
+		return fmt.Errorf("podQoSMaxRate is invalid: %v", err)
+	}
+	o.podQoSMaxRate = maxRate
+	o.podQoSClasses = nil
+	classNames := sets.NewString()
+	priorityClassNames := sets.NewString()
+	for _, class := range o.config.PodQoSClasses {
+		if class.Name == "" {
+			return fmt.Errorf("the name of a QoS class must be set")
+		}
+		if classNames.Has(class.Name) {
+			return fmt.Errorf("QoS class %s is duplicated", class.Name)
+		}
+		classNames.Insert(class.Name)
+		for _, priorityClassName := range class.PriorityClassNames {
+			if priorityClassNames.Has(priorityClassName) {
+				return fmt.Errorf("PriorityClass %s belongs to more than one QoS class", priorityClassName)
+			}
+			priorityClassNames.Insert(priorityClassName)
+		}
+		qosClass := bandwidth.QoSClass{Name: class.Name, PriorityClassNames: class.PriorityClassNames, MaxRate: maxRate}
+		if class.MinRate != "" {
+			if qosClass.MinRate, err = bandwidth.ParseRate(class.MinRate); err != nil {
+				return fmt.Errorf("minRate of QoS class %s is invalid: %v", class.Name, err)
+			}
+		}
+		if class.MaxRate != "" {
+			if qosClass.MaxRate, err = bandwidth.ParseRate(class.MaxRate); err != nil {
+				return fmt.Errorf("maxRate of QoS class %s is invalid: %v", class.Name, err)
+			}
+		}
+		if qosClass.MinRate > qosClass.MaxRate {
+			return fmt.Errorf("minRate of QoS class %s is greater than its maxRate", class.Name)
+		}
+		if class.DSCP < 0 || class.DSCP > 63 {
+			return fmt.Errorf("dscp of QoS class %s must be in the range 0-63", class.Name)
+		}
+		// The DSCP values of the RFC 2474 pool xxxx11 are used as the dataplane tags of the Traceflow packets.
+		if class.DSCP&0b11 == 0b11 {
+			return fmt.Errorf("dscp %d of QoS class %s is reserved for Traceflow", class.DSCP, class.Name)
+		}
+		qosClass.DSCP = uint8(class.DSCP)
+		o.podQoSClasses = append(o.podQoSClasses, qosClass)
+	}
+	return nil
+}
@@ -48,9 +48,22 @@ func (o *Options) checkUnsupportedFeatures() error {
 	if o.config.TunnelType == ovsconfig.GRETunnel {
 		unsupported = append(unsupported, "TunnelType: "+o.config.TunnelType)
 	}
-	if o.config.EnableIPSecTunnel {
+	_, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	if o.config.EnableIPSecTunnel || encryptionMode == config.TrafficEncryptionModeIPSec {
 		unsupported = append(unsupported, "IPsecTunnel")
 	}
+	if encryptionMode == config.TrafficEncryptionModeWireGuard {
+		unsupported = append(unsupported, "TrafficEncryptionMode: "+encryptionMode.String())
+	}
+	if len(o.config.FallbackTunnelTypes) > 0 {
+		unsupported = append(unsupported, "FallbackTunnelTypes")
+	}
+	if o.config.Multicast.Enable {
+		unsupported = append(unsupported, "Multicast")
+	}
+	if o.config.EnableHardwareOffload {
+		unsupported = append(unsupported, "EnableHardwareOffload")
+	}
 
 	if unsupported != nil {
 		return fmt.Errorf("unsupported features on Windows: {%s}", strings.Join(unsupported, ", "))
@@ -0,0 +1,75 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The antrea-bench binary runs scale benchmarks against a real Antrea
+// deployment and reports latency distributions which can be used to track
+// performance regressions.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/version"
+)
+
+func main() {
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	command := newBenchCommand()
+	if err := command.Execute(); err != nil {
+		logs.FlushLogs()
+		os.Exit(1)
+	}
+}
+
+func newBenchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "antrea-bench",
+		Long:    "Scale benchmarks for Antrea.",
+		Version: version.GetFullVersion(),
+	}
+	// Install log flags
+	cmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
+	cmd.AddCommand(newNetworkPolicyCommand())
+	return cmd
+}
+
+func newNetworkPolicyCommand() *cobra.Command {
+	opts := newNetworkPolicyOptions()
+
+	cmd := &cobra.Command{
+		Use:   "networkpolicy",
+		Short: "Measure NetworkPolicy propagation and realization latencies",
+		Long: "Create Namespaces with fake Pods and Antrea NetworkPolicies selecting them, " +
+			"and measure how long it takes for the NetworkPolicies to be propagated by " +
+			"antrea-controller to the agent of the selected Node, and to be realized as OVS flows by that agent.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.validate(args); err != nil {
+				klog.Fatalf("Failed to validate: %v", err)
+			}
+			if err := runNetworkPolicyBenchmark(opts); err != nil {
+				klog.Fatalf("Error running NetworkPolicy benchmark: %v", err)
+			}
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	return cmd
+}
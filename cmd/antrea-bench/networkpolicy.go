@@ -0,0 +1,378 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	componentbaseconfig "k8s.io/component-base/config"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	crdclientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	"github.com/vmware-tanzu/antrea/pkg/k8s"
+)
+
+const (
+	// benchLabelKey is set on all the resources created by the benchmark.
+	benchLabelKey = "antrea-bench"
+	// groupLabelKey is set on the fake Pods to assign them to the NetworkPolicy
+	// rule selecting them.
+	groupLabelKey    = "antrea-bench/group"
+	appLabelKey      = "app"
+	targetPodName    = "antrea-bench-target"
+	namespacePrefix  = "antrea-bench-"
+	policyNamePrefix = "antrea-bench-"
+	fakePodPrefix    = "fake-pod-"
+)
+
+// networkPolicyBenchmarkResult is the result of a NetworkPolicy benchmark.
+type networkPolicyBenchmarkResult struct {
+	Namespaces           int `json:"namespaces"`
+	PodsPerNamespace     int `json:"podsPerNamespace"`
+	PoliciesPerNamespace int `json:"policiesPerNamespace"`
+	// Propagation is the distribution of the time between the creation of a
+	// NetworkPolicy and its dissemination by antrea-controller to the Node.
+	Propagation latencyDistribution `json:"propagation"`
+	// Realization is the distribution of the time between the creation of a
+	// NetworkPolicy and the report of its realization by the agent.
+	Realization latencyDistribution `json:"realization"`
+}
+
+type networkPolicyBenchmark struct {
+	opts      *networkPolicyOptions
+	k8sClient clientset.Interface
+	crdClient crdclientset.Interface
+
+	mutex sync.Mutex
+	// createTimes stores the time at which each NetworkPolicy was created,
+	// keyed by "<Namespace>/<Name>".
+	createTimes          map[string]time.Time
+	propagationLatencies map[string]time.Duration
+	realizationLatencies map[string]time.Duration
+	// allRealized is closed once all the NetworkPolicies have been realized.
+	allRealized chan struct{}
+	// closeAllRealized ensures that allRealized is closed only once, as recordLatency keeps being called for the
+	// propagation and realization events received afterwards.
+	closeAllRealized sync.Once
+}
+
+func runNetworkPolicyBenchmark(opts *networkPolicyOptions) error {
+	k8sClient, _, crdClient, err := k8s.CreateClients(componentbaseconfig.ClientConnectionConfiguration{Kubeconfig: opts.kubeconfig}, "")
+	if err != nil {
+		return fmt.Errorf("error creating K8s clients: %v", err)
+	}
+	b := &networkPolicyBenchmark{
+		opts:                 opts,
+		k8sClient:            k8sClient,
+		crdClient:            crdClient,
+		createTimes:          map[string]time.Time{},
+		propagationLatencies: map[string]time.Duration{},
+		realizationLatencies: map[string]time.Duration{},
+		allRealized:          make(chan struct{}),
+	}
+	if opts.cleanup {
+		defer b.teardown()
+	}
+	if err := b.setup(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	propagationWatcher, err := b.crdClient.ControlplaneV1beta2().NetworkPolicies().Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("nodeName", opts.nodeName).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error when watching internal NetworkPolicies for Node %s: %v", opts.nodeName, err)
+	}
+	defer propagationWatcher.Stop()
+	realizationWatcher, err := b.crdClient.SecurityV1alpha1().NetworkPolicies(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{benchLabelKey: "true"}).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error when watching Antrea NetworkPolicies: %v", err)
+	}
+	defer realizationWatcher.Stop()
+	go b.watchPropagation(propagationWatcher)
+	go b.watchRealization(realizationWatcher)
+
+	klog.Infof("Creating %d NetworkPolicies", opts.namespaces*opts.policiesPerNamespace)
+	for i := 0; i < opts.namespaces; i++ {
+		for j := 0; j < opts.policiesPerNamespace; j++ {
+			if err := b.createNetworkPolicy(getNamespaceName(i), j); err != nil {
+				return err
+			}
+		}
+	}
+
+	select {
+	case <-b.allRealized:
+		klog.Info("All NetworkPolicies have been realized")
+	case <-time.After(opts.timeout):
+		b.mutex.Lock()
+		realized := len(b.realizationLatencies)
+		b.mutex.Unlock()
+		return fmt.Errorf("timed out waiting for NetworkPolicies to be realized, %d/%d realized", realized, opts.namespaces*opts.policiesPerNamespace)
+	}
+	return b.printResult()
+}
+
+func getNamespaceName(i int) string {
+	return namespacePrefix + strconv.Itoa(i)
+}
+
+// getFakePodIP returns the i-th IP of the fake Pod CIDR, skipping the network
+// address.
+func (b *networkPolicyBenchmark) getFakePodIP(i int) string {
+	_, cidr, _ := net.ParseCIDR(b.opts.fakePodCIDR)
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(cidr.IP.To4())+uint32(i)+1)
+	return ip.String()
+}
+
+// setup creates the fake Node, the Namespaces, the Pods to which the
+// NetworkPolicies are applied and the fake Pods selected by the NetworkPolicy
+// rules. The fake Pods are assigned to a fake Node on which no kubelet runs,
+// and their IPs are set directly in their status.
+func (b *networkPolicyBenchmark) setup() error {
+	fakeNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.opts.fakeNodeName,
+			Labels: map[string]string{benchLabelKey: "true"},
+		},
+	}
+	if _, err := b.k8sClient.CoreV1().Nodes().Create(context.TODO(), fakeNode, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error when creating fake Node %s: %v", b.opts.fakeNodeName, err)
+	}
+
+	klog.Infof("Creating %d Namespaces with %d fake Pods each", b.opts.namespaces, b.opts.podsPerNamespace)
+	for i := 0; i < b.opts.namespaces; i++ {
+		namespace := getNamespaceName(i)
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: map[string]string{benchLabelKey: "true"},
+			},
+		}
+		if _, err := b.k8sClient.CoreV1().Namespaces().Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error when creating Namespace %s: %v", namespace, err)
+		}
+		targetPod := b.newPod(namespace, targetPodName, b.opts.nodeName, map[string]string{appLabelKey: targetPodName})
+		if _, err := b.k8sClient.CoreV1().Pods(namespace).Create(context.TODO(), targetPod, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error when creating Pod %s/%s: %v", namespace, targetPodName, err)
+		}
+		for j := 0; j < b.opts.podsPerNamespace; j++ {
+			podName := fakePodPrefix + strconv.Itoa(j)
+			pod := b.newPod(namespace, podName, b.opts.fakeNodeName, map[string]string{groupLabelKey: strconv.Itoa(j % b.opts.policiesPerNamespace)})
+			pod, err := b.k8sClient.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("error when creating Pod %s/%s: %v", namespace, podName, err)
+			}
+			podIP := b.getFakePodIP(i*b.opts.podsPerNamespace + j)
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.PodIP = podIP
+			pod.Status.PodIPs = []corev1.PodIP{{IP: podIP}}
+			if _, err := b.k8sClient.CoreV1().Pods(namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("error when updating status of Pod %s/%s: %v", namespace, podName, err)
+			}
+		}
+	}
+
+	klog.Infof("Waiting for Pods on Node %s to be running", b.opts.nodeName)
+	return wait.PollImmediate(time.Second, b.opts.timeout, func() (bool, error) {
+		pods, err := b.k8sClient.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{appLabelKey: targetPodName}).String(),
+		})
+		if err != nil {
+			return false, err
+		}
+		running := 0
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning {
+				running++
+			}
+		}
+		return running == b.opts.namespaces, nil
+	})
+}
+
+func (b *networkPolicyBenchmark) newPod(namespace, name, nodeName string, podLabels map[string]string) *corev1.Pod {
+	podLabels[benchLabelKey] = "true"
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    podLabels,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name:  "pause",
+					Image: b.opts.image,
+				},
+			},
+			// Tolerate all taints so that the Pods are neither evicted from the
+			// fake Node, which never becomes ready, nor prevented from running on
+			// the control-plane Node.
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+		},
+	}
+}
+
+func (b *networkPolicyBenchmark) createNetworkPolicy(namespace string, i int) error {
+	name := policyNamePrefix + strconv.Itoa(i)
+	allow := secv1alpha1.RuleActionAllow
+	np := &secv1alpha1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{benchLabelKey: "true"},
+		},
+		Spec: secv1alpha1.NetworkPolicySpec{
+			Priority: float64(i + 1),
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{appLabelKey: targetPodName}}},
+			},
+			Ingress: []secv1alpha1.Rule{
+				{
+					Action: &allow,
+					From: []secv1alpha1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{groupLabelKey: strconv.Itoa(i)}}},
+					},
+				},
+			},
+		},
+	}
+	b.mutex.Lock()
+	b.createTimes[namespace+"/"+name] = time.Now()
+	b.mutex.Unlock()
+	if _, err := b.crdClient.SecurityV1alpha1().NetworkPolicies(namespace).Create(context.TODO(), np, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error when creating NetworkPolicy %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// recordLatency records the latency of the NetworkPolicy identified by key in
+// latencies, if it is created by the benchmark and not recorded yet.
+func (b *networkPolicyBenchmark) recordLatency(key string, latencies map[string]time.Duration) {
+	now := time.Now()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	createTime, ok := b.createTimes[key]
+	if !ok {
+		return
+	}
+	if _, recorded := latencies[key]; recorded {
+		return
+	}
+	latencies[key] = now.Sub(createTime)
+	if len(b.realizationLatencies) == b.opts.namespaces*b.opts.policiesPerNamespace {
+		b.closeAllRealized.Do(func() { close(b.allRealized) })
+	}
+}
+
+func (b *networkPolicyBenchmark) watchPropagation(watcher watch.Interface) {
+	for event := range watcher.ResultChan() {
+		if event.Type != watch.Added {
+			continue
+		}
+		np, ok := event.Object.(*v1beta2.NetworkPolicy)
+		if !ok || np.SourceRef == nil || np.SourceRef.Type != v1beta2.AntreaNetworkPolicy {
+			continue
+		}
+		b.recordLatency(np.SourceRef.Namespace+"/"+np.SourceRef.Name, b.propagationLatencies)
+	}
+}
+
+func (b *networkPolicyBenchmark) watchRealization(watcher watch.Interface) {
+	for event := range watcher.ResultChan() {
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			continue
+		}
+		np, ok := event.Object.(*secv1alpha1.NetworkPolicy)
+		if !ok {
+			continue
+		}
+		if np.Status.Phase != secv1alpha1.NetworkPolicyRealized || np.Status.ObservedGeneration != np.Generation {
+			continue
+		}
+		b.recordLatency(np.Namespace+"/"+np.Name, b.realizationLatencies)
+	}
+}
+
+func (b *networkPolicyBenchmark) printResult() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	toSlice := func(latencies map[string]time.Duration) []time.Duration {
+		s := make([]time.Duration, 0, len(latencies))
+		for _, l := range latencies {
+			s = append(s, l)
+		}
+		return s
+	}
+	result := networkPolicyBenchmarkResult{
+		Namespaces:           b.opts.namespaces,
+		PodsPerNamespace:     b.opts.podsPerNamespace,
+		PoliciesPerNamespace: b.opts.policiesPerNamespace,
+		Propagation:          newLatencyDistribution(toSlice(b.propagationLatencies)),
+		Realization:          newLatencyDistribution(toSlice(b.realizationLatencies)),
+	}
+	if b.opts.outputFormat == outputFormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	fmt.Fprintf(os.Stdout, "Namespaces: %d, Pods per Namespace: %d, NetworkPolicies per Namespace: %d\n",
+		result.Namespaces, result.PodsPerNamespace, result.PoliciesPerNamespace)
+	result.Propagation.print(os.Stdout, "Propagation")
+	result.Realization.print(os.Stdout, "Realization")
+	return nil
+}
+
+// teardown deletes the Namespaces and the fake Node created by the benchmark.
+// Errors are only logged so that as many resources as possible are deleted.
+func (b *networkPolicyBenchmark) teardown() {
+	klog.Info("Deleting resources created by the benchmark")
+	for i := 0; i < b.opts.namespaces; i++ {
+		namespace := getNamespaceName(i)
+		if err := b.k8sClient.CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("Failed to delete Namespace %s: %v", namespace, err)
+		}
+	}
+	if err := b.k8sClient.CoreV1().Nodes().Delete(context.TODO(), b.opts.fakeNodeName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("Failed to delete fake Node %s: %v", b.opts.fakeNodeName, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+type networkPolicyOptions struct {
+	// The path of the kubeconfig file used to connect to the cluster.
+	kubeconfig string
+	// The number of Namespaces to create.
+	namespaces int
+	// The number of fake Pods to create in each Namespace.
+	podsPerNamespace int
+	// The number of NetworkPolicies to create in each Namespace.
+	policiesPerNamespace int
+	// The Node running the antrea-agent which realizes the NetworkPolicies.
+	nodeName string
+	// The name of the fake Node to which the fake Pods are assigned.
+	fakeNodeName string
+	// The CIDR from which the IPs of the fake Pods are allocated.
+	fakePodCIDR string
+	// The image of the Pods to which the NetworkPolicies are applied.
+	image string
+	// How long to wait for all the NetworkPolicies to be realized.
+	timeout time.Duration
+	// The format of the results, text or json.
+	outputFormat string
+	// Whether to delete the created resources when the benchmark completes.
+	cleanup bool
+}
+
+func newNetworkPolicyOptions() *networkPolicyOptions {
+	return &networkPolicyOptions{
+		namespaces:           10,
+		podsPerNamespace:     100,
+		policiesPerNamespace: 10,
+		fakeNodeName:         "antrea-bench-fake-node",
+		fakePodCIDR:          "100.64.0.0/10",
+		image:                "k8s.gcr.io/pause:3.2",
+		timeout:              10 * time.Minute,
+		outputFormat:         outputFormatText,
+		cleanup:              true,
+	}
+}
+
+// addFlags adds flags to fs and binds them to options.
+func (o *networkPolicyOptions) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The path to the kubeconfig file, the in-cluster config is used if not set")
+	fs.IntVar(&o.namespaces, "namespaces", o.namespaces, "The number of Namespaces to create")
+	fs.IntVar(&o.podsPerNamespace, "pods-per-namespace", o.podsPerNamespace, "The number of fake Pods to create in each Namespace, which are selected by the NetworkPolicy rules")
+	fs.IntVar(&o.policiesPerNamespace, "policies-per-namespace", o.policiesPerNamespace, "The number of NetworkPolicies to create in each Namespace")
+	fs.StringVar(&o.nodeName, "node", o.nodeName, "The Node on which the NetworkPolicies are realized, it must run antrea-agent")
+	fs.StringVar(&o.fakeNodeName, "fake-node", o.fakeNodeName, "The name of the fake Node to which the fake Pods are assigned")
+	fs.StringVar(&o.fakePodCIDR, "fake-pod-cidr", o.fakePodCIDR, "The CIDR from which the IPs of the fake Pods are allocated")
+	fs.StringVar(&o.image, "image", o.image, "The image of the Pods to which the NetworkPolicies are applied")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "How long to wait for all the NetworkPolicies to be realized")
+	fs.StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "The format of the results, one of text or json")
+	fs.BoolVar(&o.cleanup, "cleanup", o.cleanup, "Delete the created resources when the benchmark completes")
+}
+
+// validate validates all the required options.
+func (o *networkPolicyOptions) validate(args []string) error {
+	if len(args) != 0 {
+		return errors.New("no positional arguments are supported")
+	}
+	if o.nodeName == "" {
+		return errors.New("--node must be set")
+	}
+	if o.namespaces <= 0 || o.podsPerNamespace <= 0 || o.policiesPerNamespace <= 0 {
+		return errors.New("--namespaces, --pods-per-namespace and --policies-per-namespace must be positive")
+	}
+	_, cidr, err := net.ParseCIDR(o.fakePodCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid --fake-pod-cidr %s: %v", o.fakePodCIDR, err)
+	}
+	ones, bits := cidr.Mask.Size()
+	if cidr.IP.To4() == nil || bits-ones >= 31 {
+		return fmt.Errorf("--fake-pod-cidr must be an IPv4 CIDR with a prefix length greater than 1")
+	}
+	if uint64(o.namespaces)*uint64(o.podsPerNamespace) >= uint64(1)<<uint(bits-ones) {
+		return fmt.Errorf("--fake-pod-cidr %s is too small for %d fake Pods", o.fakePodCIDR, o.namespaces*o.podsPerNamespace)
+	}
+	if o.outputFormat != outputFormatText && o.outputFormat != outputFormatJSON {
+		return fmt.Errorf("unsupported output format %s", o.outputFormat)
+	}
+	return nil
+}
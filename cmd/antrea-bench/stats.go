@@ -0,0 +1,69 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// latencyDistribution summarizes a set of latency samples.
+type latencyDistribution struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+func newLatencyDistribution(samples []time.Duration) latencyDistribution {
+	if len(samples) == 0 {
+		return latencyDistribution{}
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, s := range sorted {
+		sum += s
+	}
+	// percentile uses the nearest-rank method.
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+	return latencyDistribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+func (d latencyDistribution) print(w io.Writer, name string) {
+	fmt.Fprintf(w, "%-12s count=%d min=%v mean=%v p50=%v p90=%v p99=%v max=%v\n",
+		name, d.Count, d.Min, d.Mean, d.P50, d.P90, d.P99, d.Max)
+}
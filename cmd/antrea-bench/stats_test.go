@@ -0,0 +1,77 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLatencyDistribution(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		durations := make([]time.Duration, 0, len(values))
+		for _, v := range values {
+			durations = append(durations, time.Duration(v)*time.Millisecond)
+		}
+		return durations
+	}
+	oneToHundred := make([]int, 0, 100)
+	for i := 100; i >= 1; i-- {
+		oneToHundred = append(oneToHundred, i)
+	}
+	for _, tc := range []struct {
+		name     string
+		samples  []time.Duration
+		expected latencyDistribution
+	}{
+		{
+			name:     "no sample",
+			expected: latencyDistribution{},
+		},
+		{
+			name:    "one sample",
+			samples: ms(5),
+			expected: latencyDistribution{
+				Count: 1, Min: 5 * time.Millisecond, Mean: 5 * time.Millisecond, P50: 5 * time.Millisecond,
+				P90: 5 * time.Millisecond, P99: 5 * time.Millisecond, Max: 5 * time.Millisecond,
+			},
+		},
+		{
+			name:    "unsorted samples",
+			samples: ms(7, 1, 10, 3, 5, 2, 9, 4, 8, 6),
+			expected: latencyDistribution{
+				Count: 10, Min: 1 * time.Millisecond, Mean: 5500 * time.Microsecond, P50: 5 * time.Millisecond,
+				P90: 9 * time.Millisecond, P99: 10 * time.Millisecond, Max: 10 * time.Millisecond,
+			},
+		},
+		{
+			name:    "hundred samples",
+			samples: ms(oneToHundred...),
+			expected: latencyDistribution{
+				Count: 100, Min: 1 * time.Millisecond, Mean: 50500 * time.Microsecond, P50: 50 * time.Millisecond,
+				P90: 90 * time.Millisecond, P99: 99 * time.Millisecond, Max: 100 * time.Millisecond,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var original []time.Duration
+			original = append(original, tc.samples...)
+			assert.Equal(t, tc.expected, newLatencyDistribution(tc.samples))
+			assert.Equal(t, original, tc.samples, "samples should not be modified")
+		})
+	}
+}
@@ -16,6 +16,8 @@ package main
 
 import (
 	componentbaseconfig "k8s.io/component-base/config"
+
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 )
 
 type ControllerConfig struct {
@@ -43,4 +45,72 @@ type ControllerConfig struct {
 	TLSCipherSuites string `yaml:"tlsCipherSuites,omitempty"`
 	// TLS min version.
 	TLSMinVersion string `yaml:"tlsMinVersion,omitempty"`
+	// Timeout of a Traceflow: a Running Traceflow is marked as Failed if it's not completed within this
+	// duration after its creation. Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// Defaults to "2m".
+	TraceflowTimeout string `yaml:"traceflowTimeout,omitempty"`
+	// Retention period of completed (Succeeded or Failed) Traceflows, after which they are deleted.
+	// It's counted from the creation of a Traceflow. Valid time units are "ns", "us" (or "µs"), "ms",
+	// "s", "m", "h". Defaults to "1h".
+	TraceflowRetentionPeriod string `yaml:"traceflowRetentionPeriod,omitempty"`
+	// Interval at which the IPsec PSK stored in the "antrea-ipsec" Secret is rotated. Valid time units
+	// are "ns", "us" (or "µs"), "ms", "s", "m", "h". The PSK is not rotated by antrea-controller if
+	// empty. Defaults to "".
+	IPsecPSKRotationInterval string `yaml:"ipsecPSKRotationInterval,omitempty"`
+	// LeaderElection configures the leader election among antrea-controller replicas.
+	LeaderElection LeaderElectionConfig `yaml:"leaderElection,omitempty"`
+	// DebugAPI configures the rate limiting and the audit log of the debug APIs.
+	DebugAPI DebugAPIConfig `yaml:"debugAPI,omitempty"`
+	// Webhook configures the HTTP endpoints notified of the events of antrea-controller.
+	Webhook WebhookConfig `yaml:"webhook,omitempty"`
+}
+
+type LeaderElectionConfig struct {
+	// Enable leader election, so that multiple antrea-controller replicas can be run for high
+	// availability. Only the leader computes the NetworkPolicies and serves the Antrea APIs, while the
+	// other replicas stand by and take over when the leader fails. The leader holds a Lease named
+	// "antrea-controller" in the Namespace of antrea-controller.
+	// Defaults to false.
+	LeaderElect bool `yaml:"leaderElect,omitempty"`
+	// Duration that the replicas which stand by wait after the last renewal of the Lease by the leader,
+	// before they attempt to acquire the Lease. It determines how fast a failed leader is replaced.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h". Defaults to "15s".
+	LeaseDuration string `yaml:"leaseDuration,omitempty"`
+	// Duration within which the leader must renew the Lease, after which it stops leading. It must be
+	// shorter than leaseDuration. Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// Defaults to "10s".
+	RenewDeadline string `yaml:"renewDeadline,omitempty"`
+	// Interval between the attempts of the replicas to acquire or renew the Lease. It must be shorter
+	// than renewDeadline. Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// Defaults to "2s".
+	RetryPeriod string `yaml:"retryPeriod,omitempty"`
+}
+
+type DebugAPIConfig struct {
+	// Maximum number of requests per second to the debug APIs of antrea-controller, e.g. the endpoint
+	// queries and the support bundles. A negative value disables the limit. Defaults to 10.
+	QPS float32 `yaml:"qps,omitempty"`
+	// Maximum burst of requests to the debug APIs. Defaults to 20.
+	Burst int `yaml:"burst,omitempty"`
+	// Maximum number of requests per second to the debug APIs from each user. A negative value
+	// disables the limit. Defaults to 2.
+	PerUserQPS float32 `yaml:"perUserQPS,omitempty"`
+	// Maximum burst of requests to the debug APIs from each user. Defaults to 10.
+	PerUserBurst int `yaml:"perUserBurst,omitempty"`
+	// Path of the file to which the requests to the debug APIs are recorded, with the user, the
+	// request URI and the response code. The requests are recorded in the log of antrea-controller if
+	// empty. Defaults to "".
+	AuditLogFile string `yaml:"auditLogFile,omitempty"`
+}
+
+type WebhookConfig struct {
+	// Webhooks to which the events are sent with HTTP POST requests. antrea-controller sends a
+	// "TraceflowDropped" event when a Traceflow completes and the packet was dropped. Each webhook has
+	// the following fields:
+	//   url: the URL of the webhook.
+	//   events: the types of the events sent to the webhook. All the events are sent if empty.
+	//   template: the Go template of the JSON body of the requests, executed with the event, which has
+	//     the fields Type, Time, Source, Message and Details. The "json" function encodes a value as
+	//     JSON. The event is encoded as JSON if empty.
+	Sinks []webhook.SinkConfig `yaml:"sinks,omitempty"`
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"path"
 	"time"
@@ -32,9 +33,11 @@ import (
 
 	"github.com/vmware-tanzu/antrea/pkg/apiserver"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/certificate"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/debugapi"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/openapi"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
 	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	"github.com/vmware-tanzu/antrea/pkg/controller/ipsec"
 	"github.com/vmware-tanzu/antrea/pkg/controller/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/store"
@@ -42,11 +45,13 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/controller/stats"
 	"github.com/vmware-tanzu/antrea/pkg/controller/traceflow"
 	"github.com/vmware-tanzu/antrea/pkg/features"
+	"github.com/vmware-tanzu/antrea/pkg/graphviz"
 	"github.com/vmware-tanzu/antrea/pkg/k8s"
 	"github.com/vmware-tanzu/antrea/pkg/log"
 	"github.com/vmware-tanzu/antrea/pkg/monitor"
 	"github.com/vmware-tanzu/antrea/pkg/signals"
 	"github.com/vmware-tanzu/antrea/pkg/util/cipher"
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 	"github.com/vmware-tanzu/antrea/pkg/version"
 )
 
@@ -79,6 +84,7 @@ var allowedPaths = []string{
 	"/validate/acnp",
 	"/validate/anp",
 	"/validate/clustergroup",
+	"/validate/traceflow",
 }
 
 // run starts Antrea Controller with the given options and waits for termination signal.
@@ -95,6 +101,8 @@ func run(o *Options) error {
 	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
 	podInformer := informerFactory.Core().V1().Pods()
 	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	serviceInformer := informerFactory.Core().V1().Services()
+	endpointsInformer := informerFactory.Core().V1().Endpoints()
 	networkPolicyInformer := informerFactory.Networking().V1().NetworkPolicies()
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	cnpInformer := crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies()
@@ -102,6 +110,7 @@ func run(o *Options) error {
 	anpInformer := crdInformerFactory.Security().V1alpha1().NetworkPolicies()
 	tierInformer := crdInformerFactory.Security().V1alpha1().Tiers()
 	traceflowInformer := crdInformerFactory.Ops().V1alpha1().Traceflows()
+	traceflowSetInformer := crdInformerFactory.Ops().V1alpha1().TraceflowSets()
 	cgInformer := crdInformerFactory.Core().V1alpha2().ClusterGroups()
 
 	// Create Antrea object storage.
@@ -114,6 +123,8 @@ func run(o *Options) error {
 		crdClient,
 		podInformer,
 		namespaceInformer,
+		serviceInformer,
+		endpointsInformer,
 		externalEntityInformer,
 		networkPolicyInformer,
 		cnpInformer,
@@ -132,20 +143,41 @@ func run(o *Options) error {
 
 	endpointQuerier := networkpolicy.NewEndpointQuerier(networkPolicyController)
 
+	serviceTopologyQuerier := networkpolicy.NewServiceTopologyQuerier(networkPolicyController, nodeInformer)
+
+	spanQuerier := networkpolicy.NewSpanQuerier(networkPolicyController)
+
 	controllerQuerier := querier.NewControllerQuerier(networkPolicyController, o.config.APIPort)
 
 	controllerMonitor := monitor.NewControllerMonitor(crdClient, nodeInformer, controllerQuerier)
 
+	// webhookNotifier is nil if no webhook is configured.
+	webhookNotifier, err := webhook.NewNotifier(o.config.Webhook.Sinks)
+	if err != nil {
+		return fmt.Errorf("error creating webhook notifier: %v", err)
+	}
+
+	// Traceflows are validated even when the Traceflow feature is disabled.
+	traceflowValidator := traceflow.NewValidator(podInformer, serviceInformer)
 	var traceflowController *traceflow.Controller
+	var traceflowSetController *traceflow.SetController
+	var traceflowGetter graphviz.TraceflowGetter
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
-		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, traceflowInformer)
+		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, traceflowInformer, o.traceflowTimeout, o.traceflowRetentionPeriod, webhookNotifier)
+		traceflowSetController = traceflow.NewTraceflowSetController(crdClient, podInformer, namespaceInformer, traceflowInformer, traceflowSetInformer, o.traceflowRetentionPeriod)
+		traceflowGetter = traceflowController.GetTraceflow
+	}
+
+	var pskRotator *ipsec.PSKRotator
+	if o.ipsecPSKRotationInterval > 0 {
+		pskRotator = ipsec.NewPSKRotator(client, nodeInformer, o.ipsecPSKRotationInterval)
 	}
 
 	// statsAggregator takes stats summaries from antrea-agents, aggregates them, and serves the Stats APIs with the
 	// aggregated data. For now it's only used for NetworkPolicy stats.
 	var statsAggregator *stats.Aggregator
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
-		statsAggregator = stats.NewAggregator(networkPolicyInformer, cnpInformer, anpInformer)
+		statsAggregator = stats.NewAggregator(networkPolicyInformer, cnpInformer, anpInformer, networkPolicyController)
 	}
 
 	cipherSuites, err := cipher.GenerateCipherSuitesList(o.config.TLSCipherSuites)
@@ -164,12 +196,23 @@ func run(o *Options) error {
 		groupStore,
 		controllerQuerier,
 		endpointQuerier,
+		serviceTopologyQuerier,
+		spanQuerier,
 		networkPolicyController,
+		traceflowValidator,
+		traceflowGetter,
 		networkPolicyStatusController,
 		statsAggregator,
 		o.config.EnablePrometheusMetrics,
 		cipherSuites,
-		cipher.TLSVersionMap[o.config.TLSMinVersion])
+		cipher.TLSVersionMap[o.config.TLSMinVersion],
+		debugapi.Config{
+			QPS:          o.config.DebugAPI.QPS,
+			Burst:        o.config.DebugAPI.Burst,
+			PerUserQPS:   o.config.DebugAPI.PerUserQPS,
+			PerUserBurst: o.config.DebugAPI.PerUserBurst,
+			AuditLogFile: o.config.DebugAPI.AuditLogFile,
+		})
 	if err != nil {
 		return fmt.Errorf("error creating API server config: %v", err)
 	}
@@ -190,29 +233,60 @@ func run(o *Options) error {
 
 	log.StartLogFileNumberMonitor(stopCh)
 
-	informerFactory.Start(stopCh)
-	crdInformerFactory.Start(stopCh)
+	// runControllers starts the informers and the controllers. When leader election is enabled, it's only called
+	// once this replica is elected as leader, and stopCh is closed when it loses the leadership.
+	runControllers := func(stopCh <-chan struct{}) {
+		informerFactory.Start(stopCh)
+		crdInformerFactory.Start(stopCh)
 
-	go controllerMonitor.Run(stopCh)
+		go controllerMonitor.Run(stopCh)
 
-	go networkPolicyController.Run(stopCh)
+		if webhookNotifier != nil {
+			go webhookNotifier.Run(stopCh)
+		}
 
-	go apiServer.Run(stopCh)
+		go networkPolicyController.Run(stopCh)
 
-	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
-		go statsAggregator.Run(stopCh)
+		if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
+			go statsAggregator.Run(stopCh)
+		}
+
+		if features.DefaultFeatureGate.Enabled(features.Traceflow) {
+			go traceflowController.Run(stopCh)
+			go traceflowSetController.Run(stopCh)
+		}
+
+		if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
+			go networkPolicyStatusController.Run(stopCh)
+		}
+
+		if o.ipsecPSKRotationInterval > 0 {
+			go pskRotator.Run(stopCh)
+		}
 	}
 
 	if o.config.EnablePrometheusMetrics {
 		metrics.InitializePrometheusMetrics()
 	}
 
-	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
-		go traceflowController.Run(stopCh)
-	}
-
-	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
-		go networkPolicyStatusController.Run(stopCh)
+	if o.config.LeaderElection.LeaderElect {
+		// All the replicas serve the API, so that they pass the liveness probe, but only the leader is ready and
+		// therefore selected by the antrea Service, and only the leader publishes its CA certificate.
+		elector, err := newLeaderElector(client, o, func(stopCh <-chan struct{}) {
+			apiServer.RunCACertController(stopCh)
+			runControllers(stopCh)
+		})
+		if err != nil {
+			return fmt.Errorf("error creating leader elector: %v", err)
+		}
+		if err := apiServer.GenericAPIServer.AddReadyzChecks(elector.readyzCheck()); err != nil {
+			return fmt.Errorf("error adding leader election readiness check: %v", err)
+		}
+		go apiServer.RunServer(stopCh)
+		go elector.run(stopCh)
+	} else {
+		runControllers(stopCh)
+		go apiServer.Run(stopCh)
 	}
 
 	<-stopCh
@@ -231,12 +305,17 @@ func createAPIServerConfig(kubeconfig string,
 	groupStore storage.Interface,
 	controllerQuerier querier.ControllerQuerier,
 	endpointQuerier networkpolicy.EndpointQuerier,
+	serviceTopologyQuerier networkpolicy.ServiceTopologyQuerier,
+	spanQuerier networkpolicy.SpanQuerier,
 	npController *networkpolicy.NetworkPolicyController,
+	traceflowValidator *traceflow.Validator,
+	traceflowGetter graphviz.TraceflowGetter,
 	networkPolicyStatusController *networkpolicy.StatusController,
 	statsAggregator *stats.Aggregator,
 	enableMetrics bool,
 	cipherSuites []uint16,
-	tlsMinVersion uint16) (*apiserver.Config, error) {
+	tlsMinVersion uint16,
+	debugAPIConfig debugapi.Config) (*apiserver.Config, error) {
 	secureServing := genericoptions.NewSecureServingOptions().WithLoopback()
 	authentication := genericoptions.NewDelegatingAuthenticationOptions()
 	authorization := genericoptions.NewDelegatingAuthorizationOptions().WithAlwaysAllowPaths(allowedPaths...)
@@ -279,6 +358,10 @@ func createAPIServerConfig(kubeconfig string,
 	serverConfig.MinRequestTimeout = int(serverMinWatchTimeout.Seconds())
 	serverConfig.SecureServing.CipherSuites = cipherSuites
 	serverConfig.SecureServing.MinTLSVersion = tlsMinVersion
+	debugAPIFilter := debugapi.NewFilter(apiserver.DebugAPIPaths, debugAPIConfig)
+	serverConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return genericapiserver.DefaultBuildHandlerChain(debugAPIFilter.Wrap(apiHandler), c)
+	}
 
 	return apiserver.NewConfig(
 		serverConfig,
@@ -291,5 +374,9 @@ func createAPIServerConfig(kubeconfig string,
 		controllerQuerier,
 		networkPolicyStatusController,
 		endpointQuerier,
-		npController), nil
+		serviceTopologyQuerier,
+		spanQuerier,
+		npController,
+		traceflowValidator,
+		traceflowGetter), nil
 }
@@ -0,0 +1,120 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apiserver/pkg/server/healthz"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/util/env"
+)
+
+const (
+	// leaseName is the name of the Lease held by the leader of the antrea-controller replicas.
+	leaseName = "antrea-controller"
+	// defaultLeaseNamespace is the Namespace of the Lease when the Namespace of antrea-controller is unknown.
+	defaultLeaseNamespace = "kube-system"
+)
+
+// leaderElector elects the leader of the antrea-controller replicas, which is the only replica running the
+// controllers and reported as ready, so that the antrea Service only selects the leader.
+type leaderElector struct {
+	config leaderelection.LeaderElectionConfig
+	// isLeader is 1 once the controllers have been started by this replica.
+	isLeader int32
+}
+
+func newLeaderElector(client clientset.Interface, o *Options, onStartedLeading func(stopCh <-chan struct{})) (*leaderElector, error) {
+	// The Pod name is kept when the antrea-controller container is restarted, so a random suffix is added to tell
+	// the instances apart.
+	identity := fmt.Sprintf("%s_%s", env.GetPodName(), uuid.NewUUID())
+	namespace := env.GetPodNamespace()
+	if namespace == "" {
+		namespace = defaultLeaseNamespace
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock,
+		namespace,
+		leaseName,
+		client.CoreV1(),
+		client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		return nil, fmt.Errorf("error creating Lease lock: %v", err)
+	}
+	e := &leaderElector{}
+	e.config = leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: o.leaseDuration,
+		RenewDeadline: o.renewDeadline,
+		RetryPeriod:   o.retryPeriod,
+		// Release the Lease when antrea-controller is stopped, so that another replica takes over without
+		// waiting for the Lease to expire.
+		ReleaseOnCancel: true,
+		Name:            leaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Became the leader of antrea-controller replicas as %s", identity)
+				onStartedLeading(ctx.Done())
+				atomic.StoreInt32(&e.isLeader, 1)
+			},
+			OnStoppedLeading: func() {
+				if atomic.LoadInt32(&e.isLeader) == 0 {
+					return
+				}
+				// The controllers cannot be restarted cleanly, and the clients must reconnect to the new leader,
+				// so antrea-controller exits and lets its Pod be restarted as a standby replica.
+				klog.Fatalf("Lost the leadership of antrea-controller replicas as %s, exiting", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					klog.Infof("The leader of antrea-controller replicas is %s", leader)
+				}
+			},
+		},
+	}
+	return e, nil
+}
+
+// readyzCheck returns a readiness check which fails while this replica is not the leader.
+func (e *leaderElector) readyzCheck() healthz.HealthChecker {
+	return healthz.NamedCheck("leader-election", func(_ *http.Request) error {
+		if atomic.LoadInt32(&e.isLeader) == 0 {
+			return errors.New("not the leader of antrea-controller replicas")
+		}
+		return nil
+	})
+}
+
+// run runs the leader election until stopCh is closed, at which point the Lease is released if held by this replica.
+func (e *leaderElector) run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		// Mark this replica as not the leader first, so that the Lease is released silently.
+		atomic.StoreInt32(&e.isLeader, 0)
+		cancel()
+	}()
+	leaderelection.RunOrDie(ctx, e.config)
+}
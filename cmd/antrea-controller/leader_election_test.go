@@ -0,0 +1,84 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeaderElector(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	o := &Options{
+		leaseDuration: 1 * time.Second,
+		renewDeadline: 500 * time.Millisecond,
+		retryPeriod:   100 * time.Millisecond,
+	}
+	startedCh := make(chan struct{})
+	var controllersStopCh <-chan struct{}
+	e, err := newLeaderElector(client, o, func(stopCh <-chan struct{}) {
+		controllersStopCh = stopCh
+		close(startedCh)
+	})
+	require.NoError(t, err)
+	readyz := e.readyzCheck()
+	assert.Error(t, readyz.Check(nil), "Replica should not be ready before becoming the leader")
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		e.run(stopCh)
+		close(doneCh)
+	}()
+
+	select {
+	case <-startedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for the replica to become the leader")
+	}
+	err = wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return readyz.Check(nil) == nil, nil
+	})
+	assert.NoError(t, err, "Replica should be ready once it is the leader")
+	lease, err := client.CoordinationV1().Leases(defaultLeaseNamespace).Get(context.TODO(), leaseName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, lease.Spec.HolderIdentity)
+	assert.NotEmpty(t, *lease.Spec.HolderIdentity)
+
+	close(stopCh)
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for the leader election to stop")
+	}
+	select {
+	case <-controllersStopCh:
+	default:
+		t.Error("The controllers should be stopped with the leader election")
+	}
+	assert.Error(t, readyz.Check(nil), "Replica should not be ready once the leader election is stopped")
+	// The Lease is released so that another replica does not wait for it to expire.
+	lease, err = client.CoordinationV1().Leases(defaultLeaseNamespace).Get(context.TODO(), leaseName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, lease.Spec.HolderIdentity)
+	assert.Empty(t, *lease.Spec.HolderIdentity)
+}
@@ -16,20 +16,41 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
 
 	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/debugapi"
 	"github.com/vmware-tanzu/antrea/pkg/features"
 )
 
+const (
+	defaultTraceflowTimeout         = 2 * time.Minute
+	defaultTraceflowRetentionPeriod = time.Hour
+	defaultLeaseDuration            = 15 * time.Second
+	defaultRenewDeadline            = 10 * time.Second
+	defaultRetryPeriod              = 2 * time.Second
+)
+
 type Options struct {
 	// The path of configuration file.
 	configFile string
 	// The configuration object
 	config *ControllerConfig
+	// Timeout of Traceflows.
+	traceflowTimeout time.Duration
+	// Retention period of completed Traceflows.
+	traceflowRetentionPeriod time.Duration
+	// Interval of IPsec PSK rotations, 0 if the PSK is not rotated.
+	ipsecPSKRotationInterval time.Duration
+	// Durations of the leader election, only used when leader election is enabled.
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
 }
 
 func newOptions() *Options {
@@ -62,6 +83,85 @@ func (o *Options) validate(args []string) error {
 	if len(args) != 0 {
 		return errors.New("no positional arguments are supported")
 	}
+	if err := o.validateTraceflowConfig(); err != nil {
+		return err
+	}
+	if err := o.validateIPsecConfig(); err != nil {
+		return err
+	}
+	if err := o.validateLeaderElectionConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *Options) validateTraceflowConfig() error {
+	if o.config.TraceflowTimeout != "" {
+		timeout, err := time.ParseDuration(o.config.TraceflowTimeout)
+		if err != nil {
+			return fmt.Errorf("traceflowTimeout is not a valid duration: %v", err)
+		}
+		if timeout <= 0 {
+			return errors.New("traceflowTimeout must be positive")
+		}
+		o.traceflowTimeout = timeout
+	}
+	if o.config.TraceflowRetentionPeriod != "" {
+		retentionPeriod, err := time.ParseDuration(o.config.TraceflowRetentionPeriod)
+		if err != nil {
+			return fmt.Errorf("traceflowRetentionPeriod is not a valid duration: %v", err)
+		}
+		o.traceflowRetentionPeriod = retentionPeriod
+	}
+	// The default retention period must also be validated against the configured timeout.
+	if o.traceflowRetentionPeriod < o.traceflowTimeout {
+		return errors.New("traceflowRetentionPeriod must not be shorter than traceflowTimeout")
+	}
+	return nil
+}
+
+func (o *Options) validateIPsecConfig() error {
+	if o.config.IPsecPSKRotationInterval != "" {
+		interval, err := time.ParseDuration(o.config.IPsecPSKRotationInterval)
+		if err != nil {
+			return fmt.Errorf("ipsecPSKRotationInterval is not a valid duration: %v", err)
+		}
+		if interval <= 0 {
+			return errors.New("ipsecPSKRotationInterval must be positive")
+		}
+		o.ipsecPSKRotationInterval = interval
+	}
+	return nil
+}
+
+func (o *Options) validateLeaderElectionConfig() error {
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"leaseDuration", o.config.LeaderElection.LeaseDuration, &o.leaseDuration},
+		{"renewDeadline", o.config.LeaderElection.RenewDeadline, &o.renewDeadline},
+		{"retryPeriod", o.config.LeaderElection.RetryPeriod, &o.retryPeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("leaderElection.%s is not a valid duration: %v", d.name, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("leaderElection.%s must be positive", d.name)
+		}
+		*d.dest = duration
+	}
+	if o.leaseDuration <= o.renewDeadline {
+		return errors.New("leaderElection.leaseDuration must be longer than leaderElection.renewDeadline")
+	}
+	if o.renewDeadline <= o.retryPeriod {
+		return errors.New("leaderElection.renewDeadline must be longer than leaderElection.retryPeriod")
+	}
 	return nil
 }
 
@@ -78,4 +178,31 @@ func (o *Options) setDefaults() {
 	if o.config.APIPort == 0 {
 		o.config.APIPort = apis.AntreaControllerAPIPort
 	}
+	if o.config.DebugAPI.QPS == 0 {
+		o.config.DebugAPI.QPS = debugapi.DefaultQPS
+	}
+	if o.config.DebugAPI.Burst == 0 {
+		o.config.DebugAPI.Burst = debugapi.DefaultBurst
+	}
+	if o.config.DebugAPI.PerUserQPS == 0 {
+		o.config.DebugAPI.PerUserQPS = debugapi.DefaultPerUserQPS
+	}
+	if o.config.DebugAPI.PerUserBurst == 0 {
+		o.config.DebugAPI.PerUserBurst = debugapi.DefaultPerUserBurst
+	}
+	if o.config.TraceflowTimeout == "" {
+		o.traceflowTimeout = defaultTraceflowTimeout
+	}
+	if o.config.TraceflowRetentionPeriod == "" {
+		o.traceflowRetentionPeriod = defaultTraceflowRetentionPeriod
+	}
+	if o.config.LeaderElection.LeaseDuration == "" {
+		o.leaseDuration = defaultLeaseDuration
+	}
+	if o.config.LeaderElection.RenewDeadline == "" {
+		o.renewDeadline = defaultRenewDeadline
+	}
+	if o.config.LeaderElection.RetryPeriod == "" {
+		o.retryPeriod = defaultRetryPeriod
+	}
 }
@@ -0,0 +1,167 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTraceflowConfig(t *testing.T) {
+	testCases := []struct {
+		desc                    string
+		timeout                 string
+		retentionPeriod         string
+		pass                    bool
+		expectedTimeout         time.Duration
+		expectedRetentionPeriod time.Duration
+	}{
+		{
+			desc:                    "default",
+			pass:                    true,
+			expectedTimeout:         defaultTraceflowTimeout,
+			expectedRetentionPeriod: defaultTraceflowRetentionPeriod,
+		},
+		{
+			desc:                    "custom",
+			timeout:                 "30s",
+			retentionPeriod:         "10m",
+			pass:                    true,
+			expectedTimeout:         30 * time.Second,
+			expectedRetentionPeriod: 10 * time.Minute,
+		},
+		{
+			desc:    "invalid timeout",
+			timeout: "30",
+			pass:    false,
+		},
+		{
+			desc:    "negative timeout",
+			timeout: "-30s",
+			pass:    false,
+		},
+		{
+			desc:            "invalid retention period",
+			retentionPeriod: "1d",
+			pass:            false,
+		},
+		{
+			desc:            "retention period shorter than timeout",
+			timeout:         "10m",
+			retentionPeriod: "5m",
+			pass:            false,
+		},
+		{
+			desc:    "default retention period shorter than timeout",
+			timeout: "2h",
+			pass:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			o := newOptions()
+			o.config.TraceflowTimeout = tc.timeout
+			o.config.TraceflowRetentionPeriod = tc.retentionPeriod
+			o.setDefaults()
+			err := o.validateTraceflowConfig()
+			if !tc.pass {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTimeout, o.traceflowTimeout)
+			assert.Equal(t, tc.expectedRetentionPeriod, o.traceflowRetentionPeriod)
+		})
+	}
+}
+
+func TestValidateLeaderElectionConfig(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		leaseDuration         string
+		renewDeadline         string
+		retryPeriod           string
+		pass                  bool
+		expectedLeaseDuration time.Duration
+		expectedRenewDeadline time.Duration
+		expectedRetryPeriod   time.Duration
+	}{
+		{
+			desc:                  "default",
+			pass:                  true,
+			expectedLeaseDuration: defaultLeaseDuration,
+			expectedRenewDeadline: defaultRenewDeadline,
+			expectedRetryPeriod:   defaultRetryPeriod,
+		},
+		{
+			desc:                  "custom",
+			leaseDuration:         "30s",
+			renewDeadline:         "20s",
+			retryPeriod:           "5s",
+			pass:                  true,
+			expectedLeaseDuration: 30 * time.Second,
+			expectedRenewDeadline: 20 * time.Second,
+			expectedRetryPeriod:   5 * time.Second,
+		},
+		{
+			desc:          "invalid lease duration",
+			leaseDuration: "30",
+			pass:          false,
+		},
+		{
+			desc:          "negative renew deadline",
+			renewDeadline: "-10s",
+			pass:          false,
+		},
+		{
+			desc:        "zero retry period",
+			retryPeriod: "0s",
+			pass:        false,
+		},
+		{
+			desc:          "lease duration not longer than renew deadline",
+			leaseDuration: "10s",
+			pass:          false,
+		},
+		{
+			desc:          "renew deadline not longer than retry period",
+			renewDeadline: "5s",
+			retryPeriod:   "5s",
+			pass:          false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			o := newOptions()
+			o.config.LeaderElection.LeaseDuration = tc.leaseDuration
+			o.config.LeaderElection.RenewDeadline = tc.renewDeadline
+			o.config.LeaderElection.RetryPeriod = tc.retryPeriod
+			o.setDefaults()
+			err := o.validateLeaderElectionConfig()
+			if !tc.pass {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLeaseDuration, o.leaseDuration)
+			assert.Equal(t, tc.expectedRenewDeadline, o.renewDeadline)
+			assert.Equal(t, tc.expectedRetryPeriod, o.retryPeriod)
+		})
+	}
+}
@@ -275,9 +275,11 @@ if $IPSEC; then
     $KUSTOMIZE edit add base $BASE
     # create a K8s Secret to save the PSK (pre-shared key) for IKE authentication.
     $KUSTOMIZE edit add resource ipsecSecret.yml
+    # allow antrea-controller to rotate the PSK.
+    $KUSTOMIZE edit add resource ipsecRBAC.yml
     # add a container to the Agent DaemonSet that runs the OVS IPSec and strongSwan daemons.
     $KUSTOMIZE edit add patch --path ipsecContainer.yml
-    # add an environment variable to the antrea-agent container for passing the PSK to Agent.
+    # add an environment variable and a volume to the antrea-agent container for passing the PSK to Agent.
     $KUSTOMIZE edit add patch --path pskEnv.yml
     BASE=../ipsec
     cd ..
@@ -99,11 +99,11 @@ MOCKGEN_TARGETS=(
   "pkg/agent/interfacestore InterfaceStore"
   "pkg/agent/openflow Client,OFEntryOperations"
   "pkg/agent/route Interface"
-  "pkg/ovs/openflow Bridge,Table,Flow,Action,CTAction,FlowBuilder"
+  "pkg/ovs/openflow Bridge,Table,Flow,Action,CTAction,FlowBuilder,Group,BucketBuilder"
   "pkg/ovs/ovsconfig OVSBridgeClient"
   "pkg/ovs/ovsctl OVSCtlClient"
   "pkg/agent/querier AgentQuerier"
-  "pkg/controller/networkpolicy EndpointQuerier"
+  "pkg/controller/networkpolicy BaselineStatusQuerier,EndpointQuerier,ServiceTopologyQuerier,SpanQuerier"
   "pkg/controller/querier ControllerQuerier"
   "pkg/querier AgentNetworkPolicyInfoQuerier"
   "pkg/agent/flowexporter/connections ConnTrackDumper,NetFilterConnTrack"
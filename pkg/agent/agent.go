@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/containernetworking/plugins/pkg/ip"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
@@ -51,23 +53,32 @@ const (
 	roundNumKey             = "roundNum" // round number key in externalIDs.
 	initialRoundNum         = 1
 	maxRetryForRoundNumSave = 5
+	// hwOffloadConfigKey is the key of the OVS hardware offload config in the other_config column of the
+	// Open_vSwitch table.
+	hwOffloadConfigKey = "hw-offload"
+	// tunnelTOSKey is the option of the tunnel interfaces which sets the TOS of the outer IP header.
+	tunnelTOSKey = "tos"
+	// tunnelTOSInherit copies the TOS of the inner IP header to the outer IP header.
+	tunnelTOSInherit = "inherit"
 )
 
 // Initializer knows how to setup host networking, OpenVSwitch, and Openflow.
 type Initializer struct {
-	client          clientset.Interface
-	ovsBridgeClient ovsconfig.OVSBridgeClient
-	ofClient        openflow.Client
-	routeClient     route.Interface
-	ifaceStore      interfacestore.InterfaceStore
-	ovsBridge       string
-	hostGateway     string // name of gateway port on the OVS bridge
-	mtu             int
-	serviceCIDR     *net.IPNet // K8s Service ClusterIP CIDR
-	serviceCIDRv6   *net.IPNet // K8s Service ClusterIP CIDR in IPv6
-	networkConfig   *config.NetworkConfig
-	nodeConfig      *config.NodeConfig
-	enableProxy     bool
+	client                clientset.Interface
+	ovsBridgeClient       ovsconfig.OVSBridgeClient
+	ofClient              openflow.Client
+	routeClient           route.Interface
+	ifaceStore            interfacestore.InterfaceStore
+	ovsBridge             string
+	hostGateway           string // name of gateway port on the OVS bridge
+	mtu                   int
+	serviceCIDR           *net.IPNet // K8s Service ClusterIP CIDR
+	serviceCIDRv6         *net.IPNet // K8s Service ClusterIP CIDR in IPv6
+	networkConfig         *config.NetworkConfig
+	nodeConfig            *config.NodeConfig
+	enableProxy           bool
+	enableHardwareOffload bool
+	enablePodQoS          bool
 	// networkReadyCh should be closed once the Node's network is ready.
 	// The CNI server will wait for it before handling any CNI Add requests.
 	networkReadyCh chan<- struct{}
@@ -86,21 +97,25 @@ func NewInitializer(
 	serviceCIDRv6 *net.IPNet,
 	networkConfig *config.NetworkConfig,
 	networkReadyCh chan<- struct{},
-	enableProxy bool) *Initializer {
+	enableProxy bool,
+	enableHardwareOffload bool,
+	enablePodQoS bool) *Initializer {
 	return &Initializer{
-		ovsBridgeClient: ovsBridgeClient,
-		client:          k8sClient,
-		ifaceStore:      ifaceStore,
-		ofClient:        ofClient,
-		routeClient:     routeClient,
-		ovsBridge:       ovsBridge,
-		hostGateway:     hostGateway,
-		mtu:             mtu,
-		serviceCIDR:     serviceCIDR,
-		serviceCIDRv6:   serviceCIDRv6,
-		networkConfig:   networkConfig,
-		networkReadyCh:  networkReadyCh,
-		enableProxy:     enableProxy,
+		ovsBridgeClient:       ovsBridgeClient,
+		client:                k8sClient,
+		ifaceStore:            ifaceStore,
+		ofClient:              ofClient,
+		routeClient:           routeClient,
+		ovsBridge:             ovsBridge,
+		hostGateway:           hostGateway,
+		mtu:                   mtu,
+		serviceCIDR:           serviceCIDR,
+		serviceCIDRv6:         serviceCIDRv6,
+		networkConfig:         networkConfig,
+		networkReadyCh:        networkReadyCh,
+		enableProxy:           enableProxy,
+		enableHardwareOffload: enableHardwareOffload,
+		enablePodQoS:          enablePodQoS,
 	}
 }
 
@@ -111,6 +126,12 @@ func (i *Initializer) GetNodeConfig() *config.NodeConfig {
 
 // setupOVSBridge sets up the OVS bridge and create host gateway interface and tunnel port
 func (i *Initializer) setupOVSBridge() error {
+	if i.enableHardwareOffload {
+		// Must be done before the bridge is created, as the hardware offload config is read when creating it.
+		if err := i.setupHardwareOffload(); err != nil {
+			return err
+		}
+	}
 	if err := i.ovsBridgeClient.Create(); err != nil {
 		klog.Error("Failed to create OVS bridge: ", err)
 		return err
@@ -137,6 +158,30 @@ func (i *Initializer) setupOVSBridge() error {
 	return nil
 }
 
+// setupHardwareOffload sets "other_config:hw-offload" to true in OVSDB, so that the datapath flows are offloaded to
+// the NIC with TC. ovs-vswitchd only reads the config when it starts, so a warning is logged if it was not set yet.
+func (i *Initializer) setupHardwareOffload() error {
+	otherConfig, err := i.ovsBridgeClient.GetOVSOtherConfig()
+	if err != nil {
+		return fmt.Errorf("error when getting OVS other_config: %v", err)
+	}
+	value, exists := otherConfig[hwOffloadConfigKey]
+	if value == "true" {
+		return nil
+	}
+	if exists {
+		// The "insert" mutation of AddOVSOtherConfig doesn't replace the value of an existing key.
+		if err := i.ovsBridgeClient.DeleteOVSOtherConfig(map[string]interface{}{hwOffloadConfigKey: value}); err != nil {
+			return fmt.Errorf("error when deleting OVS hw-offload config: %v", err)
+		}
+	}
+	if err := i.ovsBridgeClient.AddOVSOtherConfig(map[string]interface{}{hwOffloadConfigKey: "true"}); err != nil {
+		return fmt.Errorf("error when enabling OVS hardware offload: %v", err)
+	}
+	klog.Warning("Enabled OVS hardware offload in OVSDB, ovs-vswitchd must be restarted for it to take effect")
+	return nil
+}
+
 // initInterfaceStore initializes InterfaceStore with all OVS ports retrieved
 // from the OVS bridge.
 func (i *Initializer) initInterfaceStore() error {
@@ -322,7 +367,18 @@ func (i *Initializer) initOpenFlowPipeline() error {
 		// from local Pods to any Service address can be forwarded to the host gateway interface
 		// correctly. Otherwise packets might be dropped by egress rules before they are DNATed to
 		// backend Pods.
-		if err := i.ofClient.InstallClusterServiceCIDRFlows([]*net.IPNet{i.serviceCIDR, i.serviceCIDRv6}); err != nil {
+		// The flows of each Service CIDR forward the traffic to the host gateway, so they are only installed for the
+		// IP families of the host gateway, which also works in networkPolicyOnly mode where the Node has no Pod CIDR.
+		// serviceCIDR always has a value as it defaults to 10.96.0.0/12, while serviceCIDRv6 is only set when
+		// requested explicitly.
+		var serviceCIDRs []*net.IPNet
+		if i.nodeConfig.GatewayConfig.IPv4 != nil {
+			serviceCIDRs = append(serviceCIDRs, i.serviceCIDR)
+		}
+		if i.nodeConfig.GatewayConfig.IPv6 != nil && i.serviceCIDRv6 != nil {
+			serviceCIDRs = append(serviceCIDRs, i.serviceCIDRv6)
+		}
+		if err := i.ofClient.InstallClusterServiceCIDRFlows(serviceCIDRs); err != nil {
 			klog.Errorf("Failed to setup OpenFlow entries for Service CIDRs: %v", err)
 			return err
 		}
@@ -565,6 +621,9 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 				}
 				tunnelIface.TunnelInterfaceConfig.Csum = true
 			}
+			if err := i.syncTunnelTOS(tunnelPortName); err != nil {
+				return fmt.Errorf("failed to set tos for tunnel port %s: %v", tunnelPortName, err)
+			}
 			return nil
 		}
 
@@ -596,6 +655,9 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 		tunnelIface = interfacestore.NewTunnelInterface(tunnelPortName, i.networkConfig.TunnelType, localIP, shouldEnableCsum)
 		tunnelIface.OVSPortConfig = &interfacestore.OVSPortConfig{PortUUID: tunnelPortUUID, OFPort: config.DefaultTunOFPort}
 		i.ifaceStore.AddInterface(tunnelIface)
+		if err := i.syncTunnelTOS(tunnelPortName); err != nil {
+			return fmt.Errorf("failed to set tos for tunnel port %s: %v", tunnelPortName, err)
+		}
 	}
 	return nil
 }
@@ -614,6 +676,30 @@ func (i *Initializer) enableTunnelCsum(tunnelPortName string) error {
 	return i.ovsBridgeClient.SetInterfaceOptions(tunnelPortName, updatedOptions)
 }
 
+// syncTunnelTOS makes the tunnel port copy the TOS of the inner IP header to the outer IP header when PodQoS is
+// enabled, so that the DSCP marked on the egress traffic of the Pods is seen by the underlay network. The option is
+// removed when PodQoS is disabled.
+func (i *Initializer) syncTunnelTOS(tunnelPortName string) error {
+	options, err := i.ovsBridgeClient.GetInterfaceOptions(tunnelPortName)
+	if err != nil {
+		return fmt.Errorf("error getting interface options: %w", err)
+	}
+	if (options[tunnelTOSKey] == tunnelTOSInherit) == i.enablePodQoS {
+		return nil
+	}
+
+	updatedOptions := make(map[string]interface{})
+	for k, v := range options {
+		if k != tunnelTOSKey {
+			updatedOptions[k] = v
+		}
+	}
+	if i.enablePodQoS {
+		updatedOptions[tunnelTOSKey] = tunnelTOSInherit
+	}
+	return i.ovsBridgeClient.SetInterfaceOptions(tunnelPortName, updatedOptions)
+}
+
 // initNodeLocalConfig retrieves node's subnet CIDR from node.spec.PodCIDR, which is used for IPAM and setup
 // host gateway interface.
 func (i *Initializer) initNodeLocalConfig() error {
@@ -650,6 +736,10 @@ func (i *Initializer) initNodeLocalConfig() error {
 	i.nodeConfig.NodeMTU = mtu
 	klog.Infof("Setting Node MTU=%d", mtu)
 
+	if err := i.updateNodeTunnelTypes(node); err != nil {
+		return err
+	}
+
 	if i.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() {
 		return nil
 	}
@@ -699,9 +789,34 @@ func (i *Initializer) initNodeLocalConfig() error {
 	return nil
 }
 
+// updateNodeTunnelTypes advertises the tunnel types supported by this Node to
+// the other Nodes through the tunnel types annotation of the Node, so that the
+// tunnel type to use can be negotiated for each pair of Nodes.
+func (i *Initializer) updateNodeTunnelTypes(node *corev1.Node) error {
+	value := ""
+	if i.networkConfig.TrafficEncapMode.SupportsEncap() && len(i.networkConfig.FallbackTunnelTypes) > 0 {
+		value = noderoute.FormatTunnelTypes(i.networkConfig.SupportedTunnelTypes())
+	}
+	current, exists := node.Annotations[noderoute.TunnelTypesAnnotationKey]
+	if current == value && (exists || value == "") {
+		return nil
+	}
+	var patch string
+	if value == "" {
+		patch = fmt.Sprintf(`{"metadata":{"annotations":{"%s":null}}}`, noderoute.TunnelTypesAnnotationKey)
+	} else {
+		patch = fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, noderoute.TunnelTypesAnnotationKey, value)
+	}
+	if _, err := i.client.CoreV1().Nodes().Patch(context.TODO(), node.Name, apitypes.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to update tunnel types annotation of Node %s: %v", node.Name, err)
+	}
+	klog.Infof("Updated tunnel types of Node %s to \"%s\"", node.Name, value)
+	return nil
+}
+
 // initializeIPSec checks if preconditions are met for using IPsec and reads the IPsec PSK value.
 func (i *Initializer) initializeIPSec() error {
-	if !i.networkConfig.EnableIPSecTunnel {
+	if i.networkConfig.TrafficEncryptionMode != config.TrafficEncryptionModeIPSec {
 		return nil
 	}
 
@@ -806,19 +921,32 @@ func (i *Initializer) getNodeMTU(localIntf *net.Interface) (int, error) {
 	if mtu <= 0 {
 		return 0, fmt.Errorf("Failed to fetch Node MTU : %v", mtu)
 	}
-	if i.networkConfig.TrafficEncapMode.SupportsEncap() {
-		if i.networkConfig.TunnelType == ovsconfig.VXLANTunnel {
-			mtu -= config.VXLANOverhead
-		} else if i.networkConfig.TunnelType == ovsconfig.GeneveTunnel {
-			mtu -= config.GeneveOverhead
-		} else if i.networkConfig.TunnelType == ovsconfig.GRETunnel {
-			mtu -= config.GREOverhead
+	if i.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		// Pod traffic to other Nodes is not encapsulated by OVS but by WireGuard.
+		mtu -= config.WireGuardOverhead
+	} else if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+		// Accommodate for the largest overhead of all the tunnel types which can be
+		// used by this Node.
+		overhead := 0
+		for _, tunnelType := range i.networkConfig.SupportedTunnelTypes() {
+			tunnelOverhead := 0
+			if tunnelType == ovsconfig.VXLANTunnel {
+				tunnelOverhead = config.VXLANOverhead
+			} else if tunnelType == ovsconfig.GeneveTunnel {
+				tunnelOverhead = config.GeneveOverhead
+			} else if tunnelType == ovsconfig.GRETunnel {
+				tunnelOverhead = config.GREOverhead
+			}
+			if tunnelOverhead > overhead {
+				overhead = tunnelOverhead
+			}
 		}
+		mtu -= overhead
 		if i.nodeConfig.NodeIPAddr.IP.To4() == nil {
 			mtu -= config.IPv6ExtraOverhead
 		}
 	}
-	if i.networkConfig.EnableIPSecTunnel {
+	if i.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		mtu -= config.IPSecESPOverhead
 	}
 	return mtu, nil
@@ -134,3 +134,89 @@ func TestGetRoundInfo(t *testing.T) {
 	roundInfo = getRoundInfo(mockOVSBridgeClient)
 	assert.Equal(t, uint64(initialRoundNum), roundInfo.RoundNum, "Unexpected round number")
 }
+
+func TestSetupHardwareOffload(t *testing.T) {
+	tests := []struct {
+		name        string
+		otherConfig map[string]string
+		expectCalls func(mockOVSBridgeClient *ovsconfigtest.MockOVSBridgeClient)
+	}{
+		{
+			name:        "already enabled",
+			otherConfig: map[string]string{hwOffloadConfigKey: "true"},
+			expectCalls: func(mockOVSBridgeClient *ovsconfigtest.MockOVSBridgeClient) {},
+		},
+		{
+			name:        "not set",
+			otherConfig: map[string]string{},
+			expectCalls: func(mockOVSBridgeClient *ovsconfigtest.MockOVSBridgeClient) {
+				mockOVSBridgeClient.EXPECT().AddOVSOtherConfig(map[string]interface{}{hwOffloadConfigKey: "true"}).Return(nil)
+			},
+		},
+		{
+			name:        "disabled",
+			otherConfig: map[string]string{hwOffloadConfigKey: "false"},
+			expectCalls: func(mockOVSBridgeClient *ovsconfigtest.MockOVSBridgeClient) {
+				deleteCall := mockOVSBridgeClient.EXPECT().DeleteOVSOtherConfig(map[string]interface{}{hwOffloadConfigKey: "false"}).Return(nil)
+				mockOVSBridgeClient.EXPECT().AddOVSOtherConfig(map[string]interface{}{hwOffloadConfigKey: "true"}).Return(nil).After(deleteCall)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := mock.NewController(t)
+			defer controller.Finish()
+			mockOVSBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(controller)
+			mockOVSBridgeClient.EXPECT().GetOVSOtherConfig().Return(tt.otherConfig, nil)
+			tt.expectCalls(mockOVSBridgeClient)
+
+			initializer := newAgentInitializer(mockOVSBridgeClient, interfacestore.NewInterfaceStore())
+			assert.NoError(t, initializer.setupHardwareOffload())
+		})
+	}
+}
+
+func TestSyncTunnelTOS(t *testing.T) {
+	tests := []struct {
+		name            string
+		enablePodQoS    bool
+		options         map[string]string
+		expectedOptions map[string]interface{}
+	}{
+		{
+			name:            "PodQoS enabled",
+			enablePodQoS:    true,
+			options:         map[string]string{"csum": "true"},
+			expectedOptions: map[string]interface{}{"csum": "true", tunnelTOSKey: tunnelTOSInherit},
+		},
+		{
+			name:         "PodQoS enabled and tos already set",
+			enablePodQoS: true,
+			options:      map[string]string{"csum": "true", tunnelTOSKey: tunnelTOSInherit},
+		},
+		{
+			name:            "PodQoS disabled and tos set",
+			options:         map[string]string{"csum": "true", tunnelTOSKey: tunnelTOSInherit},
+			expectedOptions: map[string]interface{}{"csum": "true"},
+		},
+		{
+			name:    "PodQoS disabled",
+			options: map[string]string{"csum": "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := mock.NewController(t)
+			defer controller.Finish()
+			mockOVSBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(controller)
+			mockOVSBridgeClient.EXPECT().GetInterfaceOptions("antrea-tun0").Return(tt.options, nil)
+			if tt.expectedOptions != nil {
+				mockOVSBridgeClient.EXPECT().SetInterfaceOptions("antrea-tun0", tt.expectedOptions).Return(nil)
+			}
+
+			initializer := newAgentInitializer(mockOVSBridgeClient, interfacestore.NewInterfaceStore())
+			initializer.enablePodQoS = tt.enablePodQoS
+			assert.NoError(t, initializer.syncTunnelTOS("antrea-tun0"))
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"path"
 
@@ -33,13 +34,20 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/addressgroup"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/agentinfo"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/appliedtogroup"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/cniconflict"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/conntrack"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/drain"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/encryption"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/networkpolicy"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsdatapathflows"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsflows"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsflowstats"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovstracing"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/podinterface"
 	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
 	systeminstall "github.com/vmware-tanzu/antrea/pkg/apis/system/install"
 	systemv1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/system/v1beta1"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/debugapi"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/loglevel"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/registry/system/supportbundle"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
@@ -56,6 +64,24 @@ var (
 	TokenPath = "/var/run/antrea/apiserver/loopback-client-token"
 )
 
+// debugAPIPaths are the paths of the APIs exposing information about the Node network, whose
+// requests are rate limited and audited.
+var debugAPIPaths = []string{
+	"/podinterfaces",
+	"/networkpolicies",
+	"/appliedtogroups",
+	"/addressgroups",
+	"/ovsflows",
+	"/ovsflowstats",
+	"/ovsdatapathflows",
+	"/ovstracing",
+	"/encryptionstatus",
+	"/drain",
+	"/cniconflicts",
+	"/conntrack",
+	"/apis/" + systemv1beta1.GroupName + "/v1beta1/supportbundles",
+}
+
 func init() {
 	systeminstall.Install(scheme)
 	metav1.AddToGroupVersion(scheme, schema.GroupVersion{Version: "v1"})
@@ -77,7 +103,13 @@ func installHandlers(aq agentquerier.AgentQuerier, npq querier.AgentNetworkPolic
 	s.Handler.NonGoRestfulMux.HandleFunc("/appliedtogroups", appliedtogroup.HandleFunc(npq))
 	s.Handler.NonGoRestfulMux.HandleFunc("/addressgroups", addressgroup.HandleFunc(npq))
 	s.Handler.NonGoRestfulMux.HandleFunc("/ovsflows", ovsflows.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/ovsflowstats", ovsflowstats.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/ovsdatapathflows", ovsdatapathflows.HandleFunc(aq))
 	s.Handler.NonGoRestfulMux.HandleFunc("/ovstracing", ovstracing.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/encryptionstatus", encryption.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/drain", drain.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/cniconflicts", cniconflict.HandleFunc(aq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/conntrack", conntrack.HandleFunc(aq))
 }
 
 func installAPIGroup(s *genericapiserver.GenericAPIServer, aq agentquerier.AgentQuerier, npq querier.AgentNetworkPolicyInfoQuerier) error {
@@ -92,8 +124,8 @@ func installAPIGroup(s *genericapiserver.GenericAPIServer, aq agentquerier.Agent
 
 // New creates an APIServer for running in antrea agent.
 func New(aq agentquerier.AgentQuerier, npq querier.AgentNetworkPolicyInfoQuerier, bindPort int,
-	enableMetrics bool, kubeconfig string, cipherSuites []uint16, tlsMinVersion uint16) (*agentAPIServer, error) {
-	cfg, err := newConfig(bindPort, enableMetrics, kubeconfig)
+	enableMetrics bool, kubeconfig string, cipherSuites []uint16, tlsMinVersion uint16, debugAPIConfig debugapi.Config) (*agentAPIServer, error) {
+	cfg, err := newConfig(bindPort, enableMetrics, kubeconfig, debugAPIConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +142,7 @@ func New(aq agentquerier.AgentQuerier, npq querier.AgentNetworkPolicyInfoQuerier
 	return &agentAPIServer{GenericAPIServer: s}, nil
 }
 
-func newConfig(bindPort int, enableMetrics bool, kubeconfig string) (*genericapiserver.CompletedConfig, error) {
+func newConfig(bindPort int, enableMetrics bool, kubeconfig string, debugAPIConfig debugapi.Config) (*genericapiserver.CompletedConfig, error) {
 	secureServing := genericoptions.NewSecureServingOptions().WithLoopback()
 	authentication := genericoptions.NewDelegatingAuthenticationOptions()
 	authorization := genericoptions.NewDelegatingAuthorizationOptions().WithAlwaysAllowPaths("/healthz", "/livez", "/readyz")
@@ -155,6 +187,10 @@ func newConfig(bindPort int, enableMetrics bool, kubeconfig string) (*genericapi
 		GitCommit:    antreaversion.GetGitSHA(),
 	}
 	serverConfig.EnableMetrics = enableMetrics
+	debugAPIFilter := debugapi.NewFilter(debugAPIPaths, debugAPIConfig)
+	serverConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return genericapiserver.DefaultBuildHandlerChain(debugAPIFilter.Wrap(apiHandler), c)
+	}
 
 	completedServerCfg := serverConfig.Complete(nil)
 	return &completedServerCfg, nil
@@ -75,6 +75,10 @@ func (r AntreaAgentInfoResponse) GetAgentConditionStr() string {
 	}
 	agentCondition := "Healthy"
 	for _, cond := range r.AgentConditions {
+		// DatapathDrained is False while the datapath is being drained, which does not affect the health of the agent.
+		if cond.Type == v1beta1.DatapathDrained {
+			continue
+		}
 		if cond.Status == corev1.ConditionUnknown {
 			agentCondition = "Unknown"
 		}
@@ -0,0 +1,102 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"k8s.io/klog"
+
+	agentcniconflict "github.com/vmware-tanzu/antrea/pkg/agent/cniconflict"
+	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+)
+
+const actionCleanup = "cleanup"
+
+// Response is the response struct of cniconflicts command.
+type Response struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+func newResponses(conflicts []agentcniconflict.Conflict) []Response {
+	resps := make([]Response, 0, len(conflicts))
+	for _, c := range conflicts {
+		resps = append(resps, Response{Type: string(c.Type), Name: c.Name, Owner: c.Owner})
+	}
+	return resps
+}
+
+// HandleFunc returns the function which can handle API requests to
+// "/cniconflicts". The Node is scanned for the resources left by other CNIs,
+// which are removed if the "action" parameter of a POST request is "cleanup",
+// and the remaining resources are returned.
+func HandleFunc(aq agentquerier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := aq.GetCNIConflictScanner()
+		if s == nil {
+			http.Error(w, "CNI conflict detection is not supported when Antrea is chained with another CNI", http.StatusNotImplemented)
+			return
+		}
+		action := r.URL.Query().Get("action")
+		if action != "" && r.Method != http.MethodPost {
+			http.Error(w, "the cleanup action must be requested with the POST method", http.StatusMethodNotAllowed)
+			return
+		}
+		var conflicts []agentcniconflict.Conflict
+		var err error
+		switch action {
+		case "":
+			if conflicts, err = s.Scan(); err != nil {
+				klog.Errorf("Failed to scan the Node for resources of other CNIs: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case actionCleanup:
+			if conflicts, err = s.Cleanup(); err != nil {
+				klog.Errorf("Failed to remove the resources of other CNIs: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		default:
+			http.Error(w, "unsupported action "+strconv.Quote(action), http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(newResponses(conflicts))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			klog.Errorf("Error when encoding CNI conflicts to json: %v", err)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"TYPE", "NAME", "OWNER"}
+}
+
+func (r Response) GetTableRow(maxColumnLength int) []string {
+	return []string{r.Type, r.Name, r.Owner}
+}
+
+func (r Response) SortRows() bool {
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentcniconflict "github.com/vmware-tanzu/antrea/pkg/agent/cniconflict"
+	aqtest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+)
+
+func serve(t *testing.T, aq *aqtest.MockAgentQuerier, method, query string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, query, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HandleFunc(aq).ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestChaining(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetCNIConflictScanner().Return(nil)
+
+	recorder := serve(t, aq, http.MethodPost, "?action=cleanup")
+	assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+}
+
+func TestCleanupMethodNotAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetCNIConflictScanner().Return(agentcniconflict.NewScanner("", ""))
+
+	recorder := serve(t, aq, http.MethodGet, "?action=cleanup")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestBadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetCNIConflictScanner().Return(agentcniconflict.NewScanner("", ""))
+
+	recorder := serve(t, aq, http.MethodPost, "?action=delete")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestNewResponses(t *testing.T) {
+	conflicts := []agentcniconflict.Conflict{
+		{Type: agentcniconflict.CNIConfFile, Name: "/host/etc/cni/net.d/10-flannel.conflist", Owner: "flannel"},
+		{Type: agentcniconflict.IPTablesChain, Name: "nat/FLANNEL-POSTRTG", Owner: "flannel"},
+	}
+	assert.Equal(t, []Response{
+		{Type: "CNIConfFile", Name: "/host/etc/cni/net.d/10-flannel.conflist", Owner: "flannel"},
+		{Type: "IPTablesChain", Name: "nat/FLANNEL-POSTRTG", Owner: "flannel"},
+	}, newResponses(conflicts))
+	assert.Empty(t, newResponses(nil))
+}
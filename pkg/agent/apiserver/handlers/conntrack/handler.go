@@ -0,0 +1,183 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conntrack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+)
+
+var protocolNames = map[uint8]string{
+	1:   "ICMP",
+	6:   "TCP",
+	17:  "UDP",
+	58:  "ICMPv6",
+	132: "SCTP",
+}
+
+// Response is the response struct of conntrack command. Destination is the
+// original destination of the connection, e.g. a Service ClusterIP, and
+// TranslatedDestination the destination after DNAT, e.g. the Endpoint of the
+// Service, if it is different. The Pods are only set for the local Pods.
+type Response struct {
+	Protocol              string `json:"protocol"`
+	Source                string `json:"source"`
+	Destination           string `json:"destination"`
+	TranslatedDestination string `json:"translatedDestination,omitempty"`
+	SourcePod             string `json:"sourcePod,omitempty"`
+	DestinationPod        string `json:"destinationPod,omitempty"`
+	Packets               uint64 `json:"packets"`
+	Bytes                 uint64 `json:"bytes"`
+	ReplyPackets          uint64 `json:"replyPackets"`
+	ReplyBytes            uint64 `json:"replyBytes"`
+	Timeout               uint32 `json:"timeout"`
+}
+
+func protocolName(protocol uint8) string {
+	if name, exists := protocolNames[protocol]; exists {
+		return name
+	}
+	return strconv.Itoa(int(protocol))
+}
+
+// endpoint returns the IP and port of a connection endpoint. The port is
+// omitted for the protocols without ports, e.g. ICMP.
+func endpoint(ip net.IP, port uint16) string {
+	if port == 0 {
+		return ip.String()
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// localPod returns the Namespace and the name of the local Pod which has the IP,
+// or empty strings if there is no such Pod.
+func localPod(ifaceStore interfacestore.InterfaceStore, ip net.IP) (string, string) {
+	iface, exists := ifaceStore.GetInterfaceByIP(ip.String())
+	if !exists || iface.Type != interfacestore.ContainerInterface {
+		return "", ""
+	}
+	return iface.PodNamespace, iface.PodName
+}
+
+// newResponses converts the connections to responses, keeping only the
+// connections of the Pod if name is set, or of the Namespace if namespace is
+// set.
+func newResponses(conns []*flowexporter.Connection, ifaceStore interfacestore.InterfaceStore, name, namespace string) []Response {
+	resps := make([]Response, 0, len(conns))
+	matchPod := func(podNamespace, podName string) bool {
+		return podName != "" && (namespace == "" || podNamespace == namespace) && (name == "" || podName == name)
+	}
+	for _, conn := range conns {
+		srcNamespace, srcName := localPod(ifaceStore, conn.TupleOrig.SourceAddress)
+		// The source of the reply is the actual destination of the connection, after DNAT.
+		dstNamespace, dstName := localPod(ifaceStore, conn.TupleReply.SourceAddress)
+		if (name != "" || namespace != "") && !matchPod(srcNamespace, srcName) && !matchPod(dstNamespace, dstName) {
+			continue
+		}
+		resp := Response{
+			Protocol:     protocolName(conn.TupleOrig.Protocol),
+			Source:       endpoint(conn.TupleOrig.SourceAddress, conn.TupleOrig.SourcePort),
+			Destination:  endpoint(conn.TupleOrig.DestinationAddress, conn.TupleOrig.DestinationPort),
+			Packets:      conn.OriginalPackets,
+			Bytes:        conn.OriginalBytes,
+			ReplyPackets: conn.ReversePackets,
+			ReplyBytes:   conn.ReverseBytes,
+			Timeout:      conn.Timeout,
+		}
+		if translated := endpoint(conn.TupleReply.SourceAddress, conn.TupleReply.SourcePort); translated != resp.Destination {
+			resp.TranslatedDestination = translated
+		}
+		if srcName != "" {
+			resp.SourcePod = srcNamespace + "/" + srcName
+		}
+		if dstName != "" {
+			resp.DestinationPod = dstNamespace + "/" + dstName
+		}
+		resps = append(resps, resp)
+	}
+	return resps
+}
+
+// HandleFunc returns the function which can handle API requests to
+// "/conntrack". The connections of the conntrack zones of Antrea are returned,
+// optionally filtered by local Pod with the "name" and "namespace" parameters.
+func HandleFunc(aq agentquerier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		namespace := r.URL.Query().Get("namespace")
+		if name != "" && namespace == "" {
+			http.Error(w, "namespace must be provided with Pod name", http.StatusBadRequest)
+			return
+		}
+		dumper := aq.GetConnTrackDumper()
+		if dumper == nil {
+			http.Error(w, "dumping conntrack is not supported with the OVS datapath type", http.StatusNotImplemented)
+			return
+		}
+		nodeConfig := aq.GetNodeConfig()
+		encapMode := aq.GetNetworkConfig().TrafficEncapMode
+		var zones []uint16
+		if config.IsIPv4Enabled(nodeConfig, encapMode) {
+			zones = append(zones, openflow.CtZone)
+		}
+		if config.IsIPv6Enabled(nodeConfig, encapMode) {
+			zones = append(zones, openflow.CtZoneV6)
+		}
+		var conns []*flowexporter.Connection
+		for _, zone := range zones {
+			zoneConns, err := dumper.DumpZone(zone)
+			if err != nil {
+				klog.Errorf("Failed to dump conntrack zone %d: %v", zone, err)
+				http.Error(w, fmt.Sprintf("failed to dump conntrack zone %d: %v", zone, err), http.StatusInternalServerError)
+				return
+			}
+			conns = append(conns, zoneConns...)
+		}
+
+		err := json.NewEncoder(w).Encode(newResponses(conns, aq.GetInterfaceStore(), name, namespace))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			klog.Errorf("Error when encoding conntrack connections to json: %v", err)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"PROTOCOL", "SOURCE", "DESTINATION", "TRANSLATED-DESTINATION", "SOURCE-POD", "DESTINATION-POD", "PACKETS", "BYTES", "REPLY-PACKETS", "REPLY-BYTES", "TIMEOUT"}
+}
+
+func (r Response) GetTableRow(_ int) []string {
+	return []string{r.Protocol, r.Source, r.Destination, r.TranslatedDestination, r.SourcePod, r.DestinationPod,
+		strconv.FormatUint(r.Packets, 10), strconv.FormatUint(r.Bytes, 10), strconv.FormatUint(r.ReplyPackets, 10),
+		strconv.FormatUint(r.ReplyBytes, 10), strconv.FormatUint(uint64(r.Timeout), 10)}
+}
+
+func (r Response) SortRows() bool {
+	return true
+}
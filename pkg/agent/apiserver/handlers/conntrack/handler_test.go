@@ -0,0 +1,203 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conntrack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+	connectionstest "github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/connections/testing"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	queriertest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+)
+
+// There are 2 connections: pod1 in namespaceA connecting to a Service whose
+// Endpoint is on another Node, and an external client pinging pod2 in
+// namespaceB.
+var testConns = []*flowexporter.Connection{
+	{
+		TupleOrig: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP("10.10.0.2"),
+			DestinationAddress: net.ParseIP("10.96.0.10"),
+			Protocol:           6,
+			SourcePort:         40000,
+			DestinationPort:    80,
+		},
+		TupleReply: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP("10.10.1.5"),
+			DestinationAddress: net.ParseIP("10.10.0.2"),
+			Protocol:           6,
+			SourcePort:         8080,
+			DestinationPort:    40000,
+		},
+		OriginalPackets: 10,
+		OriginalBytes:   1000,
+		ReversePackets:  5,
+		ReverseBytes:    500,
+		Timeout:         86400,
+	},
+	{
+		TupleOrig: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP("192.168.1.1"),
+			DestinationAddress: net.ParseIP("10.10.0.3"),
+			Protocol:           1,
+		},
+		TupleReply: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP("10.10.0.3"),
+			DestinationAddress: net.ParseIP("192.168.1.1"),
+			Protocol:           1,
+		},
+		OriginalPackets: 1,
+		OriginalBytes:   84,
+		ReversePackets:  1,
+		ReverseBytes:    84,
+		Timeout:         30,
+	},
+}
+
+var responses = []Response{
+	{
+		Protocol:              "TCP",
+		Source:                "10.10.0.2:40000",
+		Destination:           "10.96.0.10:80",
+		TranslatedDestination: "10.10.1.5:8080",
+		SourcePod:             "namespaceA/pod1",
+		Packets:               10,
+		Bytes:                 1000,
+		ReplyPackets:          5,
+		ReplyBytes:            500,
+		Timeout:               86400,
+	},
+	{
+		Protocol:       "ICMP",
+		Source:         "192.168.1.1",
+		Destination:    "10.10.0.3",
+		DestinationPod: "namespaceB/pod2",
+		Packets:        1,
+		Bytes:          84,
+		ReplyPackets:   1,
+		ReplyBytes:     84,
+		Timeout:        30,
+	},
+}
+
+func newTestInterfaceStore() interfacestore.InterfaceStore {
+	ifaceStore := interfacestore.NewInterfaceStore()
+	ifaceStore.AddInterface(interfacestore.NewContainerInterface("interface1", "containerid1", "pod1", "namespaceA", nil, []net.IP{net.ParseIP("10.10.0.2")}))
+	ifaceStore.AddInterface(interfacestore.NewContainerInterface("interface2", "containerid2", "pod2", "namespaceB", nil, []net.IP{net.ParseIP("10.10.0.3")}))
+	return ifaceStore
+}
+
+func TestConntrackQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, podCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	nodeConfig := &config.NodeConfig{PodIPv4CIDR: podCIDR}
+	networkConfig := &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}
+
+	testcases := map[string]struct {
+		query           string
+		dumpErr         error
+		expectedStatus  int
+		expectedContent []Response
+	}{
+		"All connections": {
+			query:           "",
+			expectedStatus:  http.StatusOK,
+			expectedContent: responses,
+		},
+		"Connections of a Pod": {
+			query:           "?name=pod1&namespace=namespaceA",
+			expectedStatus:  http.StatusOK,
+			expectedContent: []Response{responses[0]},
+		},
+		"Connections of a Namespace": {
+			query:           "?namespace=namespaceB",
+			expectedStatus:  http.StatusOK,
+			expectedContent: []Response{responses[1]},
+		},
+		"No connection of the Pod": {
+			query:           "?name=pod1&namespace=namespaceB",
+			expectedStatus:  http.StatusOK,
+			expectedContent: []Response{},
+		},
+		"Pod name without namespace": {
+			query:          "?name=pod1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"Dump error": {
+			query:          "",
+			dumpErr:        fmt.Errorf("netlink error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for k, tc := range testcases {
+		dumper := connectionstest.NewMockConnTrackDumper(ctrl)
+		if tc.dumpErr != nil {
+			dumper.EXPECT().DumpZone(uint16(openflow.CtZone)).Return(nil, tc.dumpErr)
+		} else {
+			dumper.EXPECT().DumpZone(uint16(openflow.CtZone)).Return(testConns, nil).AnyTimes()
+		}
+
+		q := queriertest.NewMockAgentQuerier(ctrl)
+		q.EXPECT().GetConnTrackDumper().Return(dumper).AnyTimes()
+		q.EXPECT().GetNodeConfig().Return(nodeConfig).AnyTimes()
+		q.EXPECT().GetNetworkConfig().Return(networkConfig).AnyTimes()
+		q.EXPECT().GetInterfaceStore().Return(newTestInterfaceStore()).AnyTimes()
+		handler := HandleFunc(q)
+
+		req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+		assert.Nil(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, tc.expectedStatus, recorder.Code, k)
+
+		if tc.expectedStatus == http.StatusOK {
+			var received []Response
+			err = json.Unmarshal(recorder.Body.Bytes(), &received)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedContent, received, k)
+		}
+	}
+}
+
+func TestConntrackQueryNotSupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q := queriertest.NewMockAgentQuerier(ctrl)
+	q.EXPECT().GetConnTrackDumper().Return(nil)
+	handler := HandleFunc(q)
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/klog"
+
+	agentdrain "github.com/vmware-tanzu/antrea/pkg/agent/drain"
+	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+)
+
+const (
+	actionStart = "start"
+	actionStop  = "stop"
+)
+
+// Response is the response struct of drain command.
+type Response struct {
+	State string `json:"state"`
+	// StartTime is the time at which the drain was started, in RFC3339
+	// format. It is empty if the datapath is not in drain mode.
+	StartTime   string `json:"startTime,omitempty"`
+	GracePeriod string `json:"gracePeriod,omitempty"`
+	// SafeToReboot is true once the established Service connections are
+	// closed, or the grace period has expired.
+	SafeToReboot bool `json:"safeToReboot"`
+}
+
+func newResponse(status agentdrain.Status) Response {
+	resp := Response{
+		State:        string(status.State),
+		SafeToReboot: status.State == agentdrain.Drained,
+	}
+	if status.State != agentdrain.NotDraining {
+		resp.StartTime = status.StartTime.UTC().Format(time.RFC3339)
+		resp.GracePeriod = status.GracePeriod.String()
+	}
+	return resp
+}
+
+// HandleFunc returns the function which can handle API requests to "/drain".
+// The drain mode is started or stopped according to the "action" parameter of
+// a POST request, and the current drain status is returned.
+func HandleFunc(aq agentquerier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := aq.GetDrainController()
+		if c == nil {
+			http.Error(w, "datapath drain is not supported", http.StatusNotImplemented)
+			return
+		}
+		action := r.URL.Query().Get("action")
+		if action != "" && r.Method != http.MethodPost {
+			http.Error(w, "the drain action must be requested with the POST method", http.StatusMethodNotAllowed)
+			return
+		}
+		switch action {
+		case "":
+		case actionStart:
+			gracePeriod := agentdrain.DefaultGracePeriod
+			if gp := r.URL.Query().Get("grace-period"); gp != "" {
+				var err error
+				if gracePeriod, err = time.ParseDuration(gp); err != nil || gracePeriod < 0 {
+					http.Error(w, "invalid grace period "+strconv.Quote(gp), http.StatusBadRequest)
+					return
+				}
+			}
+			if err := c.Start(gracePeriod); err != nil {
+				klog.Errorf("Failed to start draining the datapath: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case actionStop:
+			if err := c.Stop(); err != nil {
+				klog.Errorf("Failed to stop draining the datapath: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		default:
+			http.Error(w, "unsupported action "+strconv.Quote(action), http.StatusBadRequest)
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(newResponse(c.GetStatus()))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			klog.Errorf("Error when encoding drain status to json: %v", err)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"STATE", "START-TIME", "GRACE-PERIOD", "SAFE-TO-REBOOT"}
+}
+
+func (r Response) GetTableRow(maxColumnLength int) []string {
+	return []string{r.State, r.StartTime, r.GracePeriod, strconv.FormatBool(r.SafeToReboot)}
+}
+
+func (r Response) SortRows() bool {
+	return false
+}
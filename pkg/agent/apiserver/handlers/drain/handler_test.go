@@ -0,0 +1,91 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentdrain "github.com/vmware-tanzu/antrea/pkg/agent/drain"
+	aqtest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
+)
+
+func serve(t *testing.T, aq *aqtest.MockAgentQuerier, method, query string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, query, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HandleFunc(aq).ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	routeClient := routetest.NewMockInterface(ctrl)
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetDrainController().Return(agentdrain.NewController(routeClient, nil, nil)).AnyTimes()
+
+	var resp Response
+	recorder := serve(t, aq, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, Response{State: string(agentdrain.NotDraining)}, resp)
+
+	routeClient.EXPECT().SetDrainMode(true)
+	recorder = serve(t, aq, http.MethodPost, "?action=start&grace-period=5m")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, string(agentdrain.Draining), resp.State)
+	assert.Equal(t, "5m0s", resp.GracePeriod)
+	assert.NotEmpty(t, resp.StartTime)
+	assert.False(t, resp.SafeToReboot)
+
+	routeClient.EXPECT().SetDrainMode(false)
+	recorder = serve(t, aq, http.MethodPost, "?action=stop")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, Response{State: string(agentdrain.NotDraining)}, resp)
+}
+
+func TestBadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetDrainController().Return(agentdrain.NewController(routetest.NewMockInterface(ctrl), nil, nil)).AnyTimes()
+
+	for _, query := range []string{"?action=pause", "?action=start&grace-period=5", "?action=start&grace-period=-1s"} {
+		recorder := serve(t, aq, http.MethodPost, query)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, "Unexpected status code for query %s", query)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	aq := aqtest.NewMockAgentQuerier(ctrl)
+	aq.EXPECT().GetDrainController().Return(agentdrain.NewController(routetest.NewMockInterface(ctrl), nil, nil)).AnyTimes()
+
+	for _, query := range []string{"?action=start", "?action=stop"} {
+		recorder := serve(t, aq, http.MethodGet, query)
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code, "Unexpected status code for query %s", query)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipsec"
+	"github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+)
+
+// wireGuardRejectAfterTime is the time after which WireGuard stops using the
+// session keys negotiated by a handshake. A peer whose latest handshake is
+// older than that does not have valid keys to encrypt traffic with.
+const wireGuardRejectAfterTime = 180 * time.Second
+
+// listSecurityAssociations is a variable so that it can be overridden in
+// tests.
+var listSecurityAssociations = ipsec.ListSecurityAssociations
+
+// Response describes the response struct of encryptionstatus command.
+type Response struct {
+	NodeName  string `json:"nodeName,omitempty" antctl:"name,Name of the peer Node"`
+	PeerIP    string `json:"peerIP,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	Encrypted bool   `json:"encrypted"`
+	// Status describes the IPsec SAs or the WireGuard handshake of the peer.
+	Status string `json:"status,omitempty"`
+	// LastRekeyTime is the time at which the keys used to encrypt the
+	// traffic to the peer were last rotated, if known.
+	LastRekeyTime string `json:"lastRekeyTime,omitempty"`
+}
+
+func getIPsecStatus(aq querier.AgentQuerier) ([]Response, error) {
+	sas, err := listSecurityAssociations()
+	if err != nil {
+		return nil, err
+	}
+	var resps []Response
+	for _, tunnel := range aq.GetInterfaceStore().GetInterfacesByType(interfacestore.TunnelInterface) {
+		tunnelConfig := tunnel.TunnelInterfaceConfig
+		// The default tunnel port is shared by all the Nodes and is not
+		// encrypted.
+		if tunnelConfig.NodeName == "" || tunnelConfig.PSK == "" {
+			continue
+		}
+		var inbound, outbound int
+		// The SAs of a tunnel are replaced by new ones when the keys are
+		// rotated, so the most recent SA gives the time of the last rekey.
+		var lastRekeyTime time.Time
+		for _, sa := range sas {
+			if sa.Dst.Equal(tunnelConfig.RemoteIP) {
+				outbound++
+			} else if sa.Src.Equal(tunnelConfig.RemoteIP) {
+				inbound++
+			} else {
+				continue
+			}
+			if sa.AddTime.After(lastRekeyTime) {
+				lastRekeyTime = sa.AddTime
+			}
+		}
+		resp := Response{
+			NodeName:  tunnelConfig.NodeName,
+			PeerIP:    tunnelConfig.RemoteIP.String(),
+			Mode:      config.TrafficEncryptionModeIPSec.String(),
+			Encrypted: inbound > 0 && outbound > 0,
+			Status:    fmt.Sprintf("%d inbound SAs, %d outbound SAs", inbound, outbound),
+		}
+		if !lastRekeyTime.IsZero() {
+			resp.LastRekeyTime = lastRekeyTime.UTC().Format(time.RFC3339)
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+func getWireGuardStatus(aq querier.AgentQuerier, now time.Time) ([]Response, error) {
+	client := aq.GetWireGuardClient()
+	if client == nil {
+		return nil, nil
+	}
+	peers, err := client.GetPeerStatus()
+	if err != nil {
+		return nil, err
+	}
+	var resps []Response
+	for _, peer := range peers {
+		resp := Response{
+			NodeName: peer.NodeName,
+			PeerIP:   peer.Endpoint,
+			Mode:     config.TrafficEncryptionModeWireGuard.String(),
+			Status:   "no handshake",
+		}
+		if !peer.LatestHandshake.IsZero() {
+			age := now.Sub(peer.LatestHandshake).Truncate(time.Second)
+			resp.Encrypted = age < wireGuardRejectAfterTime
+			resp.Status = fmt.Sprintf("latest handshake %s ago", age)
+			resp.LastRekeyTime = peer.LatestHandshake.UTC().Format(time.RFC3339)
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// HandleFunc returns the function which can handle queries issued by the encryptionstatus command.
+func HandleFunc(aq querier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+
+		var resps []Response
+		var err error
+		switch aq.GetNetworkConfig().TrafficEncryptionMode {
+		case config.TrafficEncryptionModeIPSec:
+			resps, err = getIPsecStatus(aq)
+		case config.TrafficEncryptionModeWireGuard:
+			resps, err = getWireGuardStatus(aq, time.Now())
+		default:
+			http.Error(w, "traffic encryption is not enabled", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			klog.Errorf("Failed to get traffic encryption status: %v", err)
+			http.Error(w, "failed to get traffic encryption status", http.StatusInternalServerError)
+			return
+		}
+
+		if len(name) > 0 {
+			var filtered []Response
+			for _, resp := range resps {
+				if resp.NodeName == name {
+					filtered = append(filtered, resp)
+				}
+			}
+			if len(filtered) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			resps = filtered
+		}
+		if resps == nil {
+			resps = []Response{}
+		}
+		err = json.NewEncoder(w).Encode(resps)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"NODE", "PEER-IP", "MODE", "ENCRYPTED", "STATUS", "LAST-REKEY"}
+}
+
+func (r Response) GetTableRow(_ int) []string {
+	return []string{r.NodeName, r.PeerIP, r.Mode, strconv.FormatBool(r.Encrypted), r.Status, r.LastRekeyTime}
+}
+
+func (r Response) SortRows() bool {
+	return true
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	interfacestoretest "github.com/vmware-tanzu/antrea/pkg/agent/interfacestore/testing"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipsec"
+	queriertest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+func TestIPsecEncryptionStatusQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	localIP := net.ParseIP("192.168.0.1")
+	node2IP := net.ParseIP("192.168.0.2")
+	node3IP := net.ParseIP("192.168.0.3")
+	listSecurityAssociations = func() ([]ipsec.SecurityAssociation, error) {
+		return []ipsec.SecurityAssociation{
+			{Src: localIP, Dst: node2IP, SPI: 1, AddTime: time.Unix(1600000000, 0)},
+			{Src: node2IP, Dst: localIP, SPI: 2, AddTime: time.Unix(1600000001, 0)},
+			{Src: localIP, Dst: node3IP, SPI: 3},
+		}, nil
+	}
+	defer func() {
+		listSecurityAssociations = ipsec.ListSecurityAssociations
+	}()
+	tunnels := []*interfacestore.InterfaceConfig{
+		interfacestore.NewTunnelInterface("antrea-tun0", ovsconfig.GeneveTunnel, nil, false),
+		interfacestore.NewIPSecTunnelInterface("node2-abcdef", ovsconfig.GeneveTunnel, "node2", node2IP, "psk"),
+		interfacestore.NewIPSecTunnelInterface("node3-abcdef", ovsconfig.GeneveTunnel, "node3", node3IP, "psk"),
+	}
+
+	testcases := map[string]struct {
+		query           string
+		expectedStatus  int
+		expectedContent []Response
+	}{
+		"List encryption status": {
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedContent: []Response{
+				{NodeName: "node2", PeerIP: "192.168.0.2", Mode: "ipsec", Encrypted: true, Status: "1 inbound SAs, 1 outbound SAs", LastRekeyTime: "2020-09-13T12:26:41Z"},
+				{NodeName: "node3", PeerIP: "192.168.0.3", Mode: "ipsec", Encrypted: false, Status: "0 inbound SAs, 1 outbound SAs"},
+			},
+		},
+		"Hit encryption status query": {
+			query:          "?name=node3",
+			expectedStatus: http.StatusOK,
+			expectedContent: []Response{
+				{NodeName: "node3", PeerIP: "192.168.0.3", Mode: "ipsec", Encrypted: false, Status: "0 inbound SAs, 1 outbound SAs"},
+			},
+		},
+		"Miss encryption status query": {
+			query:          "?name=node4",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for k, tc := range testcases {
+		i := interfacestoretest.NewMockInterfaceStore(ctrl)
+		i.EXPECT().GetInterfacesByType(interfacestore.TunnelInterface).Return(tunnels).AnyTimes()
+
+		q := queriertest.NewMockAgentQuerier(ctrl)
+		q.EXPECT().GetNetworkConfig().Return(&config.NetworkConfig{TrafficEncryptionMode: config.TrafficEncryptionModeIPSec}).AnyTimes()
+		q.EXPECT().GetInterfaceStore().Return(i).AnyTimes()
+		handler := HandleFunc(q)
+
+		req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+		assert.Nil(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, tc.expectedStatus, recorder.Code, k)
+
+		if tc.expectedStatus == http.StatusOK {
+			var received []Response
+			err = json.Unmarshal(recorder.Body.Bytes(), &received)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedContent, received, k)
+		}
+	}
+}
+
+func TestEncryptionStatusQueryNotEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q := queriertest.NewMockAgentQuerier(ctrl)
+	q.EXPECT().GetNetworkConfig().Return(&config.NetworkConfig{TrafficEncryptionMode: config.TrafficEncryptionModeNone})
+	handler := HandleFunc(q)
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
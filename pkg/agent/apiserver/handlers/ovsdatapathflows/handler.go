@@ -0,0 +1,96 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdatapathflows
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"k8s.io/klog"
+
+	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+)
+
+// Response is the response struct of ovsdatapathflows command. Offloaded is
+// "yes" if the flow is offloaded to the hardware, "partial" if only its match
+// is offloaded, or "no".
+type Response struct {
+	Match     string `json:"match"`
+	Actions   string `json:"actions"`
+	Packets   uint64 `json:"packets"`
+	Bytes     uint64 `json:"bytes"`
+	Offloaded string `json:"offloaded"`
+	Datapath  string `json:"datapath,omitempty"`
+}
+
+// HandleFunc returns the function which can handle API requests to
+// "/ovsdatapathflows". The flows of the OVS datapath are returned along with
+// their hardware offload status, optionally filtered with the "offloaded"
+// parameter.
+func HandleFunc(aq agentquerier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var offloadedFilter *bool
+		if offloaded := r.URL.Query().Get("offloaded"); offloaded != "" {
+			value, err := strconv.ParseBool(offloaded)
+			if err != nil {
+				http.Error(w, "invalid offloaded value "+offloaded, http.StatusBadRequest)
+				return
+			}
+			offloadedFilter = &value
+		}
+		flows, err := aq.GetOVSCtlClient().DumpDatapathFlows()
+		if err != nil {
+			klog.Errorf("Failed to dump datapath flows: %v", err)
+			http.Error(w, "OVS datapath flow dumping failed", http.StatusInternalServerError)
+			return
+		}
+		resps := make([]Response, 0, len(flows))
+		for _, flow := range flows {
+			if offloadedFilter != nil && *offloadedFilter != (flow.Offloaded != "no") {
+				continue
+			}
+			resps = append(resps, Response{
+				Match:     flow.Match,
+				Actions:   flow.Actions,
+				Packets:   flow.Packets,
+				Bytes:     flow.Bytes,
+				Offloaded: flow.Offloaded,
+				Datapath:  flow.Datapath,
+			})
+		}
+
+		err = json.NewEncoder(w).Encode(resps)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			klog.Errorf("Error when encoding datapath flows to json: %v", err)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"OFFLOADED", "DATAPATH", "PACKETS", "BYTES", "MATCH", "ACTIONS"}
+}
+
+func (r Response) GetTableRow(_ int) []string {
+	return []string{r.Offloaded, r.Datapath, strconv.FormatUint(r.Packets, 10), strconv.FormatUint(r.Bytes, 10), r.Match, r.Actions}
+}
+
+func (r Response) SortRows() bool {
+	return false
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdatapathflows
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	aqtest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
+)
+
+func TestBadRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "?offloaded=maybe", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HandleFunc(nil).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestDatapathFlows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flows := []ovsctl.DatapathFlow{
+		{Match: "recirc_id(0),in_port(2),eth_type(0x0800),ipv4(frag=no)", Actions: "ct(zone=65520),recirc(0x1)", Packets: 10, Bytes: 980, Offloaded: "yes", Datapath: "tc"},
+		{Match: "recirc_id(0),in_port(3),eth_type(0x0806)", Actions: "2", Packets: 1, Bytes: 42, Offloaded: "no", Datapath: "ovs"},
+	}
+	offloadedResponse := Response{Match: "recirc_id(0),in_port(2),eth_type(0x0800),ipv4(frag=no)", Actions: "ct(zone=65520),recirc(0x1)", Packets: 10, Bytes: 980, Offloaded: "yes", Datapath: "tc"}
+	notOffloadedResponse := Response{Match: "recirc_id(0),in_port(3),eth_type(0x0806)", Actions: "2", Packets: 1, Bytes: 42, Offloaded: "no", Datapath: "ovs"}
+
+	testcases := []struct {
+		name              string
+		query             string
+		expectedResponses []Response
+	}{
+		{
+			name:              "all flows",
+			query:             "",
+			expectedResponses: []Response{offloadedResponse, notOffloadedResponse},
+		},
+		{
+			name:              "offloaded flows",
+			query:             "?offloaded=true",
+			expectedResponses: []Response{offloadedResponse},
+		},
+		{
+			name:              "not offloaded flows",
+			query:             "?offloaded=false",
+			expectedResponses: []Response{notOffloadedResponse},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ovsctlClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+			ovsctlClient.EXPECT().DumpDatapathFlows().Return(flows, nil)
+			q := aqtest.NewMockAgentQuerier(ctrl)
+			q.EXPECT().GetOVSCtlClient().Return(ovsctlClient)
+
+			req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			HandleFunc(q).ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var received []Response
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+			assert.Equal(t, tc.expectedResponses, received)
+		})
+	}
+}
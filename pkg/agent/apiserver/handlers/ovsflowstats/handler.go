@@ -0,0 +1,154 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsflowstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	agentquerier "github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+const (
+	groupByTable  = "table"
+	groupByCookie = "cookie"
+)
+
+// Response is the response struct of ovsflowstats command.
+type Response struct {
+	// Table is the name of the flow table, or its number if the table has no
+	// name. It is only set when the statistics are grouped by table.
+	Table string `json:"table,omitempty"`
+	// Cookie is the cookie of the flows in hexadecimal. It is only set when
+	// the statistics are grouped by cookie.
+	Cookie string `json:"cookie,omitempty"`
+	// Category is the category of the flows encoded in their cookie.
+	Category    string `json:"category,omitempty"`
+	FlowCount   uint64 `json:"flowCount"`
+	PacketCount uint64 `json:"packetCount"`
+	ByteCount   uint64 `json:"byteCount"`
+}
+
+func getTableStats(aq agentquerier.AgentQuerier) ([]Response, error) {
+	stats, err := aq.GetOpenflowClient().GetFlowTableStats()
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]binding.TableIDType, 0, len(stats))
+	for table := range stats {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i] < tables[j]
+	})
+	resps := make([]Response, 0, len(tables))
+	for _, table := range tables {
+		name := openflow.GetFlowTableName(table)
+		if name == "" {
+			name = strconv.Itoa(int(table))
+		}
+		s := stats[table]
+		resps = append(resps, Response{
+			Table:       name,
+			FlowCount:   s.FlowCount,
+			PacketCount: s.PacketCount,
+			ByteCount:   s.ByteCount,
+		})
+	}
+	return resps, nil
+}
+
+func getCookieStats(aq agentquerier.AgentQuerier) ([]Response, error) {
+	stats, err := aq.GetOpenflowClient().GetFlowCookieStats()
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]uint64, 0, len(stats))
+	for c := range stats {
+		cookies = append(cookies, c)
+	}
+	sort.Slice(cookies, func(i, j int) bool {
+		return cookies[i] < cookies[j]
+	})
+	resps := make([]Response, 0, len(cookies))
+	for _, c := range cookies {
+		s := stats[c]
+		resps = append(resps, Response{
+			Cookie:      fmt.Sprintf("%#x", c),
+			Category:    cookie.ID(c).Category().String(),
+			FlowCount:   s.FlowCount,
+			PacketCount: s.PacketCount,
+			ByteCount:   s.ByteCount,
+		})
+	}
+	return resps, nil
+}
+
+// HandleFunc returns the function which can handle API requests to "/ovsflowstats".
+func HandleFunc(aq agentquerier.AgentQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var resps []Response
+		switch groupBy := r.URL.Query().Get("groupby"); groupBy {
+		case "", groupByTable:
+			resps, err = getTableStats(aq)
+		case groupByCookie:
+			resps, err = getCookieStats(aq)
+		default:
+			http.Error(w, "unsupported groupby value "+groupBy, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			klog.Errorf("Failed to dump flow statistics: %v", err)
+			http.Error(w, "OVS flow statistics dumping failed", http.StatusInternalServerError)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(resps)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	if r.Cookie != "" {
+		return []string{"COOKIE", "CATEGORY", "FLOWS", "PACKETS", "BYTES"}
+	}
+	return []string{"TABLE", "FLOWS", "PACKETS", "BYTES"}
+}
+
+func (r Response) GetTableRow(maxColumnLength int) []string {
+	counters := []string{strconv.FormatUint(r.FlowCount, 10), strconv.FormatUint(r.PacketCount, 10), strconv.FormatUint(r.ByteCount, 10)}
+	if r.Cookie != "" {
+		return append([]string{r.Cookie, r.Category}, counters...)
+	}
+	return append([]string{r.Table}, counters...)
+}
+
+func (r Response) SortRows() bool {
+	return false
+}
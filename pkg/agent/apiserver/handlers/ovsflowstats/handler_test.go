@@ -0,0 +1,99 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsflowstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	aqtest "github.com/vmware-tanzu/antrea/pkg/agent/querier/testing"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+func TestBadRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "?groupby=priority", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HandleFunc(nil).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestFlowStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Round 1, category Policy, object ID 1.
+	policyCookie := uint64(0x0001_0500_0000_0001)
+	tableStats := map[binding.TableIDType]*binding.FlowStatsSummary{
+		openflow.IngressRuleTable: {FlowCount: 3, PacketCount: 20, ByteCount: 2000},
+		openflow.ClassifierTable:  {FlowCount: 5, PacketCount: 100, ByteCount: 10000},
+		binding.TableIDType(254):  {FlowCount: 1},
+	}
+	cookieStats := map[uint64]*binding.FlowStatsSummary{
+		policyCookie: {FlowCount: 2, PacketCount: 10, ByteCount: 1000},
+	}
+
+	testcases := []struct {
+		name              string
+		query             string
+		expectedResponses []Response
+	}{
+		{
+			name:  "group by table by default",
+			query: "",
+			expectedResponses: []Response{
+				{Table: "Classification", FlowCount: 5, PacketCount: 100, ByteCount: 10000},
+				{Table: "IngressRule", FlowCount: 3, PacketCount: 20, ByteCount: 2000},
+				{Table: "254", FlowCount: 1},
+			},
+		},
+		{
+			name:  "group by cookie",
+			query: "?groupby=cookie",
+			expectedResponses: []Response{
+				{Cookie: "0x1050000000001", Category: "Policy", FlowCount: 2, PacketCount: 10, ByteCount: 1000},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ofClient := oftest.NewMockClient(ctrl)
+			q := aqtest.NewMockAgentQuerier(ctrl)
+			q.EXPECT().GetOpenflowClient().Return(ofClient)
+			if tc.query == "" {
+				ofClient.EXPECT().GetFlowTableStats().Return(tableStats, nil)
+			} else {
+				ofClient.EXPECT().GetFlowCookieStats().Return(cookieStats, nil)
+			}
+			req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			HandleFunc(q).ServeHTTP(recorder, req)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			var received []Response
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+			assert.Equal(t, tc.expectedResponses, received)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cniconflict detects the state left on the Node by other CNI plugins
+// or by previous Antrea installations: CNI configuration files, network
+// interfaces, iptables chains and OVS bridges. Such state may take precedence
+// over Antrea, e.g. a CNI configuration file sorted before the Antrea one is
+// used by the kubelet, or intercept the traffic of the Pods. The conflicts are
+// only reported when scanning, and removed when explicitly requested.
+package cniconflict
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/klog"
+)
+
+const (
+	// antreaCNIConfFile is the name of the CNI configuration file installed
+	// by the install-cni initContainer.
+	antreaCNIConfFile = "10-antrea.conflist"
+	// legacyAntreaCNIConfFile is the name of the CNI configuration file
+	// installed by previous Antrea versions. It is preferred by the kubelet
+	// over antreaCNIConfFile.
+	legacyAntreaCNIConfFile = "10-antrea.conf"
+
+	antreaOwner = "antrea"
+)
+
+// ResourceType is the type of a resource left on the Node.
+type ResourceType string
+
+const (
+	CNIConfFile   ResourceType = "CNIConfFile"
+	Interface     ResourceType = "Interface"
+	IPTablesChain ResourceType = "IPTablesChain"
+	OVSBridge     ResourceType = "OVSBridge"
+)
+
+// Conflict is a resource left on the Node by another CNI plugin or by a
+// previous Antrea installation.
+type Conflict struct {
+	Type ResourceType
+	// Name is the path of CNIConfFile resources, the name of Interface
+	// resources, "<table>/<chain>" for IPTablesChain resources, and the
+	// name of OVSBridge resources.
+	Name string
+	// Owner is the CNI plugin which is known to create the resource.
+	Owner string
+}
+
+// knownCNI is the signature of the resources created by a CNI plugin.
+type knownCNI struct {
+	name string
+	// pluginTypes are the types of the CNI plugins referenced by its CNI
+	// configuration files.
+	pluginTypes []string
+	// interfaces are the names of the network interfaces created on the
+	// Node.
+	interfaces []string
+	// chainPrefixes are the prefixes of the iptables chains created on the
+	// Node.
+	chainPrefixes []string
+}
+
+var knownCNIs = []knownCNI{
+	{name: "calico", pluginTypes: []string{"calico"}, interfaces: []string{"vxlan.calico", "wireguard.cali"}, chainPrefixes: []string{"cali-"}},
+	{name: "cilium", pluginTypes: []string{"cilium-cni"}, interfaces: []string{"cilium_host", "cilium_net", "cilium_vxlan", "cilium_geneve"}, chainPrefixes: []string{"CILIUM_"}},
+	{name: "flannel", pluginTypes: []string{"flannel"}, interfaces: []string{"cni0", "flannel.1", "flannel-wg"}, chainPrefixes: []string{"FLANNEL-"}},
+	{name: "kube-router", interfaces: []string{"kube-bridge", "kube-dummy-if"}, chainPrefixes: []string{"KUBE-ROUTER-"}},
+	{name: "weave", pluginTypes: []string{"weave-net"}, interfaces: []string{"weave", "datapath", "vxlan-6784"}, chainPrefixes: []string{"WEAVE"}},
+}
+
+// cniConf is the part of a CNI configuration file, or of a CNI configuration
+// list, which identifies the CNI plugins it uses.
+type cniConf struct {
+	Type    string `json:"type"`
+	Plugins []struct {
+		Type string `json:"type"`
+	} `json:"plugins"`
+}
+
+// getConfFileOwner returns the CNI plugin which installed a CNI configuration
+// file, from its name or from the types of the plugins it references, or an
+// empty string if the file is not installed by a known CNI plugin. The files
+// of other plugins, e.g. Multus or the loopback plugin, are left untouched.
+func getConfFileOwner(fileName string, data []byte) string {
+	if fileName == legacyAntreaCNIConfFile {
+		return antreaOwner
+	}
+	for _, cni := range knownCNIs {
+		if strings.Contains(strings.ToLower(fileName), cni.name) {
+			return cni.name
+		}
+	}
+	var conf cniConf
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return ""
+	}
+	pluginTypes := []string{conf.Type}
+	for _, plugin := range conf.Plugins {
+		pluginTypes = append(pluginTypes, plugin.Type)
+	}
+	for _, cni := range knownCNIs {
+		for _, knownType := range cni.pluginTypes {
+			for _, pluginType := range pluginTypes {
+				if pluginType == knownType {
+					return cni.name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// getInterfaceOwner returns the CNI plugin which creates a network interface,
+// or an empty string if the interface is not created by a known CNI plugin.
+func getInterfaceOwner(name string) string {
+	for _, cni := range knownCNIs {
+		for _, iface := range cni.interfaces {
+			if name == iface {
+				return cni.name
+			}
+		}
+	}
+	return ""
+}
+
+// getChainOwner returns the CNI plugin which creates an iptables chain, or an
+// empty string if the chain is not created by a known CNI plugin.
+func getChainOwner(chain string) string {
+	for _, cni := range knownCNIs {
+		for _, prefix := range cni.chainPrefixes {
+			if strings.HasPrefix(chain, prefix) {
+				return cni.name
+			}
+		}
+	}
+	return ""
+}
+
+// Scanner detects and removes the resources left on the Node by other CNI
+// plugins or by previous Antrea installations.
+type Scanner struct {
+	// cniConfDir is the directory of the CNI configuration files of the
+	// Node. The CNI configuration files are not scanned if it is empty.
+	cniConfDir string
+	// ovsBridge is the OVS bridge used by Antrea, which is never reported.
+	ovsBridge string
+
+	mutex     sync.RWMutex
+	conflicts []Conflict
+}
+
+// NewScanner creates a Scanner for the provided CNI configuration directory
+// and OVS bridge.
+func NewScanner(cniConfDir string, ovsBridge string) *Scanner {
+	return &Scanner{cniConfDir: cniConfDir, ovsBridge: ovsBridge}
+}
+
+// Scan scans the Node and returns the conflicts found, which are also
+// returned by GetConflicts until the next scan.
+func (s *Scanner) Scan() ([]Conflict, error) {
+	confFiles, err := s.scanCNIConfFiles()
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := scanPlatform(s.ovsBridge)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(confFiles, conflicts...)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.conflicts = conflicts
+	return conflicts, nil
+}
+
+// GetConflicts returns the conflicts found by the last scan.
+func (s *Scanner) GetConflicts() []Conflict {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.conflicts
+}
+
+// Cleanup rescans the Node, removes all the conflicts found, and returns the
+// conflicts which could not be removed.
+func (s *Scanner) Cleanup() ([]Conflict, error) {
+	conflicts, err := s.Scan()
+	if err != nil {
+		return nil, err
+	}
+	// The iptables chains are removed together, as they may reference each
+	// other.
+	var chains []Conflict
+	var errs []string
+	for _, c := range conflicts {
+		var err error
+		switch c.Type {
+		case CNIConfFile:
+			err = os.Remove(c.Name)
+		case Interface:
+			err = deleteInterface(c.Name)
+		case OVSBridge:
+			err = deleteOVSBridge(c.Name)
+		case IPTablesChain:
+			chains = append(chains, c)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %v", c.Type, c.Name, err))
+			continue
+		}
+		klog.Infof("Removed %s %s of %s", c.Type, c.Name, c.Owner)
+	}
+	if len(chains) > 0 {
+		if err := deleteIPTablesChains(chains); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			klog.Infof("Removed %d iptables chains", len(chains))
+		}
+	}
+	if conflicts, err = s.Scan(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return conflicts, fmt.Errorf("failed to remove some conflicts: %s", strings.Join(errs, "; "))
+	}
+	return conflicts, nil
+}
+
+// scanCNIConfFiles returns the CNI configuration files installed by known CNI
+// plugins or by previous Antrea versions.
+func (s *Scanner) scanCNIConfFiles() ([]Conflict, error) {
+	if s.cniConfDir == "" {
+		return nil, nil
+	}
+	files, err := ioutil.ReadDir(s.cniConfDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading CNI configuration directory %s: %v", s.cniConfDir, err)
+	}
+	var conflicts []Conflict
+	for _, file := range files {
+		if file.IsDir() || file.Name() == antreaCNIConfFile {
+			continue
+		}
+		// The kubelet only considers these extensions.
+		switch filepath.Ext(file.Name()) {
+		case ".conf", ".conflist", ".json":
+		default:
+			continue
+		}
+		path := filepath.Join(s.cniConfDir, file.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CNI configuration file %s: %v", path, err)
+		}
+		if owner := getConfFileOwner(file.Name(), data); owner != "" {
+			conflicts = append(conflicts, Conflict{Type: CNIConfFile, Name: path, Owner: owner})
+		}
+	}
+	return conflicts, nil
+}
+
+// sortConflicts sorts conflicts by name, so that the results of different
+// scans can be compared.
+func sortConflicts(conflicts []Conflict) {
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Name < conflicts[j].Name
+	})
+}
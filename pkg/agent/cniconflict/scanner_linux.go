@@ -0,0 +1,269 @@
+// +build linux
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+)
+
+// DefaultCNIConfDir is the path at which the CNI configuration directory of
+// the Node is mounted in the antrea-agent container.
+const DefaultCNIConfDir = "/host/etc/cni/net.d"
+
+const (
+	// antreaChainPrefix is the prefix of the iptables chains created by
+	// Antrea.
+	antreaChainPrefix = "ANTREA-"
+	// roundNumKey is the external ID set by antrea-agent on the OVS bridges
+	// it creates.
+	roundNumKey = "roundNum"
+)
+
+// runOVSVsctl runs an ovs-vsctl command and returns its output. It is a
+// variable so that it can be overridden in tests.
+var runOVSVsctl = func(args ...string) (string, error) {
+	output, err := exec.Command("ovs-vsctl", append([]string{"--timeout=10"}, args...)...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+var iptablesTables = []string{iptables.FilterTable, iptables.NATTable, iptables.MangleTable, iptables.RawTable}
+
+// newIPTablesClients creates an iptables client for each IP family, as the
+// chains and the rules referencing them differ between families. IPv6 is
+// skipped if ip6tables is not available on the Node.
+func newIPTablesClients() ([]*iptables.Client, error) {
+	ipv4Client, err := iptables.New(true, false)
+	if err != nil {
+		return nil, err
+	}
+	clients := []*iptables.Client{ipv4Client}
+	if ipv6Client, err := iptables.New(false, true); err != nil {
+		klog.V(2).Infof("Skipping ip6tables chains: %v", err)
+	} else {
+		clients = append(clients, ipv6Client)
+	}
+	return clients, nil
+}
+
+// isStaleAntreaChain returns whether an iptables chain was created by a
+// previous Antrea version and is no longer used.
+func isStaleAntreaChain(chain string) bool {
+	if !strings.HasPrefix(chain, antreaChainPrefix) || chain == rules.NodePortLocalChain {
+		return false
+	}
+	for _, c := range route.IPTablesChains {
+		if chain == c {
+			return false
+		}
+	}
+	return true
+}
+
+// scanOVSBridges returns the OVS bridges created by previous Antrea
+// installations with a different bridge name. The bridges created by other
+// applications, which do not have the roundNum external ID, are ignored.
+func scanOVSBridges(ovsBridge string) ([]Conflict, error) {
+	output, err := runOVSVsctl("list-br")
+	if err != nil {
+		return nil, fmt.Errorf("error listing OVS bridges: %v", err)
+	}
+	var conflicts []Conflict
+	for _, bridge := range strings.Fields(output) {
+		if bridge == ovsBridge {
+			continue
+		}
+		roundNum, err := runOVSVsctl("br-get-external-id", bridge, roundNumKey)
+		if err != nil {
+			return nil, fmt.Errorf("error getting external IDs of OVS bridge %s: %v", bridge, err)
+		}
+		if roundNum != "" {
+			conflicts = append(conflicts, Conflict{Type: OVSBridge, Name: bridge, Owner: antreaOwner})
+		}
+	}
+	return conflicts, nil
+}
+
+func scanPlatform(ovsBridge string) ([]Conflict, error) {
+	var conflicts []Conflict
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %v", err)
+	}
+	for _, link := range links {
+		name := link.Attrs().Name
+		if owner := getInterfaceOwner(name); owner != "" {
+			conflicts = append(conflicts, Conflict{Type: Interface, Name: name, Owner: owner})
+		}
+	}
+
+	clients, err := newIPTablesClients()
+	if err != nil {
+		return nil, err
+	}
+	var chainConflicts []Conflict
+	found := map[string]bool{}
+	for _, client := range clients {
+		for _, table := range iptablesTables {
+			chains, err := client.ListChains(table)
+			if err != nil {
+				return nil, err
+			}
+			for _, chain := range chains {
+				name := table + "/" + chain
+				owner := getChainOwner(chain)
+				if owner == "" && isStaleAntreaChain(chain) {
+					owner = antreaOwner
+				}
+				if owner != "" && !found[name] {
+					found[name] = true
+					chainConflicts = append(chainConflicts, Conflict{Type: IPTablesChain, Name: name, Owner: owner})
+				}
+			}
+		}
+	}
+	sortConflicts(chainConflicts)
+	conflicts = append(conflicts, chainConflicts...)
+
+	bridges, err := scanOVSBridges(ovsBridge)
+	if err != nil {
+		return nil, err
+	}
+	return append(conflicts, bridges...), nil
+}
+
+func deleteInterface(name string) error {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	return netlink.LinkDel(link)
+}
+
+func deleteOVSBridge(name string) error {
+	_, err := runOVSVsctl("--if-exists", "del-br", name)
+	return err
+}
+
+// deleteIPTablesChains deletes the provided chains, after deleting the rules
+// of all the chains which jump to them.
+func deleteIPTablesChains(conflicts []Conflict) error {
+	tableChains := map[string]map[string]bool{}
+	for _, c := range conflicts {
+		parts := strings.SplitN(c.Name, "/", 2)
+		if tableChains[parts[0]] == nil {
+			tableChains[parts[0]] = map[string]bool{}
+		}
+		tableChains[parts[0]][parts[1]] = true
+	}
+	clients, err := newIPTablesClients()
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		for table, deletedChains := range tableChains {
+			chains, err := client.ListChains(table)
+			if err != nil {
+				return err
+			}
+			for _, chain := range chains {
+				rules, err := client.ListRules(table, chain)
+				if err != nil {
+					return err
+				}
+				for _, rule := range rules {
+					ruleSpec := splitRule(rule)
+					// Only the "-A <chain> ..." rules are deleted, not the
+					// "-N <chain>" and "-P <chain> <policy>" lines.
+					if len(ruleSpec) < 2 || ruleSpec[0] != "-A" || !jumpsToChain(ruleSpec, deletedChains) {
+						continue
+					}
+					if err := client.DeleteRule(table, chain, ruleSpec[2:]); err != nil {
+						return err
+					}
+				}
+			}
+			for _, chain := range chains {
+				if !deletedChains[chain] {
+					continue
+				}
+				if err := client.DeleteChain(table, chain); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jumpsToChain returns whether the target of a rule is one of the provided
+// chains.
+func jumpsToChain(ruleSpec []string, chains map[string]bool) bool {
+	for i := 0; i < len(ruleSpec)-1; i++ {
+		if (ruleSpec[i] == "-j" || ruleSpec[i] == "-g") && chains[ruleSpec[i+1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRule splits a rule listed by iptables into arguments. Double-quoted
+// arguments, e.g. comments, are unquoted.
+func splitRule(rule string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted, escaped := false, false, false
+	for _, r := range rule {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case r == ' ' && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
@@ -0,0 +1,100 @@
+// +build linux
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRule(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected []string
+	}{
+		{
+			rule:     "-A FORWARD -j cali-FORWARD",
+			expected: []string{"-A", "FORWARD", "-j", "cali-FORWARD"},
+		},
+		{
+			rule:     `-A FORWARD -m comment --comment "cali:wUHhoiAYhphO9Mso" -j cali-FORWARD`,
+			expected: []string{"-A", "FORWARD", "-m", "comment", "--comment", "cali:wUHhoiAYhphO9Mso", "-j", "cali-FORWARD"},
+		},
+		{
+			rule:     `-A POSTROUTING -m comment --comment "flanneld masq \"all\"" -j FLANNEL-POSTRTG`,
+			expected: []string{"-A", "POSTROUTING", "-m", "comment", "--comment", `flanneld masq "all"`, "-j", "FLANNEL-POSTRTG"},
+		},
+		{
+			rule:     "-N cali-FORWARD",
+			expected: []string{"-N", "cali-FORWARD"},
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, splitRule(tt.rule))
+	}
+}
+
+func TestJumpsToChain(t *testing.T) {
+	chains := map[string]bool{"cali-FORWARD": true}
+	assert.True(t, jumpsToChain(splitRule("-A FORWARD -j cali-FORWARD"), chains))
+	assert.True(t, jumpsToChain(splitRule("-A FORWARD -g cali-FORWARD"), chains))
+	assert.False(t, jumpsToChain(splitRule("-A FORWARD -j ANTREA-FORWARD"), chains))
+	assert.False(t, jumpsToChain(splitRule(`-A FORWARD -m comment --comment "-j cali-FORWARD" -j ACCEPT`), chains))
+}
+
+func TestIsStaleAntreaChain(t *testing.T) {
+	assert.True(t, isStaleAntreaChain("ANTREA-RAW"))
+	assert.False(t, isStaleAntreaChain("ANTREA-FORWARD"))
+	assert.False(t, isStaleAntreaChain("ANTREA-POSTROUTING"))
+	assert.False(t, isStaleAntreaChain("ANTREA-NODE-PORT-LOCAL"))
+	assert.False(t, isStaleAntreaChain("KUBE-SERVICES"))
+}
+
+func TestScanOVSBridges(t *testing.T) {
+	defer func(orig func(args ...string) (string, error)) { runOVSVsctl = orig }(runOVSVsctl)
+	externalIDs := map[string]string{"br-int": "3", "br-antrea": "2", "br-ex": ""}
+	var commands []string
+	runOVSVsctl = func(args ...string) (string, error) {
+		commands = append(commands, strings.Join(args, " "))
+		switch args[0] {
+		case "list-br":
+			return "br-antrea\nbr-ex\nbr-int", nil
+		case "br-get-external-id":
+			return externalIDs[args[1]], nil
+		}
+		return "", errors.New("unexpected command")
+	}
+
+	conflicts, err := scanOVSBridges("br-int")
+	require.NoError(t, err)
+	assert.Equal(t, []Conflict{{Type: OVSBridge, Name: "br-antrea", Owner: antreaOwner}}, conflicts)
+	assert.Equal(t, []string{"list-br", "br-get-external-id br-antrea roundNum", "br-get-external-id br-ex roundNum"}, commands)
+
+	commands = nil
+	require.NoError(t, deleteOVSBridge("br-antrea"))
+	assert.Equal(t, []string{"--if-exists del-br br-antrea"}, commands)
+
+	runOVSVsctl = func(args ...string) (string, error) {
+		return "", errors.New("database connection failed")
+	}
+	_, err = scanOVSBridges("br-int")
+	assert.Error(t, err)
+}
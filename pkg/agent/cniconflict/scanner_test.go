@@ -0,0 +1,97 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanCNIConfFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cniconflict")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"00-multus.conf":      `{"name": "multus-cni-network", "type": "multus", "delegates": [{"name": "cbr0", "plugins": [{"type": "flannel"}]}]}`,
+		"05-cilium.conf":      `{}`,
+		"10-antrea.conf":      `{}`,
+		"10-antrea.conflist":  `{"name": "antrea", "plugins": [{"type": "antrea"}, {"type": "portmap"}]}`,
+		"10-calico.conflist":  `{"name": "k8s-pod-network", "plugins": [{"type": "calico"}, {"type": "portmap"}]}`,
+		"10-flannel.conflist": `{}`,
+		"10-weave.conf":       `{}`,
+		"20-cni.conflist":     `{"name": "cbr0", "plugins": [{"type": "flannel"}]}`,
+		"99-loopback.json":    `{"name": "lo", "type": "loopback"}`,
+		"calico-kubeconfig":   `{}`,
+		"invalid.conf":        `invalid`,
+	}
+	for name, data := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "multus.d"), 0755))
+
+	s := NewScanner(dir, "br-int")
+	conflicts, err := s.scanCNIConfFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []Conflict{
+		{Type: CNIConfFile, Name: filepath.Join(dir, "05-cilium.conf"), Owner: "cilium"},
+		{Type: CNIConfFile, Name: filepath.Join(dir, "10-antrea.conf"), Owner: antreaOwner},
+		{Type: CNIConfFile, Name: filepath.Join(dir, "10-calico.conflist"), Owner: "calico"},
+		{Type: CNIConfFile, Name: filepath.Join(dir, "10-flannel.conflist"), Owner: "flannel"},
+		{Type: CNIConfFile, Name: filepath.Join(dir, "10-weave.conf"), Owner: "weave"},
+		{Type: CNIConfFile, Name: filepath.Join(dir, "20-cni.conflist"), Owner: "flannel"},
+	}, conflicts)
+
+	s = NewScanner(filepath.Join(dir, "missing"), "br-int")
+	conflicts, err = s.scanCNIConfFiles()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestGetConfFileOwner(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		expected string
+	}{
+		{name: "legacy Antrea", fileName: "10-antrea.conf", data: `{"type": "antrea"}`, expected: antreaOwner},
+		{name: "file name", fileName: "10-Flannel.conflist", data: `{}`, expected: "flannel"},
+		{name: "plugin type", fileName: "10-default.conf", data: `{"type": "weave-net"}`, expected: "weave"},
+		{name: "plugin list", fileName: "10-default.conflist", data: `{"plugins": [{"type": "cilium-cni"}]}`, expected: "cilium"},
+		{name: "Multus", fileName: "00-multus.conf", data: `{"type": "multus", "delegates": [{"type": "calico"}]}`, expected: ""},
+		{name: "unknown plugin", fileName: "99-loopback.conf", data: `{"type": "loopback"}`, expected: ""},
+		{name: "invalid", fileName: "99-invalid.conf", data: `{`, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getConfFileOwner(tt.fileName, []byte(tt.data)))
+		})
+	}
+}
+
+func TestGetOwner(t *testing.T) {
+	assert.Equal(t, "flannel", getInterfaceOwner("flannel.1"))
+	assert.Equal(t, "cilium", getInterfaceOwner("cilium_host"))
+	assert.Equal(t, "", getInterfaceOwner("antrea-gw0"))
+	assert.Equal(t, "calico", getChainOwner("cali-FORWARD"))
+	assert.Equal(t, "kube-router", getChainOwner("KUBE-ROUTER-INPUT"))
+	assert.Equal(t, "", getChainOwner("ANTREA-FORWARD"))
+	assert.Equal(t, "", getChainOwner("KUBE-SERVICES"))
+}
@@ -0,0 +1,43 @@
+// +build windows
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniconflict
+
+import (
+	"errors"
+)
+
+// DefaultCNIConfDir is empty as the CNI configuration directory of the Node is
+// not mounted in the antrea-agent container on Windows.
+const DefaultCNIConfDir = ""
+
+// scanPlatform returns no conflicts as the network interfaces, iptables chains
+// and OVS bridges are not scanned on Windows.
+func scanPlatform(ovsBridge string) ([]Conflict, error) {
+	return nil, nil
+}
+
+func deleteInterface(name string) error {
+	return errors.New("deleting network interfaces is not supported on Windows")
+}
+
+func deleteOVSBridge(name string) error {
+	return errors.New("deleting OVS bridges is not supported on Windows")
+}
+
+func deleteIPTablesChains(conflicts []Conflict) error {
+	return errors.New("iptables is not supported on Windows")
+}
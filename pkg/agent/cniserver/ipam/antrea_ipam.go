@@ -0,0 +1,387 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/types/current"
+	"k8s.io/klog"
+)
+
+const (
+	// AntreaIPAMType is the IPAM type of the IPAM driver implemented by antrea-agent, which allocates the IP
+	// addresses of the Pods from the Pod CIDRs of the Node without invoking an external IPAM plugin.
+	AntreaIPAMType = "antrea"
+
+	// defaultDataDir is the directory in which the allocated IP addresses are persisted. It is the same as the one of
+	// the host-local IPAM plugin, and the same file format is used, so that the allocations made by one driver are
+	// honored by the other one when switching between them.
+	defaultDataDir = "/var/lib/cni/networks"
+	// lastReservedIPFilePrefix is the prefix of the files storing the last IP address allocated from each range set,
+	// so that addresses are allocated in a round-robin manner and are not reused immediately after being released.
+	lastReservedIPFilePrefix = "last_reserved_ip."
+	// lineBreak separates the container ID and the interface name in the file of an allocated IP address.
+	lineBreak = "\r\n"
+)
+
+// networkConfig is the part of the CNI network configuration used by the antrea IPAM driver. The ranges are set by
+// the CNI server from the Pod CIDRs of the Node.
+type networkConfig struct {
+	CNIVersion string     `json:"cniVersion,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	IPAM       IPAMConfig `json:"ipam,omitempty"`
+}
+
+// AntreaIPAM allocates the IP addresses of the Pods from the ranges of the network configuration, one address per
+// range set, and persists the allocations on disk so that they are preserved across restarts of antrea-agent.
+type AntreaIPAM struct {
+	dataDir string
+	// mutex serializes the allocations, as all the CNI requests are handled by the same process.
+	mutex sync.Mutex
+}
+
+// NewAntreaIPAM returns an AntreaIPAM which persists the allocated IP addresses in the provided directory.
+func NewAntreaIPAM(dataDir string) *AntreaIPAM {
+	return &AntreaIPAM{dataDir: dataDir}
+}
+
+// ipamRange is a Range with its parsed subnet and gateway.
+type ipamRange struct {
+	subnet  *net.IPNet
+	gateway net.IP
+}
+
+func parseNetworkConfig(config []byte) (*networkConfig, [][]ipamRange, error) {
+	conf := &networkConfig{}
+	if err := json.Unmarshal(config, conf); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse network configuration: %v", err)
+	}
+	if conf.Name == "" {
+		return nil, nil, fmt.Errorf("network name is missing")
+	}
+	if len(conf.IPAM.Ranges) == 0 {
+		return nil, nil, fmt.Errorf("no IP range is configured")
+	}
+	rangeSets := make([][]ipamRange, 0, len(conf.IPAM.Ranges))
+	for _, rangeSet := range conf.IPAM.Ranges {
+		ranges := make([]ipamRange, 0, len(rangeSet))
+		for _, r := range rangeSet {
+			_, subnet, err := net.ParseCIDR(r.Subnet)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid subnet %s: %v", r.Subnet, err)
+			}
+			var gateway net.IP
+			if r.Gateway != "" {
+				if gateway = net.ParseIP(r.Gateway); gateway == nil {
+					return nil, nil, fmt.Errorf("invalid gateway %s", r.Gateway)
+				}
+			}
+			ranges = append(ranges, ipamRange{subnet: subnet, gateway: gateway})
+		}
+		rangeSets = append(rangeSets, ranges)
+	}
+	return conf, rangeSets, nil
+}
+
+// allocationKey returns the content of the file of an IP address allocated to the provided container interface.
+func allocationKey(containerID, ifName string) string {
+	return strings.TrimSpace(containerID) + lineBreak + ifName
+}
+
+// isAllocatedTo returns whether the content of the file of an IP address matches the provided container interface.
+// Files which only contain the container ID, written by older versions of the host-local plugin, are matched too.
+func isAllocatedTo(content, containerID, ifName string) bool {
+	content = strings.TrimSpace(content)
+	containerID = strings.TrimSpace(containerID)
+	return content == containerID || content == allocationKey(containerID, ifName)
+}
+
+// Add allocates one IP address from each range set of the network configuration to the container interface. The
+// addresses already allocated to the interface are returned if any, so that Add is idempotent.
+func (d *AntreaIPAM) Add(args *invoke.Args, config []byte) (*current.Result, error) {
+	conf, rangeSets, err := parseNetworkConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	dir := filepath.Join(d.dataDir, conf.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	allocated, err := d.getAllocatedIPs(dir, args.ContainerID, args.IfName)
+	if err != nil {
+		return nil, err
+	}
+	result := &current.Result{CNIVersion: conf.CNIVersion}
+	var reserved []net.IP
+	success := false
+	defer func() {
+		if success {
+			return
+		}
+		for _, ip := range reserved {
+			if err := os.Remove(filepath.Join(dir, ip.String())); err != nil {
+				klog.Errorf("Failed to release IP %s after failed allocation: %v", ip, err)
+			}
+		}
+	}()
+	for i, ranges := range rangeSets {
+		ipConfig := findAllocatedIP(allocated, ranges)
+		if ipConfig == nil {
+			if ipConfig, err = d.reserveIP(dir, i, ranges, args.ContainerID, args.IfName); err != nil {
+				return nil, err
+			}
+			reserved = append(reserved, ipConfig.Address.IP)
+		}
+		result.IPs = append(result.IPs, ipConfig)
+	}
+	success = true
+	return result, nil
+}
+
+// Del releases all the IP addresses allocated to the container interface. It succeeds if no address is allocated.
+func (d *AntreaIPAM) Del(args *invoke.Args, config []byte) error {
+	conf, _, err := parseNetworkConfig(config)
+	if err != nil {
+		return err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	dir := filepath.Join(d.dataDir, conf.Name)
+	allocated, err := d.getAllocatedIPs(dir, args.ContainerID, args.IfName)
+	if err != nil {
+		return err
+	}
+	for _, ip := range allocated {
+		if err := os.Remove(filepath.Join(dir, ip.String())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		klog.V(2).Infof("Released IP %s of container %s", ip, args.ContainerID)
+	}
+	return nil
+}
+
+// Check verifies that an IP address is allocated to the container interface from each range set of the network
+// configuration.
+func (d *AntreaIPAM) Check(args *invoke.Args, config []byte) error {
+	conf, rangeSets, err := parseNetworkConfig(config)
+	if err != nil {
+		return err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	allocated, err := d.getAllocatedIPs(filepath.Join(d.dataDir, conf.Name), args.ContainerID, args.IfName)
+	if err != nil {
+		return err
+	}
+	for _, ranges := range rangeSets {
+		if findAllocatedIP(allocated, ranges) == nil {
+			return fmt.Errorf("no IP allocated to container %s from range %s", args.ContainerID, ranges[0].subnet)
+		}
+	}
+	return nil
+}
+
+// getAllocatedIPs returns the IP addresses allocated to the container interface in the provided directory.
+func (d *AntreaIPAM) getAllocatedIPs(dir, containerID, ifName string) ([]net.IP, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ips []net.IP
+	for _, file := range files {
+		ip := net.ParseIP(file.Name())
+		if ip == nil || file.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if isAllocatedTo(string(content), containerID, ifName) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+// findAllocatedIP returns the IPConfig of the first IP address which belongs to one of the ranges, or nil if there is
+// none.
+func findAllocatedIP(allocated []net.IP, ranges []ipamRange) *current.IPConfig {
+	for _, ip := range allocated {
+		for _, r := range ranges {
+			if r.subnet.Contains(ip) {
+				return newIPConfig(ip, r)
+			}
+		}
+	}
+	return nil
+}
+
+func newIPConfig(ip net.IP, r ipamRange) *current.IPConfig {
+	version := "4"
+	if ip.To4() == nil {
+		version = "6"
+	}
+	return &current.IPConfig{
+		Version: version,
+		Address: net.IPNet{IP: ip, Mask: r.subnet.Mask},
+		Gateway: r.gateway,
+	}
+}
+
+// reserveIP reserves the first available IP address of the ranges after the last reserved one, and persists the
+// reservation.
+func (d *AntreaIPAM) reserveIP(dir string, rangeSetIndex int, ranges []ipamRange, containerID, ifName string) (*current.IPConfig, error) {
+	lastReservedIPFile := filepath.Join(dir, lastReservedIPFilePrefix+strconv.Itoa(rangeSetIndex))
+	var lastReservedIP net.IP
+	if content, err := ioutil.ReadFile(lastReservedIPFile); err == nil {
+		lastReservedIP = net.ParseIP(strings.TrimSpace(string(content)))
+	}
+	// Start right after the last reserved IP, and scan all the ranges of the set once.
+	i, ip := 0, firstIP(ranges[0].subnet)
+	for j, r := range ranges {
+		if lastReservedIP != nil && r.subnet.Contains(lastReservedIP) {
+			i, ip = j, lastReservedIP
+			i, ip = advanceIP(ranges, i, ip)
+			break
+		}
+	}
+	startRange, startIP := i, ip
+	for {
+		r := ranges[i]
+		if !ip.Equal(r.gateway) {
+			reserved, err := reserveIPFile(filepath.Join(dir, ip.String()), containerID, ifName)
+			if err != nil {
+				return nil, err
+			}
+			if reserved {
+				if err := ioutil.WriteFile(lastReservedIPFile, []byte(ip.String()), 0644); err != nil {
+					klog.Errorf("Failed to persist last reserved IP %s: %v", ip, err)
+				}
+				klog.V(2).Infof("Reserved IP %s for container %s", ip, containerID)
+				return newIPConfig(ip, r), nil
+			}
+		}
+		i, ip = advanceIP(ranges, i, ip)
+		if i == startRange && ip.Equal(startIP) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("no IP address available in range set %d", rangeSetIndex)
+}
+
+// advanceIP returns the IP address following ip in the range set, moving to the first IP address of the next range
+// once the last one of the current range is reached.
+func advanceIP(ranges []ipamRange, i int, ip net.IP) (int, net.IP) {
+	if !ip.Equal(lastIP(ranges[i].subnet)) {
+		if next := nextIP(ip); ranges[i].subnet.Contains(next) {
+			return i, next
+		}
+	}
+	i = (i + 1) % len(ranges)
+	return i, firstIP(ranges[i].subnet)
+}
+
+// reserveIPFile creates the file of an IP address for the container interface. It returns false if the IP address
+// is already allocated.
+func reserveIPFile(path, containerID, ifName string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_EXCL|os.O_CREATE, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, err = f.WriteString(allocationKey(containerID, ifName))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("failed to persist reservation of IP address: %v", err)
+	}
+	return true, nil
+}
+
+// firstIP returns the first usable IP address of the subnet, i.e. the one following the network address.
+func firstIP(subnet *net.IPNet) net.IP {
+	ip := subnet.IP.Mask(subnet.Mask)
+	if ones, bits := subnet.Mask.Size(); bits-ones < 2 {
+		return ip
+	}
+	return nextIP(ip)
+}
+
+// lastIP returns the last usable IP address of the subnet, i.e. the one preceding the broadcast address for IPv4.
+func lastIP(subnet *net.IPNet) net.IP {
+	network := subnet.IP.Mask(subnet.Mask)
+	ip := make(net.IP, len(network))
+	for i := range network {
+		ip[i] = network[i] | ^subnet.Mask[i]
+	}
+	if ones, bits := subnet.Mask.Size(); network.To4() == nil || bits-ones < 2 {
+		return ip
+	}
+	return prevIP(ip)
+}
+
+func nextIP(ip net.IP) net.IP {
+	return addToIP(ip, 1)
+}
+
+func prevIP(ip net.IP) net.IP {
+	return addToIP(ip, -1)
+}
+
+func addToIP(ip net.IP, n int64) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	i := new(big.Int).SetBytes(ip)
+	i.Add(i, big.NewInt(n))
+	b := i.Bytes()
+	result := make(net.IP, len(ip))
+	// Wrap around when the result overflows the address family.
+	if len(b) > len(ip) {
+		b = b[len(b)-len(ip):]
+	}
+	copy(result[len(result)-len(b):], b)
+	return result
+}
+
+func init() {
+	if err := RegisterIPAMDriver(AntreaIPAMType, NewAntreaIPAM(defaultDataDir)); err != nil {
+		klog.Errorf("Failed to register IPAM plugin on type %s", AntreaIPAMType)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetworkConfig = `{
+	"cniVersion": "0.3.0",
+	"name": "antrea",
+	"ipam": {
+		"type": "antrea",
+		"ranges": [
+			[{"subnet": "10.10.0.0/29", "gateway": "10.10.0.1"}],
+			[{"subnet": "fd00::/125", "gateway": "fd00::1"}]
+		]
+	}
+}`
+
+func newTestAntreaIPAM(t *testing.T) (*AntreaIPAM, string) {
+	dir, err := ioutil.TempDir("", "antrea-ipam")
+	require.NoError(t, err)
+	return NewAntreaIPAM(dir), dir
+}
+
+func TestAntreaIPAMAdd(t *testing.T) {
+	d, dir := newTestAntreaIPAM(t)
+	defer os.RemoveAll(dir)
+	config := []byte(testNetworkConfig)
+
+	result, err := d.Add(&invoke.Args{ContainerID: "c1", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	require.Len(t, result.IPs, 2)
+	assert.Equal(t, "4", result.IPs[0].Version)
+	assert.Equal(t, "10.10.0.2/29", result.IPs[0].Address.String())
+	assert.Equal(t, "10.10.0.1", result.IPs[0].Gateway.String())
+	assert.Equal(t, "6", result.IPs[1].Version)
+	assert.Equal(t, "fd00::2/125", result.IPs[1].Address.String())
+	assert.Equal(t, "fd00::1", result.IPs[1].Gateway.String())
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "antrea", "10.10.0.2"))
+	require.NoError(t, err)
+	assert.Equal(t, "c1\r\neth0", string(content))
+
+	// Add is idempotent for the same container interface.
+	result, err = d.Add(&invoke.Args{ContainerID: "c1", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.2/29", result.IPs[0].Address.String())
+	assert.Equal(t, "fd00::2/125", result.IPs[1].Address.String())
+
+	// A new AntreaIPAM, e.g. after antrea-agent restarts, honors the persisted allocations.
+	result, err = NewAntreaIPAM(dir).Add(&invoke.Args{ContainerID: "c2", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.3/29", result.IPs[0].Address.String())
+	assert.Equal(t, "fd00::3/125", result.IPs[1].Address.String())
+}
+
+func TestAntreaIPAMExhausted(t *testing.T) {
+	d, dir := newTestAntreaIPAM(t)
+	defer os.RemoveAll(dir)
+	config := []byte(`{"name": "antrea", "ipam": {"ranges": [[{"subnet": "10.10.0.0/30", "gateway": "10.10.0.1"}]]}}`)
+
+	// 10.10.0.0/30 only has one usable IP besides the gateway.
+	result, err := d.Add(&invoke.Args{ContainerID: "c1", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.2/30", result.IPs[0].Address.String())
+	_, err = d.Add(&invoke.Args{ContainerID: "c2", IfName: "eth0"}, config)
+	assert.Error(t, err)
+
+	// The released IP can be allocated again.
+	require.NoError(t, d.Del(&invoke.Args{ContainerID: "c1", IfName: "eth0"}, config))
+	result, err = d.Add(&invoke.Args{ContainerID: "c2", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.2/30", result.IPs[0].Address.String())
+}
+
+func TestAntreaIPAMDelAndCheck(t *testing.T) {
+	d, dir := newTestAntreaIPAM(t)
+	defer os.RemoveAll(dir)
+	config := []byte(testNetworkConfig)
+	args := &invoke.Args{ContainerID: "c1", IfName: "eth0"}
+
+	assert.Error(t, d.Check(args, config))
+	// Del succeeds if no IP is allocated.
+	assert.NoError(t, d.Del(args, config))
+
+	_, err := d.Add(args, config)
+	require.NoError(t, err)
+	assert.NoError(t, d.Check(args, config))
+	assert.Error(t, d.Check(&invoke.Args{ContainerID: "c2", IfName: "eth0"}, config))
+
+	require.NoError(t, d.Del(args, config))
+	assert.Error(t, d.Check(args, config))
+	_, err = os.Stat(filepath.Join(dir, "antrea", "10.10.0.2"))
+	assert.True(t, os.IsNotExist(err))
+
+	// IPs are allocated in a round-robin manner and not reused immediately after being released.
+	result, err := d.Add(&invoke.Args{ContainerID: "c2", IfName: "eth0"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.3/29", result.IPs[0].Address.String())
+}
+
+func TestAntreaIPAMLegacyAllocation(t *testing.T) {
+	d, dir := newTestAntreaIPAM(t)
+	defer os.RemoveAll(dir)
+	config := []byte(testNetworkConfig)
+	args := &invoke.Args{ContainerID: "c1", IfName: "eth0"}
+
+	// Files written by older versions of host-local only contain the container ID.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "antrea"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "antrea", "10.10.0.5"), []byte("c1"), 0644))
+
+	result, err := d.Add(args, config)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.5/29", result.IPs[0].Address.String())
+	assert.Equal(t, "fd00::2/125", result.IPs[1].Address.String())
+
+	require.NoError(t, d.Del(args, config))
+	_, err = os.Stat(filepath.Join(dir, "antrea", "10.10.0.5"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAntreaIPAMInvalidConfig(t *testing.T) {
+	d, dir := newTestAntreaIPAM(t)
+	defer os.RemoveAll(dir)
+	args := &invoke.Args{ContainerID: "c1", IfName: "eth0"}
+
+	for _, config := range []string{
+		`{"name": "antrea"}`,
+		`{"ipam": {"ranges": [[{"subnet": "10.10.0.0/24"}]]}}`,
+		`{"name": "antrea", "ipam": {"ranges": [[{"subnet": "10.10.0.0"}]]}}`,
+		`{"name": "antrea", "ipam": {"ranges": [[{"subnet": "10.10.0.0/24", "gateway": "10.10.0"}]]}}`,
+	} {
+		_, err := d.Add(args, []byte(config))
+		assert.Error(t, err, "Add should fail for config %s", config)
+	}
+}
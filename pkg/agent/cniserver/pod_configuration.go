@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/cni/pkg/types/current"
@@ -58,6 +59,11 @@ const (
 	ovsExternalIDPodNamespace = "pod-namespace"
 )
 
+// networkPolicyRealizationTimeout is the maximum time to wait for the NetworkPolicy rules applied to a new Pod to be
+// realized before replying to the CNI ADD request. The request doesn't fail if the timeout occurs, as the rules of the
+// Pod may not be complete yet, e.g. when antrea-agent is connecting to antrea-controller.
+const networkPolicyRealizationTimeout = 5 * time.Second
+
 const (
 	defaultOVSInterfaceType int = iota //nolint suppress deadcode check for windows
 	internalOVSInterfaceType
@@ -73,6 +79,9 @@ type podConfigurator struct {
 	// entityUpdates is a channel for notifying updates of local endpoints / entities (most notably Pod)
 	// to other components which may benefit from this information, i.e NetworkPolicyController.
 	entityUpdates chan<- types.EntityReference
+	// podReadiness sets the network readiness condition of the Pods which have it as a readiness gate. It is nil if
+	// the PodReadinessGate feature is disabled.
+	podReadiness *podReadinessUpdater
 }
 
 func newPodConfigurator(
@@ -201,10 +210,14 @@ func (pc *podConfigurator) configureInterfaces(
 	containerIFDev string,
 	mtu int,
 	sriovVFDeviceID string,
+	vhostUser bool,
 	result *current.Result,
 	createOVSPort bool,
 	containerAccess *containerAccessArbitrator,
 ) error {
+	if vhostUser {
+		return pc.configureVhostUserInterface(podName, podNameSpace, containerID, containerNetNS, containerIFDev, result, createOVSPort)
+	}
 	err := pc.ifConfigurator.configureContainerLink(podName, podNameSpace, containerID, containerNetNS, containerIFDev, mtu, sriovVFDeviceID, result)
 	if err != nil {
 		return err
@@ -260,11 +273,15 @@ func (pc *podConfigurator) configureInterfaces(
 	return nil
 }
 
-func (pc *podConfigurator) createOVSPort(ovsPortName string, ovsAttachInfo map[string]interface{}) (string, error) {
+// createOVSPort creates the OVS port of a container interface. If vhostUserSocket is not empty, a vhost-user port
+// connecting to the socket is created.
+func (pc *podConfigurator) createOVSPort(ovsPortName string, ovsAttachInfo map[string]interface{}, vhostUserSocket string) (string, error) {
 	var portUUID string
 	var err error
-	switch pc.ifConfigurator.getOVSInterfaceType() {
-	case internalOVSInterfaceType:
+	switch {
+	case vhostUserSocket != "":
+		portUUID, err = pc.ovsBridgeClient.CreateVhostUserClientPort(ovsPortName, vhostUserSocket, ovsAttachInfo)
+	case pc.ifConfigurator.getOVSInterfaceType() == internalOVSInterfaceType:
 		portUUID, err = pc.ovsBridgeClient.CreateInternalPort(ovsPortName, 0, ovsAttachInfo)
 	default:
 		portUUID, err = pc.ovsBridgeClient.CreatePort(ovsPortName, ovsPortName, ovsAttachInfo)
@@ -301,6 +318,8 @@ func (pc *podConfigurator) removeInterfaces(containerID string) error {
 	if err := pc.ifConfigurator.removeContainerLink(containerID, containerConfig.InterfaceName); err != nil {
 		return err
 	}
+	// The vhost-user socket directory is removed last, as the port connecting to it has been deleted.
+	pc.removeVhostUserSocketDir(containerConfig.PodNamespace, containerConfig.PodName)
 	return nil
 }
 
@@ -409,6 +428,9 @@ func (pc *podConfigurator) reconcile(pods []corev1.Pod, containerAccess *contain
 	// actualPods is the set of Pods that are present, based on the container
 	// interfaces got from the OVSDB.
 	actualPods := sets.NewString()
+	// notReadyPods is the set of Pods whose network readiness condition has not been set yet, e.g. because
+	// antrea-agent was restarted before setting it.
+	notReadyPods := sets.NewString()
 	// knownInterfaces is the list of interfaces currently in the local cache.
 	knownInterfaces := pc.ifaceStore.GetInterfacesByType(interfacestore.ContainerInterface)
 
@@ -418,6 +440,9 @@ func (pc *podConfigurator) reconcile(pods []corev1.Pod, containerAccess *contain
 			continue
 		}
 		desiredPods.Insert(k8s.NamespacedName(pod.Namespace, pod.Name))
+		if pc.podReadiness != nil && hasNetworkReadinessGate(&pod) && !isNetworkReady(&pod) {
+			notReadyPods.Insert(k8s.NamespacedName(pod.Namespace, pod.Name))
+		}
 	}
 
 	for _, containerConfig := range knownInterfaces {
@@ -437,6 +462,9 @@ func (pc *podConfigurator) reconcile(pods []corev1.Pod, containerAccess *contain
 			); err != nil {
 				klog.Errorf("Error when re-installing flows for Pod %s", namespacedName)
 			}
+			if notReadyPods.Has(namespacedName) {
+				go pc.podReadiness.setNetworkReadyWhenRealized(containerConfig.PodNamespace, containerConfig.PodName, containerConfig.ContainerID, nil)
+			}
 		} else {
 			// clean-up and delete interface
 			klog.V(4).Infof("Deleting interface %s", containerConfig.InterfaceName)
@@ -452,12 +480,12 @@ func (pc *podConfigurator) reconcile(pods []corev1.Pod, containerAccess *contain
 	return nil
 }
 
-func (pc *podConfigurator) connectInterfaceToOVSCommon(ovsPortName string, containerConfig *interfacestore.InterfaceConfig) error {
+func (pc *podConfigurator) connectInterfaceToOVSCommon(ovsPortName, vhostUserSocket string, containerConfig *interfacestore.InterfaceConfig) error {
 	// create OVS Port and add attach container configuration into external_ids
 	containerID := containerConfig.ContainerID
 	klog.V(2).Infof("Adding OVS port %s for container %s", ovsPortName, containerID)
 	ovsAttachInfo := BuildOVSPortExternalIDs(containerConfig)
-	portUUID, err := pc.createOVSPort(ovsPortName, ovsAttachInfo)
+	portUUID, err := pc.createOVSPort(ovsPortName, ovsAttachInfo, vhostUserSocket)
 	if err != nil {
 		return fmt.Errorf("failed to add OVS port for container %s: %v", containerID, err)
 	}
@@ -482,9 +510,22 @@ func (pc *podConfigurator) connectInterfaceToOVSCommon(ovsPortName string, conta
 	containerConfig.OVSPortConfig = &interfacestore.OVSPortConfig{PortUUID: portUUID, OFPort: ofPort}
 	// Add containerConfig into local cache
 	pc.ifaceStore.AddInterface(containerConfig)
-	// Notify the Pod update event to required components.
+	// Notify the Pod update event to required components, and wait for the NetworkPolicy rules applied to the Pod
+	// to be realized, so that the Pod cannot send or receive any packet before its full rule set is enforced: the
+	// containers of the Pod are not started before the CNI ADD request returns.
+	realized := make(chan struct{})
 	pc.entityUpdates <- types.EntityReference{
-		Pod: &v1beta2.PodReference{Name: containerConfig.PodName, Namespace: containerConfig.PodNamespace},
+		Pod:      &v1beta2.PodReference{Name: containerConfig.PodName, Namespace: containerConfig.PodNamespace},
+		Realized: realized,
+	}
+	select {
+	case <-realized:
+		klog.V(2).Infof("NetworkPolicy rules for container %s have been realized", containerID)
+	case <-time.After(networkPolicyRealizationTimeout):
+		klog.Warningf("Timed out waiting for the NetworkPolicy rules for container %s to be realized", containerID)
+	}
+	if pc.podReadiness != nil {
+		go pc.podReadiness.handlePodAdd(containerConfig.PodNamespace, containerConfig.PodName, containerID, realized)
 	}
 	return nil
 }
@@ -17,11 +17,28 @@
 package cniserver
 
 import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
 	"github.com/containernetworking/cni/pkg/types/current"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+const (
+	// vhostUserSocketRootDir is the directory of the vhost-user sockets of the Pods. It is in the OVS run directory,
+	// which is mounted to both the antrea-agent and the antrea-ovs containers, i.e. /var/run/antrea/openvswitch on
+	// the Node.
+	vhostUserSocketRootDir = "/var/run/openvswitch/vhostuser"
+	// maxVhostUserSocketPathLen is the maximum length of the path of a Unix domain socket.
+	maxVhostUserSocketPathLen = 107
 )
 
 // connectInterfaceToOVS connects an existing interface to ovs br-int.
@@ -37,7 +54,7 @@ func (pc *podConfigurator) connectInterfaceToOVS(
 	// Use the outer veth interface name as the OVS port name.
 	ovsPortName := hostIface.Name
 	containerConfig := buildContainerConfig(ovsPortName, containerID, podName, podNameSpace, containerIface, ips)
-	return containerConfig, pc.connectInterfaceToOVSCommon(ovsPortName, containerConfig)
+	return containerConfig, pc.connectInterfaceToOVSCommon(ovsPortName, "", containerConfig)
 }
 
 func (pc *podConfigurator) reconcileMissingPods(pods sets.String, containerAccess *containerAccessArbitrator) {
@@ -54,3 +71,76 @@ func (pc *podConfigurator) reconcileMissingPods(pods sets.String, containerAcces
 		klog.Warningf("Interface for Pod %s not found in the interface store", pod)
 	}
 }
+
+// vhostUserSocketDir returns the directory of the vhost-user sockets of a Pod.
+func vhostUserSocketDir(podNamespace, podName string) string {
+	return filepath.Join(vhostUserSocketRootDir, podNamespace, podName)
+}
+
+// generateVhostUserMAC returns a random unicast, locally administered MAC address.
+func generateVhostUserMAC() (net.HardwareAddr, error) {
+	mac := make(net.HardwareAddr, 6)
+	if _, err := rand.Read(mac); err != nil {
+		return nil, fmt.Errorf("failed to generate MAC address: %v", err)
+	}
+	mac[0] = (mac[0] | 0x02) &^ 0x01
+	return mac, nil
+}
+
+// configureVhostUserInterface connects the Pod to OVS with a vhost-user port instead of a veth pair, for the DPDK
+// applications running in the Pod. No interface is created in the network namespace of the Pod: the application
+// creates the vhost-user server socket "<containerIFDev>.sock" in the directory returned by vhostUserSocketDir, OVS
+// connects to it as the client, and the application must use the MAC and IP addresses of the CNI result.
+func (pc *podConfigurator) configureVhostUserInterface(
+	podName string,
+	podNamespace string,
+	containerID string,
+	containerNetNS string,
+	containerIFDev string,
+	result *current.Result,
+	createOVSPort bool,
+) error {
+	if pc.ifConfigurator.ovsDatapathType != ovsconfig.OVSDatapathNetdev {
+		return fmt.Errorf("vhost-user interfaces require the %s OVS datapath type", ovsconfig.OVSDatapathNetdev)
+	}
+	socketDir := vhostUserSocketDir(podNamespace, podName)
+	socketPath := filepath.Join(socketDir, containerIFDev+".sock")
+	if len(socketPath) > maxVhostUserSocketPathLen {
+		return fmt.Errorf("vhost-user socket path %s is longer than %d characters", socketPath, maxVhostUserSocketPathLen)
+	}
+	mac, err := generateVhostUserMAC()
+	if err != nil {
+		return err
+	}
+	hostIface := &current.Interface{Name: util.GenerateContainerInterfaceName(podName, podNamespace, containerID)}
+	containerIface := &current.Interface{Name: containerIFDev, Mac: mac.String(), Sandbox: containerNetNS}
+	result.Interfaces = []*current.Interface{hostIface, containerIface}
+
+	if !createOVSPort {
+		return nil
+	}
+	if err := os.MkdirAll(socketDir, 0755); err != nil {
+		return fmt.Errorf("failed to create vhost-user socket directory %s: %v", socketDir, err)
+	}
+	klog.V(2).Infof("Adding vhost-user port for container %s with socket %s", containerID, socketPath)
+	// Use the generated host interface name as the OVS port name, like for a veth pair.
+	ovsPortName := hostIface.Name
+	containerConfig := buildContainerConfig(ovsPortName, containerID, podName, podNamespace, containerIface, result.IPs)
+	if err := pc.connectInterfaceToOVSCommon(ovsPortName, socketPath, containerConfig); err != nil {
+		return fmt.Errorf("failed to connect to ovs for container %s: %v", containerID, err)
+	}
+	klog.Infof("Configured vhost-user interface for container %s", containerID)
+	return nil
+}
+
+// removeVhostUserSocketDir removes the directory of the vhost-user sockets of a Pod, if any.
+func (pc *podConfigurator) removeVhostUserSocketDir(podNamespace, podName string) {
+	// Never remove the root directory, which has the sockets of all the Pods.
+	if podNamespace == "" || podName == "" {
+		return
+	}
+	socketDir := vhostUserSocketDir(podNamespace, podName)
+	if err := os.RemoveAll(socketDir); err != nil {
+		klog.Warningf("Failed to remove vhost-user socket directory %s: %v", socketDir, err)
+	}
+}
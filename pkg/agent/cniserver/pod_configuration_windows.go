@@ -60,7 +60,7 @@ func (pc *podConfigurator) connectInterfaceToOVSAsync(ifConfig *interfacestore.I
 				klog.Infof("Waiting for interface %s to be created", hostIfAlias)
 				return false, nil
 			}
-			if err := pc.connectInterfaceToOVSCommon(ovsPortName, ifConfig); err != nil {
+			if err := pc.connectInterfaceToOVSCommon(ovsPortName, "", ifConfig); err != nil {
 				return true, fmt.Errorf("failed to connect to OVS for container %s: %v", containerID, err)
 			}
 			return true, nil
@@ -94,7 +94,7 @@ func (pc *podConfigurator) connectInterfaceToOVS(
 	//   HNSEndpoint/HostComputeEndpoint, the current implementation will still work. It will choose the synchronized
 	//   way to create OVS port.
 	if util.HostInterfaceExists(hostIfAlias) {
-		return containerConfig, pc.connectInterfaceToOVSCommon(ovsPortName, containerConfig)
+		return containerConfig, pc.connectInterfaceToOVSCommon(ovsPortName, "", containerConfig)
 	} else {
 		return containerConfig, pc.connectInterfaceToOVSAsync(containerConfig, containerAccess)
 	}
@@ -113,3 +113,18 @@ func (pc *podConfigurator) reconcileMissingPods(pods sets.String, containerAcces
 		}
 	}
 }
+
+func (pc *podConfigurator) configureVhostUserInterface(
+	podName string,
+	podNamespace string,
+	containerID string,
+	containerNetNS string,
+	containerIFDev string,
+	result *current.Result,
+	createOVSPort bool,
+) error {
+	return fmt.Errorf("vhost-user interfaces are not supported on Windows")
+}
+
+func (pc *podConfigurator) removeVhostUserSocketDir(podNamespace, podName string) {
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniserver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+// NetworkReadyConditionType is the type of the Pod condition set by antrea-agent once the network of a Pod has been
+// fully programmed, i.e. the Pod flows have been installed and all the NetworkPolicy rules applied to the Pod have
+// been realized. It is only set for the Pods which have it as a readiness gate, so that they are not considered
+// Ready, and do not receive traffic from Services, before that.
+const NetworkReadyConditionType corev1.PodConditionType = "pod.antrea.io/network-ready"
+
+// networkReadyRetryInterval is the interval at which the realization of the NetworkPolicy rules applied to a Pod is
+// requested again if it has not completed, e.g. because antrea-agent is not connected to antrea-controller.
+const networkReadyRetryInterval = 30 * time.Second
+
+// podReadinessUpdater sets the NetworkReadyConditionType condition of the Pods which have it as a readiness gate.
+type podReadinessUpdater struct {
+	kubeClient    clientset.Interface
+	ifaceStore    interfacestore.InterfaceStore
+	entityUpdates chan<- types.EntityReference
+}
+
+func newPodReadinessUpdater(kubeClient clientset.Interface, ifaceStore interfacestore.InterfaceStore, entityUpdates chan<- types.EntityReference) *podReadinessUpdater {
+	return &podReadinessUpdater{
+		kubeClient:    kubeClient,
+		ifaceStore:    ifaceStore,
+		entityUpdates: entityUpdates,
+	}
+}
+
+// hasNetworkReadinessGate returns whether the Pod has NetworkReadyConditionType as a readiness gate.
+func hasNetworkReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == NetworkReadyConditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// isNetworkReady returns whether the NetworkReadyConditionType condition of the Pod is true.
+func isNetworkReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == NetworkReadyConditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// handlePodAdd sets the NetworkReadyConditionType condition of a new Pod once realized is closed, if the Pod has the
+// corresponding readiness gate. realized must be the channel sent with the Pod update to the NetworkPolicy controller.
+func (u *podReadinessUpdater) handlePodAdd(namespace, name, containerID string, realized <-chan struct{}) {
+	pod, err := u.kubeClient.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get Pod %s/%s to check its readiness gates: %v", namespace, name, err)
+		return
+	}
+	if !hasNetworkReadinessGate(pod) {
+		return
+	}
+	u.setNetworkReadyWhenRealized(namespace, name, containerID, realized)
+}
+
+// setNetworkReadyWhenRealized waits for the NetworkPolicy rules applied to the Pod to be realized, and then sets its
+// NetworkReadyConditionType condition. If realized is nil, or if the rules are not realized in time, the realization
+// is requested again, until the container interface of the Pod is deleted.
+func (u *podReadinessUpdater) setNetworkReadyWhenRealized(namespace, name, containerID string, realized <-chan struct{}) {
+	for {
+		if realized == nil {
+			ch := make(chan struct{})
+			u.entityUpdates <- types.EntityReference{
+				Pod:      &v1beta2.PodReference{Name: name, Namespace: namespace},
+				Realized: ch,
+			}
+			realized = ch
+		}
+		select {
+		case <-realized:
+			if err := u.setNetworkReady(namespace, name); err != nil {
+				klog.Errorf("Failed to set condition %s of Pod %s/%s: %v", NetworkReadyConditionType, namespace, name, err)
+			}
+			return
+		case <-time.After(networkReadyRetryInterval):
+		}
+		if _, found := u.ifaceStore.GetContainerInterface(containerID); !found {
+			return
+		}
+		klog.Warningf("NetworkPolicy rules for Pod %s/%s are still not realized, requesting their realization again", namespace, name)
+		realized = nil
+	}
+}
+
+// setNetworkReady sets the NetworkReadyConditionType condition of the Pod to true. The condition is merged with the
+// other conditions of the Pod, which are managed by kubelet.
+func (u *podReadinessUpdater) setNetworkReady(namespace, name string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.PodCondition{{
+				Type:               NetworkReadyConditionType,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return wait.ExponentialBackoff(retry.DefaultBackoff, func() (bool, error) {
+		if _, err := u.kubeClient.CoreV1().Pods(namespace).Patch(context.TODO(), name, apitypes.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+			klog.V(2).Infof("Failed to patch status of Pod %s/%s, retrying: %v", namespace, name, err)
+			return false, nil
+		}
+		klog.V(2).Infof("Set condition %s of Pod %s/%s", NetworkReadyConditionType, namespace, name)
+		return true, nil
+	})
+}
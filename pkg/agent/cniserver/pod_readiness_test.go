@@ -0,0 +1,104 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cniserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+)
+
+func newTestPod(name string, readinessGate bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		},
+	}
+	if readinessGate {
+		pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: NetworkReadyConditionType}}
+	}
+	return pod
+}
+
+func TestHandlePodAdd(t *testing.T) {
+	tests := []struct {
+		name          string
+		readinessGate bool
+		expectedReady bool
+	}{
+		{
+			name:          "with-readiness-gate",
+			readinessGate: true,
+			expectedReady: true,
+		},
+		{
+			name:          "without-readiness-gate",
+			readinessGate: false,
+			expectedReady: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("pod1", tt.readinessGate)
+			assert.Equal(t, tt.readinessGate, hasNetworkReadinessGate(pod))
+			kubeClient := fake.NewSimpleClientset(pod)
+			entityUpdates := make(chan types.EntityReference, 1)
+			u := newPodReadinessUpdater(kubeClient, interfacestore.NewInterfaceStore(), entityUpdates)
+
+			realized := make(chan struct{})
+			close(realized)
+			u.handlePodAdd("ns1", "pod1", "container1", realized)
+
+			updatedPod, err := kubeClient.CoreV1().Pods("ns1").Get(context.TODO(), "pod1", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedReady, isNetworkReady(updatedPod))
+			// The existing conditions must be preserved.
+			assert.Equal(t, corev1.PodScheduled, updatedPod.Status.Conditions[0].Type)
+			// The realization must not be requested again as the channel is provided.
+			assert.Empty(t, entityUpdates)
+		})
+	}
+}
+
+func TestSetNetworkReadyWhenRealizedRequestsRealization(t *testing.T) {
+	pod := newTestPod("pod1", true)
+	kubeClient := fake.NewSimpleClientset(pod)
+	entityUpdates := make(chan types.EntityReference, 1)
+	u := newPodReadinessUpdater(kubeClient, interfacestore.NewInterfaceStore(), entityUpdates)
+
+	done := make(chan struct{})
+	go func() {
+		u.setNetworkReadyWhenRealized("ns1", "pod1", "container1", nil)
+		close(done)
+	}()
+	update := <-entityUpdates
+	assert.Equal(t, "pod1", update.Pod.Name)
+	assert.Equal(t, "ns1", update.Pod.Namespace)
+	close(update.Realized)
+	<-done
+
+	updatedPod, err := kubeClient.CoreV1().Pods("ns1").Get(context.TODO(), "pod1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, isNetworkReady(updatedPod))
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	cnipb "github.com/vmware-tanzu/antrea/pkg/apis/cni/v1beta1"
 	"github.com/vmware-tanzu/antrea/pkg/cni"
+	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 )
 
@@ -128,6 +129,7 @@ type NetworkConfig struct {
 	Name       string          `json:"name,omitempty"`
 	Type       string          `json:"type,omitempty"`
 	DeviceID   string          `json:"deviceID"` // PCI address of a VF
+	VhostUser  bool            `json:"vhostUser,omitempty"`
 	MTU        int             `json:"mtu,omitempty"`
 	DNS        cnitypes.DNS    `json:"dns"`
 	IPAM       ipam.IPAMConfig `json:"ipam,omitempty"`
@@ -343,7 +345,7 @@ func (s *CNIServer) parsePrevResultFromRequest(networkConfig *NetworkConfig) (*c
 
 // validatePrevResult validates container and host interfaces configuration
 // the return value is nil if prevResult is valid
-func (s *CNIServer) validatePrevResult(cfgArgs *cnipb.CniCmdArgs, k8sCNIArgs *k8sArgs, prevResult *current.Result, sriovVFDeviceID string) *cnipb.CniCmdResponse {
+func (s *CNIServer) validatePrevResult(cfgArgs *cnipb.CniCmdArgs, k8sCNIArgs *k8sArgs, prevResult *current.Result, sriovVFDeviceID string, vhostUser bool) *cnipb.CniCmdResponse {
 	containerID := cfgArgs.ContainerId
 	netNS := s.hostNetNsPath(cfgArgs.Netns)
 
@@ -353,6 +355,13 @@ func (s *CNIServer) validatePrevResult(cfgArgs *cnipb.CniCmdArgs, k8sCNIArgs *k8
 		klog.Errorf("Failed to find interface %s of container %s", cfgArgs.Ifname, containerID)
 		return s.invalidNetworkConfigResponse("prevResult does not match network configuration")
 	}
+	if vhostUser {
+		// There is no interface in the network namespace of a Pod connected with vhost-user, only the OVS port.
+		if err := s.podConfigurator.validateOVSInterfaceConfig(containerID, containerIntf.Mac, prevResult.IPs); err != nil {
+			return s.checkInterfaceFailureResponse(err)
+		}
+		return nil
+	}
 	if err := s.podConfigurator.checkInterfaces(
 		containerID,
 		netNS,
@@ -444,6 +453,7 @@ func (s *CNIServer) CmdAdd(ctx context.Context, request *cnipb.CniCmdRequest) (*
 		cniConfig.Ifname,
 		cniConfig.MTU,
 		cniConfig.DeviceID,
+		cniConfig.VhostUser,
 		result,
 		isInfraContainer,
 		s.containerAccess,
@@ -517,7 +527,7 @@ func (s *CNIServer) CmdCheck(_ context.Context, request *cnipb.CniCmdRequest) (
 	if valid, _ := version.GreaterThanOrEqualTo(cniVersion, "0.4.0"); valid {
 		if prevResult, response := s.parsePrevResultFromRequest(cniConfig.NetworkConfig); response != nil {
 			return response, nil
-		} else if response := s.validatePrevResult(cniConfig.CniCmdArgs, cniConfig.k8sArgs, prevResult, cniConfig.DeviceID); response != nil {
+		} else if response := s.validatePrevResult(cniConfig.CniCmdArgs, cniConfig.k8sArgs, prevResult, cniConfig.DeviceID, cniConfig.VhostUser); response != nil {
 			return response, nil
 		}
 	}
@@ -561,6 +571,9 @@ func (s *CNIServer) Initialize(
 	if err != nil {
 		return fmt.Errorf("error during initialize podConfigurator: %v", err)
 	}
+	if features.DefaultFeatureGate.Enabled(features.PodReadinessGate) {
+		s.podConfigurator.podReadiness = newPodReadinessUpdater(s.kubeClient, ifaceStore, entityUpdates)
+	}
 	if err := s.reconcile(); err != nil {
 		return fmt.Errorf("error during initial reconciliation for CNI server: %v", err)
 	}
@@ -264,7 +264,7 @@ func TestValidatePrevResult(t *testing.T) {
 		cniConfig.Ifname = "invalid_iface" // invalid
 		sriovVFDeviceID := ""
 		prevResult.Interfaces = []*current.Interface{hostIface, containerIface}
-		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID)
+		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID, false)
 		checkErrorResponse(
 			t, response, cnipb.ErrorCode_INVALID_NETWORK_CONFIG,
 			"prevResult does not match network configuration",
@@ -276,7 +276,7 @@ func TestValidatePrevResult(t *testing.T) {
 		cniConfig.Ifname = "invalid_iface" // invalid
 		sriovVFDeviceID := "0000:03:00.6"
 		prevResult.Interfaces = []*current.Interface{hostIface, containerIface}
-		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID)
+		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID, false)
 		checkErrorResponse(
 			t, response, cnipb.ErrorCode_INVALID_NETWORK_CONFIG,
 			"prevResult does not match network configuration",
@@ -290,7 +290,7 @@ func TestValidatePrevResult(t *testing.T) {
 		sriovVFDeviceID := ""
 		prevResult.Interfaces = []*current.Interface{hostIface, containerIface}
 		cniServer.podConfigurator, _ = newPodConfigurator(nil, nil, nil, nil, nil, "", false, make(chan antreatypes.EntityReference, 100))
-		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID)
+		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID, false)
 		checkErrorResponse(t, response, cnipb.ErrorCode_CHECK_INTERFACE_FAILURE, "")
 	})
 
@@ -301,7 +301,7 @@ func TestValidatePrevResult(t *testing.T) {
 		sriovVFDeviceID := "0000:03:00.6"
 		prevResult.Interfaces = []*current.Interface{hostIface, containerIface}
 		cniServer.podConfigurator, _ = newPodConfigurator(nil, nil, nil, nil, nil, "", true, make(chan antreatypes.EntityReference, 100))
-		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID)
+		response := cniServer.validatePrevResult(cniConfig.CniCmdArgs, k8sPodArgs, prevResult, sriovVFDeviceID, false)
 		checkErrorResponse(t, response, cnipb.ErrorCode_CHECK_INTERFACE_FAILURE, "")
 	})
 }
@@ -493,6 +493,38 @@ func TestRemoveInterface(t *testing.T) {
 	})
 }
 
+func TestConfigureVhostUserInterface(t *testing.T) {
+	containerID := uuid.New().String()
+	newResult := func() *current.Result {
+		return ipamtest.GenerateIPAMResult(supportedCNIVersion, ips, routes, dns)
+	}
+
+	t.Run("system datapath", func(t *testing.T) {
+		podConfigurator, _ := newPodConfigurator(nil, nil, nil, nil, nil, ovsconfig.OVSDatapathSystem, false, make(chan antreatypes.EntityReference, 100))
+		err := podConfigurator.configureInterfaces(testPodName, testPodNamespace, containerID, netns, ifname, 1450, "", true, newResult(), false, nil)
+		assert.Error(t, err, "vhost-user interfaces should require the netdev datapath")
+	})
+
+	t.Run("socket path too long", func(t *testing.T) {
+		podConfigurator, _ := newPodConfigurator(nil, nil, nil, nil, nil, ovsconfig.OVSDatapathNetdev, false, make(chan antreatypes.EntityReference, 100))
+		err := podConfigurator.configureInterfaces(strings.Repeat("a", 80), testPodNamespace, containerID, netns, ifname, 1450, "", true, newResult(), false, nil)
+		assert.Error(t, err, "vhost-user socket path should be too long")
+	})
+
+	t.Run("netdev datapath", func(t *testing.T) {
+		podConfigurator, _ := newPodConfigurator(nil, nil, nil, nil, nil, ovsconfig.OVSDatapathNetdev, false, make(chan antreatypes.EntityReference, 100))
+		result := newResult()
+		err := podConfigurator.configureInterfaces(testPodName, testPodNamespace, containerID, netns, ifname, 1450, "", true, result, false, nil)
+		require.NoError(t, err)
+		require.Len(t, result.Interfaces, 2)
+		assert.Equal(t, util.GenerateContainerInterfaceName(testPodName, testPodNamespace, containerID), result.Interfaces[0].Name)
+		assert.Equal(t, ifname, result.Interfaces[1].Name)
+		mac, err := net.ParseMAC(result.Interfaces[1].Mac)
+		require.NoError(t, err)
+		assert.Equal(t, byte(0x02), mac[0]&0x03, "MAC should be a unicast, locally administered address")
+	})
+}
+
 func TestBuildOVSPortExternalIDs(t *testing.T) {
 	containerID := uuid.New().String()
 	containerMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
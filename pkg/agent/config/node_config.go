@@ -39,10 +39,22 @@ const (
 	GREOverhead    = 38
 	// IPsec ESP can add a maximum of 38 bytes to the packet including the ESP
 	// header and trailer.
-	IPSecESPOverhead  = 38
+	IPSecESPOverhead = 38
+	// WireGuard adds an outer IP header (up to 40 bytes for IPv6), a UDP header
+	// and a 32-byte WireGuard header and trailer to the packet.
+	WireGuardOverhead = 80
 	IPv6ExtraOverhead = 20
 )
 
+var (
+	// VirtualNodePortIPv4 is the virtual IP address to which the host network DNATs the NodePort Service traffic
+	// destined to the Node IP address, when NodePort Services are handled by AntreaProxy. The traffic is routed
+	// to the host gateway interface, and load-balanced to the Service Endpoints in the OVS pipeline.
+	VirtualNodePortIPv4 = net.ParseIP("169.254.169.110")
+	// VirtualNodePortIPv6 is the IPv6 counterpart of VirtualNodePortIPv4.
+	VirtualNodePortIPv6 = net.ParseIP("fc01::aabb:ccdd:eeff")
+)
+
 type GatewayConfig struct {
 	// Name is the name of host gateway, e.g. antrea-gw0.
 	Name string
@@ -58,6 +70,16 @@ func (g *GatewayConfig) String() string {
 	return fmt.Sprintf("Name %s: IPv4 %s, IPv6 %s, MAC %s", g.Name, g.IPv4, g.IPv6, g.MAC)
 }
 
+type WireGuardConfig struct {
+	// Name is the name of the WireGuard interface, e.g. antrea-wg0.
+	Name string
+	// Port is the UDP port on which WireGuard listens.
+	Port int
+	MTU  int
+	// LinkIndex is the link index of the WireGuard interface.
+	LinkIndex int
+}
+
 type AdapterNetConfig struct {
 	Name       string
 	Index      int
@@ -93,6 +115,9 @@ type NodeConfig struct {
 	GatewayConfig *GatewayConfig
 	// The config of the OVS bridge uplink interface. Only for Windows Node.
 	UplinkNetConfig *AdapterNetConfig
+	// The config of the WireGuard interface. It's nil unless the wireGuard
+	// trafficEncryptionMode is used.
+	WireGuardConfig *WireGuardConfig
 }
 
 func (n *NodeConfig) String() string {
@@ -102,10 +127,21 @@ func (n *NodeConfig) String() string {
 
 // User provided network configuration parameters.
 type NetworkConfig struct {
-	TrafficEncapMode  TrafficEncapModeType
-	TunnelType        ovsconfig.TunnelType
-	EnableIPSecTunnel bool
-	IPSecPSK          string
+	TrafficEncapMode TrafficEncapModeType
+	TunnelType       ovsconfig.TunnelType
+	// FallbackTunnelTypes are the alternative tunnel types, in order of preference, which
+	// can be negotiated with peer Nodes when the default TunnelType cannot be used.
+	FallbackTunnelTypes   []ovsconfig.TunnelType
+	TrafficEncryptionMode TrafficEncryptionModeType
+	IPSecPSK              string
+}
+
+// SupportedTunnelTypes returns the tunnel types that can be used by this Node, in order of
+// preference: the fallback tunnel types followed by the default tunnel type.
+func (nc *NetworkConfig) SupportedTunnelTypes() []ovsconfig.TunnelType {
+	tunnelTypes := make([]ovsconfig.TunnelType, 0, len(nc.FallbackTunnelTypes)+1)
+	tunnelTypes = append(tunnelTypes, nc.FallbackTunnelTypes...)
+	return append(tunnelTypes, nc.TunnelType)
 }
 
 // IsIPv4Enabled returns true if the cluster network supports IPv4.
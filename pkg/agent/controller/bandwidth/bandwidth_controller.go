@@ -0,0 +1,239 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+)
+
+const (
+	controllerName = "AntreaAgentBandwidthController"
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of a Pod.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+
+	// The annotations used by the Kubernetes bandwidth plugin to limit the bandwidth of a Pod, in bits per second.
+	IngressBandwidthAnnotation = "kubernetes.io/ingress-bandwidth"
+	EgressBandwidthAnnotation  = "kubernetes.io/egress-bandwidth"
+)
+
+var (
+	// The bounds enforced by kubelet for the bandwidth annotations.
+	minBandwidth = resource.MustParse("1k")
+	maxBandwidth = resource.MustParse("1P")
+)
+
+// Controller is responsible for enforcing the bandwidth annotations of the local Pods with OpenFlow meters, instead
+// of tc qdiscs as the Kubernetes bandwidth plugin does.
+type Controller struct {
+	ofClient        openflow.Client
+	interfaceStore  interfacestore.InterfaceStore
+	podInformer     cache.SharedIndexInformer
+	podLister       corelisters.PodLister
+	podListerSynced cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	// podInterfaces maps the local Pods (namespace/name) to the interfaces for which a bandwidth limit is installed.
+	// It is only accessed by the single worker of the controller.
+	podInterfaces map[string]string
+}
+
+// NewBandwidthController instantiates a new Controller object which will process the events of the local Pods.
+func NewBandwidthController(
+	kubeClient clientset.Interface,
+	ofClient openflow.Client,
+	interfaceStore interfacestore.InterfaceStore,
+	nodeName string) *Controller {
+	// Watch only the Pods which belong to the Node where the agent is running.
+	listOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	podInformer := coreinformers.NewFilteredPodInformer(
+		kubeClient,
+		metav1.NamespaceAll,
+		resyncPeriod,
+		cache.Indexers{},
+		listOptions,
+	)
+	c := &Controller{
+		ofClient:        ofClient,
+		interfaceStore:  interfaceStore,
+		podInformer:     podInformer,
+		podLister:       corelisters.NewPodLister(podInformer.GetIndexer()),
+		podListerSynced: podInformer.HasSynced,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "bandwidth"),
+		podInterfaces:   make(map[string]string),
+	}
+	podInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueuePod,
+			UpdateFunc: func(_, obj interface{}) { c.enqueuePod(obj) },
+			DeleteFunc: c.enqueuePod,
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+func (c *Controller) enqueuePod(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get key of Pod %v: %v", obj, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// Run will start the local Pod informer and a single worker which will process the Pod events from the work queue.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	go c.podInformer.Run(stopCh)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.podListerSynced) {
+		return
+	}
+
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the work queue.
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	key := obj.(string)
+	if err := c.syncPod(key); err != nil {
+		klog.Errorf("Error syncing bandwidth of Pod %s, requeuing: %v", key, err)
+		c.queue.AddRateLimited(obj)
+		return true
+	}
+	c.queue.Forget(obj)
+	return true
+}
+
+// syncPod reconciles the bandwidth limit installed for the Pod with its annotations.
+func (c *Controller) syncPod(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	var ingressRate, egressRate uint64
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if pod != nil && !pod.Spec.HostNetwork {
+		ingressRate, egressRate, err = getPodBandwidth(pod)
+		if err != nil {
+			// Retrying won't help until the annotations are updated, which generates a new event.
+			klog.Errorf("Invalid bandwidth annotations for Pod %s: %v", key, err)
+			ingressRate, egressRate = 0, 0
+		}
+	}
+
+	var interfaceName string
+	var ofPort uint32
+	if ifaces := c.interfaceStore.GetContainerInterfacesByPod(name, namespace); len(ifaces) > 0 {
+		interfaceName, ofPort = ifaces[0].InterfaceName, uint32(ifaces[0].OFPort)
+	}
+	limited := interfaceName != "" && (ingressRate > 0 || egressRate > 0)
+	// Remove the limit installed for a stale interface of the Pod, e.g. if its sandbox has been recreated.
+	if installed, exists := c.podInterfaces[key]; exists && (!limited || installed != interfaceName) {
+		if err := c.ofClient.UninstallPodBandwidthFlows(installed); err != nil {
+			return fmt.Errorf("error uninstalling bandwidth flows for interface %s: %v", installed, err)
+		}
+		delete(c.podInterfaces, key)
+	}
+	if !limited {
+		// If the interface is not found, the Pod will be processed again when its IP is reported, after the CNI ADD
+		// request is completed.
+		return nil
+	}
+	if err := c.ofClient.InstallPodBandwidthFlows(interfaceName, ofPort, ingressRate, egressRate); err != nil {
+		return fmt.Errorf("error installing bandwidth flows for interface %s: %v", interfaceName, err)
+	}
+	c.podInterfaces[key] = interfaceName
+	return nil
+}
+
+// getPodBandwidth returns the ingress and egress bandwidth limits of the Pod, in bits per second. 0 means that the
+// bandwidth is not limited.
+func getPodBandwidth(pod *corev1.Pod) (uint64, uint64, error) {
+	ingressRate, err := parseBandwidth(pod.Annotations, IngressBandwidthAnnotation)
+	if err != nil {
+		return 0, 0, err
+	}
+	egressRate, err := parseBandwidth(pod.Annotations, EgressBandwidthAnnotation)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ingressRate, egressRate, nil
+}
+
+func parseBandwidth(annotations map[string]string, key string) (uint64, error) {
+	value, exists := annotations[key]
+	if !exists {
+		return 0, nil
+	}
+	rate, err := ParseRate(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for annotation %s: %v", key, err)
+	}
+	return rate, nil
+}
+
+// ParseRate parses a bandwidth in bits per second, e.g. "10M", within the bounds enforced by kubelet.
+func ParseRate(value string) (uint64, error) {
+	rate, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %v", value, err)
+	}
+	if rate.Cmp(minBandwidth) < 0 || rate.Cmp(maxBandwidth) > 0 {
+		return 0, fmt.Errorf("bandwidth %q is out of range [%s, %s]", value, minBandwidth.String(), maxBandwidth.String())
+	}
+	return uint64(rate.Value()), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+)
+
+type fakeController struct {
+	*Controller
+	ofClient       *oftest.MockClient
+	interfaceStore interfacestore.InterfaceStore
+}
+
+func newController(t *testing.T) (*fakeController, func()) {
+	ctrl := gomock.NewController(t)
+	ofClient := oftest.NewMockClient(ctrl)
+	interfaceStore := interfacestore.NewInterfaceStore()
+	c := NewBandwidthController(fake.NewSimpleClientset(), ofClient, interfaceStore, "node1")
+	return &fakeController{
+		Controller:     c,
+		ofClient:       ofClient,
+		interfaceStore: interfaceStore,
+	}, ctrl.Finish
+}
+
+func (c *fakeController) updatePod(name string, annotations map[string]string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name, Annotations: annotations},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+	}
+	c.podInformer.GetIndexer().Update(pod)
+}
+
+func (c *fakeController) addInterface(podName, interfaceName string, ofPort int32) {
+	iface := interfacestore.NewContainerInterface(interfaceName, interfaceName, podName, "ns1", nil, []net.IP{net.ParseIP("10.10.0.2")})
+	iface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: ofPort}
+	c.interfaceStore.AddInterface(iface)
+}
+
+func TestSyncPod(t *testing.T) {
+	c, closeFn := newController(t)
+	defer closeFn()
+
+	// The interface of the Pod is not created yet.
+	c.updatePod("podA", map[string]string{IngressBandwidthAnnotation: "10M", EgressBandwidthAnnotation: "1G"})
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+
+	c.addInterface("podA", "podA-1", 1)
+	c.ofClient.EXPECT().InstallPodBandwidthFlows("podA-1", uint32(1), uint64(10000000), uint64(1000000000))
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Equal(t, map[string]string{"ns1/podA": "podA-1"}, c.podInterfaces)
+
+	c.updatePod("podA", map[string]string{EgressBandwidthAnnotation: "100M"})
+	c.ofClient.EXPECT().InstallPodBandwidthFlows("podA-1", uint32(1), uint64(0), uint64(100000000))
+	require.NoError(t, c.syncPod("ns1/podA"))
+
+	// Invalid annotations are handled as if the bandwidth was not limited.
+	c.updatePod("podA", map[string]string{EgressBandwidthAnnotation: "10P"})
+	c.ofClient.EXPECT().UninstallPodBandwidthFlows("podA-1")
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+
+	c.updatePod("podA", map[string]string{EgressBandwidthAnnotation: "100M"})
+	c.ofClient.EXPECT().InstallPodBandwidthFlows("podA-1", uint32(1), uint64(0), uint64(100000000))
+	require.NoError(t, c.syncPod("ns1/podA"))
+
+	c.podInformer.GetIndexer().Delete(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "podA"}})
+	c.ofClient.EXPECT().UninstallPodBandwidthFlows("podA-1")
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+}
+
+func TestGetPodBandwidth(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		expectedIngress uint64
+		expectedEgress  uint64
+		expectedErr     bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:            "ingress and egress",
+			annotations:     map[string]string{IngressBandwidthAnnotation: "1k", EgressBandwidthAnnotation: "2.5M"},
+			expectedIngress: 1000,
+			expectedEgress:  2500000,
+		},
+		{
+			name:        "invalid quantity",
+			annotations: map[string]string{IngressBandwidthAnnotation: "foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "too small",
+			annotations: map[string]string{EgressBandwidthAnnotation: "10"},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			ingress, egress, err := getPodBandwidth(pod)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIngress, ingress)
+			assert.Equal(t, tt.expectedEgress, egress)
+		})
+	}
+}
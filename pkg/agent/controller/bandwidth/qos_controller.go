@@ -0,0 +1,331 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+const (
+	qosControllerName = "AntreaAgentQoSController"
+
+	// QoSClassAnnotation is the annotation used to set the QoS class of a Pod.
+	QoSClassAnnotation = "qos.antrea.io/class"
+
+	// defaultQueueID is the queue of the uplink port used by the traffic of the Pods without a QoS class. The
+	// queues of the QoS classes come next, in the order of the classes.
+	defaultQueueID = 0
+)
+
+// QoSClass is a class of Pods whose egress traffic leaving the OVS bridge is assigned to a dedicated OVS QoS queue of
+// the uplink port, and optionally marked with a DSCP value.
+type QoSClass struct {
+	Name string
+	// PriorityClassNames are the PriorityClasses of the Pods of the class, for the Pods without the
+	// QoSClassAnnotation.
+	PriorityClassNames []string
+	// MinRate is the rate guaranteed to the class under congestion, and MaxRate the rate that the class cannot
+	// exceed, in bits per second. 0 means that the rate is not set.
+	MinRate uint64
+	MaxRate uint64
+	// DSCP is the value set in the DSCP field of the IP packets of the class, so that the underlay network can
+	// prioritize them. 0 means that the DSCP field is not modified.
+	DSCP uint8
+}
+
+// QoSController is responsible for sending the egress traffic of the local Pods to the OVS QoS queues of their
+// classes, and marking it with the DSCP values of their classes. The DSCP is set in the IP packets leaving the OVS
+// bridge through any of the egress ports: the host gateway, the default tunnel port, and the uplink port when it is
+// connected to the bridge. The queues are only set to the uplink port, which uses a linux-htb QoS guaranteeing the
+// minimum rate of each class when the traffic exceeds the maximum rate shared by all the queues. The linux-htb QoS
+// does not shape the traffic output to the host gateway, an OVS internal port, nor to the tunnel port, a vport, so
+// the queues are not set to them.
+type QoSController struct {
+	ofClient        openflow.Client
+	ovsBridgeClient ovsconfig.OVSBridgeClient
+	interfaceStore  interfacestore.InterfaceStore
+	maxRate         uint64
+	classes         []QoSClass
+	// classQueueIDs and priorityClassQueueIDs map the names of the QoS classes and PriorityClasses to the IDs of the
+	// queues of their classes.
+	classQueueIDs         map[string]uint32
+	priorityClassQueueIDs map[string]uint32
+	// queueDSCPs maps the IDs of the queues to the DSCP values of their classes.
+	queueDSCPs map[uint32]uint8
+	// egressOFPorts are the OpenFlow ports of the egress ports, and queueOFPorts those of the egress ports with a
+	// QoS, set once their QoS is set.
+	egressOFPorts   []uint32
+	queueOFPorts    []uint32
+	podInformer     cache.SharedIndexInformer
+	podLister       corelisters.PodLister
+	podListerSynced cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	// podInterfaces maps the local Pods (namespace/name) to the interfaces for which a queue is assigned. It is
+	// only accessed by the single worker of the controller.
+	podInterfaces map[string]string
+}
+
+// NewQoSController instantiates a new QoSController object which will process the events of the local Pods. maxRate
+// is the rate shared by all the queues of the uplink port, in bits per second.
+func NewQoSController(
+	kubeClient clientset.Interface,
+	ofClient openflow.Client,
+	ovsBridgeClient ovsconfig.OVSBridgeClient,
+	interfaceStore interfacestore.InterfaceStore,
+	maxRate uint64,
+	classes []QoSClass,
+	nodeName string) *QoSController {
+	// Watch only the Pods which belong to the Node where the agent is running.
+	listOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	podInformer := coreinformers.NewFilteredPodInformer(
+		kubeClient,
+		metav1.NamespaceAll,
+		resyncPeriod,
+		cache.Indexers{},
+		listOptions,
+	)
+	c := &QoSController{
+		ofClient:              ofClient,
+		ovsBridgeClient:       ovsBridgeClient,
+		interfaceStore:        interfaceStore,
+		maxRate:               maxRate,
+		classes:               classes,
+		classQueueIDs:         make(map[string]uint32),
+		priorityClassQueueIDs: make(map[string]uint32),
+		queueDSCPs:            make(map[uint32]uint8),
+		podInformer:           podInformer,
+		podLister:             corelisters.NewPodLister(podInformer.GetIndexer()),
+		podListerSynced:       podInformer.HasSynced,
+		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "qos"),
+		podInterfaces:         make(map[string]string),
+	}
+	for i, class := range classes {
+		queueID := uint32(defaultQueueID + i + 1)
+		c.classQueueIDs[class.Name] = queueID
+		c.queueDSCPs[queueID] = class.DSCP
+		for _, priorityClassName := range class.PriorityClassNames {
+			c.priorityClassQueueIDs[priorityClassName] = queueID
+		}
+	}
+	podInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueuePod,
+			UpdateFunc: func(_, obj interface{}) { c.enqueuePod(obj) },
+			DeleteFunc: c.enqueuePod,
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+func (c *QoSController) enqueuePod(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get key of Pod %v: %v", obj, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// Run will set the QoS of the egress ports, then start the local Pod informer and a single worker which will process
+// the Pod events from the work queue.
+func (c *QoSController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", qosControllerName)
+	defer klog.Infof("Shutting down %s", qosControllerName)
+
+	if len(getQueuePorts(c.interfaceStore)) == 0 {
+		klog.Errorf("The uplink is not connected to the OVS bridge, the QoS queues are not used and only the DSCP of the QoS classes is set")
+	}
+	if err := wait.PollImmediateUntil(minRetryDelay, func() (bool, error) {
+		if err := c.setEgressPortsQoS(); err != nil {
+			klog.Errorf("Failed to set the QoS of the egress ports, retrying: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}, stopCh); err != nil {
+		return
+	}
+
+	go c.podInformer.Run(stopCh)
+
+	if !cache.WaitForNamedCacheSync(qosControllerName, stopCh, c.podListerSynced) {
+		return
+	}
+
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+// getEgressPorts returns the ports through which the traffic of the Pods leaves the OVS bridge: the host gateway,
+// the default tunnel port, and the uplink port. The tunnel ports created for each Node with IPsec are not included.
+func getEgressPorts(interfaceStore interfacestore.InterfaceStore) []*interfacestore.InterfaceConfig {
+	ports := interfaceStore.GetInterfacesByType(interfacestore.GatewayInterface)
+	for _, iface := range interfaceStore.GetInterfacesByType(interfacestore.TunnelInterface) {
+		if iface.NodeName == "" {
+			ports = append(ports, iface)
+		}
+	}
+	ports = append(ports, getQueuePorts(interfaceStore)...)
+	return ports
+}
+
+// getQueuePorts returns the egress ports which support the QoS queues, i.e. the uplink port.
+func getQueuePorts(interfaceStore interfacestore.InterfaceStore) []*interfacestore.InterfaceConfig {
+	return interfaceStore.GetInterfacesByType(interfacestore.UplinkInterface)
+}
+
+func sortedOFPorts(ports []*interfacestore.InterfaceConfig) []uint32 {
+	ofPorts := make([]uint32, 0, len(ports))
+	for _, port := range ports {
+		ofPorts = append(ofPorts, uint32(port.OFPort))
+	}
+	sort.Slice(ofPorts, func(i, j int) bool { return ofPorts[i] < ofPorts[j] })
+	return ofPorts
+}
+
+// setEgressPortsQoS sets the queues of the QoS classes to the egress ports which support them, replacing the queues
+// set by a previous run of the agent. The traffic of the Pods without a QoS class is sent to the default queue, which has no minimum
+// rate.
+func (c *QoSController) setEgressPortsQoS() error {
+	queues := map[uint32]ovsconfig.QueueConfig{
+		defaultQueueID: {MaxRate: c.maxRate},
+	}
+	for _, class := range c.classes {
+		queues[c.classQueueIDs[class.Name]] = ovsconfig.QueueConfig{MinRate: class.MinRate, MaxRate: class.MaxRate}
+	}
+	queuePorts := getQueuePorts(c.interfaceStore)
+	for _, port := range queuePorts {
+		if err := c.ovsBridgeClient.SetPortQoS(port.InterfaceName, c.maxRate, queues); err != nil {
+			return fmt.Errorf("error setting the QoS of port %s: %v", port.InterfaceName, err)
+		}
+		klog.Infof("Set the QoS of port %s with %d classes", port.InterfaceName, len(c.classes))
+	}
+	c.egressOFPorts = sortedOFPorts(getEgressPorts(c.interfaceStore))
+	c.queueOFPorts = sortedOFPorts(queuePorts)
+	return nil
+}
+
+// DeleteEgressPortsQoS removes the QoS set to the egress ports by a previous run of the agent with the PodQoS feature
+// enabled, together with its queues. It does nothing for the ports without a QoS.
+func DeleteEgressPortsQoS(ovsBridgeClient ovsconfig.OVSBridgeClient, interfaceStore interfacestore.InterfaceStore) error {
+	for _, port := range getQueuePorts(interfaceStore) {
+		if err := ovsBridgeClient.DeletePortQoS(port.InterfaceName); err != nil {
+			return fmt.Errorf("error deleting the QoS of port %s: %v", port.InterfaceName, err)
+		}
+	}
+	return nil
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the work queue.
+func (c *QoSController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *QoSController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	key := obj.(string)
+	if err := c.syncPod(key); err != nil {
+		klog.Errorf("Error syncing QoS of Pod %s, requeuing: %v", key, err)
+		c.queue.AddRateLimited(obj)
+		return true
+	}
+	c.queue.Forget(obj)
+	return true
+}
+
+// syncPod reconciles the queue and the DSCP assigned to the traffic of the Pod with its QoS class.
+func (c *QoSController) syncPod(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	queueID := uint32(defaultQueueID)
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if pod != nil && !pod.Spec.HostNetwork {
+		queueID = c.getPodQueueID(pod)
+	}
+
+	var interfaceName string
+	var ofPort uint32
+	if ifaces := c.interfaceStore.GetContainerInterfacesByPod(name, namespace); len(ifaces) > 0 {
+		interfaceName, ofPort = ifaces[0].InterfaceName, uint32(ifaces[0].OFPort)
+	}
+	assigned := interfaceName != "" && queueID != defaultQueueID
+	// Remove the queue assigned to a stale interface of the Pod, e.g. if its sandbox has been recreated.
+	if installed, exists := c.podInterfaces[key]; exists && (!assigned || installed != interfaceName) {
+		if err := c.ofClient.UninstallPodQoSFlows(installed); err != nil {
+			return fmt.Errorf("error uninstalling QoS flows for interface %s: %v", installed, err)
+		}
+		delete(c.podInterfaces, key)
+	}
+	if !assigned {
+		// If the interface is not found, the Pod will be processed again when its IP is reported, after the CNI ADD
+		// request is completed.
+		return nil
+	}
+	if err := c.ofClient.InstallPodQoSFlows(interfaceName, ofPort, queueID, c.queueDSCPs[queueID], c.egressOFPorts, c.queueOFPorts); err != nil {
+		return fmt.Errorf("error installing QoS flows for interface %s: %v", interfaceName, err)
+	}
+	c.podInterfaces[key] = interfaceName
+	return nil
+}
+
+// getPodQueueID returns the ID of the queue of the QoS class of the Pod. The class set with the QoSClassAnnotation
+// takes precedence over the class of the PriorityClass of the Pod.
+func (c *QoSController) getPodQueueID(pod *corev1.Pod) uint32 {
+	if className, exists := pod.Annotations[QoSClassAnnotation]; exists {
+		if queueID, exists := c.classQueueIDs[className]; exists {
+			return queueID
+		}
+		// Retrying won't help until the annotation is updated, which generates a new event.
+		klog.Errorf("Unknown QoS class %s for Pod %s/%s", className, pod.Namespace, pod.Name)
+	}
+	if queueID, exists := c.priorityClassQueueIDs[pod.Spec.PriorityClassName]; exists {
+		return queueID
+	}
+	return defaultQueueID
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+	ovsconfigtest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig/testing"
+)
+
+var testQoSClasses = []QoSClass{
+	{Name: "gold", PriorityClassNames: []string{"high-priority"}, MinRate: 1000000000, MaxRate: 10000000000, DSCP: 46},
+	{Name: "silver", MinRate: 100000000, MaxRate: 1000000000},
+}
+
+type fakeQoSController struct {
+	*QoSController
+	ofClient        *oftest.MockClient
+	ovsBridgeClient *ovsconfigtest.MockOVSBridgeClient
+	interfaceStore  interfacestore.InterfaceStore
+}
+
+func newQoSController(t *testing.T) (*fakeQoSController, func()) {
+	ctrl := gomock.NewController(t)
+	ofClient := oftest.NewMockClient(ctrl)
+	ovsBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(ctrl)
+	interfaceStore := interfacestore.NewInterfaceStore()
+	gatewayIface := interfacestore.NewGatewayInterface("antrea-gw0")
+	gatewayIface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: 2}
+	interfaceStore.AddInterface(gatewayIface)
+	tunnelIface := interfacestore.NewTunnelInterface("antrea-tun0", ovsconfig.GeneveTunnel, nil, false)
+	tunnelIface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: 1}
+	interfaceStore.AddInterface(tunnelIface)
+	// The tunnel ports created for each Node with IPsec are not egress ports.
+	ipsecIface := interfacestore.NewIPSecTunnelInterface("node2-abcdef", ovsconfig.GeneveTunnel, "node2", net.ParseIP("10.0.0.2"), "psk")
+	ipsecIface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: 5}
+	interfaceStore.AddInterface(ipsecIface)
+	uplinkIface := interfacestore.NewUplinkInterface("eth0")
+	uplinkIface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: 3}
+	interfaceStore.AddInterface(uplinkIface)
+	c := NewQoSController(fake.NewSimpleClientset(), ofClient, ovsBridgeClient, interfaceStore, 10000000000, testQoSClasses, "node1")
+	return &fakeQoSController{
+		QoSController:   c,
+		ofClient:        ofClient,
+		ovsBridgeClient: ovsBridgeClient,
+		interfaceStore:  interfaceStore,
+	}, ctrl.Finish
+}
+
+func (c *fakeQoSController) updatePod(name, priorityClassName string, annotations map[string]string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name, Annotations: annotations},
+		Spec:       corev1.PodSpec{NodeName: "node1", PriorityClassName: priorityClassName},
+	}
+	c.podInformer.GetIndexer().Update(pod)
+}
+
+func (c *fakeQoSController) addInterface(podName, interfaceName string, ofPort int32) {
+	iface := interfacestore.NewContainerInterface(interfaceName, interfaceName, podName, "ns1", nil, []net.IP{net.ParseIP("10.10.0.2")})
+	iface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: ofPort}
+	c.interfaceStore.AddInterface(iface)
+}
+
+func TestSetEgressPortsQoS(t *testing.T) {
+	c, closeFn := newQoSController(t)
+	defer closeFn()
+
+	queues := map[uint32]ovsconfig.QueueConfig{
+		0: {MaxRate: 10000000000},
+		1: {MinRate: 1000000000, MaxRate: 10000000000},
+		2: {MinRate: 100000000, MaxRate: 1000000000},
+	}
+	// The queues are only set to the uplink port.
+	c.ovsBridgeClient.EXPECT().SetPortQoS("eth0", uint64(10000000000), queues)
+	require.NoError(t, c.setEgressPortsQoS())
+	assert.Equal(t, []uint32{1, 2, 3}, c.egressOFPorts)
+	assert.Equal(t, []uint32{3}, c.queueOFPorts)
+
+	// Without uplink port, only the DSCP of the classes is set.
+	c.interfaceStore.DeleteInterface(c.interfaceStore.GetInterfacesByType(interfacestore.UplinkInterface)[0])
+	require.NoError(t, c.setEgressPortsQoS())
+	assert.Equal(t, []uint32{1, 2}, c.egressOFPorts)
+	assert.Empty(t, c.queueOFPorts)
+}
+
+func TestDeleteEgressPortsQoS(t *testing.T) {
+	c, closeFn := newQoSController(t)
+	defer closeFn()
+
+	c.ovsBridgeClient.EXPECT().DeletePortQoS("eth0")
+	require.NoError(t, DeleteEgressPortsQoS(c.ovsBridgeClient, c.interfaceStore))
+}
+
+func TestSyncPodQoS(t *testing.T) {
+	c, closeFn := newQoSController(t)
+	defer closeFn()
+	c.egressOFPorts = []uint32{1, 2, 3}
+	c.queueOFPorts = []uint32{3}
+
+	// The interface of the Pod is not created yet.
+	c.updatePod("podA", "", map[string]string{QoSClassAnnotation: "silver"})
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+
+	c.addInterface("podA", "podA-1", 1)
+	c.ofClient.EXPECT().InstallPodQoSFlows("podA-1", uint32(1), uint32(2), uint8(0), []uint32{1, 2, 3}, []uint32{3})
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Equal(t, map[string]string{"ns1/podA": "podA-1"}, c.podInterfaces)
+
+	// The class of the PriorityClass is used when the annotated class is unknown.
+	c.updatePod("podA", "high-priority", map[string]string{QoSClassAnnotation: "bronze"})
+	c.ofClient.EXPECT().InstallPodQoSFlows("podA-1", uint32(1), uint32(1), uint8(46), []uint32{1, 2, 3}, []uint32{3})
+	require.NoError(t, c.syncPod("ns1/podA"))
+
+	// The sandbox of the Pod is recreated.
+	c.interfaceStore.DeleteInterface(c.interfaceStore.GetContainerInterfacesByPod("podA", "ns1")[0])
+	c.addInterface("podA", "podA-2", 2)
+	c.ofClient.EXPECT().UninstallPodQoSFlows("podA-1")
+	c.ofClient.EXPECT().InstallPodQoSFlows("podA-2", uint32(2), uint32(1), uint8(46), []uint32{1, 2, 3}, []uint32{3})
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Equal(t, map[string]string{"ns1/podA": "podA-2"}, c.podInterfaces)
+
+	c.updatePod("podA", "", nil)
+	c.ofClient.EXPECT().UninstallPodQoSFlows("podA-2")
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+
+	c.updatePod("podA", "high-priority", nil)
+	c.ofClient.EXPECT().InstallPodQoSFlows("podA-2", uint32(2), uint32(1), uint8(46), []uint32{1, 2, 3}, []uint32{3})
+	require.NoError(t, c.syncPod("ns1/podA"))
+
+	c.podInformer.GetIndexer().Delete(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "podA"}})
+	c.ofClient.EXPECT().UninstallPodQoSFlows("podA-2")
+	require.NoError(t, c.syncPod("ns1/podA"))
+	assert.Empty(t, c.podInterfaces)
+}
@@ -0,0 +1,331 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	corev1alpha2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	quotainformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/core/v1alpha2"
+	quotalisters "github.com/vmware-tanzu/antrea/pkg/client/listers/core/v1alpha2"
+)
+
+const (
+	quotaControllerName = "AntreaAgentBandwidthQuotaController"
+	// quotaKey is the only key of the work queue: all BandwidthQuotas are processed at once as the BandwidthQuota of
+	// a Pod depends on all the BandwidthQuotas selecting its Namespace.
+	quotaKey = "quota"
+	// quotaStatsInterval is the interval at which the statistics of the quota Meters are collected.
+	quotaStatsInterval = 1 * time.Minute
+
+	// The reasons of the Events recorded for the BandwidthQuotas.
+	reasonInvalidBandwidthQuota  = "InvalidBandwidthQuota"
+	reasonBandwidthQuotaExceeded = "BandwidthQuotaExceeded"
+)
+
+// QuotaController is responsible for enforcing the BandwidthQuotas on the local Node: the traffic sent by the local
+// Pods of all the Namespaces selected by a BandwidthQuota is metered by a single OpenFlow meter, which drops the
+// traffic exceeding the rate of the BandwidthQuota. The rate is enforced independently on each Node, it is not
+// distributed across the Nodes running Pods of the tenant. It also exposes the statistics of the meters as metrics,
+// and records an Event when a BandwidthQuota starts being exceeded on the Node.
+type QuotaController struct {
+	ofClient              openflow.Client
+	interfaceStore        interfacestore.InterfaceStore
+	recorder              record.EventRecorder
+	nodeName              string
+	quotaLister           quotalisters.BandwidthQuotaLister
+	quotaListerSynced     cache.InformerSynced
+	podInformer           cache.SharedIndexInformer
+	podListerSynced       cache.InformerSynced
+	namespaceLister       corelisters.NamespaceLister
+	namespaceListerSynced cache.InformerSynced
+	queue                 workqueue.RateLimitingInterface
+	// The following fields are only accessed by the single worker of the controller.
+	// installedQuotas are the names of the BandwidthQuotas which are installed on the local Node.
+	installedQuotas sets.String
+	// invalidQuotas maps the names of the invalid BandwidthQuotas to the generation for which an Event was recorded.
+	invalidQuotas map[string]int64
+	// The following fields are only accessed by the goroutine collecting the statistics.
+	// droppedPackets maps the names of the BandwidthQuotas to the number of packets dropped by their Meter at the
+	// last collection, and exceededQuotas are the BandwidthQuotas which were exceeded during the last interval.
+	droppedPackets map[string]uint64
+	exceededQuotas sets.String
+}
+
+// NewQuotaController instantiates a new QuotaController object which will process BandwidthQuota, Pod and Namespace
+// events.
+func NewQuotaController(
+	kubeClient clientset.Interface,
+	informerFactory informers.SharedInformerFactory,
+	quotaInformer quotainformers.BandwidthQuotaInformer,
+	ofClient openflow.Client,
+	interfaceStore interfacestore.InterfaceStore,
+	recorder record.EventRecorder,
+	nodeName string) *QuotaController {
+	// Watch only the Pods which belong to the Node where the agent is running.
+	listOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	podInformer := coreinformers.NewFilteredPodInformer(
+		kubeClient,
+		metav1.NamespaceAll,
+		resyncPeriod,
+		cache.Indexers{},
+		listOptions,
+	)
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	c := &QuotaController{
+		ofClient:              ofClient,
+		interfaceStore:        interfaceStore,
+		recorder:              recorder,
+		nodeName:              nodeName,
+		quotaLister:           quotaInformer.Lister(),
+		quotaListerSynced:     quotaInformer.Informer().HasSynced,
+		podInformer:           podInformer,
+		podListerSynced:       podInformer.HasSynced,
+		namespaceLister:       namespaceInformer.Lister(),
+		namespaceListerSynced: namespaceInformer.Informer().HasSynced,
+		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "bandwidthQuota"),
+		installedQuotas:       sets.NewString(),
+		invalidQuotas:         make(map[string]int64),
+		droppedPackets:        make(map[string]uint64),
+		exceededQuotas:        sets.NewString(),
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, _ interface{}) { c.enqueue(nil) },
+		DeleteFunc: c.enqueue,
+	}
+	quotaInformer.Informer().AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	podInformer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	namespaceInformer.Informer().AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	return c
+}
+
+// enqueue adds the single key to the controller work queue, regardless of the object which was updated.
+func (c *QuotaController) enqueue(_ interface{}) {
+	c.queue.Add(quotaKey)
+}
+
+// Run will start the local Pod informer, a single worker which will process the events from the work queue, and the
+// periodic collection of the statistics of the quota Meters.
+func (c *QuotaController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", quotaControllerName)
+	defer klog.Infof("Shutting down %s", quotaControllerName)
+
+	go c.podInformer.Run(stopCh)
+
+	if !cache.WaitForNamedCacheSync(quotaControllerName, stopCh, c.quotaListerSynced, c.podListerSynced, c.namespaceListerSynced) {
+		return
+	}
+
+	go wait.Until(c.worker, time.Second, stopCh)
+	go wait.Until(c.collectStats, quotaStatsInterval, stopCh)
+	<-stopCh
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the work queue.
+func (c *QuotaController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *QuotaController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if err := c.syncQuotas(); err != nil {
+		klog.Errorf("Error syncing BandwidthQuotas, requeuing: %v", err)
+		c.queue.AddRateLimited(obj)
+		return true
+	}
+	c.queue.Forget(obj)
+	return true
+}
+
+// syncQuotas computes the BandwidthQuota of every local Pod, and reconciles the realized Meters and flows with them.
+func (c *QuotaController) syncQuotas() error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing BandwidthQuotas. (%v)", time.Since(startTime))
+	}()
+
+	quotas, err := c.quotaLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	// When several BandwidthQuotas select the same Namespace, the BandwidthQuota with the smallest name is applied.
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Name < quotas[j].Name
+	})
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	rates := make(map[string]uint64)
+	// namespaceQuotas maps the Namespaces to the name of the BandwidthQuota applied to them.
+	namespaceQuotas := make(map[string]string)
+	invalidQuotas := make(map[string]int64)
+	for _, quota := range quotas {
+		rate, selector, err := parseQuota(quota)
+		if err != nil {
+			invalidQuotas[quota.Name] = quota.Generation
+			// Retrying won't help until the BandwidthQuota is updated, so the Event is only recorded once per
+			// generation.
+			if generation, reported := c.invalidQuotas[quota.Name]; !reported || generation != quota.Generation {
+				klog.Errorf("Invalid BandwidthQuota %s: %v", quota.Name, err)
+				c.recorder.Eventf(quotaReference(quota), corev1.EventTypeWarning, reasonInvalidBandwidthQuota, "Invalid BandwidthQuota: %v", err)
+			}
+			continue
+		}
+		rates[quota.Name] = rate
+		for _, namespace := range namespaces {
+			if _, exists := namespaceQuotas[namespace.Name]; exists {
+				continue
+			}
+			if selector.Matches(labels.Set(namespace.Labels)) {
+				namespaceQuotas[namespace.Name] = quota.Name
+			}
+		}
+	}
+	c.invalidQuotas = invalidQuotas
+
+	// The local Pods are found from their interfaces, as the Pods which don't have an interface yet, or anymore,
+	// don't need to be metered. The Pod events still trigger a sync once the interface is created.
+	desiredQuotas := make(map[string]map[string]uint32)
+	for _, iface := range c.interfaceStore.GetInterfacesByType(interfacestore.ContainerInterface) {
+		quotaName, exists := namespaceQuotas[iface.PodNamespace]
+		if !exists || iface.OVSPortConfig == nil {
+			continue
+		}
+		if _, exists := desiredQuotas[quotaName]; !exists {
+			desiredQuotas[quotaName] = make(map[string]uint32)
+		}
+		desiredQuotas[quotaName][iface.InterfaceName] = uint32(iface.OFPort)
+	}
+
+	for _, quotaName := range c.installedQuotas.List() {
+		if _, exists := desiredQuotas[quotaName]; exists {
+			continue
+		}
+		if err := c.ofClient.UninstallBandwidthQuotaFlows(quotaName); err != nil {
+			return fmt.Errorf("error uninstalling flows for BandwidthQuota %s: %v", quotaName, err)
+		}
+		c.installedQuotas.Delete(quotaName)
+		metrics.BandwidthQuotaPodCount.DeleteLabelValues(quotaName)
+	}
+	for quotaName, podOFPorts := range desiredQuotas {
+		if err := c.ofClient.InstallBandwidthQuotaFlows(quotaName, rates[quotaName], podOFPorts); err != nil {
+			return fmt.Errorf("error installing flows for BandwidthQuota %s: %v", quotaName, err)
+		}
+		c.installedQuotas.Insert(quotaName)
+		metrics.BandwidthQuotaPodCount.WithLabelValues(quotaName).Set(float64(len(podOFPorts)))
+	}
+	return nil
+}
+
+// parseQuota returns the egress rate of the BandwidthQuota, in bits per second, and the selector of its Namespaces.
+func parseQuota(quota *corev1alpha2.BandwidthQuota) (uint64, labels.Selector, error) {
+	rate, err := ParseRate(quota.Spec.Egress)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid egress: %v", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&quota.Spec.NamespaceSelector)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid namespaceSelector: %v", err)
+	}
+	return rate, selector, nil
+}
+
+// collectStats updates the metrics of the installed BandwidthQuotas with the statistics of their Meters, and records
+// an Event for the BandwidthQuotas which start being exceeded on the local Node.
+func (c *QuotaController) collectStats() {
+	stats, err := c.ofClient.GetBandwidthQuotaStats()
+	if err != nil {
+		klog.Errorf("Failed to get the statistics of the BandwidthQuotas: %v", err)
+		return
+	}
+	for quotaName := range c.droppedPackets {
+		if _, exists := stats[quotaName]; !exists {
+			delete(c.droppedPackets, quotaName)
+			c.exceededQuotas.Delete(quotaName)
+			metrics.BandwidthQuotaEgressBytes.DeleteLabelValues(quotaName)
+			metrics.BandwidthQuotaDroppedBytes.DeleteLabelValues(quotaName)
+		}
+	}
+	for quotaName, s := range stats {
+		metrics.BandwidthQuotaEgressBytes.WithLabelValues(quotaName).Set(float64(s.Bytes))
+		metrics.BandwidthQuotaDroppedBytes.WithLabelValues(quotaName).Set(float64(s.DroppedBytes))
+
+		// The counters are reset when the Meter is re-installed, in which case all the dropped packets are new.
+		lastDropped := c.droppedPackets[quotaName]
+		if s.DroppedPackets < lastDropped {
+			lastDropped = 0
+		}
+		c.droppedPackets[quotaName] = s.DroppedPackets
+		if s.DroppedPackets == lastDropped {
+			c.exceededQuotas.Delete(quotaName)
+			continue
+		}
+		if c.exceededQuotas.Has(quotaName) {
+			continue
+		}
+		c.exceededQuotas.Insert(quotaName)
+		quota, err := c.quotaLister.Get(quotaName)
+		if err != nil {
+			// The BandwidthQuota has been deleted and will be uninstalled soon.
+			continue
+		}
+		klog.Infof("BandwidthQuota %s is exceeded by the local Pods, %d packets were dropped", quotaName, s.DroppedPackets-lastDropped)
+		c.recorder.Eventf(quotaReference(quota), corev1.EventTypeWarning, reasonBandwidthQuotaExceeded,
+			"Egress bandwidth %s exceeded by the Pods on Node %s, %d packets were dropped", quota.Spec.Egress, c.nodeName, s.DroppedPackets-lastDropped)
+	}
+}
+
+func quotaReference(quota *corev1alpha2.BandwidthQuota) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: corev1alpha2.SchemeGroupVersion.String(),
+		Kind:       "BandwidthQuota",
+		Name:       quota.Name,
+		UID:        quota.UID,
+	}
+}
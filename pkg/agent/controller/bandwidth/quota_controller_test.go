@@ -0,0 +1,192 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bandwidth
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	corev1alpha2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
+)
+
+type fakeQuotaController struct {
+	*QuotaController
+	informerFactory    informers.SharedInformerFactory
+	crdInformerFactory crdinformers.SharedInformerFactory
+	ofClient           *oftest.MockClient
+	interfaceStore     interfacestore.InterfaceStore
+	recorder           *record.FakeRecorder
+}
+
+func newQuotaController(t *testing.T) (*fakeQuotaController, func()) {
+	clientset := fake.NewSimpleClientset()
+	crdClient := fakeversioned.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(clientset, 12*time.Hour)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 12*time.Hour)
+	ctrl := gomock.NewController(t)
+	ofClient := oftest.NewMockClient(ctrl)
+	interfaceStore := interfacestore.NewInterfaceStore()
+	recorder := record.NewFakeRecorder(10)
+	c := NewQuotaController(clientset, informerFactory, crdInformerFactory.Core().V1alpha2().BandwidthQuotas(), ofClient, interfaceStore, recorder, "node1")
+	return &fakeQuotaController{
+		QuotaController:    c,
+		informerFactory:    informerFactory,
+		crdInformerFactory: crdInformerFactory,
+		ofClient:           ofClient,
+		interfaceStore:     interfaceStore,
+		recorder:           recorder,
+	}, ctrl.Finish
+}
+
+func (c *fakeQuotaController) addNamespace(name string, nsLabels map[string]string) {
+	c.informerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nsLabels}})
+}
+
+func (c *fakeQuotaController) addQuota(name, egress string, generation int64, nsLabels map[string]string) {
+	quota := &corev1alpha2.BandwidthQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: generation},
+		Spec: corev1alpha2.BandwidthQuotaSpec{
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: nsLabels},
+			Egress:            egress,
+		},
+	}
+	c.crdInformerFactory.Core().V1alpha2().BandwidthQuotas().Informer().GetIndexer().Update(quota)
+}
+
+func (c *fakeQuotaController) deleteQuota(name string) {
+	c.crdInformerFactory.Core().V1alpha2().BandwidthQuotas().Informer().GetIndexer().Delete(&corev1alpha2.BandwidthQuota{ObjectMeta: metav1.ObjectMeta{Name: name}})
+}
+
+func (c *fakeQuotaController) addPodInterface(namespace, name string, ofPort int32) {
+	iface := interfacestore.NewContainerInterface(name, name, name, namespace, nil, []net.IP{net.ParseIP("10.10.0.2")})
+	iface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: ofPort}
+	c.interfaceStore.AddInterface(iface)
+}
+
+func (c *fakeQuotaController) expectEvents(t *testing.T, reasons ...string) {
+	for _, reason := range reasons {
+		select {
+		case event := <-c.recorder.Events:
+			assert.Contains(t, event, reason)
+		default:
+			t.Errorf("Expected Event with reason %s", reason)
+		}
+	}
+	select {
+	case event := <-c.recorder.Events:
+		t.Errorf("Unexpected Event %s", event)
+	default:
+	}
+}
+
+func TestSyncQuotas(t *testing.T) {
+	c, closeFn := newQuotaController(t)
+	defer closeFn()
+
+	c.addNamespace("ns1", map[string]string{"tenant": "foo"})
+	c.addNamespace("ns2", map[string]string{"tenant": "foo"})
+	c.addNamespace("ns3", map[string]string{"tenant": "bar"})
+	c.addPodInterface("ns1", "podA", 1)
+	c.addPodInterface("ns2", "podB", 2)
+	c.addPodInterface("ns3", "podC", 3)
+	c.addQuota("quotaA", "100M", 1, map[string]string{"tenant": "foo"})
+	// quotaB selects no local Pod, so it should not be installed.
+	c.addQuota("quotaB", "1G", 1, map[string]string{"tenant": "baz"})
+
+	c.ofClient.EXPECT().InstallBandwidthQuotaFlows("quotaA", uint64(100000000), map[string]uint32{"podA": 1, "podB": 2})
+	require.NoError(t, c.syncQuotas())
+	assert.Equal(t, []string{"quotaA"}, c.installedQuotas.List())
+
+	// ns2 is selected by both quotaA and quotaC, quotaA should be applied.
+	c.addNamespace("ns2", map[string]string{"tenant": "foo", "team": "qux"})
+	c.addQuota("quotaC", "10M", 1, map[string]string{"team": "qux"})
+	c.ofClient.EXPECT().InstallBandwidthQuotaFlows("quotaA", uint64(100000000), map[string]uint32{"podA": 1, "podB": 2})
+	require.NoError(t, c.syncQuotas())
+
+	// Deleting quotaA should make podB use quotaC.
+	c.deleteQuota("quotaA")
+	c.ofClient.EXPECT().UninstallBandwidthQuotaFlows("quotaA")
+	c.ofClient.EXPECT().InstallBandwidthQuotaFlows("quotaC", uint64(10000000), map[string]uint32{"podB": 2})
+	require.NoError(t, c.syncQuotas())
+	assert.Equal(t, []string{"quotaC"}, c.installedQuotas.List())
+
+	// An invalid quota is not applied, and reported once per generation.
+	c.addQuota("quotaC", "10P", 2, map[string]string{"team": "qux"})
+	c.ofClient.EXPECT().UninstallBandwidthQuotaFlows("quotaC")
+	require.NoError(t, c.syncQuotas())
+	require.NoError(t, c.syncQuotas())
+	c.expectEvents(t, reasonInvalidBandwidthQuota)
+	assert.Empty(t, c.installedQuotas)
+
+	c.addQuota("quotaC", "20M", 3, map[string]string{"team": "qux"})
+	c.ofClient.EXPECT().InstallBandwidthQuotaFlows("quotaC", uint64(20000000), map[string]uint32{"podB": 2})
+	require.NoError(t, c.syncQuotas())
+	assert.Empty(t, c.invalidQuotas)
+}
+
+func TestCollectStats(t *testing.T) {
+	c, closeFn := newQuotaController(t)
+	defer closeFn()
+
+	c.addQuota("quotaA", "100M", 1, map[string]string{"tenant": "foo"})
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{
+		"quotaA": {Packets: 10, Bytes: 1000},
+	}, nil)
+	c.collectStats()
+	c.expectEvents(t)
+
+	// An Event is recorded when the quota starts dropping packets, not while it keeps dropping them.
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{
+		"quotaA": {Packets: 100, Bytes: 10000, DroppedPackets: 5, DroppedBytes: 500},
+	}, nil)
+	c.collectStats()
+	c.expectEvents(t, reasonBandwidthQuotaExceeded)
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{
+		"quotaA": {Packets: 200, Bytes: 20000, DroppedPackets: 15, DroppedBytes: 1500},
+	}, nil)
+	c.collectStats()
+	c.expectEvents(t)
+
+	// The quota is exceeded again after an interval without drops.
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{
+		"quotaA": {Packets: 300, Bytes: 30000, DroppedPackets: 15, DroppedBytes: 1500},
+	}, nil)
+	c.collectStats()
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{
+		"quotaA": {Packets: 400, Bytes: 40000, DroppedPackets: 20, DroppedBytes: 2000},
+	}, nil)
+	c.collectStats()
+	c.expectEvents(t, reasonBandwidthQuotaExceeded)
+
+	c.ofClient.EXPECT().GetBandwidthQuotaStats().Return(map[string]*ovsctl.MeterStats{}, nil)
+	c.collectStats()
+	assert.Empty(t, c.droppedPackets)
+	assert.Empty(t, c.exceededQuotas)
+}
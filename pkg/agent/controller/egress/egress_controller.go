@@ -0,0 +1,366 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	corev1alpha2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	egressinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/core/v1alpha2"
+	egresslisters "github.com/vmware-tanzu/antrea/pkg/client/listers/core/v1alpha2"
+	"github.com/vmware-tanzu/antrea/pkg/k8s"
+)
+
+const (
+	controllerName = "AntreaAgentEgressController"
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of the Egresses.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// The Egress IPs can be assigned to or removed from the local Node at any time without any event being
+	// generated, so the Egresses are re-synced periodically.
+	egressResyncPeriod = 1 * time.Minute
+	// egressKey is the only key of the work queue: all Egresses are processed at once as the Egress of a Pod
+	// depends on all the Egresses selecting it.
+	egressKey = "egress"
+	// maxSNATMark is the largest packet mark that can be allocated to a local Egress IP.
+	maxSNATMark = types.SNATIPMarkMask
+)
+
+// podSNAT is the Egress IP realized for a local Pod.
+type podSNAT struct {
+	ofPort   uint32
+	egressIP string
+	// isLocal is true if the Egress IP is assigned to the local Node.
+	isLocal bool
+}
+
+// Controller is responsible for realizing the Egresses on the local Node: the traffic from the local Pods selected
+// by an Egress to the external network is either SNAT'd locally, if the Egress IP is assigned to the local Node, or
+// tunnelled to the Node owning the Egress IP.
+type Controller struct {
+	ofClient              openflow.Client
+	routeClient           route.Interface
+	interfaceStore        interfacestore.InterfaceStore
+	egressLister          egresslisters.EgressLister
+	egressListerSynced    cache.InformerSynced
+	podInformer           cache.SharedIndexInformer
+	podLister             corelisters.PodLister
+	podListerSynced       cache.InformerSynced
+	namespaceLister       corelisters.NamespaceLister
+	namespaceListerSynced cache.InformerSynced
+	queue                 workqueue.RateLimitingInterface
+	// isLocalIP returns whether the provided IP is assigned to the local Node. It can be overridden in tests.
+	isLocalIP func(ip net.IP) bool
+	// The following fields are only accessed by the single worker of the controller.
+	// localIPMarks maps the local Egress IPs to the packet marks allocated for them.
+	localIPMarks map[string]uint32
+	// podSNATs maps the local Pods (namespace/name) to their realized Egress IP.
+	podSNATs map[string]*podSNAT
+}
+
+// NewEgressController instantiates a new Controller object which will process Egress, Pod and Namespace events.
+func NewEgressController(
+	kubeClient clientset.Interface,
+	informerFactory informers.SharedInformerFactory,
+	egressInformer egressinformers.EgressInformer,
+	ofClient openflow.Client,
+	routeClient route.Interface,
+	interfaceStore interfacestore.InterfaceStore,
+	nodeName string) *Controller {
+	// Watch only the Pods which belong to the Node where the agent is running.
+	listOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	podInformer := coreinformers.NewFilteredPodInformer(
+		kubeClient,
+		metav1.NamespaceAll,
+		resyncPeriod,
+		cache.Indexers{},
+		listOptions,
+	)
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	c := &Controller{
+		ofClient:              ofClient,
+		routeClient:           routeClient,
+		interfaceStore:        interfaceStore,
+		egressLister:          egressInformer.Lister(),
+		egressListerSynced:    egressInformer.Informer().HasSynced,
+		podInformer:           podInformer,
+		podLister:             corelisters.NewPodLister(podInformer.GetIndexer()),
+		podListerSynced:       podInformer.HasSynced,
+		namespaceLister:       namespaceInformer.Lister(),
+		namespaceListerSynced: namespaceInformer.Informer().HasSynced,
+		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "egress"),
+		isLocalIP:             isLocalIP,
+		localIPMarks:          make(map[string]uint32),
+		podSNATs:              make(map[string]*podSNAT),
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, _ interface{}) { c.enqueue(nil) },
+		DeleteFunc: c.enqueue,
+	}
+	egressInformer.Informer().AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	podInformer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	namespaceInformer.Informer().AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	return c
+}
+
+func isLocalIP(ip net.IP) bool {
+	_, _, err := util.GetIPNetDeviceFromIP(ip)
+	return err == nil
+}
+
+// enqueue adds the single key to the controller work queue, regardless of the object which was updated.
+func (c *Controller) enqueue(_ interface{}) {
+	c.queue.Add(egressKey)
+}
+
+// Run will start the local Pod informer and a single worker which will process the Egress events from the work
+// queue.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	go c.podInformer.Run(stopCh)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.egressListerSynced, c.podListerSynced, c.namespaceListerSynced) {
+		return
+	}
+
+	go wait.Until(func() { c.enqueue(nil) }, egressResyncPeriod, stopCh)
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the work queue.
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if err := c.syncEgresses(); err != nil {
+		klog.Errorf("Error syncing Egresses, requeuing: %v", err)
+		c.queue.AddRateLimited(obj)
+		return true
+	}
+	c.queue.Forget(obj)
+	return true
+}
+
+// syncEgresses computes the Egress IP of every local Pod and of every local Egress IP, and reconciles the realized
+// SNAT flows and rules with them.
+func (c *Controller) syncEgresses() error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing Egresses. (%v)", time.Since(startTime))
+	}()
+
+	egresses, err := c.egressLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	// When several Egresses select the same Pod, the Egress with the smallest name is applied.
+	sort.Slice(egresses, func(i, j int) bool {
+		return egresses[i].Name < egresses[j].Name
+	})
+
+	desiredLocalIPs := make(map[string]net.IP)
+	var validEgresses []*corev1alpha2.Egress
+	for _, egress := range egresses {
+		egressIP := net.ParseIP(egress.Spec.EgressIP)
+		if egressIP == nil {
+			klog.Errorf("Invalid IP %s in Egress %s", egress.Spec.EgressIP, egress.Name)
+			continue
+		}
+		validEgresses = append(validEgresses, egress)
+		if c.isLocalIP(egressIP) {
+			desiredLocalIPs[egressIP.String()] = egressIP
+		}
+	}
+
+	desiredPodSNATs, err := c.computePodSNATs(validEgresses)
+	if err != nil {
+		return err
+	}
+	for _, desired := range desiredPodSNATs {
+		_, desired.isLocal = desiredLocalIPs[desired.egressIP]
+	}
+
+	// Remove the stale Pod flows first, as they may refer to the marks of stale local IPs.
+	for podKey, actual := range c.podSNATs {
+		if desired, exists := desiredPodSNATs[podKey]; exists && *desired == *actual {
+			continue
+		}
+		if err := c.ofClient.UninstallPodSNATFlows(actual.ofPort); err != nil {
+			return fmt.Errorf("error uninstalling SNAT flows for Pod %s: %v", podKey, err)
+		}
+		delete(c.podSNATs, podKey)
+	}
+
+	for ip, mark := range c.localIPMarks {
+		if _, exists := desiredLocalIPs[ip]; exists {
+			continue
+		}
+		if err := c.ofClient.UninstallSNATMarkFlows(mark); err != nil {
+			return fmt.Errorf("error uninstalling SNAT mark flows for IP %s: %v", ip, err)
+		}
+		if err := c.routeClient.DeleteSNATRule(mark); err != nil {
+			return fmt.Errorf("error deleting SNAT rule for IP %s: %v", ip, err)
+		}
+		delete(c.localIPMarks, ip)
+	}
+
+	for ip, egressIP := range desiredLocalIPs {
+		if _, exists := c.localIPMarks[ip]; exists {
+			continue
+		}
+		mark, err := c.allocateMark()
+		if err != nil {
+			return err
+		}
+		if err := c.routeClient.AddSNATRule(egressIP, mark); err != nil {
+			return fmt.Errorf("error adding SNAT rule for IP %s: %v", ip, err)
+		}
+		if err := c.ofClient.InstallSNATMarkFlows(egressIP, mark); err != nil {
+			return fmt.Errorf("error installing SNAT mark flows for IP %s: %v", ip, err)
+		}
+		c.localIPMarks[ip] = mark
+	}
+
+	for podKey, desired := range desiredPodSNATs {
+		if _, exists := c.podSNATs[podKey]; exists {
+			continue
+		}
+		egressIP := net.ParseIP(desired.egressIP)
+		// The mark is 0 if the Egress IP is not local, in which case the traffic is tunnelled to the Egress IP.
+		mark := c.localIPMarks[desired.egressIP]
+		if err := c.ofClient.InstallPodSNATFlows(desired.ofPort, egressIP, mark); err != nil {
+			return fmt.Errorf("error installing SNAT flows for Pod %s: %v", podKey, err)
+		}
+		c.podSNATs[podKey] = desired
+	}
+	return nil
+}
+
+// computePodSNATs returns the Egress IP which should be used by each local Pod selected by an Egress.
+func (c *Controller) computePodSNATs(egresses []*corev1alpha2.Egress) (map[string]*podSNAT, error) {
+	podSNATs := make(map[string]*podSNAT)
+	if len(egresses) == 0 {
+		return podSNATs, nil
+	}
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || pod.Status.PodIP == "" {
+			continue
+		}
+		ifaces := c.interfaceStore.GetContainerInterfacesByPod(pod.Name, pod.Namespace)
+		if len(ifaces) == 0 {
+			// The Pod will be processed again when its IP is reported, after the CNI ADD request is completed.
+			klog.V(2).Infof("Interface of Pod %s/%s not found", pod.Namespace, pod.Name)
+			continue
+		}
+		namespace, err := c.namespaceLister.Get(pod.Namespace)
+		if err != nil {
+			klog.V(2).Infof("Namespace %s of Pod %s not found", pod.Namespace, pod.Name)
+			continue
+		}
+		for _, egress := range egresses {
+			if !appliedToMatches(&egress.Spec.AppliedTo, pod, namespace) {
+				continue
+			}
+			podSNATs[k8s.NamespacedName(pod.Namespace, pod.Name)] = &podSNAT{
+				ofPort:   uint32(ifaces[0].OFPort),
+				egressIP: net.ParseIP(egress.Spec.EgressIP).String(),
+			}
+			break
+		}
+	}
+	return podSNATs, nil
+}
+
+// appliedToMatches returns whether the provided Pod is selected by the AppliedTo. An AppliedTo without any selector
+// doesn't select any Pod.
+func appliedToMatches(appliedTo *corev1alpha2.AppliedTo, pod *corev1.Pod, namespace *corev1.Namespace) bool {
+	if appliedTo.PodSelector == nil && appliedTo.NamespaceSelector == nil {
+		return false
+	}
+	if appliedTo.NamespaceSelector != nil && !selectorMatches(appliedTo.NamespaceSelector, namespace.Labels) {
+		return false
+	}
+	if appliedTo.PodSelector != nil && !selectorMatches(appliedTo.PodSelector, pod.Labels) {
+		return false
+	}
+	return true
+}
+
+func selectorMatches(labelSelector *metav1.LabelSelector, objLabels map[string]string) bool {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		klog.Errorf("Invalid label selector %v: %v", labelSelector, err)
+		return false
+	}
+	return selector.Matches(labels.Set(objLabels))
+}
+
+// allocateMark returns the smallest packet mark which is not allocated to a local Egress IP.
+func (c *Controller) allocateMark() (uint32, error) {
+	allocated := make(map[uint32]bool, len(c.localIPMarks))
+	for _, mark := range c.localIPMarks {
+		allocated[mark] = true
+	}
+	for mark := uint32(1); mark <= maxSNATMark; mark++ {
+		if !allocated[mark] {
+			return mark, nil
+		}
+	}
+	return 0, fmt.Errorf("no SNAT mark available, the number of local Egress IPs cannot exceed %d", maxSNATMark)
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
+	corev1alpha2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+const (
+	localIP  = "1.1.1.1"
+	remoteIP = "1.1.1.2"
+)
+
+type fakeController struct {
+	*Controller
+	informerFactory    informers.SharedInformerFactory
+	crdInformerFactory crdinformers.SharedInformerFactory
+	ofClient           *oftest.MockClient
+	routeClient        *routetest.MockInterface
+	interfaceStore     interfacestore.InterfaceStore
+}
+
+func newController(t *testing.T) (*fakeController, func()) {
+	clientset := fake.NewSimpleClientset()
+	crdClient := fakeversioned.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(clientset, 12*time.Hour)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 12*time.Hour)
+	ctrl := gomock.NewController(t)
+	ofClient := oftest.NewMockClient(ctrl)
+	routeClient := routetest.NewMockInterface(ctrl)
+	interfaceStore := interfacestore.NewInterfaceStore()
+	c := NewEgressController(clientset, informerFactory, crdInformerFactory.Core().V1alpha2().Egresses(), ofClient, routeClient, interfaceStore, "node1")
+	c.isLocalIP = func(ip net.IP) bool {
+		return ip.String() == localIP
+	}
+	return &fakeController{
+		Controller:         c,
+		informerFactory:    informerFactory,
+		crdInformerFactory: crdInformerFactory,
+		ofClient:           ofClient,
+		routeClient:        routeClient,
+		interfaceStore:     interfaceStore,
+	}, ctrl.Finish
+}
+
+func (c *fakeController) addEgress(name, egressIP string, podSelector *metav1.LabelSelector) {
+	egress := &corev1alpha2.Egress{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1alpha2.EgressSpec{
+			AppliedTo: corev1alpha2.AppliedTo{PodSelector: podSelector},
+			EgressIP:  egressIP,
+		},
+	}
+	c.crdInformerFactory.Core().V1alpha2().Egresses().Informer().GetIndexer().Add(egress)
+}
+
+func (c *fakeController) addPod(name string, ofPort int32, podLabels map[string]string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name, Labels: podLabels},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+		Status:     corev1.PodStatus{PodIP: "10.10.0.2"},
+	}
+	c.podInformer.GetIndexer().Add(pod)
+	iface := interfacestore.NewContainerInterface(name, name, name, "ns1", nil, []net.IP{net.ParseIP(pod.Status.PodIP)})
+	iface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: ofPort}
+	c.interfaceStore.AddInterface(iface)
+}
+
+func TestSyncEgresses(t *testing.T) {
+	c, closeFn := newController(t)
+	defer closeFn()
+
+	c.informerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	c.addPod("podA", 1, map[string]string{"app": "foo"})
+	c.addPod("podB", 2, map[string]string{"app": "bar"})
+	c.addPod("podC", 3, map[string]string{"app": "baz"})
+	c.addEgress("egressA", localIP, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}})
+	c.addEgress("egressB", remoteIP, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "bar"}})
+	// podB is selected by both egressB and egressC, egressB should be applied.
+	c.addEgress("egressC", localIP, &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"bar", "qux"}},
+	}})
+
+	c.routeClient.EXPECT().AddSNATRule(net.ParseIP(localIP), uint32(1))
+	c.ofClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(localIP), uint32(1))
+	c.ofClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(localIP), uint32(1))
+	c.ofClient.EXPECT().InstallPodSNATFlows(uint32(2), net.ParseIP(remoteIP), uint32(0))
+	require.NoError(t, c.syncEgresses())
+	assert.Equal(t, map[string]uint32{localIP: 1}, c.localIPMarks)
+	assert.Len(t, c.podSNATs, 2)
+
+	// Syncing again without any change should be a no-op.
+	require.NoError(t, c.syncEgresses())
+
+	// Deleting egressA should only remove podA's flows, as localIP is still used by egressC.
+	c.crdInformerFactory.Core().V1alpha2().Egresses().Informer().GetIndexer().Delete(&corev1alpha2.Egress{ObjectMeta: metav1.ObjectMeta{Name: "egressA"}})
+	c.ofClient.EXPECT().UninstallPodSNATFlows(uint32(1))
+	require.NoError(t, c.syncEgresses())
+	assert.Equal(t, map[string]uint32{localIP: 1}, c.localIPMarks)
+
+	// Deleting egressB should make podB use egressC.
+	c.crdInformerFactory.Core().V1alpha2().Egresses().Informer().GetIndexer().Delete(&corev1alpha2.Egress{ObjectMeta: metav1.ObjectMeta{Name: "egressB"}})
+	c.ofClient.EXPECT().UninstallPodSNATFlows(uint32(2))
+	c.ofClient.EXPECT().InstallPodSNATFlows(uint32(2), net.ParseIP(localIP), uint32(1))
+	require.NoError(t, c.syncEgresses())
+
+	// Deleting egressC should remove all the state.
+	c.crdInformerFactory.Core().V1alpha2().Egresses().Informer().GetIndexer().Delete(&corev1alpha2.Egress{ObjectMeta: metav1.ObjectMeta{Name: "egressC"}})
+	c.ofClient.EXPECT().UninstallPodSNATFlows(uint32(2))
+	c.ofClient.EXPECT().UninstallSNATMarkFlows(uint32(1))
+	c.routeClient.EXPECT().DeleteSNATRule(uint32(1))
+	require.NoError(t, c.syncEgresses())
+	assert.Empty(t, c.localIPMarks)
+	assert.Empty(t, c.podSNATs)
+}
+
+func TestAllocateMark(t *testing.T) {
+	c := &Controller{localIPMarks: map[string]uint32{"1.1.1.1": 1, "1.1.1.3": 3}}
+	mark, err := c.allocateMark()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), mark)
+
+	c.localIPMarks = make(map[string]uint32)
+	for i := uint32(1); i <= maxSNATMark; i++ {
+		c.localIPMarks[net.IPv4(10, 0, byte(i>>8), byte(i)).String()] = i
+	}
+	_, err = c.allocateMark()
+	assert.Error(t, err)
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multicast forwards the IPv4 multicast traffic to the local Pods
+// which joined the multicast groups, learning their memberships by snooping
+// the IGMP messages they send.
+package multicast
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+)
+
+const (
+	controllerName = "AntreaAgentMulticastController"
+	minRetryDelay  = 5 * time.Second
+	maxRetryDelay  = 300 * time.Second
+	// staleMemberCheckInterval is the interval at which the memberships of the Pods whose interface has been removed,
+	// or which have not been reported for membershipTimeout, are deleted.
+	staleMemberCheckInterval = time.Minute
+	// queryInterval is the interval at which the agent sends IGMP general queries to the local Pods, so that they
+	// report the groups they have joined. It is the default query interval of RFC 2236.
+	queryInterval = 125 * time.Second
+	// membershipTimeout is the time after which a membership which has not been reported again is deleted, e.g.
+	// when a Pod left a group without sending a leave message. It is the group membership interval of RFC 2236,
+	// computed with the default robustness variable and query response interval.
+	membershipTimeout = 2*queryInterval + 10*time.Second
+
+	// igmpProtocol is the IP protocol number of IGMP.
+	igmpProtocol = 2
+)
+
+// IGMP message types, as defined in RFC 2236 and RFC 3376. The membership queries are ignored, as the agent is the
+// querier of the local Pods.
+const (
+	igmpV1MembershipReport = 0x12
+	igmpV2MembershipReport = 0x16
+	igmpV2LeaveGroup       = 0x17
+	igmpV3MembershipReport = 0x22
+)
+
+// IGMPv3 group record types, as defined in RFC 3376.
+const (
+	igmpModeIsInclude       = 1
+	igmpModeIsExclude       = 2
+	igmpChangeToIncludeMode = 3
+	igmpChangeToExcludeMode = 4
+	igmpAllowNewSources     = 5
+	igmpBlockOldSources     = 6
+)
+
+// membershipEvent is a change of the membership of a Pod in a multicast group, reported by an IGMP message.
+type membershipEvent struct {
+	group net.IP
+	join  bool
+}
+
+// Controller is responsible for forwarding the IPv4 multicast traffic to the local Pods which joined the multicast
+// groups. It is the PacketIn handler for the PacketInReasonMC reason: the memberships of the local Pods are learnt by
+// snooping their IGMP messages, and an OVS Group replicating the traffic to the members is installed for each group.
+// Source filtering is not supported: a Pod joining a group with a list of sources receives the traffic of all the
+// sources.
+type Controller struct {
+	ofClient       openflow.Client
+	interfaceStore interfacestore.InterfaceStore
+	// queue contains the multicast group addresses whose members changed.
+	queue workqueue.RateLimitingInterface
+	// groupMembers maps the multicast group addresses to the interface names of the local Pods which joined them,
+	// and to the time their membership was last reported.
+	groupMembers map[string]map[string]time.Time
+	mutex        sync.Mutex
+}
+
+// NewMulticastController instantiates a new Controller and registers it as the PacketIn handler for the IGMP
+// messages.
+func NewMulticastController(ofClient openflow.Client, interfaceStore interfacestore.InterfaceStore) *Controller {
+	c := &Controller{
+		ofClient:       ofClient,
+		interfaceStore: interfaceStore,
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "multicast"),
+		groupMembers:   map[string]map[string]time.Time{},
+	}
+	ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonMC), "multicast", c)
+	return c
+}
+
+// Run will start a single worker which will install the OVS Groups of the multicast groups whose members changed,
+// and the querier which periodically queries the memberships of the local Pods.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	go wait.Until(c.removeStaleMembers, staleMemberCheckInterval, stopCh)
+	go wait.Until(c.sendQueries, queryInterval, stopCh)
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the work queue.
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncGroup(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing multicast group %s, requeuing. Error: %v", key, err)
+	}
+	return true
+}
+
+// syncGroup installs the OVS Group replicating the traffic sent to the multicast group to its current members, or
+// uninstalls it if the group has no member left.
+func (c *Controller) syncGroup(key string) error {
+	c.mutex.Lock()
+	names := make([]string, 0, len(c.groupMembers[key]))
+	for name := range c.groupMembers[key] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var ofPorts []uint32
+	for _, name := range names {
+		intf, ok := c.interfaceStore.GetInterfaceByName(name)
+		if !ok {
+			// The membership is deleted by removeStaleMembers.
+			continue
+		}
+		ofPorts = append(ofPorts, uint32(intf.OFPort))
+	}
+	c.mutex.Unlock()
+
+	groupIP := net.ParseIP(key)
+	if len(ofPorts) == 0 {
+		klog.V(2).Infof("Uninstalling multicast group %s", key)
+		return c.ofClient.UninstallMulticastGroup(groupIP)
+	}
+	klog.V(2).Infof("Installing multicast group %s with receiver ports %v", key, ofPorts)
+	return c.ofClient.InstallMulticastGroup(groupIP, ofPorts)
+}
+
+// removeStaleMembers deletes the memberships of the Pods whose interface has been removed, as the Pods may not leave
+// the groups before being deleted, and the memberships which have not been reported for membershipTimeout.
+func (c *Controller) removeStaleMembers() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	for key, members := range c.groupMembers {
+		for name, lastReportTime := range members {
+			_, ok := c.interfaceStore.GetInterfaceByName(name)
+			if !ok || now.Sub(lastReportTime) > membershipTimeout {
+				delete(members, name)
+				c.queue.Add(key)
+			}
+		}
+		if len(members) == 0 {
+			delete(c.groupMembers, key)
+		}
+	}
+}
+
+// sendQueries sends an IGMP general query to each local Pod. The Pods report the groups they have joined in response,
+// which refreshes their memberships, and lets the agent learn the memberships of the Pods again after restarting.
+func (c *Controller) sendQueries() {
+	for _, intf := range c.interfaceStore.GetInterfacesByType(interfacestore.ContainerInterface) {
+		if intf.OVSPortConfig == nil || intf.OFPort < 0 {
+			continue
+		}
+		if err := c.ofClient.SendIGMPQueryPacketOut(uint32(intf.OFPort)); err != nil {
+			klog.Errorf("Failed to send IGMP query to Pod %s/%s: %v", intf.PodNamespace, intf.PodName, err)
+		}
+	}
+}
+
+// HandlePacketIn implements openflow.PacketInHandler. It updates the memberships of the local Pod which sent the IGMP
+// message.
+func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	if pktIn == nil {
+		return errors.New("empty packetin for multicast")
+	}
+	inPort, err := getInPort(pktIn)
+	if err != nil {
+		return err
+	}
+	intf, ok := c.interfaceStore.GetInterfaceByOFPort(inPort)
+	if !ok || intf.Type != interfacestore.ContainerInterface {
+		// Only the memberships of the local Pods are snooped, e.g. the IGMP messages of the host are ignored.
+		klog.V(4).Infof("Ignoring IGMP message received from OVS port %d which is not a Pod port", inPort)
+		return nil
+	}
+	events, err := parseIGMPPacket(pktIn)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	for _, event := range events {
+		if !event.group.IsMulticast() || event.group.Equal(net.IPv4allsys) {
+			continue
+		}
+		key := event.group.String()
+		members := c.groupMembers[key]
+		_, isMember := members[intf.InterfaceName]
+		if event.join {
+			if members == nil {
+				members = map[string]time.Time{}
+				c.groupMembers[key] = members
+			}
+			// The report refreshes the membership, the group only changes when the Pod joins it.
+			members[intf.InterfaceName] = now
+			if isMember {
+				continue
+			}
+			klog.V(2).Infof("Pod %s/%s joined multicast group %s", intf.PodNamespace, intf.PodName, key)
+		} else {
+			if !isMember {
+				continue
+			}
+			delete(members, intf.InterfaceName)
+			if len(members) == 0 {
+				delete(c.groupMembers, key)
+			}
+			klog.V(2).Infof("Pod %s/%s left multicast group %s", intf.PodNamespace, intf.PodName, key)
+		}
+		c.queue.Add(key)
+	}
+	return nil
+}
+
+// getInPort returns the OVS port on which the packet was received.
+func getInPort(pktIn *ofctrl.PacketIn) (uint32, error) {
+	for _, field := range pktIn.Match.Fields {
+		if field.Class != openflow13.OXM_CLASS_OPENFLOW_BASIC || field.Field != openflow13.OXM_FIELD_IN_PORT {
+			continue
+		}
+		inPortField, ok := field.Value.(*openflow13.InPortField)
+		if !ok {
+			return 0, errors.New("invalid in_port in packetin")
+		}
+		return inPortField.InPort, nil
+	}
+	return 0, errors.New("in_port not found in packetin")
+}
+
+// parseIGMPPacket returns the membership events reported by the IGMP message of the packet.
+func parseIGMPPacket(pktIn *ofctrl.PacketIn) ([]membershipEvent, error) {
+	if pktIn.Data.Ethertype != protocol.IPv4_MSG {
+		return nil, fmt.Errorf("unsupported packet Ethertype for IGMP: %d", pktIn.Data.Ethertype)
+	}
+	ipPacket, ok := pktIn.Data.Data.(*protocol.IPv4)
+	if !ok || ipPacket.Protocol != igmpProtocol || ipPacket.Data == nil {
+		return nil, errors.New("invalid IGMP packet")
+	}
+	data, err := ipPacket.Data.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("invalid IGMP message: %v", err)
+	}
+	return parseIGMPMessage(data)
+}
+
+// parseIGMPMessage returns the membership events reported by an IGMP message. The IGMPv1 and IGMPv2 reports and the
+// IGMPv2 leave messages report a single group. The IGMPv3 reports contain a record per group: a Pod joins the group
+// unless the record changes the filter mode to include no source.
+func parseIGMPMessage(data []byte) ([]membershipEvent, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("IGMP message is too short: %d bytes", len(data))
+	}
+	switch data[0] {
+	case igmpV1MembershipReport, igmpV2MembershipReport:
+		return []membershipEvent{{group: net.IPv4(data[4], data[5], data[6], data[7]), join: true}}, nil
+	case igmpV2LeaveGroup:
+		return []membershipEvent{{group: net.IPv4(data[4], data[5], data[6], data[7]), join: false}}, nil
+	case igmpV3MembershipReport:
+		numRecords := int(binary.BigEndian.Uint16(data[6:8]))
+		events := make([]membershipEvent, 0, numRecords)
+		offset := 8
+		for i := 0; i < numRecords; i++ {
+			if len(data) < offset+8 {
+				return nil, errors.New("IGMPv3 group record is truncated")
+			}
+			recordType := data[offset]
+			auxDataLen := int(data[offset+1]) * 4
+			numSources := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+			group := net.IPv4(data[offset+4], data[offset+5], data[offset+6], data[offset+7])
+			offset += 8 + numSources*4 + auxDataLen
+			switch recordType {
+			case igmpModeIsExclude, igmpChangeToExcludeMode, igmpAllowNewSources:
+				events = append(events, membershipEvent{group: group, join: true})
+			case igmpModeIsInclude, igmpChangeToIncludeMode:
+				events = append(events, membershipEvent{group: group, join: numSources > 0})
+			case igmpBlockOldSources:
+				// The Pod still receives the traffic of the other sources.
+			}
+		}
+		return events, nil
+	default:
+		// The queries don't change the memberships.
+		return nil, nil
+	}
+}
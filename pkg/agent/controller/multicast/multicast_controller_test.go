@@ -0,0 +1,206 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multicast
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	openflowtest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+)
+
+var (
+	group1 = net.ParseIP("239.1.1.1")
+	group2 = net.ParseIP("239.2.2.2")
+)
+
+func newIGMPPacketIn(inPort uint32, message []byte) *ofctrl.PacketIn {
+	return &ofctrl.PacketIn{
+		Match: openflow13.Match{
+			Fields: []openflow13.MatchField{*openflow13.NewInPortField(inPort)},
+		},
+		Data: protocol.Ethernet{
+			Ethertype: protocol.IPv4_MSG,
+			Data: &protocol.IPv4{
+				Protocol: igmpProtocol,
+				Data:     util.NewBuffer(message),
+			},
+		},
+	}
+}
+
+func newPodInterface(name string, ofPort int32) *interfacestore.InterfaceConfig {
+	return &interfacestore.InterfaceConfig{
+		Type:                     interfacestore.ContainerInterface,
+		InterfaceName:            name,
+		OVSPortConfig:            &interfacestore.OVSPortConfig{OFPort: ofPort},
+		ContainerInterfaceConfig: &interfacestore.ContainerInterfaceConfig{ContainerID: name, PodName: name, PodNamespace: "ns1"},
+	}
+}
+
+func TestParseIGMPMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        []byte
+		expectedEvents []membershipEvent
+		expectedErr    bool
+	}{
+		{
+			name:           "IGMPv2 report",
+			message:        []byte{igmpV2MembershipReport, 0, 0, 0, 239, 1, 1, 1},
+			expectedEvents: []membershipEvent{{group: group1, join: true}},
+		},
+		{
+			name:           "IGMPv2 leave",
+			message:        []byte{igmpV2LeaveGroup, 0, 0, 0, 239, 1, 1, 1},
+			expectedEvents: []membershipEvent{{group: group1, join: false}},
+		},
+		{
+			name: "IGMPv3 report",
+			message: []byte{igmpV3MembershipReport, 0, 0, 0, 0, 0, 0, 3,
+				// Join group1 from any source.
+				igmpChangeToExcludeMode, 0, 0, 0, 239, 1, 1, 1,
+				// Leave group2.
+				igmpChangeToIncludeMode, 0, 0, 0, 239, 2, 2, 2,
+				// Join group2 from a single source, with auxiliary data.
+				igmpAllowNewSources, 1, 0, 1, 239, 2, 2, 2, 10, 0, 0, 1, 0, 0, 0, 0,
+			},
+			expectedEvents: []membershipEvent{{group: group1, join: true}, {group: group2, join: false}, {group: group2, join: true}},
+		},
+		{
+			name:    "IGMP query",
+			message: []byte{0x11, 100, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:        "truncated IGMPv3 report",
+			message:     []byte{igmpV3MembershipReport, 0, 0, 0, 0, 0, 0, 1, igmpModeIsExclude, 0, 0, 0},
+			expectedErr: true,
+		},
+		{
+			name:        "too short",
+			message:     []byte{igmpV2MembershipReport, 0, 0, 0},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseIGMPMessage(tt.message)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.expectedEvents), len(events))
+			for i := range tt.expectedEvents {
+				assert.True(t, tt.expectedEvents[i].group.Equal(events[i].group))
+				assert.Equal(t, tt.expectedEvents[i].join, events[i].join)
+			}
+		})
+	}
+}
+
+func TestMulticastController(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ofClient := openflowtest.NewMockClient(ctrl)
+	ifaceStore := interfacestore.NewInterfaceStore()
+	pod1 := newPodInterface("pod1", 3)
+	pod2 := newPodInterface("pod2", 4)
+	ifaceStore.AddInterface(pod1)
+	ifaceStore.AddInterface(pod2)
+
+	ofClient.EXPECT().RegisterPacketInHandler(gomock.Any(), "multicast", gomock.Any())
+	c := NewMulticastController(ofClient, ifaceStore)
+
+	join := []byte{igmpV2MembershipReport, 0, 0, 0, 239, 1, 1, 1}
+	leave := []byte{igmpV2LeaveGroup, 0, 0, 0, 239, 1, 1, 1}
+	sync := func() {
+		require.Equal(t, 1, c.queue.Len())
+		key, _ := c.queue.Get()
+		require.NoError(t, c.syncGroup(key.(string)))
+		c.queue.Done(key)
+	}
+
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(3, join)))
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(4, join)))
+	ofClient.EXPECT().InstallMulticastGroup(gomock.Any(), []uint32{3, 4}).Return(nil)
+	sync()
+
+	// The IGMP messages which are not sent by a Pod are ignored.
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(2, leave)))
+	assert.Equal(t, 0, c.queue.Len())
+
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(3, leave)))
+	ofClient.EXPECT().InstallMulticastGroup(gomock.Any(), []uint32{4}).Return(nil)
+	sync()
+
+	// A report from a member refreshes its membership without changing the group.
+	c.groupMembers[group1.String()]["pod2"] = time.Now().Add(-time.Minute)
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(4, join)))
+	assert.Equal(t, 0, c.queue.Len())
+	assert.WithinDuration(t, time.Now(), c.groupMembers[group1.String()]["pod2"], time.Second)
+
+	// The membership of a removed Pod is deleted.
+	ifaceStore.DeleteInterface(pod2)
+	c.removeStaleMembers()
+	assert.Empty(t, c.groupMembers)
+	ofClient.EXPECT().UninstallMulticastGroup(gomock.Any()).Return(nil)
+	sync()
+
+	// The membership which has not been reported for membershipTimeout is deleted.
+	require.NoError(t, c.HandlePacketIn(newIGMPPacketIn(3, join)))
+	ofClient.EXPECT().InstallMulticastGroup(gomock.Any(), []uint32{3}).Return(nil)
+	sync()
+	c.removeStaleMembers()
+	assert.Equal(t, 0, c.queue.Len())
+	c.groupMembers[group1.String()]["pod1"] = time.Now().Add(-membershipTimeout - time.Second)
+	c.removeStaleMembers()
+	assert.Empty(t, c.groupMembers)
+	ofClient.EXPECT().UninstallMulticastGroup(gomock.Any()).Return(nil)
+	sync()
+}
+
+func TestSendQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ofClient := openflowtest.NewMockClient(ctrl)
+	ifaceStore := interfacestore.NewInterfaceStore()
+	ifaceStore.AddInterface(newPodInterface("pod1", 3))
+	ifaceStore.AddInterface(newPodInterface("pod2", 4))
+	ifaceStore.AddInterface(&interfacestore.InterfaceConfig{
+		Type:          interfacestore.GatewayInterface,
+		InterfaceName: "antrea-gw0",
+		OVSPortConfig: &interfacestore.OVSPortConfig{OFPort: 2},
+	})
+
+	ofClient.EXPECT().RegisterPacketInHandler(gomock.Any(), "multicast", gomock.Any())
+	c := NewMulticastController(ofClient, ifaceStore)
+
+	// The queries are only sent to the Pods, and an error doesn't prevent querying the other Pods.
+	ofClient.EXPECT().SendIGMPQueryPacketOut(uint32(3)).Return(errors.New("error"))
+	ofClient.EXPECT().SendIGMPQueryPacketOut(uint32(4)).Return(nil)
+	c.sendQueries()
+}
@@ -88,6 +88,8 @@ type rule struct {
 	SourceRef *v1beta.NetworkPolicyReference
 	// EnableLogging is a boolean indicating whether logging is required for Antrea Policies. Always false for K8s NetworkPolicy.
 	EnableLogging bool
+	// ConnectionStates of the packets matched by this rule. Empty for K8s NetworkPolicy.
+	ConnectionStates []secv1alpha1.ConnectionState
 }
 
 // hashRule calculates a string based on the rule's content.
@@ -154,6 +156,9 @@ type ruleCache struct {
 
 	// entityUpdates is a channel for receiving entity (e.g. Pod) updates from CNIServer.
 	entityUpdates <-chan antreatypes.EntityReference
+	// realizationTracker tracks the realization of the rules applied to the
+	// entities whose updates were sent with a Realized channel.
+	realizationTracker *realizationTracker
 }
 
 func (c *ruleCache) getNetworkPolicies(npFilter *querier.NetworkPolicyQueryFilter) []v1beta.NetworkPolicy {
@@ -320,6 +325,7 @@ func newRuleCache(dirtyRuleHandler func(string), podUpdate <-chan antreatypes.En
 		dirtyRuleHandler:    dirtyRuleHandler,
 		entityUpdates:       podUpdate,
 	}
+	cache.realizationTracker = newRealizationTracker(cache.getAppliedRuleIDs)
 	go cache.processEntityUpdates()
 	return cache
 }
@@ -331,25 +337,41 @@ func newRuleCache(dirtyRuleHandler func(string), podUpdate <-chan antreatypes.En
 // done if antrea-controller has computed the Pods' policies and propagated
 // them to this Node by their labels and NodeName, instead of waiting for their
 // IPs are reported to kube-apiserver and processed by antrea-controller.
+// If the update has a Realized channel, the channel is closed once the related
+// rules have been realized.
 func (c *ruleCache) processEntityUpdates() {
 	for {
 		select {
 		case entity := <-c.entityUpdates:
-			func() {
-				member := &v1beta.GroupMember{
-					Pod:            entity.Pod,
-					ExternalEntity: entity.ExternalEntity,
-				}
-				c.appliedToSetLock.RLock()
-				defer c.appliedToSetLock.RUnlock()
-				for group, memberSet := range c.appliedToSetByGroup {
-					if memberSet.Has(member) {
-						c.onAppliedToGroupUpdate(group)
-					}
-				}
-			}()
+			member := &v1beta.GroupMember{
+				Pod:            entity.Pod,
+				ExternalEntity: entity.ExternalEntity,
+			}
+			// The entity must be tracked before its rules are marked as
+			// dirty, otherwise their syncs could be missed.
+			if entity.Realized != nil {
+				c.realizationTracker.track(member, entity.Realized)
+			}
+			for ruleID := range c.getAppliedRuleIDs(member) {
+				c.dirtyRuleHandler(ruleID)
+			}
+		}
+	}
+}
+
+// getAppliedRuleIDs returns the IDs of the rules whose AppliedToGroups contain
+// the provided member.
+func (c *ruleCache) getAppliedRuleIDs(member *v1beta.GroupMember) sets.String {
+	c.appliedToSetLock.RLock()
+	defer c.appliedToSetLock.RUnlock()
+	ruleIDs := sets.NewString()
+	for group, memberSet := range c.appliedToSetByGroup {
+		if memberSet.Has(member) {
+			groupRuleIDs, _ := c.rules.IndexKeys(appliedToGroupIndex, group)
+			ruleIDs.Insert(groupRuleIDs...)
 		}
 	}
+	return ruleIDs
 }
 
 // GetAddressGroupNum gets the number of AddressGroup.
@@ -539,18 +561,19 @@ func toRule(r *v1beta.NetworkPolicyRule, policy *v1beta.NetworkPolicy, maxPriori
 		appliedToGroups = r.AppliedToGroups
 	}
 	rule := &rule{
-		Direction:       r.Direction,
-		From:            r.From,
-		To:              r.To,
-		Services:        r.Services,
-		Action:          r.Action,
-		Priority:        r.Priority,
-		PolicyPriority:  policy.Priority,
-		TierPriority:    policy.TierPriority,
-		AppliedToGroups: appliedToGroups,
-		PolicyUID:       policy.UID,
-		SourceRef:       policy.SourceRef,
-		EnableLogging:   r.EnableLogging,
+		Direction:        r.Direction,
+		From:             r.From,
+		To:               r.To,
+		Services:         r.Services,
+		Action:           r.Action,
+		Priority:         r.Priority,
+		PolicyPriority:   policy.Priority,
+		TierPriority:     policy.TierPriority,
+		AppliedToGroups:  appliedToGroups,
+		PolicyUID:        policy.UID,
+		SourceRef:        policy.SourceRef,
+		EnableLogging:    r.EnableLogging,
+		ConnectionStates: r.ConnectionStates,
 	}
 	rule.ID = hashRule(rule)
 	rule.PolicyName = policy.Name
@@ -34,6 +34,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	"github.com/vmware-tanzu/antrea/pkg/querier"
+	"github.com/vmware-tanzu/antrea/pkg/util/watchrecord"
 )
 
 const (
@@ -80,6 +81,8 @@ type Controller struct {
 	reconciler Reconciler
 	// ofClient registers packetin for Antrea Policy logging.
 	ofClient openflow.Client
+	// logSampler samples the packets logged by each Antrea Policy rule.
+	logSampler *logSampler
 	// statusManager syncs NetworkPolicy statuses with the antrea-controller.
 	// It's only for Antrea NetworkPolicies.
 	statusManager         StatusManager
@@ -122,6 +125,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	if c.ofClient != nil && loggingEnabled {
 		// Register packetInHandler
 		c.ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonNP), "networkpolicy", c)
+		c.logSampler = newLogSampler()
 		// Initiate logger for Antrea Policy audit logging
 		err := initLogger()
 		if err != nil {
@@ -362,6 +366,37 @@ func (c *Controller) GetControllerConnectionStatus() bool {
 	return c.addressGroupWatcher.isConnected() && c.appliedToGroupWatcher.isConnected() && c.networkPolicyWatcher.isConnected()
 }
 
+// RecordWatchStreams records the events received from antrea-controller by the
+// NetworkPolicy, AppliedToGroup and AddressGroup watchers with the provided
+// recorder. It must be called before Run.
+func (c *Controller) RecordWatchStreams(recorder *watchrecord.Recorder) {
+	for _, w := range []*watcher{c.networkPolicyWatcher, c.appliedToGroupWatcher, c.addressGroupWatcher} {
+		objectType, watchFunc := w.objectType, w.watchFunc
+		w.watchFunc = func() (watch.Interface, error) {
+			source, err := watchFunc()
+			if err != nil {
+				return nil, err
+			}
+			return recorder.Wrap(objectType, source), nil
+		}
+	}
+}
+
+// ReplayWatchStreams makes the NetworkPolicy, AppliedToGroup and AddressGroup
+// watchers receive the events replayed by the provided replayer instead of the
+// events sent by antrea-controller. It must be called before Run.
+func (c *Controller) ReplayWatchStreams(replayer *watchrecord.Replayer) {
+	c.networkPolicyWatcher.watchFunc = func() (watch.Interface, error) {
+		return replayer.Watch(c.networkPolicyWatcher.objectType, func() runtime.Object { return &v1beta2.NetworkPolicy{} }), nil
+	}
+	c.appliedToGroupWatcher.watchFunc = func() (watch.Interface, error) {
+		return replayer.Watch(c.appliedToGroupWatcher.objectType, func() runtime.Object { return &v1beta2.AppliedToGroup{} }), nil
+	}
+	c.addressGroupWatcher.watchFunc = func() (watch.Interface, error) {
+		return replayer.Watch(c.addressGroupWatcher.objectType, func() runtime.Object { return &v1beta2.AddressGroup{} }), nil
+	}
+}
+
 // Run begins watching and processing Antrea AddressGroups, AppliedToGroups
 // and NetworkPolicies, and spawns workers that reconciles NetworkPolicy rules.
 // Run will not return until stopCh is closed.
@@ -404,6 +439,11 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	klog.Infof("Starting IDAllocator worker to maintain the async rule cache")
 	go c.reconciler.RunIDAllocatorWorker(stopCh)
 
+	if c.antreaPolicyEnabled {
+		klog.Infof("Starting priority compaction worker for Antrea policy tables")
+		go c.reconciler.RunPriorityCompactionWorker(stopCh)
+	}
+
 	if c.statusManagerEnabled {
 		go c.statusManager.Run(stopCh)
 	}
@@ -463,6 +503,7 @@ func (c *Controller) syncRule(key string) error {
 		klog.V(4).Infof("Finished syncing rule %q. (%v)", key, time.Since(startTime))
 	}()
 
+	trackedEntities := c.ruleCache.realizationTracker.startSync()
 	rule, exists, completed := c.ruleCache.GetCompletedRule(key)
 	if !exists {
 		klog.V(2).Infof("Rule %v had been deleted, removing its flows", key)
@@ -474,6 +515,7 @@ func (c *Controller) syncRule(key string) error {
 			// harmless to delete it.
 			c.statusManager.DeleteRuleRealization(key)
 		}
+		c.ruleCache.realizationTracker.rulesSynced([]string{key}, trackedEntities)
 		return nil
 	}
 	// If the rule is not complete, we can simply skip it as it will be marked as dirty
@@ -488,6 +530,7 @@ func (c *Controller) syncRule(key string) error {
 	if c.statusManagerEnabled && rule.SourceRef.Type != v1beta2.K8sNetworkPolicy {
 		c.statusManager.SetRuleRealization(key, rule.PolicyUID)
 	}
+	c.ruleCache.realizationTracker.rulesSynced([]string{key}, trackedEntities)
 	return nil
 }
 
@@ -500,6 +543,7 @@ func (c *Controller) syncRules(keys []string) error {
 		klog.V(4).Infof("Finished syncing all rules before bookmark event (%v)", time.Since(startTime))
 	}()
 
+	trackedEntities := c.ruleCache.realizationTracker.startSync()
 	var allRules []*CompletedRule
 	for _, key := range keys {
 		rule, exists, completed := c.ruleCache.GetCompletedRule(key)
@@ -512,13 +556,14 @@ func (c *Controller) syncRules(keys []string) error {
 	if err := c.reconciler.BatchReconcile(allRules); err != nil {
 		return err
 	}
-	if c.statusManagerEnabled {
-		for _, rule := range allRules {
-			if rule.SourceRef.Type != v1beta2.K8sNetworkPolicy {
-				c.statusManager.SetRuleRealization(rule.ID, rule.PolicyUID)
-			}
+	ruleIDs := make([]string, 0, len(allRules))
+	for _, rule := range allRules {
+		if c.statusManagerEnabled && rule.SourceRef.Type != v1beta2.K8sNetworkPolicy {
+			c.statusManager.SetRuleRealization(rule.ID, rule.PolicyUID)
 		}
+		ruleIDs = append(ruleIDs, rule.ID)
 	}
+	c.ruleCache.realizationTracker.rulesSynced(ruleIDs, trackedEntities)
 	return nil
 }
 
@@ -107,6 +107,10 @@ func (r *mockReconciler) RunIDAllocatorWorker(_ <-chan struct{}) {
 	return
 }
 
+func (r *mockReconciler) RunPriorityCompactionWorker(_ <-chan struct{}) {
+	return
+}
+
 func (r *mockReconciler) GetRuleByFlowID(_ uint32) (*agenttypes.PolicyRule, bool, error) {
 	return nil, false, nil
 }
@@ -393,6 +397,74 @@ func TestDeleteRule(t *testing.T) {
 	}
 }
 
+// TestPodRulesRealizedBeforeCNIAdd verifies that the Realized channel of a Pod
+// update is closed only after all the rules applied to the Pod are realized,
+// including the rules received while the CNI ADD request is in progress.
+func TestPodRulesRealizedBeforeCNIAdd(t *testing.T) {
+	clientset := &fake.Clientset{}
+	ch := make(chan agenttypes.EntityReference, 100)
+	controller, _ := NewNetworkPolicyController(&antreaClientGetter{clientset}, nil, nil, "node1", ch,
+		true, true, true, testAsyncDeleteInterval)
+	reconciler := newMockReconciler()
+	controller.reconciler = reconciler
+	addressGroupWatcher := watch.NewFake()
+	appliedToGroupWatcher := watch.NewFake()
+	networkPolicyWatcher := watch.NewFake()
+	clientset.AddWatchReactor("addressgroups", k8stesting.DefaultWatchReactor(addressGroupWatcher, nil))
+	clientset.AddWatchReactor("appliedtogroups", k8stesting.DefaultWatchReactor(appliedToGroupWatcher, nil))
+	clientset.AddWatchReactor("networkpolicies", k8stesting.DefaultWatchReactor(networkPolicyWatcher, nil))
+
+	protocolTCP := v1beta2.ProtocolTCP
+	port := intstr.FromInt(80)
+	services := []v1beta2.Service{{Protocol: &protocolTCP, Port: &port}}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go controller.Run(stopCh)
+
+	addressGroupWatcher.Add(newAddressGroup("addressGroup1", []v1beta2.GroupMember{*newAddressGroupMember("1.1.1.1")}))
+	addressGroupWatcher.Action(watch.Bookmark, nil)
+	appliedToGroupWatcher.Add(newAppliedToGroup("appliedToGroup1", []v1beta2.GroupMember{*newAppliedToGroupMember("pod1", "ns1")}))
+	appliedToGroupWatcher.Action(watch.Bookmark, nil)
+	networkPolicyWatcher.Add(newNetworkPolicy("policy1", "uid1", []string{"addressGroup1"}, []string{}, []string{"appliedToGroup1"}, services))
+	networkPolicyWatcher.Action(watch.Bookmark, nil)
+	select {
+	case <-reconciler.updated:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("Expected one update, got none")
+	}
+
+	// Block the reconciler to simulate a policy churn, in which the rules
+	// of the Pod are received while its CNI ADD request is in progress.
+	reconciler.Lock()
+	realized := make(chan struct{})
+	ch <- agenttypes.EntityReference{Pod: &v1beta2.PodReference{Name: "pod1", Namespace: "ns1"}, Realized: realized}
+	appliedToGroupWatcher.Add(newAppliedToGroup("appliedToGroup2", []v1beta2.GroupMember{*newAppliedToGroupMember("pod1", "ns1")}))
+	networkPolicyWatcher.Add(newNetworkPolicy("policy2", "uid2", []string{"addressGroup1"}, []string{}, []string{"appliedToGroup2"}, services))
+	require.Eventually(t, func() bool {
+		return controller.GetNetworkPolicyNum() == 2 && controller.GetAppliedToGroupNum() == 2
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-realized:
+		t.Fatal("Expected the rules of the Pod not to be realized")
+	case <-time.After(time.Millisecond * 100):
+	}
+	reconciler.Unlock()
+
+	select {
+	case <-realized:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the rules of the Pod to be realized")
+	}
+	// Both rules must have been realized for the Pod when the CNI ADD
+	// request is notified.
+	reconciler.Lock()
+	defer reconciler.Unlock()
+	require.Len(t, reconciler.lastRealized, 2)
+	for ruleID, rule := range reconciler.lastRealized {
+		assert.True(t, rule.TargetMembers.Has(newAppliedToGroupMember("pod1", "ns1")), "Rule %s is not applied to the Pod", ruleID)
+	}
+}
+
 func TestAddNetworkPolicyWithMultipleRules(t *testing.T) {
 	controller, clientset, reconciler := newTestController()
 	addressGroupWatcher := watch.NewFake()
@@ -19,11 +19,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet/ofctrl"
+	"golang.org/x/time/rate"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
@@ -34,6 +38,17 @@ import (
 const (
 	logDir      string = "/var/log/antrea/networkpolicy/"
 	logfileName string = "np.log"
+
+	// logRatePerRule is the maximum rate of the audit log entries of each policy rule, in entries per second. The
+	// packets logged by a rule above this rate are sampled: they are counted, and the number of packets which were
+	// not logged is reported with the next entry of the rule.
+	logRatePerRule = 10
+	// logBurstPerRule is the number of entries which can be logged for a rule at once, before the rate applies.
+	logBurstPerRule = 20
+	// maxSampledRules is the maximum number of rules whose sampling state is remembered, and sampledRuleTTL the
+	// duration after which the state of a rule which did not log any packet is forgotten.
+	maxSampledRules = 1024
+	sampledRuleTTL  = time.Minute
 )
 
 var (
@@ -44,7 +59,7 @@ var (
 type logInfo struct {
 	tableName   string // name of the table sending packetin
 	npRef       string // Network Policy name reference for Antrea NetworkPolicy
-	disposition string // Allow/Drop of the rule sending packetin
+	disposition string // Allow/Drop/Reject of the rule sending packetin
 	ofPriority  string // openflow priority of the flow sending packetin
 	srcIP       string // source IP of the traffic logged
 	destIP      string // destination IP of the traffic logged
@@ -52,6 +67,51 @@ type logInfo struct {
 	protocolStr string // protocol of the traffic logged
 }
 
+// logSampler samples the packets logged by each policy rule, so that a rule matching many packets cannot flood the
+// audit log. The packets sent to the agent are already rate-limited per packet-in reason by OVS when the datapath
+// supports Meters, except for the allowed packets, which the Meter would drop.
+type logSampler struct {
+	mutex sync.Mutex
+	// rules stores the ruleLogSampler of the rules which logged packets recently.
+	rules *cache.LRUExpireCache
+}
+
+type ruleLogSampler struct {
+	limiter *rate.Limiter
+	// skipped is the number of packets of the rule which were not logged since its last entry.
+	skipped uint64
+}
+
+func newLogSampler() *logSampler {
+	return &logSampler{rules: cache.NewLRUExpireCache(maxSampledRules)}
+}
+
+// sample returns whether a packet logged by the rule identified by ruleKey at time now must be logged and, if so, the
+// number of packets of the rule which were skipped since its previous entry.
+func (s *logSampler) sample(ruleKey string, now time.Time) (bool, uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var rule *ruleLogSampler
+	if obj, ok := s.rules.Get(ruleKey); ok {
+		rule = obj.(*ruleLogSampler)
+	} else {
+		rule = &ruleLogSampler{limiter: rate.NewLimiter(logRatePerRule, logBurstPerRule)}
+	}
+	s.rules.Add(ruleKey, rule, sampledRuleTTL)
+	if !rule.limiter.AllowN(now, 1) {
+		rule.skipped++
+		return false, 0
+	}
+	skipped := rule.skipped
+	rule.skipped = 0
+	return true, skipped
+}
+
+// ruleKey returns the key identifying the policy rule which logged the packet, used to sample the log entries.
+func (ob *logInfo) ruleKey() string {
+	return fmt.Sprintf("%s %s %s %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority)
+}
+
 // initLogger is called while newing Antrea network policy agent controller.
 // Customize AntreaPolicyLogger specifically for Antrea Policies audit logging.
 func initLogger() error {
@@ -79,12 +139,34 @@ func initLogger() error {
 }
 
 // HandlePacketIn is the packetin handler registered to openflow by Antrea network policy agent controller.
-// Retrieves information from openflow reg, controller cache, packetin packet to log.
+// The custom reasons loaded in the register by the flow sending the packetin decide whether the packet is logged,
+// and whether a reject response is sent back to the source of the packet.
 func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 	if pktIn == nil {
 		return errors.New("empty packetin for Antrea Policy")
 	}
+	matchers := pktIn.GetMatches()
+	match := getMatchRegField(matchers, uint32(openflow.CustomReasonMarkReg))
+	customReasons, err := getInfoInReg(match, openflow.CustomReasonMarkRange.ToNXRange())
+	if err != nil {
+		return fmt.Errorf("received error while unloading custom reasons from reg: %v", err)
+	}
+	isReject := customReasons&openflow.CustomReasonReject == openflow.CustomReasonReject
+	if customReasons&openflow.CustomReasonLogging == openflow.CustomReasonLogging {
+		if err := c.logPacket(pktIn, isReject); err != nil {
+			return err
+		}
+	}
+	if isReject {
+		if err := c.rejectRequest(pktIn); err != nil {
+			return fmt.Errorf("received error while sending reject response for NetworkPolicy: %v", err)
+		}
+	}
+	return nil
+}
 
+// logPacket retrieves information from openflow reg, controller cache, packetin packet to log.
+func (c *Controller) logPacket(pktIn *ofctrl.PacketIn, isReject bool) error {
 	ob := new(logInfo)
 
 	// Get Network Policy log info
@@ -92,6 +174,9 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 	if err != nil {
 		return fmt.Errorf("received error while retrieving NetworkPolicy info: %v", err)
 	}
+	if isReject {
+		ob.disposition = "Reject"
+	}
 
 	// Get packet log info
 	err = getPacketInfo(pktIn, ob)
@@ -99,8 +184,17 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 		return fmt.Errorf("received error while handling packetin for NetworkPolicy: %v", err)
 	}
 
+	logged, skipped := c.logSampler.sample(ob.ruleKey(), time.Now())
+	if !logged {
+		return nil
+	}
+
 	// Store log file
-	AntreaPolicyLogger.Printf("%s %s %s %s SRC: %s DEST: %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
+	entry := fmt.Sprintf("%s %s %s %s SRC: %s DEST: %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
+	if skipped > 0 {
+		entry = fmt.Sprintf("%s SKIPPED: %d", entry, skipped)
+	}
+	AntreaPolicyLogger.Print(entry)
 	return nil
 }
 
@@ -17,6 +17,7 @@ package networkpolicy
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/libOpenflow/util"
@@ -63,3 +64,25 @@ func TestGetPacketInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestLogSampler(t *testing.T) {
+	s := newLogSampler()
+	now := time.Now()
+	// The first logBurstPerRule packets of a rule are logged.
+	for i := 0; i < logBurstPerRule; i++ {
+		logged, skipped := s.sample("rule1", now)
+		assert.True(t, logged)
+		assert.Equal(t, uint64(0), skipped)
+	}
+	// The next packets are skipped, without affecting the other rules.
+	for i := 0; i < 5; i++ {
+		logged, _ := s.sample("rule1", now)
+		assert.False(t, logged)
+	}
+	logged, _ := s.sample("rule2", now)
+	assert.True(t, logged)
+	// Once the rate allows it, the next packet is logged with the number of skipped packets.
+	logged, skipped := s.sample("rule1", now.Add(time.Second/logRatePerRule))
+	assert.True(t, logged)
+	assert.Equal(t, uint64(5), skipped)
+}
@@ -397,3 +397,97 @@ func (pa *priorityAssigner) unregisterPriority(priority types.Priority) {
 	}
 	pa.sortedPriorities = append(pa.sortedPriorities[:idxToDel], pa.sortedPriorities[idxToDel+1:]...)
 }
+
+// compactedOFPriorities computes the ofPriorities that the registered Priorities would be assigned if they were
+// spread evenly in the table. Consecutive Priorities are kept next to each other, and the unused ofPriorities are
+// distributed evenly between the groups of consecutive Priorities. The returned slice is in the same order as
+// sortedPriorities.
+func (pa *priorityAssigner) compactedOFPriorities() []uint16 {
+	numPriorities := len(pa.sortedPriorities)
+	if numPriorities == 0 {
+		return nil
+	}
+	numZones := 1
+	for i := 1; i < numPriorities; i++ {
+		if !pa.sortedPriorities[i].IsConsecutive(pa.sortedPriorities[i-1]) {
+			numZones++
+		}
+	}
+	gap := (int(pa.policyTopPriority-pa.policyBottomPriority) + 1 - numPriorities) / (numZones + 1)
+	targets := make([]uint16, numPriorities)
+	ofPriority := int(pa.policyBottomPriority) + gap
+	for i := range pa.sortedPriorities {
+		if i > 0 {
+			ofPriority++
+			if !pa.sortedPriorities[i].IsConsecutive(pa.sortedPriorities[i-1]) {
+				ofPriority += gap
+			}
+		}
+		targets[i] = uint16(ofPriority)
+	}
+	return targets
+}
+
+// isFragmented returns true if two groups of consecutive Priorities have been assigned adjacent ofPriorities while
+// there are enough unused ofPriorities to separate them. In that case, registering a Priority between them requires
+// reassigning existing Priorities.
+func (pa *priorityAssigner) isFragmented(targets []uint16) bool {
+	for i := 1; i < len(pa.sortedPriorities); i++ {
+		if pa.sortedPriorities[i].IsConsecutive(pa.sortedPriorities[i-1]) {
+			continue
+		}
+		if pa.priorityMap[pa.sortedPriorities[i]]-pa.priorityMap[pa.sortedPriorities[i-1]] == 1 && targets[i]-targets[i-1] > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// CompactPriorities moves up to maxUpdates registered Priorities towards the ofPriorities computed by
+// compactedOFPriorities, if the table is fragmented. It returns the ofPriority updates, as well as a revert
+// function that can undo the reassignment if any error occurred in data plane.
+// Priorities that move to a lower ofPriority are processed from the lowest to the highest, and then Priorities
+// that move to a higher ofPriority are processed from the highest to the lowest. This guarantees that the
+// ofPriorities of the registered Priorities are still unique and in order after each batch of updates, so the
+// compaction can be spread over multiple calls without disrupting traffic. The compaction is complete when no
+// update is returned.
+func (pa *priorityAssigner) CompactPriorities(maxUpdates int) (map[uint16]uint16, func()) {
+	targets := pa.compactedOFPriorities()
+	if !pa.isFragmented(targets) {
+		return nil, nil
+	}
+	var toMove []int
+	for i, p := range pa.sortedPriorities {
+		if len(toMove) == maxUpdates {
+			break
+		}
+		if targets[i] < pa.priorityMap[p] {
+			toMove = append(toMove, i)
+		}
+	}
+	if len(toMove) == 0 {
+		for i := len(pa.sortedPriorities) - 1; i >= 0 && len(toMove) < maxUpdates; i-- {
+			if targets[i] > pa.priorityMap[pa.sortedPriorities[i]] {
+				toMove = append(toMove, i)
+			}
+		}
+	}
+	allUpdates := map[types.Priority]*PriorityUpdate{}
+	for _, i := range toMove {
+		p := pa.sortedPriorities[i]
+		allUpdates[p] = &PriorityUpdate{Original: pa.priorityMap[p], Updated: targets[i]}
+		delete(pa.ofPriorityMap, pa.priorityMap[p])
+	}
+	for p, update := range allUpdates {
+		pa.updatePriorityAssignment(update.Updated, p)
+	}
+	revertFunc := func() {
+		for p := range allUpdates {
+			delete(pa.ofPriorityMap, pa.priorityMap[p])
+		}
+		for p, update := range allUpdates {
+			pa.updatePriorityAssignment(update.Original, p)
+		}
+	}
+	return priorityUpdatesToOFUpdates(allUpdates), revertFunc
+}
@@ -349,3 +349,34 @@ func TestRegisterAllOFPriorities(t *testing.T) {
 	_, _, err = pa.RegisterPriorities([]types.Priority{extraPriority})
 	assert.Errorf(t, err, "Error should be raised after max number of priorities are registered")
 }
+
+func TestCompactPriorities(t *testing.T) {
+	pa := newPriorityAssigner(true)
+	// Priorities of different policies are assigned adjacent ofPriorities, which is what happens
+	// after existing Priorities are reassigned to make room for new ones.
+	pa.updatePriorityAssignment(100, p1130)
+	pa.updatePriorityAssignment(101, p1121)
+	pa.updatePriorityAssignment(102, p1120)
+	pa.updatePriorityAssignment(103, p110)
+	// There are 167 unused ofPriorities for 3 groups of consecutive Priorities, so the groups
+	// should be separated by 41 unused ofPriorities.
+	assert.Equal(t, []uint16{51, 93, 94, 136}, pa.compactedOFPriorities())
+
+	updates, revertFunc := pa.CompactPriorities(2)
+	assert.Equal(t, map[uint16]uint16{100: 51, 101: 93}, updates)
+	revertFunc()
+	assert.Equal(t, map[uint16]types.Priority{100: p1130, 101: p1121, 102: p1120, 103: p110}, pa.ofPriorityMap)
+
+	expectedUpdates := []map[uint16]uint16{
+		{100: 51, 101: 93},
+		{102: 94},
+		{103: 136},
+	}
+	for i, expected := range expectedUpdates {
+		updates, _ = pa.CompactPriorities(2)
+		assert.Equal(t, expected, updates, "Unexpected updates for batch %d", i)
+	}
+	assert.Equal(t, map[uint16]types.Priority{51: p1130, 93: p1121, 94: p1120, 136: p110}, pa.ofPriorityMap)
+	updates, _ = pa.CompactPriorities(2)
+	assert.Empty(t, updates, "No update expected after compaction")
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+// maxRealizationTrackingTime is the time after which an entity stops being
+// tracked if its rules are still not realized, e.g. because one of them is not
+// complete. The senders don't wait that long for the realization.
+const maxRealizationTrackingTime = time.Minute
+
+// entityRealization is the realization state of the rules applied to an
+// entity, for which the sender of the entity update is waiting.
+type entityRealization struct {
+	member *v1beta.GroupMember
+	// realizedRules are the rules which have been synced by a sync that
+	// started after the entity update was received.
+	realizedRules sets.String
+	realized      chan<- struct{}
+	trackingTime  time.Time
+}
+
+// realizationTracker tracks the entities whose updates were sent with a
+// Realized channel, typically Pods for which CNIServer is processing a CNI ADD
+// request, and closes the channel once all the rules applied to an entity have
+// been realized.
+// The rules applied to an entity are computed again each time a rule is
+// synced, as opposed to once when the entity update is received. This way the
+// rule updates received while the request is in progress, e.g. a new
+// NetworkPolicy or an AppliedToGroup patch selecting the Pod, are merged into
+// the set of rules to wait for, and there is no window in which the Pod is
+// running without one of its rules.
+type realizationTracker struct {
+	mutex   sync.Mutex
+	pending map[*entityRealization]struct{}
+	// getRuleIDs returns the IDs of the rules currently applied to the
+	// provided member.
+	getRuleIDs func(member *v1beta.GroupMember) sets.String
+}
+
+func newRealizationTracker(getRuleIDs func(member *v1beta.GroupMember) sets.String) *realizationTracker {
+	return &realizationTracker{
+		pending:    map[*entityRealization]struct{}{},
+		getRuleIDs: getRuleIDs,
+	}
+}
+
+// track starts tracking the realization of the rules applied to the provided
+// member. It must be called before the rules are enqueued, so that their syncs
+// are taken into account.
+func (t *realizationTracker) track(member *v1beta.GroupMember, realized chan<- struct{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	r := &entityRealization{member: member, realizedRules: sets.NewString(), realized: realized, trackingTime: time.Now()}
+	if !t.checkLocked(r) {
+		t.pending[r] = struct{}{}
+	}
+}
+
+// startSync must be called before a rule is read from the cache to be synced.
+// It returns the entities which are being tracked at the moment, for which the
+// sync can be taken into account.
+func (t *realizationTracker) startSync() []*entityRealization {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.pending) == 0 {
+		return nil
+	}
+	entities := make([]*entityRealization, 0, len(t.pending))
+	for r := range t.pending {
+		entities = append(entities, r)
+	}
+	return entities
+}
+
+// rulesSynced must be called after the provided rules have been successfully
+// realized (or removed), with the entities returned by startSync before the
+// syncs started. It notifies the entities whose rules have all been realized.
+func (t *realizationTracker) rulesSynced(ruleIDs []string, entities []*entityRealization) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.pending) == 0 {
+		return
+	}
+	for _, r := range entities {
+		r.realizedRules.Insert(ruleIDs...)
+	}
+	// The rules deleted by the syncs may have been the last rules some
+	// entities were waiting for, so all the pending entities are checked.
+	for r := range t.pending {
+		if t.checkLocked(r) || time.Since(r.trackingTime) > maxRealizationTrackingTime {
+			delete(t.pending, r)
+		}
+	}
+}
+
+// checkLocked closes the Realized channel of the entity and returns true if
+// all the rules applied to the entity have been realized.
+func (t *realizationTracker) checkLocked(r *entityRealization) bool {
+	if !r.realizedRules.IsSuperset(t.getRuleIDs(r.member)) {
+		return false
+	}
+	close(r.realized)
+	return true
+}
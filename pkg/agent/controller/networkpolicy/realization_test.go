@@ -0,0 +1,66 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestRealizationTracker(t *testing.T) {
+	appliedRules := sets.NewString()
+	tracker := newRealizationTracker(func(_ *v1beta.GroupMember) sets.String {
+		return appliedRules
+	})
+	pod := newAppliedToGroupMember("pod1", "ns1")
+
+	// No rule is applied to the Pod.
+	realized1 := make(chan struct{})
+	tracker.track(pod, realized1)
+	assert.True(t, isClosed(realized1))
+
+	appliedRules.Insert("rule1")
+	// The sync of rule1 started before the Pod was tracked, it must not be taken into account.
+	entities := tracker.startSync()
+	realized2 := make(chan struct{})
+	tracker.track(pod, realized2)
+	tracker.rulesSynced([]string{"rule1"}, entities)
+	assert.False(t, isClosed(realized2))
+
+	// rule2 is applied to the Pod while waiting for rule1.
+	entities = tracker.startSync()
+	appliedRules.Insert("rule2")
+	tracker.rulesSynced([]string{"rule1"}, entities)
+	assert.False(t, isClosed(realized2))
+
+	// rule2 is deleted.
+	appliedRules.Delete("rule2")
+	tracker.rulesSynced([]string{"rule2"}, tracker.startSync())
+	assert.True(t, isClosed(realized2))
+	assert.Empty(t, tracker.pending)
+}
@@ -40,6 +40,15 @@ var (
 	baselineTierPriority int32 = 253
 )
 
+const (
+	// priorityCompactionInterval is the interval at which the Antrea policy tables
+	// are checked for fragmented priorities.
+	priorityCompactionInterval = time.Minute
+	// priorityCompactionBatchSize is the maximum number of Priorities reassigned
+	// in a single OpenFlow bundle when compacting a table.
+	priorityCompactionBatchSize = 100
+)
+
 // Reconciler is an interface that knows how to reconcile the desired state of
 // CompletedRule with the actual state of Openflow entries.
 type Reconciler interface {
@@ -61,6 +70,10 @@ type Reconciler interface {
 	// RunIDAllocatorWorker runs the worker that deletes the rules from the cache
 	// in idAllocator.
 	RunIDAllocatorWorker(stopCh <-chan struct{})
+
+	// RunPriorityCompactionWorker runs the worker that compacts the
+	// priorities of the Antrea policy tables when they get fragmented.
+	RunPriorityCompactionWorker(stopCh <-chan struct{})
 }
 
 // servicesKey is used to identify Services based on their numbered ports.
@@ -228,6 +241,56 @@ func (r *reconciler) RunIDAllocatorWorker(stopCh <-chan struct{}) {
 	<-stopCh
 }
 
+// RunPriorityCompactionWorker runs the worker that compacts the priorities of
+// the Antrea policy tables when they get fragmented.
+func (r *reconciler) RunPriorityCompactionWorker(stopCh <-chan struct{}) {
+	wait.Until(func() { r.compactPriorities(stopCh) }, priorityCompactionInterval, stopCh)
+}
+
+// compactPriorities compacts the priorities of all the Antrea policy tables. It
+// returns early if stopCh is closed, as compacting a large table can take many
+// batches.
+func (r *reconciler) compactPriorities(stopCh <-chan struct{}) {
+	for table, pa := range r.priorityAssigners {
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			done, err := r.compactTablePriorities(table, pa)
+			if err != nil {
+				klog.Errorf("Failed to compact priorities of table %d: %v", table, err)
+				break
+			}
+			if done {
+				break
+			}
+		}
+	}
+}
+
+// compactTablePriorities reassigns a batch of Priorities of the table to
+// compact its priorities, and returns true if there is nothing left to compact.
+// The mutex of the table is only held for a single batch so that rules can
+// still be reconciled while a large table is being compacted.
+func (r *reconciler) compactTablePriorities(table binding.TableIDType, pa *tablePriorityAssigner) (bool, error) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	priorityUpdates, revertFunc := pa.assigner.CompactPriorities(priorityCompactionBatchSize)
+	if len(priorityUpdates) == 0 {
+		return true, nil
+	}
+	klog.V(2).Infof("Reassigning %d priorities to compact table %d", len(priorityUpdates), table)
+	// The flows are updated in a single bundle, and the order of the Priorities
+	// is preserved by every batch, so traffic is not disrupted.
+	if err := r.ofClient.ReassignFlowPriorities(priorityUpdates, table); err != nil {
+		revertFunc()
+		return false, err
+	}
+	return false, nil
+}
+
 // Reconcile checks whether the provided rule have been enforced or not, and
 // invoke the add or update method accordingly.
 func (r *reconciler) Reconcile(rule *CompletedRule) error {
@@ -426,15 +489,16 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 			ofPorts := r.getOFPorts(members)
 			lastRealized.podOFPorts[svcKey] = ofPorts
 			ofRuleByServicesMap[svcKey] = &types.PolicyRule{
-				Direction:     v1beta2.DirectionIn,
-				From:          append(from1, from2...),
-				To:            ofPortsToOFAddresses(ofPorts),
-				Service:       filterUnresolvablePort(servicesMap[svcKey]),
-				Action:        rule.Action,
-				Priority:      ofPriority,
-				TableID:       table,
-				PolicyRef:     rule.SourceRef,
-				EnableLogging: rule.EnableLogging,
+				Direction:        v1beta2.DirectionIn,
+				From:             append(from1, from2...),
+				To:               ofPortsToOFAddresses(ofPorts),
+				Service:          filterUnresolvablePort(servicesMap[svcKey]),
+				Action:           rule.Action,
+				Priority:         ofPriority,
+				TableID:          table,
+				PolicyRef:        rule.SourceRef,
+				EnableLogging:    rule.EnableLogging,
+				ConnectionStates: rule.ConnectionStates,
 			}
 		}
 	} else {
@@ -444,15 +508,16 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 		memberByServicesMap, servicesMap := groupMembersByServices(rule.Services, rule.ToAddresses)
 		for svcKey, members := range memberByServicesMap {
 			ofRuleByServicesMap[svcKey] = &types.PolicyRule{
-				Direction:     v1beta2.DirectionOut,
-				From:          from,
-				To:            groupMembersToOFAddresses(members),
-				Service:       filterUnresolvablePort(servicesMap[svcKey]),
-				Action:        rule.Action,
-				Priority:      ofPriority,
-				TableID:       table,
-				PolicyRef:     rule.SourceRef,
-				EnableLogging: rule.EnableLogging,
+				Direction:        v1beta2.DirectionOut,
+				From:             from,
+				To:               groupMembersToOFAddresses(members),
+				Service:          filterUnresolvablePort(servicesMap[svcKey]),
+				Action:           rule.Action,
+				Priority:         ofPriority,
+				TableID:          table,
+				PolicyRef:        rule.SourceRef,
+				EnableLogging:    rule.EnableLogging,
+				ConnectionStates: rule.ConnectionStates,
 			}
 		}
 
@@ -467,15 +532,16 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 			// Create a new Openflow rule if the group doesn't exist.
 			if !exists {
 				ofRule = &types.PolicyRule{
-					Direction:     v1beta2.DirectionOut,
-					From:          from,
-					To:            []types.Address{},
-					Service:       filterUnresolvablePort(rule.Services),
-					Action:        rule.Action,
-					Priority:      nil,
-					TableID:       table,
-					PolicyRef:     rule.SourceRef,
-					EnableLogging: rule.EnableLogging,
+					Direction:        v1beta2.DirectionOut,
+					From:             from,
+					To:               []types.Address{},
+					Service:          filterUnresolvablePort(rule.Services),
+					Action:           rule.Action,
+					Priority:         nil,
+					TableID:          table,
+					PolicyRef:        rule.SourceRef,
+					EnableLogging:    rule.EnableLogging,
+					ConnectionStates: rule.ConnectionStates,
 				}
 				ofRuleByServicesMap[svcKey] = ofRule
 			}
@@ -553,16 +619,17 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 			// Install a new Openflow rule if this group doesn't exist, otherwise do incremental update.
 			if !exists {
 				ofRule := &types.PolicyRule{
-					Direction:     v1beta2.DirectionIn,
-					From:          append(from1, from2...),
-					To:            ofPortsToOFAddresses(newOFPorts),
-					Service:       filterUnresolvablePort(servicesMap[svcKey]),
-					Action:        newRule.Action,
-					Priority:      ofPriority,
-					FlowID:        ofID,
-					TableID:       table,
-					PolicyRef:     newRule.SourceRef,
-					EnableLogging: newRule.EnableLogging,
+					Direction:        v1beta2.DirectionIn,
+					From:             append(from1, from2...),
+					To:               ofPortsToOFAddresses(newOFPorts),
+					Service:          filterUnresolvablePort(servicesMap[svcKey]),
+					Action:           newRule.Action,
+					Priority:         ofPriority,
+					FlowID:           ofID,
+					TableID:          table,
+					PolicyRef:        newRule.SourceRef,
+					EnableLogging:    newRule.EnableLogging,
+					ConnectionStates: newRule.ConnectionStates,
 				}
 				err := r.idAllocator.allocateForRule(ofRule)
 				if err != nil {
@@ -602,16 +669,17 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 			ofID, exists := lastRealized.ofIDs[svcKey]
 			if !exists {
 				ofRule := &types.PolicyRule{
-					Direction:     v1beta2.DirectionOut,
-					From:          from,
-					To:            groupMembersToOFAddresses(members),
-					Service:       filterUnresolvablePort(servicesMap[svcKey]),
-					Action:        newRule.Action,
-					Priority:      ofPriority,
-					FlowID:        ofID,
-					TableID:       table,
-					PolicyRef:     newRule.SourceRef,
-					EnableLogging: newRule.EnableLogging,
+					Direction:        v1beta2.DirectionOut,
+					From:             from,
+					To:               groupMembersToOFAddresses(members),
+					Service:          filterUnresolvablePort(servicesMap[svcKey]),
+					Action:           newRule.Action,
+					Priority:         ofPriority,
+					FlowID:           ofID,
+					TableID:          table,
+					PolicyRef:        newRule.SourceRef,
+					EnableLogging:    newRule.EnableLogging,
+					ConnectionStates: newRule.ConnectionStates,
 				}
 				// If the PolicyRule for the original services doesn't exist and IPBlocks is present, it means the
 				// reconciler hasn't installed flows for IPBlocks, then it must be added to the new PolicyRule.
@@ -0,0 +1,193 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	"k8s.io/klog"
+)
+
+const (
+	tcpFlagSYN uint8 = 0x02
+	tcpFlagRST uint8 = 0x04
+	tcpFlagACK uint8 = 0x10
+
+	icmpEchoRequestType   uint8 = 8
+	icmpDstUnreachType    uint8 = 3
+	icmpPortUnreachCode   uint8 = 3
+	icmpv6EchoRequestType uint8 = 128
+	icmpv6DstUnreachType  uint8 = 1
+	icmpv6PortUnreachCode uint8 = 4
+
+	// icmpOrigDataLen is the number of bytes of the original datagram's data,
+	// after its IP header, which are included in an ICMP error message.
+	icmpOrigDataLen = 8
+)
+
+// rejectRequest sends a reject response for the packet sent to the controller
+// by a flow of a Reject rule. A TCP RST packet is sent for TCP packets, and an
+// ICMP port unreachable packet is sent for other packets. The response is
+// output to the port from which the packet was received, through the tunnel it
+// came from if any, so that it does not go through the pipeline again. The
+// response to a packet whose destination was translated by AntreaProxy on this
+// Node, i.e. sent to a Service, goes through the conntrack table instead, so
+// that its source is translated back to the Service address.
+func (c *Controller) rejectRequest(pktIn *ofctrl.PacketIn) error {
+	matchers := pktIn.GetMatches()
+	inPort, err := getInPortValue(matchers)
+	if err != nil {
+		return err
+	}
+	var tunnelDst net.IP
+	if match := matchers.GetMatchByName("NXM_NX_TUN_IPV4_SRC"); match != nil {
+		tunnelDst, _ = match.GetValue().(net.IP)
+	}
+	return c.sendRejectResponse(&pktIn.Data, inPort, tunnelDst, getCTOrigDstIP(matchers))
+}
+
+// sendRejectResponse sends the reject response for the ethernet packet back
+// to its source through outPort. ctOrigDstIP is the original destination of
+// the connection of the packet in the conntrack table of Antrea, if any.
+func (c *Controller) sendRejectResponse(ethPacket *protocol.Ethernet, inPort uint32, tunnelDst net.IP, ctOrigDstIP net.IP) error {
+	var srcIP, dstIP net.IP
+	var proto uint8
+	var transport interface{}
+	var origData []byte
+	isIPv6 := false
+	switch ethPacket.Ethertype {
+	case protocol.IPv4_MSG:
+		ipPacket, ok := ethPacket.Data.(*protocol.IPv4)
+		if !ok {
+			return errors.New("invalid IPv4 packet")
+		}
+		srcIP, dstIP, proto, transport = ipPacket.NWSrc, ipPacket.NWDst, ipPacket.Protocol, ipPacket.Data
+		data, err := ipPacket.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("error when marshalling IPv4 packet: %v", err)
+		}
+		origData = truncateOrigData(data, int(ipPacket.IHL)*4)
+	case protocol.IPv6_MSG:
+		ipPacket, ok := ethPacket.Data.(*protocol.IPv6)
+		if !ok {
+			return errors.New("invalid IPv6 packet")
+		}
+		isIPv6 = true
+		srcIP, dstIP, proto, transport = ipPacket.NWSrc, ipPacket.NWDst, ipPacket.NextHeader, ipPacket.Data
+		data, err := ipPacket.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("error when marshalling IPv6 packet: %v", err)
+		}
+		origData = truncateOrigData(data, 40)
+	default:
+		return fmt.Errorf("unsupported Ethertype for reject response: %d", ethPacket.Ethertype)
+	}
+	srcMAC, dstMAC := ethPacket.HWSrc, ethPacket.HWDst
+	outPort := int32(inPort)
+	if ctOrigDstIP != nil && !ctOrigDstIP.Equal(dstIP) {
+		// The response is sent from the Service Endpoint, the conntrack NAT
+		// rewrites its source to the Service address.
+		klog.V(2).Infof("Rejected packet was sent to Service %s, the response goes through conntrack", ctOrigDstIP)
+		outPort, tunnelDst = -1, nil
+	}
+
+	if proto == protocol.Type_TCP {
+		tcpPacket, ok := transport.(*protocol.TCP)
+		if !ok {
+			return errors.New("invalid TCP packet")
+		}
+		// Never respond to a RST packet.
+		if tcpPacket.Code&tcpFlagRST != 0 {
+			return nil
+		}
+		seqNum, ackNum, flags := getTCPResetNums(tcpPacket)
+		klog.V(2).Infof("Sending TCP RST to %s:%d for rejected packet", srcIP, tcpPacket.PortSrc)
+		return c.ofClient.SendTCPPacketOut(dstMAC, srcMAC, dstIP, srcIP, outPort, tunnelDst, tcpPacket.PortDst, tcpPacket.PortSrc, seqNum, ackNum, flags)
+	}
+
+	icmpType, icmpCode := icmpDstUnreachType, icmpPortUnreachCode
+	if isIPv6 {
+		icmpType, icmpCode = icmpv6DstUnreachType, icmpv6PortUnreachCode
+	}
+	// ICMP error messages must not be sent in response to ICMP error
+	// messages, so only echo requests are rejected with an ICMP response.
+	if proto == protocol.Type_ICMP || proto == protocol.Type_IPv6ICMP {
+		icmpPacket, ok := transport.(*protocol.ICMP)
+		if !ok {
+			return errors.New("invalid ICMP packet")
+		}
+		if icmpPacket.Type != icmpEchoRequestType && icmpPacket.Type != icmpv6EchoRequestType {
+			return nil
+		}
+	}
+	// The first 4 bytes of the ICMP data are unused for destination
+	// unreachable messages, and are followed by the original datagram.
+	icmpData := append(make([]byte, 4), origData...)
+	klog.V(2).Infof("Sending ICMP destination unreachable to %s for rejected packet", srcIP)
+	return c.ofClient.SendICMPPacketOut(dstMAC, srcMAC, dstIP, srcIP, outPort, tunnelDst, icmpType, icmpCode, icmpData)
+}
+
+// getTCPResetNums returns the sequence number, acknowledgment number and flags
+// of the RST packet sent in response to tcpPacket, as described in RFC 793.
+func getTCPResetNums(tcpPacket *protocol.TCP) (uint32, uint32, uint8) {
+	if tcpPacket.Code&tcpFlagACK != 0 {
+		return tcpPacket.AckNum, 0, tcpFlagRST
+	}
+	ackNum := tcpPacket.SeqNum + uint32(len(tcpPacket.Data))
+	if tcpPacket.Code&tcpFlagSYN != 0 {
+		ackNum++
+	}
+	return 0, ackNum, tcpFlagRST | tcpFlagACK
+}
+
+// truncateOrigData truncates the marshalled IP packet to the IP header and the
+// first bytes of its data, which are included in the ICMP error message.
+func truncateOrigData(data []byte, headerLen int) []byte {
+	if len(data) > headerLen+icmpOrigDataLen {
+		return data[:headerLen+icmpOrigDataLen]
+	}
+	return data
+}
+
+// getCTOrigDstIP returns the original destination IP of the connection of the
+// packet, which is included in the packet-in message if the packet is tracked.
+func getCTOrigDstIP(matchers *ofctrl.Matchers) net.IP {
+	match := matchers.GetMatchByName("NXM_NX_CT_NW_DST")
+	if match == nil {
+		match = matchers.GetMatchByName("NXM_NX_CT_IPV6_DST")
+	}
+	if match == nil {
+		return nil
+	}
+	ip, _ := match.GetValue().(net.IP)
+	return ip
+}
+
+// getInPortValue returns the port from which the packet was received.
+func getInPortValue(matchers *ofctrl.Matchers) (uint32, error) {
+	match := matchers.GetMatchByName("OXM_OF_IN_PORT")
+	if match == nil {
+		return 0, errors.New("in_port field not found")
+	}
+	inPort, ok := match.GetValue().(uint32)
+	if !ok {
+		return 0, errors.New("in_port value cannot be retrieved")
+	}
+	return inPort, nil
+}
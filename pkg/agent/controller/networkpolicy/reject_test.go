@@ -0,0 +1,158 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	openflowtest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+)
+
+func TestGetTCPResetNums(t *testing.T) {
+	tests := []struct {
+		name          string
+		tcpPacket     *protocol.TCP
+		expectedSeq   uint32
+		expectedAck   uint32
+		expectedFlags uint8
+	}{
+		{
+			name:          "syn",
+			tcpPacket:     &protocol.TCP{SeqNum: 100, Code: tcpFlagSYN},
+			expectedSeq:   0,
+			expectedAck:   101,
+			expectedFlags: tcpFlagRST | tcpFlagACK,
+		},
+		{
+			name:          "ack",
+			tcpPacket:     &protocol.TCP{SeqNum: 100, AckNum: 200, Code: tcpFlagACK, Data: []byte("data")},
+			expectedSeq:   200,
+			expectedAck:   0,
+			expectedFlags: tcpFlagRST,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, ack, flags := getTCPResetNums(tt.tcpPacket)
+			assert.Equal(t, tt.expectedSeq, seq)
+			assert.Equal(t, tt.expectedAck, ack)
+			assert.Equal(t, tt.expectedFlags, flags)
+		})
+	}
+}
+
+func TestSendRejectResponse(t *testing.T) {
+	srcMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+	dstMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:02")
+	srcIP := net.ParseIP("10.10.0.1").To4()
+	dstIP := net.ParseIP("10.10.0.2").To4()
+	tunnelDst := net.ParseIP("192.168.0.1")
+	newIPv4Packet := func(proto uint8, data util.Message) *protocol.Ethernet {
+		return &protocol.Ethernet{
+			HWSrc:     srcMAC,
+			HWDst:     dstMAC,
+			Ethertype: protocol.IPv4_MSG,
+			Data: &protocol.IPv4{
+				Version:  4,
+				IHL:      5,
+				TTL:      64,
+				Protocol: proto,
+				NWSrc:    srcIP,
+				NWDst:    dstIP,
+				Data:     data,
+			},
+		}
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockOFClient := openflowtest.NewMockClient(controller)
+	c := &Controller{ofClient: mockOFClient}
+
+	// A TCP RST is sent in response to a TCP SYN.
+	mockOFClient.EXPECT().SendTCPPacketOut(dstMAC, srcMAC, dstIP, srcIP, int32(3), tunnelDst, uint16(80), uint16(10000), uint32(0), uint32(101), tcpFlagRST|tcpFlagACK)
+	err := c.sendRejectResponse(newIPv4Packet(protocol.Type_TCP, &protocol.TCP{PortSrc: 10000, PortDst: 80, SeqNum: 100, Code: tcpFlagSYN}), 3, tunnelDst, nil)
+	assert.NoError(t, err)
+
+	// No response is sent for a TCP RST.
+	err = c.sendRejectResponse(newIPv4Packet(protocol.Type_TCP, &protocol.TCP{PortSrc: 10000, PortDst: 80, Code: tcpFlagRST}), 3, nil, nil)
+	assert.NoError(t, err)
+
+	// An ICMP port unreachable is sent in response to a UDP packet. Its data
+	// includes the IP header and the UDP header of the original packet.
+	mockOFClient.EXPECT().SendICMPPacketOut(dstMAC, srcMAC, dstIP, srcIP, int32(3), nil, icmpDstUnreachType, icmpPortUnreachCode, gomock.Any()).
+		Do(func(_, _ net.HardwareAddr, _, _ net.IP, _ int32, _ net.IP, _, _ uint8, data []byte) {
+			assert.Len(t, data, 4+20+icmpOrigDataLen)
+		})
+	err = c.sendRejectResponse(newIPv4Packet(protocol.Type_UDP, &protocol.UDP{PortSrc: 10000, PortDst: 53, Data: []byte("query data")}), 3, nil, nil)
+	assert.NoError(t, err)
+
+	// No response is sent for an ICMP packet which is not an echo request.
+	err = c.sendRejectResponse(newIPv4Packet(protocol.Type_ICMP, &protocol.ICMP{Type: 0}), 3, nil, nil)
+	assert.NoError(t, err)
+
+	// The response is output directly when the destination of the connection
+	// was not translated.
+	mockOFClient.EXPECT().SendTCPPacketOut(dstMAC, srcMAC, dstIP, srcIP, int32(3), nil, uint16(80), uint16(10000), uint32(0), uint32(101), tcpFlagRST|tcpFlagACK)
+	err = c.sendRejectResponse(newIPv4Packet(protocol.Type_TCP, &protocol.TCP{PortSrc: 10000, PortDst: 80, SeqNum: 100, Code: tcpFlagSYN}), 3, nil, dstIP)
+	assert.NoError(t, err)
+}
+
+func TestSendRejectResponseToService(t *testing.T) {
+	srcMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+	gatewayMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:02")
+	srcIP := net.ParseIP("10.10.0.1").To4()
+	// The Service ClusterIP was translated to the Endpoint IP by AntreaProxy.
+	clusterIP := net.ParseIP("10.96.0.10").To4()
+	endpointIP := net.ParseIP("10.10.1.2").To4()
+	newIPv4Packet := func(proto uint8, data util.Message) *protocol.Ethernet {
+		return &protocol.Ethernet{
+			HWSrc:     srcMAC,
+			HWDst:     gatewayMAC,
+			Ethertype: protocol.IPv4_MSG,
+			Data: &protocol.IPv4{
+				Version:  4,
+				IHL:      5,
+				TTL:      64,
+				Protocol: proto,
+				NWSrc:    srcIP,
+				NWDst:    endpointIP,
+				Data:     data,
+			},
+		}
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockOFClient := openflowtest.NewMockClient(controller)
+	c := &Controller{ofClient: mockOFClient}
+
+	// The responses are sent from the Endpoint through the conntrack table,
+	// which translates their source back to the ClusterIP, and never through
+	// the tunnel directly.
+	mockOFClient.EXPECT().SendTCPPacketOut(gatewayMAC, srcMAC, endpointIP, srcIP, int32(-1), nil, uint16(8080), uint16(10000), uint32(0), uint32(101), tcpFlagRST|tcpFlagACK)
+	err := c.sendRejectResponse(newIPv4Packet(protocol.Type_TCP, &protocol.TCP{PortSrc: 10000, PortDst: 8080, SeqNum: 100, Code: tcpFlagSYN}), 3, net.ParseIP("192.168.0.1"), clusterIP)
+	assert.NoError(t, err)
+
+	mockOFClient.EXPECT().SendICMPPacketOut(gatewayMAC, srcMAC, endpointIP, srcIP, int32(-1), nil, icmpDstUnreachType, icmpPortUnreachCode, gomock.Any())
+	err = c.sendRejectResponse(newIPv4Packet(protocol.Type_UDP, &protocol.UDP{PortSrc: 10000, PortDst: 53, Data: []byte("query data")}), 3, nil, clusterIP)
+	assert.NoError(t, err)
+}
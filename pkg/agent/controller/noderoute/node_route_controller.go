@@ -33,9 +33,11 @@ import (
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipsec"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 )
 
@@ -67,6 +69,12 @@ type Controller struct {
 	nodeLister       corelisters.NodeLister
 	nodeListerSynced cache.InformerSynced
 	queue            workqueue.RateLimitingInterface
+	// pskManager provides the IPsec PSK to use for each Node. It is nil if
+	// IPsec is not enabled.
+	pskManager *ipsec.PSKManager
+	// wireGuardClient configures the WireGuard peers of the Nodes. It is nil
+	// if WireGuard is not enabled.
+	wireGuardClient wireguard.Interface
 	// installedNodes records routes and flows installation states of Nodes.
 	// The key is the host name of the Node, the value is the nodeRouteInfo of the Node.
 	// A node will be in the map after its flows and routes are installed successfully.
@@ -83,7 +91,9 @@ func NewNodeRouteController(
 	routeClient route.Interface,
 	interfaceStore interfacestore.InterfaceStore,
 	networkConfig *config.NetworkConfig,
-	nodeConfig *config.NodeConfig) *Controller {
+	nodeConfig *config.NodeConfig,
+	pskManager *ipsec.PSKManager,
+	wireGuardClient wireguard.Interface) *Controller {
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	controller := &Controller{
 		kubeClient:       kubeClient,
@@ -97,6 +107,8 @@ func NewNodeRouteController(
 		nodeLister:       nodeInformer.Lister(),
 		nodeListerSynced: nodeInformer.Informer().HasSynced,
 		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "noderoute"),
+		pskManager:       pskManager,
+		wireGuardClient:  wireGuardClient,
 		installedNodes:   cache.NewIndexer(nodeRouteInfoKeyFunc, cache.Indexers{nodeRouteInfoPodCIDRIndexName: nodeRouteInfoPodCIDRIndexFunc})}
 	nodeInformer.Informer().AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
@@ -112,6 +124,10 @@ func NewNodeRouteController(
 		},
 		nodeResyncPeriod,
 	)
+	if pskManager != nil {
+		// The PSK used for the IPsec tunnels must be updated when the PSK is rotated.
+		pskManager.AddEventHandler(controller.enqueueAllNodes)
+	}
 	return controller
 }
 
@@ -133,6 +149,8 @@ type nodeRouteInfo struct {
 	podCIDRs  []*net.IPNet
 	nodeIP    net.IP
 	gatewayIP []net.IP
+	// tunnelType is the tunnel type used for encapsulating traffic to the Node.
+	tunnelType ovsconfig.TunnelType
 }
 
 // enqueueNode adds an object to the controller work queue
@@ -158,6 +176,21 @@ func (c *Controller) enqueueNode(obj interface{}) {
 	}
 }
 
+// enqueueAllNodes adds all the Nodes except this Node to the controller work
+// queue.
+func (c *Controller) enqueueAllNodes() {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Error when listing Nodes: %v", err)
+		return
+	}
+	for _, node := range nodes {
+		if node.Name != c.nodeConfig.Name {
+			c.queue.Add(node.Name)
+		}
+	}
+}
+
 // removeStaleGatewayRoutes removes all the gateway routes which no longer correspond to a Node in
 // the cluster. If the antrea agent restarts and Nodes have left the cluster, this function will
 // take care of removing routes which are no longer valid.
@@ -202,7 +235,7 @@ func (c *Controller) removeStaleTunnelPorts() error {
 	// knownInterfaces is the list of interfaces currently in the local cache.
 	knownInterfaces := c.interfaceStore.GetInterfaceKeysByType(interfacestore.TunnelInterface)
 
-	if c.networkConfig.EnableIPSecTunnel {
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		for _, node := range nodes {
 			interfaceConfig, found := c.interfaceStore.GetNodeTunnelInterface(node.Name)
 			if !found {
@@ -217,13 +250,36 @@ func (c *Controller) removeStaleTunnelPorts() error {
 			}
 
 			ifaceID := util.GenerateNodeTunnelInterfaceKey(node.Name)
-			validConfiguration := interfaceConfig.PSK == c.networkConfig.IPSecPSK &&
+			validConfiguration := interfaceConfig.PSK == c.getPSKToNode(node) &&
 				interfaceConfig.RemoteIP.Equal(peerNodeIP) &&
 				interfaceConfig.TunnelInterfaceConfig.Type == c.networkConfig.TunnelType
 			if validConfiguration {
 				desiredInterfaces[ifaceID] = true
 			}
 		}
+	} else if len(c.networkConfig.FallbackTunnelTypes) > 0 {
+		for _, node := range nodes {
+			interfaceConfig, found := c.interfaceStore.GetNodeTunnelInterface(node.Name)
+			if !found {
+				continue
+			}
+
+			peerNodeIP, err := GetNodeAddr(node)
+			if err != nil {
+				klog.Errorf("Failed to retrieve IP address of Node %s: %v", node.Name, err)
+				continue
+			}
+
+			ifaceID := util.GenerateNodeTunnelInterfaceKey(node.Name)
+			tunnelType := c.getTunnelTypeToNode(node)
+			validConfiguration := interfaceConfig.PSK == "" &&
+				interfaceConfig.RemoteIP.Equal(peerNodeIP) &&
+				interfaceConfig.TunnelInterfaceConfig.Type == tunnelType &&
+				tunnelType != c.networkConfig.TunnelType
+			if validConfiguration {
+				desiredInterfaces[ifaceID] = true
+			}
+		}
 	}
 
 	// remove all ports which are no longer needed or for which the configuration is no longer
@@ -253,6 +309,25 @@ func (c *Controller) removeStaleTunnelPorts() error {
 	return nil
 }
 
+// removeStaleWireGuardPeers removes all the WireGuard peers which no longer correspond to a Node in
+// the cluster, or whose public key has changed.
+func (c *Controller) removeStaleWireGuardPeers() error {
+	if c.wireGuardClient == nil {
+		return nil
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error when listing Nodes: %v", err)
+	}
+	publicKeys := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if publicKey := node.Annotations[wireguard.PublicKeyAnnotationKey]; publicKey != "" {
+			publicKeys[node.Name] = publicKey
+		}
+	}
+	return c.wireGuardClient.RemoveStalePeers(publicKeys)
+}
+
 func (c *Controller) reconcile() error {
 	klog.Infof("Reconciliation for %s", controllerName)
 	// reconciliation consists of removing stale routes and stale / invalid tunnel ports:
@@ -264,6 +339,9 @@ func (c *Controller) reconcile() error {
 	if err := c.removeStaleTunnelPorts(); err != nil {
 		return fmt.Errorf("error when removing stale tunnel ports: %v", err)
 	}
+	if err := c.removeStaleWireGuardPeers(); err != nil {
+		return fmt.Errorf("error when removing stale WireGuard peers: %v", err)
+	}
 	return nil
 }
 
@@ -387,26 +465,57 @@ func (c *Controller) deleteNodeRoute(nodeName string) error {
 	}
 	c.installedNodes.Delete(obj)
 
-	if c.networkConfig.EnableIPSecTunnel {
-		interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface(nodeName)
-		if !ok {
-			// Tunnel port not created for this Node.
-			return nil
+	if c.wireGuardClient != nil {
+		if err := c.wireGuardClient.DeletePeer(nodeName); err != nil {
+			return fmt.Errorf("failed to delete the WireGuard peer of Node %s: %v", nodeName, err)
 		}
-		if err := c.ovsBridgeClient.DeletePort(interfaceConfig.PortUUID); err != nil {
-			klog.Errorf("Failed to delete OVS tunnel port %s for Node %s: %v",
-				interfaceConfig.InterfaceName, nodeName, err)
-			return fmt.Errorf("failed to delete OVS tunnel port for Node %s", nodeName)
-		}
-		c.interfaceStore.DeleteInterface(interfaceConfig)
 	}
+
+	// A separate tunnel port is created for the Node when IPSec tunnel is enabled, or when
+	// the tunnel type negotiated with the Node is not the default tunnel type.
+	return c.deleteNodeTunnelPort(nodeName)
+}
+
+func (c *Controller) deleteNodeTunnelPort(nodeName string) error {
+	interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface(nodeName)
+	if !ok {
+		// Tunnel port not created for this Node.
+		return nil
+	}
+	if err := c.ovsBridgeClient.DeletePort(interfaceConfig.PortUUID); err != nil {
+		klog.Errorf("Failed to delete OVS tunnel port %s for Node %s: %v",
+			interfaceConfig.InterfaceName, nodeName, err)
+		return fmt.Errorf("failed to delete OVS tunnel port for Node %s", nodeName)
+	}
+	c.interfaceStore.DeleteInterface(interfaceConfig)
 	return nil
 }
 
 func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
-	if _, installed, _ := c.installedNodes.GetByKey(nodeName); installed {
-		// Route is already added for this Node.
-		return nil
+	tunnelType := c.getTunnelTypeToNode(node)
+	if obj, installed, _ := c.installedNodes.GetByKey(nodeName); installed {
+		if obj.(*nodeRouteInfo).tunnelType == tunnelType {
+			// Route is already added for this Node.
+			if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
+				// The PSK to use for the Node may have changed because of a PSK
+				// rotation.
+				return c.updateIPSecTunnelPSK(nodeName, c.getPSKToNode(node))
+			}
+			if c.wireGuardClient != nil {
+				// The public key of the Node changes when its WireGuard interface is
+				// re-created, e.g. after a reboot.
+				nodeRouteInfo := obj.(*nodeRouteInfo)
+				return c.updateWireGuardPeer(nodeName, node, nodeRouteInfo.nodeIP, nodeRouteInfo.podCIDRs)
+			}
+			return nil
+		}
+		// The tunnel type negotiated with the Node has changed, e.g. because the Node
+		// updated its supported tunnel types. Remove the current routes, flows and tunnel
+		// port, and add them again with the new tunnel type.
+		klog.Infof("Tunnel type to Node %s changed from %s to %s", nodeName, obj.(*nodeRouteInfo).tunnelType, tunnelType)
+		if err := c.deleteNodeRoute(nodeName); err != nil {
+			return err
+		}
 	}
 
 	podCIDRStrs := getPodCIDRsOnNode(node)
@@ -460,24 +569,48 @@ func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
 		return nil
 	}
 
-	ipsecTunOFPort := int32(0)
-	if c.networkConfig.EnableIPSecTunnel {
+	if c.wireGuardClient != nil && node.Annotations[wireguard.PublicKeyAnnotationKey] == "" {
+		// The Node has not advertised its public key yet, the routes and flows
+		// will be added when its annotation is updated.
+		klog.Infof("WireGuard public key of Node %s is not available yet", nodeName)
+		return nil
+	}
+
+	tunOFPort := int32(0)
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		// Create a separate tunnel port for the Node, as OVS IPSec monitor needs to
 		// read PSK and remote IP from the Node's tunnel interface to create IPSec
 		// security policies.
-		if ipsecTunOFPort, err = c.createIPSecTunnelPort(nodeName, peerNodeIP); err != nil {
+		if tunOFPort, err = c.createNodeTunnelPort(nodeName, peerNodeIP, c.networkConfig.TunnelType, c.getPSKToNode(node)); err != nil {
+			return err
+		}
+	} else if tunnelType != c.networkConfig.TunnelType && c.networkConfig.TrafficEncapMode.NeedsEncapToPeer(peerNodeIP, c.nodeConfig.NodeIPAddr) {
+		// The default tunnel port is flow based and can only use the default tunnel type,
+		// so create a separate tunnel port with the negotiated tunnel type for the Node.
+		if tunOFPort, err = c.createNodeTunnelPort(nodeName, peerNodeIP, tunnelType, ""); err != nil {
 			return err
 		}
 	}
 
+	tunnelPeerIP := peerNodeIP
+	if c.wireGuardClient != nil {
+		// The traffic to the Node is forwarded to the host gateway and encrypted by
+		// WireGuard instead of being tunneled by OVS.
+		tunnelPeerIP = nil
+	}
 	err = c.ofClient.InstallNodeFlows(
 		nodeName,
 		peerConfig,
-		peerNodeIP,
-		uint32(ipsecTunOFPort))
+		tunnelPeerIP,
+		uint32(tunOFPort))
 	if err != nil {
 		return fmt.Errorf("failed to install flows to Node %s: %v", nodeName, err)
 	}
+	if c.wireGuardClient != nil {
+		if err := c.updateWireGuardPeer(nodeName, node, peerNodeIP, podCIDRs); err != nil {
+			return err
+		}
+	}
 
 	var peerGatewayIPs []net.IP
 	for peerPodCIDR, peerGatewayIP := range peerConfig {
@@ -487,14 +620,29 @@ func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
 		peerGatewayIPs = append(peerGatewayIPs, peerGatewayIP)
 	}
 	c.installedNodes.Add(&nodeRouteInfo{
-		nodeName:  nodeName,
-		podCIDRs:  podCIDRs,
-		nodeIP:    peerNodeIP,
-		gatewayIP: peerGatewayIPs,
+		nodeName:   nodeName,
+		podCIDRs:   podCIDRs,
+		nodeIP:     peerNodeIP,
+		gatewayIP:  peerGatewayIPs,
+		tunnelType: tunnelType,
 	})
 	return err
 }
 
+// updateWireGuardPeer configures the WireGuard peer of the Node with the public key advertised by
+// the Node.
+func (c *Controller) updateWireGuardPeer(nodeName string, node *corev1.Node, peerNodeIP net.IP, podCIDRs []*net.IPNet) error {
+	publicKey := node.Annotations[wireguard.PublicKeyAnnotationKey]
+	if publicKey == "" {
+		klog.Warningf("WireGuard public key of Node %s has been removed, keeping the current WireGuard peer", nodeName)
+		return nil
+	}
+	if err := c.wireGuardClient.UpdatePeer(nodeName, publicKey, peerNodeIP, podCIDRs); err != nil {
+		return fmt.Errorf("failed to update the WireGuard peer of Node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
 func getPodCIDRsOnNode(node *corev1.Node) []string {
 	if node.Spec.PodCIDRs != nil {
 		return node.Spec.PodCIDRs
@@ -508,14 +656,28 @@ func getPodCIDRsOnNode(node *corev1.Node) []string {
 	return []string{node.Spec.PodCIDR}
 }
 
-// createIPSecTunnelPort creates an IPSec tunnel port for the remote Node if the
-// tunnel does not exist, and returns the ofport number.
-func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int32, error) {
+// createNodeTunnelPort creates a tunnel port of the provided tunnel type for
+// the remote Node if the tunnel does not exist, and returns the ofport number.
+// If psk is not empty, an IPSec tunnel port is created.
+func (c *Controller) createNodeTunnelPort(nodeName string, nodeIP net.IP, tunnelType ovsconfig.TunnelType, psk string) (int32, error) {
 	interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface(nodeName)
+	if ok && interfaceConfig.TunnelInterfaceConfig.Type != tunnelType {
+		// The tunnel type negotiated with the Node has changed, delete the existing
+		// tunnel port so that it can be re-created with the new tunnel type.
+		if err := c.deleteNodeTunnelPort(nodeName); err != nil {
+			return 0, err
+		}
+		ok = false
+	}
 	if ok {
-		// TODO: check if Node IP, PSK, or tunnel type changes. This can
-		// happen if removeStaleTunnelPorts fails to remove a "stale"
-		// tunnel port for which the configuration has changed.
+		// TODO: check if Node IP changes. This can happen if
+		// removeStaleTunnelPorts fails to remove a "stale" tunnel port
+		// for which the configuration has changed.
+		if psk != "" {
+			if err := c.updateIPSecTunnelPSK(nodeName, psk); err != nil {
+				return 0, err
+			}
+		}
 		if interfaceConfig.OFPort != 0 {
 			return interfaceConfig.OFPort, nil
 		}
@@ -524,25 +686,33 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 		ovsExternalIDs := map[string]interface{}{ovsExternalIDNodeName: nodeName}
 		portUUID, err := c.ovsBridgeClient.CreateTunnelPortExt(
 			portName,
-			c.networkConfig.TunnelType,
+			tunnelType,
 			0, // ofPortRequest - let OVS allocate OFPort number.
 			false,
 			"",
 			nodeIP.String(),
-			c.networkConfig.IPSecPSK,
+			psk,
 			ovsExternalIDs)
 		if err != nil {
-			return 0, fmt.Errorf("failed to create IPSec tunnel port for Node %s", nodeName)
+			return 0, fmt.Errorf("failed to create %s tunnel port for Node %s", tunnelType, nodeName)
 		}
-		klog.Infof("Created IPSec tunnel port %s for Node %s", portName, nodeName)
+		klog.Infof("Created %s tunnel port %s for Node %s", tunnelType, portName, nodeName)
 
 		ovsPortConfig := &interfacestore.OVSPortConfig{PortUUID: portUUID}
-		interfaceConfig = interfacestore.NewIPSecTunnelInterface(
-			portName,
-			c.networkConfig.TunnelType,
-			nodeName,
-			nodeIP,
-			c.networkConfig.IPSecPSK)
+		if psk != "" {
+			interfaceConfig = interfacestore.NewIPSecTunnelInterface(
+				portName,
+				tunnelType,
+				nodeName,
+				nodeIP,
+				psk)
+		} else {
+			interfaceConfig = interfacestore.NewNodeTunnelInterface(
+				portName,
+				tunnelType,
+				nodeName,
+				nodeIP)
+		}
 		interfaceConfig.OVSPortConfig = ovsPortConfig
 		c.interfaceStore.AddInterface(interfaceConfig)
 	}
@@ -552,12 +722,52 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 	if err != nil {
 		// Could be a temporary OVSDB connection failure or timeout.
 		// Let NodeRouteController retry at errors.
-		return 0, fmt.Errorf("failed to get of_port of IPSec tunnel port for Node %s", nodeName)
+		return 0, fmt.Errorf("failed to get of_port of tunnel port for Node %s", nodeName)
 	}
 	interfaceConfig.OFPort = ofPort
 	return ofPort, nil
 }
 
+// updateIPSecTunnelPSK updates the PSK of the IPsec tunnel port for the remote
+// Node if it is different from the provided PSK. The tunnel port is updated in
+// place, so its OFPort and the flows using it are not affected. The
+// InterfaceConfig of the port is replaced in the interface store rather than
+// mutated, as it can be read concurrently by other components.
+func (c *Controller) updateIPSecTunnelPSK(nodeName string, psk string) error {
+	interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface(nodeName)
+	if !ok || interfaceConfig.PSK == psk {
+		return nil
+	}
+	options, err := c.ovsBridgeClient.GetInterfaceOptions(interfaceConfig.InterfaceName)
+	if err != nil {
+		return fmt.Errorf("failed to get options of tunnel port for Node %s: %v", nodeName, err)
+	}
+	updatedOptions := make(map[string]interface{}, len(options))
+	for k, v := range options {
+		updatedOptions[k] = v
+	}
+	updatedOptions["psk"] = psk
+	if err := c.ovsBridgeClient.SetInterfaceOptions(interfaceConfig.InterfaceName, updatedOptions); err != nil {
+		return fmt.Errorf("failed to update PSK of tunnel port for Node %s: %v", nodeName, err)
+	}
+	updatedConfig := *interfaceConfig
+	tunnelConfig := *interfaceConfig.TunnelInterfaceConfig
+	tunnelConfig.PSK = psk
+	updatedConfig.TunnelInterfaceConfig = &tunnelConfig
+	c.interfaceStore.AddInterface(&updatedConfig)
+	klog.Infof("Updated IPsec PSK of tunnel port %s for Node %s", interfaceConfig.InterfaceName, nodeName)
+	return nil
+}
+
+// getPSKToNode returns the IPsec PSK to use for the tunnel to the provided
+// Node.
+func (c *Controller) getPSKToNode(node *corev1.Node) string {
+	if c.pskManager == nil {
+		return c.networkConfig.IPSecPSK
+	}
+	return c.pskManager.GetPSKForNode(node)
+}
+
 // ParseTunnelInterfaceConfig initializes and returns an InterfaceConfig struct
 // for a tunnel interface. It reads tunnel type, remote IP, IPSec PSK from the
 // OVS interface options, and NodeName from the OVS port external_ids.
@@ -584,6 +794,12 @@ func ParseTunnelInterfaceConfig(
 			nodeName,
 			remoteIP,
 			psk)
+	} else if nodeName != "" {
+		interfaceConfig = interfacestore.NewNodeTunnelInterface(
+			portData.Name,
+			ovsconfig.TunnelType(portData.IFType),
+			nodeName,
+			remoteIP)
 	} else {
 		interfaceConfig = interfacestore.NewTunnelInterface(portData.Name, ovsconfig.TunnelType(portData.IFType), localIP, csum)
 	}
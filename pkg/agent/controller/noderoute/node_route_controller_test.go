@@ -63,7 +63,7 @@ func newController(t *testing.T) (*fakeController, func()) {
 	c := NewNodeRouteController(clientset, informerFactory, ofClient, ovsClient, routeClient, interfaceStore, &config.NetworkConfig{}, &config.NodeConfig{GatewayConfig: &config.GatewayConfig{
 		IPv4: nil,
 		MAC:  gatewayMAC,
-	}})
+	}}, nil, nil)
 	return &fakeController{
 		Controller:      c,
 		clientset:       clientset,
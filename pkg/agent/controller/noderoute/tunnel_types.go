@@ -0,0 +1,95 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package noderoute
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+// TunnelTypesAnnotationKey is the key of the Node annotation which advertises
+// the tunnel types supported by the antrea-agent running on the Node, as a
+// comma-separated list ordered by preference.
+const TunnelTypesAnnotationKey = "node.antrea.io/tunnel-types"
+
+// FormatTunnelTypes returns the value of the tunnel types annotation for the
+// provided tunnel types.
+func FormatTunnelTypes(tunnelTypes []ovsconfig.TunnelType) string {
+	strs := make([]string, 0, len(tunnelTypes))
+	for _, tunnelType := range tunnelTypes {
+		strs = append(strs, string(tunnelType))
+	}
+	return strings.Join(strs, ",")
+}
+
+// getNodeTunnelTypes returns the tunnel types advertised by the Node. If the
+// Node has no tunnel types annotation (e.g. it runs an older version of
+// antrea-agent), it is assumed to support defaultTunnelType only.
+func getNodeTunnelTypes(node *corev1.Node, defaultTunnelType ovsconfig.TunnelType) []ovsconfig.TunnelType {
+	value, ok := node.Annotations[TunnelTypesAnnotationKey]
+	if !ok || value == "" {
+		return []ovsconfig.TunnelType{defaultTunnelType}
+	}
+	var tunnelTypes []ovsconfig.TunnelType
+	for _, str := range strings.Split(value, ",") {
+		tunnelTypes = append(tunnelTypes, ovsconfig.TunnelType(strings.TrimSpace(str)))
+	}
+	return tunnelTypes
+}
+
+// negotiateTunnelType selects the tunnel type to use between two Nodes. To
+// make sure both Nodes make the same decision, the preference order of the
+// Node with the smaller name is used, and the first tunnel type supported by
+// both Nodes is selected. defaultTunnelType is returned if the Nodes have no
+// tunnel type in common.
+func negotiateTunnelType(
+	localNodeName string,
+	localTunnelTypes []ovsconfig.TunnelType,
+	peerNodeName string,
+	peerTunnelTypes []ovsconfig.TunnelType,
+	defaultTunnelType ovsconfig.TunnelType) ovsconfig.TunnelType {
+	preferred, other := localTunnelTypes, peerTunnelTypes
+	if peerNodeName < localNodeName {
+		preferred, other = peerTunnelTypes, localTunnelTypes
+	}
+	for _, tunnelType := range preferred {
+		for _, t := range other {
+			if t == tunnelType {
+				return tunnelType
+			}
+		}
+	}
+	klog.V(2).Infof("No common tunnel type between Node %s (%s) and Node %s (%s), using %s",
+		localNodeName, FormatTunnelTypes(localTunnelTypes), peerNodeName, FormatTunnelTypes(peerTunnelTypes), defaultTunnelType)
+	return defaultTunnelType
+}
+
+// getTunnelTypeToNode returns the tunnel type to use for encapsulating traffic
+// to the provided Node.
+func (c *Controller) getTunnelTypeToNode(node *corev1.Node) ovsconfig.TunnelType {
+	if len(c.networkConfig.FallbackTunnelTypes) == 0 {
+		return c.networkConfig.TunnelType
+	}
+	return negotiateTunnelType(
+		c.nodeConfig.Name,
+		c.networkConfig.SupportedTunnelTypes(),
+		node.Name,
+		getNodeTunnelTypes(node, c.networkConfig.TunnelType),
+		c.networkConfig.TunnelType)
+}
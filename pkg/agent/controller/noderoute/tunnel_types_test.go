@@ -0,0 +1,101 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package noderoute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+func TestGetNodeTunnelTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    []ovsconfig.TunnelType
+	}{
+		{
+			name:     "no annotation",
+			expected: []ovsconfig.TunnelType{ovsconfig.GeneveTunnel},
+		},
+		{
+			name:        "empty annotation",
+			annotations: map[string]string{TunnelTypesAnnotationKey: ""},
+			expected:    []ovsconfig.TunnelType{ovsconfig.GeneveTunnel},
+		},
+		{
+			name:        "multiple tunnel types",
+			annotations: map[string]string{TunnelTypesAnnotationKey: "gre, stt,geneve"},
+			expected:    []ovsconfig.TunnelType{ovsconfig.GRETunnel, ovsconfig.STTTunnel, ovsconfig.GeneveTunnel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: tt.annotations}}
+			assert.Equal(t, tt.expected, getNodeTunnelTypes(node, ovsconfig.GeneveTunnel))
+		})
+	}
+}
+
+func TestNegotiateTunnelType(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodeA    []ovsconfig.TunnelType
+		nodeB    []ovsconfig.TunnelType
+		expected ovsconfig.TunnelType
+	}{
+		{
+			name:     "default tunnel type only",
+			nodeA:    []ovsconfig.TunnelType{ovsconfig.GeneveTunnel},
+			nodeB:    []ovsconfig.TunnelType{ovsconfig.GeneveTunnel},
+			expected: ovsconfig.GeneveTunnel,
+		},
+		{
+			name:     "fallback tunnel type on both Nodes",
+			nodeA:    []ovsconfig.TunnelType{ovsconfig.GRETunnel, ovsconfig.GeneveTunnel},
+			nodeB:    []ovsconfig.TunnelType{ovsconfig.GRETunnel, ovsconfig.GeneveTunnel},
+			expected: ovsconfig.GRETunnel,
+		},
+		{
+			name:     "fallback tunnel type on one Node",
+			nodeA:    []ovsconfig.TunnelType{ovsconfig.GeneveTunnel},
+			nodeB:    []ovsconfig.TunnelType{ovsconfig.GRETunnel, ovsconfig.GeneveTunnel},
+			expected: ovsconfig.GeneveTunnel,
+		},
+		{
+			name:     "different preference orders",
+			nodeA:    []ovsconfig.TunnelType{ovsconfig.STTTunnel, ovsconfig.GRETunnel, ovsconfig.GeneveTunnel},
+			nodeB:    []ovsconfig.TunnelType{ovsconfig.GRETunnel, ovsconfig.STTTunnel, ovsconfig.GeneveTunnel},
+			expected: ovsconfig.STTTunnel,
+		},
+		{
+			name:     "no common tunnel type",
+			nodeA:    []ovsconfig.TunnelType{ovsconfig.GRETunnel},
+			nodeB:    []ovsconfig.TunnelType{ovsconfig.STTTunnel},
+			expected: ovsconfig.GeneveTunnel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Both Nodes must select the same tunnel type.
+			assert.Equal(t, tt.expected, negotiateTunnelType("nodeA", tt.nodeA, "nodeB", tt.nodeB, ovsconfig.GeneveTunnel))
+			assert.Equal(t, tt.expected, negotiateTunnelType("nodeB", tt.nodeB, "nodeA", tt.nodeA, ovsconfig.GeneveTunnel))
+		})
+	}
+}
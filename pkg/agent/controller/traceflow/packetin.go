@@ -19,16 +19,19 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet/ofctrl"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
@@ -114,6 +117,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*opsv1alpha1.Tracefl
 
 	// Collect Service DNAT.
 	ctNwDst := ""
+	ipSrc := ""
 	ipDst := ""
 	switch pktIn.Data.Ethertype {
 	case protocol.IPv4_MSG:
@@ -125,6 +129,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*opsv1alpha1.Tracefl
 		if err != nil {
 			return nil, nil, err
 		}
+		ipSrc = ipPacket.NWSrc.String()
 		ipDst = ipPacket.NWDst.String()
 	case protocol.IPv6_MSG:
 		ipPacket, ok := pktIn.Data.Data.(*protocol.IPv6)
@@ -135,6 +140,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*opsv1alpha1.Tracefl
 		if err != nil {
 			return nil, nil, err
 		}
+		ipSrc = ipPacket.NWSrc.String()
 		ipDst = ipPacket.NWDst.String()
 	default:
 		return nil, nil, fmt.Errorf("unsupported traceflow packet ether type %d", pktIn.Data.Ethertype)
@@ -213,7 +219,13 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*opsv1alpha1.Tracefl
 				return nil, nil, err
 			}
 		}
-		if (c.networkConfig.TrafficEncapMode.SupportsEncap() && outputPort == config.DefaultTunOFPort) || outputPort == config.HostGatewayOFPort {
+		if outputPort == config.HostGatewayOFPort {
+			// The fate of a packet sent to the Gateway port depends on its destination.
+			ob.Action = c.getGatewayOutputAction(net.ParseIP(ipDst))
+			if ob.Action == opsv1alpha1.Forwarded {
+				ob.TunnelDstIP = tunnelDstIP
+			}
+		} else if c.networkConfig.TrafficEncapMode.SupportsEncap() && c.isTunnelPort(outputPort) {
 			// Output port is Tunnel/Gateway port, packet is forwarded.
 			// tunnelDstIP is valid IP in encapMode, and empty string in other modes.
 			ob.TunnelDstIP = tunnelDstIP
@@ -225,12 +237,149 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*opsv1alpha1.Tracefl
 		ob.ComponentInfo = openflow.GetFlowTableName(binding.TableIDType(tableID))
 		ob.Component = opsv1alpha1.Forwarding
 		obs = append(obs, *ob)
+		// Many unanswered ICMP echo requests are caused by the replies being dropped, so check the return path of
+		// the echo reply when the request is delivered.
+		if ob.Action == opsv1alpha1.Delivered && isICMPEchoRequest(&pktIn.Data) {
+			if replyOb := c.getEchoReplyObservation(net.ParseIP(ipDst), net.ParseIP(ipSrc)); replyOb != nil {
+				obs = append(obs, *replyOb)
+			}
+		}
 	}
 
 	nodeResult := opsv1alpha1.NodeResult{Node: c.nodeConfig.Name, Timestamp: time.Now().Unix(), Observations: obs}
 	return tf, &nodeResult, nil
 }
 
+// getGatewayOutputAction returns the action of a packet sent to the Gateway port, based on its destination IP. The
+// packet is delivered if it is sent to the local Node, e.g. to a hostNetwork Pod. It is forwarded if it is sent to a
+// Pod of another Node, which is routed through the Gateway in noEncap and hybrid modes, or to a Service ClusterIP
+// handled by kube-proxy, which sends the packet back to OVS once it is translated. Otherwise, the packet leaves the
+// overlay network, e.g. to a destination outside the cluster or to the host network of another Node. In
+// networkPolicyOnly mode, all the traffic is routed by the host network, so the packet is only forwarded.
+func (c *Controller) getGatewayOutputAction(dstIP net.IP) opsv1alpha1.TraceflowAction {
+	if c.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() || dstIP == nil {
+		return opsv1alpha1.Forwarded
+	}
+	if c.isLocalNodeIP(dstIP) {
+		return opsv1alpha1.Delivered
+	}
+	if c.serviceCIDR != nil && c.serviceCIDR.Contains(dstIP) {
+		return opsv1alpha1.Forwarded
+	}
+	if c.isClusterPodIP(dstIP) {
+		return opsv1alpha1.Forwarded
+	}
+	return opsv1alpha1.ForwardedOutOfOverlay
+}
+
+// isLocalNodeIP returns true if ip is the IP of the local Node or of its Gateway interface.
+func (c *Controller) isLocalNodeIP(ip net.IP) bool {
+	if c.nodeConfig.NodeIPAddr != nil && c.nodeConfig.NodeIPAddr.IP.Equal(ip) {
+		return true
+	}
+	gatewayConfig := c.nodeConfig.GatewayConfig
+	return gatewayConfig != nil && (gatewayConfig.IPv4.Equal(ip) || gatewayConfig.IPv6.Equal(ip))
+}
+
+// isClusterPodIP returns true if ip belongs to the Pod CIDR of a Node of the cluster.
+func (c *Controller) isClusterPodIP(ip net.IP) bool {
+	for _, podCIDR := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
+		if podCIDR != nil && podCIDR.Contains(ip) {
+			return true
+		}
+	}
+	if c.nodeLister == nil {
+		return false
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list Nodes: %v", err)
+		return false
+	}
+	for _, node := range nodes {
+		podCIDRs := node.Spec.PodCIDRs
+		if len(podCIDRs) == 0 && node.Spec.PodCIDR != "" {
+			podCIDRs = []string{node.Spec.PodCIDR}
+		}
+		for _, podCIDR := range podCIDRs {
+			if _, ipNet, err := net.ParseCIDR(podCIDR); err == nil && ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTunnelPort returns true if ofPort is the default tunnel port, or the tunnel
+// port created for a remote Node.
+func (c *Controller) isTunnelPort(ofPort uint32) bool {
+	if ofPort == config.DefaultTunOFPort {
+		return true
+	}
+	for _, intf := range c.interfaceStore.GetInterfacesByType(interfacestore.TunnelInterface) {
+		if uint32(intf.OFPort) == ofPort {
+			return true
+		}
+	}
+	return false
+}
+
+// isICMPEchoRequest returns whether the packet is an ICMP or ICMPv6 echo request.
+func isICMPEchoRequest(pkt *protocol.Ethernet) bool {
+	switch ipPacket := pkt.Data.(type) {
+	case *protocol.IPv4:
+		icmpPacket, ok := ipPacket.Data.(*protocol.ICMP)
+		return ok && icmpPacket.Type == uint8(icmpEchoRequestType)
+	case *protocol.IPv6:
+		icmpPacket, ok := ipPacket.Data.(*protocol.ICMP)
+		return ok && icmpPacket.Type == uint8(icmpv6EchoRequestType)
+	}
+	return false
+}
+
+// getEchoReplyObservation traces in OVS the echo reply sent by the local Pod with podIP to peerIP, in the connection
+// committed by the echo request, and returns an Observation recording whether the reply is forwarded or dropped, e.g.
+// by SpoofGuard or by the NetworkPolicies applied to the return path. It returns nil if the reply cannot be traced.
+func (c *Controller) getEchoReplyObservation(podIP, peerIP net.IP) *opsv1alpha1.Observation {
+	podInterface, ok := c.interfaceStore.GetInterfaceByIP(podIP.String())
+	if !ok || podInterface.Type != interfacestore.ContainerInterface {
+		return nil
+	}
+	var icmpFlow string
+	if podIP.To4() != nil {
+		icmpFlow = fmt.Sprintf("icmp,icmp_type=%d,icmp_code=0,nw_ttl=64,nw_src=%s,nw_dst=%s", icmpEchoReplyType, podIP, peerIP)
+	} else {
+		icmpFlow = fmt.Sprintf("icmp6,icmpv6_type=%d,icmpv6_code=0,nw_ttl=64,ipv6_src=%s,ipv6_dst=%s", icmpv6EchoReplyType, podIP, peerIP)
+	}
+	// The Pod sends the reply to the MAC address of the gateway, which is its default gateway.
+	flow := fmt.Sprintf("in_port=%s,dl_src=%s,dl_dst=%s,%s", podInterface.InterfaceName, podInterface.MAC, c.nodeConfig.GatewayConfig.MAC, icmpFlow)
+	out, execErr := c.ovsCtlClient.RunAppctlCmd("ofproto/trace", true, flow, "--ct-next", "trk,est")
+	if execErr != nil {
+		klog.Errorf("Failed to trace the echo reply from %s to %s: %v, output: %s", podIP, peerIP, execErr, execErr.GetErrorOutput())
+		return nil
+	}
+	ob := &opsv1alpha1.Observation{
+		Component: opsv1alpha1.ReplyPath,
+		Action:    opsv1alpha1.Forwarded,
+	}
+	if getDatapathActions(string(out)) == "drop" {
+		ob.Action = opsv1alpha1.Dropped
+		ob.Reason = dropReasonEchoReply
+	}
+	return ob
+}
+
+// getDatapathActions returns the datapath actions in the output of "ovs-appctl ofproto/trace", e.g. "drop".
+func getDatapathActions(traceOutput string) string {
+	const prefix = "Datapath actions:"
+	for _, line := range strings.Split(traceOutput, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+	}
+	return ""
+}
+
 func getMatchRegField(matchers *ofctrl.Matchers, regNum uint32) *ofctrl.MatchField {
 	return matchers.GetMatchByName(fmt.Sprintf("NXM_NX_REG%d", regNum))
 }
@@ -278,25 +427,45 @@ func getCTDstValue(matchers *ofctrl.Matchers, isIPv6 bool) (string, error) {
 	return regValue.String(), nil
 }
 
+const (
+	// Reasons set to the Observations of packets dropped by NetworkPolicies.
+	dropReasonPolicyRule = "Dropped by NetworkPolicy rule"
+	dropReasonIsolation  = "Dropped by default isolation of NetworkPolicy"
+	// Reason set to the Observations of echo replies dropped on the return path.
+	dropReasonEchoReply = "Echo reply dropped on the return path"
+)
+
 func getNetworkPolicyObservation(tableID uint8, ingress bool) *opsv1alpha1.Observation {
 	ob := new(opsv1alpha1.Observation)
 	ob.Component = opsv1alpha1.NetworkPolicy
 	if ingress {
 		switch tableID {
-		case uint8(openflow.IngressMetricTable), uint8(openflow.IngressDefaultTable):
-			// Packet dropped by ANP/default drop rule
+		case uint8(openflow.IngressMetricTable):
+			// Packet dropped by ANP drop rule
+			ob.ComponentInfo = openflow.GetFlowTableName(binding.TableIDType(tableID))
+			ob.Action = opsv1alpha1.Dropped
+			ob.Reason = dropReasonPolicyRule
+		case uint8(openflow.IngressDefaultTable):
+			// Packet dropped by default drop rule
 			ob.ComponentInfo = openflow.GetFlowTableName(binding.TableIDType(tableID))
 			ob.Action = opsv1alpha1.Dropped
+			ob.Reason = dropReasonIsolation
 		default:
 			ob.ComponentInfo = openflow.GetFlowTableName(openflow.IngressRuleTable)
 			ob.Action = opsv1alpha1.Forwarded
 		}
 	} else {
 		switch tableID {
-		case uint8(openflow.EgressMetricTable), uint8(openflow.EgressDefaultTable):
-			// Packet dropped by ANP/default drop rule
+		case uint8(openflow.EgressMetricTable):
+			// Packet dropped by ANP drop rule
+			ob.ComponentInfo = openflow.GetFlowTableName(binding.TableIDType(tableID))
+			ob.Action = opsv1alpha1.Dropped
+			ob.Reason = dropReasonPolicyRule
+		case uint8(openflow.EgressDefaultTable):
+			// Packet dropped by default drop rule
 			ob.ComponentInfo = openflow.GetFlowTableName(binding.TableIDType(tableID))
 			ob.Action = opsv1alpha1.Dropped
+			ob.Reason = dropReasonIsolation
 		default:
 			ob.ComponentInfo = openflow.GetFlowTableName(openflow.EgressRuleTable)
 			ob.Action = opsv1alpha1.Forwarded
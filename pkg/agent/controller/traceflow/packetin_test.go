@@ -15,11 +15,28 @@
 package traceflow
 
 import (
+	"net"
 	"reflect"
 	"testing"
 
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
 )
 
 func Test_getNetworkPolicyObservation(t *testing.T) {
@@ -42,6 +59,7 @@ func Test_getNetworkPolicyObservation(t *testing.T) {
 				Component:     opsv1alpha1.NetworkPolicy,
 				ComponentInfo: "IngressMetric",
 				Action:        opsv1alpha1.Dropped,
+				Reason:        dropReasonPolicyRule,
 			},
 		},
 		{
@@ -66,6 +84,7 @@ func Test_getNetworkPolicyObservation(t *testing.T) {
 				Component:     opsv1alpha1.NetworkPolicy,
 				ComponentInfo: "EgressDefaultRule",
 				Action:        opsv1alpha1.Dropped,
+				Reason:        dropReasonIsolation,
 			},
 		},
 		{
@@ -89,3 +108,216 @@ func Test_getNetworkPolicyObservation(t *testing.T) {
 		})
 	}
 }
+
+func Test_getEchoReplyObservation(t *testing.T) {
+	podMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+	gatewayMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	tests := []struct {
+		name        string
+		podIP       string
+		peerIP      string
+		expectedCmd string
+		traceOutput string
+		want        *opsv1alpha1.Observation
+	}{
+		{
+			name:        "IPv4 reply forwarded",
+			podIP:       "10.10.0.2",
+			peerIP:      "10.10.1.2",
+			expectedCmd: "in_port=pod1-1,dl_src=aa:bb:cc:dd:ee:01,dl_dst=aa:bb:cc:dd:ee:ff,icmp,icmp_type=0,icmp_code=0,nw_ttl=64,nw_src=10.10.0.2,nw_dst=10.10.1.2",
+			traceOutput: "Final flow: unchanged\nMegaflow: recirc_id=0x1,ct_state=+est+trk,icmp\nDatapath actions: set(tunnel(dst=192.168.1.2)),1\n",
+			want: &opsv1alpha1.Observation{
+				Component: opsv1alpha1.ReplyPath,
+				Action:    opsv1alpha1.Forwarded,
+			},
+		},
+		{
+			name:        "IPv6 reply dropped",
+			podIP:       "fd00:10:10::2",
+			peerIP:      "fd00:10:10::3",
+			expectedCmd: "in_port=pod1-1,dl_src=aa:bb:cc:dd:ee:01,dl_dst=aa:bb:cc:dd:ee:ff,icmp6,icmpv6_type=129,icmpv6_code=0,nw_ttl=64,ipv6_src=fd00:10:10::2,ipv6_dst=fd00:10:10::3",
+			traceOutput: "Final flow: unchanged\nMegaflow: recirc_id=0x1,ct_state=+est+trk,icmp6\nDatapath actions: drop\n",
+			want: &opsv1alpha1.Observation{
+				Component: opsv1alpha1.ReplyPath,
+				Action:    opsv1alpha1.Dropped,
+				Reason:    dropReasonEchoReply,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ovsCtlClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+			interfaceStore := interfacestore.NewInterfaceStore()
+			podInterface := interfacestore.NewContainerInterface("pod1-1", "container1", "pod1", "ns1", podMAC, []net.IP{net.ParseIP(tt.podIP)})
+			podInterface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: 3}
+			interfaceStore.AddInterface(podInterface)
+			c := &Controller{
+				ovsCtlClient:   ovsCtlClient,
+				interfaceStore: interfaceStore,
+				nodeConfig:     &config.NodeConfig{GatewayConfig: &config.GatewayConfig{MAC: gatewayMAC}},
+			}
+			ovsCtlClient.EXPECT().RunAppctlCmd("ofproto/trace", true, tt.expectedCmd, "--ct-next", "trk,est").Return([]byte(tt.traceOutput), nil)
+			assert.Equal(t, tt.want, c.getEchoReplyObservation(net.ParseIP(tt.podIP), net.ParseIP(tt.peerIP)))
+		})
+	}
+
+	// The reply is not traced if the destination is not a local Pod.
+	c := &Controller{interfaceStore: interfacestore.NewInterfaceStore()}
+	assert.Nil(t, c.getEchoReplyObservation(net.ParseIP("10.10.0.2"), net.ParseIP("10.10.1.2")))
+}
+
+func Test_getGatewayOutputAction(t *testing.T) {
+	_, serviceCIDR, _ := net.ParseCIDR("10.96.0.0/12")
+	_, localPodCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	nodeConfig := &config.NodeConfig{
+		NodeIPAddr:    &net.IPNet{IP: net.ParseIP("192.168.0.1"), Mask: net.CIDRMask(24, 32)},
+		PodIPv4CIDR:   localPodCIDR,
+		GatewayConfig: &config.GatewayConfig{IPv4: net.ParseIP("10.10.0.1")},
+	}
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	nodeInformer.Informer().GetIndexer().Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node2"},
+		Spec:       corev1.NodeSpec{PodCIDR: "10.10.1.0/24", PodCIDRs: []string{"10.10.1.0/24"}},
+	})
+	tests := []struct {
+		name      string
+		encapMode config.TrafficEncapModeType
+		dstIP     string
+		want      opsv1alpha1.TraceflowAction
+	}{
+		{
+			name:      "hostNetwork Pod on the local Node",
+			encapMode: config.TrafficEncapModeEncap,
+			dstIP:     "192.168.0.1",
+			want:      opsv1alpha1.Delivered,
+		},
+		{
+			name:      "local Gateway",
+			encapMode: config.TrafficEncapModeEncap,
+			dstIP:     "10.10.0.1",
+			want:      opsv1alpha1.Delivered,
+		},
+		{
+			name:      "Service handled by kube-proxy",
+			encapMode: config.TrafficEncapModeEncap,
+			dstIP:     "10.96.0.10",
+			want:      opsv1alpha1.Forwarded,
+		},
+		{
+			name:      "hostNetwork Pod on another Node",
+			encapMode: config.TrafficEncapModeEncap,
+			dstIP:     "192.168.0.2",
+			want:      opsv1alpha1.ForwardedOutOfOverlay,
+		},
+		{
+			name:      "external IP in encap mode",
+			encapMode: config.TrafficEncapModeEncap,
+			dstIP:     "8.8.8.8",
+			want:      opsv1alpha1.ForwardedOutOfOverlay,
+		},
+		{
+			name:      "Pod on another Node in noEncap mode",
+			encapMode: config.TrafficEncapModeNoEncap,
+			dstIP:     "10.10.1.2",
+			want:      opsv1alpha1.Forwarded,
+		},
+		{
+			name:      "external IP in noEncap mode",
+			encapMode: config.TrafficEncapModeNoEncap,
+			dstIP:     "8.8.8.8",
+			want:      opsv1alpha1.ForwardedOutOfOverlay,
+		},
+		{
+			name:      "Pod on another Node in hybrid mode",
+			encapMode: config.TrafficEncapModeHybrid,
+			dstIP:     "10.10.1.2",
+			want:      opsv1alpha1.Forwarded,
+		},
+		{
+			name:      "external IP in networkPolicyOnly mode",
+			encapMode: config.TrafficEncapModeNetworkPolicyOnly,
+			dstIP:     "8.8.8.8",
+			want:      opsv1alpha1.Forwarded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{
+				networkConfig: &config.NetworkConfig{TrafficEncapMode: tt.encapMode},
+				nodeConfig:    nodeConfig,
+				serviceCIDR:   serviceCIDR,
+				nodeLister:    nodeInformer.Lister(),
+			}
+			assert.Equal(t, tt.want, c.getGatewayOutputAction(net.ParseIP(tt.dstIP)))
+		})
+	}
+}
+
+func Test_parsePacketInDropped(t *testing.T) {
+	const tag uint8 = 7
+	tf := &opsv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+		Status:     opsv1alpha1.TraceflowStatus{Phase: opsv1alpha1.Running, DataplaneTag: tag},
+	}
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(fakeversioned.NewSimpleClientset(), 0)
+	traceflowInformer := crdInformerFactory.Ops().V1alpha1().Traceflows()
+	traceflowInformer.Informer().GetIndexer().Add(tf)
+
+	tests := []struct {
+		name     string
+		tableID  uint8
+		isSender bool
+		want     []opsv1alpha1.Observation
+	}{
+		{
+			name:     "dropped by egress default isolation on the sender",
+			tableID:  uint8(openflow.EgressDefaultTable),
+			isSender: true,
+			want: []opsv1alpha1.Observation{
+				{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded},
+				{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "EgressDefaultRule", Action: opsv1alpha1.Dropped, Reason: dropReasonIsolation},
+			},
+		},
+		{
+			name:    "dropped by ingress rule on the receiver",
+			tableID: uint8(openflow.IngressMetricTable),
+			want: []opsv1alpha1.Observation{
+				{Component: opsv1alpha1.Forwarding, ComponentInfo: "Classification", Action: opsv1alpha1.Received},
+				{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "IngressMetric", Action: opsv1alpha1.Dropped, Reason: dropReasonPolicyRule},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{
+				traceflowLister:   traceflowInformer.Lister(),
+				nodeConfig:        &config.NodeConfig{Name: "node1"},
+				runningTraceflows: map[uint8]string{tag: tf.Name},
+				injectedTags:      map[uint8]string{},
+			}
+			if tt.isSender {
+				c.injectedTags[tag] = tf.Name
+			}
+			pktIn := &ofctrl.PacketIn{
+				TableId: tt.tableID,
+				Data: protocol.Ethernet{
+					Ethertype: protocol.IPv4_MSG,
+					Data: util.Message(&protocol.IPv4{
+						DSCP:     tag,
+						NWSrc:    net.ParseIP("10.10.0.2"),
+						NWDst:    net.ParseIP("10.10.1.2"),
+						Protocol: protocol.Type_TCP,
+					}),
+				},
+			}
+			gotTf, nodeResult, err := c.parsePacketIn(pktIn)
+			require.NoError(t, err)
+			assert.Equal(t, tf, gotTf)
+			assert.Equal(t, "node1", nodeResult.Node)
+			assert.Equal(t, tt.want, nodeResult.Observations)
+		})
+	}
+}
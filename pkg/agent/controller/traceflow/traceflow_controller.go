@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/contiv/libOpenflow/protocol"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -44,6 +45,7 @@ import (
 	opslisters "github.com/vmware-tanzu/antrea/pkg/client/listers/ops/v1alpha1"
 	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
 	"github.com/vmware-tanzu/antrea/pkg/querier"
 )
 
@@ -66,19 +68,27 @@ const (
 	icmpEchoRequestType   icmpType = 8
 	icmpv6EchoRequestType icmpType = 128
 	icmpEchoRequestCode   icmpCode = 0
+	// ICMP Echo Reply type.
+	icmpEchoReplyType   icmpType = 0
+	icmpv6EchoReplyType icmpType = 129
 )
 
 // Controller is responsible for setting up Openflow entries and injecting traceflow packet into
 // the switch for traceflow request.
 type Controller struct {
 	kubeClient             clientset.Interface
+	podLister              corelisters.PodLister
+	podListerSynced        cache.InformerSynced
 	serviceLister          corelisters.ServiceLister
 	serviceListerSynced    cache.InformerSynced
+	nodeLister             corelisters.NodeLister
+	nodeListerSynced       cache.InformerSynced
 	traceflowClient        clientsetversioned.Interface
 	traceflowInformer      opsinformers.TraceflowInformer
 	traceflowLister        opslisters.TraceflowLister
 	traceflowListerSynced  cache.InformerSynced
 	ovsBridgeClient        ovsconfig.OVSBridgeClient
+	ovsCtlClient           ovsctl.OVSCtlClient
 	ofClient               openflow.Client
 	networkPolicyQuerier   querier.AgentNetworkPolicyInfoQuerier
 	interfaceStore         interfacestore.InterfaceStore
@@ -112,7 +122,12 @@ func NewTraceflowController(
 		traceflowInformer:     traceflowInformer,
 		traceflowLister:       traceflowInformer.Lister(),
 		traceflowListerSynced: traceflowInformer.Informer().HasSynced,
+		podLister:             informerFactory.Core().V1().Pods().Lister(),
+		podListerSynced:       informerFactory.Core().V1().Pods().Informer().HasSynced,
+		nodeLister:            informerFactory.Core().V1().Nodes().Lister(),
+		nodeListerSynced:      informerFactory.Core().V1().Nodes().Informer().HasSynced,
 		ovsBridgeClient:       ovsBridgeClient,
+		ovsCtlClient:          ovsctl.NewClient(nodeConfig.OVSBridge),
 		ofClient:              client,
 		networkPolicyQuerier:  npQuerier,
 		interfaceStore:        interfaceStore,
@@ -155,7 +170,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	klog.Infof("Starting %s", controllerName)
 	defer klog.Infof("Shutting down %s", controllerName)
 
-	cacheSyncs := []cache.InformerSynced{c.traceflowListerSynced}
+	cacheSyncs := []cache.InformerSynced{c.traceflowListerSynced, c.podListerSynced, c.nodeListerSynced}
 	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 		cacheSyncs = append(cacheSyncs, c.serviceListerSynced)
 	}
@@ -322,6 +337,7 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 
 	var srcTCPPort, dstTCPPort, srcUDPPort, dstUDPPort, idICMP, sequenceICMP uint16
 	var flagsTCP uint8
+	var dstSvc *corev1.Service
 
 	// Calculate destination MAC/IP.
 	isIPv6 := tf.Spec.Packet.IPv6Header != nil
@@ -352,7 +368,7 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 				dstIP = dstPodInterfaces[0].GetIPv4Addr().String()
 			}
 		} else {
-			dstPod, err := c.kubeClient.CoreV1().Pods(tf.Spec.Destination.Namespace).Get(context.TODO(), tf.Spec.Destination.Pod, metav1.GetOptions{})
+			dstPod, err := c.podLister.Pods(tf.Spec.Destination.Namespace).Get(tf.Spec.Destination.Pod)
 			if err != nil {
 				return err
 			}
@@ -369,10 +385,14 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 			}
 		}
 	} else if tf.Spec.Destination.Service != "" {
-		dstSvc, err := c.serviceLister.Services(tf.Spec.Destination.Namespace).Get(tf.Spec.Destination.Service)
+		var err error
+		dstSvc, err = c.serviceLister.Services(tf.Spec.Destination.Namespace).Get(tf.Spec.Destination.Service)
 		if err != nil {
 			return err
 		}
+		if dstSvc.Spec.ClusterIP == "" || dstSvc.Spec.ClusterIP == corev1.ClusterIPNone {
+			return fmt.Errorf("destination Service %s/%s has no ClusterIP", dstSvc.Namespace, dstSvc.Name)
+		}
 		dstIP = dstSvc.Spec.ClusterIP
 		flagsTCP = 2
 	}
@@ -410,6 +430,9 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 	if tf.Spec.Packet.TransportHeader.TCP != nil {
 		srcTCPPort = uint16(tf.Spec.Packet.TransportHeader.TCP.SrcPort)
 		dstTCPPort = uint16(tf.Spec.Packet.TransportHeader.TCP.DstPort)
+		if dstTCPPort == 0 && dstSvc != nil {
+			dstTCPPort = getServicePort(dstSvc, corev1.ProtocolTCP)
+		}
 		if tf.Spec.Packet.TransportHeader.TCP.Flags != 0 {
 			flagsTCP = uint8(tf.Spec.Packet.TransportHeader.TCP.Flags)
 		}
@@ -417,17 +440,24 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 	if tf.Spec.Packet.TransportHeader.UDP != nil {
 		srcUDPPort = uint16(tf.Spec.Packet.TransportHeader.UDP.SrcPort)
 		dstUDPPort = uint16(tf.Spec.Packet.TransportHeader.UDP.DstPort)
+		if dstUDPPort == 0 && dstSvc != nil {
+			dstUDPPort = getServicePort(dstSvc, corev1.ProtocolUDP)
+		}
+	}
+	var packetOutICMPType icmpType
+	if isIPv6 {
+		packetOutICMPType = icmpv6EchoRequestType
+	} else {
+		packetOutICMPType = icmpEchoRequestType
 	}
+	packetOutICMPCode := icmpEchoRequestCode
 	if tf.Spec.Packet.TransportHeader.ICMP != nil {
 		idICMP = uint16(tf.Spec.Packet.TransportHeader.ICMP.ID)
 		sequenceICMP = uint16(tf.Spec.Packet.TransportHeader.ICMP.Sequence)
-	}
-
-	var packetOutIcmpEchoRequestType icmpType
-	if isIPv6 {
-		packetOutIcmpEchoRequestType = icmpv6EchoRequestType
-	} else {
-		packetOutIcmpEchoRequestType = icmpEchoRequestType
+		if tf.Spec.Packet.TransportHeader.ICMP.Type != nil {
+			packetOutICMPType = icmpType(*tf.Spec.Packet.TransportHeader.ICMP.Type)
+		}
+		packetOutICMPCode = icmpCode(tf.Spec.Packet.TransportHeader.ICMP.Code)
 	}
 
 	return c.ofClient.SendTraceflowPacket(
@@ -444,14 +474,25 @@ func (c *Controller) injectPacket(tf *opsv1alpha1.Traceflow) error {
 		flagsTCP,
 		srcUDPPort,
 		dstUDPPort,
-		uint8(packetOutIcmpEchoRequestType),
-		uint8(icmpEchoRequestCode),
+		uint8(packetOutICMPType),
+		uint8(packetOutICMPCode),
 		idICMP,
 		sequenceICMP,
 		uint32(podInterfaces[0].OFPort),
 		-1)
 }
 
+// getServicePort returns the first port of the Service with the provided
+// protocol, or 0 if there is none.
+func getServicePort(svc *corev1.Service, proto corev1.Protocol) uint16 {
+	for _, port := range svc.Spec.Ports {
+		if port.Protocol == proto {
+			return uint16(port.Port)
+		}
+	}
+	return 0
+}
+
 func (c *Controller) errorTraceflowCRD(tf *opsv1alpha1.Traceflow, reason string) (*opsv1alpha1.Traceflow, error) {
 	tf.Status.Phase = opsv1alpha1.Failed
 
@@ -0,0 +1,145 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	openflowtest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+func newPodInterface(podName, mac, ip string, ofPort int32) *interfacestore.InterfaceConfig {
+	parsedMAC, _ := net.ParseMAC(mac)
+	iface := interfacestore.NewContainerInterface(podName+"-eth0", podName, podName, "ns1", parsedMAC, []net.IP{net.ParseIP(ip)})
+	iface.OVSPortConfig = &interfacestore.OVSPortConfig{OFPort: ofPort}
+	return iface
+}
+
+func Test_injectPacketICMP(t *testing.T) {
+	const tag uint8 = 3
+	icmpType := int32(3)
+	icmpV6Type := int32(1)
+	tests := []struct {
+		name         string
+		packet       opsv1alpha1.Packet
+		srcIP        string
+		dstIP        string
+		ipProtocol   uint8
+		icmpType     uint8
+		icmpCode     uint8
+		icmpID       uint16
+		icmpSequence uint16
+	}{
+		{
+			name:       "default echo request",
+			srcIP:      "10.10.0.2",
+			dstIP:      "10.10.0.3",
+			ipProtocol: protocol.Type_ICMP,
+			icmpType:   uint8(icmpEchoRequestType),
+			icmpCode:   uint8(icmpEchoRequestCode),
+		},
+		{
+			name: "echo request with id and sequence",
+			packet: opsv1alpha1.Packet{
+				IPHeader: opsv1alpha1.IPHeader{Protocol: opsv1alpha1.ICMPProtocol},
+				TransportHeader: opsv1alpha1.TransportHeader{
+					ICMP: &opsv1alpha1.ICMPEchoRequestHeader{ID: 10, Sequence: 1},
+				},
+			},
+			srcIP:        "10.10.0.2",
+			dstIP:        "10.10.0.3",
+			ipProtocol:   protocol.Type_ICMP,
+			icmpType:     uint8(icmpEchoRequestType),
+			icmpCode:     uint8(icmpEchoRequestCode),
+			icmpID:       10,
+			icmpSequence: 1,
+		},
+		{
+			name: "port unreachable",
+			packet: opsv1alpha1.Packet{
+				IPHeader: opsv1alpha1.IPHeader{Protocol: opsv1alpha1.ICMPProtocol},
+				TransportHeader: opsv1alpha1.TransportHeader{
+					ICMP: &opsv1alpha1.ICMPEchoRequestHeader{Type: &icmpType, Code: 3},
+				},
+			},
+			srcIP:      "10.10.0.2",
+			dstIP:      "10.10.0.3",
+			ipProtocol: protocol.Type_ICMP,
+			icmpType:   3,
+			icmpCode:   3,
+		},
+		{
+			name: "IPv6 default echo request",
+			packet: opsv1alpha1.Packet{
+				IPv6Header: &opsv1alpha1.IPv6Header{},
+			},
+			srcIP:      "fd00:10:10::2",
+			dstIP:      "fd00:10:10::3",
+			ipProtocol: protocol.Type_IPv6ICMP,
+			icmpType:   uint8(icmpv6EchoRequestType),
+			icmpCode:   uint8(icmpEchoRequestCode),
+		},
+		{
+			name: "IPv6 destination unreachable",
+			packet: opsv1alpha1.Packet{
+				IPv6Header: &opsv1alpha1.IPv6Header{},
+				TransportHeader: opsv1alpha1.TransportHeader{
+					ICMP: &opsv1alpha1.ICMPEchoRequestHeader{Type: &icmpV6Type, Code: 4},
+				},
+			},
+			srcIP:      "fd00:10:10::2",
+			dstIP:      "fd00:10:10::3",
+			ipProtocol: protocol.Type_IPv6ICMP,
+			icmpType:   1,
+			icmpCode:   4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ofClient := openflowtest.NewMockClient(ctrl)
+			ifaceStore := interfacestore.NewInterfaceStore()
+			ifaceStore.AddInterface(newPodInterface("pod1", "aa:bb:cc:dd:ee:01", tt.srcIP, 1))
+			ifaceStore.AddInterface(newPodInterface("pod2", "aa:bb:cc:dd:ee:02", tt.dstIP, 2))
+			c := &Controller{
+				ofClient:       ofClient,
+				interfaceStore: ifaceStore,
+				injectedTags:   map[uint8]string{},
+			}
+			tf := &opsv1alpha1.Traceflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+				Spec: opsv1alpha1.TraceflowSpec{
+					Source:      opsv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+					Destination: opsv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+					Packet:      tt.packet,
+				},
+				Status: opsv1alpha1.TraceflowStatus{DataplaneTag: tag},
+			}
+			ofClient.EXPECT().SendTraceflowPacket(tag, "aa:bb:cc:dd:ee:01", "aa:bb:cc:dd:ee:02", tt.srcIP, tt.dstIP,
+				tt.ipProtocol, uint8(0), uint16(0), uint16(0), uint16(0), uint8(0), uint16(0), uint16(0),
+				tt.icmpType, tt.icmpCode, tt.icmpID, tt.icmpSequence, uint32(1), int32(-1)).Return(nil)
+			require.NoError(t, c.injectPacket(tf))
+		})
+	}
+}
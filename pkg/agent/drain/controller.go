@@ -0,0 +1,242 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drain puts the datapath of the Node into drain mode before a
+// maintenance: the new connections received from outside the Node through
+// NodePort and LoadBalancer Services are dropped, while the established
+// connections are given a grace period to finish. The Node is considered safe
+// to reboot once these connections have been closed, or at the latest when the
+// grace period has expired.
+package drain
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
+)
+
+const (
+	// DefaultGracePeriod is the grace period given to the established
+	// connections when none is provided.
+	DefaultGracePeriod = 60 * time.Second
+
+	// defaultZone is the conntrack zone of the connections DNAT'd to the
+	// Services by the host network stack.
+	defaultZone = 0
+	// statusDstNAT is the IPS_DST_NAT status bit of the conntrack
+	// connections.
+	statusDstNAT = 1 << 5
+)
+
+// checkInterval is the interval at which the remaining external Service
+// connections are counted while draining. It is a variable so that it can be
+// overridden in tests.
+var checkInterval = 5 * time.Second
+
+// State is the drain state of the datapath of the Node.
+type State string
+
+const (
+	// NotDraining means that the datapath accepts new connections.
+	NotDraining State = "NotDraining"
+	// Draining means that new external connections are dropped and that
+	// some established external connections may still be open.
+	Draining State = "Draining"
+	// Drained means that the established external connections have been
+	// closed, or that the grace period has expired, and that the Node is
+	// safe to reboot. New external connections are still dropped.
+	Drained State = "Drained"
+)
+
+// Status is the drain status of the datapath of the Node.
+type Status struct {
+	State State
+	// StartTime is the time at which the drain was started. It is zero when
+	// State is NotDraining.
+	StartTime time.Time
+	// GracePeriod is the grace period given to the established connections.
+	GracePeriod time.Duration
+}
+
+// ConnectionDumper dumps the conntrack connections of a zone. It is
+// implemented by the ConnTrackDumper of the flow exporter.
+type ConnectionDumper interface {
+	DumpZone(zoneFilter uint16) ([]*flowexporter.Connection, error)
+}
+
+// Controller manages the drain mode of the datapath of the Node. The drain
+// mode is not persisted, so it is exited when antrea-agent restarts, e.g.
+// after the Node has been rebooted.
+type Controller struct {
+	routeClient route.Interface
+	// connDumper is used to check whether the established external Service
+	// connections have been closed. If it is nil, the datapath is only
+	// considered drained when the grace period expires.
+	connDumper ConnectionDumper
+	nodeConfig *config.NodeConfig
+
+	mutex  sync.RWMutex
+	status Status
+	// stopCh stops the goroutine waiting for the datapath to be drained.
+	stopCh chan struct{}
+}
+
+// NewController creates a Controller which configures the datapath with the
+// provided route client, and checks the remaining connections with the
+// provided ConnectionDumper.
+func NewController(routeClient route.Interface, connDumper ConnectionDumper, nodeConfig *config.NodeConfig) *Controller {
+	return &Controller{
+		routeClient: routeClient,
+		connDumper:  connDumper,
+		nodeConfig:  nodeConfig,
+		status:      Status{State: NotDraining},
+	}
+}
+
+// Start puts the datapath into drain mode. If the datapath is already in drain
+// mode, the grace period is updated, and still counts from the original start
+// time.
+func (c *Controller) Start(gracePeriod time.Duration) error {
+	if gracePeriod < 0 {
+		return fmt.Errorf("grace period must not be negative")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.status.State == NotDraining {
+		if err := c.routeClient.SetDrainMode(true); err != nil {
+			return fmt.Errorf("error when dropping new external connections: %v", err)
+		}
+		c.status.StartTime = time.Now()
+		klog.Infof("Started draining the datapath with grace period %v", gracePeriod)
+	} else {
+		klog.Infof("Updated the grace period of the datapath drain to %v", gracePeriod)
+	}
+	c.status.State = Draining
+	c.status.GracePeriod = gracePeriod
+	if c.stopCh != nil {
+		close(c.stopCh)
+	}
+	c.stopCh = make(chan struct{})
+	go c.waitDrained(c.stopCh, c.status.StartTime.Add(gracePeriod))
+	return nil
+}
+
+// waitDrained moves the state to Drained once the established external
+// Service connections have been closed, or once the deadline has passed. It
+// returns when stopCh is closed, i.e. when the drain is stopped or restarted.
+func (c *Controller) waitDrained(stopCh <-chan struct{}, deadline time.Time) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if c.connDumper != nil {
+			if n, err := c.countExternalConnections(); err != nil {
+				klog.Errorf("Failed to count the external Service connections: %v", err)
+			} else if n == 0 {
+				c.setDrained(stopCh, "all the external Service connections have been closed")
+				return
+			} else {
+				klog.V(2).Infof("%d external Service connections are still open", n)
+			}
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+			c.setDrained(stopCh, "the grace period of the datapath drain has expired")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Controller) setDrained(stopCh <-chan struct{}, reason string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// The drain may have been stopped or restarted in the meantime.
+	if c.stopCh != stopCh {
+		return
+	}
+	c.status.State = Drained
+	klog.Infof("The Node is safe to reboot, as %s", reason)
+}
+
+// countExternalConnections returns the number of connections DNAT'd to a
+// Service by the host network stack whose client is outside the Node, i.e. the
+// connections which are dropped when they are new in drain mode.
+func (c *Controller) countExternalConnections() (int, error) {
+	conns, err := c.connDumper.DumpZone(defaultZone)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, conn := range conns {
+		if conn.StatusFlag&statusDstNAT != 0 && !c.isLocalAddress(conn.TupleOrig.SourceAddress) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// isLocalAddress returns whether the IP is the IP of the Node or of a local
+// Pod.
+func (c *Controller) isLocalAddress(ip net.IP) bool {
+	if c.nodeConfig == nil {
+		return false
+	}
+	if c.nodeConfig.NodeIPAddr != nil && c.nodeConfig.NodeIPAddr.IP.Equal(ip) {
+		return true
+	}
+	for _, cidr := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
+		if cidr != nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop exits the drain mode, so that new external connections are accepted
+// again. It does nothing if the datapath is not in drain mode.
+func (c *Controller) Stop() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.status.State == NotDraining {
+		return nil
+	}
+	if err := c.routeClient.SetDrainMode(false); err != nil {
+		return fmt.Errorf("error when accepting new external connections: %v", err)
+	}
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+	c.status = Status{State: NotDraining}
+	klog.Info("Stopped draining the datapath")
+	return nil
+}
+
+// GetStatus returns the current drain status.
+func (c *Controller) GetStatus() Status {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.status
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
+)
+
+type fakeConnectionDumper struct {
+	mutex sync.Mutex
+	conns []*flowexporter.Connection
+}
+
+func (d *fakeConnectionDumper) DumpZone(zoneFilter uint16) ([]*flowexporter.Connection, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.conns, nil
+}
+
+func (d *fakeConnectionDumper) setConnections(conns ...*flowexporter.Connection) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.conns = conns
+}
+
+func newConnection(sourceIP string, statusFlag uint32) *flowexporter.Connection {
+	return &flowexporter.Connection{
+		StatusFlag: statusFlag,
+		TupleOrig:  flowexporter.Tuple{SourceAddress: net.ParseIP(sourceIP)},
+	}
+}
+
+func TestStartAndStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	routeClient := routetest.NewMockInterface(ctrl)
+	c := NewController(routeClient, nil, nil)
+	assert.Equal(t, NotDraining, c.GetStatus().State)
+
+	routeClient.EXPECT().SetDrainMode(true).Times(1)
+	require.NoError(t, c.Start(time.Hour))
+	status := c.GetStatus()
+	assert.Equal(t, Draining, status.State)
+	assert.Equal(t, time.Hour, status.GracePeriod)
+	assert.False(t, status.StartTime.IsZero())
+
+	// Starting again only updates the grace period, which counts from the original start time.
+	require.NoError(t, c.Start(0))
+	assert.Eventually(t, func() bool {
+		return c.GetStatus().State == Drained
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, status.StartTime, c.GetStatus().StartTime)
+
+	routeClient.EXPECT().SetDrainMode(false).Times(1)
+	require.NoError(t, c.Stop())
+	assert.Equal(t, Status{State: NotDraining}, c.GetStatus())
+	// Stopping again does nothing.
+	require.NoError(t, c.Stop())
+}
+
+func TestStopBeforeGracePeriodExpires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	routeClient := routetest.NewMockInterface(ctrl)
+	c := NewController(routeClient, nil, nil)
+
+	routeClient.EXPECT().SetDrainMode(true).Times(1)
+	require.NoError(t, c.Start(50*time.Millisecond))
+	routeClient.EXPECT().SetDrainMode(false).Times(1)
+	require.NoError(t, c.Stop())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, NotDraining, c.GetStatus().State)
+}
+
+func TestStartFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	routeClient := routetest.NewMockInterface(ctrl)
+	c := NewController(routeClient, nil, nil)
+
+	assert.Error(t, c.Start(-time.Second))
+	routeClient.EXPECT().SetDrainMode(true).Return(errors.New("iptables-restore failed")).Times(1)
+	assert.Error(t, c.Start(time.Second))
+	assert.Equal(t, NotDraining, c.GetStatus().State)
+}
+
+func TestDrainedWhenConnectionsClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	routeClient := routetest.NewMockInterface(ctrl)
+	_, podCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	nodeConfig := &config.NodeConfig{
+		NodeIPAddr:  &net.IPNet{IP: net.ParseIP("192.168.0.1"), Mask: net.CIDRMask(24, 32)},
+		PodIPv4CIDR: podCIDR,
+	}
+	connDumper := &fakeConnectionDumper{}
+	// An external Service connection, which keeps the datapath draining.
+	connDumper.setConnections(newConnection("192.168.0.2", statusDstNAT))
+	defer func(interval time.Duration) { checkInterval = interval }(checkInterval)
+	checkInterval = 10 * time.Millisecond
+	c := NewController(routeClient, connDumper, nodeConfig)
+
+	routeClient.EXPECT().SetDrainMode(true).Times(1)
+	require.NoError(t, c.Start(time.Hour))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, Draining, c.GetStatus().State)
+
+	// The connections from the Node or from local Pods, and the connections
+	// which are not DNAT'd, are not dropped in drain mode and are ignored.
+	connDumper.setConnections(
+		newConnection("192.168.0.1", statusDstNAT),
+		newConnection("10.10.0.2", statusDstNAT),
+		newConnection("192.168.0.2", 0),
+	)
+	assert.Eventually(t, func() bool {
+		return c.GetStatus().State == Drained
+	}, time.Second, 10*time.Millisecond)
+
+	routeClient.EXPECT().SetDrainMode(false).Times(1)
+	require.NoError(t, c.Stop())
+}
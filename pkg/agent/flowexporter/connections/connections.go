@@ -18,6 +18,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -47,6 +48,8 @@ type ConnectionStore struct {
 	networkPolicyQuerier querier.AgentNetworkPolicyInfoQuerier
 	pollInterval         time.Duration
 	mutex                sync.Mutex
+	// paused is set to 1 when conntrack polling is paused, e.g. because the agent is under memory pressure.
+	paused int32
 }
 
 func NewConnectionStore(
@@ -82,6 +85,11 @@ func (cs *ConnectionStore) Run(stopCh <-chan struct{}, pollDone chan struct{}) {
 		case <-stopCh:
 			break
 		case <-pollTicker.C:
+			if atomic.LoadInt32(&cs.paused) == 1 {
+				// Not signaling pollDone either, so that flow records are not exported while polling is paused.
+				klog.V(2).Infof("Conntrack polling is paused, skipping poll cycle")
+				continue
+			}
 			_, err := cs.Poll()
 			if err != nil {
 				// Not failing here as errors can be transient and could be resolved in future poll cycles.
@@ -96,6 +104,24 @@ func (cs *ConnectionStore) Run(stopCh <-chan struct{}, pollDone chan struct{}) {
 	}
 }
 
+// SetPaused pauses or resumes the periodical polling of conntrack connections, and thus the export of flow records.
+// The connections are removed from the store when polling is paused, as they are neither updated nor exported until
+// it is resumed: the connections which are still active are added back by the first poll after it is resumed.
+func (cs *ConnectionStore) SetPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&cs.paused, v)
+	if !paused {
+		return
+	}
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	metrics.TotalAntreaConnectionsInConnTrackTable.Sub(float64(len(cs.connections)))
+	cs.connections = make(map[flowexporter.ConnectionKey]flowexporter.Connection)
+}
+
 // addOrUpdateConn updates the connection if it is already present, i.e., update timestamp, counters etc.,
 // or adds a new Connection by 5-tuple of the flow along with local Pod and PodNameSpace.
 func (cs *ConnectionStore) addOrUpdateConn(conn *flowexporter.Connection) {
@@ -348,6 +348,33 @@ func TestConnectionStore_DeleteConnectionByKey(t *testing.T) {
 	}
 }
 
+func TestConnectionStore_SetPaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	metrics.InitializeConnectionMetrics()
+	tuple, revTuple := makeTuple(&net.IP{1, 2, 3, 4}, &net.IP{4, 3, 2, 1}, 6, 65280, 255)
+	conn := flowexporter.Connection{
+		TupleOrig:  tuple,
+		TupleReply: revTuple,
+		IsActive:   true,
+	}
+	connKey := flowexporter.NewConnectionKey(&conn)
+	metrics.TotalAntreaConnectionsInConnTrackTable.Set(1)
+	mockIfaceStore := interfacestoretest.NewMockInterfaceStore(ctrl)
+	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
+	connStore := NewConnectionStore(mockConnDumper, mockIfaceStore, true, false, nil, nil, testPollInterval)
+	connStore.connections[connKey] = conn
+
+	connStore.SetPaused(false)
+	_, exists := connStore.GetConnByKey(connKey)
+	assert.True(t, exists, "connection should be kept when polling is resumed")
+
+	connStore.SetPaused(true)
+	_, exists = connStore.GetConnByKey(connKey)
+	assert.False(t, exists, "connection should be removed when polling is paused")
+	checkAntreaConnectionMetrics(t, 0)
+}
+
 func TestConnectionStore_MetricSettingInPoll(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -19,6 +19,7 @@ package connections
 import (
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/ti-mo/conntrack"
 	"k8s.io/klog"
@@ -38,40 +39,50 @@ type connTrackSystem struct {
 	serviceCIDRv6        *net.IPNet
 	isAntreaProxyEnabled bool
 	connTrack            NetFilterConnTrack
+	// connTrackMutex serializes the dumps, which are requested by the flow exporter and by the agent API, as each
+	// dump dials the netlink connection of connTrack and closes it.
+	connTrackMutex sync.Mutex
 }
 
+// NewConnTrackSystem returns a ConnTrackDumper for the Linux kernel datapath. The counters and timestamps of the
+// connections are only available if SetupConntrackParameters has been called.
 func NewConnTrackSystem(nodeConfig *config.NodeConfig, serviceCIDRv4 *net.IPNet, serviceCIDRv6 *net.IPNet, isAntreaProxyEnabled bool) *connTrackSystem {
-	if err := SetupConntrackParameters(); err != nil {
-		// Do not fail, but continue after logging an error as we can still dump flows with missing information.
-		klog.Errorf("Error when setting up conntrack parameters, some information may be missing from exported flows: %v", err)
-	}
 	return &connTrackSystem{
-		nodeConfig,
-		serviceCIDRv4,
-		serviceCIDRv6,
-		isAntreaProxyEnabled,
-		&netFilterConnTrack{},
+		nodeConfig:           nodeConfig,
+		serviceCIDRv4:        serviceCIDRv4,
+		serviceCIDRv6:        serviceCIDRv6,
+		isAntreaProxyEnabled: isAntreaProxyEnabled,
+		connTrack:            &netFilterConnTrack{},
 	}
 }
 
-// DumpFlows opens netlink connection and dumps all the flows in Antrea ZoneID of conntrack table.
-func (ct *connTrackSystem) DumpFlows(zoneFilter uint16) ([]*flowexporter.Connection, int, error) {
-	svcCIDR := ct.serviceCIDRv4
-	if zoneFilter == openflow.CtZoneV6 {
-		svcCIDR = ct.serviceCIDRv6
-	}
+// dumpConnections opens netlink connection and dumps all the flows of the conntrack table.
+func (ct *connTrackSystem) dumpConnections(zoneFilter uint16) ([]*flowexporter.Connection, error) {
+	ct.connTrackMutex.Lock()
+	defer ct.connTrackMutex.Unlock()
 	// Get connection to netlink socket
-	err := ct.connTrack.Dial()
-	if err != nil {
-		return nil, 0, fmt.Errorf("error when getting netlink socket: %v", err)
+	if err := ct.connTrack.Dial(); err != nil {
+		return nil, fmt.Errorf("error when getting netlink socket: %v", err)
 	}
-
 	// ZoneID filter is not supported currently in tl-mo/conntrack library.
 	// Link to issue: https://github.com/ti-mo/conntrack/issues/23
 	// Dump all flows in the conntrack table for now.
 	conns, err := ct.connTrack.DumpFlowsInCtZone(zoneFilter)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error when dumping flows from conntrack: %v", err)
+		return nil, fmt.Errorf("error when dumping flows from conntrack: %v", err)
+	}
+	return conns, nil
+}
+
+// DumpFlows opens netlink connection and dumps all the flows in Antrea ZoneID of conntrack table.
+func (ct *connTrackSystem) DumpFlows(zoneFilter uint16) ([]*flowexporter.Connection, int, error) {
+	svcCIDR := ct.serviceCIDRv4
+	if zoneFilter == openflow.CtZoneV6 {
+		svcCIDR = ct.serviceCIDRv6
+	}
+	conns, err := ct.dumpConnections(zoneFilter)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	filteredConns := filterAntreaConns(conns, ct.nodeConfig, svcCIDR, zoneFilter, ct.isAntreaProxyEnabled)
@@ -80,6 +91,21 @@ func (ct *connTrackSystem) DumpFlows(zoneFilter uint16) ([]*flowexporter.Connect
 	return filteredConns, len(conns), nil
 }
 
+// DumpZone opens netlink connection and dumps all the flows in the zone of conntrack table.
+func (ct *connTrackSystem) DumpZone(zoneFilter uint16) ([]*flowexporter.Connection, error) {
+	conns, err := ct.dumpConnections(zoneFilter)
+	if err != nil {
+		return nil, err
+	}
+	zoneConns := conns[:0]
+	for _, conn := range conns {
+		if conn.Zone == zoneFilter {
+			zoneConns = append(zoneConns, conn)
+		}
+	}
+	return zoneConns, nil
+}
+
 // NetFilterConnTrack interface helps for testing the code that contains the third party library functions ("github.com/ti-mo/conntrack")
 type NetFilterConnTrack interface {
 	Dial() error
@@ -161,6 +187,8 @@ func netlinkFlowToAntreaConnection(conn *conntrack.Flow) *flowexporter.Connectio
 	return &newConn
 }
 
+// SetupConntrackParameters enables the accounting and the timestamps of the conntrack connections, which provide
+// their counters and start times. It is only called when the flow exporter is enabled.
 func SetupConntrackParameters() error {
 	parametersWithErrors := []string{}
 	if sysctl.EnsureSysctlNetValue("netfilter/nf_conntrack_acct", 1) != nil {
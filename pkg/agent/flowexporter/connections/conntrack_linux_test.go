@@ -96,6 +96,44 @@ func TestConnTrackSystem_DumpFlows(t *testing.T) {
 	assert.Equal(t, len(testFlows), totalConns, "Number of connections in conntrack table should be equal to testFlows")
 }
 
+func TestConnTrackSystem_DumpZone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// Create flows for test
+	tuple, revTuple := makeTuple(&net.IP{1, 2, 3, 4}, &net.IP{4, 3, 2, 1}, 6, 65280, 255)
+	antreaFlow := &flowexporter.Connection{
+		TupleOrig:  tuple,
+		TupleReply: revTuple,
+		Zone:       openflow.CtZone,
+	}
+	tuple, revTuple = makeTuple(&net.IP{5, 6, 7, 8}, &net.IP{8, 7, 6, 5}, 6, 60001, 200)
+	antreaGWFlow := &flowexporter.Connection{
+		TupleOrig:  tuple,
+		TupleReply: revTuple,
+		Zone:       openflow.CtZone,
+	}
+	nonAntreaFlow := &flowexporter.Connection{
+		TupleOrig:  tuple,
+		TupleReply: revTuple,
+		Zone:       100,
+	}
+	testFlows := []*flowexporter.Connection{antreaFlow, antreaGWFlow, nonAntreaFlow}
+
+	nodeConfig := &config.NodeConfig{
+		GatewayConfig: &config.GatewayConfig{IPv4: net.IP{8, 7, 6, 5}},
+	}
+	mockNetlinkCT := connectionstest.NewMockNetFilterConnTrack(ctrl)
+	connDumperDPSystem := NewConnTrackSystem(nodeConfig, nil, nil, false)
+	connDumperDPSystem.connTrack = mockNetlinkCT
+	mockNetlinkCT.EXPECT().Dial().Return(nil)
+	mockNetlinkCT.EXPECT().DumpFlowsInCtZone(uint16(openflow.CtZone)).Return(testFlows, nil)
+
+	// Unlike DumpFlows, DumpZone only filters the connections by zone.
+	conns, err := connDumperDPSystem.DumpZone(openflow.CtZone)
+	assert.NoErrorf(t, err, "Dump zone function returned error: %v", err)
+	assert.Equal(t, []*flowexporter.Connection{antreaFlow, antreaGWFlow}, conns)
+}
+
 func TestConnTrackOvsAppCtl_DumpFlows(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
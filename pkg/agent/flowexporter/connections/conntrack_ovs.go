@@ -76,6 +76,15 @@ func (ct *connTrackOvsCtl) DumpFlows(zoneFilter uint16) ([]*flowexporter.Connect
 	return filteredConns, totalConns, nil
 }
 
+// DumpZone uses "ovs-appctl dpctl/dump-conntrack" to dump all the conntrack flows in the zone.
+func (ct *connTrackOvsCtl) DumpZone(zoneFilter uint16) ([]*flowexporter.Connection, error) {
+	conns, _, err := ct.ovsAppctlDumpConnections(zoneFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error when dumping flows from conntrack: %v", err)
+	}
+	return conns, nil
+}
+
 func (ct *connTrackOvsCtl) ovsAppctlDumpConnections(zoneFilter uint16) ([]*flowexporter.Connection, int, error) {
 	// Dump conntrack using ovs-appctl dpctl/dump-conntrack
 	cmdOutput, execErr := ct.ovsctlClient.RunAppctlCmd("dpctl/dump-conntrack", false, "-m", "-s")
@@ -25,3 +25,9 @@ import (
 func NewConnTrackSystem(nodeConfig *config.NodeConfig, serviceCIDRv4 *net.IPNet, serviceCIDRv6 *net.IPNet, isAntreaProxyEnabled bool) *connTrackOvsCtl {
 	return NewConnTrackOvsAppCtl(nodeConfig, serviceCIDRv4, serviceCIDRv6, isAntreaProxyEnabled)
 }
+
+// SetupConntrackParameters does nothing on Windows, where the connections are dumped from the OVS userspace
+// conntrack, which always provides their counters.
+func SetupConntrackParameters() error {
+	return nil
+}
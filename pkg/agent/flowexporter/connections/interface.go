@@ -24,6 +24,9 @@ import (
 type ConnTrackDumper interface {
 	// DumpFlows returns a list of filtered connections and the number of total connections.
 	DumpFlows(zoneFilter uint16) ([]*flowexporter.Connection, int, error)
+	// DumpZone returns all the connections of the zone, including the ones filtered out by DumpFlows, e.g. the
+	// connections of the host gateway.
+	DumpZone(zoneFilter uint16) ([]*flowexporter.Connection, error)
 	// GetMaxConnections returns the size of the connection tracking table.
 	GetMaxConnections() (int, error)
 }
@@ -64,6 +64,21 @@ func (mr *MockConnTrackDumperMockRecorder) DumpFlows(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpFlows", reflect.TypeOf((*MockConnTrackDumper)(nil).DumpFlows), arg0)
 }
 
+// DumpZone mocks base method
+func (m *MockConnTrackDumper) DumpZone(arg0 uint16) ([]*flowexporter.Connection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpZone", arg0)
+	ret0, _ := ret[0].([]*flowexporter.Connection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpZone indicates an expected call of DumpZone
+func (mr *MockConnTrackDumperMockRecorder) DumpZone(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpZone", reflect.TypeOf((*MockConnTrackDumper)(nil).DumpZone), arg0)
+}
+
 // GetMaxConnections mocks base method
 func (m *MockConnTrackDumper) GetMaxConnections() (int, error) {
 	m.ctrl.T.Helper()
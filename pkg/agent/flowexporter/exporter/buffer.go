@@ -0,0 +1,172 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+)
+
+const (
+	// DefaultRecordBufferPath is the file in which the flow records are
+	// buffered. It is in a hostPath volume, so that the buffered records are
+	// not lost when the Agent restarts.
+	DefaultRecordBufferPath = "/var/run/antrea/flow-exporter/records"
+	// maxRecordLineSize is the maximum size of a JSON encoded record.
+	maxRecordLineSize = 64 * 1024
+)
+
+var errRecordBufferFull = errors.New("flow record buffer is full")
+
+// recordBuffer is a bounded FIFO queue of flow records which is persisted to
+// a file. It holds the records of the connections which are no longer active
+// while the flow collector cannot be reached, so that they are neither lost
+// nor kept in memory. Records are stored in JSON format, one per line, so that
+// they can be appended without rewriting the file.
+type recordBuffer struct {
+	path    string
+	maxSize int
+	// size is the number of records in the file.
+	size int
+}
+
+// newRecordBuffer returns a recordBuffer which holds up to maxSize records in
+// the provided file. Records left in the file by a previous run are kept.
+func newRecordBuffer(path string, maxSize int) (*recordBuffer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error when creating directory for flow record buffer: %v", err)
+	}
+	b := &recordBuffer{path: path, maxSize: maxSize}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error when reading flow record buffer: %v", err)
+	}
+	b.size = bytes.Count(data, []byte("\n"))
+	if b.size > 0 {
+		klog.Infof("Found %d buffered flow records in %s", b.size, path)
+	}
+	metrics.FlowRecordBufferedCount.Set(float64(b.size))
+	return b, nil
+}
+
+func (b *recordBuffer) len() int {
+	return b.size
+}
+
+// push appends the record to the buffer. errRecordBufferFull is returned if
+// the buffer already holds maxSize records.
+func (b *recordBuffer) push(record flowexporter.FlowRecord) error {
+	if b.size >= b.maxSize {
+		return errRecordBufferFull
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error when encoding flow record: %v", err)
+	}
+	f, err := os.OpenFile(b.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error when opening flow record buffer: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error when writing flow record buffer: %v", err)
+	}
+	b.size++
+	metrics.FlowRecordBufferedCount.Set(float64(b.size))
+	return nil
+}
+
+// drain calls send for the buffered records in the order in which they were
+// pushed, and removes them from the buffer. It stops at the first error
+// returned by send, in which case the record which could not be sent and the
+// following ones are kept in the buffer.
+func (b *recordBuffer) drain(send func(record flowexporter.FlowRecord) error) error {
+	f, err := os.Open(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			b.size = 0
+			metrics.FlowRecordBufferedCount.Set(0)
+			return nil
+		}
+		return fmt.Errorf("error when opening flow record buffer: %v", err)
+	}
+	var sendErr error
+	var remaining [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), maxRecordLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if sendErr == nil {
+			var record flowexporter.FlowRecord
+			if err := json.Unmarshal(line, &record); err != nil || record.Conn == nil {
+				// The last line may be truncated if the Agent was
+				// stopped while writing it.
+				klog.Errorf("Dropping invalid buffered flow record: %v", err)
+				metrics.FlowRecordDroppedCount.Inc()
+				continue
+			}
+			if sendErr = send(record); sendErr == nil {
+				continue
+			}
+		}
+		remaining = append(remaining, append([]byte(nil), line...))
+	}
+	f.Close()
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error when reading flow record buffer: %v", err)
+	}
+	if err := b.reset(remaining); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// reset replaces the content of the buffer with the provided records.
+func (b *recordBuffer) reset(lines [][]byte) error {
+	if len(lines) == 0 {
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error when removing flow record buffer: %v", err)
+		}
+	} else {
+		var data []byte
+		for _, line := range lines {
+			data = append(data, line...)
+			data = append(data, '\n')
+		}
+		// Write to a temporary file first so that the buffer is not
+		// corrupted if the Agent is stopped in the meantime.
+		tmpPath := b.path + ".tmp"
+		if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+			return fmt.Errorf("error when writing flow record buffer: %v", err)
+		}
+		if err := os.Rename(tmpPath, b.path); err != nil {
+			return fmt.Errorf("error when writing flow record buffer: %v", err)
+		}
+	}
+	b.size = len(lines)
+	metrics.FlowRecordBufferedCount.Set(float64(b.size))
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
+)
+
+func newTestRecord(sourcePort uint16) flowexporter.FlowRecord {
+	return flowexporter.FlowRecord{
+		Conn: &flowexporter.Connection{
+			TupleOrig: flowexporter.Tuple{
+				SourceAddress:      net.ParseIP("10.10.0.1"),
+				DestinationAddress: net.ParseIP("10.10.1.1"),
+				Protocol:           6,
+				SourcePort:         sourcePort,
+				DestinationPort:    80,
+			},
+			OriginalPackets:    10,
+			SourcePodName:      "pod1",
+			SourcePodNamespace: "ns1",
+		},
+		PrevPackets: 5,
+	}
+}
+
+func TestRecordBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flow-exporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buffer", "records")
+
+	b, err := newRecordBuffer(path, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 0, b.len())
+	for _, port := range []uint16{1001, 1002, 1003} {
+		require.NoError(t, b.push(newTestRecord(port)))
+	}
+	assert.Equal(t, errRecordBufferFull, b.push(newTestRecord(1004)))
+
+	// The buffered records are kept when the buffer is re-created, e.g. when
+	// the Agent restarts.
+	b, err = newRecordBuffer(path, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, b.len())
+
+	// The records which cannot be sent are kept in the buffer.
+	var sent []flowexporter.FlowRecord
+	sendErr := errors.New("connection refused")
+	err = b.drain(func(record flowexporter.FlowRecord) error {
+		if len(sent) == 2 {
+			return sendErr
+		}
+		sent = append(sent, record)
+		return nil
+	})
+	assert.Equal(t, sendErr, err)
+	assert.Equal(t, []flowexporter.FlowRecord{newTestRecord(1001), newTestRecord(1002)}, sent)
+	assert.Equal(t, 1, b.len())
+
+	require.NoError(t, b.push(newTestRecord(1004)))
+	sent = nil
+	err = b.drain(func(record flowexporter.FlowRecord) error {
+		sent = append(sent, record)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []flowexporter.FlowRecord{newTestRecord(1003), newTestRecord(1004)}, sent)
+	assert.Equal(t, 0, b.len())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecordBufferInvalidRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flow-exporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "records")
+
+	b, err := newRecordBuffer(path, 3)
+	require.NoError(t, err)
+	require.NoError(t, b.push(newTestRecord(1001)))
+	// Simulate a record which was truncated when the Agent was stopped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("{\"Conn\":{\n"))
+	require.NoError(t, err)
+	f.Close()
+
+	b, err = newRecordBuffer(path, 3)
+	require.NoError(t, err)
+	var sent []flowexporter.FlowRecord
+	err = b.drain(func(record flowexporter.FlowRecord) error {
+		sent = append(sent, record)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []flowexporter.FlowRecord{newTestRecord(1001)}, sent)
+	assert.Equal(t, 0, b.len())
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/flowrecords"
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/ipfix"
 	"github.com/vmware-tanzu/antrea/pkg/util/env"
 )
@@ -92,6 +93,9 @@ type flowExporter struct {
 	v4Enabled       bool
 	v6Enabled       bool
 	collectorAddr   net.Addr
+	// buffer holds the records of the inactive connections while the flow
+	// collector cannot be reached. It is nil if buffering is disabled.
+	buffer *recordBuffer
 }
 
 func genObservationID() (uint32, error) {
@@ -104,9 +108,20 @@ func genObservationID() (uint32, error) {
 	return h.Sum32(), nil
 }
 
-func NewFlowExporter(records *flowrecords.FlowRecords, exportFrequency uint, v4Enabled bool, v6Enabled bool) *flowExporter {
+// NewFlowExporter returns a flow exporter. If bufferSize is positive, up to
+// bufferSize records of inactive connections are buffered in the file at
+// bufferPath while the flow collector cannot be reached, and are sent once the
+// connection to the flow collector is re-established.
+func NewFlowExporter(records *flowrecords.FlowRecords, exportFrequency uint, v4Enabled bool, v6Enabled bool, bufferPath string, bufferSize int) (*flowExporter, error) {
 	registry := ipfix.NewIPFIXRegistry()
 	registry.LoadRegistry()
+	var buffer *recordBuffer
+	if bufferSize > 0 {
+		var err error
+		if buffer, err = newRecordBuffer(bufferPath, bufferSize); err != nil {
+			return nil, err
+		}
+	}
 	return &flowExporter{
 		records,
 		nil,
@@ -120,7 +135,8 @@ func NewFlowExporter(records *flowrecords.FlowRecords, exportFrequency uint, v4E
 		v4Enabled,
 		v6Enabled,
 		nil,
-	}
+		buffer,
+	}, nil
 }
 
 // DoExport enables us to export flow records periodically at a given flow export frequency.
@@ -145,9 +161,18 @@ func (exp *flowExporter) Export(collectorAddr string, collectorProto string, sto
 							exp.process.CloseConnToCollector()
 							exp.process = nil
 						}
+						exp.bufferFlowRecords()
 						return
 					}
 				}
+				// Send the records buffered while the IPFIX collector could not be reached first.
+				if err := exp.drainBuffer(); err != nil {
+					klog.Errorf("Error when sending buffered flow records: %v", err)
+					exp.process.CloseConnToCollector()
+					exp.process = nil
+					exp.bufferFlowRecords()
+					return
+				}
 				// Build and send flow records to IPFIX collector.
 				exp.flowRecords.BuildFlowRecords()
 				err := exp.sendFlowRecords()
@@ -157,6 +182,7 @@ func (exp *flowExporter) Export(collectorAddr string, collectorProto string, sto
 					// to IPFIX collector and retry in the next export cycle to reinitialize the connection and send flow records.
 					exp.process.CloseConnToCollector()
 					exp.process = nil
+					exp.bufferFlowRecords()
 					return
 				}
 
@@ -252,26 +278,33 @@ func (exp *flowExporter) initFlowExporter(collectorAddr string, collectorProto s
 	return nil
 }
 
+func (exp *flowExporter) sendFlowRecord(record flowexporter.FlowRecord) error {
+	if record.IsIPv6 {
+		dataSetIPv6 := ipfix.NewSet(ipfixentities.Data, exp.templateIDv6, false)
+		// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
+		if err := exp.addRecordToSet(dataSetIPv6, record); err != nil {
+			return err
+		}
+		if _, err := exp.sendDataSet(dataSetIPv6); err != nil {
+			return err
+		}
+	} else {
+		dataSetIPv4 := ipfix.NewSet(ipfixentities.Data, exp.templateIDv4, false)
+		// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
+		if err := exp.addRecordToSet(dataSetIPv4, record); err != nil {
+			return err
+		}
+		if _, err := exp.sendDataSet(dataSetIPv4); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (exp *flowExporter) sendFlowRecords() error {
 	addAndSendFlowRecord := func(key flowexporter.ConnectionKey, record flowexporter.FlowRecord) error {
-		if record.IsIPv6 {
-			dataSetIPv6 := ipfix.NewSet(ipfixentities.Data, exp.templateIDv6, false)
-			// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
-			if err := exp.addRecordToSet(dataSetIPv6, record); err != nil {
-				return err
-			}
-			if _, err := exp.sendDataSet(dataSetIPv6); err != nil {
-				return err
-			}
-		} else {
-			dataSetIPv4 := ipfix.NewSet(ipfixentities.Data, exp.templateIDv4, false)
-			// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
-			if err := exp.addRecordToSet(dataSetIPv4, record); err != nil {
-				return err
-			}
-			if _, err := exp.sendDataSet(dataSetIPv4); err != nil {
-				return err
-			}
+		if err := exp.sendFlowRecord(record); err != nil {
+			return err
 		}
 		if err := exp.flowRecords.ValidateAndUpdateStats(key, record); err != nil {
 			return err
@@ -285,6 +318,52 @@ func (exp *flowExporter) sendFlowRecords() error {
 	return nil
 }
 
+// bufferFlowRecords moves the records of the inactive connections to the buffer
+// when they cannot be sent to the IPFIX collector. The records of the active
+// connections are kept in memory, and their stats are sent once the connection
+// to the IPFIX collector is re-established. Records are dropped if the buffer
+// is full.
+func (exp *flowExporter) bufferFlowRecords() {
+	if exp.buffer == nil {
+		return
+	}
+	exp.flowRecords.BuildFlowRecords()
+	buffered, dropped := 0, 0
+	bufferFlowRecord := func(key flowexporter.ConnectionKey, record flowexporter.FlowRecord) error {
+		if record.Conn.IsActive {
+			return nil
+		}
+		if err := exp.buffer.push(record); err != nil {
+			if err != errRecordBufferFull {
+				klog.Errorf("Error when buffering flow record: %v", err)
+			}
+			metrics.FlowRecordDroppedCount.Inc()
+			dropped++
+		} else {
+			buffered++
+		}
+		// The record and its connection are deleted as the connection is
+		// inactive.
+		return exp.flowRecords.ValidateAndUpdateStats(key, record)
+	}
+	if err := exp.flowRecords.ForAllFlowRecordsDo(bufferFlowRecord); err != nil {
+		klog.Errorf("Error when buffering flow records: %v", err)
+	}
+	if dropped > 0 {
+		klog.Warningf("Dropped %d flow records which could not be buffered", dropped)
+	}
+	klog.V(2).Infof("Buffered %d flow records, %d flow records in buffer", buffered, exp.buffer.len())
+}
+
+// drainBuffer sends the records in the buffer to the IPFIX collector.
+func (exp *flowExporter) drainBuffer() error {
+	if exp.buffer == nil || exp.buffer.len() == 0 {
+		return nil
+	}
+	klog.Infof("Sending %d buffered flow records", exp.buffer.len())
+	return exp.buffer.drain(exp.sendFlowRecord)
+}
+
 func (exp *flowExporter) sendTemplateSet(templateSet ipfix.IPFIXSet, isIPv6 bool) (int, error) {
 	elements := make([]*ipfixentities.InfoElementWithValue, 0)
 
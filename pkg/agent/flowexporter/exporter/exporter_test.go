@@ -66,6 +66,7 @@ func testFlowExporter_sendTemplateSet(t *testing.T, v4Enabled bool, v6Enabled bo
 		v4Enabled,
 		v6Enabled,
 		nil,
+		nil,
 	}
 
 	if v4Enabled {
@@ -171,6 +172,7 @@ func testFlowExporter_sendDataSet(t *testing.T, v4Enabled bool, v6Enabled bool)
 		v4Enabled,
 		v6Enabled,
 		nil,
+		nil,
 	}
 
 	sendDataSet := func(elemList []*ipfixentities.InfoElementWithValue, templateID uint16, record flowexporter.FlowRecord) {
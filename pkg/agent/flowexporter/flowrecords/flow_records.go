@@ -65,6 +65,13 @@ func (fr *FlowRecords) BuildFlowRecords() error {
 		return nil
 	}
 
+	// Remove the records of the connections which are no longer in the connection store, i.e. the connections which
+	// were removed from it while conntrack polling was paused and are no longer active.
+	for key := range fr.recordsMap {
+		if _, exists := fr.connStore.GetConnByKey(key); !exists {
+			delete(fr.recordsMap, key)
+		}
+	}
 	// addOrUpdateFlowRecord method does not return any error, hence no error handling required.
 	fr.connStore.ForAllConnectionsDo(addOrUpdateFlowRecord)
 	klog.V(2).Infof("No. of flow records built: %d", len(fr.recordsMap))
@@ -0,0 +1,184 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flowlimit detects when the number of OVS flows of the Node gets
+// close to the configured maximum, so that operators are warned and, if
+// enabled, no new Pods are scheduled on the Node before flows start failing to
+// be installed.
+package flowlimit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+)
+
+const (
+	// TaintKey is the key of the NoSchedule taint set on the Node while the
+	// flow limit is exceeded, when cordoning is enabled.
+	TaintKey = "node.antrea.io/flow-limit-exceeded"
+
+	// checkInterval is the interval at which the number of flows is evaluated.
+	checkInterval = 10 * time.Second
+	// enterRatio is the ratio of the maximum number of flows above which the
+	// flow limit is considered exceeded.
+	enterRatio = 0.9
+	// exitRatio is the ratio of the maximum number of flows below which the
+	// flow limit is no longer considered exceeded. It is lower than
+	// enterRatio to avoid flapping.
+	exitRatio = 0.8
+)
+
+// flowCountFunc returns the number of flows installed on the OVS bridge.
+type flowCountFunc func() int
+
+// Monitor periodically compares the number of OVS flows of the Node with the
+// configured maximum. When the number of flows exceeds enterRatio of the
+// maximum, a warning is logged, the flow limit metric is set and, if cordoning
+// is enabled, the Node is tainted so that no new Pods are scheduled on it. The
+// state is cleared when the number of flows goes back below exitRatio of the
+// maximum.
+type Monitor struct {
+	kubeClient   clientset.Interface
+	nodeName     string
+	maxFlows     int
+	cordon       bool
+	getFlowCount flowCountFunc
+
+	mutex    sync.RWMutex
+	exceeded bool
+	// tainted is whether the taint is known to be set on the Node. It is
+	// only accessed by check.
+	tainted bool
+}
+
+// NewMonitor creates a Monitor for the provided maximum number of flows.
+// getFlowCount returns the current number of flows of the OVS bridge.
+func NewMonitor(kubeClient clientset.Interface, nodeName string, maxFlows int, cordon bool, getFlowCount func() int) *Monitor {
+	return &Monitor{
+		kubeClient:   kubeClient,
+		nodeName:     nodeName,
+		maxFlows:     maxFlows,
+		cordon:       cordon,
+		getFlowCount: getFlowCount,
+	}
+}
+
+// IsExceeded returns whether the number of flows is currently considered to
+// exceed the flow limit.
+func (m *Monitor) IsExceeded() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.exceeded
+}
+
+// Run evaluates the number of flows periodically until stopCh is closed.
+func (m *Monitor) Run(stopCh <-chan struct{}) {
+	klog.Infof("Starting flow limit monitor with maximum %d flows", m.maxFlows)
+	metrics.OVSFlowLimit.Set(float64(m.maxFlows))
+	// Clear any taint left by a previous run, it will be set again if the
+	// flow limit is still exceeded.
+	m.tainted = true
+	wait.Until(m.check, checkInterval, stopCh)
+}
+
+func (m *Monitor) check() {
+	count := m.getFlowCount()
+	m.mutex.Lock()
+	exceeded := m.exceeded
+	if !exceeded && float64(count) > enterRatio*float64(m.maxFlows) {
+		exceeded = true
+	} else if exceeded && float64(count) < exitRatio*float64(m.maxFlows) {
+		exceeded = false
+	}
+	changed := exceeded != m.exceeded
+	m.exceeded = exceeded
+	m.mutex.Unlock()
+
+	if changed {
+		if exceeded {
+			klog.Warningf("Number of OVS flows %d is close to the maximum %d", count, m.maxFlows)
+			metrics.OVSFlowLimitExceeded.Set(1)
+		} else {
+			klog.Infof("Number of OVS flows %d is back under the maximum %d", count, m.maxFlows)
+			metrics.OVSFlowLimitExceeded.Set(0)
+		}
+	}
+	shouldTaint := m.cordon && exceeded
+	if shouldTaint == m.tainted {
+		return
+	}
+	// The taint is retried at the next check if it cannot be updated.
+	if err := m.setTaint(shouldTaint); err != nil {
+		klog.Errorf("Failed to update taint %s of Node %s: %v", TaintKey, m.nodeName, err)
+		return
+	}
+	m.tainted = shouldTaint
+}
+
+// setTaint adds or removes the flow limit taint of the Node.
+func (m *Monitor) setTaint(taint bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := m.kubeClient.CoreV1().Nodes().Get(context.TODO(), m.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		taints := make([]corev1.Taint, 0, len(node.Spec.Taints)+1)
+		found := false
+		for _, t := range node.Spec.Taints {
+			if t.Key == TaintKey {
+				found = true
+				if !taint {
+					continue
+				}
+			}
+			taints = append(taints, t)
+		}
+		if found == taint {
+			return nil
+		}
+		if taint {
+			taints = append(taints, corev1.Taint{Key: TaintKey, Effect: corev1.TaintEffectNoSchedule})
+		}
+		// The resourceVersion makes the patch fail with a conflict if the
+		// taints have been updated concurrently, as the whole list is replaced.
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"resourceVersion": node.ResourceVersion},
+			"spec":     map[string]interface{}{"taints": taints},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := m.kubeClient.CoreV1().Nodes().Patch(context.TODO(), m.nodeName, apitypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+		if taint {
+			klog.Warningf("Tainted Node %s with %s to prevent new Pods from being scheduled", m.nodeName, TaintKey)
+		} else {
+			klog.Infof("Removed taint %s from Node %s", TaintKey, m.nodeName)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowlimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheck(t *testing.T) {
+	otherTaint := corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoExecute}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{otherTaint}},
+	}
+	kubeClient := fake.NewSimpleClientset(node)
+	var count int
+	m := NewMonitor(kubeClient, "node1", 100, true, func() int { return count })
+
+	getTaints := func() []corev1.Taint {
+		node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+		require.NoError(t, err)
+		return node.Spec.Taints
+	}
+	flowLimitTaint := corev1.Taint{Key: TaintKey, Effect: corev1.TaintEffectNoSchedule}
+
+	steps := []struct {
+		count    int
+		exceeded bool
+	}{
+		{50, false},
+		{91, true},
+		// The flow limit stays exceeded until the number of flows goes below exitRatio.
+		{85, true},
+		{79, false},
+	}
+	for i, step := range steps {
+		count = step.count
+		m.check()
+		assert.Equal(t, step.exceeded, m.IsExceeded(), "Unexpected state at step %d", i)
+		if step.exceeded {
+			assert.Equal(t, []corev1.Taint{otherTaint, flowLimitTaint}, getTaints(), "Unexpected taints at step %d", i)
+		} else {
+			assert.Equal(t, []corev1.Taint{otherTaint}, getTaints(), "Unexpected taints at step %d", i)
+		}
+	}
+}
+
+func TestCheckWithoutCordon(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	kubeClient := fake.NewSimpleClientset(node)
+	m := NewMonitor(kubeClient, "node1", 100, false, func() int { return 95 })
+	m.check()
+	assert.True(t, m.IsExceeded())
+	assert.Empty(t, kubeClient.Actions())
+}
@@ -15,6 +15,7 @@
 package interfacestore
 
 import (
+	"strconv"
 	"sync"
 
 	"k8s.io/client-go/tools/cache"
@@ -40,6 +41,9 @@ const (
 	// interfaceIPIndex is the index built with InterfaceConfig.IP
 	// Only the interfaces with IP get indexed.
 	interfaceIPIndex = "ip"
+	// ofPortIndex is the index built with InterfaceConfig.OFPort.
+	// Only the interfaces with an OVS port get indexed.
+	ofPortIndex = "ofPort"
 )
 
 // Local cache for interfaces created on node, including container, host gateway, and tunnel
@@ -148,6 +152,18 @@ func (c *interfaceCache) GetInterfaceByIP(interfaceIP string) (*InterfaceConfig,
 	return interfaceConfigs[0].(*InterfaceConfig), true
 }
 
+// GetInterfaceByOFPort retrieves interface from local cache given the OVS port
+// number.
+func (c *interfaceCache) GetInterfaceByOFPort(ofPort uint32) (*InterfaceConfig, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	interfaceConfigs, _ := c.cache.ByIndex(ofPortIndex, strconv.FormatUint(uint64(ofPort), 10))
+	if len(interfaceConfigs) == 0 {
+		return nil, false
+	}
+	return interfaceConfigs[0].(*InterfaceConfig), true
+}
+
 func (c *interfaceCache) GetContainerInterfaceNum() int {
 	c.RLock()
 	defer c.RUnlock()
@@ -260,6 +276,15 @@ func interfaceIPIndexFunc(obj interface{}) ([]string, error) {
 	return intfIPs, nil
 }
 
+func ofPortIndexFunc(obj interface{}) ([]string, error) {
+	interfaceConfig := obj.(*InterfaceConfig)
+	if interfaceConfig.OVSPortConfig == nil || interfaceConfig.OFPort < 0 {
+		// If the interface is not attached to OVS yet, we return empty key.
+		return []string{}, nil
+	}
+	return []string{strconv.Itoa(int(interfaceConfig.OFPort))}, nil
+}
+
 func NewInterfaceStore() InterfaceStore {
 	return &interfaceCache{
 		cache: cache.NewIndexer(getInterfaceKey, cache.Indexers{
@@ -268,6 +293,7 @@ func NewInterfaceStore() InterfaceStore {
 			containerIDIndex:   containerIDIndexFunc,
 			podIndex:           podIndexFunc,
 			interfaceIPIndex:   interfaceIPIndexFunc,
+			ofPortIndex:        ofPortIndexFunc,
 		}),
 	}
 }
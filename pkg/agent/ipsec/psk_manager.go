@@ -0,0 +1,286 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	ipsecutil "github.com/vmware-tanzu/antrea/pkg/util/ipsec"
+)
+
+const (
+	// DefaultPSKFile is the path at which the PSK of the antrea-ipsec Secret is
+	// mounted in the antrea-agent container. Unlike environment variables,
+	// Secret volumes are updated by the kubelet when the Secret is updated.
+	DefaultPSKFile = "/etc/antrea/ipsec/psk"
+	// pskSyncInterval is the interval at which the PSK file is read and the
+	// rotation status is evaluated.
+	pskSyncInterval = 10 * time.Second
+)
+
+// PSKManager keeps track of the IPsec PSK and coordinates PSK rotations with
+// the other Nodes. When the PSK is rotated, the previous PSK is kept and used
+// for the tunnels to the Nodes which have not loaded the new PSK yet, so that
+// both ends of a tunnel always switch to the new PSK together. The IDs of the
+// loaded PSKs are advertised through an annotation of the Node, and the
+// previous PSK is discarded once all the other Nodes have loaded the new one,
+// and no tunnel port uses it anymore, so that the tunnels are switched to the
+// new PSK before the previous one is removed.
+type PSKManager struct {
+	kubeClient       clientset.Interface
+	nodeName         string
+	nodeLister       corelisters.NodeLister
+	nodeListerSynced cache.InformerSynced
+	interfaceStore   interfacestore.InterfaceStore
+	pskFile          string
+
+	mutex       sync.RWMutex
+	currentPSK  string
+	previousPSK string
+	// pendingNodes is the list of Nodes which have not loaded the current PSK.
+	pendingNodes []string
+	// lastRotationTime is the time at which the current PSK was loaded, after
+	// the previous one was rotated.
+	lastRotationTime time.Time
+	// advertisedPSKIDs is the value of the PSK IDs annotation last set on the
+	// Node.
+	advertisedPSKIDs string
+	// handlers are called when the PSK to use for some Nodes may have changed.
+	handlers []func()
+	// notifyPending indicates that the PSK has been rotated but the handlers
+	// have not been called yet. It is only accessed by sync.
+	notifyPending bool
+}
+
+// NewPSKManager creates a PSKManager. psk is the PSK used when the agent
+// starts, and pskFile is the path of the file to watch for PSK rotations.
+func NewPSKManager(
+	kubeClient clientset.Interface,
+	nodeInformer coreinformers.NodeInformer,
+	interfaceStore interfacestore.InterfaceStore,
+	nodeName string,
+	pskFile string,
+	psk string) *PSKManager {
+	return &PSKManager{
+		kubeClient:       kubeClient,
+		nodeName:         nodeName,
+		nodeLister:       nodeInformer.Lister(),
+		nodeListerSynced: nodeInformer.Informer().HasSynced,
+		interfaceStore:   interfaceStore,
+		pskFile:          pskFile,
+		currentPSK:       psk,
+	}
+}
+
+// AddEventHandler registers a handler which is called after the PSK has been
+// rotated.
+func (m *PSKManager) AddEventHandler(handler func()) {
+	m.handlers = append(m.handlers, handler)
+}
+
+// GetPSK returns the current PSK.
+func (m *PSKManager) GetPSK() string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.currentPSK
+}
+
+// GetPSKForNode returns the PSK to use for the tunnel to the provided Node. The
+// previous PSK is used as long as the Node has not loaded the current one, and
+// the Node is expected to make the same decision as it has loaded both of them.
+func (m *PSKManager) GetPSKForNode(node *corev1.Node) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.previousPSK == "" {
+		return m.currentPSK
+	}
+	currentID, previousID := ipsecutil.PSKID(m.currentPSK), ipsecutil.PSKID(m.previousPSK)
+	var hasPrevious bool
+	for _, id := range ipsecutil.GetNodePSKIDs(node) {
+		if id == currentID {
+			return m.currentPSK
+		}
+		if id == previousID {
+			hasPrevious = true
+		}
+	}
+	if hasPrevious {
+		return m.previousPSK
+	}
+	return m.currentPSK
+}
+
+// GetRotationStatus returns whether a PSK rotation is in progress, the Nodes
+// which have not loaded the current PSK, and the time of the last rotation.
+func (m *PSKManager) GetRotationStatus() (bool, []string, time.Time) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.previousPSK != "", m.pendingNodes, m.lastRotationTime
+}
+
+// Run starts watching the PSK file and advertising the loaded PSKs until
+// stopCh is closed.
+func (m *PSKManager) Run(stopCh <-chan struct{}) {
+	klog.Info("Starting IPsec PSK manager")
+	defer klog.Info("Shutting down IPsec PSK manager")
+
+	if !cache.WaitForNamedCacheSync("IPsecPSKManager", stopCh, m.nodeListerSynced) {
+		return
+	}
+	wait.Until(m.sync, pskSyncInterval, stopCh)
+}
+
+func (m *PSKManager) sync() {
+	rotated, err := m.loadPSK()
+	if err != nil {
+		klog.Errorf("Failed to read IPsec PSK from %s: %v", m.pskFile, err)
+	}
+	if rotated {
+		m.notifyPending = true
+	}
+	if err := m.updateRotationStatus(); err != nil {
+		klog.Errorf("Failed to update IPsec PSK rotation status: %v", err)
+	}
+	// The handlers are only called once the new PSK has been advertised, otherwise
+	// the other Nodes may keep using the previous PSK for this Node while this Node
+	// switches to the new one.
+	if err := m.advertisePSKIDs(); err != nil {
+		klog.Errorf("Failed to advertise IPsec PSKs: %v", err)
+		return
+	}
+	if m.notifyPending {
+		for _, handler := range m.handlers {
+			handler()
+		}
+		m.notifyPending = false
+	}
+}
+
+// loadPSK reads the PSK file and returns whether the PSK has been rotated. The
+// PSK is never rotated if the file does not exist.
+func (m *PSKManager) loadPSK() (bool, error) {
+	data, err := ioutil.ReadFile(m.pskFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	psk := strings.TrimSpace(string(data))
+	if psk == "" {
+		return false, fmt.Errorf("PSK file is empty")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if psk == m.currentPSK {
+		return false, nil
+	}
+	klog.Infof("IPsec PSK has been rotated, PSK ID: %s", ipsecutil.PSKID(psk))
+	m.previousPSK = m.currentPSK
+	m.currentPSK = psk
+	m.lastRotationTime = time.Now()
+	return true, nil
+}
+
+// updateRotationStatus computes the list of Nodes which have not loaded the
+// current PSK, and discards the previous PSK if there is none and no tunnel port
+// uses it anymore. The Nodes which do not advertise any PSK, e.g. because IPsec
+// is not supported by the agent running on them, are ignored as the current PSK
+// is always used for them.
+func (m *PSKManager) updateRotationStatus() error {
+	nodes, err := m.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error when listing Nodes: %v", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	currentID := ipsecutil.PSKID(m.currentPSK)
+	var pendingNodes []string
+	for _, node := range nodes {
+		if node.Name == m.nodeName || ipsecutil.GetNodePSKIDs(node) == nil {
+			continue
+		}
+		if !ipsecutil.HasCurrentPSK(node, currentID) {
+			pendingNodes = append(pendingNodes, node.Name)
+		}
+	}
+	m.pendingNodes = pendingNodes
+	if len(pendingNodes) == 0 && m.previousPSK != "" {
+		if m.isPSKInUse(m.previousPSK) {
+			klog.Info("All Nodes have loaded the current IPsec PSK, waiting for the tunnel ports to be updated")
+			return nil
+		}
+		klog.Info("All Nodes have loaded the current IPsec PSK, discarding the previous one")
+		m.previousPSK = ""
+	}
+	return nil
+}
+
+// isPSKInUse returns whether a tunnel port uses the provided PSK.
+func (m *PSKManager) isPSKInUse(psk string) bool {
+	for _, interfaceConfig := range m.interfaceStore.GetInterfacesByType(interfacestore.TunnelInterface) {
+		if interfaceConfig.PSK == psk {
+			return true
+		}
+	}
+	return false
+}
+
+// advertisePSKIDs updates the PSK IDs annotation of the Node if the loaded PSKs
+// have changed.
+func (m *PSKManager) advertisePSKIDs() error {
+	m.mutex.RLock()
+	ids := []string{ipsecutil.PSKID(m.currentPSK)}
+	if m.previousPSK != "" {
+		ids = append(ids, ipsecutil.PSKID(m.previousPSK))
+	}
+	advertisedPSKIDs := m.advertisedPSKIDs
+	m.mutex.RUnlock()
+
+	value := strings.Join(ids, ",")
+	if value == advertisedPSKIDs {
+		return nil
+	}
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, ipsecutil.PSKIDsAnnotationKey, value)
+	if _, err := m.kubeClient.CoreV1().Nodes().Patch(context.TODO(), m.nodeName, apitypes.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to update IPsec PSK IDs annotation of Node %s: %v", m.nodeName, err)
+	}
+	klog.Infof("Updated IPsec PSK IDs of Node %s to \"%s\"", m.nodeName, value)
+
+	m.mutex.Lock()
+	m.advertisedPSKIDs = value
+	m.mutex.Unlock()
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+	ipsecutil "github.com/vmware-tanzu/antrea/pkg/util/ipsec"
+)
+
+func newNode(name string, pskIDs string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if pskIDs != "" {
+		node.Annotations = map[string]string{ipsecutil.PSKIDsAnnotationKey: pskIDs}
+	}
+	return node
+}
+
+func newFakePSKManager(t *testing.T, pskFile string, psk string, nodes ...*corev1.Node) (*PSKManager, *fake.Clientset, cache.Indexer, interfacestore.InterfaceStore) {
+	var objs []runtime.Object
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	client := fake.NewSimpleClientset(objs...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	nodeIndexer := nodeInformer.Informer().GetIndexer()
+	for _, node := range nodes {
+		require.NoError(t, nodeIndexer.Add(node))
+	}
+	ifaceStore := interfacestore.NewInterfaceStore()
+	return NewPSKManager(client, nodeInformer, ifaceStore, "node1", pskFile, psk), client, nodeIndexer, ifaceStore
+}
+
+func TestGetPSKForNode(t *testing.T) {
+	oldID, newID := ipsecutil.PSKID("old"), ipsecutil.PSKID("new")
+	tests := []struct {
+		name        string
+		previousPSK string
+		peerPSKIDs  string
+		expected    string
+	}{
+		{
+			name:       "no rotation",
+			peerPSKIDs: newID,
+			expected:   "new",
+		},
+		{
+			name:        "peer has not loaded the new PSK",
+			previousPSK: "old",
+			peerPSKIDs:  oldID,
+			expected:    "old",
+		},
+		{
+			name:        "peer has loaded the new PSK",
+			previousPSK: "old",
+			peerPSKIDs:  newID + "," + oldID,
+			expected:    "new",
+		},
+		{
+			name:        "peer does not advertise PSKs",
+			previousPSK: "old",
+			expected:    "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, _, _ := newFakePSKManager(t, "", "new")
+			m.previousPSK = tt.previousPSK
+			assert.Equal(t, tt.expected, m.GetPSKForNode(newNode("node2", tt.peerPSKIDs)))
+		})
+	}
+}
+
+func TestPSKRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "antrea-ipsec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pskFile := filepath.Join(dir, "psk")
+	require.NoError(t, ioutil.WriteFile(pskFile, []byte("old"), 0600))
+
+	oldID, newID := ipsecutil.PSKID("old"), ipsecutil.PSKID("new")
+	// node3 does not advertise any PSK and is ignored.
+	m, client, nodeIndexer, ifaceStore := newFakePSKManager(t, pskFile, "old", newNode("node1", ""), newNode("node2", oldID), newNode("node3", ""))
+	ifaceStore.AddInterface(interfacestore.NewIPSecTunnelInterface("node2-a1b2c3", ovsconfig.GeneveTunnel, "node2", net.ParseIP("10.0.0.2"), "old"))
+	notified := 0
+	m.AddEventHandler(func() { notified++ })
+	getAdvertisedPSKIDs := func() string {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+		require.NoError(t, err)
+		return node.Annotations[ipsecutil.PSKIDsAnnotationKey]
+	}
+
+	m.sync()
+	assert.Equal(t, oldID, getAdvertisedPSKIDs())
+	assert.Equal(t, 0, notified)
+	inProgress, pendingNodes, _ := m.GetRotationStatus()
+	assert.False(t, inProgress)
+	assert.Empty(t, pendingNodes)
+
+	// The PSK is rotated, the previous PSK must be kept until node2 loads the new PSK.
+	require.NoError(t, ioutil.WriteFile(pskFile, []byte("new\n"), 0600))
+	m.sync()
+	assert.Equal(t, newID+","+oldID, getAdvertisedPSKIDs())
+	assert.Equal(t, 1, notified)
+	assert.Equal(t, "new", m.GetPSK())
+	inProgress, pendingNodes, _ = m.GetRotationStatus()
+	assert.True(t, inProgress)
+	assert.Equal(t, []string{"node2"}, pendingNodes)
+
+	// node2 loads the new PSK, the previous PSK must be kept until the tunnel port to node2 uses the new PSK.
+	require.NoError(t, nodeIndexer.Update(newNode("node2", newID+","+oldID)))
+	m.sync()
+	assert.Equal(t, newID+","+oldID, getAdvertisedPSKIDs())
+	inProgress, pendingNodes, _ = m.GetRotationStatus()
+	assert.True(t, inProgress)
+	assert.Empty(t, pendingNodes)
+
+	// The tunnel port to node2 uses the new PSK, the previous PSK can be discarded.
+	ifaceStore.AddInterface(interfacestore.NewIPSecTunnelInterface("node2-a1b2c3", ovsconfig.GeneveTunnel, "node2", net.ParseIP("10.0.0.2"), "new"))
+	m.sync()
+	assert.Equal(t, newID, getAdvertisedPSKIDs())
+	assert.Equal(t, 1, notified)
+	inProgress, pendingNodes, _ = m.GetRotationStatus()
+	assert.False(t, inProgress)
+	assert.Empty(t, pendingNodes)
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"net"
+	"time"
+)
+
+// SecurityAssociation is an ESP Security Association (SA) installed in the
+// kernel by the IKE daemon for an IPsec tunnel. An SA protects the traffic in
+// one direction, from Src to Dst.
+type SecurityAssociation struct {
+	Src net.IP
+	Dst net.IP
+	SPI uint32
+	// AddTime is the time at which the SA was installed, which is the time of
+	// the last rekey for the SAs installed by a rekey. It is zero if unknown.
+	AddTime time.Time
+}
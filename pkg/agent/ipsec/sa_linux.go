@@ -0,0 +1,44 @@
+// +build linux
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// ListSecurityAssociations returns the ESP SAs installed in the kernel.
+func ListSecurityAssociations() ([]SecurityAssociation, error) {
+	states, err := netlink.XfrmStateList(netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list xfrm states: %v", err)
+	}
+	var sas []SecurityAssociation
+	for _, state := range states {
+		if state.Proto != netlink.XFRM_PROTO_ESP {
+			continue
+		}
+		sa := SecurityAssociation{Src: state.Src, Dst: state.Dst, SPI: uint32(state.Spi)}
+		if state.Statistics.AddTime > 0 {
+			sa.AddTime = time.Unix(int64(state.Statistics.AddTime), 0)
+		}
+		sas = append(sas, sa)
+	}
+	return sas, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorypressure
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// cgroupRoot is where the cgroup filesystem of the container is mounted.
+	cgroupRoot = "/sys/fs/cgroup"
+	// cgroupV1UnlimitedThreshold is used to detect cgroup v1 memory limits
+	// which are not set: the kernel reports the maximum page-aligned int64.
+	cgroupV1UnlimitedThreshold = uint64(1) << 62
+)
+
+// getCgroupMemoryStats returns the working set, i.e. the memory usage minus
+// the inactive file cache which can be reclaimed without OOM, and the memory
+// limit of the cgroup of the container, for both cgroup v1 and v2. This is
+// consistent with the working set used by the kubelet for evictions.
+func getCgroupMemoryStats() (uint64, uint64, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "memory.max")); err == nil {
+		return readMemoryStats(cgroupRoot, "memory.current", "memory.max", "inactive_file")
+	}
+	return readMemoryStats(filepath.Join(cgroupRoot, "memory"), "memory.usage_in_bytes", "memory.limit_in_bytes", "total_inactive_file")
+}
+
+func readMemoryStats(dir, usageFile, limitFile, inactiveFileKey string) (uint64, uint64, error) {
+	usage, err := readUint(filepath.Join(dir, usageFile))
+	if err != nil {
+		return 0, 0, err
+	}
+	var limit uint64
+	limitStr, err := readString(filepath.Join(dir, limitFile))
+	if err != nil {
+		return 0, 0, err
+	}
+	if limitStr != "max" {
+		if limit, err = strconv.ParseUint(limitStr, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid memory limit %q: %v", limitStr, err)
+		}
+		if limit >= cgroupV1UnlimitedThreshold {
+			limit = 0
+		}
+	}
+	inactiveFile, err := readStat(filepath.Join(dir, "memory.stat"), inactiveFileKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	workingSet := uint64(0)
+	if usage > inactiveFile {
+		workingSet = usage - inactiveFile
+	}
+	return workingSet, limit, nil
+}
+
+func readString(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func readUint(path string) (uint64, error) {
+	s, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s: %v", s, path, err)
+	}
+	return v, nil
+}
+
+// readStat returns the value of the provided key in a memory.stat file, or 0
+// if the key is not present.
+func readStat(path, key string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != key {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q for %s in %s: %v", fields[1], key, path, err)
+		}
+		return v, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorypressure
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMemoryStats(t *testing.T) {
+	tests := []struct {
+		name               string
+		files              map[string]string
+		usageFile          string
+		limitFile          string
+		inactiveFileKey    string
+		expectedWorkingSet uint64
+		expectedLimit      uint64
+	}{
+		{
+			name: "cgroup v1",
+			files: map[string]string{
+				"memory.usage_in_bytes": "1000\n",
+				"memory.limit_in_bytes": "2000\n",
+				"memory.stat":           "cache 300\ntotal_inactive_file 200\n",
+			},
+			usageFile:          "memory.usage_in_bytes",
+			limitFile:          "memory.limit_in_bytes",
+			inactiveFileKey:    "total_inactive_file",
+			expectedWorkingSet: 800,
+			expectedLimit:      2000,
+		},
+		{
+			name: "cgroup v1 unlimited",
+			files: map[string]string{
+				"memory.usage_in_bytes": "1000\n",
+				"memory.limit_in_bytes": "9223372036854771712\n",
+				"memory.stat":           "total_inactive_file 200\n",
+			},
+			usageFile:          "memory.usage_in_bytes",
+			limitFile:          "memory.limit_in_bytes",
+			inactiveFileKey:    "total_inactive_file",
+			expectedWorkingSet: 800,
+			expectedLimit:      0,
+		},
+		{
+			name: "cgroup v2",
+			files: map[string]string{
+				"memory.current": "1000\n",
+				"memory.max":     "4000\n",
+				"memory.stat":    "anon 600\ninactive_file 100\n",
+			},
+			usageFile:          "memory.current",
+			limitFile:          "memory.max",
+			inactiveFileKey:    "inactive_file",
+			expectedWorkingSet: 900,
+			expectedLimit:      4000,
+		},
+		{
+			name: "cgroup v2 unlimited",
+			files: map[string]string{
+				"memory.current": "1000\n",
+				"memory.max":     "max\n",
+				"memory.stat":    "anon 600\n",
+			},
+			usageFile:          "memory.current",
+			limitFile:          "memory.max",
+			inactiveFileKey:    "inactive_file",
+			expectedWorkingSet: 1000,
+			expectedLimit:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "memorypressure")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			for name, content := range tt.files {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+			workingSet, limit, err := readMemoryStats(dir, tt.usageFile, tt.limitFile, tt.inactiveFileKey)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWorkingSet, workingSet)
+			assert.Equal(t, tt.expectedLimit, limit)
+		})
+	}
+}
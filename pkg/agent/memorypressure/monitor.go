@@ -0,0 +1,132 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memorypressure detects when antrea-agent gets close to the memory
+// limit of its cgroup, so that non-essential features can be paused instead
+// of the agent being OOM-killed and disturbing the datapath.
+package memorypressure
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	// checkInterval is the interval at which the memory usage is evaluated.
+	checkInterval = 10 * time.Second
+	// enterRatio is the ratio of the memory limit above which the agent
+	// enters the memory pressure mode.
+	enterRatio = 0.9
+	// exitRatio is the ratio of the memory limit below which the agent exits
+	// the memory pressure mode. It is lower than enterRatio to avoid flapping.
+	exitRatio = 0.8
+)
+
+// memoryStatsFunc returns the working set of the cgroup of the agent and its
+// memory limit. limit is 0 if the cgroup has no memory limit.
+type memoryStatsFunc func() (workingSet, limit uint64, err error)
+
+// Monitor periodically compares the memory working set of antrea-agent with
+// the memory limit of its cgroup. When the working set exceeds enterRatio of
+// the limit, the agent enters the memory pressure mode: the registered
+// handlers are called so that they can pause non-essential work, and the Go
+// runtime is asked to return freed memory to the OS. The agent exits the mode
+// when the working set goes back below exitRatio of the limit.
+type Monitor struct {
+	getMemoryStats memoryStatsFunc
+
+	mutex         sync.RWMutex
+	underPressure bool
+	workingSet    uint64
+	limit         uint64
+	// lastTransitionTime is the time at which the agent last entered or
+	// exited the memory pressure mode.
+	lastTransitionTime time.Time
+	// handlers are called with the new state when the agent enters or exits
+	// the memory pressure mode.
+	handlers []func(underPressure bool)
+}
+
+// NewMonitor creates a Monitor for the cgroup of the current process.
+func NewMonitor() *Monitor {
+	return &Monitor{getMemoryStats: getCgroupMemoryStats}
+}
+
+// AddEventHandler registers a handler which is called with the new state when
+// the agent enters or exits the memory pressure mode. It must be called before
+// Run.
+func (m *Monitor) AddEventHandler(handler func(underPressure bool)) {
+	m.handlers = append(m.handlers, handler)
+}
+
+// GetStatus returns whether the agent is under memory pressure, its last
+// memory working set and limit, and the time of the last transition. limit is
+// 0 if the memory limit of the agent is unknown, in which case the agent never
+// enters the memory pressure mode.
+func (m *Monitor) GetStatus() (underPressure bool, workingSet, limit uint64, lastTransitionTime time.Time) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.underPressure, m.workingSet, m.limit, m.lastTransitionTime
+}
+
+// Run evaluates the memory usage periodically until stopCh is closed.
+func (m *Monitor) Run(stopCh <-chan struct{}) {
+	klog.Info("Starting memory pressure monitor")
+	wait.Until(m.check, checkInterval, stopCh)
+}
+
+func (m *Monitor) check() {
+	workingSet, limit, err := m.getMemoryStats()
+	if err != nil {
+		klog.Errorf("Failed to get memory usage: %v", err)
+		return
+	}
+	m.mutex.Lock()
+	m.workingSet, m.limit = workingSet, limit
+	underPressure := m.underPressure
+	if limit == 0 {
+		underPressure = false
+	} else if !underPressure && float64(workingSet) > enterRatio*float64(limit) {
+		underPressure = true
+	} else if underPressure && float64(workingSet) < exitRatio*float64(limit) {
+		underPressure = false
+	}
+	changed := underPressure != m.underPressure
+	if changed {
+		m.underPressure = underPressure
+		m.lastTransitionTime = time.Now()
+	}
+	m.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+	if underPressure {
+		klog.Warningf("Memory working set %d bytes is close to the limit %d bytes, pausing non-essential features", workingSet, limit)
+	} else {
+		klog.Infof("Memory working set %d bytes is back under control, resuming paused features", workingSet)
+	}
+	for _, handler := range m.handlers {
+		handler(underPressure)
+	}
+	if underPressure {
+		// Release the memory freed by the handlers, and any other unused
+		// memory, instead of waiting for the scavenger.
+		debug.FreeOSMemory()
+	}
+}
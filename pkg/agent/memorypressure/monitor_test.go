@@ -0,0 +1,57 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorypressure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	var workingSet, limit uint64
+	m := &Monitor{getMemoryStats: func() (uint64, uint64, error) {
+		return workingSet, limit, nil
+	}}
+	var events []bool
+	m.AddEventHandler(func(underPressure bool) {
+		events = append(events, underPressure)
+	})
+
+	steps := []struct {
+		workingSet    uint64
+		limit         uint64
+		underPressure bool
+	}{
+		{50, 0, false},
+		{80, 100, false},
+		{91, 100, true},
+		// The agent stays under pressure until the working set goes below exitRatio.
+		{85, 100, true},
+		{79, 100, false},
+		{95, 100, true},
+		// Losing the limit exits the memory pressure mode.
+		{95, 0, false},
+	}
+	for i, step := range steps {
+		workingSet, limit = step.workingSet, step.limit
+		m.check()
+		underPressure, gotWorkingSet, gotLimit, _ := m.GetStatus()
+		assert.Equal(t, step.underPressure, underPressure, "Unexpected state at step %d", i)
+		assert.Equal(t, step.workingSet, gotWorkingSet)
+		assert.Equal(t, step.limit, gotLimit)
+	}
+	assert.Equal(t, []bool{true, false, true, false}, events)
+}
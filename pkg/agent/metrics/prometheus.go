@@ -83,6 +83,26 @@ var (
 		StabilityLevel: metrics.STABLE,
 	}, []string{"table_id"})
 
+	OVSFlowLimit = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "ovs_flow_limit",
+			Help:           "Maximum number of OVS flows on the Node, as configured by maxFlowsPerNode. 0 means that there is no limit.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	OVSFlowLimitExceeded = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "ovs_flow_limit_exceeded",
+			Help:           "Whether the number of OVS flows on the Node is close to the maximum configured by maxFlowsPerNode. 1 means that it exceeds 90% of the maximum, and it is reset to 0 once it goes back below 80% of the maximum.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
 	OVSFlowOpsCount = metrics.NewCounterVec(
 		&metrics.CounterOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -116,6 +136,82 @@ var (
 		[]string{"operation"},
 	)
 
+	OVSFlowCacheSize = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "ovs_flow_cache_size",
+			Help:           "Number of flows cached by the Antrea Agent OpenFlow client, partitioned by cache (node, pod, service and snat).",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"cache"},
+	)
+
+	OVSConnectionStatus = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "ovs_connection_status",
+			Help:           "Status of the OpenFlow connection between the Antrea Agent and the OVS bridge. 1 means connected and 0 means disconnected.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	OVSDroppedPackets = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "ovs_dropped_packets",
+			Help:           "Number of packets dropped by the table-miss flows, the spoof guard flows and the NetworkPolicy default drop flows of the OVS pipeline, partitioned by reason. This metric gets updated every minute, and is reset when the flows are re-installed.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"reason"},
+	)
+
+	SpoofGuardViolationCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "spoofguard_violation_count",
+			Help:           "Number of packets sent by local Pods which failed the spoof guard checks and were reported to the Antrea Agent, partitioned by type (mac, ip and arp). This metric is only updated when spoofGuardMode is logOnly or enableSpoofGuardEvents is true.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"type"},
+	)
+
+	BandwidthQuotaPodCount = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "bandwidth_quota_pod_count",
+			Help:           "Number of local Pods whose egress traffic is limited by each BandwidthQuota. The name of the BandwidthQuota is used as a label.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"quota"},
+	)
+
+	BandwidthQuotaEgressBytes = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "bandwidth_quota_egress_bytes",
+			Help:           "Number of bytes sent by the local Pods of each BandwidthQuota, including the dropped bytes. This metric gets updated every minute, and is reset when the OVS meter of the BandwidthQuota is re-installed.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"quota"},
+	)
+
+	BandwidthQuotaDroppedBytes = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "bandwidth_quota_dropped_bytes",
+			Help:           "Number of bytes sent by the local Pods of each BandwidthQuota which were dropped because they exceeded the quota. This metric gets updated every minute, and is reset when the OVS meter of the BandwidthQuota is re-installed.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"quota"},
+	)
+
 	TotalConnectionsInConnTrackTable = metrics.NewGauge(
 		&metrics.GaugeOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -145,6 +241,26 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		},
 	)
+
+	FlowRecordBufferedCount = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "flow_exporter_buffered_record_count",
+			Help:           "Number of flow records of terminated connections which are buffered on disk by the flow exporter while the flow collector cannot be reached.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	FlowRecordDroppedCount = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "flow_exporter_dropped_record_count",
+			Help:           "Number of flow records of terminated connections which are dropped by the flow exporter because they could not be buffered while the flow collector cannot be reached.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
 )
 
 func InitializePrometheusMetrics() {
@@ -154,6 +270,7 @@ func InitializePrometheusMetrics() {
 	InitializeNetworkPolicyMetrics()
 	InitializeOVSMetrics()
 	InitializeConnectionMetrics()
+	InitializeBandwidthQuotaMetrics()
 }
 
 func InitializePodMetrics() {
@@ -183,6 +300,12 @@ func InitializeOVSMetrics() {
 	if err := legacyregistry.Register(OVSFlowCount); err != nil {
 		klog.Error("Failed to register antrea_agent_ovs_flow_count with Prometheus")
 	}
+	if err := legacyregistry.Register(OVSFlowLimit); err != nil {
+		klog.Error("Failed to register antrea_agent_ovs_flow_limit with Prometheus")
+	}
+	if err := legacyregistry.Register(OVSFlowLimitExceeded); err != nil {
+		klog.Error("Failed to register antrea_agent_ovs_flow_limit_exceeded with Prometheus")
+	}
 
 	if err := legacyregistry.Register(OVSFlowOpsCount); err != nil {
 		klog.Error("Failed to register antrea_agent_ovs_flow_ops_count with Prometheus")
@@ -193,6 +316,18 @@ func InitializeOVSMetrics() {
 	if err := legacyregistry.Register(OVSFlowOpsLatency); err != nil {
 		klog.Error("Failed to register antrea_agent_ovs_flow_ops_latency_milliseconds with Prometheus")
 	}
+	if err := legacyregistry.Register(OVSFlowCacheSize); err != nil {
+		klog.Error("Failed to register antrea_agent_ovs_flow_cache_size with Prometheus")
+	}
+	if err := legacyregistry.Register(OVSConnectionStatus); err != nil {
+		klog.Error("Failed to register antrea_agent_ovs_connection_status with Prometheus")
+	}
+	if err := legacyregistry.Register(OVSDroppedPackets); err != nil {
+		klog.Error("Failed to register antrea_agent_ovs_dropped_packets with Prometheus")
+	}
+	if err := legacyregistry.Register(SpoofGuardViolationCount); err != nil {
+		klog.Error("Failed to register antrea_agent_spoofguard_violation_count with Prometheus")
+	}
 	// Initialize OpenFlow operations metrics with label add, modify and delete
 	// since those metrics won't come out until observation.
 	opsArray := [3]string{"add", "modify", "delete"}
@@ -201,6 +336,9 @@ func InitializeOVSMetrics() {
 		OVSFlowOpsErrorCount.WithLabelValues(ops)
 		OVSFlowOpsLatency.WithLabelValues(ops)
 	}
+	for _, cache := range []string{"node", "pod", "service", "snat"} {
+		OVSFlowCacheSize.WithLabelValues(cache)
+	}
 }
 
 func InitializeConnectionMetrics() {
@@ -213,4 +351,22 @@ func InitializeConnectionMetrics() {
 	if err := legacyregistry.Register(MaxConnectionsInConnTrackTable); err != nil {
 		klog.Errorf("Failed to register antrea_agent_conntrack_max_connection_count with error: %v", err)
 	}
+	if err := legacyregistry.Register(FlowRecordBufferedCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_flow_exporter_buffered_record_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(FlowRecordDroppedCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_flow_exporter_dropped_record_count with error: %v", err)
+	}
+}
+
+func InitializeBandwidthQuotaMetrics() {
+	if err := legacyregistry.Register(BandwidthQuotaPodCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_bandwidth_quota_pod_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(BandwidthQuotaEgressBytes); err != nil {
+		klog.Errorf("Failed to register antrea_agent_bandwidth_quota_egress_bytes with error: %v", err)
+	}
+	if err := legacyregistry.Register(BandwidthQuotaDroppedBytes); err != nil {
+		klog.Errorf("Failed to register antrea_agent_bandwidth_quota_dropped_bytes with error: %v", err)
+	}
 }
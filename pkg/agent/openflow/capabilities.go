@@ -0,0 +1,144 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
+	"github.com/vmware-tanzu/antrea/pkg/util/runtime"
+)
+
+const (
+	ofVersion10 = "OpenFlow10"
+	ofVersion13 = "OpenFlow13"
+	ofVersion14 = "OpenFlow14"
+	ofVersion15 = "OpenFlow15"
+)
+
+// The names of the datapath features used by the pipeline, as reported by "ovs-appctl dpif/show-dp-features".
+const (
+	dpFeatureCTState = "CT state"
+	dpFeatureCTZone  = "CT zone"
+	dpFeatureCTMark  = "CT mark"
+	dpFeatureCTNAT   = "CT state NAT"
+	dpFeatureCTClear = "Conntrack clear"
+)
+
+// OVSCapabilities describes the features supported by the OVS bridge and its datapath. They are detected before
+// connecting to the bridge, so that the Agent can fail fast with a clear error if a feature required by the pipeline
+// is missing, instead of getting OFPT_ERROR messages when installing the flows, and so that the flow generators can
+// check the optional features.
+type OVSCapabilities struct {
+	// OFVersions are the OpenFlow versions accepted by the bridge.
+	OFVersions []string
+	// DatapathFeatures are the features supported by the datapath. It is nil if they could not be retrieved, e.g.
+	// with OVS versions older than 2.11, in which case all the features are assumed to be supported.
+	DatapathFeatures map[string]string
+	// MaxMeters is the maximum number of OpenFlow meters supported by the bridge, 0 if meters are not supported.
+	MaxMeters uint32
+}
+
+// SupportsOFVersion returns whether the bridge accepts connections with the provided OpenFlow version.
+func (c *OVSCapabilities) SupportsOFVersion(version string) bool {
+	for _, v := range c.OFVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsDatapathFeature returns whether the datapath supports the provided boolean feature.
+func (c *OVSCapabilities) SupportsDatapathFeature(feature string) bool {
+	if c.DatapathFeatures == nil {
+		return true
+	}
+	return c.DatapathFeatures[feature] == "Yes"
+}
+
+// SupportsCTClear returns whether the ct_clear action is supported.
+func (c *OVSCapabilities) SupportsCTClear() bool {
+	return c.SupportsDatapathFeature(dpFeatureCTClear)
+}
+
+func (c *OVSCapabilities) String() string {
+	return fmt.Sprintf("OpenFlow versions: [%s], ct_clear: %t, CT NAT: %t, max meters: %d",
+		strings.Join(c.OFVersions, " "), c.SupportsCTClear(), c.SupportsDatapathFeature(dpFeatureCTNAT), c.MaxMeters)
+}
+
+// detectOVSCapabilities queries the capabilities of the bridge with "ovs-ofctl" and "ovs-appctl". Errors are logged
+// and the corresponding capabilities are left empty, as the bridge may be reachable with OpenFlow while the control
+// socket of ovs-vswitchd is not.
+func detectOVSCapabilities(ovsctlClient ovsctl.OVSCtlClient) *OVSCapabilities {
+	capabilities := &OVSCapabilities{}
+	for _, version := range []string{ofVersion10, ofVersion13, ofVersion14, ofVersion15} {
+		if ovsctlClient.SupportsOFVersion(version) {
+			capabilities.OFVersions = append(capabilities.OFVersions, version)
+		}
+	}
+	if features, err := ovsctlClient.DumpDatapathFeatures(); err != nil {
+		klog.Warningf("Failed to retrieve the OVS datapath features, assuming all features are supported: %v", err)
+	} else {
+		capabilities.DatapathFeatures = features
+	}
+	if maxMeters, err := ovsctlClient.GetMaxMeters(); err != nil {
+		klog.Warningf("Failed to retrieve the OVS meter features, assuming meters are not supported: %v", err)
+	} else {
+		capabilities.MaxMeters = maxMeters
+	}
+	return capabilities
+}
+
+// checkOVSCapabilities returns an error if a feature required by the pipeline is not supported by the bridge.
+func (c *client) checkOVSCapabilities() error {
+	capabilities := c.ovsCapabilities
+	if !capabilities.SupportsOFVersion(ofVersion13) {
+		return fmt.Errorf("the OVS bridge doesn't accept %s connections, which are required by the Antrea Agent (accepted versions: [%s])",
+			ofVersion13, strings.Join(capabilities.OFVersions, " "))
+	}
+	requiredFeatures := []string{dpFeatureCTState, dpFeatureCTZone, dpFeatureCTMark}
+	// NAT is used for Service load-balancing by AntreaProxy, and for SNAT of the traffic to the external network on
+	// Windows.
+	if c.enableProxy || runtime.IsWindowsPlatform() {
+		requiredFeatures = append(requiredFeatures, dpFeatureCTNAT)
+	}
+	var missingFeatures []string
+	for _, feature := range requiredFeatures {
+		if !capabilities.SupportsDatapathFeature(feature) {
+			missingFeatures = append(missingFeatures, feature)
+		}
+	}
+	if len(missingFeatures) > 0 {
+		return fmt.Errorf("the OVS datapath doesn't support the following features required by the Antrea Agent: [%s]",
+			strings.Join(missingFeatures, ", "))
+	}
+	return nil
+}
+
+// checkMeterSupport returns an error if the OpenFlow meters cannot be used, either because the OVS bridge doesn't
+// support them, or because the datapath flows using them could not be offloaded to the hardware.
+func (c *client) checkMeterSupport() error {
+	if c.enableHardwareOffload {
+		return fmt.Errorf("OpenFlow meters are not used with OVS hardware offload, as they cannot be offloaded")
+	}
+	if c.ovsCapabilities.MaxMeters == 0 {
+		return fmt.Errorf("OpenFlow meters are not supported by the OVS bridge")
+	}
+	return nil
+}
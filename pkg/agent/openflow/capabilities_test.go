@@ -0,0 +1,123 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
+)
+
+func TestDetectOVSCapabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ovsctlClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	ovsctlClient.EXPECT().SupportsOFVersion(ofVersion10).Return(true)
+	ovsctlClient.EXPECT().SupportsOFVersion(ofVersion13).Return(true)
+	ovsctlClient.EXPECT().SupportsOFVersion(ofVersion14).Return(false)
+	ovsctlClient.EXPECT().SupportsOFVersion(ofVersion15).Return(false)
+	ovsctlClient.EXPECT().DumpDatapathFeatures().Return(map[string]string{dpFeatureCTClear: "No", dpFeatureCTNAT: "Yes"}, nil)
+	ovsctlClient.EXPECT().GetMaxMeters().Return(uint32(0), fmt.Errorf("meters not supported"))
+
+	capabilities := detectOVSCapabilities(ovsctlClient)
+	assert.Equal(t, []string{ofVersion10, ofVersion13}, capabilities.OFVersions)
+	assert.False(t, capabilities.SupportsOFVersion(ofVersion15))
+	assert.False(t, capabilities.SupportsCTClear())
+	assert.True(t, capabilities.SupportsDatapathFeature(dpFeatureCTNAT))
+	assert.Equal(t, uint32(0), capabilities.MaxMeters)
+}
+
+func TestCheckOVSCapabilities(t *testing.T) {
+	allCTFeatures := map[string]string{dpFeatureCTState: "Yes", dpFeatureCTZone: "Yes", dpFeatureCTMark: "Yes", dpFeatureCTNAT: "Yes"}
+	noNATFeatures := map[string]string{dpFeatureCTState: "Yes", dpFeatureCTZone: "Yes", dpFeatureCTMark: "Yes", dpFeatureCTNAT: "No"}
+	tests := []struct {
+		name         string
+		capabilities *OVSCapabilities
+		enableProxy  bool
+		expectedErr  bool
+	}{
+		{
+			name:         "all features supported",
+			capabilities: &OVSCapabilities{OFVersions: []string{ofVersion10, ofVersion13}, DatapathFeatures: allCTFeatures},
+			enableProxy:  true,
+		},
+		{
+			name:         "OpenFlow13 not enabled",
+			capabilities: &OVSCapabilities{OFVersions: []string{ofVersion10}, DatapathFeatures: allCTFeatures},
+			expectedErr:  true,
+		},
+		{
+			name:         "datapath features unknown",
+			capabilities: &OVSCapabilities{OFVersions: []string{ofVersion13}},
+			enableProxy:  true,
+		},
+		{
+			name:         "NAT not supported with AntreaProxy",
+			capabilities: &OVSCapabilities{OFVersions: []string{ofVersion13}, DatapathFeatures: noNATFeatures},
+			enableProxy:  true,
+			expectedErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ofClient := &client{enableProxy: tt.enableProxy, ovsCapabilities: tt.capabilities}
+			err := ofClient.checkOVSCapabilities()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckMeterSupport(t *testing.T) {
+	tests := []struct {
+		name                  string
+		maxMeters             uint32
+		enableHardwareOffload bool
+		expectedErr           bool
+	}{
+		{
+			name:      "meters supported",
+			maxMeters: 100,
+		},
+		{
+			name:        "meters not supported",
+			expectedErr: true,
+		},
+		{
+			name:                  "meters not used with hardware offload",
+			maxMeters:             100,
+			enableHardwareOffload: true,
+			expectedErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ofClient := &client{enableHardwareOffload: tt.enableHardwareOffload, ovsCapabilities: &OVSCapabilities{MaxMeters: tt.maxMeters}}
+			err := ofClient.checkMeterSupport()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
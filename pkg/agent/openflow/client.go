@@ -15,20 +15,25 @@
 package openflow
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net"
+	"reflect"
 
+	"github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet/ofctrl"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
 	"github.com/vmware-tanzu/antrea/third_party/proxy"
 )
 
@@ -48,7 +53,8 @@ type Client interface {
 
 	// InstallClusterServiceCIDRFlows sets up the appropriate flows so that traffic can reach
 	// the different Services running in the Cluster. This method needs to be invoked once with
-	// the Cluster Service CIDR as a parameter.
+	// the Cluster Service CIDRs (at most one per address family) as a parameter. An error is
+	// returned if the host gateway has no address in the family of one of the CIDRs.
 	InstallClusterServiceCIDRFlows(serviceNets []*net.IPNet) error
 
 	// InstallClusterServiceFlows sets up the appropriate flows so that traffic can reach
@@ -58,10 +64,18 @@ type Client interface {
 	// InstallDefaultTunnelFlows sets up the classification flow for the default (flow based) tunnel.
 	InstallDefaultTunnelFlows() error
 
+	// InstallSpoofGuardViolationFlows sets up the flows which send the packets failing the spoof guard
+	// checks to the agent, with the PacketInReasonSG reason. If logOnly is true, these packets then
+	// continue through the pipeline as if they had passed the checks; otherwise they are dropped.
+	InstallSpoofGuardViolationFlows(logOnly bool) error
+
 	// InstallNodeFlows should be invoked when a connection to a remote Node is going to be set
-	// up. The hostname is used to identify the added flows. When IPSec tunnel is enabled,
-	// ipsecTunOFPort must be set to the OFPort number of the IPSec tunnel port to the remote Node;
-	// otherwise ipsecTunOFPort must be set to 0.
+	// up. The hostname is used to identify the added flows. When a separate tunnel port is
+	// created for the remote Node (when IPSec tunnel is enabled, or when the tunnel type used
+	// for the Node is not the default tunnel type), tunOFPort must be set to the OFPort number
+	// of this tunnel port; otherwise tunOFPort must be set to 0. tunnelPeerIP must be nil when
+	// the traffic to the remote Node must not be tunneled by OVS even in encap mode, e.g. when it
+	// is encrypted by WireGuard, in which case it is forwarded to the host gateway.
 	// InstallNodeFlows has all-or-nothing semantics(call succeeds if all the flows are installed
 	// successfully, otherwise no flows will be installed). Calls to InstallNodeFlows are idempotent.
 	// Concurrent calls to InstallNodeFlows and / or UninstallNodeFlows are supported as long as they
@@ -70,7 +84,7 @@ type Client interface {
 		hostname string,
 		peerConfigs map[*net.IPNet]net.IP,
 		tunnelPeerIP net.IP,
-		ipsecTunOFPort uint32) error
+		tunOFPort uint32) error
 
 	// UninstallNodeFlows removes the connection to the remote Node specified with the
 	// hostname. UninstallNodeFlows will do nothing if no connection to the host was established.
@@ -85,9 +99,64 @@ type Client interface {
 	InstallPodFlows(interfaceName string, podInterfaceIPs []net.IP, podInterfaceMAC net.HardwareAddr, ofPort uint32) error
 
 	// UninstallPodFlows removes the connection to the local Pod specified with the
-	// interfaceName. UninstallPodFlows will do nothing if no connection to the Pod was established.
+	// interfaceName, and the bandwidth limit and QoS queue installed for it by
+	// InstallPodBandwidthFlows and InstallPodQoSFlows.
+	// UninstallPodFlows will do nothing if no connection to the Pod was established.
 	UninstallPodFlows(interfaceName string) error
 
+	// InstallPodBandwidthFlows installs the Meters and flows which limit the bandwidth of the traffic sent
+	// (egressRate) and received (ingressRate) by the local Pod specified with the interfaceName, in bits per
+	// second. A rate of 0 means that the traffic in that direction is not limited. Calling it again for the same
+	// interfaceName replaces the previous limits.
+	InstallPodBandwidthFlows(interfaceName string, ofPort uint32, ingressRate, egressRate uint64) error
+
+	// UninstallPodBandwidthFlows removes the Meters and flows installed by InstallPodBandwidthFlows for the
+	// interfaceName. It does nothing if no bandwidth limit is installed for the interfaceName.
+	UninstallPodBandwidthFlows(interfaceName string) error
+
+	// InstallPodQoSFlows installs the flows which mark the IP packets sent by the local Pod specified with the
+	// interfaceName to the OVS ports outPorts, e.g. the host gateway and the tunnel port, with dscp if it is not 0,
+	// and send the traffic output to the queuePorts among them, i.e. the ports with a QoS, to their OVS QoS queue
+	// queueID. Calling it again for the same interfaceName replaces the previous queue and DSCP.
+	InstallPodQoSFlows(interfaceName string, ofPort uint32, queueID uint32, dscp uint8, outPorts, queuePorts []uint32) error
+
+	// UninstallPodQoSFlows removes the flows installed by InstallPodQoSFlows for the interfaceName. It does nothing
+	// if no queue is assigned to the interfaceName.
+	UninstallPodQoSFlows(interfaceName string) error
+
+	// InstallBandwidthQuotaFlows installs the Meter which limits the aggregate bandwidth of the traffic sent by the
+	// local Pods of a tenant to rate bits per second, and the flows which meter the traffic of these Pods.
+	// podOFPorts maps the interface names of the Pods to their ofPorts. Calling it again for the same quotaName
+	// updates the rate and the Pods. The quota takes precedence over the egress limits installed by
+	// InstallPodBandwidthFlows for the same Pods.
+	InstallBandwidthQuotaFlows(quotaName string, rate uint64, podOFPorts map[string]uint32) error
+
+	// UninstallBandwidthQuotaFlows removes the Meter and flows installed by InstallBandwidthQuotaFlows for the
+	// quotaName. It does nothing if the quota is not installed.
+	UninstallBandwidthQuotaFlows(quotaName string) error
+
+	// GetBandwidthQuotaStats returns the statistics of the Meters of the installed bandwidth quotas, keyed by quota
+	// name. The counters are reset when the Meters are re-installed.
+	GetBandwidthQuotaStats() (map[string]*ovsctl.MeterStats, error)
+
+	// InstallMulticastInitialFlows installs the flows which send the IPv4 multicast traffic to the multicast tables,
+	// send the IGMP messages to the agent with the PacketInReasonMC reason, and forward the multicast traffic sent
+	// by the local Pods to the other Nodes, through the tunnel or via the underlay network. The tunnel peers are
+	// tracked by InstallNodeFlows and UninstallNodeFlows.
+	InstallMulticastInitialFlows() error
+
+	// InstallMulticastGroup installs the Group and flow which replicate the multicast traffic sent to groupIP to the
+	// local Pods connected to receiverOFPorts. Calling it again for the same groupIP updates the receivers.
+	InstallMulticastGroup(groupIP net.IP, receiverOFPorts []uint32) error
+
+	// UninstallMulticastGroup removes the Group and flow installed by InstallMulticastGroup for groupIP. It does
+	// nothing if they are not installed.
+	UninstallMulticastGroup(groupIP net.IP) error
+
+	// SendIGMPQueryPacketOut sends an IGMPv2 general query from the gateway to the OVS port outPort, without going
+	// through the pipeline, so that the Pod connected to it reports the multicast groups it has joined.
+	SendIGMPQueryPacketOut(outPort uint32) error
+
 	// InstallServiceGroup installs a group for Service LB. Each endpoint
 	// is a bucket of the group. For now, each bucket has the same weight.
 	InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error
@@ -119,9 +188,31 @@ type Client interface {
 	InstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
 	// UninstallLoadBalancerServiceFromOutsideFlows removes flows installed by InstallLoadBalancerServiceFromOutsideFlows.
 	UninstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// InstallServiceFromUplinkFlows installs flows for Service traffic received from the uplink port and destined to
+	// svcIP. The traffic is load-balanced in the OVS pipeline, so that kube-proxy is not needed. It is used instead of
+	// InstallLoadBalancerServiceFromOutsideFlows when AntreaProxy handles the traffic from outside the Node.
+	// This function is only used for Windows platform.
+	InstallServiceFromUplinkFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// UninstallServiceFromUplinkFlows removes flows installed by InstallServiceFromUplinkFlows.
+	UninstallServiceFromUplinkFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+
+	// InstallNodePortServiceFlows installs flows for accessing the NodePort of a Service with the Node IP address,
+	// from the local Pods and from outside the Node. The traffic received from the uplink port is load-balanced in
+	// the OVS pipeline, so that kube-proxy is not needed.
+	// The group with the groupID must be installed before, otherwise the installation will fail.
+	// This function is only used for Windows platform.
+	InstallNodePortServiceFlows(groupID binding.GroupIDType, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) error
+	// UninstallNodePortServiceFlows removes flows installed by InstallNodePortServiceFlows.
+	UninstallNodePortServiceFlows(svcPort uint16, protocol binding.Protocol) error
 
 	// GetFlowTableStatus should return an array of flow table status, all existing flow tables should be included in the list.
 	GetFlowTableStatus() []binding.TableStatus
+	// GetFlowTableStats returns the number of flows and the packet and byte counters of each flow table, queried
+	// from OVS.
+	GetFlowTableStats() (map[binding.TableIDType]*binding.FlowStatsSummary, error)
+	// GetFlowCookieStats returns the number of flows and the packet and byte counters of the flows with each
+	// cookie, queried from OVS.
+	GetFlowCookieStats() (map[uint64]*binding.FlowStatsSummary, error)
 
 	// InstallPolicyRuleFlows installs flows for a new NetworkPolicy rule. Rule should include all fields in the
 	// NetworkPolicy rule. Each ingress/egress policy rule installs Openflow entries on two tables, one for
@@ -156,6 +247,24 @@ type Client interface {
 	// This function is only used for Windows platform.
 	InstallExternalFlows() error
 
+	// InstallSNATMarkFlows installs flows for a local SNAT IP. A single flow is added to mark the packets tunnelled
+	// from remote Nodes that should be SNAT'd with the SNAT IP. The SNAT itself is performed by the host network stack
+	// based on the mark.
+	InstallSNATMarkFlows(snatIP net.IP, mark uint32) error
+
+	// UninstallSNATMarkFlows removes the flows installed to set the packet mark for a SNAT IP.
+	UninstallSNATMarkFlows(mark uint32) error
+
+	// InstallPodSNATFlows installs the SNAT flows for a local Pod. If the SNAT IP for the Pod is on the local Node, a
+	// non-zero SNAT mark should be allocated for the SNAT IP, and the installed flow sets the SNAT mark on the packets
+	// from the ofPort to the external network; if the SNAT IP is on a remote Node, snatMark should be set to 0, and the
+	// installed flow tunnels the packets to the remote Node using the SNAT IP as the tunnel destination, and the
+	// packets will be SNAT'd on the remote Node.
+	InstallPodSNATFlows(ofPort uint32, snatIP net.IP, snatMark uint32) error
+
+	// UninstallPodSNATFlows removes the SNAT flows for the local Pod.
+	UninstallPodSNATFlows(ofPort uint32) error
+
 	// Disconnect disconnects the connection between client and OFSwitch.
 	Disconnect() error
 
@@ -216,6 +325,40 @@ type Client interface {
 		inPort uint32,
 		outPort int32) error
 
+	// SendTCPPacketOut sends a TCP packet directly to the OVS port outPort, without going through the
+	// pipeline. If tunnelDst is not nil, it is used as the tunnel destination of the packet, which is
+	// required when outPort is a tunnel port. If outPort is -1, the packet goes through the pipeline
+	// from the conntrack table instead, so that the DNAT of its connection is reversed, e.g. for a
+	// reply to a connection to a Service. It is used to reject the connections denied by Antrea
+	// Policy rules with a TCP RST.
+	SendTCPPacketOut(
+		srcMAC net.HardwareAddr,
+		dstMAC net.HardwareAddr,
+		srcIP net.IP,
+		dstIP net.IP,
+		outPort int32,
+		tunnelDst net.IP,
+		TCPSrcPort uint16,
+		TCPDstPort uint16,
+		TCPSeqNum uint32,
+		TCPAckNum uint32,
+		TCPFlags uint8) error
+
+	// SendICMPPacketOut sends an ICMP or ICMPv6 packet directly to the OVS port outPort, without going
+	// through the pipeline, or through the pipeline from the conntrack table if outPort is -1, like
+	// SendTCPPacketOut. ICMPData is the data following the ICMP type, code and checksum fields. It
+	// is used to reject the traffic denied by Antrea Policy rules with an ICMP port unreachable message.
+	SendICMPPacketOut(
+		srcMAC net.HardwareAddr,
+		dstMAC net.HardwareAddr,
+		srcIP net.IP,
+		dstIP net.IP,
+		outPort int32,
+		tunnelDst net.IP,
+		ICMPType uint8,
+		ICMPCode uint8,
+		ICMPData []byte) error
+
 	// InstallTraceflowFlows installs flows for specific traceflow request.
 	InstallTraceflowFlows(dataplaneTag uint8) error
 
@@ -232,6 +375,10 @@ type Client interface {
 	StartPacketInHandler(packetInStartedReason []uint8, stopCh <-chan struct{})
 	// Get traffic metrics of each NetworkPolicy rule.
 	NetworkPolicyMetrics() map[uint32]*types.RuleMetric
+	// DroppedPacketCounts returns the number of packets dropped by the table-miss flows and the NetworkPolicy
+	// default drop flows of the pipeline, partitioned by reason. The counters are reset when the flows are
+	// re-installed.
+	DroppedPacketCounts() map[string]uint64
 	// Returns if IPv4 is supported on this Node or not.
 	IsIPv4Enabled() bool
 	// Returns if IPv6 is supported on this Node or not.
@@ -243,6 +390,16 @@ func (c *client) GetFlowTableStatus() []binding.TableStatus {
 	return c.bridge.DumpTableStatus()
 }
 
+// GetFlowTableStats returns the number of flows and the packet and byte counters of each flow table.
+func (c *client) GetFlowTableStats() (map[binding.TableIDType]*binding.FlowStatsSummary, error) {
+	return c.bridge.DumpTableStats(0, 0)
+}
+
+// GetFlowCookieStats returns the number of flows and the packet and byte counters of the flows with each cookie.
+func (c *client) GetFlowCookieStats() (map[uint64]*binding.FlowStatsSummary, error) {
+	return c.bridge.DumpCookieStats(0, 0)
+}
+
 // IsConnected returns the connection status between client and OFSwitch.
 func (c *client) IsConnected() bool {
 	return c.bridge.IsConnected()
@@ -269,6 +426,7 @@ func (c *client) addFlows(cache *flowCategoryCache, flowCacheKey string, flows [
 		fCache[flow.MatchString()] = flow
 	}
 	cache.Store(flowCacheKey, fCache)
+	metrics.OVSFlowCacheSize.WithLabelValues(cache.name).Add(float64(len(fCache)))
 	return nil
 }
 
@@ -289,13 +447,14 @@ func (c *client) deleteFlows(cache *flowCategoryCache, flowCacheKey string) erro
 		return err
 	}
 	cache.Delete(flowCacheKey)
+	metrics.OVSFlowCacheSize.WithLabelValues(cache.name).Sub(float64(len(fCache)))
 	return nil
 }
 
 func (c *client) InstallNodeFlows(hostname string,
 	peerConfigs map[*net.IPNet]net.IP,
 	tunnelPeerIP net.IP,
-	ipsecTunOFPort uint32) error {
+	tunOFPort uint32) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
 
@@ -308,7 +467,7 @@ func (c *client) InstallNodeFlows(hostname string,
 			// only work for IPv4 addresses.
 			flows = append(flows, c.arpResponderFlow(peerGatewayIP, cookie.Node))
 		}
-		if c.encapMode.NeedsEncapToPeer(tunnelPeerIP, c.nodeConfig.NodeIPAddr) {
+		if tunnelPeerIP != nil && c.encapMode.NeedsEncapToPeer(tunnelPeerIP, c.nodeConfig.NodeIPAddr) {
 			// tunnelPeerIP is the Node Internal Address. In a dual-stack setup, whether this address is an IPv4 address or an
 			// IPv6 one is decided by the address family of Node Internal Address.
 			flows = append(flows, c.l3FwdFlowToRemote(localGatewayMAC, *peerPodCIDR, tunnelPeerIP, cookie.Node))
@@ -317,20 +476,36 @@ func (c *client) InstallNodeFlows(hostname string,
 		}
 	}
 
-	if ipsecTunOFPort != 0 {
-		// When IPSec tunnel is enabled, packets received from the remote Node are
-		// input from the Node's IPSec tunnel port, not the default tunnel port. So,
-		// add a separate tunnelClassifierFlow for the IPSec tunnel port.
-		flows = append(flows, c.tunnelClassifierFlow(ipsecTunOFPort, cookie.Node))
+	if tunOFPort != 0 {
+		// When a separate tunnel port is created for the remote Node, packets received
+		// from the remote Node are input from the Node's tunnel port, not the default
+		// tunnel port. So, add a separate tunnelClassifierFlow for the Node's tunnel port.
+		flows = append(flows, c.tunnelClassifierFlow(tunOFPort, cookie.Node))
+		// Packets to the remote Node must also be output to the Node's tunnel port, as
+		// the default tunnel port can only encapsulate packets with the default tunnel
+		// type.
+		flows = append(flows, c.l2ForwardCalcFlowToTunnelPeer(tunnelPeerIP, tunOFPort, cookie.Node))
 	}
 
-	return c.addFlows(c.nodeFlowCache, hostname, flows)
+	if err := c.addFlows(c.nodeFlowCache, hostname, flows); err != nil {
+		return err
+	}
+	if c.enableMulticast && tunnelPeerIP != nil && c.encapMode.NeedsEncapToPeer(tunnelPeerIP, c.nodeConfig.NodeIPAddr) {
+		return c.updateMulticastPeer(hostname, &multicastPeer{tunnelPeerIP: tunnelPeerIP, tunOFPort: tunOFPort})
+	}
+	return nil
 }
 
 func (c *client) UninstallNodeFlows(hostname string) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
-	return c.deleteFlows(c.nodeFlowCache, hostname)
+	if err := c.deleteFlows(c.nodeFlowCache, hostname); err != nil {
+		return err
+	}
+	if c.enableMulticast {
+		return c.updateMulticastPeer(hostname, nil)
+	}
+	return nil
 }
 
 func (c *client) InstallPodFlows(interfaceName string, podInterfaceIPs []net.IP, podInterfaceMAC net.HardwareAddr, ofPort uint32) error {
@@ -365,9 +540,346 @@ func (c *client) InstallPodFlows(interfaceName string, podInterfaceIPs []net.IP,
 func (c *client) UninstallPodFlows(interfaceName string) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
+	// The bandwidth limit of the Pod is removed together with its flows, as its ofPort may be reused by a new Pod.
+	if err := c.uninstallPodBandwidthFlows(interfaceName); err != nil {
+		return err
+	}
+	if err := c.uninstallPodBandwidthQuotaFlow(interfaceName); err != nil {
+		return err
+	}
+	if err := c.uninstallPodQoSFlows(interfaceName); err != nil {
+		return err
+	}
 	return c.deleteFlows(c.podFlowCache, interfaceName)
 }
 
+// podBandwidth is the bandwidth limit realized for a local Pod.
+type podBandwidth struct {
+	ingressRate, egressRate uint64
+	meterIDs                []binding.MeterIDType
+	meters                  []binding.Meter
+	flows                   []binding.Flow
+}
+
+// ofEntries returns the Meters and flows of the podBandwidth. The Meters come first as the flows depend on them.
+func (pb *podBandwidth) ofEntries() []binding.OFEntry {
+	entries := make([]binding.OFEntry, 0, len(pb.meters)+len(pb.flows))
+	for _, meter := range pb.meters {
+		entries = append(entries, meter)
+	}
+	for _, flow := range pb.flows {
+		entries = append(entries, flow)
+	}
+	return entries
+}
+
+func (c *client) InstallPodBandwidthFlows(interfaceName string, ofPort uint32, ingressRate, egressRate uint64) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	if err := c.checkMeterSupport(); err != nil {
+		return err
+	}
+	if obj, ok := c.podBandwidthCache.Load(interfaceName); ok {
+		pb := obj.(*podBandwidth)
+		if pb.ingressRate == ingressRate && pb.egressRate == egressRate {
+			return nil
+		}
+		// The previous Meters and flows are removed before the new ones are installed.
+		if err := c.uninstallPodBandwidthFlows(interfaceName); err != nil {
+			return err
+		}
+	}
+	pb := &podBandwidth{ingressRate: ingressRate, egressRate: egressRate}
+	if egressRate > 0 {
+		meterID := podEgressMeterID(ofPort)
+		pb.meterIDs = append(pb.meterIDs, meterID)
+		pb.meters = append(pb.meters, c.bandwidthMeter(meterID, egressRate))
+		pb.flows = append(pb.flows, c.podEgressMeterFlow(ofPort, meterID, cookie.Pod))
+	}
+	if ingressRate > 0 {
+		meterID := podIngressMeterID(ofPort)
+		pb.meterIDs = append(pb.meterIDs, meterID)
+		pb.meters = append(pb.meters, c.bandwidthMeter(meterID, ingressRate))
+		pb.flows = append(pb.flows, c.podIngressMeterFlow(ofPort, meterID, cookie.Pod))
+	}
+	if len(pb.meters) == 0 {
+		return nil
+	}
+	if err := c.ofEntryOperations.AddOFEntries(pb.ofEntries()); err != nil {
+		return err
+	}
+	c.podBandwidthCache.Store(interfaceName, pb)
+	return nil
+}
+
+func (c *client) UninstallPodBandwidthFlows(interfaceName string) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.uninstallPodBandwidthFlows(interfaceName)
+}
+
+func (c *client) uninstallPodBandwidthFlows(interfaceName string) error {
+	obj, ok := c.podBandwidthCache.Load(interfaceName)
+	if !ok {
+		return nil
+	}
+	pb := obj.(*podBandwidth)
+	// The flows are removed before the Meters they depend on. The Meters are deleted through the bridge, so that
+	// they are also released from the OFSwitch and can be created again for the next Pod using the same ofPort.
+	if err := c.ofEntryOperations.DeleteAll(pb.flows); err != nil {
+		return err
+	}
+	for _, meterID := range pb.meterIDs {
+		if !c.bridge.DeleteMeter(meterID) {
+			return fmt.Errorf("meter %d delete failed", meterID)
+		}
+	}
+	c.podBandwidthCache.Delete(interfaceName)
+	return nil
+}
+
+// podQoS is the QoS queue and the DSCP assigned to the traffic of a local Pod.
+type podQoS struct {
+	queueID    uint32
+	dscp       uint8
+	outPorts   []uint32
+	queuePorts []uint32
+	flows      []binding.Flow
+}
+
+func (c *client) InstallPodQoSFlows(interfaceName string, ofPort uint32, queueID uint32, dscp uint8, outPorts, queuePorts []uint32) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	if obj, ok := c.podQoSCache.Load(interfaceName); ok {
+		pq := obj.(*podQoS)
+		if pq.queueID == queueID && pq.dscp == dscp && reflect.DeepEqual(pq.outPorts, outPorts) && reflect.DeepEqual(pq.queuePorts, queuePorts) {
+			return nil
+		}
+		if err := c.uninstallPodQoSFlows(interfaceName); err != nil {
+			return err
+		}
+	}
+	pq := &podQoS{queueID: queueID, dscp: dscp, outPorts: outPorts, queuePorts: queuePorts, flows: c.podQoSQueueFlows(ofPort, queueID, dscp, outPorts, queuePorts, cookie.Pod)}
+	if err := c.ofEntryOperations.AddAll(pq.flows); err != nil {
+		return err
+	}
+	c.podQoSCache.Store(interfaceName, pq)
+	return nil
+}
+
+func (c *client) UninstallPodQoSFlows(interfaceName string) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.uninstallPodQoSFlows(interfaceName)
+}
+
+func (c *client) uninstallPodQoSFlows(interfaceName string) error {
+	obj, ok := c.podQoSCache.Load(interfaceName)
+	if !ok {
+		return nil
+	}
+	if err := c.ofEntryOperations.DeleteAll(obj.(*podQoS).flows); err != nil {
+		return err
+	}
+	c.podQoSCache.Delete(interfaceName)
+	return nil
+}
+
+const (
+	// maxOFPort is the highest ofPort allocated by OVS to a port (OFPP_MAX of OpenFlow 1.0).
+	maxOFPort = 0xfeff
+	// maxBandwidthQuotas is the maximum number of bandwidth quotas installed on the bridge.
+	maxBandwidthQuotas = 0x1000
+)
+
+// bandwidthQuota is the aggregate bandwidth limit realized for the local Pods of a tenant.
+type bandwidthQuota struct {
+	meterID binding.MeterIDType
+	rate    uint64
+	meter   binding.Meter
+	// podOFPorts and podFlows map the interface names of the Pods of the tenant to their ofPorts, and to the flows
+	// which meter their traffic.
+	podOFPorts map[string]uint32
+	podFlows   map[string]binding.Flow
+}
+
+// ofEntries returns the Meter and flows of the bandwidthQuota. The Meter comes first as the flows depend on it.
+func (bq *bandwidthQuota) ofEntries() []binding.OFEntry {
+	entries := make([]binding.OFEntry, 0, len(bq.podFlows)+1)
+	entries = append(entries, bq.meter)
+	for _, flow := range bq.podFlows {
+		entries = append(entries, flow)
+	}
+	return entries
+}
+
+// allocateBandwidthQuotaMeterID returns an unused Meter ID for a bandwidth quota, from the range returned by
+// bandwidthQuotaMeterIDRange, which doesn't overlap with the IDs of the packet-in and Pod bandwidth Meters. It must
+// be called with bandwidthQuotaMutex locked.
+func (c *client) allocateBandwidthQuotaMeterID() (binding.MeterIDType, error) {
+	used := map[binding.MeterIDType]bool{}
+	c.bandwidthQuotaCache.Range(func(_, obj interface{}) bool {
+		used[obj.(*bandwidthQuota).meterID] = true
+		return true
+	})
+	firstID, lastID := bandwidthQuotaMeterIDRange()
+	for id := firstID; id <= lastID; id++ {
+		if !used[id] {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no Meter ID available for bandwidth quota, at most %d bandwidth quotas are supported", maxBandwidthQuotas)
+}
+
+func (c *client) InstallBandwidthQuotaFlows(quotaName string, rate uint64, podOFPorts map[string]uint32) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	if err := c.checkMeterSupport(); err != nil {
+		return err
+	}
+	c.bandwidthQuotaMutex.Lock()
+	defer c.bandwidthQuotaMutex.Unlock()
+
+	var bq *bandwidthQuota
+	if obj, ok := c.bandwidthQuotaCache.Load(quotaName); ok {
+		bq = obj.(*bandwidthQuota)
+		if bq.rate != rate {
+			// The band of the Meter is replaced in place, so that the flows using it don't need to be re-installed.
+			meter := c.bandwidthMeter(bq.meterID, rate)
+			if err := meter.Modify(); err != nil {
+				return err
+			}
+			bq.meter, bq.rate = meter, rate
+		}
+	} else {
+		meterID, err := c.allocateBandwidthQuotaMeterID()
+		if err != nil {
+			return err
+		}
+		meter := c.bandwidthMeter(meterID, rate)
+		if err := c.ofEntryOperations.AddOFEntries([]binding.OFEntry{meter}); err != nil {
+			return err
+		}
+		bq = &bandwidthQuota{
+			meterID:    meterID,
+			rate:       rate,
+			meter:      meter,
+			podOFPorts: map[string]uint32{},
+			podFlows:   map[string]binding.Flow{},
+		}
+		c.bandwidthQuotaCache.Store(quotaName, bq)
+	}
+
+	// The flows of the Pods which left the quota, or whose ofPort changed, are removed before the new flows are
+	// installed, as they may have the same match.
+	var staleFlows []binding.Flow
+	var staleNames []string
+	for name, ofPort := range bq.podOFPorts {
+		if newOFPort, ok := podOFPorts[name]; !ok || newOFPort != ofPort {
+			staleFlows = append(staleFlows, bq.podFlows[name])
+			staleNames = append(staleNames, name)
+		}
+	}
+	if len(staleFlows) > 0 {
+		if err := c.ofEntryOperations.DeleteAll(staleFlows); err != nil {
+			return err
+		}
+		for _, name := range staleNames {
+			delete(bq.podOFPorts, name)
+			delete(bq.podFlows, name)
+		}
+	}
+	newFlows := map[string]binding.Flow{}
+	for name, ofPort := range podOFPorts {
+		if _, ok := bq.podOFPorts[name]; !ok {
+			newFlows[name] = c.bandwidthQuotaFlow(ofPort, bq.meterID, cookie.Pod)
+		}
+	}
+	if len(newFlows) == 0 {
+		return nil
+	}
+	flows := make([]binding.Flow, 0, len(newFlows))
+	for _, flow := range newFlows {
+		flows = append(flows, flow)
+	}
+	if err := c.ofEntryOperations.AddAll(flows); err != nil {
+		return err
+	}
+	for name, flow := range newFlows {
+		bq.podOFPorts[name] = podOFPorts[name]
+		bq.podFlows[name] = flow
+	}
+	return nil
+}
+
+func (c *client) UninstallBandwidthQuotaFlows(quotaName string) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	c.bandwidthQuotaMutex.Lock()
+	defer c.bandwidthQuotaMutex.Unlock()
+
+	obj, ok := c.bandwidthQuotaCache.Load(quotaName)
+	if !ok {
+		return nil
+	}
+	bq := obj.(*bandwidthQuota)
+	// The flows are removed before the Meter they depend on, which is released from the OFSwitch as well.
+	flows := make([]binding.Flow, 0, len(bq.podFlows))
+	for _, flow := range bq.podFlows {
+		flows = append(flows, flow)
+	}
+	if len(flows) > 0 {
+		if err := c.ofEntryOperations.DeleteAll(flows); err != nil {
+			return err
+		}
+	}
+	if !c.bridge.DeleteMeter(bq.meterID) {
+		return fmt.Errorf("meter %d delete failed", bq.meterID)
+	}
+	c.bandwidthQuotaCache.Delete(quotaName)
+	return nil
+}
+
+// uninstallPodBandwidthQuotaFlow removes the flow metering the traffic of the Pod interface with the Meter of its
+// bandwidth quota, if any.
+func (c *client) uninstallPodBandwidthQuotaFlow(interfaceName string) error {
+	c.bandwidthQuotaMutex.Lock()
+	defer c.bandwidthQuotaMutex.Unlock()
+
+	var err error
+	c.bandwidthQuotaCache.Range(func(_, obj interface{}) bool {
+		bq := obj.(*bandwidthQuota)
+		flow, ok := bq.podFlows[interfaceName]
+		if !ok {
+			return true
+		}
+		if err = c.ofEntryOperations.Delete(flow); err == nil {
+			delete(bq.podOFPorts, interfaceName)
+			delete(bq.podFlows, interfaceName)
+		}
+		return false
+	})
+	return err
+}
+
+func (c *client) GetBandwidthQuotaStats() (map[string]*ovsctl.MeterStats, error) {
+	meterStats, err := c.ovsctlClient.DumpMeterStats()
+	if err != nil {
+		return nil, err
+	}
+	stats := map[string]*ovsctl.MeterStats{}
+	c.bandwidthQuotaCache.Range(func(name, obj interface{}) bool {
+		if s, ok := meterStats[uint32(obj.(*bandwidthQuota).meterID)]; ok {
+			stats[name.(string)] = s
+		}
+		return true
+	})
+	return stats, nil
+}
+
 func (c *client) GetPodFlowKeys(interfaceName string) []string {
 	fCacheI, ok := c.podFlowCache.Load(interfaceName)
 	if !ok {
@@ -387,6 +899,143 @@ func (c *client) GetPodFlowKeys(interfaceName string) []string {
 	return flowKeys
 }
 
+// maxGroupID is the highest ID of a regular Group, the IDs above it are reserved by OpenFlow.
+const maxGroupID = 0xffffff00
+
+// multicastPeer is a remote Node to which the multicast traffic sent by the local Pods is tunnelled.
+type multicastPeer struct {
+	tunnelPeerIP net.IP
+	// tunOFPort is the separate tunnel port created for the Node, or 0 if the default tunnel port is used.
+	tunOFPort uint32
+}
+
+// multicastGroup is the replication of the traffic sent to a multicast group address to the local receivers.
+type multicastGroup struct {
+	groupID         binding.GroupIDType
+	receiverOFPorts []uint32
+	group           binding.Group
+	flow            binding.Flow
+}
+
+// ofEntries returns the Group and flow of the multicastGroup. The Group comes first as the flow depends on it.
+func (mg *multicastGroup) ofEntries() []binding.OFEntry {
+	return []binding.OFEntry{mg.group, mg.flow}
+}
+
+// allocateMulticastGroupID returns an unused Group ID for a multicast group. The IDs are allocated downwards from
+// maxGroupID, so that they don't overlap with the IDs of the Service Groups, which are allocated upwards from 1. It
+// must be called with multicastMutex locked.
+func (c *client) allocateMulticastGroupID() (binding.GroupIDType, error) {
+	used := map[binding.GroupIDType]bool{}
+	c.multicastGroupCache.Range(func(_, obj interface{}) bool {
+		used[obj.(*multicastGroup).groupID] = true
+		return true
+	})
+	for id := binding.GroupIDType(maxGroupID); id > 0; id-- {
+		if !used[id] {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no Group ID available for multicast group")
+}
+
+// updateMulticastPeer adds or updates the tunnel peer of the remote Node hostname, or removes it if peer is nil, and
+// re-installs the multicastRemoteFlow if the multicast flows are installed.
+func (c *client) updateMulticastPeer(hostname string, peer *multicastPeer) error {
+	c.multicastMutex.Lock()
+	defer c.multicastMutex.Unlock()
+
+	if peer == nil {
+		if _, ok := c.multicastPeers[hostname]; !ok {
+			return nil
+		}
+		delete(c.multicastPeers, hostname)
+	} else {
+		c.multicastPeers[hostname] = peer
+	}
+	if c.multicastRemoteFlow == nil {
+		return nil
+	}
+	flow := c.multicastRemoteForwardingFlow(c.multicastPeers, cookie.Multicast)
+	if err := c.ofEntryOperations.Modify(flow); err != nil {
+		return err
+	}
+	c.multicastRemoteFlow = flow
+	return nil
+}
+
+func (c *client) InstallMulticastInitialFlows() error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	c.multicastMutex.Lock()
+	defer c.multicastMutex.Unlock()
+
+	flows := c.multicastFlows(cookie.Multicast)
+	remoteFlow := c.multicastRemoteForwardingFlow(c.multicastPeers, cookie.Multicast)
+	if err := c.ofEntryOperations.AddAll(append(flows, remoteFlow)); err != nil {
+		return err
+	}
+	c.multicastInitialFlows, c.multicastRemoteFlow = flows, remoteFlow
+	return nil
+}
+
+func (c *client) InstallMulticastGroup(groupIP net.IP, receiverOFPorts []uint32) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	c.multicastMutex.Lock()
+	defer c.multicastMutex.Unlock()
+
+	key := groupIP.String()
+	if obj, ok := c.multicastGroupCache.Load(key); ok {
+		mg := obj.(*multicastGroup)
+		// The buckets of the Group are replaced in place, so that the flow using it doesn't need to be re-installed.
+		group := c.multicastReceiverGroup(mg.groupID, receiverOFPorts)
+		if err := group.Modify(); err != nil {
+			return fmt.Errorf("error when updating multicast Group %d: %w", mg.groupID, err)
+		}
+		mg.group, mg.receiverOFPorts = group, receiverOFPorts
+		return nil
+	}
+	groupID, err := c.allocateMulticastGroupID()
+	if err != nil {
+		return err
+	}
+	mg := &multicastGroup{
+		groupID:         groupID,
+		receiverOFPorts: receiverOFPorts,
+		group:           c.multicastReceiverGroup(groupID, receiverOFPorts),
+		flow:            c.multicastGroupFlow(groupIP, groupID, cookie.Multicast),
+	}
+	if err := c.ofEntryOperations.AddOFEntries(mg.ofEntries()); err != nil {
+		return err
+	}
+	c.multicastGroupCache.Store(key, mg)
+	return nil
+}
+
+func (c *client) UninstallMulticastGroup(groupIP net.IP) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	c.multicastMutex.Lock()
+	defer c.multicastMutex.Unlock()
+
+	key := groupIP.String()
+	obj, ok := c.multicastGroupCache.Load(key)
+	if !ok {
+		return nil
+	}
+	mg := obj.(*multicastGroup)
+	// The flow is removed before the Group it depends on.
+	if err := c.ofEntryOperations.Delete(mg.flow); err != nil {
+		return err
+	}
+	if !c.bridge.DeleteGroup(mg.groupID) {
+		return fmt.Errorf("multicast Group %d delete failed", mg.groupID)
+	}
+	c.multicastGroupCache.Delete(key)
+	return nil
+}
+
 func (c *client) InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -480,6 +1129,42 @@ func (c *client) UninstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcP
 	return c.deleteFlows(c.serviceFlowCache, cacheKey)
 }
 
+func (c *client) InstallServiceFromUplinkFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	flows := c.serviceFromUplinkFlows(svcIP, svcPort, protocol)
+	cacheKey := fmt.Sprintf("ServiceFromUplink_%s_%d_%s", svcIP, svcPort, protocol)
+	return c.addFlows(c.serviceFlowCache, cacheKey, flows)
+}
+
+func (c *client) UninstallServiceFromUplinkFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := fmt.Sprintf("ServiceFromUplink_%s_%d_%s", svcIP, svcPort, protocol)
+	return c.deleteFlows(c.serviceFlowCache, cacheKey)
+}
+
+func (c *client) InstallNodePortServiceFlows(groupID binding.GroupIDType, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	nodeIP := c.nodeConfig.NodeIPAddr.IP
+	var flows []binding.Flow
+	flows = append(flows, c.serviceLBFlow(groupID, nodeIP, svcPort, protocol))
+	if affinityTimeout != 0 {
+		flows = append(flows, c.serviceLearnFlow(groupID, nodeIP, svcPort, protocol, affinityTimeout))
+	}
+	flows = append(flows, c.serviceFromUplinkFlows(nodeIP, svcPort, protocol)...)
+	cacheKey := fmt.Sprintf("NodePortService_%d_%s", svcPort, protocol)
+	return c.addFlows(c.serviceFlowCache, cacheKey, flows)
+}
+
+func (c *client) UninstallNodePortServiceFlows(svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := fmt.Sprintf("NodePortService_%d_%s", svcPort, protocol)
+	return c.deleteFlows(c.serviceFlowCache, cacheKey)
+}
+
 func (c *client) InstallClusterServiceFlows() error {
 	flows := []binding.Flow{
 		c.serviceNeedLBFlow(),
@@ -502,6 +1187,19 @@ func (c *client) InstallClusterServiceFlows() error {
 }
 
 func (c *client) InstallClusterServiceCIDRFlows(serviceNets []*net.IPNet) error {
+	gatewayConfig := c.nodeConfig.GatewayConfig
+	for _, serviceNet := range serviceNets {
+		if serviceNet == nil {
+			continue
+		}
+		// Traffic to a Service CIDR is punted to the host gateway, which must have an address of the same family for
+		// kube-proxy to DNAT it.
+		if serviceNet.IP.To4() != nil && gatewayConfig.IPv4 == nil {
+			return fmt.Errorf("IPv4 Service CIDR %s is configured but the host gateway has no IPv4 address", serviceNet)
+		} else if serviceNet.IP.To4() == nil && gatewayConfig.IPv6 == nil {
+			return fmt.Errorf("IPv6 Service CIDR %s is configured but the host gateway has no IPv6 address", serviceNet)
+		}
+	}
 	flows := c.serviceCIDRDNATFlows(serviceNets)
 	if err := c.ofEntryOperations.AddAll(flows); err != nil {
 		return err
@@ -535,6 +1233,14 @@ func (c *client) InstallGatewayFlows() error {
 	// In NoEncap , no traffic from tunnel port
 	if c.encapMode.SupportsEncap() {
 		flows = append(flows, c.l3FwdFlowToGateway(gatewayIPs, gatewayConfig.MAC, cookie.Default)...)
+		if c.enableEgress {
+			nodeIP := c.nodeConfig.NodeIPAddr.IP
+			for _, podCIDR := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
+				if podCIDR != nil {
+					flows = append(flows, c.snatCommonFlows(nodeIP, *podCIDR, gatewayConfig.MAC, cookie.SNAT)...)
+				}
+			}
+		}
 	}
 
 	if err := c.ofEntryOperations.AddAll(flows); err != nil {
@@ -556,6 +1262,15 @@ func (c *client) InstallDefaultTunnelFlows() error {
 	return nil
 }
 
+func (c *client) InstallSpoofGuardViolationFlows(logOnly bool) error {
+	flows := c.spoofGuardViolationFlows(logOnly, cookie.Default)
+	if err := c.ofEntryOperations.AddAll(flows); err != nil {
+		return err
+	}
+	c.spoofGuardFlows = flows
+	return nil
+}
+
 func (c *client) InstallBridgeUplinkFlows() error {
 	flows := c.hostBridgeUplinkFlows(*c.nodeConfig.PodIPv4CIDR, cookie.Default)
 	c.hostNetworkingFlows = flows
@@ -567,6 +1282,15 @@ func (c *client) InstallBridgeUplinkFlows() error {
 }
 
 func (c *client) initialize() error {
+	if c.metersEnabled {
+		var meters []binding.OFEntry
+		for _, reason := range meteredPacketInReasons {
+			meters = append(meters, c.packetInMeter(reason))
+		}
+		if err := c.ofEntryOperations.AddOFEntries(meters); err != nil {
+			return fmt.Errorf("failed to install packet-in Meters: %v", err)
+		}
+	}
 	if err := c.ofEntryOperations.AddAll(c.defaultFlows()); err != nil {
 		return fmt.Errorf("failed to install default flows: %v", err)
 	}
@@ -607,6 +1331,13 @@ func (c *client) Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeCo
 		c.ipProtocols = append(c.ipProtocols, binding.ProtocolIPv6)
 	}
 
+	c.ovsCapabilities = detectOVSCapabilities(c.ovsctlClient)
+	klog.Infof("Detected OVS bridge capabilities: %s", c.ovsCapabilities)
+	if err := c.checkOVSCapabilities(); err != nil {
+		return nil, err
+	}
+	c.metersEnabled = c.checkMeterSupport() == nil
+
 	// Initiate connections to target OFswitch, and create tables on the switch.
 	connCh := make(chan struct{})
 	if err := c.bridge.Connect(maxRetryForOFSwitch, connCh); err != nil {
@@ -626,6 +1357,14 @@ func (c *client) Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeCo
 	if err := c.deleteFlowsByRoundNum(roundInfo.RoundNum); err != nil {
 		return nil, fmt.Errorf("error when deleting exiting flows for current round number: %v", err)
 	}
+	// Unlike the flows, the Meters are not associated with a round number, and adding a Meter fails if its ID is
+	// already used. The Meters installed by the previous round are removed, the Meters of the Pods and bandwidth
+	// quotas are re-installed when the Pods and quotas are reconciled.
+	if c.metersEnabled {
+		if _, err := c.ovsctlClient.RunOfctlCmd("del-meters"); err != nil {
+			return nil, fmt.Errorf("error when deleting existing Meters: %v", err)
+		}
+	}
 
 	return connCh, c.initialize()
 }
@@ -642,6 +1381,36 @@ func (c *client) InstallExternalFlows() error {
 	return nil
 }
 
+func (c *client) InstallSNATMarkFlows(snatIP net.IP, mark uint32) error {
+	flow := c.snatIPFromTunnelFlow(snatIP, mark)
+	cacheKey := fmt.Sprintf("s%x", mark)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.addFlows(c.snatFlowCache, cacheKey, []binding.Flow{flow})
+}
+
+func (c *client) UninstallSNATMarkFlows(mark uint32) error {
+	cacheKey := fmt.Sprintf("s%x", mark)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.deleteFlows(c.snatFlowCache, cacheKey)
+}
+
+func (c *client) InstallPodSNATFlows(ofPort uint32, snatIP net.IP, snatMark uint32) error {
+	flow := c.snatRuleFlow(ofPort, snatIP, snatMark, c.nodeConfig.GatewayConfig.MAC)
+	cacheKey := fmt.Sprintf("p%x", ofPort)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.addFlows(c.snatFlowCache, cacheKey, []binding.Flow{flow})
+}
+
+func (c *client) UninstallPodSNATFlows(ofPort uint32) error {
+	cacheKey := fmt.Sprintf("p%x", ofPort)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.deleteFlows(c.snatFlowCache, cacheKey)
+}
+
 func (c *client) ReplayFlows() {
 	c.replayMutex.Lock()
 	defer c.replayMutex.Unlock()
@@ -663,6 +1432,7 @@ func (c *client) ReplayFlows() {
 	addFixedFlows(c.gatewayFlows)
 	addFixedFlows(c.defaultServiceFlows)
 	addFixedFlows(c.defaultTunnelFlows)
+	addFixedFlows(c.spoofGuardFlows)
 	// hostNetworkingFlows is used only on Windows. Replay the flows only when there are flows in this cache.
 	if len(c.hostNetworkingFlows) > 0 {
 		addFixedFlows(c.hostNetworkingFlows)
@@ -691,7 +1461,51 @@ func (c *client) ReplayFlows() {
 	})
 	c.nodeFlowCache.Range(installCachedFlows)
 	c.podFlowCache.Range(installCachedFlows)
+	c.podBandwidthCache.Range(func(name, obj interface{}) bool {
+		entries := obj.(*podBandwidth).ofEntries()
+		for _, entry := range entries {
+			entry.Reset()
+		}
+		if err := c.ofEntryOperations.AddOFEntries(entries); err != nil {
+			klog.Errorf("Error when replaying cached bandwidth limit of interface %s: %v", name, err)
+		}
+		return true
+	})
+	c.bandwidthQuotaCache.Range(func(name, obj interface{}) bool {
+		entries := obj.(*bandwidthQuota).ofEntries()
+		for _, entry := range entries {
+			entry.Reset()
+		}
+		if err := c.ofEntryOperations.AddOFEntries(entries); err != nil {
+			klog.Errorf("Error when replaying cached bandwidth quota %s: %v", name, err)
+		}
+		return true
+	})
+	if c.multicastRemoteFlow != nil {
+		addFixedFlows(append(c.multicastInitialFlows, c.multicastRemoteFlow))
+	}
+	c.multicastGroupCache.Range(func(groupIP, obj interface{}) bool {
+		entries := obj.(*multicastGroup).ofEntries()
+		for _, entry := range entries {
+			entry.Reset()
+		}
+		if err := c.ofEntryOperations.AddOFEntries(entries); err != nil {
+			klog.Errorf("Error when replaying cached multicast group %s: %v", groupIP, err)
+		}
+		return true
+	})
+	c.podQoSCache.Range(func(name, obj interface{}) bool {
+		flows := obj.(*podQoS).flows
+		for _, flow := range flows {
+			flow.Reset()
+		}
+		if err := c.ofEntryOperations.AddAll(flows); err != nil {
+			klog.Errorf("Error when replaying cached QoS queue of interface %s: %v", name, err)
+		}
+		return true
+	})
 	c.serviceFlowCache.Range(installCachedFlows)
+	c.snatFlowCache.Range(installCachedFlows)
 
 	c.replayPolicyFlows()
 }
@@ -841,6 +1655,110 @@ func (c *client) SendTraceflowPacket(
 	return c.bridge.SendPacketOut(packetOutObj)
 }
 
+func (c *client) SendTCPPacketOut(
+	srcMAC net.HardwareAddr,
+	dstMAC net.HardwareAddr,
+	srcIP net.IP,
+	dstIP net.IP,
+	outPort int32,
+	tunnelDst net.IP,
+	TCPSrcPort uint16,
+	TCPDstPort uint16,
+	TCPSeqNum uint32,
+	TCPAckNum uint32,
+	TCPFlags uint8) error {
+	packetOutBuilder, err := c.newDirectPacketOutBuilder(srcMAC, dstMAC, srcIP, dstIP, outPort, tunnelDst)
+	if err != nil {
+		return err
+	}
+	if srcIP.To4() == nil {
+		packetOutBuilder = packetOutBuilder.SetIPProtocol(binding.ProtocolTCPv6)
+	} else {
+		packetOutBuilder = packetOutBuilder.SetIPProtocol(binding.ProtocolTCP)
+	}
+	packetOutBuilder = packetOutBuilder.SetTCPSrcPort(TCPSrcPort)
+	packetOutBuilder = packetOutBuilder.SetTCPDstPort(TCPDstPort)
+	packetOutBuilder = packetOutBuilder.SetTCPSeqNum(TCPSeqNum)
+	packetOutBuilder = packetOutBuilder.SetTCPAckNum(TCPAckNum)
+	packetOutBuilder = packetOutBuilder.SetTCPFlags(TCPFlags)
+	return c.bridge.SendPacketOut(packetOutBuilder.Done())
+}
+
+func (c *client) SendICMPPacketOut(
+	srcMAC net.HardwareAddr,
+	dstMAC net.HardwareAddr,
+	srcIP net.IP,
+	dstIP net.IP,
+	outPort int32,
+	tunnelDst net.IP,
+	ICMPType uint8,
+	ICMPCode uint8,
+	ICMPData []byte) error {
+	packetOutBuilder, err := c.newDirectPacketOutBuilder(srcMAC, dstMAC, srcIP, dstIP, outPort, tunnelDst)
+	if err != nil {
+		return err
+	}
+	if srcIP.To4() == nil {
+		packetOutBuilder = packetOutBuilder.SetIPProtocol(binding.ProtocolICMPv6)
+	} else {
+		packetOutBuilder = packetOutBuilder.SetIPProtocol(binding.ProtocolICMP)
+	}
+	packetOutBuilder = packetOutBuilder.SetICMPType(ICMPType)
+	packetOutBuilder = packetOutBuilder.SetICMPCode(ICMPCode)
+	packetOutBuilder = packetOutBuilder.SetICMPData(ICMPData)
+	return c.bridge.SendPacketOut(packetOutBuilder.Done())
+}
+
+func (c *client) SendIGMPQueryPacketOut(outPort uint32) error {
+	gatewayConfig := c.nodeConfig.GatewayConfig
+	packetOutBuilder, err := c.newDirectPacketOutBuilder(gatewayConfig.MAC, igmpAllSystemsMAC, gatewayConfig.IPv4, net.IPv4allsys, int32(outPort), nil)
+	if err != nil {
+		return err
+	}
+	// The IGMP messages are link-local.
+	packetOutBuilder = packetOutBuilder.SetTTL(1)
+	packetOutBuilder = packetOutBuilder.SetIPProtocol(binding.ProtocolIGMP)
+	packetOutBuilder = packetOutBuilder.SetICMPType(igmpQueryType)
+	// The code of an IGMPv2 message is its max response time, and the group address of a general query is 0.
+	packetOutBuilder = packetOutBuilder.SetICMPCode(igmpQueryMaxResponseTime)
+	packetOutBuilder = packetOutBuilder.SetICMPData(net.IPv4zero.To4())
+	return c.bridge.SendPacketOut(packetOutBuilder.Done())
+}
+
+// newDirectPacketOutBuilder returns a PacketOutBuilder with the Ethernet and IP headers set, for a packet which is
+// output to outPort without going through the pipeline. If outPort is -1, the packet is resubmitted to the conntrack
+// table instead: the conntrack NAT reverses the DNAT of the connection of the packet, and the packet is then forwarded
+// like the other packets of the connection.
+func (c *client) newDirectPacketOutBuilder(srcMAC, dstMAC net.HardwareAddr, srcIP, dstIP net.IP, outPort int32, tunnelDst net.IP) (binding.PacketOutBuilder, error) {
+	if srcIP == nil || dstIP == nil {
+		return nil, errors.New("invalid IP")
+	}
+	if (srcIP.To4() == nil) != (dstIP.To4() == nil) {
+		return nil, errors.New("IP version mismatch")
+	}
+	packetOutBuilder := c.bridge.BuildPacketOut()
+	packetOutBuilder = packetOutBuilder.SetSrcMAC(srcMAC)
+	packetOutBuilder = packetOutBuilder.SetDstMAC(dstMAC)
+	packetOutBuilder = packetOutBuilder.SetSrcIP(srcIP)
+	packetOutBuilder = packetOutBuilder.SetDstIP(dstIP)
+	packetOutBuilder = packetOutBuilder.SetTTL(64)
+	packetOutBuilder = packetOutBuilder.SetInport(openflow13.P_CONTROLLER)
+	if outPort == -1 {
+		table := uint8(conntrackTable)
+		packetOutBuilder = packetOutBuilder.AddResubmitAction(nil, &table)
+	} else {
+		packetOutBuilder = packetOutBuilder.SetOutport(uint32(outPort))
+	}
+	if tunnelDst != nil {
+		tunnelDstIPv4 := tunnelDst.To4()
+		if tunnelDstIPv4 == nil {
+			return nil, errors.New("IPv6 tunnel destination is not supported")
+		}
+		packetOutBuilder = packetOutBuilder.AddLoadAction(binding.NxmFieldTunIPv4Dst, uint64(binary.BigEndian.Uint32(tunnelDstIPv4)), binding.Range{0, 31})
+	}
+	return packetOutBuilder, nil
+}
+
 func (c *client) InstallTraceflowFlows(dataplaneTag uint8) error {
 	flows := c.traceflowL2ForwardOutputFlows(dataplaneTag, cookie.Default)
 	if err := c.AddAll(flows); err != nil {
@@ -91,7 +91,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -122,7 +122,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -166,7 +166,7 @@ func TestFlowInstallationFailed(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -203,7 +203,7 @@ func TestConcurrentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -469,7 +469,7 @@ func Test_client_SendTraceflowPacket(t *testing.T) {
 }
 
 func prepareTraceflowFlow(ctrl *gomock.Controller) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true, false, false, false)
 	c := ofClient.(*client)
 	c.cookieAllocator = cookie.NewAllocator(0)
 	c.nodeConfig = &config.NodeConfig{}
@@ -487,7 +487,7 @@ func prepareTraceflowFlow(ctrl *gomock.Controller) *client {
 }
 
 func prepareSendTraceflowPacket(ctrl *gomock.Controller, success bool) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true, false, false, false)
 	c := ofClient.(*client)
 	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
 	c.nodeConfig = &config.NodeConfig{GatewayConfig: &config.GatewayConfig{MAC: mac}}
@@ -500,3 +500,275 @@ func prepareSendTraceflowPacket(ctrl *gomock.Controller, success bool) *client {
 	}
 	return c
 }
+
+func TestInstallClusterServiceCIDRFlows(t *testing.T) {
+	_, serviceCIDRv4, _ := net.ParseCIDR("10.96.0.0/12")
+	_, serviceCIDRv6, _ := net.ParseCIDR("fd00:10:96::/112")
+	testCases := []struct {
+		name         string
+		gatewayIPv4  net.IP
+		gatewayIPv6  net.IP
+		serviceCIDRs []*net.IPNet
+		numFlows     int
+		wantErr      bool
+	}{
+		{"IPv4", net.ParseIP("10.10.0.1"), nil, []*net.IPNet{serviceCIDRv4}, 1, false},
+		{"IPv6", nil, net.ParseIP("fd00:10:10::1"), []*net.IPNet{serviceCIDRv6}, 1, false},
+		{"DualStack", net.ParseIP("10.10.0.1"), net.ParseIP("fd00:10:10::1"), []*net.IPNet{serviceCIDRv4, serviceCIDRv6}, 2, false},
+		{"IPv6WithoutGatewayIPv6", net.ParseIP("10.10.0.1"), nil, []*net.IPNet{serviceCIDRv4, serviceCIDRv6}, 0, true},
+		{"IPv4WithoutGatewayIPv4", nil, net.ParseIP("fd00:10:10::1"), []*net.IPNet{serviceCIDRv4}, 0, true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			m := oftest.NewMockOFEntryOperations(ctrl)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, false, false)
+			client := ofClient.(*client)
+			client.cookieAllocator = cookie.NewAllocator(0)
+			client.ofEntryOperations = m
+			client.nodeConfig = &config.NodeConfig{GatewayConfig: &config.GatewayConfig{IPv4: tc.gatewayIPv4, IPv6: tc.gatewayIPv6}}
+
+			if !tc.wantErr {
+				m.EXPECT().AddAll(gomock.Any()).Return(nil).Times(1)
+			}
+			err := ofClient.InstallClusterServiceCIDRFlows(tc.serviceCIDRs)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, client.defaultServiceFlows, tc.numFlows)
+		})
+	}
+}
+
+func TestMulticastRemoteForwardingFlow(t *testing.T) {
+	peer1 := &multicastPeer{tunnelPeerIP: net.ParseIP("192.168.0.2")}
+	peer2 := &multicastPeer{tunnelPeerIP: net.ParseIP("192.168.0.3"), tunOFPort: 5}
+	for _, tc := range []struct {
+		name            string
+		encapMode       config.TrafficEncapModeType
+		peers           map[string]*multicastPeer
+		expectedOutputs []int
+		expectedTunDsts []net.IP
+	}{
+		{
+			name:      "encap",
+			encapMode: config.TrafficEncapModeEncap,
+			peers:     map[string]*multicastPeer{"node2": peer1, "node3": peer2},
+			// The default tunnel port is used when the peer has no tunnel port.
+			expectedOutputs: []int{int(config.DefaultTunOFPort), 5},
+			expectedTunDsts: []net.IP{peer1.tunnelPeerIP, peer2.tunnelPeerIP},
+		},
+		{
+			name:            "hybrid",
+			encapMode:       config.TrafficEncapModeHybrid,
+			peers:           map[string]*multicastPeer{"node2": peer1},
+			expectedOutputs: []int{int(config.DefaultTunOFPort), int(config.HostGatewayOFPort)},
+			expectedTunDsts: []net.IP{peer1.tunnelPeerIP},
+		},
+		{
+			name:            "noEncap",
+			encapMode:       config.TrafficEncapModeNoEncap,
+			expectedOutputs: []int{int(config.HostGatewayOFPort)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			table := createMockTable(ctrl, multicastTable, multicastGroupTable, ofconfig.TableMissActionNext)
+			builder := ovsoftest.NewMockFlowBuilder(ctrl)
+			action := ovsoftest.NewMockAction(ctrl)
+			flow := ovsoftest.NewMockFlow(ctrl)
+			c := &client{
+				pipeline:        map[ofconfig.TableIDType]ofconfig.Table{multicastTable: table},
+				encapMode:       tc.encapMode,
+				cookieAllocator: cookie.NewAllocator(0),
+			}
+
+			table.EXPECT().BuildFlow(priorityNormal).Return(builder)
+			builder.EXPECT().MatchProtocol(ofconfig.ProtocolIP).Return(builder)
+			// Only the traffic sent by the local Pods is forwarded to the other Nodes.
+			builder.EXPECT().MatchRegRange(int(marksReg), uint32(markTrafficFromLocal), ofconfig.Range{0, 15}).Return(builder)
+			builder.EXPECT().Action().Return(action).AnyTimes()
+			for _, tunDst := range tc.expectedTunDsts {
+				action.EXPECT().SetTunnelDst(tunDst).Return(builder)
+			}
+			for _, port := range tc.expectedOutputs {
+				action.EXPECT().Output(port).Return(builder)
+			}
+			action.EXPECT().GotoTable(multicastGroupTable).Return(builder)
+			builder.EXPECT().Cookie(gomock.Any()).Return(builder)
+			builder.EXPECT().Done().Return(flow)
+
+			assert.Equal(t, flow, c.multicastRemoteForwardingFlow(tc.peers, cookie.Multicast))
+		})
+	}
+}
+
+// expectMulticastReceiverGroup sets the expectations of building the Group replicating the multicast traffic to the
+// receiverOFPorts with the bridge.
+func expectMulticastReceiverGroup(ctrl *gomock.Controller, bridge *ovsoftest.MockBridge, groupID ofconfig.GroupIDType, receiverOFPorts []uint32) *ovsoftest.MockGroup {
+	group := ovsoftest.NewMockGroup(ctrl)
+	bucket := ovsoftest.NewMockBucketBuilder(ctrl)
+	bridge.EXPECT().CreateGroupTypeAll(groupID).Return(group)
+	group.EXPECT().ResetBuckets().Return(group)
+	group.EXPECT().Bucket().Return(bucket).Times(len(receiverOFPorts))
+	for _, ofPort := range receiverOFPorts {
+		bucket.EXPECT().LoadRegRange(int(PortCacheReg), ofPort, ofPortRegRange).Return(bucket)
+	}
+	bucket.EXPECT().LoadRegRange(int(marksReg), portFoundMark, ofPortMarkRange).Return(bucket).Times(len(receiverOFPorts))
+	// Each copy goes through the ingress NetworkPolicies of its receiver.
+	bucket.EXPECT().ResubmitToTable(IngressRuleTable).Return(bucket).Times(len(receiverOFPorts))
+	bucket.EXPECT().Done().Return(group).Times(len(receiverOFPorts))
+	return group
+}
+
+func TestMulticastReceiverGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bridge := ovsoftest.NewMockBridge(ctrl)
+	c := &client{bridge: bridge, ingressEntryTable: IngressRuleTable}
+
+	group := expectMulticastReceiverGroup(ctrl, bridge, 10, []uint32{3, 4})
+	assert.Equal(t, group, c.multicastReceiverGroup(10, []uint32{3, 4}))
+}
+
+func TestInstallMulticastGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false, true, false)
+	c := ofClient.(*client)
+	c.cookieAllocator = cookie.NewAllocator(0)
+	c.ofEntryOperations = m
+	bridge := ovsoftest.NewMockBridge(ctrl)
+	c.bridge = bridge
+	groupIP := net.ParseIP("239.1.1.1")
+
+	// The Group IDs are allocated downwards from maxGroupID.
+	groupID := ofconfig.GroupIDType(maxGroupID)
+	group := expectMulticastReceiverGroup(ctrl, bridge, groupID, []uint32{3})
+	m.EXPECT().AddOFEntries(gomock.Any()).DoAndReturn(func(entries []ofconfig.OFEntry) error {
+		require.Len(t, entries, 2)
+		// The Group is installed before the flow using it.
+		assert.Equal(t, group, entries[0])
+		assert.Contains(t, entries[1].(ofconfig.Flow).MatchString(), "nw_dst=239.1.1.1")
+		return nil
+	})
+	require.NoError(t, c.InstallMulticastGroup(groupIP, []uint32{3}))
+
+	// Updating the receivers modifies the Group in place, and doesn't re-install the flow.
+	group = expectMulticastReceiverGroup(ctrl, bridge, groupID, []uint32{3, 4})
+	group.EXPECT().Modify().Return(nil)
+	require.NoError(t, c.InstallMulticastGroup(groupIP, []uint32{3, 4}))
+	obj, ok := c.multicastGroupCache.Load(groupIP.String())
+	require.True(t, ok)
+	assert.Equal(t, []uint32{3, 4}, obj.(*multicastGroup).receiverOFPorts)
+
+	// Another multicast group gets the next Group ID.
+	expectMulticastReceiverGroup(ctrl, bridge, groupID-1, []uint32{4})
+	m.EXPECT().AddOFEntries(gomock.Any()).Return(nil)
+	require.NoError(t, c.InstallMulticastGroup(net.ParseIP("239.2.2.2"), []uint32{4}))
+}
+
+func TestUninstallMulticastGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	bridge := ovsoftest.NewMockBridge(ctrl)
+	c := &client{ofEntryOperations: m, bridge: bridge}
+	groupIP := net.ParseIP("239.1.1.1")
+	flow := ovsoftest.NewMockFlow(ctrl)
+	c.multicastGroupCache.Store(groupIP.String(), &multicastGroup{groupID: 10, receiverOFPorts: []uint32{3}, flow: flow})
+
+	// The flow is removed before the Group it depends on.
+	gomock.InOrder(
+		m.EXPECT().Delete(flow).Return(nil),
+		bridge.EXPECT().DeleteGroup(ofconfig.GroupIDType(10)).Return(true),
+	)
+	require.NoError(t, c.UninstallMulticastGroup(groupIP))
+	_, ok := c.multicastGroupCache.Load(groupIP.String())
+	assert.False(t, ok)
+
+	// Uninstalling a group which is not installed does nothing.
+	require.NoError(t, c.UninstallMulticastGroup(groupIP))
+}
+
+func TestAllocateBandwidthQuotaMeterID(t *testing.T) {
+	firstID, lastID := bandwidthQuotaMeterIDRange()
+	// The IDs of the bandwidth quotas must not overlap with the IDs of the packet-in and Pod bandwidth Meters.
+	assert.Greater(t, uint32(firstID), uint32(podIngressMeterID(maxOFPort)))
+	assert.Greater(t, uint32(podEgressMeterID(1)), uint32(packetInMeterID(PacketInReasonMC)))
+	assert.Equal(t, ofconfig.MeterIDType(maxBandwidthQuotas), lastID-firstID+1)
+
+	c := &client{}
+	id, err := c.allocateBandwidthQuotaMeterID()
+	require.NoError(t, err)
+	assert.Equal(t, firstID, id)
+
+	c.bandwidthQuotaCache.Store("quota1", &bandwidthQuota{meterID: firstID})
+	id, err = c.allocateBandwidthQuotaMeterID()
+	require.NoError(t, err)
+	assert.Equal(t, firstID+1, id)
+
+	for i := ofconfig.MeterIDType(0); i < maxBandwidthQuotas; i++ {
+		c.bandwidthQuotaCache.Store(fmt.Sprintf("quota-%d", i), &bandwidthQuota{meterID: firstID + i})
+	}
+	_, err = c.allocateBandwidthQuotaMeterID()
+	assert.Error(t, err)
+}
+
+func TestUninstallPodBandwidthFlows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	bridge := ovsoftest.NewMockBridge(ctrl)
+	c := &client{ofEntryOperations: m, bridge: bridge}
+	flows := []ofconfig.Flow{ovsoftest.NewMockFlow(ctrl), ovsoftest.NewMockFlow(ctrl)}
+	c.podBandwidthCache.Store("pod1-eth0", &podBandwidth{
+		ingressRate: 1000000,
+		egressRate:  2000000,
+		meterIDs:    []ofconfig.MeterIDType{podEgressMeterID(3), podIngressMeterID(3)},
+		flows:       flows,
+	})
+
+	// The flows are removed before the Meters they depend on, and the Meters are released from the bridge.
+	gomock.InOrder(
+		m.EXPECT().DeleteAll(flows).Return(nil),
+		bridge.EXPECT().DeleteMeter(podEgressMeterID(3)).Return(true),
+		bridge.EXPECT().DeleteMeter(podIngressMeterID(3)).Return(true),
+	)
+	require.NoError(t, c.UninstallPodBandwidthFlows("pod1-eth0"))
+	_, ok := c.podBandwidthCache.Load("pod1-eth0")
+	assert.False(t, ok)
+
+	// Uninstalling the bandwidth limit of a Pod which has none does nothing.
+	require.NoError(t, c.UninstallPodBandwidthFlows("pod1-eth0"))
+}
+
+func TestUninstallBandwidthQuotaFlows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	bridge := ovsoftest.NewMockBridge(ctrl)
+	c := &client{ofEntryOperations: m, bridge: bridge}
+	firstID, _ := bandwidthQuotaMeterIDRange()
+	flow := ovsoftest.NewMockFlow(ctrl)
+	c.bandwidthQuotaCache.Store("quota1", &bandwidthQuota{
+		meterID:    firstID,
+		podOFPorts: map[string]uint32{"pod1-eth0": 3},
+		podFlows:   map[string]ofconfig.Flow{"pod1-eth0": flow},
+	})
+
+	gomock.InOrder(
+		m.EXPECT().DeleteAll([]ofconfig.Flow{flow}).Return(nil),
+		bridge.EXPECT().DeleteMeter(firstID).Return(false),
+	)
+	assert.Error(t, c.UninstallBandwidthQuotaFlows("quota1"))
+	// The quota is kept in the cache when its Meter could not be deleted, so that the deletion can be retried.
+	_, ok := c.bandwidthQuotaCache.Load("quota1")
+	assert.True(t, ok)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog"
+
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+// The reasons for which packets are dropped by the pipeline, used as the "reason" label of the dropped packet metric.
+const (
+	// dropReasonClassifierMiss is used for packets received from an unknown OVS port.
+	dropReasonClassifierMiss = "classifier_miss"
+	// dropReasonSpoofGuard is used for packets whose source MAC or IP does not match the Pod sending them.
+	dropReasonSpoofGuard = "spoofguard"
+	// dropReasonARPResponderMiss is used for ARP packets which can neither be replied nor forwarded.
+	dropReasonARPResponderMiss = "arp_responder_miss"
+	// dropReasonL2ForwardingOutMiss is used for packets whose output port could not be resolved.
+	dropReasonL2ForwardingOutMiss = "l2_forwarding_out_miss"
+	// dropReasonEgressDefaultDeny is used for packets sent by Pods isolated by egress NetworkPolicies, which are not
+	// allowed by any rule.
+	dropReasonEgressDefaultDeny = "egress_default_deny"
+	// dropReasonIngressDefaultDeny is used for packets sent to Pods isolated by ingress NetworkPolicies, which are
+	// not allowed by any rule.
+	dropReasonIngressDefaultDeny = "ingress_default_deny"
+	// dropReasonEgressAntreaPolicy is used for packets sent by Pods which are dropped or rejected by an egress rule
+	// of an Antrea-native policy.
+	dropReasonEgressAntreaPolicy = "egress_antrea_policy"
+	// dropReasonIngressAntreaPolicy is used for packets sent to Pods which are dropped or rejected by an ingress
+	// rule of an Antrea-native policy.
+	dropReasonIngressAntreaPolicy = "ingress_antrea_policy"
+)
+
+// dropCounter describes the drop flows which are counted for a drop reason: either the table-miss flow of the table,
+// or all the drop flows of the table. A flow which only sends packets to the controller, possibly through a Meter, is
+// considered as a drop flow, e.g. the spoof guard violation flows installed in drop mode.
+type dropCounter struct {
+	reason    string
+	table     binding.TableIDType
+	tableMiss bool
+}
+
+var dropCounters = []dropCounter{
+	{reason: dropReasonClassifierMiss, table: ClassifierTable, tableMiss: true},
+	{reason: dropReasonSpoofGuard, table: spoofGuardTable},
+	{reason: dropReasonARPResponderMiss, table: arpResponderTable, tableMiss: true},
+	{reason: dropReasonL2ForwardingOutMiss, table: L2ForwardingOutTable, tableMiss: true},
+	{reason: dropReasonEgressDefaultDeny, table: EgressDefaultTable},
+	{reason: dropReasonIngressDefaultDeny, table: IngressDefaultTable},
+	// The packets dropped by the Antrea-native policy rules are marked in the policy tables, and dropped in the metric
+	// tables once the rule metrics are updated.
+	{reason: dropReasonEgressAntreaPolicy, table: EgressMetricTable},
+	{reason: dropReasonIngressAntreaPolicy, table: IngressMetricTable},
+}
+
+// NetworkPolicyDropReasons are the drop reasons of the packets dropped by the NetworkPolicies.
+var NetworkPolicyDropReasons = []string{
+	dropReasonEgressDefaultDeny,
+	dropReasonIngressDefaultDeny,
+	dropReasonEgressAntreaPolicy,
+	dropReasonIngressAntreaPolicy,
+}
+
+var (
+	flowPriorityRegex = regexp.MustCompile(`priority=(\d+)`)
+	flowPacketsRegex  = regexp.MustCompile(`n_packets=(\d+)`)
+	dropActionsRegex  = regexp.MustCompile(`actions=(drop|(meter:\d+,)?controller\([^)]*\))$`)
+)
+
+func (c *client) DroppedPacketCounts() map[string]uint64 {
+	counts := make(map[string]uint64, len(dropCounters))
+	for _, counter := range dropCounters {
+		flows, err := c.ovsctlClient.DumpTableFlows(uint8(counter.table))
+		if err != nil {
+			klog.Errorf("Failed to dump flows of table %d: %v", counter.table, err)
+			continue
+		}
+		counts[counter.reason] = countDroppedPackets(flows, counter.tableMiss)
+	}
+	return counts
+}
+
+// countDroppedPackets returns the total number of packets matched by the drop flows in the output of
+// "ovs-ofctl dump-flows", e.g.:
+// table=10, n_packets=3, n_bytes=180, priority=0 actions=drop
+// table=10, n_packets=2, n_bytes=120, priority=190,arp actions=meter:3,controller(reason=no_match)
+// If tableMiss is true, only the table-miss flow is considered.
+func countDroppedPackets(flows []string, tableMiss bool) uint64 {
+	var total uint64
+	for _, flow := range flows {
+		if !dropActionsRegex.MatchString(strings.TrimSpace(flow)) {
+			continue
+		}
+		if tableMiss {
+			match := flowPriorityRegex.FindStringSubmatch(flow)
+			if match == nil || match[1] != strconv.Itoa(int(priorityMiss)) {
+				continue
+			}
+		}
+		match := flowPacketsRegex.FindStringSubmatch(flow)
+		if match == nil {
+			continue
+		}
+		packets, _ := strconv.ParseUint(match[1], 10, 64)
+		total += packets
+	}
+	return total
+}
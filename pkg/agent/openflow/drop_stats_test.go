@@ -0,0 +1,77 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
+)
+
+func TestCountDroppedPackets(t *testing.T) {
+	flows := []string{
+		"table=10, n_packets=12, n_bytes=720, priority=200,ip,in_port=3,dl_src=aa:bb:cc:dd:ee:ff,nw_src=10.10.0.2 actions=resubmit(,29)",
+		"table=10, n_packets=3, n_bytes=180, priority=0 actions=drop",
+		"table=10, n_packets=5, n_bytes=300, priority=200,arp,in_port=4 actions=drop",
+		"table=10, n_packets=2, n_bytes=120, priority=190,ip actions=meter:3,controller(reason=no_match)",
+		"table=10, n_packets=1, n_bytes=60, priority=190,ipv6 actions=controller(reason=no_match)",
+		"table=10, n_packets=4, n_bytes=240, priority=190,arp actions=controller(reason=no_match),resubmit(,20)",
+	}
+	assert.Equal(t, uint64(3), countDroppedPackets(flows, true))
+	assert.Equal(t, uint64(11), countDroppedPackets(flows, false))
+	assert.Equal(t, uint64(0), countDroppedPackets(nil, true))
+}
+
+func TestDroppedPacketCounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ovsctlClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	ofClient := &client{ovsctlClient: ovsctlClient}
+
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(ClassifierTable)).Return([]string{"table=0, n_packets=1, n_bytes=60, priority=0 actions=drop"}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(spoofGuardTable)).Return([]string{"table=10, n_packets=2, n_bytes=120, priority=0 actions=drop"}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(arpResponderTable)).Return(nil, fmt.Errorf("ovs-ofctl failed"))
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(L2ForwardingOutTable)).Return([]string{"table=110, n_packets=0, n_bytes=0, priority=0 actions=drop"}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(EgressDefaultTable)).Return([]string{
+		"table=60, n_packets=4, n_bytes=240, priority=200,ip,reg1=0x3 actions=drop",
+		"table=60, n_packets=10, n_bytes=600, priority=0 actions=resubmit(,61)",
+	}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(IngressDefaultTable)).Return([]string{
+		"table=100, n_packets=6, n_bytes=360, priority=200,ip,reg1=0x3 actions=drop",
+		"table=100, n_packets=7, n_bytes=420, priority=200,ip,reg1=0x4 actions=drop",
+	}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(EgressMetricTable)).Return([]string{
+		"table=61, n_packets=8, n_bytes=480, priority=200,ct_state=+new,ct_label=0x1/0xffffffff,ip actions=goto_table:70",
+		"table=61, n_packets=3, n_bytes=180, priority=200,reg0=0x100000/0x100000,reg3=0x2 actions=drop",
+	}, nil)
+	ovsctlClient.EXPECT().DumpTableFlows(uint8(IngressMetricTable)).Return([]string{
+		"table=101, n_packets=5, n_bytes=300, priority=200,reg0=0x100000/0x100000,reg3=0x3 actions=drop",
+	}, nil)
+
+	expected := map[string]uint64{
+		dropReasonClassifierMiss:      1,
+		dropReasonSpoofGuard:          2,
+		dropReasonL2ForwardingOutMiss: 0,
+		dropReasonEgressDefaultDeny:   4,
+		dropReasonIngressDefaultDeny:  13,
+		dropReasonEgressAntreaPolicy:  3,
+		dropReasonIngressAntreaPolicy: 5,
+	}
+	assert.Equal(t, expected, ofClient.DroppedPacketCounts())
+}
@@ -867,12 +867,13 @@ func (c *client) calculateActionFlowChangesForRule(rule *types.PolicyRule) *poli
 		// Install action flows.
 		var actionFlows []binding.Flow
 		var metricFlows []binding.Flow
-		if rule.IsAntreaNetworkPolicyRule() && *rule.Action == secv1alpha1.RuleActionDrop {
+		if rule.IsAntreaNetworkPolicyRule() && (*rule.Action == secv1alpha1.RuleActionDrop || *rule.Action == secv1alpha1.RuleActionReject) {
+			isReject := *rule.Action == secv1alpha1.RuleActionReject
 			metricFlows = append(metricFlows, c.dropRuleMetricFlow(ruleOfID, isIngress))
-			actionFlows = append(actionFlows, c.conjunctionActionDropFlow(ruleOfID, ruleTable.GetID(), rule.Priority, rule.EnableLogging))
+			actionFlows = append(actionFlows, c.conjunctionActionDropFlow(ruleOfID, ruleTable.GetID(), rule.Priority, rule.EnableLogging, isReject, rule.ConnectionStates)...)
 		} else {
 			metricFlows = append(metricFlows, c.allowRulesMetricFlows(ruleOfID, isIngress)...)
-			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable.GetID(), dropTable.GetNext(), rule.Priority, rule.EnableLogging)...)
+			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable.GetID(), dropTable.GetNext(), rule.Priority, rule.EnableLogging, rule.ConnectionStates)...)
 		}
 		conj.actionFlows = actionFlows
 		conj.metricFlows = metricFlows
@@ -280,6 +280,35 @@ func TestInstallPolicyRuleFlows(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestConjunctionActionFlowConnectionStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c = prepareClient(ctrl)
+	c.ipProtocols = []binding.Protocol{binding.ProtocolIP}
+	outTable.EXPECT().BuildFlow(gomock.Any()).Return(newMockRuleFlowBuilder(ctrl)).AnyTimes()
+	ruleID := uint32(101)
+
+	flows := c.conjunctionActionFlow(ruleID, EgressRuleTable, EgressMetricTable, nil, false, nil)
+	assert.Equal(t, 1, len(flows))
+
+	// Established packets are matched with "-new+est" and related packets with "+rel".
+	ruleFlowBuilder.EXPECT().MatchCTStateNew(false).Return(ruleFlowBuilder).Times(1)
+	ruleFlowBuilder.EXPECT().MatchCTStateEst(true).Return(ruleFlowBuilder).Times(1)
+	ruleFlowBuilder.EXPECT().MatchCTStateRel(true).Return(ruleFlowBuilder).Times(1)
+	states := []secv1alpha1.ConnectionState{secv1alpha1.ConnectionStateEstablished, secv1alpha1.ConnectionStateRelated}
+	flows = c.conjunctionActionFlow(ruleID, EgressRuleTable, EgressMetricTable, nil, false, states)
+	assert.Equal(t, 2, len(flows))
+
+	// New packets are matched with "+new-rel".
+	ruleFlowBuilder.EXPECT().MatchCTStateNew(true).Return(ruleFlowBuilder).Times(1)
+	ruleFlowBuilder.EXPECT().MatchCTStateRel(false).Return(ruleFlowBuilder).Times(1)
+	priority := uint16(priorityNormal)
+	states = []secv1alpha1.ConnectionState{secv1alpha1.ConnectionStateNew}
+	flows = c.conjunctionActionDropFlow(ruleID, EgressRuleTable, &priority, false, false, states)
+	assert.Equal(t, 1, len(flows))
+}
+
 func TestBatchInstallPolicyRuleFlows(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
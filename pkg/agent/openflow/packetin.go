@@ -21,6 +21,8 @@ import (
 	"github.com/contiv/ofnet/ofctrl"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
+
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 )
 
 type ofpPacketInReason uint8
@@ -35,8 +37,46 @@ const (
 	// PacketIn reasons
 	PacketInReasonTF ofpPacketInReason = 1
 	PacketInReasonNP ofpPacketInReason = 0
+	// PacketInReasonSG and PacketInReasonMC are not OpenFlow reasons, as OpenFlow 1.3 only defines the no_match,
+	// action and invalid_ttl reasons: the spoof guard violations and the IGMP messages are sent to the controller with
+	// PacketInReasonNP, and are told apart from the NetworkPolicy packet-in messages by the table sending them.
+	PacketInReasonSG ofpPacketInReason = 2
+	PacketInReasonMC ofpPacketInReason = 3
+
+	// maxPacketInMeterID is the highest ID of the Meters rate-limiting the packet-in messages.
+	maxPacketInMeterID = 0xff
+	// packetInMeterRate is the maximum rate of the packet-in messages of each reason, in packets per second. The
+	// packets exceeding it are dropped by OVS, so that the Pods cannot flood the agent with packet-in messages.
+	packetInMeterRate = 100
 )
 
+// meteredPacketInReasons are the packet-in reasons whose messages are rate-limited with an OpenFlow Meter.
+var meteredPacketInReasons = []ofpPacketInReason{PacketInReasonNP, PacketInReasonSG, PacketInReasonMC}
+
+// ofPacketInReason returns the OpenFlow reason of the packet-in messages sent with the provided reason.
+func ofPacketInReason(reason uint8) uint8 {
+	switch ofpPacketInReason(reason) {
+	case PacketInReasonSG, PacketInReasonMC:
+		return uint8(PacketInReasonNP)
+	}
+	return reason
+}
+
+// packetInHandlerReason returns the reason of the handlers processing the packet-in message received with the
+// provided OpenFlow reason, based on the table which sent it to the controller.
+func packetInHandlerReason(ofReason uint8, pktIn *ofctrl.PacketIn) uint8 {
+	if ofpPacketInReason(ofReason) != PacketInReasonNP {
+		return ofReason
+	}
+	switch binding.TableIDType(pktIn.TableId) {
+	case spoofGuardTable:
+		return uint8(PacketInReasonSG)
+	case multicastTable:
+		return uint8(PacketInReasonMC)
+	}
+	return ofReason
+}
+
 // RegisterPacketInHandler stores controller handler in a map of map with reason and name as keys.
 func (c *client) RegisterPacketInHandler(packetHandlerReason uint8, packetHandlerName string, packetInHandler interface{}) {
 	handler, ok := packetInHandler.(PacketInHandler)
@@ -52,14 +92,16 @@ func (c *client) RegisterPacketInHandler(packetHandlerReason uint8, packetHandle
 
 // featureStartPacketIn contains packetin resources specifically for each feature that uses packetin.
 type featureStartPacketIn struct {
-	reason        uint8
-	subscribeCh   chan *ofctrl.PacketIn
-	stopCh        <-chan struct{}
-	packetInQueue *workqueue.Type
+	// reason is the OpenFlow reason subscribed to, and handlerReasons are the reasons of the features sharing it.
+	reason         uint8
+	handlerReasons map[uint8]bool
+	subscribeCh    chan *ofctrl.PacketIn
+	stopCh         <-chan struct{}
+	packetInQueue  *workqueue.Type
 }
 
 func newfeatureStartPacketIn(reason uint8, stopCh <-chan struct{}) *featureStartPacketIn {
-	featurePacketIn := featureStartPacketIn{reason: reason, stopCh: stopCh}
+	featurePacketIn := featureStartPacketIn{reason: reason, handlerReasons: map[uint8]bool{}, stopCh: stopCh}
 	featurePacketIn.subscribeCh = make(chan *ofctrl.PacketIn)
 	featurePacketIn.packetInQueue = workqueue.NewNamed(string(reason))
 	return &featurePacketIn
@@ -88,9 +130,19 @@ func (c *client) StartPacketInHandler(packetInStartedReason []uint8, stopCh <-ch
 		return
 	}
 
-	// Iterate through each feature that starts packetin. Subscribe with their specified reason.
+	// Iterate through each feature that starts packetin. Subscribe once to the OpenFlow reason of their specified
+	// reason, as several features can share it.
+	featurePacketIns := map[uint8]*featureStartPacketIn{}
 	for _, reason := range packetInStartedReason {
-		featurePacketIn := newfeatureStartPacketIn(reason, stopCh)
+		ofReason := ofPacketInReason(reason)
+		featurePacketIn, ok := featurePacketIns[ofReason]
+		if !ok {
+			featurePacketIn = newfeatureStartPacketIn(ofReason, stopCh)
+			featurePacketIns[ofReason] = featurePacketIn
+		}
+		featurePacketIn.handlerReasons[reason] = true
+	}
+	for _, featurePacketIn := range featurePacketIns {
 		err := c.subscribeFeaturePacketIn(featurePacketIn)
 		if err != nil {
 			klog.Errorf("received error %+v while subscribing packetin for each feature", err)
@@ -103,12 +155,12 @@ func (c *client) subscribeFeaturePacketIn(featurePacketIn *featureStartPacketIn)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Subscribe %d PacketIn failed %+v", featurePacketIn.reason, err))
 	}
-	go c.parsePacketIn(featurePacketIn.packetInQueue, featurePacketIn.reason)
+	go c.parsePacketIn(featurePacketIn.packetInQueue, featurePacketIn.reason, featurePacketIn.handlerReasons)
 	go featurePacketIn.ListenPacketIn()
 	return nil
 }
 
-func (c *client) parsePacketIn(packetInQueue workqueue.Interface, packetHandlerReason uint8) {
+func (c *client) parsePacketIn(packetInQueue workqueue.Interface, ofReason uint8, handlerReasons map[uint8]bool) {
 	for {
 		obj, quit := packetInQueue.Get()
 		if quit {
@@ -121,6 +173,10 @@ func (c *client) parsePacketIn(packetInQueue workqueue.Interface, packetHandlerR
 			continue
 		}
 		// Use corresponding handlers subscribed to the reason to handle PacketIn
+		packetHandlerReason := packetInHandlerReason(ofReason, pktIn)
+		if !handlerReasons[packetHandlerReason] {
+			continue
+		}
 		for name, handler := range c.packetInHandlers[packetHandlerReason] {
 			err := handler.HandlePacketIn(pktIn)
 			if err != nil {
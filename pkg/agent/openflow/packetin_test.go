@@ -0,0 +1,79 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type fakePacketInHandler struct {
+	packetIns []*ofctrl.PacketIn
+}
+
+func (h *fakePacketInHandler) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	h.packetIns = append(h.packetIns, pktIn)
+	return nil
+}
+
+func TestParsePacketIn(t *testing.T) {
+	npHandler := &fakePacketInHandler{}
+	sgHandler := &fakePacketInHandler{}
+	mcHandler := &fakePacketInHandler{}
+	c := &client{packetInHandlers: map[uint8]map[string]PacketInHandler{}}
+	c.RegisterPacketInHandler(uint8(PacketInReasonNP), "networkpolicy", npHandler)
+	c.RegisterPacketInHandler(uint8(PacketInReasonSG), "spoofguard", sgHandler)
+	c.RegisterPacketInHandler(uint8(PacketInReasonMC), "multicast", mcHandler)
+
+	npPacketIn := &ofctrl.PacketIn{TableId: uint8(IngressRuleTable)}
+	sgPacketIn := &ofctrl.PacketIn{TableId: uint8(spoofGuardTable)}
+	mcPacketIn := &ofctrl.PacketIn{TableId: uint8(multicastTable)}
+	queue := workqueue.New()
+	queue.Add(npPacketIn)
+	queue.Add(sgPacketIn)
+	queue.Add(mcPacketIn)
+	queue.ShutDown()
+	c.parsePacketIn(queue, ofPacketInReason(uint8(PacketInReasonMC)), map[uint8]bool{
+		uint8(PacketInReasonNP): true,
+		uint8(PacketInReasonSG): true,
+		uint8(PacketInReasonMC): true,
+	})
+
+	assert.Equal(t, []*ofctrl.PacketIn{npPacketIn}, npHandler.packetIns)
+	assert.Equal(t, []*ofctrl.PacketIn{sgPacketIn}, sgHandler.packetIns)
+	assert.Equal(t, []*ofctrl.PacketIn{mcPacketIn}, mcHandler.packetIns)
+}
+
+func TestParsePacketInNotStarted(t *testing.T) {
+	npHandler := &fakePacketInHandler{}
+	sgHandler := &fakePacketInHandler{}
+	c := &client{packetInHandlers: map[uint8]map[string]PacketInHandler{}}
+	c.RegisterPacketInHandler(uint8(PacketInReasonNP), "networkpolicy", npHandler)
+	c.RegisterPacketInHandler(uint8(PacketInReasonSG), "spoofguard", sgHandler)
+
+	// Only the spoof guard feature started the packet-in messages: the NetworkPolicy handler must not receive any.
+	sgPacketIn := &ofctrl.PacketIn{TableId: uint8(spoofGuardTable)}
+	queue := workqueue.New()
+	queue.Add(&ofctrl.PacketIn{TableId: uint8(IngressRuleTable)})
+	queue.Add(sgPacketIn)
+	queue.ShutDown()
+	c.parsePacketIn(queue, uint8(PacketInReasonNP), map[uint8]bool{uint8(PacketInReasonSG): true})
+
+	assert.Empty(t, npHandler.packetIns)
+	assert.Equal(t, []*ofctrl.PacketIn{sgPacketIn}, sgHandler.packetIns)
+}
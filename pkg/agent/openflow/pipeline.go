@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/contiv/ofnet/ofctrl"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
 	"github.com/vmware-tanzu/antrea/pkg/util/runtime"
@@ -57,6 +59,9 @@ const (
 	EgressMetricTable            binding.TableIDType = 61
 	l3ForwardingTable            binding.TableIDType = 70
 	l3DecTTLTable                binding.TableIDType = 71
+	multicastTable               binding.TableIDType = 72
+	multicastGroupTable          binding.TableIDType = 73
+	snatTable                    binding.TableIDType = 75
 	l2ForwardingCalcTable        binding.TableIDType = 80
 	AntreaPolicyIngressRuleTable binding.TableIDType = 85
 	DefaultTierIngressRuleTable  binding.TableIDType = 89
@@ -84,6 +89,12 @@ const (
 	markTrafficFromUplink  = 4
 	markTrafficFromBridge  = 5
 
+	// IPv4 multicast prefix
+	ipv4MulticastAddr = "224.0.0.0/4"
+	// IGMP message type of the membership queries
+	igmpQueryType = 0x11
+	// Max response time of the IGMPv2 queries sent by the agent, in 1/10 seconds
+	igmpQueryMaxResponseTime = 100
 	// IPv6 multicast prefix
 	ipv6MulticastAddr = "FF00::/8"
 	// IPv6 link-local prefix
@@ -91,6 +102,9 @@ const (
 )
 
 var (
+	// igmpAllSystemsMAC is the MAC address of the all-systems multicast group 224.0.0.1.
+	igmpAllSystemsMAC, _ = net.ParseMAC("01:00:5e:00:00:01")
+
 	// egressTables map records all IDs of tables related to
 	// egress rules.
 	egressTables = map[binding.TableIDType]struct{}{
@@ -120,6 +134,9 @@ var (
 		{EgressDefaultTable, "EgressDefaultRule"},
 		{EgressMetricTable, "EgressMetric"},
 		{l3ForwardingTable, "l3Forwarding"},
+		{multicastTable, "Multicast"},
+		{multicastGroupTable, "MulticastGroup"},
+		{snatTable, "SNAT"},
 		{l2ForwardingCalcTable, "L2Forwarding"},
 		{AntreaPolicyIngressRuleTable, "AntreaPolicyIngressRule"},
 		{IngressRuleTable, "IngressRule"},
@@ -198,7 +215,8 @@ func (rt regType) reg() string {
 
 const (
 	// marksReg stores traffic-source mark and pod-found mark.
-	// traffic-source resides in [0..15], pod-found resides in [16], Antrea Policy disposition Allow or Drop in [21]
+	// traffic-source resides in [0..15], pod-found resides in [16], Antrea Policy disposition Allow or Drop in [21],
+	// Antrea Policy custom reasons of packet-in in [22..23]
 	marksReg        regType = 0
 	PortCacheReg    regType = 1
 	swapReg         regType = 2
@@ -255,6 +273,14 @@ const (
 	// disposition marks the flow action as either Allow or Drop
 	DispositionAllow = 0b0
 	DispositionDrop  = 0b1
+
+	// custom reasons are loaded in marksReg [22..23]
+	CustomReasonMarkReg regType = 0
+	// custom reasons tell the agent how to process the packets sent to the controller with PacketInReasonNP: the
+	// packet is logged if CustomReasonLogging is set, and a reject response is sent back to the source of the packet
+	// if CustomReasonReject is set.
+	CustomReasonLogging = 0b01
+	CustomReasonReject  = 0b10
 )
 
 var DispositionToString = map[uint32]string{
@@ -265,6 +291,9 @@ var DispositionToString = map[uint32]string{
 var (
 	// APDispositionMarkRange takes the 21 to 21 bits of register marksReg to indicate disposition of Antrea Policy.
 	APDispositionMarkRange = binding.Range{21, 21}
+	// CustomReasonMarkRange takes the 22 to 23 bits of register marksReg to indicate the custom reasons of the
+	// packet-in messages sent by Antrea Policy rules.
+	CustomReasonMarkRange = binding.Range{22, 23}
 	// ofPortMarkRange takes the 16th bit of register marksReg to indicate if the ofPort number of an interface
 	// is found or not. Its value is 0x1 if yes.
 	ofPortMarkRange = binding.Range{16, 16}
@@ -280,6 +309,9 @@ var (
 	// if the packet's MAC addresses need to be rewritten. Its value is 0x1 if yes.
 	macRewriteMarkRange = binding.Range{19, 19}
 	cnpDropMarkRange    = binding.Range{20, 20}
+	// snatPktMarkRange takes an 8-bit range of pkt_mark to store the ID of
+	// a SNAT IP. The bit range must match SNATIPMarkMask.
+	snatPktMarkRange = binding.Range{0, 7}
 	// endpointIPRegRange takes a 32-bit range of register endpointIPReg to store
 	// the selected Service Endpoint IP.
 	endpointIPRegRange = binding.Range{0, 31}
@@ -321,6 +353,8 @@ type flowCache map[string]binding.Flow
 
 type flowCategoryCache struct {
 	sync.Map
+	// name is used as the label of the cache size metric.
+	name string
 }
 
 func portToUint16(port int) uint16 {
@@ -334,6 +368,9 @@ func portToUint16(port int) uint16 {
 type client struct {
 	enableProxy                                   bool
 	enableAntreaPolicy                            bool
+	enableEgress                                  bool
+	enableMulticast                               bool
+	enableHardwareOffload                         bool
 	roundInfo                                     types.RoundInfo
 	cookieAllocator                               cookie.Allocator
 	bridge                                        binding.Bridge
@@ -341,9 +378,12 @@ type client struct {
 	ingressEntryTable                             binding.TableIDType
 	pipeline                                      map[binding.TableIDType]binding.Table
 	nodeFlowCache, podFlowCache, serviceFlowCache *flowCategoryCache // cache for corresponding deletions
+	snatFlowCache                                 *flowCategoryCache
 	// "fixed" flows installed by the agent after initialization and which do not change during
 	// the lifetime of the client.
 	gatewayFlows, defaultServiceFlows, defaultTunnelFlows, hostNetworkingFlows []binding.Flow
+	// spoofGuardFlows are installed only when the packets failing the spoof guard checks are reported to the agent.
+	spoofGuardFlows []binding.Flow
 	// ofEntryOperations is a wrapper interface for OpenFlow entry Add / Modify / Delete operations. It
 	// enables convenient mocking in unit tests.
 	ofEntryOperations OFEntryOperations
@@ -352,6 +392,25 @@ type client struct {
 	policyCache       cache.Indexer
 	conjMatchFlowLock sync.Mutex // Lock for access globalConjMatchFlowCache
 	groupCache        sync.Map
+	// podBandwidthCache maps the interface names of the local Pods to their realized *podBandwidth.
+	podBandwidthCache sync.Map
+	// podQoSCache maps the interface names of the local Pods to their realized *podQoS.
+	podQoSCache sync.Map
+	// bandwidthQuotaCache maps the names of the bandwidth quotas to their realized *bandwidthQuota.
+	bandwidthQuotaCache sync.Map
+	// bandwidthQuotaMutex serializes the updates of the bandwidth quotas, as the Meter IDs are allocated among them.
+	bandwidthQuotaMutex sync.Mutex
+	// multicastInitialFlows are installed only when multicast is enabled. multicastRemoteFlow forwards the multicast
+	// traffic sent by the local Pods to the other Nodes, it is re-installed when the tunnel peers change.
+	multicastInitialFlows []binding.Flow
+	multicastRemoteFlow   binding.Flow
+	// multicastPeers maps the names of the remote Nodes reached through the tunnel to their *multicastPeer.
+	multicastPeers map[string]*multicastPeer
+	// multicastGroupCache maps the multicast group addresses to their realized *multicastGroup.
+	multicastGroupCache sync.Map
+	// multicastMutex serializes the updates of the multicast groups and peers, as the Group IDs are allocated
+	// among the groups.
+	multicastMutex sync.Mutex
 	// globalConjMatchFlowCache is a global map for conjMatchFlowContext. The key is a string generated from the
 	// conjMatchFlowContext.
 	globalConjMatchFlowCache map[string]*conjMatchFlowContext
@@ -367,6 +426,11 @@ type client struct {
 	ipProtocols []binding.Protocol
 	// ovsctlClient is the interface for executing OVS "ovs-ofctl" and "ovs-appctl" commands.
 	ovsctlClient ovsctl.OVSCtlClient
+	// ovsCapabilities are the features supported by the OVS bridge, detected before connecting to it.
+	ovsCapabilities *OVSCapabilities
+	// metersEnabled is set when the OpenFlow Meters can be used, as reported by checkMeterSupport when connecting to
+	// the bridge. The packet-in messages are only rate-limited with Meters in that case.
+	metersEnabled bool
 }
 
 func (c *client) GetTunnelVirtualMAC() net.HardwareAddr {
@@ -525,6 +589,142 @@ func (c *client) podClassifierFlow(podOFPort uint32, category cookie.Category) b
 		Done()
 }
 
+// packetInMeterID returns the ID of the Meter rate-limiting the packet-in messages sent with the provided reason.
+// The IDs up to maxPacketInMeterID are reserved for these Meters.
+func packetInMeterID(reason ofpPacketInReason) binding.MeterIDType {
+	return binding.MeterIDType(reason) + 1
+}
+
+// packetInMeter generates the Meter which drops the packets sent to the agent with the provided reason above
+// packetInMeterRate packets per second.
+func (c *client) packetInMeter(reason ofpPacketInReason) binding.Meter {
+	return c.bridge.CreateMeter(packetInMeterID(reason), ofctrl.MeterBurst|ofctrl.MeterPktps).ResetMeterBands().
+		MeterBand().MeterType(ofctrl.MeterDrop).Rate(packetInMeterRate).Burst(2 * packetInMeterRate).Done()
+}
+
+// meterPacketIn adds the action rate-limiting the packets sent to the agent with the provided reason to the flow,
+// when the packet-in Meters are enabled. Otherwise, the packet-in messages are only limited by the size of the
+// packet-in queue of the reason in the agent.
+func (c *client) meterPacketIn(flowBuilder binding.FlowBuilder, reason ofpPacketInReason) binding.FlowBuilder {
+	if !c.metersEnabled {
+		return flowBuilder
+	}
+	return flowBuilder.Action().Meter(packetInMeterID(reason))
+}
+
+// podEgressMeterID returns the ID of the Meter limiting the bandwidth of the traffic sent by the Pod connected to
+// podOFPort, and podIngressMeterID the one of the traffic sent to the Pod. They are derived from the ofPort so that
+// they are unique among the local Pods, and start after the IDs reserved for the packet-in Meters.
+func podEgressMeterID(podOFPort uint32) binding.MeterIDType {
+	return maxPacketInMeterID + 1 + binding.MeterIDType(podOFPort<<1)
+}
+
+func podIngressMeterID(podOFPort uint32) binding.MeterIDType {
+	return maxPacketInMeterID + 1 + binding.MeterIDType(podOFPort<<1|1)
+}
+
+// bandwidthQuotaMeterIDRange returns the first and the last IDs of the Meters of the bandwidth quotas. They come
+// after the IDs of the Pod bandwidth Meters, which are bounded by the highest ofPort allocated by OVS.
+func bandwidthQuotaMeterIDRange() (binding.MeterIDType, binding.MeterIDType) {
+	first := podIngressMeterID(maxOFPort) + 1
+	return first, first + maxBandwidthQuotas - 1
+}
+
+// bandwidthMeter generates the Meter which drops the packets exceeding the provided rate, in bits per second. The
+// burst size is set to the amount of traffic allowed in one second.
+func (c *client) bandwidthMeter(meterID binding.MeterIDType, rate uint64) binding.Meter {
+	rateKbps := uint32(math.MaxUint32)
+	if rate/1000 < math.MaxUint32 {
+		rateKbps = uint32(rate / 1000)
+	}
+	return c.bridge.CreateMeter(meterID, ofctrl.MeterBurst|ofctrl.MeterKbps).ResetMeterBands().
+		MeterBand().MeterType(ofctrl.MeterDrop).Rate(rateKbps).Burst(rateKbps).Done()
+}
+
+// podEgressMeterFlow generates the flow to meter the traffic sent by the Pod connected to podOFPort. It takes
+// precedence over the podClassifierFlow of the Pod, and marks the traffic the same way.
+func (c *client) podEgressMeterFlow(podOFPort uint32, meterID binding.MeterIDType, category cookie.Category) binding.Flow {
+	classifierTable := c.pipeline[ClassifierTable]
+	return classifierTable.BuildFlow(priorityNormal).
+		MatchInPort(podOFPort).
+		Action().Meter(meterID).
+		Action().LoadRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+		Action().GotoTable(classifierTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
+// bandwidthQuotaFlow generates the flow to meter the traffic sent by the Pod connected to podOFPort with the Meter of
+// its bandwidth quota. It takes precedence over the podEgressMeterFlow of the Pod, as a flow can only use one Meter
+// with OpenFlow 1.3 and the quota of a tenant must not be bypassed with the annotations of its Pods.
+func (c *client) bandwidthQuotaFlow(podOFPort uint32, meterID binding.MeterIDType, category cookie.Category) binding.Flow {
+	classifierTable := c.pipeline[ClassifierTable]
+	return classifierTable.BuildFlow(priorityNormal+1).
+		MatchInPort(podOFPort).
+		Action().Meter(meterID).
+		Action().LoadRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+		Action().GotoTable(classifierTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
+// podIngressMeterFlow generates the flow to meter the traffic output to the Pod connected to podOFPort. It takes
+// precedence over the l2ForwardOutputFlows.
+func (c *client) podIngressMeterFlow(podOFPort uint32, meterID binding.MeterIDType, category cookie.Category) binding.Flow {
+	return c.pipeline[L2ForwardingOutTable].BuildFlow(priorityNormal+1).
+		MatchRegRange(int(marksReg), portFoundMark, ofPortMarkRange).
+		MatchRegRange(int(PortCacheReg), podOFPort, ofPortRegRange).
+		Action().Meter(meterID).
+		Action().Output(int(podOFPort)).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
+// podQoSQueueFlows generates the flows to send the traffic output by the Pod connected to podOFPort to each of the
+// queuePorts, e.g. the uplink, to the QoS queue queueID of the port. If dscp is not 0, the DSCP field of the IP
+// packets output to each of the outPorts, e.g. the host gateway and the tunnel port, is also set to dscp so that the
+// underlay network can prioritize them, which requires a flow per IP version. The flows take precedence over the
+// l2ForwardOutputFlows, but not over the traceflowL2ForwardOutputFlows, so that the Traceflow packets keep their
+// dataplane tag.
+func (c *client) podQoSQueueFlows(podOFPort uint32, queueID uint32, dscp uint8, outPorts, queuePorts []uint32, category cookie.Category) []binding.Flow {
+	var flows []binding.Flow
+	for _, outPort := range outPorts {
+		setQueue := false
+		for _, queuePort := range queuePorts {
+			if queuePort == outPort {
+				setQueue = true
+			}
+		}
+		if dscp == 0 {
+			if setQueue {
+				flows = append(flows, c.pipeline[L2ForwardingOutTable].BuildFlow(priorityNormal+1).
+					MatchInPort(podOFPort).
+					MatchRegRange(int(marksReg), portFoundMark, ofPortMarkRange).
+					MatchRegRange(int(PortCacheReg), outPort, ofPortRegRange).
+					Action().SetQueue(queueID).
+					Action().Output(int(outPort)).
+					Cookie(c.cookieAllocator.Request(category).Raw()).
+					Done())
+			}
+			continue
+		}
+		for _, ipProtocol := range []binding.Protocol{binding.ProtocolIP, binding.ProtocolIPv6} {
+			fb := c.pipeline[L2ForwardingOutTable].BuildFlow(priorityNormal+1).MatchProtocol(ipProtocol).
+				MatchInPort(podOFPort).
+				MatchRegRange(int(marksReg), portFoundMark, ofPortMarkRange).
+				MatchRegRange(int(PortCacheReg), outPort, ofPortRegRange).
+				Action().SetIPDscp(dscp)
+			if setQueue {
+				fb = fb.Action().SetQueue(queueID)
+			}
+			flows = append(flows, fb.Action().Output(int(outPort)).
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done())
+		}
+	}
+	return flows
+}
+
 // connectionTrackFlows generates flows that redirect traffic to ct_zone and handle traffic according to ct_state:
 // 1) commit new connections to ct_zone(0xfff0) in the conntrackCommitTable.
 // 2) Add ct_mark on the packet if it is sent to the switch from the host gateway.
@@ -765,6 +965,22 @@ func (c *client) l2ForwardCalcFlow(dstMAC net.HardwareAddr, ofPort uint32, skipI
 	// the default flow of L2ForwardingOutTable.
 }
 
+// l2ForwardCalcFlowToTunnelPeer generates the L2Forward flow to output the
+// packets to a remote Node through the Node's tunnel port, instead of the
+// default tunnel port. The tunnel destination has been set to the remote Node
+// IP in L3ForwardingTable.
+func (c *client) l2ForwardCalcFlowToTunnelPeer(tunnelPeer net.IP, ofPort uint32, category cookie.Category) binding.Flow {
+	l2FwdCalcTable := c.pipeline[l2ForwardingCalcTable]
+	return l2FwdCalcTable.BuildFlow(priorityHigh).
+		MatchDstMAC(globalVirtualMAC).
+		MatchTunnelDst(tunnelPeer).
+		Action().LoadRegRange(int(PortCacheReg), ofPort, ofPortRegRange).
+		Action().LoadRegRange(int(marksReg), portFoundMark, ofPortMarkRange).
+		Action().GotoTable(l2FwdCalcTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
 // traceflowL2ForwardOutputFlows generates Traceflow specific flows that outputs traceflow packets to OVS port and Antrea
 // Agent after L2forwarding calculation.
 func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, category cookie.Category) []binding.Flow {
@@ -773,7 +989,7 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, category cook
 	// The gw0 IP as Traceflow destination is not supported.
 	if c.encapMode.SupportsEncap() {
 		flows = append(flows, c.pipeline[L2ForwardingOutTable].BuildFlow(priorityNormal+3).
-			MatchReg(int(PortCacheReg), config.DefaultTunOFPort).
+			MatchDstMAC(globalVirtualMAC).
 			MatchIPDscp(dataplaneTag).
 			SetHardTimeout(300).
 			MatchProtocol(binding.ProtocolIP).
@@ -783,7 +999,7 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, category cook
 			Cookie(c.cookieAllocator.Request(category).Raw()).
 			Done())
 		flows = append(flows, c.pipeline[L2ForwardingOutTable].BuildFlow(priorityNormal+3).
-			MatchReg(int(PortCacheReg), config.DefaultTunOFPort).
+			MatchDstMAC(globalVirtualMAC).
 			MatchIPDscp(dataplaneTag).
 			SetHardTimeout(300).
 			MatchProtocol(binding.ProtocolIPv6).
@@ -1042,6 +1258,34 @@ func (c *client) podIPSpoofGuardFlow(ifIPs []net.IP, ifMAC net.HardwareAddr, ifO
 	return flows
 }
 
+// spoofGuardViolationFlows generates the flows which send the packets which did not match any of the spoof guard flows
+// of the Pods and of the gateway interface to the agent, so that the violations can be reported. If logOnly is true,
+// the packets are then forwarded to the same tables as legitimate packets, otherwise they are dropped.
+func (c *client) spoofGuardViolationFlows(logOnly bool, category cookie.Category) []binding.Flow {
+	table := c.pipeline[spoofGuardTable]
+	var flows []binding.Flow
+	for _, proto := range append([]binding.Protocol{binding.ProtocolARP}, c.ipProtocols...) {
+		flowBuilder := table.BuildFlow(priorityLow).MatchProtocol(proto)
+		// The dropped packets sent to the agent are rate-limited. The forwarded packets are not, as the Meter would
+		// drop them.
+		if !logOnly {
+			flowBuilder = c.meterPacketIn(flowBuilder, PacketInReasonSG)
+		}
+		flowBuilder = flowBuilder.Action().SendToController(ofPacketInReason(uint8(PacketInReasonSG)))
+		if logOnly {
+			nextTable := table.GetNext()
+			if proto == binding.ProtocolARP {
+				nextTable = arpResponderTable
+			} else if proto == binding.ProtocolIPv6 {
+				nextTable = ipv6Table
+			}
+			flowBuilder = flowBuilder.Action().GotoTable(nextTable)
+		}
+		flows = append(flows, flowBuilder.Cookie(c.cookieAllocator.Request(category).Raw()).Done())
+	}
+	return flows
+}
+
 func getIPProtocol(ip net.IP) binding.Protocol {
 	var ipProtocol binding.Protocol
 	if ip.To4() != nil {
@@ -1128,7 +1372,8 @@ func (c *client) gatewayIPSpoofGuardFlows(category cookie.Category) []binding.Fl
 	return flows
 }
 
-// serviceCIDRDNATFlow generates flows to match dst IP in service CIDR and output to host gateway interface directly.
+// serviceCIDRDNATFlows generates flows to match dst IP in service CIDR and output to host gateway interface directly.
+// One flow is generated per address family, matching the IP protocol of the CIDR.
 func (c *client) serviceCIDRDNATFlows(serviceCIDRs []*net.IPNet) []binding.Flow {
 	var flows []binding.Flow
 	for _, serviceCIDR := range serviceCIDRs {
@@ -1247,7 +1492,9 @@ func (c *client) ipv6Flows(category cookie.Category) []binding.Flow {
 
 // conjunctionActionFlow generates the flow to jump to a specific table if policyRuleConjunction ID is matched. Priority of
 // conjunctionActionFlow is created at priorityLow for k8s network policies, and *priority assigned by PriorityAssigner for AntreaPolicy.
-func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.TableIDType, nextTable binding.TableIDType, priority *uint16, enableLogging bool) []binding.Flow {
+// If connectionStates is not empty, a flow is generated for each connection state, so that only the packets of
+// connections in these states are matched.
+func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.TableIDType, nextTable binding.TableIDType, priority *uint16, enableLogging bool, connectionStates []secv1alpha1.ConnectionState) []binding.Flow {
 	var ofPriority uint16
 	if priority == nil {
 		ofPriority = priorityLow
@@ -1260,16 +1507,18 @@ func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.Tab
 		conjReg = EgressReg
 		labelRange = metricEgressRuleIDRange
 	}
-	conjActionFlow := func(proto binding.Protocol) binding.Flow {
+	conjActionFlow := func(proto binding.Protocol, state secv1alpha1.ConnectionState) binding.Flow {
 		ctZone := CtZone
 		if proto == binding.ProtocolIPv6 {
 			ctZone = CtZoneV6
 		}
+		flowBuilder := matchConnectionState(c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
+			MatchConjID(conjunctionID), state)
 		if enableLogging {
-			return c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
-				MatchConjID(conjunctionID).
+			return flowBuilder.
 				Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}).       // Traceflow.
 				Action().LoadRegRange(int(marksReg), DispositionAllow, APDispositionMarkRange). // AntreaPolicy
+				Action().LoadRegRange(int(marksReg), CustomReasonLogging, CustomReasonMarkRange).
 				Action().SendToController(uint8(PacketInReasonNP)).
 				Action().CT(true, nextTable, ctZone). // CT action requires commit flag if actions other than NAT without arguments are specified.
 				LoadToLabelRange(uint64(conjunctionID), &labelRange).
@@ -1277,8 +1526,7 @@ func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.Tab
 				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
 				Done()
 		} else {
-			return c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
-				MatchConjID(conjunctionID).
+			return flowBuilder.
 				Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}). // Traceflow.
 				Action().CT(true, nextTable, ctZone).                                     // CT action requires commit flag if actions other than NAT without arguments are specified.
 				LoadToLabelRange(uint64(conjunctionID), &labelRange).
@@ -1289,47 +1537,93 @@ func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.Tab
 	}
 	var flows []binding.Flow
 	for _, proto := range c.ipProtocols {
-		flows = append(flows, conjActionFlow(proto))
+		for _, state := range connectionStatesOrAny(connectionStates) {
+			flows = append(flows, conjActionFlow(proto, state))
+		}
 	}
 	return flows
 }
 
 // conjunctionActionDropFlow generates the flow to mark the packet to be dropped if policyRuleConjunction ID is matched.
-// Any matched flow will be dropped in corresponding metric tables.
-func (c *client) conjunctionActionDropFlow(conjunctionID uint32, tableID binding.TableIDType, priority *uint16, enableLogging bool) binding.Flow {
+// Any matched flow will be dropped in corresponding metric tables. If isReject is true, the packet is also sent to the
+// controller, so that the agent can send a reject response back to the source of the packet. If connectionStates is
+// not empty, a flow is generated for each connection state.
+func (c *client) conjunctionActionDropFlow(conjunctionID uint32, tableID binding.TableIDType, priority *uint16, enableLogging, isReject bool, connectionStates []secv1alpha1.ConnectionState) []binding.Flow {
 	ofPriority := *priority
 	metricTableID := IngressMetricTable
 	if _, ok := egressTables[tableID]; ok {
 		metricTableID = EgressMetricTable
 	}
-	// We do not drop the packet immediately but send the packet to the metric table to update the rule metrics.
+	var customReasons uint32
 	if enableLogging {
-		return c.pipeline[tableID].BuildFlow(ofPriority).
-			MatchConjID(conjunctionID).
-			Action().LoadRegRange(int(CNPDropConjunctionIDReg), conjunctionID, binding.Range{0, 31}).
-			Action().LoadRegRange(int(marksReg), cnpDropMark, cnpDropMarkRange).
-			Action().LoadRegRange(int(marksReg), DispositionDrop, APDispositionMarkRange). //Logging
-			Action().SendToController(uint8(PacketInReasonNP)).
-			Action().GotoTable(metricTableID).
-			Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
-			Done()
-	} else {
-		return c.pipeline[tableID].BuildFlow(ofPriority).
-			MatchConjID(conjunctionID).
-			Action().LoadRegRange(int(CNPDropConjunctionIDReg), conjunctionID, binding.Range{0, 31}).
-			Action().LoadRegRange(int(marksReg), cnpDropMark, cnpDropMarkRange).
-			Action().GotoTable(metricTableID).
-			Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
-			Done()
+		customReasons |= CustomReasonLogging
+	}
+	if isReject {
+		customReasons |= CustomReasonReject
+	}
+	conjActionDropFlow := func(state secv1alpha1.ConnectionState) binding.Flow {
+		flowBuilder := matchConnectionState(c.pipeline[tableID].BuildFlow(ofPriority).
+			MatchConjID(conjunctionID), state)
+		// We do not drop the packet immediately but send the packet to the metric table to update the rule metrics.
+		if customReasons != 0 {
+			// The packets sent to the agent are rate-limited, the packets above the rate are dropped without being
+			// counted in the rule metrics. The allowed packets which are logged are not rate-limited, as the Meter
+			// would drop them.
+			return c.meterPacketIn(flowBuilder, PacketInReasonNP).
+				Action().LoadRegRange(int(CNPDropConjunctionIDReg), conjunctionID, binding.Range{0, 31}).
+				Action().LoadRegRange(int(marksReg), cnpDropMark, cnpDropMarkRange).
+				Action().LoadRegRange(int(marksReg), DispositionDrop, APDispositionMarkRange). //Logging
+				Action().LoadRegRange(int(marksReg), customReasons, CustomReasonMarkRange).
+				Action().SendToController(uint8(PacketInReasonNP)).
+				Action().GotoTable(metricTableID).
+				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+				Done()
+		} else {
+			return flowBuilder.
+				Action().LoadRegRange(int(CNPDropConjunctionIDReg), conjunctionID, binding.Range{0, 31}).
+				Action().LoadRegRange(int(marksReg), cnpDropMark, cnpDropMarkRange).
+				Action().GotoTable(metricTableID).
+				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+				Done()
+		}
+	}
+	var flows []binding.Flow
+	for _, state := range connectionStatesOrAny(connectionStates) {
+		flows = append(flows, conjActionDropFlow(state))
 	}
+	return flows
+}
+
+// connectionStatesOrAny returns the provided connection states, or a single empty state matching connections in any
+// state if none is provided.
+func connectionStatesOrAny(connectionStates []secv1alpha1.ConnectionState) []secv1alpha1.ConnectionState {
+	if len(connectionStates) == 0 {
+		return []secv1alpha1.ConnectionState{""}
+	}
+	return connectionStates
+}
+
+// matchConnectionState adds the ct_state matches of a connection state to the flow. Packets of new connections are
+// matched with "+new-rel", as related connections are also new, and packets of established connections are matched
+// with "-new+est". The flow is not changed if the state is empty.
+func matchConnectionState(flowBuilder binding.FlowBuilder, state secv1alpha1.ConnectionState) binding.FlowBuilder {
+	switch state {
+	case secv1alpha1.ConnectionStateNew:
+		return flowBuilder.MatchCTStateNew(true).MatchCTStateRel(false)
+	case secv1alpha1.ConnectionStateEstablished:
+		return flowBuilder.MatchCTStateNew(false).MatchCTStateEst(true)
+	case secv1alpha1.ConnectionStateRelated:
+		return flowBuilder.MatchCTStateRel(true)
+	}
+	return flowBuilder
 }
 
 func (c *client) Disconnect() error {
 	return c.bridge.Disconnect()
 }
 
-func newFlowCategoryCache() *flowCategoryCache {
-	return &flowCategoryCache{}
+func newFlowCategoryCache(name string) *flowCategoryCache {
+	return &flowCategoryCache{name: name}
 }
 
 // establishedConnectionFlows generates flows to ensure established connections skip the NetworkPolicy rules.
@@ -1702,6 +1996,125 @@ func (c *client) snatFlows(nodeIP net.IP, localSubnet net.IPNet, category cookie
 	return flows
 }
 
+// snatCommonFlows installs the default flows for the Egress feature. The flows
+// identify the packets from local Pods to the external network, and the packets
+// tunnelled from remote Nodes to an SNAT IP on the local Node, and send them to
+// snatTable, where the SNAT IPs are looked up for the packets.
+func (c *client) snatCommonFlows(nodeIP net.IP, localSubnet net.IPNet, localGatewayMAC net.HardwareAddr, category cookie.Category) []binding.Flow {
+	l3FwdTable := c.pipeline[l3ForwardingTable]
+	nextTable := l3FwdTable.GetNext()
+	ipProto := getIPProtocol(localSubnet.IP)
+	flows := []binding.Flow{
+		// First install flows for traffic that should bypass SNAT.
+
+		// This flow is for traffic to the local Pod subnet.
+		l3FwdTable.BuildFlow(priorityNormal).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+			MatchDstIPNet(localSubnet).
+			Action().GotoTable(nextTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+		// This flow is for the return traffic of connections to a local
+		// Pod through the gateway interface (so gatewayCTMark is set).
+		l3FwdTable.BuildFlow(priorityNormal).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+			MatchCTMark(gatewayCTMark, nil).
+			Action().GotoTable(nextTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+
+		// Send the traffic to external to snatTable.
+		l3FwdTable.BuildFlow(priorityLow).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+			Action().GotoTable(snatTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+		// For the traffic tunnelled from remote Nodes, rewrite the
+		// destination MAC to the gateway interface MAC.
+		l3FwdTable.BuildFlow(priorityLow).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromTunnel, binding.Range{0, 15}).
+			Action().SetDstMAC(localGatewayMAC).
+			Action().GotoTable(snatTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+
+		// Drop the traffic from remote Nodes if no matched SNAT policy.
+		c.pipeline[snatTable].BuildFlow(priorityLow).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromTunnel, binding.Range{0, 15}).
+			Action().Drop().
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+	}
+	if nodeIP != nil && getIPProtocol(nodeIP) == ipProto {
+		// This flow is for the traffic to the local Node IP.
+		flows = append(flows, l3FwdTable.BuildFlow(priorityNormal).
+			MatchProtocol(ipProto).
+			MatchRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15}).
+			MatchDstIP(nodeIP).
+			Action().GotoTable(nextTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done())
+	}
+	return flows
+}
+
+// snatIPFromTunnelFlow generates a flow that marks SNAT packets tunnelled from
+// remote Nodes. The SNAT IP matches the packet's tunnel destination IP. The
+// packets are then forwarded to the gateway interface, and SNAT'd by the host
+// network stack based on the packet mark: on Linux, the uplink interface is not
+// attached to the OVS bridge, so the SNAT cannot be performed by an OVS ct(nat)
+// action as the reply packets would not come back to OVS.
+func (c *client) snatIPFromTunnelFlow(snatIP net.IP, mark uint32) binding.Flow {
+	ipProto := getIPProtocol(snatIP)
+	snatTable := c.pipeline[snatTable]
+	return snatTable.BuildFlow(priorityNormal).
+		MatchProtocol(ipProto).
+		MatchCTStateNew(true).MatchCTStateTrk(true).
+		MatchTunnelDst(snatIP).
+		Action().LoadPktMarkRange(mark, snatPktMarkRange).
+		Action().DecTTL().
+		Action().GotoTable(snatTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
+		Done()
+}
+
+// snatRuleFlow generates a flow that applies the SNAT rule for a local Pod. If
+// the SNAT IP exists on the local Node, it sets the packet mark with the ID of
+// the SNAT IP, for the traffic from the ofPort to external; if the SNAT IP is
+// on a remote Node, it tunnels the packets to the SNAT IP.
+func (c *client) snatRuleFlow(ofPort uint32, snatIP net.IP, snatMark uint32, localGatewayMAC net.HardwareAddr) binding.Flow {
+	ipProto := getIPProtocol(snatIP)
+	snatTable := c.pipeline[snatTable]
+	if snatMark != 0 {
+		// Local SNAT IP.
+		return snatTable.BuildFlow(priorityNormal).
+			MatchProtocol(ipProto).
+			MatchCTStateNew(true).MatchCTStateTrk(true).
+			MatchInPort(ofPort).
+			Action().LoadPktMarkRange(snatMark, snatPktMarkRange).
+			Action().GotoTable(snatTable.GetNext()).
+			Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
+			Done()
+	}
+	// SNAT IP should be on a remote Node.
+	return snatTable.BuildFlow(priorityNormal).
+		MatchProtocol(ipProto).
+		MatchInPort(ofPort).
+		Action().SetSrcMAC(localGatewayMAC).
+		Action().SetDstMAC(globalVirtualMAC).
+		// Set tunnel destination to the SNAT IP.
+		Action().SetTunnelDst(snatIP).
+		Action().DecTTL().
+		Action().GotoTable(snatTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
+		Done()
+}
+
 // loadBalancerServiceFromOutsideFlow generates the flow to forward LoadBalancer service traffic from outside node
 // to gateway. kube-proxy will then handle the traffic.
 // This flow is for Windows Node only.
@@ -1716,6 +2129,56 @@ func (c *client) loadBalancerServiceFromOutsideFlow(svcIP net.IP, svcPort uint16
 		Done()
 }
 
+// serviceFromUplinkFlows generates the flows to load-balance the Service traffic received from the uplink port and
+// destined to dstIP (the Node IP address for NodePort Services, or an ingress IP for LoadBalancer Services) in the OVS
+// pipeline, so that kube-proxy is not needed:
+//  1) The connection is committed to CtZoneSNAT with the host gateway IP as source IP, so that the reply traffic
+//     from the Endpoints on other Nodes comes back to this Node.
+//  2) The new connection is sent to the Service load-balancing tables, where it is DNAT'd to an Endpoint in CtZone.
+//     The packets of the established connection are forwarded by the flow which handles the connections applied
+//     both DNAT and SNAT in serviceLBBypassFlows.
+//  3) The reply packets, which are un-DNAT'd in CtZone, are un-SNAT'd in CtZoneSNAT and forwarded to the host
+//     gateway, from which they are routed to the uplink by the host network.
+// These flows are for Windows Node only.
+func (c *client) serviceFromUplinkFlows(dstIP net.IP, svcPort uint16, protocol binding.Protocol) []binding.Flow {
+	gatewayIP := c.nodeConfig.GatewayConfig.IPv4
+	snatIPRange := &binding.IPRange{StartIP: gatewayIP, EndIP: gatewayIP}
+	cookieID := c.cookieAllocator.Request(cookie.Service).Raw()
+	return []binding.Flow{
+		c.pipeline[uplinkTable].BuildFlow(priorityHigh).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchRegRange(int(marksReg), markTrafficFromUplink, binding.Range{0, 15}).
+			MatchDstIP(dstIP).
+			Action().CT(true, conntrackTable, CtZoneSNAT).SNAT(snatIPRange, nil).CTDone().
+			Cookie(cookieID).
+			Done(),
+		c.pipeline[conntrackStateTable].BuildFlow(priorityHigh).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchCTStateNew(true).MatchCTStateTrk(true).
+			MatchRegRange(int(marksReg), markTrafficFromUplink, binding.Range{0, 15}).
+			MatchDstIP(dstIP).
+			Action().LoadRegRange(int(marksReg), macRewriteMark, macRewriteMarkRange).
+			Action().ResubmitToTable(sessionAffinityTable).
+			Action().ResubmitToTable(serviceLBTable).
+			Cookie(cookieID).
+			Done(),
+		c.pipeline[conntrackStateTable].BuildFlow(priorityHigh).
+			MatchProtocol(protocol).
+			MatchCTStateNew(false).MatchCTStateTrk(true).MatchCTStateRpl(true).
+			MatchCTMark(ServiceCTMark, nil).
+			MatchCTDstIP(dstIP).
+			MatchCTDstPort(svcPort).
+			MatchCTProtocol(protocol).
+			MatchDstIP(gatewayIP).
+			Action().SetDstMAC(c.nodeConfig.GatewayConfig.MAC).
+			Action().CT(false, l2ForwardingCalcTable, CtZoneSNAT).NAT().CTDone().
+			Cookie(cookieID).
+			Done(),
+	}
+}
+
 // serviceLearnFlow generates the flow with learn action which adds new flows in
 // sessionAffinityTable according to the Endpoint selection decision.
 func (c *client) serviceLearnFlow(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) binding.Flow {
@@ -1890,6 +2353,74 @@ func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAf
 	return group
 }
 
+// multicastFlows generates the flows which send the IPv4 multicast traffic to multicastTable, and the flow which
+// sends the IGMP messages to the agent with the PacketInReasonMC reason, for IGMP snooping. The IGMP messages are not
+// forwarded, and they are rate-limited with the packet-in Meter of the reason.
+func (c *client) multicastFlows(category cookie.Category) []binding.Flow {
+	_, ipv4MulticastIPNet, _ := net.ParseCIDR(ipv4MulticastAddr)
+	igmpFlowBuilder := c.pipeline[multicastTable].BuildFlow(priorityHigh).MatchProtocol(binding.ProtocolIGMP)
+	return []binding.Flow{
+		c.pipeline[l3ForwardingTable].BuildFlow(priorityHigh).MatchProtocol(binding.ProtocolIP).
+			MatchDstIPNet(*ipv4MulticastIPNet).
+			Action().GotoTable(multicastTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+		c.meterPacketIn(igmpFlowBuilder, PacketInReasonMC).
+			Action().SendToController(ofPacketInReason(uint8(PacketInReasonMC))).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done(),
+	}
+}
+
+// multicastRemoteForwardingFlow generates the flow which forwards the multicast traffic sent by the local Pods to the
+// other Nodes, before it is replicated to the local receivers in multicastGroupTable. The traffic is tunnelled to each
+// of the peers, and is sent to the underlay network through the host gateway when some Nodes are reached without
+// encapsulation. The traffic received from the tunnel or the gateway is only replicated to the local receivers, so
+// that it is never forwarded back.
+func (c *client) multicastRemoteForwardingFlow(peers map[string]*multicastPeer, category cookie.Category) binding.Flow {
+	mcastTable := c.pipeline[multicastTable]
+	flowBuilder := mcastTable.BuildFlow(priorityNormal).MatchProtocol(binding.ProtocolIP).
+		MatchRegRange(int(marksReg), markTrafficFromLocal, binding.Range{0, 15})
+	for _, peer := range peers {
+		tunOFPort := peer.tunOFPort
+		if tunOFPort == 0 {
+			tunOFPort = config.DefaultTunOFPort
+		}
+		flowBuilder = flowBuilder.Action().SetTunnelDst(peer.tunnelPeerIP).
+			Action().Output(int(tunOFPort))
+	}
+	if c.encapMode.SupportsNoEncap() {
+		flowBuilder = flowBuilder.Action().Output(int(config.HostGatewayOFPort))
+	}
+	return flowBuilder.Action().GotoTable(mcastTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
+// multicastGroupFlow generates the flow which replicates the multicast traffic sent to groupIP with the Group groupID.
+func (c *client) multicastGroupFlow(groupIP net.IP, groupID binding.GroupIDType, category cookie.Category) binding.Flow {
+	return c.pipeline[multicastGroupTable].BuildFlow(priorityNormal).MatchProtocol(binding.ProtocolIP).
+		MatchDstIP(groupIP).
+		Action().Group(groupID).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
+// multicastReceiverGroup generates the Group of type all which replicates the multicast traffic to the local Pods
+// connected to receiverOFPorts. Like the traffic forwarded to a single local Pod by the l2ForwardCalcFlow, each copy
+// is subject to the ingress NetworkPolicies of its receiver.
+func (c *client) multicastReceiverGroup(groupID binding.GroupIDType, receiverOFPorts []uint32) binding.Group {
+	group := c.bridge.CreateGroupTypeAll(groupID).ResetBuckets()
+	for _, ofPort := range receiverOFPorts {
+		group = group.Bucket().
+			LoadRegRange(int(PortCacheReg), ofPort, ofPortRegRange).
+			LoadRegRange(int(marksReg), portFoundMark, ofPortMarkRange).
+			ResubmitToTable(c.ingressEntryTable).
+			Done()
+	}
+	return group
+}
+
 // decTTLFlows decrements TTL by one for the packets forwarded across Nodes.
 // The TTL decrement should be skipped for the packets which enter OVS pipeline
 // from the gateway interface, as the host IP stack should have decremented the
@@ -1967,14 +2498,22 @@ func (c *client) generatePipeline() {
 	if runtime.IsWindowsPlatform() {
 		c.pipeline[uplinkTable] = bridge.CreateTable(uplinkTable, spoofGuardTable, binding.TableMissActionNone)
 	}
+	if c.enableEgress {
+		c.pipeline[snatTable] = bridge.CreateTable(snatTable, l2ForwardingCalcTable, binding.TableMissActionNext)
+	}
 	if c.enableAntreaPolicy {
 		c.pipeline[AntreaPolicyEgressRuleTable] = bridge.CreateTable(AntreaPolicyEgressRuleTable, EgressRuleTable, binding.TableMissActionNext)
 		c.pipeline[AntreaPolicyIngressRuleTable] = bridge.CreateTable(AntreaPolicyIngressRuleTable, IngressRuleTable, binding.TableMissActionNext)
 	}
+	if c.enableMulticast {
+		c.pipeline[multicastTable] = bridge.CreateTable(multicastTable, multicastGroupTable, binding.TableMissActionNext)
+		c.pipeline[multicastGroupTable] = bridge.CreateTable(multicastGroupTable, binding.LastTableID, binding.TableMissActionDrop)
+	}
 }
 
-// NewClient is the constructor of the Client interface.
-func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy bool) Client {
+// NewClient is the constructor of the Client interface. If enableHardwareOffload is true, the pipeline avoids the
+// actions which cannot be offloaded to the hardware by OVS, i.e. the OpenFlow meters.
+func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy, enableEgress, enableMulticast, enableHardwareOffload bool) Client {
 	bridge := binding.NewOFBridge(bridgeName, mgmtAddr)
 	policyCache := cache.NewIndexer(
 		policyConjKeyFunc,
@@ -1984,13 +2523,18 @@ func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy bool
 		bridge:                   bridge,
 		enableProxy:              enableProxy,
 		enableAntreaPolicy:       enableAntreaPolicy,
-		nodeFlowCache:            newFlowCategoryCache(),
-		podFlowCache:             newFlowCategoryCache(),
-		serviceFlowCache:         newFlowCategoryCache(),
+		enableEgress:             enableEgress,
+		enableMulticast:          enableMulticast,
+		enableHardwareOffload:    enableHardwareOffload,
+		nodeFlowCache:            newFlowCategoryCache("node"),
+		podFlowCache:             newFlowCategoryCache("pod"),
+		serviceFlowCache:         newFlowCategoryCache("service"),
+		snatFlowCache:            newFlowCategoryCache("snat"),
 		policyCache:              policyCache,
 		groupCache:               sync.Map{},
 		globalConjMatchFlowCache: map[string]*conjMatchFlowContext{},
 		packetInHandlers:         map[uint8]map[string]PacketInHandler{},
+		multicastPeers:           map[string]*multicastPeer{},
 		ovsctlClient:             ovsctl.NewClient(bridgeName),
 	}
 	c.ofEntryOperations = c
@@ -25,6 +25,7 @@ import (
 	config "github.com/vmware-tanzu/antrea/pkg/agent/config"
 	types "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	openflow "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	ovsctl "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
 	proxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 	net "net"
 	reflect "reflect"
@@ -123,6 +124,65 @@ func (mr *MockClientMockRecorder) Disconnect() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockClient)(nil).Disconnect))
 }
 
+// DroppedPacketCounts mocks base method
+func (m *MockClient) DroppedPacketCounts() map[string]uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DroppedPacketCounts")
+	ret0, _ := ret[0].(map[string]uint64)
+	return ret0
+}
+
+// DroppedPacketCounts indicates an expected call of DroppedPacketCounts
+func (mr *MockClientMockRecorder) DroppedPacketCounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DroppedPacketCounts", reflect.TypeOf((*MockClient)(nil).DroppedPacketCounts))
+}
+
+// GetBandwidthQuotaStats mocks base method
+func (m *MockClient) GetBandwidthQuotaStats() (map[string]*ovsctl.MeterStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBandwidthQuotaStats")
+	ret0, _ := ret[0].(map[string]*ovsctl.MeterStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBandwidthQuotaStats indicates an expected call of GetBandwidthQuotaStats
+func (mr *MockClientMockRecorder) GetBandwidthQuotaStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBandwidthQuotaStats", reflect.TypeOf((*MockClient)(nil).GetBandwidthQuotaStats))
+}
+
+// GetFlowCookieStats mocks base method
+func (m *MockClient) GetFlowCookieStats() (map[uint64]*openflow.FlowStatsSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlowCookieStats")
+	ret0, _ := ret[0].(map[uint64]*openflow.FlowStatsSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlowCookieStats indicates an expected call of GetFlowCookieStats
+func (mr *MockClientMockRecorder) GetFlowCookieStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlowCookieStats", reflect.TypeOf((*MockClient)(nil).GetFlowCookieStats))
+}
+
+// GetFlowTableStats mocks base method
+func (m *MockClient) GetFlowTableStats() (map[openflow.TableIDType]*openflow.FlowStatsSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlowTableStats")
+	ret0, _ := ret[0].(map[openflow.TableIDType]*openflow.FlowStatsSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlowTableStats indicates an expected call of GetFlowTableStats
+func (mr *MockClientMockRecorder) GetFlowTableStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlowTableStats", reflect.TypeOf((*MockClient)(nil).GetFlowTableStats))
+}
+
 // GetFlowTableStatus mocks base method
 func (m *MockClient) GetFlowTableStatus() []openflow.TableStatus {
 	m.ctrl.T.Helper()
@@ -223,6 +283,20 @@ func (mr *MockClientMockRecorder) Initialize(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Initialize", reflect.TypeOf((*MockClient)(nil).Initialize), arg0, arg1, arg2)
 }
 
+// InstallBandwidthQuotaFlows mocks base method
+func (m *MockClient) InstallBandwidthQuotaFlows(arg0 string, arg1 uint64, arg2 map[string]uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallBandwidthQuotaFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallBandwidthQuotaFlows indicates an expected call of InstallBandwidthQuotaFlows
+func (mr *MockClientMockRecorder) InstallBandwidthQuotaFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallBandwidthQuotaFlows", reflect.TypeOf((*MockClient)(nil).InstallBandwidthQuotaFlows), arg0, arg1, arg2)
+}
+
 // InstallBridgeUplinkFlows mocks base method
 func (m *MockClient) InstallBridgeUplinkFlows() error {
 	m.ctrl.T.Helper()
@@ -335,6 +409,34 @@ func (mr *MockClientMockRecorder) InstallLoadBalancerServiceFromOutsideFlows(arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallLoadBalancerServiceFromOutsideFlows", reflect.TypeOf((*MockClient)(nil).InstallLoadBalancerServiceFromOutsideFlows), arg0, arg1, arg2)
 }
 
+// InstallMulticastGroup mocks base method
+func (m *MockClient) InstallMulticastGroup(arg0 net.IP, arg1 []uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallMulticastGroup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallMulticastGroup indicates an expected call of InstallMulticastGroup
+func (mr *MockClientMockRecorder) InstallMulticastGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallMulticastGroup", reflect.TypeOf((*MockClient)(nil).InstallMulticastGroup), arg0, arg1)
+}
+
+// InstallMulticastInitialFlows mocks base method
+func (m *MockClient) InstallMulticastInitialFlows() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallMulticastInitialFlows")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallMulticastInitialFlows indicates an expected call of InstallMulticastInitialFlows
+func (mr *MockClientMockRecorder) InstallMulticastInitialFlows() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallMulticastInitialFlows", reflect.TypeOf((*MockClient)(nil).InstallMulticastInitialFlows))
+}
+
 // InstallNodeFlows mocks base method
 func (m *MockClient) InstallNodeFlows(arg0 string, arg1 map[*net.IPNet]net.IP, arg2 net.IP, arg3 uint32) error {
 	m.ctrl.T.Helper()
@@ -349,6 +451,34 @@ func (mr *MockClientMockRecorder) InstallNodeFlows(arg0, arg1, arg2, arg3 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallNodeFlows", reflect.TypeOf((*MockClient)(nil).InstallNodeFlows), arg0, arg1, arg2, arg3)
 }
 
+// InstallNodePortServiceFlows mocks base method
+func (m *MockClient) InstallNodePortServiceFlows(arg0 openflow.GroupIDType, arg1 uint16, arg2 openflow.Protocol, arg3 uint16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallNodePortServiceFlows", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallNodePortServiceFlows indicates an expected call of InstallNodePortServiceFlows
+func (mr *MockClientMockRecorder) InstallNodePortServiceFlows(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallNodePortServiceFlows", reflect.TypeOf((*MockClient)(nil).InstallNodePortServiceFlows), arg0, arg1, arg2, arg3)
+}
+
+// InstallPodBandwidthFlows mocks base method
+func (m *MockClient) InstallPodBandwidthFlows(arg0 string, arg1 uint32, arg2, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallPodBandwidthFlows", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallPodBandwidthFlows indicates an expected call of InstallPodBandwidthFlows
+func (mr *MockClientMockRecorder) InstallPodBandwidthFlows(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPodBandwidthFlows", reflect.TypeOf((*MockClient)(nil).InstallPodBandwidthFlows), arg0, arg1, arg2, arg3)
+}
+
 // InstallPodFlows mocks base method
 func (m *MockClient) InstallPodFlows(arg0 string, arg1 []net.IP, arg2 net.HardwareAddr, arg3 uint32) error {
 	m.ctrl.T.Helper()
@@ -363,6 +493,34 @@ func (mr *MockClientMockRecorder) InstallPodFlows(arg0, arg1, arg2, arg3 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPodFlows", reflect.TypeOf((*MockClient)(nil).InstallPodFlows), arg0, arg1, arg2, arg3)
 }
 
+// InstallPodQoSFlows mocks base method
+func (m *MockClient) InstallPodQoSFlows(arg0 string, arg1, arg2 uint32, arg3 byte, arg4, arg5 []uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallPodQoSFlows", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallPodQoSFlows indicates an expected call of InstallPodQoSFlows
+func (mr *MockClientMockRecorder) InstallPodQoSFlows(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPodQoSFlows", reflect.TypeOf((*MockClient)(nil).InstallPodQoSFlows), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// InstallPodSNATFlows mocks base method
+func (m *MockClient) InstallPodSNATFlows(arg0 uint32, arg1 net.IP, arg2 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallPodSNATFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallPodSNATFlows indicates an expected call of InstallPodSNATFlows
+func (mr *MockClientMockRecorder) InstallPodSNATFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPodSNATFlows", reflect.TypeOf((*MockClient)(nil).InstallPodSNATFlows), arg0, arg1, arg2)
+}
+
 // InstallPolicyRuleFlows mocks base method
 func (m *MockClient) InstallPolicyRuleFlows(arg0 *types.PolicyRule) error {
 	m.ctrl.T.Helper()
@@ -377,6 +535,20 @@ func (mr *MockClientMockRecorder) InstallPolicyRuleFlows(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPolicyRuleFlows", reflect.TypeOf((*MockClient)(nil).InstallPolicyRuleFlows), arg0)
 }
 
+// InstallSNATMarkFlows mocks base method
+func (m *MockClient) InstallSNATMarkFlows(arg0 net.IP, arg1 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallSNATMarkFlows", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallSNATMarkFlows indicates an expected call of InstallSNATMarkFlows
+func (mr *MockClientMockRecorder) InstallSNATMarkFlows(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallSNATMarkFlows", reflect.TypeOf((*MockClient)(nil).InstallSNATMarkFlows), arg0, arg1)
+}
+
 // InstallServiceFlows mocks base method
 func (m *MockClient) InstallServiceFlows(arg0 openflow.GroupIDType, arg1 net.IP, arg2 uint16, arg3 openflow.Protocol, arg4 uint16) error {
 	m.ctrl.T.Helper()
@@ -391,6 +563,20 @@ func (mr *MockClientMockRecorder) InstallServiceFlows(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceFlows", reflect.TypeOf((*MockClient)(nil).InstallServiceFlows), arg0, arg1, arg2, arg3, arg4)
 }
 
+// InstallServiceFromUplinkFlows mocks base method
+func (m *MockClient) InstallServiceFromUplinkFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallServiceFromUplinkFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallServiceFromUplinkFlows indicates an expected call of InstallServiceFromUplinkFlows
+func (mr *MockClientMockRecorder) InstallServiceFromUplinkFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceFromUplinkFlows", reflect.TypeOf((*MockClient)(nil).InstallServiceFromUplinkFlows), arg0, arg1, arg2)
+}
+
 // InstallServiceGroup mocks base method
 func (m *MockClient) InstallServiceGroup(arg0 openflow.GroupIDType, arg1 bool, arg2 []proxy.Endpoint) error {
 	m.ctrl.T.Helper()
@@ -405,6 +591,20 @@ func (mr *MockClientMockRecorder) InstallServiceGroup(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceGroup", reflect.TypeOf((*MockClient)(nil).InstallServiceGroup), arg0, arg1, arg2)
 }
 
+// InstallSpoofGuardViolationFlows mocks base method
+func (m *MockClient) InstallSpoofGuardViolationFlows(arg0 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallSpoofGuardViolationFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallSpoofGuardViolationFlows indicates an expected call of InstallSpoofGuardViolationFlows
+func (mr *MockClientMockRecorder) InstallSpoofGuardViolationFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallSpoofGuardViolationFlows", reflect.TypeOf((*MockClient)(nil).InstallSpoofGuardViolationFlows), arg0)
+}
+
 // InstallTraceflowFlows mocks base method
 func (m *MockClient) InstallTraceflowFlows(arg0 byte) error {
 	m.ctrl.T.Helper()
@@ -513,6 +713,48 @@ func (mr *MockClientMockRecorder) ReplayFlows() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayFlows", reflect.TypeOf((*MockClient)(nil).ReplayFlows))
 }
 
+// SendICMPPacketOut mocks base method
+func (m *MockClient) SendICMPPacketOut(arg0, arg1 net.HardwareAddr, arg2, arg3 net.IP, arg4 int32, arg5 net.IP, arg6, arg7 byte, arg8 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendICMPPacketOut", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendICMPPacketOut indicates an expected call of SendICMPPacketOut
+func (mr *MockClientMockRecorder) SendICMPPacketOut(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendICMPPacketOut", reflect.TypeOf((*MockClient)(nil).SendICMPPacketOut), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+}
+
+// SendIGMPQueryPacketOut mocks base method
+func (m *MockClient) SendIGMPQueryPacketOut(arg0 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIGMPQueryPacketOut", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendIGMPQueryPacketOut indicates an expected call of SendIGMPQueryPacketOut
+func (mr *MockClientMockRecorder) SendIGMPQueryPacketOut(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIGMPQueryPacketOut", reflect.TypeOf((*MockClient)(nil).SendIGMPQueryPacketOut), arg0)
+}
+
+// SendTCPPacketOut mocks base method
+func (m *MockClient) SendTCPPacketOut(arg0, arg1 net.HardwareAddr, arg2, arg3 net.IP, arg4 int32, arg5 net.IP, arg6, arg7 uint16, arg8, arg9 uint32, arg10 byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTCPPacketOut", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTCPPacketOut indicates an expected call of SendTCPPacketOut
+func (mr *MockClientMockRecorder) SendTCPPacketOut(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTCPPacketOut", reflect.TypeOf((*MockClient)(nil).SendTCPPacketOut), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10)
+}
+
 // SendTraceflowPacket mocks base method
 func (m *MockClient) SendTraceflowPacket(arg0 byte, arg1, arg2, arg3, arg4 string, arg5, arg6 byte, arg7, arg8, arg9 uint16, arg10 byte, arg11, arg12 uint16, arg13, arg14 byte, arg15, arg16 uint16, arg17 uint32, arg18 int32) error {
 	m.ctrl.T.Helper()
@@ -553,6 +795,20 @@ func (mr *MockClientMockRecorder) SubscribePacketIn(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePacketIn", reflect.TypeOf((*MockClient)(nil).SubscribePacketIn), arg0, arg1)
 }
 
+// UninstallBandwidthQuotaFlows mocks base method
+func (m *MockClient) UninstallBandwidthQuotaFlows(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallBandwidthQuotaFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallBandwidthQuotaFlows indicates an expected call of UninstallBandwidthQuotaFlows
+func (mr *MockClientMockRecorder) UninstallBandwidthQuotaFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallBandwidthQuotaFlows", reflect.TypeOf((*MockClient)(nil).UninstallBandwidthQuotaFlows), arg0)
+}
+
 // UninstallEndpointFlows mocks base method
 func (m *MockClient) UninstallEndpointFlows(arg0 openflow.Protocol, arg1 proxy.Endpoint) error {
 	m.ctrl.T.Helper()
@@ -581,6 +837,20 @@ func (mr *MockClientMockRecorder) UninstallLoadBalancerServiceFromOutsideFlows(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallLoadBalancerServiceFromOutsideFlows", reflect.TypeOf((*MockClient)(nil).UninstallLoadBalancerServiceFromOutsideFlows), arg0, arg1, arg2)
 }
 
+// UninstallMulticastGroup mocks base method
+func (m *MockClient) UninstallMulticastGroup(arg0 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallMulticastGroup", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallMulticastGroup indicates an expected call of UninstallMulticastGroup
+func (mr *MockClientMockRecorder) UninstallMulticastGroup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallMulticastGroup", reflect.TypeOf((*MockClient)(nil).UninstallMulticastGroup), arg0)
+}
+
 // UninstallNodeFlows mocks base method
 func (m *MockClient) UninstallNodeFlows(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -595,6 +865,34 @@ func (mr *MockClientMockRecorder) UninstallNodeFlows(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallNodeFlows", reflect.TypeOf((*MockClient)(nil).UninstallNodeFlows), arg0)
 }
 
+// UninstallNodePortServiceFlows mocks base method
+func (m *MockClient) UninstallNodePortServiceFlows(arg0 uint16, arg1 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallNodePortServiceFlows", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallNodePortServiceFlows indicates an expected call of UninstallNodePortServiceFlows
+func (mr *MockClientMockRecorder) UninstallNodePortServiceFlows(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallNodePortServiceFlows", reflect.TypeOf((*MockClient)(nil).UninstallNodePortServiceFlows), arg0, arg1)
+}
+
+// UninstallPodBandwidthFlows mocks base method
+func (m *MockClient) UninstallPodBandwidthFlows(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallPodBandwidthFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallPodBandwidthFlows indicates an expected call of UninstallPodBandwidthFlows
+func (mr *MockClientMockRecorder) UninstallPodBandwidthFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPodBandwidthFlows", reflect.TypeOf((*MockClient)(nil).UninstallPodBandwidthFlows), arg0)
+}
+
 // UninstallPodFlows mocks base method
 func (m *MockClient) UninstallPodFlows(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -609,6 +907,34 @@ func (mr *MockClientMockRecorder) UninstallPodFlows(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPodFlows", reflect.TypeOf((*MockClient)(nil).UninstallPodFlows), arg0)
 }
 
+// UninstallPodQoSFlows mocks base method
+func (m *MockClient) UninstallPodQoSFlows(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallPodQoSFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallPodQoSFlows indicates an expected call of UninstallPodQoSFlows
+func (mr *MockClientMockRecorder) UninstallPodQoSFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPodQoSFlows", reflect.TypeOf((*MockClient)(nil).UninstallPodQoSFlows), arg0)
+}
+
+// UninstallPodSNATFlows mocks base method
+func (m *MockClient) UninstallPodSNATFlows(arg0 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallPodSNATFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallPodSNATFlows indicates an expected call of UninstallPodSNATFlows
+func (mr *MockClientMockRecorder) UninstallPodSNATFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPodSNATFlows", reflect.TypeOf((*MockClient)(nil).UninstallPodSNATFlows), arg0)
+}
+
 // UninstallPolicyRuleFlows mocks base method
 func (m *MockClient) UninstallPolicyRuleFlows(arg0 uint32) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -624,6 +950,20 @@ func (mr *MockClientMockRecorder) UninstallPolicyRuleFlows(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPolicyRuleFlows", reflect.TypeOf((*MockClient)(nil).UninstallPolicyRuleFlows), arg0)
 }
 
+// UninstallSNATMarkFlows mocks base method
+func (m *MockClient) UninstallSNATMarkFlows(arg0 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallSNATMarkFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallSNATMarkFlows indicates an expected call of UninstallSNATMarkFlows
+func (mr *MockClientMockRecorder) UninstallSNATMarkFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallSNATMarkFlows", reflect.TypeOf((*MockClient)(nil).UninstallSNATMarkFlows), arg0)
+}
+
 // UninstallServiceFlows mocks base method
 func (m *MockClient) UninstallServiceFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
 	m.ctrl.T.Helper()
@@ -638,6 +978,20 @@ func (mr *MockClientMockRecorder) UninstallServiceFlows(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallServiceFlows", reflect.TypeOf((*MockClient)(nil).UninstallServiceFlows), arg0, arg1, arg2)
 }
 
+// UninstallServiceFromUplinkFlows mocks base method
+func (m *MockClient) UninstallServiceFromUplinkFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallServiceFromUplinkFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallServiceFromUplinkFlows indicates an expected call of UninstallServiceFromUplinkFlows
+func (mr *MockClientMockRecorder) UninstallServiceFromUplinkFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallServiceFromUplinkFlows", reflect.TypeOf((*MockClient)(nil).UninstallServiceFromUplinkFlows), arg0, arg1, arg2)
+}
+
 // UninstallServiceGroup mocks base method
 func (m *MockClient) UninstallServiceGroup(arg0 openflow.GroupIDType) error {
 	m.ctrl.T.Helper()
@@ -30,6 +30,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 	"github.com/vmware-tanzu/antrea/third_party/proxy/config"
@@ -68,7 +69,11 @@ type proxier struct {
 	stopChan     <-chan struct{}
 	agentQuerier querier.AgentQuerier
 	ofClient     openflow.Client
+	routeClient  route.Interface
 	isIPv6       bool
+	// proxyNodePort indicates whether NodePort Services are implemented by
+	// AntreaProxy instead of kube-proxy.
+	proxyNodePort bool
 }
 
 func (p *proxier) isInitialized() bool {
@@ -93,6 +98,12 @@ func (p *proxier) removeStaleServices() {
 				}
 			}
 		}
+		if p.proxyNodePort && svcInfo.NodePort() != 0 {
+			if err := p.uninstallNodePortService(svcInfo); err != nil {
+				klog.Errorf("Error when removing NodePort Service %v: %v", svcPortName, err)
+				continue
+			}
+		}
 		groupID, _ := p.groupCounter.Get(svcPortName)
 		if err := p.ofClient.UninstallServiceGroup(groupID); err != nil {
 			klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
@@ -184,30 +195,42 @@ func (p *proxier) installServices() {
 			continue
 		}
 
-		endpointInstalled, ok := p.endpointInstalledMap[svcPortName]
-		if !ok {
-			p.endpointInstalledMap[svcPortName] = map[string]struct{}{}
-			endpointInstalled = p.endpointInstalledMap[svcPortName]
-		}
+		endpointInstalled := p.endpointInstalledMap[svcPortName]
 
 		installedSvcPort, ok := p.serviceInstalledMap[svcPortName]
 		var pSvcInfo *types.ServiceInfo
 		needRemoval := false
 		needUpdate := true
+		needGroupUpdate := true
 		if ok {
 			pSvcInfo = installedSvcPort.(*types.ServiceInfo)
 			needRemoval = serviceIdentityChanged(svcInfo, pSvcInfo) || (svcInfo.SessionAffinityType() != pSvcInfo.SessionAffinityType())
 			needUpdate = needRemoval || (svcInfo.StickyMaxAgeSeconds() != pSvcInfo.StickyMaxAgeSeconds())
+			needGroupUpdate = needUpdate
 		}
 
-		var endpointUpdateList []k8sproxy.Endpoint
+		// Diff the Endpoints with the installed ones, so that only the flows of
+		// the added Endpoints are installed, and the group is only modified when
+		// its buckets change. The flows of the removed Endpoints are removed by
+		// removeStaleEndpoints.
+		var endpointUpdateList, addedEndpoints []k8sproxy.Endpoint
 		for _, endpoint := range endpoints {
 			if _, ok := endpointInstalled[endpoint.String()]; !ok {
-				needUpdate = true
-				endpointInstalled[endpoint.String()] = struct{}{}
+				addedEndpoints = append(addedEndpoints, endpoint)
 			}
 			endpointUpdateList = append(endpointUpdateList, endpoint)
 		}
+		endpointsRemoved := false
+		for endpointString := range endpointInstalled {
+			if _, ok := endpoints[endpointString]; !ok {
+				endpointsRemoved = true
+				break
+			}
+		}
+		if len(addedEndpoints) > 0 || endpointsRemoved {
+			needUpdate = true
+			needGroupUpdate = true
+		}
 
 		var deletedLoadBalancerIPs, addedLoadBalancerIPs []string
 		if pSvcInfo != nil {
@@ -220,20 +243,38 @@ func (p *proxier) installServices() {
 		if len(deletedLoadBalancerIPs) > 0 || len(addedLoadBalancerIPs) > 0 {
 			needUpdate = true
 		}
+		nodePortChanged := pSvcInfo == nil || svcInfo.NodePort() != pSvcInfo.NodePort()
+		if p.proxyNodePort && nodePortChanged {
+			needUpdate = true
+		}
 
 		if !needUpdate {
 			continue
 		}
 
-		if err := p.ofClient.InstallEndpointFlows(svcInfo.OFProtocol, endpointUpdateList, p.isIPv6); err != nil {
-			klog.Errorf("Error when installing Endpoints flows: %v", err)
-			continue
+		// The flows of all the Endpoints are installed again if the protocol of
+		// the Service has changed.
+		endpointFlowsList := addedEndpoints
+		if needRemoval {
+			endpointFlowsList = endpointUpdateList
 		}
-		err := p.ofClient.InstallServiceGroup(groupID, svcInfo.StickyMaxAgeSeconds() != 0, endpointUpdateList)
-		if err != nil {
-			klog.Errorf("Error when installing Endpoints groups: %v", err)
-			p.endpointInstalledMap[svcPortName] = nil
-			continue
+		if len(endpointFlowsList) > 0 {
+			if err := p.ofClient.InstallEndpointFlows(svcInfo.OFProtocol, endpointFlowsList, p.isIPv6); err != nil {
+				klog.Errorf("Error when installing Endpoints flows: %v", err)
+				continue
+			}
+		}
+		if needGroupUpdate {
+			if err := p.ofClient.InstallServiceGroup(groupID, svcInfo.StickyMaxAgeSeconds() != 0, endpointUpdateList); err != nil {
+				klog.Errorf("Error when installing Endpoints groups: %v", err)
+				delete(p.endpointInstalledMap, svcPortName)
+				continue
+			}
+			endpointInstalled = make(map[string]struct{}, len(endpointUpdateList))
+			for _, endpoint := range endpointUpdateList {
+				endpointInstalled[endpoint.String()] = struct{}{}
+			}
+			p.endpointInstalledMap[svcPortName] = endpointInstalled
 		}
 		// Delete previous flow.
 		if needRemoval {
@@ -274,6 +315,22 @@ func (p *proxier) installServices() {
 				}
 			}
 		}
+		// Install OpenFlow entries and the DNAT rule for the NodePort of the
+		// Service, so that it is accessible via the IP addresses of the Node.
+		if p.proxyNodePort && (needRemoval || nodePortChanged) {
+			if pSvcInfo != nil && pSvcInfo.NodePort() != 0 {
+				if err := p.uninstallNodePortService(pSvcInfo); err != nil {
+					klog.Errorf("Error when removing NodePort Service %v: %v", svcPortName, err)
+					continue
+				}
+			}
+			if svcInfo.NodePort() != 0 {
+				if err := p.installNodePortService(groupID, svcInfo); err != nil {
+					klog.Errorf("Error when installing NodePort Service %v: %v", svcPortName, err)
+					continue
+				}
+			}
+		}
 
 		p.serviceInstalledMap[svcPortName] = svcPort
 		p.addServiceByIP(svcInfo.String(), svcPortName)
@@ -422,13 +479,15 @@ func NewProxier(
 	hostname string,
 	informerFactory informers.SharedInformerFactory,
 	ofClient openflow.Client,
-	isIPv6 bool) *proxier {
+	routeClient route.Interface,
+	isIPv6 bool,
+	proxyNodePort bool) *proxier {
 	recorder := record.NewBroadcaster().NewRecorder(
 		runtime.NewScheme(),
 		corev1.EventSource{Component: componentName, Host: hostname},
 	)
 	metrics.Register()
-	klog.Infof("Creating proxier with IPv6 enabled=%t", isIPv6)
+	klog.Infof("Creating proxier with IPv6 enabled=%t, NodePort enabled=%t", isIPv6, proxyNodePort)
 	p := &proxier{
 		endpointsConfig:      config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod),
 		serviceConfig:        config.NewServiceConfig(informerFactory.Core().V1().Services(), resyncPeriod),
@@ -441,7 +500,9 @@ func NewProxier(
 		serviceStringMap:     map[string]k8sproxy.ServicePortName{},
 		groupCounter:         types.NewGroupCounter(),
 		ofClient:             ofClient,
+		routeClient:          routeClient,
 		isIPv6:               isIPv6,
+		proxyNodePort:        proxyNodePort,
 	}
 	p.serviceConfig.RegisterEventHandler(p)
 	p.endpointsConfig.RegisterEventHandler(p)
@@ -450,13 +511,13 @@ func NewProxier(
 }
 
 func NewDualStackProxier(
-	hostname string, informerFactory informers.SharedInformerFactory, ofClient openflow.Client) k8sproxy.Provider {
+	hostname string, informerFactory informers.SharedInformerFactory, ofClient openflow.Client, routeClient route.Interface, proxyNodePort bool) k8sproxy.Provider {
 
 	// Create an ipv4 instance of the single-stack proxier
-	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, false)
+	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, routeClient, false, proxyNodePort)
 
 	// Create an ipv6 instance of the single-stack proxier
-	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, true)
+	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, routeClient, true, proxyNodePort)
 
 	// Return a meta-proxier that dispatch calls between the two
 	// single-stack proxier instances
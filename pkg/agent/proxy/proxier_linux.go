@@ -18,6 +18,8 @@ package proxy
 import (
 	"net"
 
+	agentconfig "github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 )
 
@@ -37,3 +39,32 @@ func (p *proxier) uninstallLoadBalancerServiceFlows(svcIP net.IP, svcPort uint16
 	}
 	return nil
 }
+
+// nodePortVirtualIP returns the virtual IP to which the traffic sent to the
+// NodePort of a Service is DNAT'd by the host network, before entering OVS
+// via the gateway interface.
+func (p *proxier) nodePortVirtualIP() net.IP {
+	if p.isIPv6 {
+		return agentconfig.VirtualNodePortIPv6
+	}
+	return agentconfig.VirtualNodePortIPv4
+}
+
+// installNodePortService installs the OpenFlow entries which load-balance the
+// traffic sent to the NodePort of a Service to its Endpoints, and the rules
+// which redirect the traffic sent to <NodeIP>:<NodePort> to OVS.
+func (p *proxier) installNodePortService(groupID binding.GroupIDType, svcInfo *types.ServiceInfo) error {
+	nodePort := uint16(svcInfo.NodePort())
+	if err := p.ofClient.InstallServiceFlows(groupID, p.nodePortVirtualIP(), nodePort, svcInfo.OFProtocol, uint16(svcInfo.StickyMaxAgeSeconds())); err != nil {
+		return err
+	}
+	return p.routeClient.AddNodePort(nodePort, svcInfo.Protocol(), p.isIPv6)
+}
+
+func (p *proxier) uninstallNodePortService(svcInfo *types.ServiceInfo) error {
+	nodePort := uint16(svcInfo.NodePort())
+	if err := p.routeClient.DeleteNodePort(nodePort, svcInfo.Protocol(), p.isIPv6); err != nil {
+		return err
+	}
+	return p.ofClient.UninstallServiceFlows(p.nodePortVirtualIP(), nodePort, svcInfo.OFProtocol)
+}
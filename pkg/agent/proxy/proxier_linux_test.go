@@ -0,0 +1,106 @@
+// +build !windows
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+
+	agentconfig "github.com/vmware-tanzu/antrea/pkg/agent/config"
+	ofmock "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+func testNodePort(t *testing.T, svcIP net.IP, epIP net.IP, virtualNodePortIP net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routetest.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockOFClient, isIPv6)
+	fp.routeClient = mockRouteClient
+	fp.proxyNodePort = true
+
+	svcPort := 80
+	svcNodePort := 30008
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           fmt.Sprint(svcPort),
+		Protocol:       corev1.ProtocolTCP,
+	}
+	service := makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+		svc.Spec.ClusterIP = svcIP.String()
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:     svcPortName.Port,
+			Port:     int32(svcPort),
+			NodePort: int32(svcNodePort),
+			Protocol: corev1.ProtocolTCP,
+		}}
+	})
+	makeServiceMap(fp, service)
+
+	epFunc := func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{
+				IP: epIP.String(),
+			}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}
+
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc)
+	makeEndpointsMap(fp, ep)
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, virtualNodePortIP, uint16(svcNodePort), bindingProtocol, uint16(0)).Times(1)
+	mockRouteClient.EXPECT().AddNodePort(uint16(svcNodePort), corev1.ProtocolTCP, isIPv6).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(virtualNodePortIP, uint16(svcNodePort), bindingProtocol).Times(1)
+	mockRouteClient.EXPECT().DeleteNodePort(uint16(svcNodePort), corev1.ProtocolTCP, isIPv6).Times(1)
+	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
+
+	fp.syncProxyRules()
+
+	fp.serviceChanges.OnServiceUpdate(service, nil)
+	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
+	fp.syncProxyRules()
+}
+
+func TestNodePortIPv4(t *testing.T) {
+	testNodePort(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), agentconfig.VirtualNodePortIPv4, false)
+}
+
+func TestNodePortIPv6(t *testing.T) {
+	testNodePort(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), agentconfig.VirtualNodePortIPv6, true)
+}
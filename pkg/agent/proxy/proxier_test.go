@@ -412,6 +412,102 @@ func TestClusterIPRemoveEndpointsIPv6(t *testing.T) {
 	testClusterIPRemoveEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
 }
 
+func testClusterIPUpdateEndpoints(t *testing.T, svcIP net.IP, epIP1, epIP2 net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, isIPv6)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Spec.ClusterIP = svcIP.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+
+	makeEndpoints := func(epIPs ...net.IP) *corev1.Endpoints {
+		return makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+			var addresses []corev1.EndpointAddress
+			for _, epIP := range epIPs {
+				addresses = append(addresses, corev1.EndpointAddress{IP: epIP.String()})
+			}
+			ept.Subsets = []corev1.EndpointSubset{{
+				Addresses: addresses,
+				Ports: []corev1.EndpointPort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}},
+			}}
+		})
+	}
+	endpointIPs := func(endpoints []k8sproxy.Endpoint) []string {
+		var ips []string
+		for _, endpoint := range endpoints {
+			ips = append(ips, endpoint.IP())
+		}
+		return ips
+	}
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	ep := makeEndpoints(epIP1)
+	makeEndpointsMap(fp, ep)
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any())
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0))
+	fp.syncProxyRules()
+
+	// Only the flows of the added Endpoint are installed.
+	epNew := makeEndpoints(epIP1, epIP2)
+	fp.endpointsChanges.OnEndpointUpdate(ep, epNew)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Do(
+		func(_ binding.Protocol, endpoints []k8sproxy.Endpoint, _ bool) {
+			assert.Equal(t, []string{epIP2.String()}, endpointIPs(endpoints))
+		})
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Do(
+		func(_ binding.GroupIDType, _ bool, endpoints []k8sproxy.Endpoint) {
+			assert.ElementsMatch(t, []string{epIP1.String(), epIP2.String()}, endpointIPs(endpoints))
+		})
+	fp.syncProxyRules()
+
+	// The bucket of the removed Endpoint is removed from the group.
+	ep = epNew
+	epNew = makeEndpoints(epIP2)
+	fp.endpointsChanges.OnEndpointUpdate(ep, epNew)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Do(
+		func(_ binding.GroupIDType, _ bool, endpoints []k8sproxy.Endpoint) {
+			assert.Equal(t, []string{epIP2.String()}, endpointIPs(endpoints))
+		})
+	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any())
+	fp.syncProxyRules()
+
+	// Nothing is updated when the Endpoints are unchanged.
+	fp.endpointsChanges.OnEndpointUpdate(epNew, makeEndpoints(epIP2))
+	fp.syncProxyRules()
+	assert.Equal(t, map[string]struct{}{net.JoinHostPort(epIP2.String(), fmt.Sprint(svcPort)): {}}, fp.endpointInstalledMap[svcPortName])
+}
+
+func TestClusterIPUpdateEndpointsIPv4(t *testing.T) {
+	testClusterIPUpdateEndpoints(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), net.ParseIP("10.180.0.2"), false)
+}
+
+func TestClusterIPUpdateEndpointsIPv6(t *testing.T) {
+	testClusterIPUpdateEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), net.ParseIP("10:180::2"), true)
+}
+
 func testSessionAffinityNoEndpoint(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP net.IP, isIPv6 bool) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
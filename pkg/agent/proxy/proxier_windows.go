@@ -18,17 +18,22 @@ package proxy
 import (
 	"net"
 
+	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 )
 
 // installLoadBalancerServiceFlows installs OpenFlow entries for LoadBalancer Service.
 // The rules for traffic from local Pod to LoadBalancer Service are the same with rules for Cluster Service.
 // For the LoadBalancer Service traffic from outside, specific rules are install to forward the packets
-// to the host network to let kube-proxy handle the traffic.
+// to the host network to let kube-proxy handle the traffic, or to load-balance the packets in the OVS
+// pipeline if AntreaProxy handles the NodePort Services, so that kube-proxy is not needed.
 func (p *proxier) installLoadBalancerServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) error {
 	if err := p.ofClient.InstallServiceFlows(groupID, svcIP, svcPort, protocol, affinityTimeout); err != nil {
 		return err
 	}
+	if p.proxyNodePort {
+		return p.ofClient.InstallServiceFromUplinkFlows(svcIP, svcPort, protocol)
+	}
 	if err := p.ofClient.InstallLoadBalancerServiceFromOutsideFlows(svcIP, svcPort, protocol); err != nil {
 		return err
 	}
@@ -39,8 +44,24 @@ func (p *proxier) uninstallLoadBalancerServiceFlows(svcIP net.IP, svcPort uint16
 	if err := p.ofClient.UninstallServiceFlows(svcIP, svcPort, protocol); err != nil {
 		return err
 	}
+	if p.proxyNodePort {
+		return p.ofClient.UninstallServiceFromUplinkFlows(svcIP, svcPort, protocol)
+	}
 	if err := p.ofClient.UninstallLoadBalancerServiceFromOutsideFlows(svcIP, svcPort, protocol); err != nil {
 		return err
 	}
 	return nil
 }
+
+// installNodePortService installs the OpenFlow entries which load-balance the
+// traffic sent to <NodeIP>:<NodePort> to the Endpoints of a Service. As the
+// uplink interface is attached to the OVS bridge on Windows, the traffic from
+// outside the Node is load-balanced in the OVS pipeline directly, and no host
+// network configuration is needed.
+func (p *proxier) installNodePortService(groupID binding.GroupIDType, svcInfo *types.ServiceInfo) error {
+	return p.ofClient.InstallNodePortServiceFlows(groupID, uint16(svcInfo.NodePort()), svcInfo.OFProtocol, uint16(svcInfo.StickyMaxAgeSeconds()))
+}
+
+func (p *proxier) uninstallNodePortService(svcInfo *types.ServiceInfo) error {
+	return p.ofClient.UninstallNodePortServiceFlows(uint16(svcInfo.NodePort()), svcInfo.OFProtocol)
+}
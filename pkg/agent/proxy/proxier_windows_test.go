@@ -0,0 +1,95 @@
+// +build windows
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+
+	ofmock "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+func TestLoadBalancerProxyNodePort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, false)
+	fp.proxyNodePort = true
+
+	svcIP := net.ParseIP("10.20.30.41")
+	epIP := net.ParseIP("10.180.0.1")
+	loadBalancerIP := net.ParseIP("169.254.1.253")
+	svcPort := 80
+	svcNodePort := 30008
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           fmt.Sprint(svcPort),
+		Protocol:       corev1.ProtocolTCP,
+	}
+	service := makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+		svc.Spec.ClusterIP = svcIP.String()
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:     svcPortName.Port,
+			Port:     int32(svcPort),
+			NodePort: int32(svcNodePort),
+			Protocol: corev1.ProtocolTCP,
+		}}
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: loadBalancerIP.String()}}
+	})
+	makeServiceMap(fp, service)
+
+	epFunc := func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{
+				IP: epIP.String(),
+			}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}
+	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc)
+	makeEndpointsMap(fp, ep)
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any(), false).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), binding.ProtocolTCP, uint16(0)).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), binding.ProtocolTCP, uint16(0)).Times(1)
+	mockOFClient.EXPECT().InstallServiceFromUplinkFlows(loadBalancerIP, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().InstallNodePortServiceFlows(groupID, uint16(svcNodePort), binding.ProtocolTCP, uint16(0)).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(loadBalancerIP, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFromUplinkFlows(loadBalancerIP, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallNodePortServiceFlows(uint16(svcNodePort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
+
+	fp.syncProxyRules()
+
+	fp.serviceChanges.OnServiceUpdate(service, nil)
+	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
+	fp.syncProxyRules()
+}
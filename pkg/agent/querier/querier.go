@@ -15,16 +15,24 @@
 package querier
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 
+	"github.com/vmware-tanzu/antrea/pkg/agent/cniconflict"
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/drain"
+	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/connections"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipsec"
+	"github.com/vmware-tanzu/antrea/pkg/agent/memorypressure"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	"github.com/vmware-tanzu/antrea/pkg/apis/clusterinformation/v1beta1"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
@@ -42,6 +50,10 @@ type AgentQuerier interface {
 	GetOpenflowClient() openflow.Client
 	GetOVSCtlClient() ovsctl.OVSCtlClient
 	GetNetworkPolicyInfoQuerier() querier.AgentNetworkPolicyInfoQuerier
+	GetWireGuardClient() wireguard.Interface
+	GetDrainController() *drain.Controller
+	GetCNIConflictScanner() *cniconflict.Scanner
+	GetConnTrackDumper() connections.ConnTrackDumper
 }
 
 type agentQuerier struct {
@@ -52,6 +64,12 @@ type agentQuerier struct {
 	ofClient                 openflow.Client
 	ovsBridgeClient          ovsconfig.OVSBridgeClient
 	networkPolicyInfoQuerier querier.AgentNetworkPolicyInfoQuerier
+	pskManager               *ipsec.PSKManager
+	wireGuardClient          wireguard.Interface
+	memoryMonitor            *memorypressure.Monitor
+	drainController          *drain.Controller
+	cniConflictScanner       *cniconflict.Scanner
+	connTrackDumper          connections.ConnTrackDumper
 	apiPort                  int
 }
 
@@ -63,6 +81,12 @@ func NewAgentQuerier(
 	ofClient openflow.Client,
 	ovsBridgeClient ovsconfig.OVSBridgeClient,
 	networkPolicyInfoQuerier querier.AgentNetworkPolicyInfoQuerier,
+	pskManager *ipsec.PSKManager,
+	wireGuardClient wireguard.Interface,
+	memoryMonitor *memorypressure.Monitor,
+	drainController *drain.Controller,
+	cniConflictScanner *cniconflict.Scanner,
+	connTrackDumper connections.ConnTrackDumper,
 	apiPort int,
 ) *agentQuerier {
 	return &agentQuerier{
@@ -73,6 +97,12 @@ func NewAgentQuerier(
 		ofClient:                 ofClient,
 		ovsBridgeClient:          ovsBridgeClient,
 		networkPolicyInfoQuerier: networkPolicyInfoQuerier,
+		pskManager:               pskManager,
+		wireGuardClient:          wireGuardClient,
+		memoryMonitor:            memoryMonitor,
+		drainController:          drainController,
+		cniConflictScanner:       cniConflictScanner,
+		connTrackDumper:          connTrackDumper,
 		apiPort:                  apiPort}
 }
 
@@ -111,6 +141,29 @@ func (aq agentQuerier) GetNetworkPolicyInfoQuerier() querier.AgentNetworkPolicyI
 	return aq.networkPolicyInfoQuerier
 }
 
+// GetWireGuardClient returns the WireGuard client. It is nil if the wireGuard
+// trafficEncryptionMode is not used.
+func (aq agentQuerier) GetWireGuardClient() wireguard.Interface {
+	return aq.wireGuardClient
+}
+
+// GetDrainController returns the controller of the datapath drain mode.
+func (aq agentQuerier) GetDrainController() *drain.Controller {
+	return aq.drainController
+}
+
+// GetCNIConflictScanner returns the scanner of the resources left on the Node
+// by other CNIs. It is nil if Antrea is chained with another CNI.
+func (aq agentQuerier) GetCNIConflictScanner() *cniconflict.Scanner {
+	return aq.cniConflictScanner
+}
+
+// GetConnTrackDumper returns the dumper of the conntrack connections. It is nil
+// if the OVS datapath type is not supported.
+func (aq agentQuerier) GetConnTrackDumper() connections.ConnTrackDumper {
+	return aq.connTrackDumper
+}
+
 // getOVSVersion gets current OVS version.
 func (aq agentQuerier) getOVSVersion() string {
 	v, err := aq.ovsBridgeClient.GetOVSVersion()
@@ -146,7 +199,7 @@ func (aq agentQuerier) getAgentConditions(ovsConnected bool) []v1beta1.AgentCond
 	if !aq.ofClient.IsConnected() {
 		openflowConnectionStatus = v1.ConditionFalse
 	}
-	return []v1beta1.AgentCondition{
+	conditions := []v1beta1.AgentCondition{
 		{
 			Type:              v1beta1.AgentHealthy,
 			Status:            v1.ConditionTrue,
@@ -168,6 +221,79 @@ func (aq agentQuerier) getAgentConditions(ovsConnected bool) []v1beta1.AgentCond
 			LastHeartbeatTime: lastHeartbeatTime,
 		},
 	}
+	if aq.pskManager != nil {
+		conditions = append(conditions, aq.getIPsecPSKRotatedCondition(lastHeartbeatTime))
+	}
+	if aq.memoryMonitor != nil {
+		if condition := aq.getMemoryPressureCondition(lastHeartbeatTime); condition != nil {
+			conditions = append(conditions, *condition)
+		}
+	}
+	if aq.drainController != nil {
+		if condition := aq.getDatapathDrainedCondition(lastHeartbeatTime); condition != nil {
+			conditions = append(conditions, *condition)
+		}
+	}
+	return conditions
+}
+
+// getDatapathDrainedCondition gets whether the datapath of the Node has been drained and the Node is safe to reboot.
+// nil is returned if the datapath is not in drain mode.
+func (aq agentQuerier) getDatapathDrainedCondition(lastHeartbeatTime metav1.Time) *v1beta1.AgentCondition {
+	status := aq.drainController.GetStatus()
+	if status.State == drain.NotDraining {
+		return nil
+	}
+	condition := &v1beta1.AgentCondition{
+		Type:              v1beta1.DatapathDrained,
+		Status:            v1.ConditionTrue,
+		LastHeartbeatTime: lastHeartbeatTime,
+		Reason:            string(status.State),
+		Message:           fmt.Sprintf("Drain started at %s with grace period %v", status.StartTime.UTC().Format(time.RFC3339), status.GracePeriod),
+	}
+	if status.State == drain.Draining {
+		condition.Status = v1.ConditionFalse
+	}
+	return condition
+}
+
+// getMemoryPressureCondition gets whether the agent is under memory pressure. nil is returned if the memory limit of
+// the agent is unknown.
+func (aq agentQuerier) getMemoryPressureCondition(lastHeartbeatTime metav1.Time) *v1beta1.AgentCondition {
+	underPressure, workingSet, limit, lastTransitionTime := aq.memoryMonitor.GetStatus()
+	if limit == 0 {
+		return nil
+	}
+	condition := &v1beta1.AgentCondition{
+		Type:              v1beta1.MemoryPressure,
+		Status:            v1.ConditionFalse,
+		LastHeartbeatTime: lastHeartbeatTime,
+		Message:           fmt.Sprintf("Memory working set is %d bytes, limit is %d bytes", workingSet, limit),
+	}
+	if underPressure {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = "MemoryLimitNearlyReached"
+		condition.Message = fmt.Sprintf("%s; flow exporter and stats collection paused since %s", condition.Message, lastTransitionTime.UTC().Format(time.RFC3339))
+	}
+	return condition
+}
+
+// getIPsecPSKRotatedCondition gets the status of the last IPsec PSK rotation.
+func (aq agentQuerier) getIPsecPSKRotatedCondition(lastHeartbeatTime metav1.Time) v1beta1.AgentCondition {
+	inProgress, pendingNodes, lastRotationTime := aq.pskManager.GetRotationStatus()
+	condition := v1beta1.AgentCondition{
+		Type:              v1beta1.IPsecPSKRotated,
+		Status:            v1.ConditionTrue,
+		LastHeartbeatTime: lastHeartbeatTime,
+	}
+	if inProgress {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = "RotationInProgress"
+		condition.Message = fmt.Sprintf("%d Node(s) have not loaded the current PSK", len(pendingNodes))
+	} else if !lastRotationTime.IsZero() {
+		condition.Message = fmt.Sprintf("PSK rotated at %s", lastRotationTime.UTC().Format(time.RFC3339))
+	}
+	return condition
 }
 
 // getNetworkPolicyControllerInfo gets current network policy controller info
@@ -21,9 +21,13 @@ package testing
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	cniconflict "github.com/vmware-tanzu/antrea/pkg/agent/cniconflict"
 	config "github.com/vmware-tanzu/antrea/pkg/agent/config"
+	drain "github.com/vmware-tanzu/antrea/pkg/agent/drain"
+	connections "github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/connections"
 	interfacestore "github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	openflow "github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	wireguard "github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	v1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/clusterinformation/v1beta1"
 	ovsctl "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
 	querier "github.com/vmware-tanzu/antrea/pkg/querier"
@@ -66,6 +70,48 @@ func (mr *MockAgentQuerierMockRecorder) GetAgentInfo(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgentInfo", reflect.TypeOf((*MockAgentQuerier)(nil).GetAgentInfo), arg0, arg1)
 }
 
+// GetCNIConflictScanner mocks base method
+func (m *MockAgentQuerier) GetCNIConflictScanner() *cniconflict.Scanner {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCNIConflictScanner")
+	ret0, _ := ret[0].(*cniconflict.Scanner)
+	return ret0
+}
+
+// GetCNIConflictScanner indicates an expected call of GetCNIConflictScanner
+func (mr *MockAgentQuerierMockRecorder) GetCNIConflictScanner() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCNIConflictScanner", reflect.TypeOf((*MockAgentQuerier)(nil).GetCNIConflictScanner))
+}
+
+// GetConnTrackDumper mocks base method
+func (m *MockAgentQuerier) GetConnTrackDumper() connections.ConnTrackDumper {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConnTrackDumper")
+	ret0, _ := ret[0].(connections.ConnTrackDumper)
+	return ret0
+}
+
+// GetConnTrackDumper indicates an expected call of GetConnTrackDumper
+func (mr *MockAgentQuerierMockRecorder) GetConnTrackDumper() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConnTrackDumper", reflect.TypeOf((*MockAgentQuerier)(nil).GetConnTrackDumper))
+}
+
+// GetDrainController mocks base method
+func (m *MockAgentQuerier) GetDrainController() *drain.Controller {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrainController")
+	ret0, _ := ret[0].(*drain.Controller)
+	return ret0
+}
+
+// GetDrainController indicates an expected call of GetDrainController
+func (mr *MockAgentQuerierMockRecorder) GetDrainController() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrainController", reflect.TypeOf((*MockAgentQuerier)(nil).GetDrainController))
+}
+
 // GetInterfaceStore mocks base method
 func (m *MockAgentQuerier) GetInterfaceStore() interfacestore.InterfaceStore {
 	m.ctrl.T.Helper()
@@ -163,3 +209,17 @@ func (mr *MockAgentQuerierMockRecorder) GetOpenflowClient() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenflowClient", reflect.TypeOf((*MockAgentQuerier)(nil).GetOpenflowClient))
 }
+
+// GetWireGuardClient mocks base method
+func (m *MockAgentQuerier) GetWireGuardClient() wireguard.Interface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWireGuardClient")
+	ret0, _ := ret[0].(wireguard.Interface)
+	return ret0
+}
+
+// GetWireGuardClient indicates an expected call of GetWireGuardClient
+func (mr *MockAgentQuerierMockRecorder) GetWireGuardClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWireGuardClient", reflect.TypeOf((*MockAgentQuerier)(nil).GetWireGuardClient))
+}
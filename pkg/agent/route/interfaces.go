@@ -17,6 +17,8 @@ package route
 import (
 	"net"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 )
 
@@ -45,6 +47,25 @@ type Interface interface {
 	// if linkName is nil, it should remove the routes.
 	UnMigrateRoutesFromGw(route *net.IPNet, linkName string) error
 
+	// AddSNATRule should add rule to SNAT outgoing traffic with the mark, using the provided SNAT IP.
+	AddSNATRule(snatIP net.IP, mark uint32) error
+
+	// DeleteSNATRule should delete rule to SNAT outgoing traffic with the mark.
+	DeleteSNATRule(mark uint32) error
+
+	// AddNodePort should redirect the NodePort Service traffic destined to the Node IP address and the provided
+	// port to the virtual NodePort IP address through the host gateway, so that it can be load-balanced by
+	// AntreaProxy in the OVS pipeline.
+	AddNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error
+
+	// DeleteNodePort should remove the configuration installed by AddNodePort for the provided port.
+	DeleteNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error
+
+	// SetDrainMode should drop the new connections received from outside the Node and DNAT'd to Services, e.g. through
+	// a NodePort or a LoadBalancer Service, when drain is true, while letting the established connections continue. It
+	// should restore the normal processing of these connections when drain is false.
+	SetDrainMode(drain bool) error
+
 	// Run starts the sync loop.
 	Run(stopCh <-chan struct{})
 }
@@ -19,21 +19,30 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/containernetworking/plugins/pkg/ip"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/ipset"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	"github.com/vmware-tanzu/antrea/pkg/util/env"
 )
@@ -47,6 +56,10 @@ const (
 	antreaPodIPSet = "ANTREA-POD-IP"
 	// antreaPodIP6Set contains all IPv6 Pod CIDRs of this cluster.
 	antreaPodIP6Set = "ANTREA-POD-IP6"
+	// antreaNodePortIPSet contains the Node IP and port pairs of the NodePort Services handled by AntreaProxy.
+	antreaNodePortIPSet = "ANTREA-NODEPORT-IP"
+	// antreaNodePortIP6Set contains the IPv6 Node IP and port pairs of the NodePort Services handled by AntreaProxy.
+	antreaNodePortIP6Set = "ANTREA-NODEPORT-IP6"
 
 	// Antrea managed iptables chains.
 	antreaForwardChain     = "ANTREA-FORWARD"
@@ -54,8 +67,16 @@ const (
 	antreaPostRoutingChain = "ANTREA-POSTROUTING"
 	antreaOutputChain      = "ANTREA-OUTPUT"
 	antreaMangleChain      = "ANTREA-MANGLE"
+
+	// routeConflictReason is the reason of the Event recorded for the local Node when a route installed by Antrea
+	// has been overridden by another agent.
+	routeConflictReason = "RouteConflict"
 )
 
+// IPTablesChains are the iptables chains managed by the route Client, in all
+// tables.
+var IPTablesChains = []string{antreaForwardChain, antreaPreRoutingChain, antreaPostRoutingChain, antreaOutputChain, antreaMangleChain}
+
 // Client implements Interface.
 var _ Interface = &Client{}
 
@@ -79,18 +100,30 @@ type Client struct {
 	nodeRoutes sync.Map
 	// nodeNeighbors caches IPv6 Neighbors to remote host gateway
 	nodeNeighbors sync.Map
+	// markToSNATIP caches the SNAT IPs of the Egresses, indexed by the packet marks allocated for them.
+	markToSNATIP sync.Map
+	// nodePorts caches the ipset entries of the NodePort Services handled by AntreaProxy. It's a map of ipset entry
+	// (e.g. "192.168.1.1,tcp:30080") to the ipset name.
+	nodePorts sync.Map
+	// drainMode is 1 when the new connections received from outside the Node and DNAT'd to Services must be dropped.
+	// It is accessed atomically, as the iptables rules can be restored concurrently with the sync loop.
+	drainMode uint32
 	// iptablesInitialized is used to notify when iptables initialization is done.
 	iptablesInitialized chan struct{}
+	// recorder is used to record Events for the local Node when route conflicts are detected. It can be nil.
+	recorder record.EventRecorder
+	nodeRef  *corev1.ObjectReference
 }
 
 // NewClient returns a route client.
 // TODO: remove param serviceCIDR after kube-proxy is replaced by Antrea Proxy. This param is not used in this file;
 // leaving it here is to be compatible with the implementation on Windows.
-func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool) (*Client, error) {
+func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool, recorder record.EventRecorder) (*Client, error) {
 	return &Client{
 		serviceCIDR:   serviceCIDR,
 		networkConfig: networkConfig,
 		noSNAT:        noSNAT,
+		recorder:      recorder,
 	}, nil
 }
 
@@ -99,6 +132,19 @@ func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSN
 func (c *Client) Initialize(nodeConfig *config.NodeConfig, done func()) error {
 	c.nodeConfig = nodeConfig
 	c.iptablesInitialized = make(chan struct{})
+	// The UID of the Node object is set to the Node name like kubelet does, so that Events can be recorded without
+	// querying the Node.
+	c.nodeRef = &corev1.ObjectReference{
+		Kind: "Node",
+		Name: nodeConfig.Name,
+		UID:  types.UID(nodeConfig.Name),
+	}
+
+	// Makes the iptables binaries use the same backend (legacy or nftables) as the other components managing
+	// iptables on the host, otherwise the rules installed by Antrea would be ignored or conflict with theirs.
+	if err := iptables.EnsureMode(); err != nil {
+		return fmt.Errorf("failed to select iptables backend: %v", err)
+	}
 
 	// Sets up the ipset that will be used in iptables.
 	if err := c.syncIPSet(); err != nil {
@@ -130,7 +176,7 @@ func (c *Client) Initialize(nodeConfig *config.NodeConfig, done func()) error {
 	return nil
 }
 
-// Run waits for iptables initialization, then periodically syncs iptables rules.
+// Run waits for iptables initialization, then periodically syncs iptables rules and the routes to peer Pod CIDRs.
 // It will not return until stopCh is closed.
 func (c *Client) Run(stopCh <-chan struct{}) {
 	<-c.iptablesInitialized
@@ -145,11 +191,129 @@ func (c *Client) syncIPInfra() {
 		klog.Errorf("Failed to sync ipset: %v", err)
 		return
 	}
+	if err := c.syncNodePortIPSet(); err != nil {
+		klog.Errorf("Failed to sync NodePort ipset: %v", err)
+		return
+	}
 	if err := c.syncIPTables(); err != nil {
 		klog.Errorf("Failed to sync iptables: %v", err)
 		return
 	}
 	klog.V(3).Infof("Successfully synced node iptables")
+	if err := c.syncRoutes(); err != nil {
+		klog.Errorf("Failed to sync routes: %v", err)
+		return
+	}
+	klog.V(3).Infof("Successfully synced node routes")
+}
+
+// syncRoutes ensures that the routes installed by Antrea are still present in the host network, and have not been
+// overridden by another agent, e.g. a cloud route controller or the leftovers of another CNI: the routes to the local
+// Pod CIDRs through the host gateway, the routes to the virtual NodePort IPs and the routes to peer Pod CIDRs. Missing
+// routes are restored. Conflicting routes are replaced, and an Event is recorded for the local Node so that the
+// conflict is visible to cluster administrators. All the routes are synced even if some of them fail.
+func (c *Client) syncRoutes() error {
+	var errs []error
+	for _, desired := range c.desiredRoutes() {
+		if err := c.syncRoute(desired); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// The neighbor entries resolving the virtual NodePort IPs are required by their routes.
+	for _, neigh := range c.virtualNodePortNeighbors() {
+		if err := netlink.NeighSet(neigh); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore neigh %v: %v", neigh, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// desiredRoutes returns the routes installed by Antrea which syncRoutes must keep in the host network.
+func (c *Client) desiredRoutes() []*netlink.Route {
+	var routes []*netlink.Route
+	// In networkPolicyOnly mode, the local Pods are routed by the primary CNI.
+	if !c.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() {
+		for _, gwRoute := range []struct {
+			podCIDR *net.IPNet
+			gwIP    net.IP
+		}{
+			{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.GatewayConfig.IPv4},
+			{c.nodeConfig.PodIPv6CIDR, c.nodeConfig.GatewayConfig.IPv6},
+		} {
+			if gwRoute.podCIDR == nil || gwRoute.gwIP == nil {
+				continue
+			}
+			routes = append(routes, &netlink.Route{
+				Dst:       gwRoute.podCIDR,
+				Src:       gwRoute.gwIP,
+				LinkIndex: c.nodeConfig.GatewayConfig.LinkIndex,
+				Scope:     netlink.SCOPE_LINK,
+			})
+		}
+	}
+	for _, isIPv6 := range c.virtualNodePortFamilies() {
+		route, _ := c.virtualNodePortRoute(isIPv6)
+		routes = append(routes, route)
+	}
+	c.nodeRoutes.Range(func(_, v interface{}) bool {
+		routes = append(routes, v.([]*netlink.Route)...)
+		return true
+	})
+	return routes
+}
+
+// syncRoute restores the desired route if it is missing or conflicts with the actual route.
+func (c *Client) syncRoute(desired *netlink.Route) error {
+	actual, err := lookupRoute(desired.Dst)
+	if err != nil {
+		return fmt.Errorf("error listing routes to %s: %v", desired.Dst, err)
+	}
+	if actual == nil {
+		klog.Infof("Restoring missing route %v", desired)
+	} else if routeConflicts(desired, actual) {
+		klog.Warningf("Route %v conflicts with the desired route %v, replacing it", actual, desired)
+		c.recordRouteConflict(desired, actual)
+	} else {
+		return nil
+	}
+	if err := netlink.RouteReplace(desired); err != nil {
+		return fmt.Errorf("failed to restore route %v: %v", desired, err)
+	}
+	return nil
+}
+
+// lookupRoute returns the route for the provided destination in the main routing table, or nil if there is none.
+func lookupRoute(dst *net.IPNet) (*netlink.Route, error) {
+	family := netlink.FAMILY_V4
+	if dst.IP.To4() == nil {
+		family = netlink.FAMILY_V6
+	}
+	routes, err := netlink.RouteListFiltered(family, &netlink.Route{Dst: dst}, netlink.RT_FILTER_DST)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	return &routes[0], nil
+}
+
+// routeConflicts returns true if the actual route doesn't forward traffic the same way as the desired route. The
+// output interface is only compared when the desired route specifies one, as it is resolved by the kernel otherwise.
+func routeConflicts(desired, actual *netlink.Route) bool {
+	if desired.LinkIndex != 0 && desired.LinkIndex != actual.LinkIndex {
+		return true
+	}
+	return !desired.Gw.Equal(actual.Gw)
+}
+
+func (c *Client) recordRouteConflict(desired, actual *netlink.Route) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder.Eventf(c.nodeRef, corev1.EventTypeWarning, routeConflictReason,
+		"Route to %s (gateway %s, link index %d) was overridden with gateway %s, link index %d, protocol %d",
+		desired.Dst, desired.Gw, desired.LinkIndex, actual.Gw, actual.LinkIndex, actual.Protocol)
 }
 
 // syncIPSet ensures that the required ipset exists and it has the initial members.
@@ -164,6 +328,14 @@ func (c *Client) syncIPSet() error {
 	if err := ipset.CreateIPSet(antreaPodIP6Set, ipset.HashNet, true); err != nil {
 		return err
 	}
+	if nodePortEnabled() {
+		if err := ipset.CreateIPSet(antreaNodePortIPSet, ipset.HashIPPort, false); err != nil {
+			return err
+		}
+		if err := ipset.CreateIPSet(antreaNodePortIP6Set, ipset.HashIPPort, true); err != nil {
+			return err
+		}
+	}
 
 	// Loop all valid PodCIDR and add into the corresponding ipset.
 	for _, podCIDR := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
@@ -177,6 +349,45 @@ func (c *Client) syncIPSet() error {
 	return nil
 }
 
+// syncNodePortIPSet ensures that the NodePort ipsets contain exactly the entries of the NodePort Services added with
+// AddNodePort. It is not called when initializing the client, so that NodePort Services keep working until AntreaProxy
+// has synced the Services after a restart of antrea-agent.
+func (c *Client) syncNodePortIPSet() error {
+	if c.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() || !nodePortEnabled() {
+		return nil
+	}
+	for _, ipsetName := range []string{antreaNodePortIPSet, antreaNodePortIP6Set} {
+		entries, err := ipset.ListEntries(ipsetName)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if _, ok := c.nodePorts.Load(entry); ok {
+				continue
+			}
+			klog.Infof("Deleting stale NodePort entry %s from ipset %s", entry, ipsetName)
+			if err := ipset.DelEntry(ipsetName, entry); err != nil {
+				return err
+			}
+		}
+	}
+	var syncErr error
+	c.nodePorts.Range(func(k, v interface{}) bool {
+		if err := ipset.AddEntry(v.(string), k.(string)); err != nil {
+			syncErr = err
+			return false
+		}
+		return true
+	})
+	return syncErr
+}
+
+// nodePortEnabled returns whether the NodePort Services are handled by AntreaProxy, in which case the NodePort ipsets
+// and the iptables rules DNAT'ing the NodePort traffic to the virtual NodePort IP are installed.
+func nodePortEnabled() bool {
+	return features.DefaultFeatureGate.Enabled(features.AntreaProxyNodePort)
+}
+
 func getIPSetName(ip net.IP) string {
 	if ip.To4() == nil {
 		return antreaPodIP6Set
@@ -210,36 +421,55 @@ func (c *Client) writeEKSMangleRule(iptablesData *bytes.Buffer) {
 // syncIPTables ensure that the iptables infrastructure we use is set up.
 // It's idempotent and can safely be called on every startup.
 func (c *Client) syncIPTables() error {
-	var err error
 	v4Enabled := config.IsIPv4Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
 	v6Enabled := config.IsIPv6Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
-	c.ipt, err = iptables.New(v4Enabled, v6Enabled)
-	if err != nil {
-		return fmt.Errorf("error creating IPTables instance: %v", err)
+	// The IPTables instance is created only once, as the SNAT rules can be updated concurrently with the sync loop.
+	if c.ipt == nil {
+		ipt, err := iptables.New(v4Enabled, v6Enabled)
+		if err != nil {
+			return fmt.Errorf("error creating IPTables instance: %v", err)
+		}
+		c.ipt = ipt
 	}
 	// Create the antrea managed chains and link them to built-in chains.
 	// We cannot use iptables-restore for these jump rules because there
 	// are non antrea managed rules in built-in chains.
-	jumpRules := []struct{ table, srcChain, dstChain, comment string }{
-		{iptables.RawTable, iptables.PreRoutingChain, antreaPreRoutingChain, "Antrea: jump to Antrea prerouting rules"},
-		{iptables.RawTable, iptables.OutputChain, antreaOutputChain, "Antrea: jump to Antrea output rules"},
-		{iptables.FilterTable, iptables.ForwardChain, antreaForwardChain, "Antrea: jump to Antrea forwarding rules"},
-		{iptables.NATTable, iptables.PostRoutingChain, antreaPostRoutingChain, "Antrea: jump to Antrea postrouting rules"},
-		{iptables.MangleTable, iptables.PreRoutingChain, antreaMangleChain, "Antrea: jump to Antrea mangle rules"},
+	// The jump rules of the nat PREROUTING and OUTPUT chains are inserted at the beginning of the chains, so that the
+	// NodePort Service traffic is handled by AntreaProxy instead of kube-proxy if both are running.
+	jumpRules := []struct {
+		table, srcChain, dstChain, comment string
+		prepend                            bool
+	}{
+		{iptables.RawTable, iptables.PreRoutingChain, antreaPreRoutingChain, "Antrea: jump to Antrea prerouting rules", false},
+		{iptables.RawTable, iptables.OutputChain, antreaOutputChain, "Antrea: jump to Antrea output rules", false},
+		{iptables.FilterTable, iptables.ForwardChain, antreaForwardChain, "Antrea: jump to Antrea forwarding rules", false},
+		{iptables.NATTable, iptables.PreRoutingChain, antreaPreRoutingChain, "Antrea: jump to Antrea prerouting rules", true},
+		{iptables.NATTable, iptables.OutputChain, antreaOutputChain, "Antrea: jump to Antrea output rules", true},
+		{iptables.NATTable, iptables.PostRoutingChain, antreaPostRoutingChain, "Antrea: jump to Antrea postrouting rules", false},
+		{iptables.MangleTable, iptables.PreRoutingChain, antreaMangleChain, "Antrea: jump to Antrea mangle rules", false},
+		{iptables.MangleTable, iptables.ForwardChain, antreaForwardChain, "Antrea: jump to Antrea forwarding rules", false},
 	}
 	for _, rule := range jumpRules {
 		if err := c.ipt.EnsureChain(rule.table, rule.dstChain); err != nil {
 			return err
 		}
 		ruleSpec := []string{"-j", rule.dstChain, "-m", "comment", "--comment", rule.comment}
-		if err := c.ipt.EnsureRule(rule.table, rule.srcChain, ruleSpec); err != nil {
+		if rule.prepend {
+			if err := c.ipt.InsertRule(rule.table, rule.srcChain, ruleSpec); err != nil {
+				return err
+			}
+		} else if err := c.ipt.EnsureRule(rule.table, rule.srcChain, ruleSpec); err != nil {
 			return err
 		}
 	}
+	return c.restoreIptables(v4Enabled, v6Enabled)
+}
 
+// restoreIptables uses iptables-restore to (re)create the rules in the Antrea managed chains.
+func (c *Client) restoreIptables(v4Enabled, v6Enabled bool) error {
 	// Use iptables-restore to configure IPv4 settings.
 	if v4Enabled {
-		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv4CIDR, antreaPodIPSet)
+		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv4CIDR, antreaPodIPSet, antreaNodePortIPSet, config.VirtualNodePortIPv4)
 		// Setting --noflush to keep the previous contents (i.e. non antrea managed chains) of the tables.
 		if err := c.ipt.Restore(iptablesData.Bytes(), false, false); err != nil {
 			return err
@@ -248,7 +478,7 @@ func (c *Client) syncIPTables() error {
 
 	// Use ip6tables-restore to configure IPv6 settings.
 	if v6Enabled {
-		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv6CIDR, antreaPodIP6Set)
+		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv6CIDR, antreaPodIP6Set, antreaNodePortIP6Set, config.VirtualNodePortIPv6)
 		// Setting --noflush to keep the previous contents (i.e. non antrea managed chains) of the tables.
 		if err := c.ipt.Restore(iptablesData.Bytes(), false, true); err != nil {
 			return err
@@ -257,7 +487,7 @@ func (c *Client) syncIPTables() error {
 	return nil
 }
 
-func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes.Buffer {
+func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet, nodePortIPSet string, virtualNodePortIP net.IP) *bytes.Buffer {
 	// Create required rules in the antrea chains.
 	// Use iptables-restore as it flushes the involved chains and creates the desired rules
 	// with a single call, instead of string matching to clean up stale rules.
@@ -299,12 +529,27 @@ func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes
 	// Write head lines anyway so the undesired rules can be deleted when noEncap -> encap.
 	writeLine(iptablesData, "*mangle")
 	writeLine(iptablesData, iptables.MakeChainLine(antreaMangleChain))
+	writeLine(iptablesData, iptables.MakeChainLine(antreaForwardChain))
 	hostGateway := c.nodeConfig.GatewayConfig.Name
 	// When Antrea is used to enforce NetworkPolicies in EKS, an additional iptables
 	// mangle rule is required. See https://github.com/vmware-tanzu/antrea/issues/678.
 	if env.IsCloudEKS() {
 		c.writeEKSMangleRule(iptablesData)
 	}
+	// In drain mode, the new Service connections received from outside the Node are dropped, regardless of whether
+	// the Service is implemented by AntreaProxy or kube-proxy. The rule is in the mangle table as the filter rules of
+	// kube-proxy accept the Service traffic before the Antrea filter rules are evaluated. The established connections
+	// are not affected.
+	if atomic.LoadUint32(&c.drainMode) == 1 {
+		writeLine(iptablesData, []string{
+			"-A", antreaForwardChain,
+			"-m", "comment", "--comment", `"Antrea: drop new external Service connections in drain mode"`,
+			"!", "-i", hostGateway,
+			"-m", "conntrack", "--ctstate", "NEW",
+			"-m", "conntrack", "--ctstate", "DNAT",
+			"-j", iptables.DropTarget,
+		}...)
+	}
 	writeLine(iptablesData, "COMMIT")
 
 	writeLine(iptablesData, "*filter")
@@ -324,7 +569,37 @@ func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes
 	writeLine(iptablesData, "COMMIT")
 
 	writeLine(iptablesData, "*nat")
+	writeLine(iptablesData, iptables.MakeChainLine(antreaPreRoutingChain))
+	writeLine(iptablesData, iptables.MakeChainLine(antreaOutputChain))
 	writeLine(iptablesData, iptables.MakeChainLine(antreaPostRoutingChain))
+	if !c.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() && nodePortEnabled() {
+		// NodePort Service traffic destined to the Node IP is DNAT'd to the virtual NodePort IP, which is routed to
+		// the host gateway, so that it can be load-balanced by AntreaProxy in the OVS pipeline. Its source IP is
+		// masqueraded, so that the reply traffic is always sent back through this Node and un-NAT'd.
+		for _, chain := range []string{antreaPreRoutingChain, antreaOutputChain} {
+			writeLine(iptablesData, []string{
+				"-A", chain,
+				"-m", "comment", "--comment", `"Antrea: DNAT NodePort Service traffic to the virtual NodePort IP"`,
+				"-m", "set", "--match-set", nodePortIPSet, "dst,dst",
+				"-j", iptables.DNATTarget, "--to-destination", virtualNodePortIP.String(),
+			}...)
+		}
+		writeLine(iptablesData, []string{
+			"-A", antreaPostRoutingChain,
+			"-m", "comment", "--comment", `"Antrea: masquerade NodePort Service traffic"`,
+			"-d", virtualNodePortIP.String(), "-o", hostGateway,
+			"-j", iptables.MasqueradeTarget,
+		}...)
+	}
+	// The SNAT rules of the Egresses must be installed before the masquerade rule.
+	for _, mark := range c.snatMarks() {
+		snatIPI, _ := c.markToSNATIP.Load(mark)
+		snatIP := snatIPI.(net.IP)
+		if (snatIP.To4() != nil) != (podCIDR.IP.To4() != nil) {
+			continue
+		}
+		writeLine(iptablesData, append([]string{"-A", antreaPostRoutingChain}, c.snatRuleSpec(snatIP, mark)...)...)
+	}
 	if !c.noSNAT {
 		writeLine(iptablesData, []string{
 			"-A", antreaPostRoutingChain,
@@ -388,6 +663,10 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 		if reflect.DeepEqual(route.Dst, c.nodeConfig.PodIPv4CIDR) || reflect.DeepEqual(route.Dst, c.nodeConfig.PodIPv6CIDR) {
 			continue
 		}
+		if route.Dst != nil && (route.Dst.IP.Equal(config.VirtualNodePortIPv4) || route.Dst.IP.Equal(config.VirtualNodePortIPv6)) {
+			// The route to the virtual NodePort IP is installed by AddNodePort.
+			continue
+		}
 		if desiredPodCIDRs.Has(route.Dst.String()) {
 			continue
 		}
@@ -409,7 +688,7 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 		return err
 	}
 	for neighIP, actualNeigh := range actualNeighbors {
-		if desiredGWs.Has(neighIP) {
+		if desiredGWs.Has(neighIP) || neighIP == config.VirtualNodePortIPv6.String() {
 			continue
 		}
 		klog.V(4).Infof("Deleting orphaned IPv6 neighbor %v", actualNeigh)
@@ -478,7 +757,17 @@ func (c *Client) AddRoutes(podCIDR *net.IPNet, nodeIP, nodeGwIP net.IP) error {
 		Dst: podCIDR,
 	}
 	var routes []*netlink.Route
-	if c.networkConfig.TrafficEncapMode.NeedsEncapToPeer(nodeIP, c.nodeConfig.NodeIPAddr) {
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		// Pod traffic to the peer Node is encrypted by WireGuard. As the WireGuard peer of this Node only accepts
+		// traffic from its Pod CIDRs, the local gateway IP is used as the source IP of the traffic from the host.
+		route.LinkIndex = c.nodeConfig.WireGuardConfig.LinkIndex
+		route.Scope = netlink.SCOPE_LINK
+		if podCIDR.IP.To4() != nil {
+			route.Src = c.nodeConfig.GatewayConfig.IPv4
+		} else {
+			route.Src = c.nodeConfig.GatewayConfig.IPv6
+		}
+	} else if c.networkConfig.TrafficEncapMode.NeedsEncapToPeer(nodeIP, c.nodeConfig.NodeIPAddr) {
 		if podCIDR.IP.To4() == nil {
 			// "on-link" is not identified in IPv6 route entries, so split the configuration into 2 entries.
 			routes = []*netlink.Route{
@@ -557,6 +846,188 @@ func (c *Client) DeleteRoutes(podCIDR *net.IPNet) error {
 	return nil
 }
 
+// snatMarks returns the packet marks of the SNAT IPs in ascending order, so that the iptables rules are generated in
+// a stable order.
+func (c *Client) snatMarks() []uint32 {
+	var marks []uint32
+	c.markToSNATIP.Range(func(key, _ interface{}) bool {
+		marks = append(marks, key.(uint32))
+		return true
+	})
+	sort.Slice(marks, func(i, j int) bool { return marks[i] < marks[j] })
+	return marks
+}
+
+// snatRuleSpec returns the iptables rule that SNATs the packets with the provided mark to the SNAT IP.
+func (c *Client) snatRuleSpec(snatIP net.IP, snatMark uint32) []string {
+	return []string{
+		"-m", "comment", "--comment", `"Antrea: SNAT Pod to external packets"`,
+		// The packets sent to local Pods through the gateway interface must not be SNAT'd.
+		"!", "-o", c.nodeConfig.GatewayConfig.Name,
+		"-m", "mark", "--mark", fmt.Sprintf("%#x/%#x", snatMark, agenttypes.SNATIPMarkMask),
+		"-j", iptables.SNATTarget, "--to", snatIP.String(),
+	}
+}
+
+// AddSNATRule adds the iptables rule to SNAT the packets with the provided mark to the SNAT IP. The rule is kept by
+// the periodic iptables sync.
+func (c *Client) AddSNATRule(snatIP net.IP, mark uint32) error {
+	c.markToSNATIP.Store(mark, snatIP)
+	return c.restoreSNATRules(snatIP)
+}
+
+// DeleteSNATRule deletes the iptables SNAT rule for the provided mark.
+func (c *Client) DeleteSNATRule(mark uint32) error {
+	snatIPI, exists := c.markToSNATIP.Load(mark)
+	if !exists {
+		return nil
+	}
+	c.markToSNATIP.Delete(mark)
+	return c.restoreSNATRules(snatIPI.(net.IP))
+}
+
+// SetDrainMode drops the new connections received from outside the Node and DNAT'd to Services when drain is true,
+// and stops dropping them when drain is false.
+func (c *Client) SetDrainMode(drain bool) error {
+	var drainMode uint32
+	if drain {
+		drainMode = 1
+	}
+	prevDrainMode := atomic.SwapUint32(&c.drainMode, drainMode)
+	if prevDrainMode == drainMode {
+		return nil
+	}
+	if err := c.restoreIptables(config.IsIPv4Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode), config.IsIPv6Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)); err != nil {
+		// Roll back, so that drainMode reflects the installed rules and the call can be retried.
+		atomic.StoreUint32(&c.drainMode, prevDrainMode)
+		return err
+	}
+	return nil
+}
+
+// restoreSNATRules restores the iptables rules of the address family of the provided SNAT IP.
+func (c *Client) restoreSNATRules(snatIP net.IP) error {
+	isIPv4 := snatIP.To4() != nil
+	v4Enabled := isIPv4 && config.IsIPv4Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
+	v6Enabled := !isIPv4 && config.IsIPv6Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
+	return c.restoreIptables(v4Enabled, v6Enabled)
+}
+
+// nodePortIPSetEntry returns the NodePort ipset name and entry for the provided port, or an empty ipset name if the
+// address family does not match the Node IP address.
+func (c *Client) nodePortIPSetEntry(port uint16, protocol corev1.Protocol, isIPv6 bool) (string, string) {
+	nodeIP := c.nodeConfig.NodeIPAddr.IP
+	if (nodeIP.To4() == nil) != isIPv6 {
+		return "", ""
+	}
+	ipsetName := antreaNodePortIPSet
+	if isIPv6 {
+		ipsetName = antreaNodePortIP6Set
+	}
+	return ipsetName, fmt.Sprintf("%s,%s:%d", nodeIP.String(), strings.ToLower(string(protocol)), port)
+}
+
+// AddNodePort adds the Node IP and the provided port to the NodePort ipset, so that the traffic destined to them is
+// DNAT'd to the virtual NodePort IP by the iptables rules. It also ensures that the virtual NodePort IP is routed to
+// the host gateway, with a permanent neighbor entry resolving it to the global virtual MAC address.
+func (c *Client) AddNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error {
+	ipsetName, entry := c.nodePortIPSetEntry(port, protocol, isIPv6)
+	if ipsetName == "" {
+		klog.V(2).Infof("Skipping NodePort %s:%d as the Node has no IP address of this family", protocol, port)
+		return nil
+	}
+	if err := c.addVirtualNodePortRoute(isIPv6); err != nil {
+		return err
+	}
+	if err := ipset.AddEntry(ipsetName, entry); err != nil {
+		return err
+	}
+	c.nodePorts.Store(entry, ipsetName)
+	return nil
+}
+
+// DeleteNodePort removes the Node IP and the provided port from the NodePort ipset.
+func (c *Client) DeleteNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error {
+	ipsetName, entry := c.nodePortIPSetEntry(port, protocol, isIPv6)
+	if ipsetName == "" {
+		return nil
+	}
+	if err := ipset.DelEntry(ipsetName, entry); err != nil {
+		return err
+	}
+	c.nodePorts.Delete(entry)
+	return nil
+}
+
+// virtualNodePortRoute returns the route and the neighbor entry of the virtual NodePort IP on the host gateway.
+func (c *Client) virtualNodePortRoute(isIPv6 bool) (*netlink.Route, *netlink.Neigh) {
+	virtualIP := config.VirtualNodePortIPv4
+	mask := net.CIDRMask(32, 32)
+	family := netlink.FAMILY_V4
+	if isIPv6 {
+		virtualIP = config.VirtualNodePortIPv6
+		mask = net.CIDRMask(128, 128)
+		family = netlink.FAMILY_V6
+	}
+	route := &netlink.Route{
+		Dst:       &net.IPNet{IP: virtualIP, Mask: mask},
+		LinkIndex: c.nodeConfig.GatewayConfig.LinkIndex,
+		Scope:     netlink.SCOPE_LINK,
+	}
+	neigh := &netlink.Neigh{
+		LinkIndex:    c.nodeConfig.GatewayConfig.LinkIndex,
+		Family:       family,
+		State:        netlink.NUD_PERMANENT,
+		IP:           virtualIP,
+		HardwareAddr: globalVMAC,
+	}
+	return route, neigh
+}
+
+// virtualNodePortFamilies returns the IP families of the NodePorts added with AddNodePort, for which the routes to the
+// virtual NodePort IPs are installed. isIPv6 is true for the IPv6 family.
+func (c *Client) virtualNodePortFamilies() []bool {
+	var v4, v6 bool
+	c.nodePorts.Range(func(_, v interface{}) bool {
+		if v.(string) == antreaNodePortIP6Set {
+			v6 = true
+		} else {
+			v4 = true
+		}
+		return true
+	})
+	var families []bool
+	if v4 {
+		families = append(families, false)
+	}
+	if v6 {
+		families = append(families, true)
+	}
+	return families
+}
+
+// virtualNodePortNeighbors returns the neighbor entries of the virtual NodePort IPs whose routes are installed.
+func (c *Client) virtualNodePortNeighbors() []*netlink.Neigh {
+	var neighs []*netlink.Neigh
+	for _, isIPv6 := range c.virtualNodePortFamilies() {
+		_, neigh := c.virtualNodePortRoute(isIPv6)
+		neighs = append(neighs, neigh)
+	}
+	return neighs
+}
+
+// addVirtualNodePortRoute installs the route and the neighbor entry of the virtual NodePort IP on the host gateway.
+func (c *Client) addVirtualNodePortRoute(isIPv6 bool) error {
+	route, neigh := c.virtualNodePortRoute(isIPv6)
+	if err := netlink.RouteReplace(route); err != nil {
+		return fmt.Errorf("failed to install route to virtual NodePort IP %s: %v", neigh.IP, err)
+	}
+	if err := netlink.NeighSet(neigh); err != nil {
+		return fmt.Errorf("failed to add neigh %v to gw %s: %v", neigh, c.nodeConfig.GatewayConfig.Name, err)
+	}
+	return nil
+}
+
 // Join all words with spaces, terminate with newline and write to buf.
 func writeLine(buf *bytes.Buffer, words ...string) {
 	// We avoid strings.Join for performance reasons.
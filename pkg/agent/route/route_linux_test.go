@@ -0,0 +1,185 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/features"
+)
+
+func TestRouteConflicts(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	gwIP := net.ParseIP("10.10.1.1")
+	nodeIP := net.ParseIP("192.168.10.11")
+	tests := []struct {
+		name     string
+		desired  *netlink.Route
+		actual   *netlink.Route
+		expected bool
+	}{
+		{
+			name:     "same route",
+			desired:  &netlink.Route{Dst: podCIDR, Gw: gwIP, LinkIndex: 10},
+			actual:   &netlink.Route{Dst: podCIDR, Gw: gwIP, LinkIndex: 10},
+			expected: false,
+		},
+		{
+			name:     "different gateway",
+			desired:  &netlink.Route{Dst: podCIDR, Gw: gwIP, LinkIndex: 10},
+			actual:   &netlink.Route{Dst: podCIDR, Gw: nodeIP, LinkIndex: 10},
+			expected: true,
+		},
+		{
+			name:     "different link",
+			desired:  &netlink.Route{Dst: podCIDR, Gw: gwIP, LinkIndex: 10},
+			actual:   &netlink.Route{Dst: podCIDR, Gw: gwIP, LinkIndex: 2},
+			expected: true,
+		},
+		{
+			name:     "link resolved by kernel",
+			desired:  &netlink.Route{Dst: podCIDR, Gw: nodeIP},
+			actual:   &netlink.Route{Dst: podCIDR, Gw: nodeIP, LinkIndex: 2},
+			expected: false,
+		},
+		{
+			name:     "no gateway",
+			desired:  &netlink.Route{Dst: podCIDR, Gw: nodeIP},
+			actual:   &netlink.Route{Dst: podCIDR, LinkIndex: 2},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, routeConflicts(tt.desired, tt.actual))
+		})
+	}
+}
+
+func TestRecordRouteConflict(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	recorder := record.NewFakeRecorder(1)
+	client, _ := NewClient(nil, &config.NetworkConfig{}, false, recorder)
+	client.nodeRef = &corev1.ObjectReference{Kind: "Node", Name: "node1"}
+
+	client.recordRouteConflict(
+		&netlink.Route{Dst: podCIDR, Gw: net.ParseIP("10.10.1.1"), LinkIndex: 10},
+		&netlink.Route{Dst: podCIDR, Gw: net.ParseIP("192.168.10.1"), LinkIndex: 2, Protocol: 3},
+	)
+	event := <-recorder.Events
+	assert.Equal(t, "Warning RouteConflict Route to 10.10.1.0/24 (gateway 10.10.1.1, link index 10) was overridden with gateway 192.168.10.1, link index 2, protocol 3", event)
+}
+
+func TestDesiredRoutes(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	_, peerPodCIDR, _ := net.ParseCIDR("10.10.2.0/24")
+	gwIP := net.ParseIP("10.10.1.1")
+	peerRoute := &netlink.Route{Dst: peerPodCIDR, Gw: net.ParseIP("10.10.2.1"), LinkIndex: 10}
+	nodeConfig := &config.NodeConfig{
+		PodIPv4CIDR:   podCIDR,
+		GatewayConfig: &config.GatewayConfig{Name: "antrea-gw0", IPv4: gwIP, LinkIndex: 10},
+	}
+	tests := []struct {
+		name      string
+		mode      config.TrafficEncapModeType
+		nodePorts map[string]string
+		expected  []*netlink.Route
+	}{
+		{
+			name: "encap",
+			mode: config.TrafficEncapModeEncap,
+			expected: []*netlink.Route{
+				{Dst: podCIDR, Src: gwIP, LinkIndex: 10, Scope: netlink.SCOPE_LINK},
+				peerRoute,
+			},
+		},
+		{
+			name:      "encap with NodePorts",
+			mode:      config.TrafficEncapModeEncap,
+			nodePorts: map[string]string{"192.168.10.11,tcp:30080": antreaNodePortIPSet},
+			expected: []*netlink.Route{
+				{Dst: podCIDR, Src: gwIP, LinkIndex: 10, Scope: netlink.SCOPE_LINK},
+				{Dst: &net.IPNet{IP: config.VirtualNodePortIPv4, Mask: net.CIDRMask(32, 32)}, LinkIndex: 10, Scope: netlink.SCOPE_LINK},
+				peerRoute,
+			},
+		},
+		{
+			name:     "networkPolicyOnly",
+			mode:     config.TrafficEncapModeNetworkPolicyOnly,
+			expected: []*netlink.Route{peerRoute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := NewClient(nil, &config.NetworkConfig{TrafficEncapMode: tt.mode}, false, nil)
+			client.nodeConfig = nodeConfig
+			client.nodeRoutes.Store(peerPodCIDR.String(), []*netlink.Route{peerRoute})
+			for entry, ipsetName := range tt.nodePorts {
+				client.nodePorts.Store(entry, ipsetName)
+			}
+			assert.Equal(t, tt.expected, client.desiredRoutes())
+		})
+	}
+}
+
+func TestRestoreIptablesDataNodePort(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	for _, tt := range []struct {
+		name            string
+		nodePortEnabled bool
+		encapMode       config.TrafficEncapModeType
+		expectedDNAT    bool
+	}{
+		{
+			name:            "AntreaProxyNodePort enabled",
+			nodePortEnabled: true,
+			encapMode:       config.TrafficEncapModeEncap,
+			expectedDNAT:    true,
+		},
+		{
+			name:            "AntreaProxyNodePort disabled",
+			nodePortEnabled: false,
+			encapMode:       config.TrafficEncapModeEncap,
+			expectedDNAT:    false,
+		},
+		{
+			name:            "networkPolicyOnly mode",
+			nodePortEnabled: true,
+			encapMode:       config.TrafficEncapModeNetworkPolicyOnly,
+			expectedDNAT:    false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer featuregatetesting.SetFeatureGateDuringTest(t, features.DefaultFeatureGate, features.AntreaProxyNodePort, tt.nodePortEnabled)()
+			c := &Client{
+				networkConfig: &config.NetworkConfig{TrafficEncapMode: tt.encapMode},
+				nodeConfig:    &config.NodeConfig{GatewayConfig: &config.GatewayConfig{Name: "antrea-gw0"}},
+				noSNAT:        true,
+			}
+			data := c.restoreIptablesData(podCIDR, antreaPodIPSet, antreaNodePortIPSet, config.VirtualNodePortIPv4).String()
+			assert.Equal(t, tt.expectedDNAT, strings.Contains(data, "--match-set "+antreaNodePortIPSet))
+			assert.Equal(t, tt.expectedDNAT, strings.Contains(data, "-d "+config.VirtualNodePortIPv4.String()))
+		})
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"sync"
 
 	"github.com/rakelkar/gonetsh/netroute"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
@@ -45,7 +47,7 @@ type Client struct {
 
 // NewClient returns a route client.
 // Todo: remove param serviceCIDR after kube-proxy is replaced by Antrea Proxy completely.
-func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool) (*Client, error) {
+func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool, recorder record.EventRecorder) (*Client, error) {
 	nr := netroute.New()
 	return &Client{
 		nr:          nr,
@@ -152,6 +154,31 @@ func (c *Client) UnMigrateRoutesFromGw(route *net.IPNet, linkName string) error
 	return errors.New("UnMigrateRoutesFromGw is unsupported on Windows")
 }
 
+// AddSNATRule is not supported on Windows.
+func (c *Client) AddSNATRule(snatIP net.IP, mark uint32) error {
+	return errors.New("AddSNATRule is unsupported on Windows")
+}
+
+// DeleteSNATRule is not supported on Windows.
+func (c *Client) DeleteSNATRule(mark uint32) error {
+	return errors.New("DeleteSNATRule is unsupported on Windows")
+}
+
+// AddNodePort is not supported on Windows.
+func (c *Client) AddNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error {
+	return errors.New("AddNodePort is unsupported on Windows")
+}
+
+// DeleteNodePort is not supported on Windows.
+func (c *Client) DeleteNodePort(port uint16, protocol corev1.Protocol, isIPv6 bool) error {
+	return errors.New("DeleteNodePort is unsupported on Windows")
+}
+
+// SetDrainMode is not supported on Windows.
+func (c *Client) SetDrainMode(drain bool) error {
+	return errors.New("SetDrainMode is unsupported on Windows")
+}
+
 // Run is not supported on Windows and returns immediately.
 func (c *Client) Run(stopCh <-chan struct{}) {
 	return
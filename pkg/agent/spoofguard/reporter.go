@@ -0,0 +1,197 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spoofguard reports the packets sent by local Pods which fail the
+// spoof guard checks of the OVS pipeline, i.e. the packets whose source MAC or
+// IP address does not belong to the Pod sending them.
+package spoofguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+)
+
+const (
+	// Mode values of the spoofGuardMode configuration parameter.
+	ModeDrop    = "drop"
+	ModeLogOnly = "logOnly"
+
+	// violationEventReason is the reason of the Events recorded for the Pods
+	// sending packets which fail the spoof guard checks.
+	violationEventReason = "SpoofGuardViolation"
+	// reportInterval is the minimum interval between two reports (log message
+	// and Event) for the same Pod. All violations are still counted.
+	reportInterval = time.Minute
+	// maxReportedPods is the maximum number of Pods for which the time of the
+	// last report is remembered.
+	maxReportedPods = 1024
+)
+
+// Violation types, used as the "type" label of the violation metric.
+const (
+	// violationTypeMAC is used for packets whose source MAC address does not
+	// belong to the Pod.
+	violationTypeMAC = "mac"
+	// violationTypeIP is used for IP packets sent with the MAC address of the
+	// Pod, but whose source IP address does not belong to the Pod.
+	violationTypeIP = "ip"
+	// violationTypeARP is used for ARP packets whose sender hardware or
+	// protocol address does not belong to the Pod.
+	violationTypeARP = "arp"
+)
+
+// Reporter is the PacketIn handler for the PacketInReasonSG reason. It counts
+// the spoof guard violations, logs them and optionally records an Event for
+// the Pod which sent the packet. Logs and Events are rate limited per Pod.
+type Reporter struct {
+	interfaceStore interfacestore.InterfaceStore
+	// recorder is used to record Events for the Pods which violate the spoof
+	// guard. It is nil if Events are disabled.
+	recorder record.EventRecorder
+	// logOnly indicates whether the packets failing the checks are forwarded
+	// instead of being dropped.
+	logOnly bool
+	// reportedPods stores the Pods which have been reported during the last
+	// reportInterval.
+	reportedPods *cache.LRUExpireCache
+}
+
+// NewReporter creates a Reporter. recorder can be nil, in which case no Event
+// is recorded.
+func NewReporter(interfaceStore interfacestore.InterfaceStore, recorder record.EventRecorder, logOnly bool) *Reporter {
+	return &Reporter{
+		interfaceStore: interfaceStore,
+		recorder:       recorder,
+		logOnly:        logOnly,
+		reportedPods:   cache.NewLRUExpireCache(maxReportedPods),
+	}
+}
+
+// HandlePacketIn implements openflow.PacketInHandler.
+func (r *Reporter) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	if pktIn == nil {
+		return errors.New("empty packetin for spoof guard")
+	}
+	inPort, err := getInPort(pktIn)
+	if err != nil {
+		return err
+	}
+	srcMAC, srcIP, isARP, err := getPacketSource(pktIn)
+	if err != nil {
+		return err
+	}
+	intf := r.getPodInterface(inPort)
+
+	violationType := violationTypeIP
+	if isARP {
+		violationType = violationTypeARP
+	} else if intf == nil || srcMAC.String() != intf.MAC.String() {
+		violationType = violationTypeMAC
+	}
+	metrics.SpoofGuardViolationCount.WithLabelValues(violationType).Inc()
+
+	if intf == nil {
+		// The port may have been deleted since the packet was sent.
+		klog.V(2).Infof("Received packet failing spoof guard checks from OVS port %d which is not a Pod port", inPort)
+		return nil
+	}
+	podKey := intf.PodNamespace + "/" + intf.PodName
+	if _, ok := r.reportedPods.Get(podKey); ok {
+		return nil
+	}
+	r.reportedPods.Add(podKey, struct{}{}, reportInterval)
+
+	action := "dropped"
+	if r.logOnly {
+		action = "forwarded"
+	}
+	message := fmt.Sprintf("Pod sent packet with source MAC %s and source IP %s which do not match its interface (MAC %s, IPs %v); packet was %s",
+		srcMAC, srcIP, intf.MAC, intf.IPs, action)
+	klog.Warningf("Spoof guard violation by Pod %s: %s", podKey, message)
+	if r.recorder != nil {
+		podRef := &corev1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: intf.PodNamespace,
+			Name:      intf.PodName,
+		}
+		r.recorder.Event(podRef, corev1.EventTypeWarning, violationEventReason, message)
+	}
+	return nil
+}
+
+// getPodInterface returns the InterfaceConfig of the local Pod attached to the
+// provided OVS port, or nil if there is no such Pod.
+func (r *Reporter) getPodInterface(ofPort uint32) *interfacestore.InterfaceConfig {
+	intf, ok := r.interfaceStore.GetInterfaceByOFPort(ofPort)
+	if !ok || intf.Type != interfacestore.ContainerInterface {
+		return nil
+	}
+	return intf
+}
+
+// getInPort returns the OVS port on which the packet was received.
+func getInPort(pktIn *ofctrl.PacketIn) (uint32, error) {
+	for _, field := range pktIn.Match.Fields {
+		if field.Class != openflow13.OXM_CLASS_OPENFLOW_BASIC || field.Field != openflow13.OXM_FIELD_IN_PORT {
+			continue
+		}
+		inPortField, ok := field.Value.(*openflow13.InPortField)
+		if !ok {
+			return 0, errors.New("invalid in_port in packetin")
+		}
+		return inPortField.InPort, nil
+	}
+	return 0, errors.New("in_port not found in packetin")
+}
+
+// getPacketSource returns the source MAC and IP addresses of the packet, and
+// whether it is an ARP packet.
+func getPacketSource(pktIn *ofctrl.PacketIn) (net.HardwareAddr, net.IP, bool, error) {
+	srcMAC := pktIn.Data.HWSrc
+	switch pktIn.Data.Ethertype {
+	case protocol.IPv4_MSG:
+		ipPacket, ok := pktIn.Data.Data.(*protocol.IPv4)
+		if !ok {
+			return nil, nil, false, errors.New("invalid IPv4 packet")
+		}
+		return srcMAC, ipPacket.NWSrc, false, nil
+	case protocol.IPv6_MSG:
+		ipPacket, ok := pktIn.Data.Data.(*protocol.IPv6)
+		if !ok {
+			return nil, nil, false, errors.New("invalid IPv6 packet")
+		}
+		return srcMAC, ipPacket.NWSrc, false, nil
+	case protocol.ARP_MSG:
+		arpPacket, ok := pktIn.Data.Data.(*protocol.ARP)
+		if !ok {
+			return nil, nil, false, errors.New("invalid ARP packet")
+		}
+		return arpPacket.HWSrc, arpPacket.IPSrc, true, nil
+	default:
+		return nil, nil, false, fmt.Errorf("unsupported packet Ethertype: %d", pktIn.Data.Ethertype)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spoofguard
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+)
+
+var (
+	podMAC   = net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x01}
+	podIP    = net.ParseIP("10.10.0.2")
+	otherMAC = net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x02}
+	otherIP  = net.ParseIP("10.10.0.3")
+)
+
+func newPacketIn(inPort uint32, ethertype uint16, srcMAC net.HardwareAddr, data util.Message) *ofctrl.PacketIn {
+	return &ofctrl.PacketIn{
+		Match: openflow13.Match{
+			Fields: []openflow13.MatchField{*openflow13.NewInPortField(inPort)},
+		},
+		Data: protocol.Ethernet{
+			HWSrc:     srcMAC,
+			Ethertype: ethertype,
+			Data:      data,
+		},
+	}
+}
+
+func newTestReporter(logOnly bool) (*Reporter, *record.FakeRecorder) {
+	ifaceStore := interfacestore.NewInterfaceStore()
+	ifaceStore.AddInterface(&interfacestore.InterfaceConfig{
+		Type:                     interfacestore.ContainerInterface,
+		InterfaceName:            "pod1-abcdef",
+		IPs:                      []net.IP{podIP},
+		MAC:                      podMAC,
+		OVSPortConfig:            &interfacestore.OVSPortConfig{OFPort: 3},
+		ContainerInterfaceConfig: &interfacestore.ContainerInterfaceConfig{ContainerID: "abcdef", PodName: "pod1", PodNamespace: "ns1"},
+	})
+	recorder := record.NewFakeRecorder(10)
+	return NewReporter(ifaceStore, recorder, logOnly), recorder
+}
+
+func TestHandlePacketIn(t *testing.T) {
+	tests := []struct {
+		name          string
+		logOnly       bool
+		pktIn         *ofctrl.PacketIn
+		expectedEvent string
+	}{
+		{
+			name:          "IP violation",
+			pktIn:         newPacketIn(3, protocol.IPv4_MSG, podMAC, &protocol.IPv4{NWSrc: otherIP, NWDst: podIP}),
+			expectedEvent: "Warning SpoofGuardViolation Pod sent packet with source MAC aa:bb:cc:dd:ee:01 and source IP 10.10.0.3 which do not match its interface (MAC aa:bb:cc:dd:ee:01, IPs [10.10.0.2]); packet was dropped",
+		},
+		{
+			name:          "MAC violation in logOnly mode",
+			logOnly:       true,
+			pktIn:         newPacketIn(3, protocol.IPv4_MSG, otherMAC, &protocol.IPv4{NWSrc: podIP, NWDst: otherIP}),
+			expectedEvent: "Warning SpoofGuardViolation Pod sent packet with source MAC aa:bb:cc:dd:ee:02 and source IP 10.10.0.2 which do not match its interface (MAC aa:bb:cc:dd:ee:01, IPs [10.10.0.2]); packet was forwarded",
+		},
+		{
+			name:          "ARP violation",
+			pktIn:         newPacketIn(3, protocol.ARP_MSG, podMAC, &protocol.ARP{HWSrc: podMAC, IPSrc: otherIP}),
+			expectedEvent: "Warning SpoofGuardViolation Pod sent packet with source MAC aa:bb:cc:dd:ee:01 and source IP 10.10.0.3 which do not match its interface (MAC aa:bb:cc:dd:ee:01, IPs [10.10.0.2]); packet was dropped",
+		},
+		{
+			name:  "unknown port",
+			pktIn: newPacketIn(4, protocol.IPv4_MSG, podMAC, &protocol.IPv4{NWSrc: otherIP, NWDst: podIP}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter, recorder := newTestReporter(tt.logOnly)
+			require.NoError(t, reporter.HandlePacketIn(tt.pktIn))
+			// The second violation of the same Pod is rate limited.
+			require.NoError(t, reporter.HandlePacketIn(tt.pktIn))
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if tt.expectedEvent == "" {
+				assert.Empty(t, events)
+			} else {
+				assert.Equal(t, []string{tt.expectedEvent}, events)
+			}
+		})
+	}
+}
+
+func TestHandlePacketInWithoutEvents(t *testing.T) {
+	reporter, _ := newTestReporter(false)
+	reporter.recorder = nil
+	pktIn := newPacketIn(3, protocol.IPv4_MSG, podMAC, &protocol.IPv4{NWSrc: otherIP, NWDst: podIP})
+	assert.NoError(t, reporter.HandlePacketIn(pktIn))
+}
+
+func TestHandlePacketInErrors(t *testing.T) {
+	reporter, _ := newTestReporter(false)
+	assert.Error(t, reporter.HandlePacketIn(nil))
+	pktIn := newPacketIn(3, protocol.IPv4_MSG, podMAC, &protocol.IPv4{NWSrc: otherIP, NWDst: podIP})
+	pktIn.Match.Fields = nil
+	assert.Error(t, reporter.HandlePacketIn(pktIn))
+	pktIn = newPacketIn(3, 0x88cc, podMAC, nil)
+	assert.Error(t, reporter.HandlePacketIn(pktIn))
+}
@@ -16,6 +16,7 @@ package stats
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -58,6 +59,8 @@ type Collector struct {
 	// lastStatsCollection is the last statistics that has been reported to antrea-controller successfully.
 	// It is used to calculate the delta of the statistics that will be reported.
 	lastStatsCollection *statsCollection
+	// paused is set to 1 when stats collection is paused, e.g. because the agent is under memory pressure.
+	paused int32
 }
 
 func NewCollector(antreaClientProvider agent.AntreaClientProvider, ofClient openflow.Client, npQuerier querier.AgentNetworkPolicyInfoQuerier) *Collector {
@@ -85,6 +88,11 @@ func (m *Collector) Run(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-ticker.C:
+			if atomic.LoadInt32(&m.paused) == 1 {
+				// The stats produced while paused will be added up in the next report.
+				klog.V(2).Infof("Stats collection is paused, skipping collection")
+				continue
+			}
 			curStatsCollection := m.collect()
 			// Do not update m.lastStatsMap if the report fails so that the next report attempt can add up the
 			// statistics produced in this duration.
@@ -99,6 +107,15 @@ func (m *Collector) Run(stopCh <-chan struct{}) {
 	}
 }
 
+// SetPaused pauses or resumes the periodical collection and report of statistics.
+func (m *Collector) SetPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&m.paused, v)
+}
+
 // collect collects the stats of Openflow rules, maps them to the stats of NetworkPolicies.
 // It returns a map from NetworkPolicyReferences to their stats.
 func (m *Collector) collect() *statsCollection {
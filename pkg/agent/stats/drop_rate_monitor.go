@@ -0,0 +1,99 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
+)
+
+// DropRateMonitor computes the rate of the packets dropped by the NetworkPolicies from the dropped packet counts
+// read periodically from OVS, and notifies the webhooks when it exceeds a threshold. A single event is sent until
+// the rate goes back below the threshold.
+type DropRateMonitor struct {
+	nodeName string
+	notifier *webhook.Notifier
+	// threshold is the rate of dropped packets, in packets per second, above which the webhooks are notified.
+	threshold float64
+	// lastCounts are the dropped packet counts of the previous check, by drop reason.
+	lastCounts map[string]uint64
+	lastTime   time.Time
+	// exceeded contains the drop reasons whose rate exceeded the threshold at the previous check.
+	exceeded map[string]bool
+}
+
+func NewDropRateMonitor(nodeName string, notifier *webhook.Notifier, threshold float64) *DropRateMonitor {
+	return &DropRateMonitor{
+		nodeName:  nodeName,
+		notifier:  notifier,
+		threshold: threshold,
+		exceeded:  map[string]bool{},
+	}
+}
+
+// Check computes the drop rates since the previous check from the dropped packet counts by drop reason, as returned
+// by openflow.Client.DroppedPacketCounts, and notifies the webhooks of the rates which exceed the threshold.
+func (m *DropRateMonitor) Check(counts map[string]uint64, now time.Time) {
+	lastCounts, lastTime := m.lastCounts, m.lastTime
+	m.lastCounts, m.lastTime = counts, now
+	if lastCounts == nil {
+		// The first counts are the base of the rates.
+		return
+	}
+	elapsed := now.Sub(lastTime).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	for _, reason := range openflow.NetworkPolicyDropReasons {
+		count, ok := counts[reason]
+		lastCount, lastOK := lastCounts[reason]
+		// The counters are reset when the flows are reinstalled, e.g. when OVS restarts.
+		if !ok || !lastOK || count < lastCount {
+			continue
+		}
+		rate := float64(count-lastCount) / elapsed
+		if rate <= m.threshold {
+			if m.exceeded[reason] {
+				klog.Infof("Rate of packets dropped for reason %s is back below the threshold: %.2f packets/s", reason, rate)
+				delete(m.exceeded, reason)
+			}
+			continue
+		}
+		if m.exceeded[reason] {
+			continue
+		}
+		m.exceeded[reason] = true
+		message := fmt.Sprintf("Rate of packets dropped by NetworkPolicies on Node %s for reason %s is %.2f packets/s, exceeding the threshold of %.2f packets/s",
+			m.nodeName, reason, rate, m.threshold)
+		klog.Warning(message)
+		m.notifier.Notify(webhook.Event{
+			Type:    webhook.EventPolicyDropRateExceeded,
+			Time:    now,
+			Source:  m.nodeName,
+			Message: message,
+			Details: map[string]string{
+				"node":      m.nodeName,
+				"reason":    reason,
+				"rate":      fmt.Sprintf("%.2f", rate),
+				"threshold": fmt.Sprintf("%.2f", m.threshold),
+			},
+		})
+	}
+}
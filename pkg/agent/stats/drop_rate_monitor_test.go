@@ -0,0 +1,58 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropRateMonitor(t *testing.T) {
+	m := NewDropRateMonitor("node1", nil, 10)
+	now := time.Now()
+	check := func(elapsed time.Duration, egressDrops, ingressDrops uint64) {
+		now = now.Add(elapsed)
+		m.Check(map[string]uint64{"egress_default_deny": egressDrops, "ingress_default_deny": ingressDrops, "spoofguard": 100000}, now)
+	}
+
+	check(0, 0, 1000)
+	assert.Empty(t, m.exceeded, "The first counts must only be recorded")
+
+	// 1200 packets/minute is 20 packets/s.
+	check(time.Minute, 1200, 1000)
+	assert.Equal(t, map[string]bool{"egress_default_deny": true}, m.exceeded)
+
+	check(time.Minute, 2400, 1300)
+	assert.Equal(t, map[string]bool{"egress_default_deny": true}, m.exceeded)
+
+	check(time.Minute, 2500, 2600)
+	assert.Equal(t, map[string]bool{"ingress_default_deny": true}, m.exceeded)
+
+	// The counters are reset.
+	check(time.Minute, 0, 0)
+	assert.Equal(t, map[string]bool{"ingress_default_deny": true}, m.exceeded)
+	check(time.Minute, 0, 0)
+	assert.Empty(t, m.exceeded)
+}
+
+func TestDropRateMonitorAntreaPolicy(t *testing.T) {
+	m := NewDropRateMonitor("node1", nil, 10)
+	now := time.Now()
+	m.Check(map[string]uint64{"egress_antrea_policy": 0, "ingress_antrea_policy": 0}, now)
+	m.Check(map[string]uint64{"egress_antrea_policy": 100, "ingress_antrea_policy": 1200}, now.Add(time.Minute))
+	assert.Equal(t, map[string]bool{"ingress_antrea_policy": true}, m.exceeded)
+}
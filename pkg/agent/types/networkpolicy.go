@@ -81,6 +81,8 @@ type PolicyRule struct {
 	TableID       binding.TableIDType
 	PolicyRef     *v1beta2.NetworkPolicyReference
 	EnableLogging bool
+	// ConnectionStates restricts the rule to packets of connections in these states. Empty matches any state.
+	ConnectionStates []secv1alpha1.ConnectionState
 }
 
 // IsAntreaNetworkPolicyRule returns if a PolicyRule is created for Antrea NetworkPolicy types.
@@ -154,4 +156,7 @@ type EntityReference struct {
 	Pod *v1beta2.PodReference
 	// ExternalEntity maintains the reference to the ExternalEntity.
 	ExternalEntity *v1beta2.ExternalEntityReference
+	// Realized, if not nil, is closed by the receiver once all the NetworkPolicy rules applied to the entity have
+	// been realized, including the rules updated while the sender is waiting.
+	Realized chan<- struct{}
 }
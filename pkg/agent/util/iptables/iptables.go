@@ -36,9 +36,12 @@ const (
 
 	AcceptTarget     = "ACCEPT"
 	MasqueradeTarget = "MASQUERADE"
+	SNATTarget       = "SNAT"
+	DNATTarget       = "DNAT"
 	MarkTarget       = "MARK"
 	ConnTrackTarget  = "CT"
 	NoTrackTarget    = "NOTRACK"
+	DropTarget       = "DROP"
 
 	PreRoutingChain  = "PREROUTING"
 	ForwardChain     = "FORWARD"
@@ -122,6 +125,19 @@ func (c *Client) ChainExists(table string, chain string) (bool, error) {
 	return false, nil
 }
 
+// ListChains lists all chains of a table.
+func (c *Client) ListChains(table string) ([]string, error) {
+	var allChains []string
+	for idx := range c.ipts {
+		chains, err := c.ipts[idx].ListChains(table)
+		if err != nil {
+			return allChains, fmt.Errorf("error listing existing chains in table %s: %v", table, err)
+		}
+		allChains = append(allChains, chains...)
+	}
+	return allChains, nil
+}
+
 // EnsureRule checks if target rule already exists, appends it if not.
 func (c *Client) EnsureRule(table string, chain string, ruleSpec []string) error {
 	for idx := range c.ipts {
@@ -141,6 +157,25 @@ func (c *Client) EnsureRule(table string, chain string, ruleSpec []string) error
 	return nil
 }
 
+// InsertRule checks if target rule already exists, inserts it at the beginning of the chain if not.
+func (c *Client) InsertRule(table string, chain string, ruleSpec []string) error {
+	for idx := range c.ipts {
+		ipt := c.ipts[idx]
+		exist, err := ipt.Exists(table, chain, ruleSpec...)
+		if err != nil {
+			return fmt.Errorf("error checking if rule %v exists in table %s chain %s: %v", ruleSpec, table, chain, err)
+		}
+		if exist {
+			continue
+		}
+		if err := ipt.Insert(table, chain, 1, ruleSpec...); err != nil {
+			return fmt.Errorf("error inserting rule %v to table %s chain %s: %v", ruleSpec, table, chain, err)
+		}
+		klog.V(2).Infof("Inserted rule %v to table %s chain %s", ruleSpec, table, chain)
+	}
+	return nil
+}
+
 // DeleteRule checks if target rule already exists, deletes the rule if found.
 func (c *Client) DeleteRule(table string, chain string, ruleSpec []string) error {
 	for idx := range c.ipts {
@@ -0,0 +1,235 @@
+// +build !windows
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iptables
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/klog"
+)
+
+// Mode is the backend of the iptables binaries. Starting with iptables 1.8, the binaries can either program the
+// legacy xtables kernel API or nftables. Rules installed with one backend are not visible to the other one, so the
+// Antrea Agent must use the same backend as the other components managing iptables on the host, e.g. kubelet and
+// kube-proxy.
+type Mode string
+
+const (
+	LegacyMode Mode = "legacy"
+	NFTMode    Mode = "nft"
+)
+
+// antreaChainPrefix is the prefix of the names of the chains created by Antrea.
+const antreaChainPrefix = "ANTREA-"
+
+// commandRunner runs the iptables and update-alternatives commands.
+type commandRunner interface {
+	// LookPath searches for an executable in the directories named by the PATH environment variable.
+	LookPath(file string) (string, error)
+	// Run runs the command with the provided standard input, and returns its standard output. The standard error is
+	// included in the returned error if the command fails.
+	Run(stdin []byte, name string, args ...string) ([]byte, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+func (execRunner) Run(stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, fmt.Errorf("%v, stderr: %s", err, stderr.String())
+	}
+	return output, nil
+}
+
+// runner runs the commands of this file. It is a variable so that it can be replaced in tests.
+var runner commandRunner = execRunner{}
+
+// kubeletHintChains are the chains created by kubelet (since K8s v1.17) in the mangle table, which indicate the
+// backend used by the host regardless of the number of rules.
+var kubeletHintChains = []string{"KUBE-IPTABLES-HINT", "KUBE-KUBELET-CANARY"}
+
+// DetectMode detects the backend used on the host. The nftables backend is selected if kubelet created its hint chains
+// with it, otherwise the backend with the most rules wins. An empty Mode is returned if only one backend is available
+// in the image, i.e. the iptables version is older than 1.8.
+func DetectMode() Mode {
+	if _, err := runner.LookPath("iptables-nft-save"); err != nil {
+		return ""
+	}
+	if _, err := runner.LookPath("iptables-legacy-save"); err != nil {
+		return ""
+	}
+	nftOutput := save(NFTMode)
+	if hasChain(nftOutput, kubeletHintChains...) {
+		return NFTMode
+	}
+	legacyOutput := save(LegacyMode)
+	if countRules(legacyOutput) > countRules(nftOutput) {
+		return LegacyMode
+	}
+	return NFTMode
+}
+
+// SetMode makes the "iptables" and "ip6tables" commands, and their save / restore variants, use the provided backend,
+// with update-alternatives as it is done by the Debian iptables package.
+func SetMode(mode Mode) error {
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		path, err := runner.LookPath(fmt.Sprintf("%s-%s", cmd, mode))
+		if err != nil {
+			return fmt.Errorf("error looking for %s binary with backend %s: %v", cmd, mode, err)
+		}
+		if output, err := runner.Run(nil, "update-alternatives", "--set", cmd, path); err != nil {
+			return fmt.Errorf("error setting backend %s for %s: %v, output: %s", mode, cmd, err, output)
+		}
+	}
+	klog.Infof("Using iptables backend %s", mode)
+	return nil
+}
+
+// EnsureMode detects the backend used on the host and makes the iptables binaries use it. The Antrea rules left in the
+// other backend, e.g. by a previous Antrea Agent which selected it, are removed, as they would still be applied to the
+// traffic. It does nothing if only one backend is available.
+func EnsureMode() error {
+	mode := DetectMode()
+	if mode == "" {
+		klog.V(2).Info("Only one iptables backend is available, skipping iptables backend detection")
+		return nil
+	}
+	if err := SetMode(mode); err != nil {
+		return err
+	}
+	otherMode := LegacyMode
+	if mode == LegacyMode {
+		otherMode = NFTMode
+	}
+	return cleanupMode(otherMode)
+}
+
+// cleanupMode removes the Antrea chains, and the rules jumping to them, from the provided backend.
+func cleanupMode(mode Mode) error {
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		output, err := runner.Run(nil, fmt.Sprintf("%s-%s-save", cmd, mode))
+		if err != nil {
+			klog.V(2).Infof("Error executing %s-%s-save: %v", cmd, mode, err)
+			continue
+		}
+		data := cleanupData(output)
+		if data == nil {
+			continue
+		}
+		restoreCmd := fmt.Sprintf("%s-%s-restore", cmd, mode)
+		if _, err := runner.Run(data, restoreCmd, "--noflush"); err != nil {
+			return fmt.Errorf("error removing the Antrea rules with %s: %v", restoreCmd, err)
+		}
+		klog.Infof("Removed the Antrea rules left in the %s backend of %s", mode, cmd)
+	}
+	return nil
+}
+
+// cleanupData returns the iptables-restore input which removes the Antrea chains declared in the provided output of
+// iptables-save, and the rules jumping to them from the other chains. It returns nil if there is no Antrea chain.
+// The Antrea chains are declared first, which flushes them when the input is restored with --noflush, so that they
+// can be deleted once the rules jumping to them are deleted.
+func cleanupData(output []byte) []byte {
+	var data bytes.Buffer
+	var table string
+	var chains, rules []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "*"):
+			table = line
+			chains, rules = nil, nil
+		case strings.HasPrefix(line, ":"+antreaChainPrefix):
+			chains = append(chains, strings.Fields(line[1:])[0])
+		case strings.HasPrefix(line, "-A ") && !strings.HasPrefix(line, "-A "+antreaChainPrefix):
+			if strings.Contains(line, " -j "+antreaChainPrefix) || strings.Contains(line, " -g "+antreaChainPrefix) {
+				rules = append(rules, "-D "+line[len("-A "):])
+			}
+		case line == "COMMIT" && len(chains) > 0:
+			data.WriteString(table + "\n")
+			for _, chain := range chains {
+				data.WriteString(":" + chain + " - [0:0]\n")
+			}
+			for _, rule := range rules {
+				data.WriteString(rule + "\n")
+			}
+			for _, chain := range chains {
+				data.WriteString("-X " + chain + "\n")
+			}
+			data.WriteString("COMMIT\n")
+		}
+	}
+	if data.Len() == 0 {
+		return nil
+	}
+	return data.Bytes()
+}
+
+// save returns the combined output of the IPv4 and IPv6 save commands of the provided backend. Errors are ignored as
+// some tables or protocols may not be available on the host.
+func save(mode Mode) []byte {
+	var output []byte
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		data, err := runner.Run(nil, fmt.Sprintf("%s-%s-save", cmd, mode))
+		if err != nil {
+			klog.V(2).Infof("Error executing %s-%s-save: %v", cmd, mode, err)
+		}
+		output = append(output, data...)
+	}
+	return output
+}
+
+// countRules returns the number of rules in the output of iptables-save.
+func countRules(output []byte) int {
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "-") {
+			count++
+		}
+	}
+	return count
+}
+
+// hasChain returns whether any of the provided chains is declared in the output of iptables-save.
+func hasChain(output []byte, chains ...string) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, chain := range chains {
+			if strings.HasPrefix(line, ":"+chain+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
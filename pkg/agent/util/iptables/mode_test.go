@@ -0,0 +1,218 @@
+// +build !windows
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iptables
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const saveOutput = `# Generated by iptables-nft-save v1.8.4 on Thu Jan 14 08:10:02 2021
+*mangle
+:PREROUTING ACCEPT [0:0]
+:KUBE-KUBELET-CANARY - [0:0]
+COMMIT
+*nat
+:PREROUTING ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+:KUBE-POSTROUTING - [0:0]
+-A POSTROUTING -m comment --comment "kubernetes postrouting rules" -j KUBE-POSTROUTING
+-A KUBE-POSTROUTING -m mark ! --mark 0x4000/0x4000 -j RETURN
+COMMIT
+`
+
+func TestCountRules(t *testing.T) {
+	assert.Equal(t, 2, countRules([]byte(saveOutput)))
+	assert.Equal(t, 0, countRules(nil))
+}
+
+func TestHasChain(t *testing.T) {
+	assert.True(t, hasChain([]byte(saveOutput), kubeletHintChains...))
+	assert.True(t, hasChain([]byte(saveOutput), "KUBE-POSTROUTING"))
+	assert.False(t, hasChain([]byte(saveOutput), "KUBE"))
+	assert.False(t, hasChain([]byte(saveOutput), "ANTREA-POSTROUTING"))
+}
+
+const legacySaveOutput = `# Generated by iptables-save v1.8.4 on Thu Jan 14 08:10:02 2021
+*nat
+:PREROUTING ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+:KUBE-SERVICES - [0:0]
+:KUBE-POSTROUTING - [0:0]
+-A PREROUTING -m comment --comment "kubernetes service portals" -j KUBE-SERVICES
+-A POSTROUTING -m comment --comment "kubernetes postrouting rules" -j KUBE-POSTROUTING
+-A KUBE-POSTROUTING -m mark ! --mark 0x4000/0x4000 -j RETURN
+COMMIT
+`
+
+const antreaSaveOutput = `# Generated by iptables-nft-save v1.8.4 on Thu Jan 14 08:10:02 2021
+*raw
+:PREROUTING ACCEPT [0:0]
+:ANTREA-PREROUTING - [0:0]
+-A PREROUTING -m comment --comment "Antrea: jump to Antrea prerouting rules" -j ANTREA-PREROUTING
+-A ANTREA-PREROUTING -i antrea-gw0 -j NOTRACK
+COMMIT
+*filter
+:FORWARD ACCEPT [0:0]
+-A FORWARD -j ACCEPT
+COMMIT
+*nat
+:POSTROUTING ACCEPT [0:0]
+:ANTREA-POSTROUTING - [0:0]
+:ANTREA-NODE-PORT-LOCAL - [0:0]
+-A POSTROUTING -m comment --comment "Antrea: jump to Antrea postrouting rules" -j ANTREA-POSTROUTING
+-A POSTROUTING -g ANTREA-NODE-PORT-LOCAL
+-A ANTREA-POSTROUTING -s 10.10.0.0/24 -j MASQUERADE
+COMMIT
+`
+
+// fakeRunner is a commandRunner which returns the configured outputs of the save commands, and records the other
+// commands it runs.
+type fakeRunner struct {
+	binaries []string
+	outputs  map[string]string
+	commands []string
+}
+
+func (r *fakeRunner) LookPath(file string) (string, error) {
+	for _, binary := range r.binaries {
+		if binary == file {
+			return "/usr/sbin/" + file, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found", file)
+}
+
+func (r *fakeRunner) Run(stdin []byte, name string, args ...string) ([]byte, error) {
+	if strings.HasSuffix(name, "-save") {
+		output, ok := r.outputs[name]
+		if !ok {
+			return nil, fmt.Errorf("%s failed", name)
+		}
+		return []byte(output), nil
+	}
+	command := strings.Join(append([]string{name}, args...), " ")
+	if stdin != nil {
+		command += "\n" + string(stdin)
+	}
+	r.commands = append(r.commands, command)
+	return nil, nil
+}
+
+func setFakeRunner(r *fakeRunner) func() {
+	prevRunner := runner
+	runner = r
+	return func() { runner = prevRunner }
+}
+
+func TestDetectMode(t *testing.T) {
+	allBinaries := []string{"iptables-nft-save", "iptables-legacy-save"}
+	tests := []struct {
+		name         string
+		binaries     []string
+		outputs      map[string]string
+		expectedMode Mode
+	}{
+		{
+			name:         "single backend",
+			binaries:     []string{"iptables-legacy-save"},
+			outputs:      map[string]string{"iptables-legacy-save": legacySaveOutput},
+			expectedMode: "",
+		},
+		{
+			name:     "kubelet hint chains",
+			binaries: allBinaries,
+			outputs: map[string]string{
+				"iptables-nft-save":    saveOutput,
+				"iptables-legacy-save": legacySaveOutput,
+			},
+			expectedMode: NFTMode,
+		},
+		{
+			name:     "most rules in legacy",
+			binaries: allBinaries,
+			outputs: map[string]string{
+				"iptables-nft-save":    "*filter\n:FORWARD ACCEPT [0:0]\n-A FORWARD -j ACCEPT\nCOMMIT\n",
+				"iptables-legacy-save": legacySaveOutput,
+			},
+			expectedMode: LegacyMode,
+		},
+		{
+			name:     "IPv6 rules counted",
+			binaries: allBinaries,
+			outputs: map[string]string{
+				"iptables-nft-save":     "*filter\n:FORWARD ACCEPT [0:0]\n-A FORWARD -j ACCEPT\nCOMMIT\n",
+				"ip6tables-nft-save":    legacySaveOutput,
+				"iptables-legacy-save":  legacySaveOutput,
+				"ip6tables-legacy-save": "",
+			},
+			expectedMode: NFTMode,
+		},
+		{
+			name:         "no rules",
+			binaries:     allBinaries,
+			outputs:      map[string]string{},
+			expectedMode: NFTMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setFakeRunner(&fakeRunner{binaries: tt.binaries, outputs: tt.outputs})()
+			assert.Equal(t, tt.expectedMode, DetectMode())
+		})
+	}
+}
+
+func TestCleanupData(t *testing.T) {
+	assert.Nil(t, cleanupData([]byte(legacySaveOutput)))
+	expected := `*raw
+:ANTREA-PREROUTING - [0:0]
+-D PREROUTING -m comment --comment "Antrea: jump to Antrea prerouting rules" -j ANTREA-PREROUTING
+-X ANTREA-PREROUTING
+COMMIT
+*nat
+:ANTREA-POSTROUTING - [0:0]
+:ANTREA-NODE-PORT-LOCAL - [0:0]
+-D POSTROUTING -m comment --comment "Antrea: jump to Antrea postrouting rules" -j ANTREA-POSTROUTING
+-D POSTROUTING -g ANTREA-NODE-PORT-LOCAL
+-X ANTREA-POSTROUTING
+-X ANTREA-NODE-PORT-LOCAL
+COMMIT
+`
+	assert.Equal(t, expected, string(cleanupData([]byte(antreaSaveOutput))))
+}
+
+func TestEnsureMode(t *testing.T) {
+	r := &fakeRunner{
+		binaries: []string{"iptables-nft-save", "iptables-legacy-save", "iptables-nft", "ip6tables-nft"},
+		outputs: map[string]string{
+			"iptables-nft-save":    saveOutput,
+			"iptables-legacy-save": antreaSaveOutput,
+		},
+	}
+	defer setFakeRunner(r)()
+	require.NoError(t, EnsureMode())
+	require.Len(t, r.commands, 3)
+	assert.Equal(t, "update-alternatives --set iptables /usr/sbin/iptables-nft", r.commands[0])
+	assert.Equal(t, "update-alternatives --set ip6tables /usr/sbin/ip6tables-nft", r.commands[1])
+	// The Antrea rules left in the legacy backend are removed.
+	assert.Equal(t, "iptables-legacy-restore --noflush\n"+string(cleanupData([]byte(antreaSaveOutput))), r.commands[2])
+}
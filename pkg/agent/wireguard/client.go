@@ -0,0 +1,119 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wireguard manages the WireGuard interface used to encrypt the Pod
+// traffic between Nodes when the wireGuard trafficEncryptionMode is used.
+package wireguard
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+	// PublicKeyAnnotationKey is the key of the Node annotation which
+	// advertises the WireGuard public key of the Node to the other Nodes.
+	PublicKeyAnnotationKey = "node.antrea.io/wireguard-public-key"
+	// DefaultLinkName is the name of the WireGuard interface created by
+	// antrea-agent.
+	DefaultLinkName = "antrea-wg0"
+	// DefaultPort is the default UDP port on which WireGuard listens.
+	DefaultPort = 51820
+
+	keyLen = 32
+)
+
+// Interface is the interface of the WireGuard client, which configures the
+// WireGuard interface of the Node and its peers, i.e. the other Nodes.
+type Interface interface {
+	// Init creates the WireGuard interface if it doesn't exist, configures
+	// it and advertises its public key through the Node annotation. The
+	// private key of an existing interface is kept, so that the public key
+	// does not change when the agent restarts. The link index of the
+	// interface is set in the WireGuardConfig.
+	Init() error
+
+	// UpdatePeer adds or updates the WireGuard peer for the provided Node,
+	// which accepts the traffic for the provided Pod CIDRs. Calls to
+	// UpdatePeer are idempotent.
+	UpdatePeer(nodeName string, publicKey string, peerNodeIP net.IP, podCIDRs []*net.IPNet) error
+
+	// DeletePeer deletes the WireGuard peer for the provided Node.
+	DeletePeer(nodeName string) error
+
+	// RemoveStalePeers removes the WireGuard peers whose public key is not
+	// the public key of one of the provided Nodes, e.g. the peers of the
+	// Nodes deleted while the agent was not running.
+	RemoveStalePeers(publicKeys map[string]string) error
+
+	// GetPeerStatus returns the status of the WireGuard peers configured
+	// on the WireGuard interface.
+	GetPeerStatus() ([]PeerStatus, error)
+}
+
+// PeerStatus is the status of the WireGuard peer of a Node.
+type PeerStatus struct {
+	// NodeName is empty if the peer is not configured by the agent, e.g. a
+	// stale peer which has not been removed yet.
+	NodeName  string
+	PublicKey string
+	Endpoint  string
+	// LatestHandshake is the time of the latest handshake with the peer,
+	// i.e. the time at which the session keys were last rotated. It is zero
+	// if no handshake has completed yet.
+	LatestHandshake time.Time
+	// TransferRx and TransferTx are the numbers of bytes received from and
+	// sent to the peer.
+	TransferRx int64
+	TransferTx int64
+}
+
+// generatePrivateKey returns a new Curve25519 private key.
+func generatePrivateKey() ([]byte, error) {
+	key := make([]byte, keyLen)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	// Clamp the key as described in https://cr.yp.to/ecdh.html.
+	key[0] &= 248
+	key[31] = (key[31] & 127) | 64
+	return key, nil
+}
+
+// getPublicKey returns the public key corresponding to the private key.
+func getPublicKey(privateKey []byte) ([]byte, error) {
+	return curve25519.X25519(privateKey, curve25519.Basepoint)
+}
+
+// parseKey parses a base64 encoded key, which is the format used by the wg
+// command and by the public key annotation.
+func parseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WireGuard key: %v", err)
+	}
+	if len(key) != keyLen {
+		return nil, fmt.Errorf("invalid WireGuard key length %d", len(key))
+	}
+	return key, nil
+}
+
+func formatKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
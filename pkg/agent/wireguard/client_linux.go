@@ -0,0 +1,289 @@
+// +build linux
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+)
+
+// peerConfig is the configuration of the WireGuard peer of a Node.
+type peerConfig struct {
+	publicKey  string
+	endpoint   string
+	allowedIPs string
+}
+
+type client struct {
+	kubeClient      clientset.Interface
+	nodeName        string
+	wireGuardConfig *config.WireGuardConfig
+	// runWG runs the wg command with the provided arguments and standard
+	// input, and returns its output.
+	runWG func(stdin string, args ...string) (string, error)
+
+	mutex sync.Mutex
+	// peers maps the names of the Nodes to the configuration of their peers.
+	peers map[string]*peerConfig
+}
+
+// NewClient returns a WireGuard client for the provided WireGuardConfig. The
+// wg command must be installed.
+func NewClient(kubeClient clientset.Interface, nodeName string, wireGuardConfig *config.WireGuardConfig) (Interface, error) {
+	if _, err := exec.LookPath("wg"); err != nil {
+		return nil, fmt.Errorf("wg command not found: %v", err)
+	}
+	return &client{
+		kubeClient:      kubeClient,
+		nodeName:        nodeName,
+		wireGuardConfig: wireGuardConfig,
+		runWG:           runWG,
+		peers:           make(map[string]*peerConfig),
+	}, nil
+}
+
+func runWG(stdin string, args ...string) (string, error) {
+	cmd := exec.Command("wg", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running wg %s: %v, output: %s", strings.Join(args, " "), err, output)
+	}
+	return string(output), nil
+}
+
+func (c *client) Init() error {
+	name := c.wireGuardConfig.Name
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); !ok {
+			return fmt.Errorf("failed to get WireGuard interface %s: %v", name, err)
+		}
+		klog.Infof("Creating WireGuard interface %s", name)
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = name
+		attrs.MTU = c.wireGuardConfig.MTU
+		if err := netlink.LinkAdd(&netlink.GenericLink{LinkAttrs: attrs, LinkType: "wireguard"}); err != nil {
+			return fmt.Errorf("failed to create WireGuard interface %s: %v", name, err)
+		}
+		if link, err = netlink.LinkByName(name); err != nil {
+			return fmt.Errorf("failed to get WireGuard interface %s: %v", name, err)
+		}
+	}
+	if link.Attrs().MTU != c.wireGuardConfig.MTU {
+		if err := netlink.LinkSetMTU(link, c.wireGuardConfig.MTU); err != nil {
+			return fmt.Errorf("failed to set MTU of WireGuard interface %s: %v", name, err)
+		}
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("failed to set WireGuard interface %s up: %v", name, err)
+	}
+	c.wireGuardConfig.LinkIndex = link.Attrs().Index
+
+	publicKey, err := c.configureDevice()
+	if err != nil {
+		return err
+	}
+	return c.advertisePublicKey(publicKey)
+}
+
+// configureDevice sets the listening port and the private key of the
+// WireGuard interface, and returns its public key.
+func (c *client) configureDevice() (string, error) {
+	name := c.wireGuardConfig.Name
+	output, err := c.runWG("", "show", name, "private-key")
+	if err != nil {
+		return "", err
+	}
+	// The output is "(none)" if the private key of the interface is not set.
+	privateKey, err := parseKey(strings.TrimSpace(output))
+	if err != nil {
+		klog.Infof("Generating private key for WireGuard interface %s", name)
+		if privateKey, err = generatePrivateKey(); err != nil {
+			return "", fmt.Errorf("failed to generate WireGuard private key: %v", err)
+		}
+	}
+	// The private key is passed through the standard input so that it
+	// never appears in the command line.
+	if _, err := c.runWG(formatKey(privateKey), "set", name, "listen-port", strconv.Itoa(c.wireGuardConfig.Port), "private-key", "/dev/stdin"); err != nil {
+		return "", err
+	}
+	publicKey, err := getPublicKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute WireGuard public key: %v", err)
+	}
+	return formatKey(publicKey), nil
+}
+
+// advertisePublicKey updates the WireGuard public key annotation of the Node.
+func (c *client) advertisePublicKey(publicKey string) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, PublicKeyAnnotationKey, publicKey)
+	if _, err := c.kubeClient.CoreV1().Nodes().Patch(context.TODO(), c.nodeName, apitypes.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to update WireGuard public key annotation of Node %s: %v", c.nodeName, err)
+	}
+	klog.Infof("Updated WireGuard public key of Node %s to %s", c.nodeName, publicKey)
+	return nil
+}
+
+func (c *client) UpdatePeer(nodeName string, publicKey string, peerNodeIP net.IP, podCIDRs []*net.IPNet) error {
+	if _, err := parseKey(publicKey); err != nil {
+		return fmt.Errorf("invalid WireGuard public key for Node %s: %v", nodeName, err)
+	}
+	allowedIPs := make([]string, 0, len(podCIDRs))
+	for _, podCIDR := range podCIDRs {
+		allowedIPs = append(allowedIPs, podCIDR.String())
+	}
+	// All the Nodes use the same WireGuard port.
+	peer := &peerConfig{
+		publicKey:  publicKey,
+		endpoint:   net.JoinHostPort(peerNodeIP.String(), strconv.Itoa(c.wireGuardConfig.Port)),
+		allowedIPs: strings.Join(allowedIPs, ","),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if current, ok := c.peers[nodeName]; ok {
+		if *current == *peer {
+			return nil
+		}
+		if current.publicKey != publicKey {
+			// The public key of the Node changes when its WireGuard interface is
+			// re-created, e.g. after a reboot.
+			if err := c.removePeer(current.publicKey); err != nil {
+				return err
+			}
+			delete(c.peers, nodeName)
+		}
+	}
+	if _, err := c.runWG("", "set", c.wireGuardConfig.Name, "peer", peer.publicKey, "endpoint", peer.endpoint, "allowed-ips", peer.allowedIPs); err != nil {
+		return fmt.Errorf("failed to configure WireGuard peer for Node %s: %v", nodeName, err)
+	}
+	c.peers[nodeName] = peer
+	return nil
+}
+
+func (c *client) DeletePeer(nodeName string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	peer, ok := c.peers[nodeName]
+	if !ok {
+		return nil
+	}
+	if err := c.removePeer(peer.publicKey); err != nil {
+		return err
+	}
+	delete(c.peers, nodeName)
+	return nil
+}
+
+func (c *client) RemoveStalePeers(publicKeys map[string]string) error {
+	desiredKeys := make(map[string]bool, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		desiredKeys[publicKey] = true
+	}
+	output, err := c.runWG("", "show", c.wireGuardConfig.Name, "peers")
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, publicKey := range strings.Fields(output) {
+		if desiredKeys[publicKey] {
+			continue
+		}
+		klog.Infof("Removing stale WireGuard peer %s", publicKey)
+		if err := c.removePeer(publicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) GetPeerStatus() ([]PeerStatus, error) {
+	output, err := c.runWG("", "show", c.wireGuardConfig.Name, "dump")
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	nodeNames := make(map[string]string, len(c.peers))
+	for nodeName, peer := range c.peers {
+		nodeNames[peer.publicKey] = nodeName
+	}
+	c.mutex.Unlock()
+	return parsePeerStatus(output, nodeNames)
+}
+
+// parsePeerStatus parses the output of "wg show <interface> dump". The first
+// line describes the interface, and each following line describes a peer with
+// the tab separated fields: public-key, preshared-key, endpoint, allowed-ips,
+// latest-handshake, transfer-rx, transfer-tx and persistent-keepalive.
+func parsePeerStatus(output string, nodeNames map[string]string) ([]PeerStatus, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var peers []PeerStatus
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("unexpected WireGuard peer dump: %s", line)
+		}
+		handshake, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WireGuard latest handshake %s: %v", fields[4], err)
+		}
+		rx, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WireGuard transfer-rx %s: %v", fields[5], err)
+		}
+		tx, err := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WireGuard transfer-tx %s: %v", fields[6], err)
+		}
+		peer := PeerStatus{
+			NodeName:   nodeNames[fields[0]],
+			PublicKey:  fields[0],
+			Endpoint:   fields[2],
+			TransferRx: rx,
+			TransferTx: tx,
+		}
+		if handshake > 0 {
+			peer.LatestHandshake = time.Unix(handshake, 0)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+func (c *client) removePeer(publicKey string) error {
+	if _, err := c.runWG("", "set", c.wireGuardConfig.Name, "peer", publicKey, "remove"); err != nil {
+		return fmt.Errorf("failed to remove WireGuard peer %s: %v", publicKey, err)
+	}
+	return nil
+}
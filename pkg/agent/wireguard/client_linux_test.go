@@ -0,0 +1,157 @@
+// +build linux
+
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+)
+
+// fakeWG records the wg commands and returns the configured outputs.
+type fakeWG struct {
+	commands []string
+	outputs  map[string]string
+}
+
+func (f *fakeWG) run(stdin string, args ...string) (string, error) {
+	command := strings.Join(args, " ")
+	f.commands = append(f.commands, command)
+	return f.outputs[command], nil
+}
+
+func newTestClient(outputs map[string]string) (*client, *fakeWG) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	wg := &fakeWG{outputs: outputs}
+	return &client{
+		kubeClient:      fake.NewSimpleClientset(node),
+		nodeName:        "node1",
+		wireGuardConfig: &config.WireGuardConfig{Name: DefaultLinkName, Port: DefaultPort, MTU: 1420},
+		runWG:           wg.run,
+		peers:           make(map[string]*peerConfig),
+	}, wg
+}
+
+func TestKeys(t *testing.T) {
+	privateKey, err := generatePrivateKey()
+	require.NoError(t, err)
+	assert.Len(t, privateKey, keyLen)
+	publicKey, err := getPublicKey(privateKey)
+	require.NoError(t, err)
+	parsed, err := parseKey(formatKey(publicKey))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, parsed)
+
+	_, err = parseKey("(none)")
+	assert.Error(t, err)
+	_, err = parseKey("YWJj")
+	assert.Error(t, err)
+}
+
+func TestConfigureDevice(t *testing.T) {
+	// Private and public keys from the wg(8) man page.
+	privateKey := "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+	expectedPublicKey := "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+
+	c, wg := newTestClient(map[string]string{"show antrea-wg0 private-key": privateKey + "\n"})
+	publicKey, err := c.configureDevice()
+	require.NoError(t, err)
+	assert.Equal(t, expectedPublicKey, publicKey)
+	assert.Equal(t, []string{
+		"show antrea-wg0 private-key",
+		"set antrea-wg0 listen-port 51820 private-key /dev/stdin",
+	}, wg.commands)
+
+	require.NoError(t, c.advertisePublicKey(publicKey))
+	node, err := c.kubeClient.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, expectedPublicKey, node.Annotations[PublicKeyAnnotationKey])
+
+	// A new private key is generated if the interface has none.
+	c, _ = newTestClient(map[string]string{"show antrea-wg0 private-key": "(none)\n"})
+	publicKey, err = c.configureDevice()
+	require.NoError(t, err)
+	assert.NotEqual(t, expectedPublicKey, publicKey)
+}
+
+func TestPeers(t *testing.T) {
+	key1 := "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+	key2 := "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	nodeIP := net.ParseIP("192.168.0.2")
+
+	c, wg := newTestClient(map[string]string{"show antrea-wg0 peers": key1 + "\n" + key2 + "\n"})
+	require.NoError(t, c.UpdatePeer("node2", key1, nodeIP, []*net.IPNet{podCIDR}))
+	// The peer is not configured again if it has not changed.
+	require.NoError(t, c.UpdatePeer("node2", key1, nodeIP, []*net.IPNet{podCIDR}))
+	// The previous peer is removed if the public key of the Node has changed.
+	require.NoError(t, c.UpdatePeer("node2", key2, nodeIP, []*net.IPNet{podCIDR}))
+	assert.Error(t, c.UpdatePeer("node3", "invalid", nodeIP, []*net.IPNet{podCIDR}))
+	require.NoError(t, c.RemoveStalePeers(map[string]string{"node2": key2}))
+	require.NoError(t, c.DeletePeer("node2"))
+	require.NoError(t, c.DeletePeer("node2"))
+	assert.Equal(t, []string{
+		"set antrea-wg0 peer " + key1 + " endpoint 192.168.0.2:51820 allowed-ips 10.10.1.0/24",
+		"set antrea-wg0 peer " + key1 + " remove",
+		"set antrea-wg0 peer " + key2 + " endpoint 192.168.0.2:51820 allowed-ips 10.10.1.0/24",
+		"show antrea-wg0 peers",
+		"set antrea-wg0 peer " + key1 + " remove",
+		"set antrea-wg0 peer " + key2 + " remove",
+	}, wg.commands)
+}
+
+func TestGetPeerStatus(t *testing.T) {
+	key1 := "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+	key2 := "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="
+	_, podCIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	dump := "privatekey\tpublickey\t51820\toff\n" +
+		key1 + "\t(none)\t192.168.0.2:51820\t10.10.1.0/24\t1617000000\t1024\t2048\toff\n" +
+		key2 + "\t(none)\t(none)\t10.10.2.0/24\t0\t0\t0\toff\n"
+
+	c, _ := newTestClient(map[string]string{"show antrea-wg0 dump": dump})
+	require.NoError(t, c.UpdatePeer("node2", key1, net.ParseIP("192.168.0.2"), []*net.IPNet{podCIDR}))
+	peers, err := c.GetPeerStatus()
+	require.NoError(t, err)
+	assert.Equal(t, []PeerStatus{
+		{
+			NodeName:        "node2",
+			PublicKey:       key1,
+			Endpoint:        "192.168.0.2:51820",
+			LatestHandshake: time.Unix(1617000000, 0),
+			TransferRx:      1024,
+			TransferTx:      2048,
+		},
+		{
+			PublicKey: key2,
+			Endpoint:  "(none)",
+		},
+	}, peers)
+
+	c, _ = newTestClient(map[string]string{"show antrea-wg0 dump": "privatekey\tpublickey\t51820\toff\ninvalid\n"})
+	_, err = c.GetPeerStatus()
+	assert.Error(t, err)
+}
@@ -19,7 +19,13 @@ import (
 	"reflect"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/agentinfo"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/cniconflict"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/conntrack"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/drain"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/encryption"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsdatapathflows"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsflows"
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsflowstats"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovstracing"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/podinterface"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
@@ -279,6 +285,31 @@ var CommandList = &commandList{
 			commandGroup:        get,
 			transformedResponse: reflect.TypeOf(podinterface.Response{}),
 		},
+		{
+			use:     "encryptionstatus",
+			aliases: []string{"es"},
+			short:   "Print the traffic encryption status of the peer Nodes",
+			long:    "Print the status of the IPsec or WireGuard encryption of the traffic between the local Node and the specified peer Node, or all the peer Nodes.",
+			example: `  Get the traffic encryption status of all the peer Nodes
+  $ antctl get encryptionstatus
+  Get the traffic encryption status of a peer Node
+  $ antctl get encryptionstatus node2`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/encryptionstatus",
+					params: []flagInfo{
+						{
+							name:  "name",
+							usage: "Retrieve the traffic encryption status of a peer Node by name.",
+							arg:   true,
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			commandGroup:        get,
+			transformedResponse: reflect.TypeOf(encryption.Response{}),
+		},
 		{
 			use:     "ovsflows",
 			aliases: []string{"of"},
@@ -324,6 +355,88 @@ var CommandList = &commandList{
 			commandGroup:        get,
 			transformedResponse: reflect.TypeOf(ovsflows.Response{}),
 		},
+		{
+			use:     "ovsflowstats",
+			aliases: []string{"ofs"},
+			short:   "Print OVS flow statistics",
+			long:    "Print the number of OVS flows and their packet and byte counters, aggregated per flow table or per flow cookie, to see which stages of the pipeline the traffic is hitting.",
+			example: `  Print the OVS flow statistics of each flow table
+  $ antctl get ovsflowstats
+  Print the OVS flow statistics of each flow cookie
+  $ antctl get ovsflowstats --groupby cookie`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/ovsflowstats",
+					params: []flagInfo{
+						{
+							name:            "groupby",
+							usage:           "Aggregate the statistics per flow table (default) or per flow cookie.",
+							supportedValues: []string{"table", "cookie"},
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			commandGroup:        get,
+			transformedResponse: reflect.TypeOf(ovsflowstats.Response{}),
+		},
+		{
+			use:     "ovsdatapathflows",
+			aliases: []string{"dpflows"},
+			short:   "Dump OVS datapath flows",
+			long:    "Dump the flows of the OVS datapath along with their hardware offload status, to verify which flows are offloaded to the NIC when OVS hardware offload is enabled.",
+			example: `  Dump all the OVS datapath flows
+  $ antctl get ovsdatapathflows
+  Dump the OVS datapath flows which are not offloaded to the hardware
+  $ antctl get ovsdatapathflows --offloaded=false`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/ovsdatapathflows",
+					params: []flagInfo{
+						{
+							name:            "offloaded",
+							usage:           "Only dump the flows which are offloaded (true) or not offloaded (false) to the hardware.",
+							supportedValues: []string{"true", "false"},
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			commandGroup:        get,
+			transformedResponse: reflect.TypeOf(ovsdatapathflows.Response{}),
+		},
+		{
+			use:     "conntrack",
+			aliases: []string{"flows", "ct"},
+			short:   "Dump the conntrack connections",
+			long:    "Dump the connections of the conntrack zones of Antrea on the local Node, with the local Pods of their endpoints, to debug the connections without a flow collector.",
+			example: `  Dump all the connections
+  $ antctl get conntrack
+  Dump the connections of a local Pod
+  $ antctl get conntrack pod1 -n ns1
+  Dump the connections of the local Pods of a Namespace
+  $ antctl get conntrack -n ns1`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/conntrack",
+					params: []flagInfo{
+						{
+							name:  "name",
+							usage: "Retrieve the connections of a local Pod by name. If present, Namespace must be provided.",
+							arg:   true,
+						},
+						{
+							name:      "namespace",
+							usage:     "Retrieve the connections of the local Pods of a Namespace",
+							shorthand: "n",
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			commandGroup:        get,
+			transformedResponse: reflect.TypeOf(conntrack.Response{}),
+		},
 		{
 			use:   "trace-packet",
 			short: "OVS packet tracing",
@@ -378,6 +491,62 @@ var CommandList = &commandList{
 			commandGroup:        flat,
 			transformedResponse: reflect.TypeOf(ovstracing.Response{}),
 		},
+		{
+			use:   "drain",
+			short: "Show or set the drain mode of the Node datapath",
+			long:  "Show or set the drain mode of the datapath of the Node, to prepare the Node for a maintenance. In drain mode, new connections received from outside the Node through NodePort and LoadBalancer Services are dropped, while established connections are given a grace period to finish. The Node is safe to reboot once the grace period has expired. The drain mode is exited when the Antrea Agent restarts.",
+			example: `  Show the drain status of the Node datapath
+  $ antctl drain
+  Start draining the Node datapath with a grace period of 5 minutes
+  $ antctl drain start --grace-period 5m
+  Stop draining the Node datapath
+  $ antctl drain stop`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/drain",
+					params: []flagInfo{
+						{
+							name:  "action",
+							usage: "The drain action to perform: start or stop. The drain status is shown if not specified.",
+							arg:   true,
+							post:  true,
+						},
+						{
+							name:  "grace-period",
+							usage: "The grace period given to the established connections when starting the drain, e.g. 90s or 5m. Defaults to 1m.",
+						},
+					},
+					outputType: single,
+				},
+			},
+			commandGroup:        flat,
+			transformedResponse: reflect.TypeOf(drain.Response{}),
+		},
+		{
+			use:   "cniconflicts",
+			short: "Show or remove the resources left on the Node by other CNIs",
+			long:  "Show or remove the resources left on the Node by other CNIs or by previous Antrea installations, which may conflict with Antrea: CNI configuration files, network interfaces and iptables chains. The resources are only removed when the cleanup action is specified.",
+			example: `  Show the resources left on the Node by other CNIs
+  $ antctl cniconflicts
+  Remove the resources left on the Node by other CNIs
+  $ antctl cniconflicts cleanup`,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/cniconflicts",
+					params: []flagInfo{
+						{
+							name:  "action",
+							usage: "The action to perform: cleanup. The resources are shown if not specified.",
+							arg:   true,
+							post:  true,
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			commandGroup:        flat,
+			transformedResponse: reflect.TypeOf(cniconflict.Response{}),
+		},
 		{ // TODO: implement as a "rawCommand" (see supportbundle) so that the command can be run out-of-cluster
 			use:     "endpoint",
 			aliases: []string{"endpoints"},
@@ -407,6 +576,114 @@ var CommandList = &commandList{
 			},
 			transformedResponse: reflect.TypeOf(controllernetworkpolicy.EndpointQueryResponse{}),
 		},
+		{
+			use:   "servicetopology",
+			short: "Show how NetworkPolicies affect the endpoints of a Service in each zone and Node.",
+			long:  "Show the distribution of the endpoints of a Service port across zones and Nodes, along with whether the NetworkPolicies applied to each endpoint allow traffic to it. Warnings are reported for the zones and Nodes in which no endpoint is reachable, in particular when the topology keys of the Service prevent clients from falling back to other zones.",
+			example: `  Query the topology of the first port of a Service
+  $ antctl query servicetopology -s svc1 -n ns1
+  Query the topology of a named port of a Service
+  $ antctl query servicetopology -s svc1 -n ns1 -p http
+`,
+			commandGroup: query,
+			controllerEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/servicetopology",
+					params: []flagInfo{
+						{
+							name:      "namespace",
+							usage:     "Namespace of the Service (defaults to 'default')",
+							shorthand: "n",
+						},
+						{
+							name:      "service",
+							usage:     "Name of the Service",
+							shorthand: "s",
+						},
+						{
+							name:      "port",
+							usage:     "Name or number of the Service port (defaults to the first port)",
+							shorthand: "p",
+						},
+					},
+					outputType: single,
+				},
+			},
+			transformedResponse: reflect.TypeOf(controllernetworkpolicy.ServiceTopologyResponse{}),
+		},
+		{
+			use:     "span",
+			aliases: []string{"spans"},
+			short:   "Show the groups of control plane NetworkPolicies and the Nodes they are sent to.",
+			long:    "Show the control plane NetworkPolicies computed by the Antrea Controller, along with the AppliedToGroups and AddressGroups they refer to. For each of them, the selector, the number of selected Pods and ExternalEntities, and the span, i.e. the Nodes to which it is sent, are reported.",
+			example: `  Query the spans of a specific control plane NetworkPolicy
+  $ antctl query span 6001549b-ba63-4752-8267-30f52b4332db
+  Query the spans of the control plane NetworkPolicies derived from a source NetworkPolicy
+  $ antctl query span -S allow-http -n ns1
+  Query the spans of the control plane NetworkPolicies with a specific source type
+  $ antctl query span -T acnp
+`,
+			commandGroup: query,
+			controllerEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/span",
+					params: []flagInfo{
+						{
+							name:  "name",
+							usage: "Name of the control plane NetworkPolicy.",
+							arg:   true,
+						},
+						{
+							name:      "source",
+							usage:     "Name of the source NetworkPolicy from which the control plane NetworkPolicies were derived.",
+							shorthand: "S",
+						},
+						{
+							name:      "namespace",
+							usage:     "Namespace of the source NetworkPolicy.",
+							shorthand: "n",
+						},
+						{
+							name:      "type",
+							usage:     "Type of the source NetworkPolicy: K8sNP, ACNP, ANP",
+							shorthand: "T",
+						},
+					},
+					outputType: single,
+				},
+			},
+			transformedResponse: reflect.TypeOf(controllernetworkpolicy.SpanQueryResponse{}),
+		},
+		{
+			use:   "baselinestatus",
+			short: "Show the violations of the baseline policies of a Namespace in Observe mode.",
+			long:  "Show the baseline mode of a Namespace and, if it is in Observe mode, the number of sessions which would have been dropped by its baseline Antrea NetworkPolicies during the provided window, as reported by the NetworkPolicy stats. The Namespace is ready to be switched to Enforce mode once it has been observed for the whole window without violations.",
+			example: `  Query the violations of the baseline policies of a Namespace in the last 24 hours
+  $ antctl query baselinestatus -n ns1
+  Query the violations of the baseline policies of a Namespace in the last 6 hours
+  $ antctl query baselinestatus -n ns1 -w 6h
+`,
+			commandGroup: query,
+			controllerEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/baselinestatus",
+					params: []flagInfo{
+						{
+							name:      "namespace",
+							usage:     "Namespace to query.",
+							shorthand: "n",
+						},
+						{
+							name:      "window",
+							usage:     "Period over which the violations are counted, rounded up to whole hours (defaults to and must not exceed 24h).",
+							shorthand: "w",
+						},
+					},
+					outputType: single,
+				},
+			},
+			transformedResponse: reflect.TypeOf(controllernetworkpolicy.BaselineStatus{}),
+		},
 	},
 	rawCommands: []rawCommand{
 		{
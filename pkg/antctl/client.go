@@ -113,8 +113,8 @@ func (c *client) nonResourceRequest(e *nonResourceEndpoint, opt *requestOption)
 		q.Set(k, v)
 	}
 	u.RawQuery = q.Encode()
-	getter := restClient.Get().RequestURI(u.RequestURI()).Timeout(opt.timeout)
-	result, err := getter.DoRaw(context.TODO())
+	request := restClient.Verb(e.method(opt.args)).RequestURI(u.RequestURI()).Timeout(opt.timeout)
+	result, err := request.DoRaw(context.TODO())
 	if err != nil {
 		statusErr, ok := err.(*errors.StatusError)
 		if !ok {
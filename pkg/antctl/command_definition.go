@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
 	"os"
 	"reflect"
 	"sort"
@@ -30,6 +32,7 @@ import (
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/antctl/runtime"
@@ -44,11 +47,20 @@ const (
 	jsonFormatter  formatterType = "json"
 	yamlFormatter  formatterType = "yaml"
 	tableFormatter formatterType = "table"
+	// wideFormatter is the table format without the summarization of long columns.
+	wideFormatter formatterType = "wide"
+	// jsonpathFormatter is the format of the output of a JSONPath template, which is
+	// specified as "jsonpath=<template>".
+	jsonpathFormatter formatterType = "jsonpath"
 )
 
+// outputFormatUsage is the usage of the output flag of all the commands.
+const outputFormatUsage = "output format: json|jsonpath=<template>|table|wide|yaml"
+
 const (
-	maxTableOutputColumnLength int    = 50
-	sortByEffectivePriority    string = "effectivePriority"
+	maxTableOutputColumnLength  int    = 50
+	wideTableOutputColumnLength int    = math.MaxInt32
+	sortByEffectivePriority     string = "effectivePriority"
 )
 
 // commandGroup is used to group commands, it could be specified in commandDefinition.
@@ -153,6 +165,18 @@ func (e *nonResourceEndpoint) OutputType() OutputType {
 	return e.outputType
 }
 
+// method returns the HTTP method of the request sent with the provided
+// arguments: POST if one of the flags which change the state of the component
+// is set, and GET otherwise.
+func (e *nonResourceEndpoint) method(args map[string]string) string {
+	for _, f := range e.params {
+		if f.post && args[f.name] != "" {
+			return http.MethodPost
+		}
+	}
+	return http.MethodGet
+}
+
 // endpoint is used to specified the API for an antctl running against antrea-controller.
 type endpoint struct {
 	resourceEndpoint    *resourceEndpoint
@@ -171,6 +195,10 @@ type flagInfo struct {
 	supportedValues []string
 	arg             bool
 	usage           string
+	// post is true for a flag of a nonResourceEndpoint which changes the
+	// state of the component when it is set, in which case the request is
+	// sent with the POST method instead of GET.
+	post bool
 }
 
 // rawCommand defines a full function cobra.Command which lets developers
@@ -382,6 +410,46 @@ func (cd *commandDefinition) yamlOutput(obj interface{}, writer io.Writer) error
 	return nil
 }
 
+// jsonpathOutput prints the result of a JSONPath template applied to the JSON
+// representation of obj, i.e. the same document as the json output.
+func (cd *commandDefinition) jsonpathOutput(obj interface{}, template string, writer io.Writer) error {
+	target, err := respTransformer(obj)
+	if err != nil {
+		return fmt.Errorf("error when transforming obj: %w", err)
+	}
+	jp := jsonpath.New("output")
+	if err := jp.Parse(template); err != nil {
+		return fmt.Errorf("error when parsing jsonpath template %q: %w", template, err)
+	}
+	var buffer bytes.Buffer
+	if err := jp.Execute(&buffer, target); err != nil {
+		return fmt.Errorf("error when executing jsonpath template %q: %w", template, err)
+	}
+	buffer.WriteString("\n")
+	if _, err := io.Copy(writer, &buffer); err != nil {
+		return fmt.Errorf("error when copy output into writer: %w", err)
+	}
+	return nil
+}
+
+// parseOutputFormat returns the formatter of an output format and, for the
+// jsonpath formatter, the template following "jsonpath=".
+func parseOutputFormat(format string) (formatterType, string, error) {
+	ft := formatterType(format)
+	switch ft {
+	case jsonFormatter, yamlFormatter, tableFormatter, wideFormatter:
+		return ft, "", nil
+	}
+	if strings.HasPrefix(format, string(jsonpathFormatter)+"=") {
+		template := strings.TrimPrefix(format, string(jsonpathFormatter)+"=")
+		if template == "" {
+			return "", "", fmt.Errorf("jsonpath template must be provided")
+		}
+		return jsonpathFormatter, template, nil
+	}
+	return "", "", fmt.Errorf("unsupported format type: %v", format)
+}
+
 // respTransformer collects output fields in original transformedResponse
 // and flattens them. respTransformer realizes this by turning obj into
 // JSON and unmarshalling it.
@@ -401,8 +469,9 @@ func respTransformer(obj interface{}) (interface{}, error) {
 	return target, nil
 }
 
-// tableOutputForGetCommands formats the table output for "get" commands.
-func (cd *commandDefinition) tableOutputForGetCommands(obj interface{}, writer io.Writer) error {
+// tableOutputForGetCommands formats the table output for "get" commands. The
+// lists in the columns are summarized to fit in maxColumnLength characters.
+func (cd *commandDefinition) tableOutputForGetCommands(obj interface{}, maxColumnLength int, writer io.Writer) error {
 	var list []common.TableOutput
 	if reflect.TypeOf(obj).Kind() == reflect.Slice {
 		s := reflect.ValueOf(obj)
@@ -434,7 +503,7 @@ func (cd *commandDefinition) tableOutputForGetCommands(obj interface{}, writer i
 	rows := make([][]string, len(list)+1)
 	rows[0] = list[0].GetTableHeader()
 	for i, element := range list {
-		rows[i+1] = element.GetTableRow(maxTableOutputColumnLength)
+		rows[i+1] = element.GetTableRow(maxColumnLength)
 	}
 
 	if list[0].SortRows() {
@@ -611,6 +680,76 @@ func (cd *commandDefinition) tableOutputForQueryEndpoint(obj interface{}, writer
 	return nil
 }
 
+// tableOutputForQueryServiceTopology prints a table with one row per endpoint of the Service, grouped by zone and
+// Node, followed by the warnings of the response.
+func (cd *commandDefinition) tableOutputForQueryServiceTopology(obj interface{}, writer io.Writer) error {
+	response := obj.(*networkpolicy.ServiceTopologyResponse)
+	rows := [][]string{{"ZONE", "NODE", "ENDPOINT", "IP", "PORT", "OUTCOME", "POLICIES"}}
+	// Empty cells are displayed as <NONE> by getColumnWidths.
+	for _, zone := range response.Zones {
+		zoneName := zone.Zone
+		if len(zone.Nodes) == 0 {
+			rows = append(rows, []string{zoneName, "", "", "", "", "", ""})
+			continue
+		}
+		for _, node := range zone.Nodes {
+			for _, ep := range node.Endpoints {
+				policies := make([]string, 0, len(ep.Policies))
+				for _, policy := range ep.Policies {
+					if policy.Namespace != "" {
+						policies = append(policies, policy.Namespace+"/"+policy.Name)
+					} else {
+						policies = append(policies, policy.Name)
+					}
+				}
+				rows = append(rows, []string{zoneName, node.Node, ep.Namespace + "/" + ep.Name, ep.IP,
+					strconv.Itoa(int(ep.Port)), string(ep.Outcome), strings.Join(policies, ",")})
+			}
+		}
+	}
+	numRows, numCols := len(rows), len(rows[0])
+	if err := constructTable(numRows, numCols, getColumnWidths(numRows, numCols, rows), rows, writer); err != nil {
+		return err
+	}
+	if len(response.Warnings) == 0 {
+		return nil
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString("\nWarnings:\n")
+	for _, warning := range response.Warnings {
+		buffer.WriteString("  " + warning + "\n")
+	}
+	if _, err := io.Copy(writer, &buffer); err != nil {
+		return fmt.Errorf("error when copy output into writer: %w", err)
+	}
+	return nil
+}
+
+func (cd *commandDefinition) tableOutputForQuerySpan(obj interface{}, writer io.Writer) error {
+	response := obj.(*networkpolicy.SpanQueryResponse)
+	rows := [][]string{{"TYPE", "NAME", "SOURCE/SELECTOR", "MEMBERS", "SPAN"}}
+	// Empty cells are displayed as <NONE> by getColumnWidths.
+	groupRow := func(groupType string, group networkpolicy.GroupSpan) []string {
+		if group.Missing {
+			return []string{groupType, group.Name, "", "<MISSING>", ""}
+		}
+		return []string{groupType, group.Name, group.Selector, strconv.Itoa(group.Members),
+			common.GenerateTableElementWithSummary(group.Nodes, maxTableOutputColumnLength)}
+	}
+	for _, policy := range response.Policies {
+		rows = append(rows, []string{"NetworkPolicy", policy.Name, policy.Source, "",
+			common.GenerateTableElementWithSummary(policy.Nodes, maxTableOutputColumnLength)})
+		for _, group := range policy.AppliedToGroups {
+			rows = append(rows, groupRow("  AppliedToGroup", group))
+		}
+		for _, group := range policy.AddressGroups {
+			rows = append(rows, groupRow("  AddressGroup", group))
+		}
+	}
+	numRows, numCols := len(rows), len(rows[0])
+	return constructTable(numRows, numCols, getColumnWidths(numRows, numCols, rows), rows, writer)
+}
+
 func (cd *commandDefinition) tableOutput(obj interface{}, writer io.Writer) error {
 	target, err := respTransformer(obj)
 	if err != nil {
@@ -689,9 +828,13 @@ func (cd *commandDefinition) tableOutput(obj interface{}, writer io.Writer) erro
 
 // output reads bytes from the resp and outputs the data to the writer in desired
 // format. If the AddonTransform is set, it will use the function to transform
-// the data first. It will try to output the resp in the format ft specified after
+// the data first. It will try to output the resp in the format specified after
 // doing transform.
-func (cd *commandDefinition) output(resp io.Reader, writer io.Writer, ft formatterType, single bool, args map[string]string) (err error) {
+func (cd *commandDefinition) output(resp io.Reader, writer io.Writer, format string, single bool, args map[string]string) (err error) {
+	ft, template, err := parseOutputFormat(format)
+	if err != nil {
+		return err
+	}
 	var obj interface{}
 	addonTransform := cd.getAddonTransform()
 
@@ -717,18 +860,26 @@ func (cd *commandDefinition) output(resp io.Reader, writer io.Writer, ft formatt
 		return cd.jsonOutput(obj, writer)
 	case yamlFormatter:
 		return cd.yamlOutput(obj, writer)
-	case tableFormatter:
+	case jsonpathFormatter:
+		return cd.jsonpathOutput(obj, template, writer)
+	case tableFormatter, wideFormatter:
 		if cd.commandGroup == get {
-			return cd.tableOutputForGetCommands(obj, writer)
+			maxColumnLength := maxTableOutputColumnLength
+			if ft == wideFormatter {
+				maxColumnLength = wideTableOutputColumnLength
+			}
+			return cd.tableOutputForGetCommands(obj, maxColumnLength, writer)
 		} else if cd.commandGroup == query {
 			if cd.controllerEndpoint.nonResourceEndpoint.path == "/endpoint" {
 				return cd.tableOutputForQueryEndpoint(obj, writer)
+			} else if cd.controllerEndpoint.nonResourceEndpoint.path == "/servicetopology" {
+				return cd.tableOutputForQueryServiceTopology(obj, writer)
+			} else if cd.controllerEndpoint.nonResourceEndpoint.path == "/span" {
+				return cd.tableOutputForQuerySpan(obj, writer)
 			}
 		} else {
 			return cd.tableOutput(obj, writer)
 		}
-	default:
-		return fmt.Errorf("unsupported format type: %v", ft)
 	}
 	return nil
 }
@@ -784,6 +935,13 @@ func (cd *commandDefinition) newCommandRunE(c *client) func(*cobra.Command, []st
 				break
 			}
 		}
+		outputFormat, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		if _, _, err := parseOutputFormat(outputFormat); err != nil {
+			return err
+		}
 		kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig")
 		timeout, _ := cmd.Flags().GetDuration("timeout")
 		server, _ := cmd.Flags().GetString("server")
@@ -797,12 +955,8 @@ func (cd *commandDefinition) newCommandRunE(c *client) func(*cobra.Command, []st
 		if err != nil {
 			return err
 		}
-		outputFormat, err := cmd.Flags().GetString("output")
-		if err != nil {
-			return err
-		}
 		isSingle := cd.getEndpoint().OutputType() != multiple && (cd.getEndpoint().OutputType() == single || argGet)
-		return cd.output(resp, os.Stdout, formatterType(outputFormat), isSingle, argMap)
+		return cd.output(resp, os.Stdout, outputFormat, isSingle, argMap)
 	}
 }
 
@@ -823,11 +977,11 @@ func (cd *commandDefinition) applyFlagsToCommand(cmd *cobra.Command) {
 		cmd.Args = cobra.NoArgs
 	}
 	if cd.commandGroup == get {
-		cmd.Flags().StringP("output", "o", "table", "output format: json|table|yaml")
+		cmd.Flags().StringP("output", "o", "table", outputFormatUsage)
 	} else if cd.commandGroup == query {
-		cmd.Flags().StringP("output", "o", "table", "output format: json|table|yaml")
+		cmd.Flags().StringP("output", "o", "table", outputFormatUsage)
 	} else {
-		cmd.Flags().StringP("output", "o", "yaml", "output format: json|table|yaml")
+		cmd.Flags().StringP("output", "o", "yaml", outputFormatUsage)
 	}
 }
 
@@ -291,7 +291,7 @@ default   nginx-6db489d4b7-vgv7v Interface      127.0.0.1 07-16-76-00-02-86 port
 		t.Run(tc.name, func(t *testing.T) {
 			opt := &commandDefinition{}
 			var outputBuf bytes.Buffer
-			err := opt.tableOutputForGetCommands(tc.rawResponseData, &outputBuf)
+			err := opt.tableOutputForGetCommands(tc.rawResponseData, maxTableOutputColumnLength, &outputBuf)
 			fmt.Println(outputBuf.String())
 			assert.Nil(t, err)
 			assert.Equal(t, tc.expected, outputBuf.String())
@@ -299,6 +299,52 @@ default   nginx-6db489d4b7-vgv7v Interface      127.0.0.1 07-16-76-00-02-86 port
 	}
 }
 
+// TestTableOutputForGetCommandsWide ensures the lists are not summarized in the
+// wide output.
+func TestTableOutputForGetCommandsWide(t *testing.T) {
+	rawResponseData := []addressgroup.Response{
+		{
+			Name: "GroupName1",
+			Pods: []common.GroupMember{
+				{IP: "127.0.0.1"}, {IP: "192.168.0.1"}, {IP: "127.0.0.2"},
+				{IP: "127.0.0.3"}, {IP: "10.0.0.3"}, {IP: "127.0.0.5"}, {IP: "127.0.0.6"},
+			},
+		},
+	}
+	expected := `NAME       POD-IPS                                                               
+GroupName1 10.0.0.3,127.0.0.1,127.0.0.2,127.0.0.3,127.0.0.5,127.0.0.6,192.168.0.1
+`
+	opt := &commandDefinition{}
+	var outputBuf bytes.Buffer
+	err := opt.tableOutputForGetCommands(rawResponseData, wideTableOutputColumnLength, &outputBuf)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, outputBuf.String())
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format           string
+		expectedFormat   formatterType
+		expectedTemplate string
+		expectedErr      bool
+	}{
+		{format: "json", expectedFormat: jsonFormatter},
+		{format: "wide", expectedFormat: wideFormatter},
+		{format: "jsonpath={.items[*].name}", expectedFormat: jsonpathFormatter, expectedTemplate: "{.items[*].name}"},
+		{format: "jsonpath=", expectedErr: true},
+		{format: "xml", expectedErr: true},
+	} {
+		ft, template, err := parseOutputFormat(tc.format)
+		if tc.expectedErr {
+			assert.Error(t, err, tc.format)
+			continue
+		}
+		assert.NoError(t, err, tc.format)
+		assert.Equal(t, tc.expectedFormat, ft)
+		assert.Equal(t, tc.expectedTemplate, template)
+	}
+}
+
 // TestFormat ensures the formatter and AddonTransform works as expected.
 func TestFormat(t *testing.T) {
 	for _, tc := range []struct {
@@ -352,6 +398,13 @@ func TestFormat(t *testing.T) {
 			expected:        "Foo            \n{\"foo\":\"foo\"}  \n{\"foo\":\"bar\"}  \n",
 			formatter:       tableFormatter,
 		},
+		{
+			name:            "StructureData-NoTransform-List-JSONPath",
+			rawResponseData: []Foobar{{Foo: "foo"}, {Foo: "bar"}},
+			responseStruct:  reflect.TypeOf(Foobar{}),
+			expected:        "foo bar\n",
+			formatter:       "jsonpath={[*].foo}",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			opt := &commandDefinition{
@@ -363,7 +416,7 @@ func TestFormat(t *testing.T) {
 			responseData, err := json.Marshal(tc.rawResponseData)
 			assert.Nil(t, err)
 			var outputBuf bytes.Buffer
-			err = opt.output(bytes.NewBuffer(responseData), &outputBuf, tc.formatter, tc.single, map[string]string{})
+			err = opt.output(bytes.NewBuffer(responseData), &outputBuf, string(tc.formatter), tc.single, map[string]string{})
 			assert.Nil(t, err)
 			assert.Equal(t, tc.expected, outputBuf.String())
 		})
@@ -17,15 +17,20 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
 	"k8s.io/klog"
 	"k8s.io/kubectl/pkg/proxy"
 
@@ -63,6 +68,7 @@ type proxyOptions struct {
 
 	controller    bool
 	agentNodeName string
+	portForward   bool
 }
 
 var options *proxyOptions
@@ -123,6 +129,8 @@ var proxyCommandExample = strings.Trim(`
   $ antctl proxy --controller
   Start a reverse proxy for the API of an Antrea Agent running on a specific Node
   $ antctl proxy --agent-node <Node Name>
+  Start a reverse proxy for the API of an Antrea Agent, tunneled through the K8s apiserver
+  $ antctl proxy --agent-node <Node Name> --port-forward
 `, "\n")
 
 func init() {
@@ -152,6 +160,7 @@ func init() {
 	Command.Flags().DurationVar(&o.keepalive, "keepalive", 0, "keepalive specifies the keep-alive period for an active network connection. Set to 0 to disable keepalive.")
 	Command.Flags().BoolVar(&o.controller, "controller", false, "Run proxy for Antrea Controller API. If both --controller and --agent-node are omitted, the proxy will run for the Controller API.")
 	Command.Flags().StringVar(&o.agentNodeName, "agent-node", "", "Run proxy for Antrea Agent API on the provided K8s Node.")
+	Command.Flags().BoolVar(&o.portForward, "port-forward", false, "Reach the Antrea API through a port-forward tunnel established via the K8s apiserver, instead of connecting to the Node IP directly. This is useful when the Nodes are not reachable from the machine running antctl.")
 }
 
 // TODO: enable secure connection. For the Antrea Controller, we can do it by using the CA
@@ -163,26 +172,29 @@ func setupKubeconfig(kubeconfig *rest.Config) {
 	kubeconfig.CAData = nil
 }
 
-func createAgentClientCfg(k8sClientset kubernetes.Interface, antreaClientset antrea.Interface, cfgTmpl *rest.Config, nodeName string) (*rest.Config, error) {
-	node, err := k8sClientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("error when looking up Node %s: %w", nodeName, err)
-	}
+func getAgentInfo(antreaClientset antrea.Interface, nodeName string) (*clusterinformationv1beta1.AntreaAgentInfo, error) {
 	// TODO: filter by Node name, but that would require API support
 	agentInfoList, err := antreaClientset.ClusterinformationV1beta1().AntreaAgentInfos().List(context.TODO(), metav1.ListOptions{ResourceVersion: "0"})
 	if err != nil {
 		return nil, err
 	}
-	var agentInfo *clusterinformationv1beta1.AntreaAgentInfo
 	for i := range agentInfoList.Items {
 		ai := agentInfoList.Items[i]
 		if ai.NodeRef.Name == nodeName {
-			agentInfo = &ai
-			break
+			return &ai, nil
 		}
 	}
-	if agentInfo == nil {
-		return nil, fmt.Errorf("no Antrea Agent found for Node name %s", nodeName)
+	return nil, fmt.Errorf("no Antrea Agent found for Node name %s", nodeName)
+}
+
+func createAgentClientCfg(k8sClientset kubernetes.Interface, antreaClientset antrea.Interface, cfgTmpl *rest.Config, nodeName string) (*rest.Config, error) {
+	node, err := k8sClientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when looking up Node %s: %w", nodeName, err)
+	}
+	agentInfo, err := getAgentInfo(antreaClientset, nodeName)
+	if err != nil {
+		return nil, err
 	}
 	nodeIP, err := noderoute.GetNodeAddr(node)
 	if err != nil {
@@ -214,6 +226,66 @@ func createControllerClientCfg(k8sClientset kubernetes.Interface, antreaClientse
 	return cfg, nil
 }
 
+// getPortForwardTarget returns the Pod and the API port of the Antrea Controller, or of the
+// Antrea Agent running on the Node if controller is false.
+func getPortForwardTarget(antreaClientset antrea.Interface, controller bool, nodeName string) (corev1.ObjectReference, int, error) {
+	if controller {
+		controllerInfo, err := antreaClientset.ClusterinformationV1beta1().AntreaControllerInfos().Get(context.TODO(), "antrea-controller", metav1.GetOptions{})
+		if err != nil {
+			return corev1.ObjectReference{}, 0, err
+		}
+		return controllerInfo.PodRef, controllerInfo.APIPort, nil
+	}
+	agentInfo, err := getAgentInfo(antreaClientset, nodeName)
+	if err != nil {
+		return corev1.ObjectReference{}, 0, err
+	}
+	return agentInfo.PodRef, agentInfo.APIPort, nil
+}
+
+// createPortForwardClientCfg forwards a local port to the API port of the Antrea Pod through the
+// K8s apiserver, and returns a config to access the Antrea API through the local port. As the
+// Antrea Pods use the host network, the API port is reachable in the network namespace of the Pod.
+// The tunnel is authenticated with the credentials of the kubeconfig, and is closed when stopCh is
+// closed.
+func createPortForwardClientCfg(kubeconfig *rest.Config, k8sClientset kubernetes.Interface, antreaClientset antrea.Interface, cfgTmpl *rest.Config, stopCh chan struct{}) (*rest.Config, error) {
+	podRef, apiPort, err := getPortForwardTarget(antreaClientset, options.controller, options.agentNodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	req := k8sClientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(podRef.Namespace).Name(podRef.Name).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	readyCh := make(chan struct{})
+	// Port 0 lets the OS pick a free local port.
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", apiPort)}, stopCh, readyCh, ioutil.Discard, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+	select {
+	case err := <-errCh:
+		return nil, fmt.Errorf("error when forwarding port of Pod %s/%s: %w", podRef.Namespace, podRef.Name, err)
+	case <-readyCh:
+	}
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("Forwarding 127.0.0.1:%d to port %d of Pod %s/%s", ports[0].Local, apiPort, podRef.Namespace, podRef.Name)
+
+	cfg := rest.CopyConfig(cfgTmpl)
+	cfg.Host = fmt.Sprintf("https://%s", net.JoinHostPort("127.0.0.1", fmt.Sprint(ports[0].Local)))
+	return cfg, nil
+}
+
 func runE(cmd *cobra.Command, _ []string) error {
 	if runtime.Mode != runtime.ModeController || runtime.InPod {
 		return fmt.Errorf("only remote mode is supported for this command")
@@ -247,7 +319,14 @@ func runE(cmd *cobra.Command, _ []string) error {
 	}
 
 	var clientCfg *rest.Config
-	if options.controller {
+	if options.portForward {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		clientCfg, err = createPortForwardClientCfg(kubeconfig, k8sClientset, antreaClientset, restconfigTmpl, stopCh)
+		if err != nil {
+			return fmt.Errorf("error when creating port-forward client config: %w", err)
+		}
+	} else if options.controller {
 		clientCfg, err = createControllerClientCfg(k8sClientset, antreaClientset, restconfigTmpl)
 		if err != nil {
 			return fmt.Errorf("error when creating Controller client config: %w", err)
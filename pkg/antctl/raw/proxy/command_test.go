@@ -0,0 +1,150 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterinformationv1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/clusterinformation/v1beta1"
+	antreafake "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+)
+
+func TestParseFlags(t *testing.T) {
+	tcs := []struct {
+		name                  string
+		args                  []string
+		expectedController    bool
+		expectedAgentNodeName string
+		expectedPortForward   bool
+		expectedErr           bool
+	}{
+		{
+			name:               "default to controller",
+			args:               []string{},
+			expectedController: true,
+		},
+		{
+			name:                "controller with port-forward",
+			args:                []string{"--controller", "--port-forward"},
+			expectedController:  true,
+			expectedPortForward: true,
+		},
+		{
+			name:                  "agent with port-forward",
+			args:                  []string{"--agent-node", "node1", "--port-forward"},
+			expectedAgentNodeName: "node1",
+			expectedPortForward:   true,
+		},
+		{
+			name:        "controller and agent",
+			args:        []string{"--controller", "--agent-node", "node1"},
+			expectedErr: true,
+		},
+		{
+			name:        "unix socket and port",
+			args:        []string{"--unix-socket", "/tmp/antctl.sock", "--port", "8080"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// The flags are bound to the global options, reset them to their defaults.
+			Command.Flags().VisitAll(func(f *pflag.Flag) {
+				require.NoError(t, f.Value.Set(f.DefValue))
+				f.Changed = false
+			})
+			require.NoError(t, Command.Flags().Parse(tc.args))
+			err := options.validateAndComplete()
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedController, options.controller)
+			assert.Equal(t, tc.expectedAgentNodeName, options.agentNodeName)
+			assert.Equal(t, tc.expectedPortForward, options.portForward)
+		})
+	}
+}
+
+func TestGetPortForwardTarget(t *testing.T) {
+	controllerInfo := &clusterinformationv1beta1.AntreaControllerInfo{
+		ObjectMeta: metav1.ObjectMeta{Name: "antrea-controller"},
+		PodRef:     corev1.ObjectReference{Kind: "Pod", Namespace: "kube-system", Name: "antrea-controller-abcde"},
+		NodeRef:    corev1.ObjectReference{Kind: "Node", Name: "node1"},
+		APIPort:    10349,
+	}
+	newAgentInfo := func(nodeName, podName string) *clusterinformationv1beta1.AntreaAgentInfo {
+		return &clusterinformationv1beta1.AntreaAgentInfo{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			PodRef:     corev1.ObjectReference{Kind: "Pod", Namespace: "kube-system", Name: podName},
+			NodeRef:    corev1.ObjectReference{Kind: "Node", Name: nodeName},
+			APIPort:    10350,
+		}
+	}
+	antreaClientset := antreafake.NewSimpleClientset(controllerInfo, newAgentInfo("node1", "antrea-agent-11111"), newAgentInfo("node2", "antrea-agent-22222"))
+
+	tcs := []struct {
+		name            string
+		controller      bool
+		nodeName        string
+		expectedPodName string
+		expectedPort    int
+		expectedErr     bool
+	}{
+		{
+			name:            "controller",
+			controller:      true,
+			expectedPodName: "antrea-controller-abcde",
+			expectedPort:    10349,
+		},
+		{
+			name:            "agent",
+			nodeName:        "node2",
+			expectedPodName: "antrea-agent-22222",
+			expectedPort:    10350,
+		},
+		{
+			name:        "unknown Node",
+			nodeName:    "node3",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			podRef, port, err := getPortForwardTarget(antreaClientset, tc.controller, tc.nodeName)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "kube-system", podRef.Namespace)
+			assert.Equal(t, tc.expectedPodName, podRef.Name)
+			assert.Equal(t, tc.expectedPort, port)
+		})
+	}
+
+	// The controller cannot be resolved if it has not published its information yet.
+	_, _, err := getPortForwardTarget(antreafake.NewSimpleClientset(), true, "")
+	assert.Error(t, err)
+}
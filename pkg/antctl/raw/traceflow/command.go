@@ -19,9 +19,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"os"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -36,6 +39,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/antctl/runtime"
 	"github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
 	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	"github.com/vmware-tanzu/antrea/pkg/graphviz"
 )
 
 var (
@@ -59,6 +63,7 @@ var protocols = map[string]int32{
 type Response struct {
 	Name        string                  `json:"name" yaml:"name"`                                   // Traceflow name
 	Phase       v1alpha1.TraceflowPhase `json:"phase,omitempty" yaml:"phase,omitempty"`             // Traceflow phase
+	Reason      string                  `json:"reason,omitempty" yaml:"reason,omitempty"`           // Traceflow reason of the phase
 	Source      string                  `json:"source,omitempty" yaml:"source,omitempty"`           // Traceflow source, e.g. "default/pod0"
 	Destination string                  `json:"destination,omitempty" yaml:"destination,omitempty"` // Traceflow destination, e.g. "default/pod1"
 	NodeResults []v1alpha1.NodeResult   `json:"results,omitempty" yaml:"results,omitempty"`         // Traceflow node results
@@ -80,13 +85,17 @@ func init() {
   $antctl traceflow -S ns0/busybox0 -D ns1/busybox1 -o json
   Start a Traceflow from busybox0 to busybox1, with TCP header and 80 as destination port
   $antctl traceflow -S busybox0 -D busybox1 -f tcp,tcp_dst=80
+  Start a Traceflow from busybox0 to busybox1 and print the observations as a table
+  $antctl traceflow -S busybox0 -D busybox1 -o table
+  Start a Traceflow from busybox0 to busybox1 and print the observations as a graph in DOT format
+  $antctl traceflow -S busybox0 -D busybox1 -o graph | dot -Tpng > tf.png
 `,
 		RunE: runE,
 	}
 
 	Command.Flags().StringVarP(&option.source, "source", "S", "", "source of the Traceflow: Namespace/Pod or Pod")
 	Command.Flags().StringVarP(&option.destination, "destination", "D", "", "destination of the Traceflow: Namespace/Pod, Pod, Namespace/Service, Service or IP")
-	Command.Flags().StringVarP(&option.outputType, "output", "o", "yaml", "output type: yaml (default), json")
+	Command.Flags().StringVarP(&option.outputType, "output", "o", "yaml", "output type: yaml (default), json, table, graph, svg, png")
 	Command.Flags().BoolVarP(&option.waiting, "wait", "", true, "if false, command returns without retrieving results")
 	Command.Flags().StringVarP(&option.flow, "flow", "f", "", "specify the flow (packet headers) of the Traceflow packet, including tcp_src, tcp_dst, tcp_flags, udp_src, udp_dst")
 }
@@ -142,12 +151,15 @@ func runE(cmd *cobra.Command, _ []string) error {
 		if err != nil {
 			return false, err
 		}
-		if tf.Status.Phase != v1alpha1.Succeeded {
+		if tf.Status.Phase != v1alpha1.Succeeded && tf.Status.Phase != v1alpha1.Failed {
 			return false, nil
 		}
-		if err := output(tf); err != nil {
+		if err := output(tf, os.Stdout); err != nil {
 			return false, fmt.Errorf("error when outputing result: %w", err)
 		}
+		if tf.Status.Phase == v1alpha1.Failed {
+			return false, fmt.Errorf("traceflow failed: %s", tf.Status.Reason)
+		}
 		return true, nil
 	}); err != nil {
 		return fmt.Errorf("error when retrieving Traceflow: %w", err)
@@ -298,10 +310,11 @@ func getPortFields(cleanFlow string) (map[string]int, error) {
 	return fields, nil
 }
 
-func output(tf *v1alpha1.Traceflow) error {
+func output(tf *v1alpha1.Traceflow, writer io.Writer) error {
 	r := Response{
 		Name:        tf.Name,
 		Phase:       tf.Status.Phase,
+		Reason:      tf.Status.Reason,
 		Source:      fmt.Sprintf("%s/%s", tf.Spec.Source.Namespace, tf.Spec.Source.Pod),
 		Destination: tf.Spec.Destination.IP,
 		NodeResults: tf.Status.Results,
@@ -313,30 +326,43 @@ func output(tf *v1alpha1.Traceflow) error {
 			r.Destination = fmt.Sprintf("%s/%s", tf.Spec.Destination.Namespace, tf.Spec.Destination.Pod)
 		}
 	}
-	if option.outputType == "json" {
-		if err := jsonOutput(&r); err != nil {
+	switch option.outputType {
+	case "json":
+		if err := jsonOutput(&r, writer); err != nil {
 			return fmt.Errorf("error when converting output to json: %w", err)
 		}
-	} else if option.outputType == "yaml" {
-		if err := yamlOutput(&r); err != nil {
+	case "yaml":
+		if err := yamlOutput(&r, writer); err != nil {
 			return fmt.Errorf("error when converting output to yaml: %w", err)
 		}
-	} else {
-		return fmt.Errorf("output types should be yaml or json")
+	case "table":
+		if err := tableOutput(&r, writer); err != nil {
+			return fmt.Errorf("error when converting output to table: %w", err)
+		}
+	case "graph":
+		if err := graphOutput(tf, writer); err != nil {
+			return fmt.Errorf("error when converting output to graph: %w", err)
+		}
+	case "svg", "png":
+		if err := imageOutput(tf, graphviz.Format(option.outputType), writer); err != nil {
+			return fmt.Errorf("error when converting output to %s: %w", option.outputType, err)
+		}
+	default:
+		return fmt.Errorf("output types should be yaml, json, table, graph, svg or png")
 	}
 	return nil
 }
 
-func yamlOutput(r *Response) error {
+func yamlOutput(r *Response, writer io.Writer) error {
 	o, err := yaml.Marshal(&r)
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(o))
-	return nil
+	_, err = fmt.Fprintln(writer, string(o))
+	return err
 }
 
-func jsonOutput(r *Response) error {
+func jsonOutput(r *Response, writer io.Writer) error {
 	o, err := json.Marshal(r)
 	if err != nil {
 		return err
@@ -345,8 +371,73 @@ func jsonOutput(r *Response) error {
 	if err = json.Indent(&b, o, "", "  "); err != nil {
 		return err
 	}
-	fmt.Println(string(b.Bytes()))
-	return nil
+	_, err = fmt.Fprintln(writer, b.String())
+	return err
+}
+
+// tableOutput prints the summary of the Traceflow followed by the observation chain, with one row per observation
+// in the order they were reported by the Nodes.
+func tableOutput(r *Response, writer io.Writer) error {
+	fmt.Fprintf(writer, "name: %s\nphase: %s\n", r.Name, r.Phase)
+	if r.Reason != "" {
+		fmt.Fprintf(writer, "reason: %s\n", r.Reason)
+	}
+	fmt.Fprintf(writer, "source: %s\ndestination: %s\n\n", r.Source, r.Destination)
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tROLE\tCOMPONENT\tCOMPONENT-INFO\tACTION\tDETAILS")
+	for _, result := range r.NodeResults {
+		for _, o := range result.Observations {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Node, result.Role, o.Component,
+				o.ComponentInfo, o.Action, getObservationDetails(&o))
+		}
+	}
+	return w.Flush()
+}
+
+// getObservationDetails returns the optional fields set in an observation as a comma-separated list of key=value
+// pairs.
+func getObservationDetails(o *v1alpha1.Observation) string {
+	var details []string
+	for _, d := range []struct{ key, value string }{
+		{"reason", o.Reason},
+		{"pod", o.Pod},
+		{"networkPolicy", o.NetworkPolicy},
+		{"translatedSrcIP", o.TranslatedSrcIP},
+		{"translatedDstIP", o.TranslatedDstIP},
+		{"tunnelDstIP", o.TunnelDstIP},
+	} {
+		if d.value != "" {
+			details = append(details, fmt.Sprintf("%s=%s", d.key, d.value))
+		}
+	}
+	if len(details) == 0 {
+		return "-"
+	}
+	return strings.Join(details, ",")
+}
+
+// graphOutput prints the Traceflow result as a graph in DOT format, which can be rendered with Graphviz.
+func graphOutput(tf *v1alpha1.Traceflow, writer io.Writer) error {
+	graph, err := graphviz.GenGraph(tf)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, graph)
+	return err
+}
+
+// imageOutput renders the graph of the Traceflow result to an image with Graphviz, which must be installed.
+func imageOutput(tf *v1alpha1.Traceflow, format graphviz.Format, writer io.Writer) error {
+	graph, err := graphviz.GenGraph(tf)
+	if err != nil {
+		return err
+	}
+	data, err := graphviz.Render(context.TODO(), graph, format)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
 }
 
 func getTFName(prefix string) string {
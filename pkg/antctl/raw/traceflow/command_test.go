@@ -15,6 +15,7 @@
 package traceflow
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -132,3 +133,72 @@ func TestParseFlow(t *testing.T) {
 		}
 	}
 }
+
+// TestTableOutput tests if the observations of a Traceflow are printed as a table.
+func TestTableOutput(t *testing.T) {
+	tf := &v1alpha1.Traceflow{
+		Spec: v1alpha1.TraceflowSpec{
+			Source: v1alpha1.Source{
+				Namespace: "default",
+				Pod:       "pod0",
+			},
+			Destination: v1alpha1.Destination{
+				Namespace: "default",
+				Pod:       "pod1",
+			},
+		},
+		Status: v1alpha1.TraceflowStatus{
+			Phase: v1alpha1.Succeeded,
+			Results: []v1alpha1.NodeResult{
+				{
+					Node: "node0",
+					Role: "Sender",
+					Observations: []v1alpha1.Observation{
+						{
+							Component: v1alpha1.SpoofGuard,
+							Action:    v1alpha1.Forwarded,
+						},
+						{
+							Component:   v1alpha1.Forwarding,
+							Action:      v1alpha1.Forwarded,
+							TunnelDstIP: "192.168.1.2",
+						},
+					},
+				},
+				{
+					Node: "node1",
+					Role: "Receiver",
+					Observations: []v1alpha1.Observation{
+						{
+							Component:     v1alpha1.NetworkPolicy,
+							ComponentInfo: "IngressRule",
+							Action:        v1alpha1.Forwarded,
+							NetworkPolicy: "default/np0",
+						},
+						{
+							Component:     v1alpha1.Forwarding,
+							ComponentInfo: "Output",
+							Action:        v1alpha1.Delivered,
+						},
+					},
+				},
+			},
+		},
+	}
+	tf.Name = "tf0"
+	expected := `name: tf0
+phase: Succeeded
+source: default/pod0
+destination: default/pod1
+
+NODE   ROLE      COMPONENT      COMPONENT-INFO  ACTION     DETAILS
+node0  Sender    SpoofGuard                     Forwarded  -
+node0  Sender    Forwarding                     Forwarded  tunnelDstIP=192.168.1.2
+node1  Receiver  NetworkPolicy  IngressRule     Forwarded  networkPolicy=default/np0
+node1  Receiver  Forwarding     Output          Delivered  -
+`
+	option.outputType = "table"
+	var b bytes.Buffer
+	assert.NoError(t, output(tf, &b))
+	assert.Equal(t, expected, b.String())
+}
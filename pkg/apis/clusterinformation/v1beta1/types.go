@@ -50,6 +50,9 @@ const (
 	ControllerConnectionUp AgentConditionType = "ControllerConnectionUp" // Status True/False is used to mark the connection status between Agent and Controller.
 	OVSDBConnectionUp      AgentConditionType = "OVSDBConnectionUp"      // Status True/False is used to mark OVSDB connection status.
 	OpenflowConnectionUp   AgentConditionType = "OpenflowConnectionUp"   // Status True/False is used to mark Openflow connection status.
+	IPsecPSKRotated        AgentConditionType = "IPsecPSKRotated"        // Status True/False is used to mark whether all the Nodes have loaded the current IPsec PSK. Only set when IPsec is enabled.
+	MemoryPressure         AgentConditionType = "MemoryPressure"         // Status True/False is used to mark whether the Agent is close to its memory limit and has paused non-essential features. Only set when the memory limit is known.
+	DatapathDrained        AgentConditionType = "DatapathDrained"        // Status True/False is used to mark whether the datapath of the Node has been drained and the Node is safe to reboot. Only set when the datapath is in drain mode.
 )
 
 type AgentCondition struct {
@@ -203,6 +203,9 @@ type NetworkPolicyRule struct {
 	// Cannot be set in conjunction with NetworkPolicy.AppliedToGroups of the NetworkPolicy
 	// that this Rule is referred to.
 	AppliedToGroups []string
+	// ConnectionStates restricts the rule to packets of connections in one of these states.
+	// An empty list matches packets of connections in any state.
+	ConnectionStates []secv1alpha1.ConnectionState
 }
 
 // Protocol defines network protocols supported for things like container ports.
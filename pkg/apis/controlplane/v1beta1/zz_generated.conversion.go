@@ -803,6 +803,7 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta1_NetworkPolicyRule(in
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
 	// WARNING: in.AppliedToGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.ConnectionStates requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1818,6 +1818,15 @@ func (m *NetworkPolicyRule) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.ConnectionStates) > 0 {
+		for iNdEx := len(m.ConnectionStates) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ConnectionStates[iNdEx])
+			copy(dAtA[i:], m.ConnectionStates[iNdEx])
+			i = encodeVarintGenerated(dAtA, i, uint64(len(m.ConnectionStates[iNdEx])))
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
 	if len(m.AppliedToGroups) > 0 {
 		for iNdEx := len(m.AppliedToGroups) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.AppliedToGroups[iNdEx])
@@ -2523,6 +2532,12 @@ func (m *NetworkPolicyRule) Size() (n int) {
 			n += 1 + l + sovGenerated(uint64(l))
 		}
 	}
+	if len(m.ConnectionStates) > 0 {
+		for _, s := range m.ConnectionStates {
+			l = len(s)
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2938,6 +2953,7 @@ func (this *NetworkPolicyRule) String() string {
 		`Action:` + valueToStringGenerated(this.Action) + `,`,
 		`EnableLogging:` + fmt.Sprintf("%v", this.EnableLogging) + `,`,
 		`AppliedToGroups:` + fmt.Sprintf("%v", this.AppliedToGroups) + `,`,
+		`ConnectionStates:` + fmt.Sprintf("%v", this.ConnectionStates) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5884,6 +5900,38 @@ func (m *NetworkPolicyRule) Unmarshal(dAtA []byte) error {
 			}
 			m.AppliedToGroups = append(m.AppliedToGroups, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectionStates", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ConnectionStates = append(m.ConnectionStates, github_com_vmware_tanzu_antrea_pkg_apis_security_v1alpha1.ConnectionState(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -210,6 +210,9 @@ type NetworkPolicyRule struct {
 	// Cannot be set in conjunction with NetworkPolicy.AppliedToGroups of the NetworkPolicy
 	// that this Rule is referred to.
 	AppliedToGroups []string `json:"appliedToGroups,omitempty" protobuf:"bytes,8,opt,name=appliedToGroups"`
+	// ConnectionStates restricts the rule to packets of connections in one of these states.
+	// An empty list matches packets of connections in any state.
+	ConnectionStates []secv1alpha1.ConnectionState `json:"connectionStates,omitempty" protobuf:"bytes,9,rep,name=connectionStates,casttype=github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1.ConnectionState"`
 }
 
 // Protocol defines network protocols supported for things like container ports.
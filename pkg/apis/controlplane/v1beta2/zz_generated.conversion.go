@@ -748,6 +748,7 @@ func autoConvert_v1beta2_NetworkPolicyRule_To_controlplane_NetworkPolicyRule(in
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
+	out.ConnectionStates = *(*[]v1alpha1.ConnectionState)(unsafe.Pointer(&in.ConnectionStates))
 	return nil
 }
 
@@ -769,6 +770,7 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta2_NetworkPolicyRule(in
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
+	out.ConnectionStates = *(*[]v1alpha1.ConnectionState)(unsafe.Pointer(&in.ConnectionStates))
 	return nil
 }
 
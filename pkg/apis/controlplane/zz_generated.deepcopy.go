@@ -623,6 +623,11 @@ func (in *NetworkPolicyRule) DeepCopyInto(out *NetworkPolicyRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConnectionStates != nil {
+		in, out := &in.ConnectionStates, &out.ConnectionStates
+		*out = make([]v1alpha1.ConnectionState, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -48,6 +48,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ExternalEntityList{},
 		&ClusterGroup{},
 		&ClusterGroupList{},
+		&Egress{},
+		&EgressList{},
+		&BandwidthQuota{},
+		&BandwidthQuotaList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -110,6 +110,29 @@ type GroupSpec struct {
 	// Cannot be set with any other selector.
 	// +optional
 	IPBlock *secv1a1.IPBlock `json:"ipBlock,omitempty"`
+	// Select the ClusterIP of the referred Service and the Pods in its
+	// Endpoints, whether they are ready or not. The members are updated
+	// when the ClusterIP or the Endpoints of the Service change.
+	// Cannot be set with any other selector or ipBlock.
+	// +optional
+	ServiceReference *ServiceReference `json:"serviceReference,omitempty"`
+	// Select the members of the referred ClusterGroups, i.e. the union of
+	// their members. The child ClusterGroups cannot have childGroups or
+	// ipBlock themselves.
+	// Cannot be set with any other selector, ipBlock or serviceReference.
+	// +optional
+	ChildGroups []ClusterGroupReference `json:"childGroups,omitempty"`
+}
+
+// ClusterGroupReference represents a reference to a ClusterGroup by name.
+type ClusterGroupReference string
+
+// ServiceReference represents a reference to a v1.Service.
+type ServiceReference struct {
+	// Name of the Service.
+	Name string `json:"name"`
+	// Namespace of the Service.
+	Namespace string `json:"namespace"`
 }
 
 type GroupConditionType string
@@ -136,3 +159,92 @@ type ClusterGroupList struct {
 
 	Items []ClusterGroup `json:"items,omitempty"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Egress defines which egress (SNAT) IP the traffic from the selected Pods to
+// the external network should use.
+type Egress struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard metadata of the object.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior of Egress.
+	Spec EgressSpec `json:"spec"`
+}
+
+// EgressSpec defines the desired state for Egress.
+type EgressSpec struct {
+	// AppliedTo selects Pods to which the Egress will be applied.
+	AppliedTo AppliedTo `json:"appliedTo"`
+	// EgressIP specifies the SNAT IP address for the selected workloads.
+	// The IP must be assigned to an interface of one of the Nodes, which
+	// is called the egress Node of the Egress.
+	EgressIP string `json:"egressIP"`
+}
+
+// AppliedTo selects the entities to which a policy is applied.
+type AppliedTo struct {
+	// Select Pods matched by this selector. If set with NamespaceSelector,
+	// Pods are matched from Namespaces matched by the NamespaceSelector;
+	// otherwise, Pods are matched from all Namespaces.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Select all Pods from Namespaces matched by this selector. If set with
+	// PodSelector, Pods are matched from Namespaces matched by the
+	// NamespaceSelector.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type EgressList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Egress `json:"items,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BandwidthQuota limits the aggregate bandwidth of the traffic sent by the
+// Pods of a tenant, i.e. of a set of Namespaces.
+type BandwidthQuota struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard metadata of the object.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior of BandwidthQuota.
+	Spec BandwidthQuotaSpec `json:"spec"`
+}
+
+// BandwidthQuotaSpec defines the desired state for BandwidthQuota.
+type BandwidthQuotaSpec struct {
+	// Select the Namespaces of the tenant. The quota applies to all the Pods
+	// of these Namespaces. A Namespace selected by several BandwidthQuotas
+	// only gets the one which comes first in alphabetical order of names.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+	// Egress is the maximum aggregate bandwidth of the traffic sent by the
+	// Pods of the tenant running on the same Node, in bits per second, e.g.
+	// "100M". It uses the same format and bounds as the
+	// kubernetes.io/egress-bandwidth annotation.
+	Egress string `json:"egress"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type BandwidthQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []BandwidthQuota `json:"items,omitempty"`
+}
@@ -44,6 +44,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		SchemeGroupVersion,
 		&Traceflow{},
 		&TraceflowList{},
+		&TraceflowSet{},
+		&TraceflowSetList{},
 	)
 
 	metav1.AddToGroupVersion(
@@ -35,6 +35,9 @@ const (
 	Routing       TraceflowComponent = "Routing"
 	NetworkPolicy TraceflowComponent = "NetworkPolicy"
 	Forwarding    TraceflowComponent = "Forwarding"
+	// ReplyPath is the return path of the echo reply to an ICMP echo request,
+	// which is checked by the destination Node.
+	ReplyPath TraceflowComponent = "ReplyPath"
 )
 
 type TraceflowAction string
@@ -44,6 +47,9 @@ const (
 	Received  TraceflowAction = "Received"
 	Forwarded TraceflowAction = "Forwarded"
 	Dropped   TraceflowAction = "Dropped"
+	// ForwardedOutOfOverlay indicates that the packet has been forwarded out of
+	// the overlay network, e.g. to a destination outside the cluster.
+	ForwardedOutOfOverlay TraceflowAction = "ForwardedOutOfOverlay"
 )
 
 // List the supported protocols and their codes in traceflow.
@@ -153,7 +159,13 @@ type TransportHeader struct {
 }
 
 // ICMPEchoRequestHeader describes spec of an ICMP echo request header.
+// Other ICMP messages can be sent by setting Type and Code.
 type ICMPEchoRequestHeader struct {
+	// Type is the ICMP type. It defaults to echo request (8 for ICMP, 128 for
+	// ICMPv6) when not set.
+	Type *int32 `json:"type,omitempty"`
+	// Code is the ICMP code.
+	Code int32 `json:"code,omitempty"`
 	// ID is the ICMPEchoRequestHeader ID.
 	ID int32 `json:"id,omitempty"`
 	// Sequence is the ICMPEchoRequestHeader sequence.
@@ -217,6 +229,8 @@ type Observation struct {
 	ComponentInfo string `json:"componentInfo,omitempty" yaml:"componentInfo,omitempty"`
 	// Action is the action to the observation.
 	Action TraceflowAction `json:"action,omitempty" yaml:"action,omitempty"`
+	// Reason is the reason of the action, e.g. why the packet is dropped.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
 	// Pod is the combination of Pod name and Pod Namespace.
 	Pod string `json:"pod,omitempty" yaml:"pod,omitempty"`
 	// DstMAC is the destination MAC.
@@ -240,3 +254,83 @@ type TraceflowList struct {
 
 	Items []Traceflow `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TraceflowSet traces the packets between all the pairs of selected source and destination Pods, by
+// creating one Traceflow per pair, and aggregates the results of the Traceflows in its status.
+type TraceflowSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TraceflowSetSpec   `json:"spec,omitempty"`
+	Status TraceflowSetStatus `json:"status,omitempty"`
+}
+
+// TraceflowSetSpec describes the spec of the TraceflowSet.
+type TraceflowSetSpec struct {
+	// Source selects the source Pods of the Traceflows.
+	Source PodSelection `json:"source,omitempty"`
+	// Destination selects the destination Pods of the Traceflows.
+	Destination PodSelection `json:"destination,omitempty"`
+	// Packet is the packet sent by all the Traceflows.
+	Packet Packet `json:"packet,omitempty"`
+	// MaxConcurrentTraceflows is the maximum number of Traceflows of the TraceflowSet which run at
+	// the same time. Defaults to 4.
+	MaxConcurrentTraceflows int32 `json:"maxConcurrentTraceflows,omitempty"`
+}
+
+// PodSelection selects Pods by the labels of the Pods and of their Namespaces.
+type PodSelection struct {
+	// NamespaceSelector selects the Namespaces of the Pods. Pods are selected from all Namespaces if
+	// it is not set.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// PodSelector selects the Pods in the selected Namespaces. All Pods are selected if it is not
+	// set.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// TraceflowSetStatus describes the current status of the TraceflowSet.
+type TraceflowSetStatus struct {
+	// Phase is the TraceflowSet phase. A TraceflowSet has Succeeded once all its Traceflows are
+	// completed, whatever their results.
+	Phase TraceflowPhase `json:"phase,omitempty"`
+	// Reason is a message indicating the reason of the TraceflowSet's current phase.
+	Reason string `json:"reason,omitempty"`
+	// Delivered is the number of pairs whose packet was delivered to the destination.
+	Delivered int32 `json:"delivered"`
+	// Dropped is the number of pairs whose packet was dropped.
+	Dropped int32 `json:"dropped"`
+	// Failed is the number of pairs whose Traceflow failed.
+	Failed int32 `json:"failed"`
+	// Results is the matrix of the results of all the pairs of source and destination Pods.
+	Results []TraceflowSetResult `json:"results,omitempty"`
+}
+
+// TraceflowSetResult describes the result of the Traceflow of a pair of source and destination Pods.
+type TraceflowSetResult struct {
+	// Source is the source Pod, as namespace/name.
+	Source string `json:"source"`
+	// Destination is the destination Pod, as namespace/name.
+	Destination string `json:"destination"`
+	// Traceflow is the name of the Traceflow of the pair, once created.
+	Traceflow string `json:"traceflow,omitempty"`
+	// Phase is the phase of the Traceflow.
+	Phase TraceflowPhase `json:"phase,omitempty"`
+	// Action is the last action applied to the packet, e.g. Delivered or Dropped, once the
+	// Traceflow has succeeded.
+	Action TraceflowAction `json:"action,omitempty"`
+	// Reason is the reason of the Traceflow failure, or of the packet drop, e.g. the NetworkPolicy
+	// dropping the packet.
+	Reason string `json:"reason,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TraceflowSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TraceflowSet `json:"items"`
+}
@@ -19,6 +19,7 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -41,6 +42,11 @@ func (in *Destination) DeepCopy() *Destination {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ICMPEchoRequestHeader) DeepCopyInto(out *ICMPEchoRequestHeader) {
 	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -151,6 +157,32 @@ func (in *Packet) DeepCopy() *Packet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSelection) DeepCopyInto(out *PodSelection) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSelection.
+func (in *PodSelection) DeepCopy() *PodSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Source) DeepCopyInto(out *Source) {
 	*out = *in
@@ -244,6 +276,123 @@ func (in *TraceflowList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSet) DeepCopyInto(out *TraceflowSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSet.
+func (in *TraceflowSet) DeepCopy() *TraceflowSet {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TraceflowSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSetList) DeepCopyInto(out *TraceflowSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TraceflowSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSetList.
+func (in *TraceflowSetList) DeepCopy() *TraceflowSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TraceflowSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSetResult) DeepCopyInto(out *TraceflowSetResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSetResult.
+func (in *TraceflowSetResult) DeepCopy() *TraceflowSetResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSetResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSetSpec) DeepCopyInto(out *TraceflowSetSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	in.Destination.DeepCopyInto(&out.Destination)
+	in.Packet.DeepCopyInto(&out.Packet)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSetSpec.
+func (in *TraceflowSetSpec) DeepCopy() *TraceflowSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSetStatus) DeepCopyInto(out *TraceflowSetStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]TraceflowSetResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSetStatus.
+func (in *TraceflowSetStatus) DeepCopy() *TraceflowSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TraceflowSpec) DeepCopyInto(out *TraceflowSpec) {
 	*out = *in
@@ -292,7 +441,7 @@ func (in *TransportHeader) DeepCopyInto(out *TransportHeader) {
 	if in.ICMP != nil {
 		in, out := &in.ICMP, &out.ICMP
 		*out = new(ICMPEchoRequestHeader)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.UDP != nil {
 		in, out := &in.UDP, &out.UDP
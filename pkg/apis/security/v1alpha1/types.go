@@ -116,6 +116,41 @@ type Rule struct {
 	// conjunction with NetworkPolicySpec/ClusterNetworkPolicySpec.AppliedTo.
 	// +optional
 	AppliedTo []NetworkPolicyPeer `json:"appliedTo,omitempty"`
+	// Schedule restricts the rule to some time windows. Outside of these
+	// windows, the rule is not realized, as if it was not part of the policy.
+	// If this field is unset, the rule is always active.
+	// +optional
+	Schedule *RuleSchedule `json:"schedule,omitempty"`
+	// ConnectionStates restricts the rule to packets of connections in one
+	// of these states. If this field is unset or empty, the rule matches
+	// packets of connections in any state.
+	// +optional
+	ConnectionStates []ConnectionState `json:"connectionStates,omitempty"`
+}
+
+// RuleSchedule describes the time windows during which a rule is active.
+type RuleSchedule struct {
+	// Windows during which the rule is active. The rule is active if the
+	// current time is in at least one of them.
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is a daily time window, which can be restricted to some days
+// of the week like the hour, minute and day-of-week fields of a cron schedule.
+// Times are in UTC.
+type ScheduleWindow struct {
+	// Days of the week on which the window opens, as "Mon", "Tue", "Wed",
+	// "Thu", "Fri", "Sat" or "Sun". If this field is empty, the window opens
+	// every day.
+	// +optional
+	DaysOfWeek []string `json:"daysOfWeek,omitempty"`
+	// Start is the time at which the window opens, in "HH:MM" format.
+	Start string `json:"start"`
+	// End is the time at which the window closes, in "HH:MM" format. If End
+	// is not after Start, the window closes on the next day, e.g. a window
+	// from "22:00" to "02:00" lasts 4 hours, and a window from "00:00" to
+	// "00:00" lasts a whole day.
+	End string `json:"end"`
 }
 
 // NetworkPolicyPeer describes the grouping selector of workloads.
@@ -185,6 +220,22 @@ const (
 	RuleActionAllow RuleAction = "Allow"
 	// RuleActionDrop describes that rule matching traffic must be dropped.
 	RuleActionDrop RuleAction = "Drop"
+	// RuleActionReject describes that rule matching traffic must be rejected: the traffic is dropped and a TCP RST
+	// or an ICMP port unreachable message is sent back to the source.
+	RuleActionReject RuleAction = "Reject"
+)
+
+// ConnectionState describes the conntrack state of the connection of a packet.
+type ConnectionState string
+
+const (
+	// ConnectionStateNew describes the packets starting a new connection.
+	ConnectionStateNew ConnectionState = "New"
+	// ConnectionStateEstablished describes the packets of a connection which has seen packets in both directions.
+	ConnectionStateEstablished ConnectionState = "Established"
+	// ConnectionStateRelated describes the packets starting a new connection related to an existing one, e.g. an ICMP
+	// error message or an FTP data connection.
+	ConnectionStateRelated ConnectionState = "Related"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -37,8 +37,11 @@ import (
 	systeminstall "github.com/vmware-tanzu/antrea/pkg/apis/system/install"
 	system "github.com/vmware-tanzu/antrea/pkg/apis/system/v1beta1"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/certificate"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/baselinestatus"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/endpoint"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/loglevel"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/servicetopology"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/span"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/webhook"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/registry/controlplane/nodestatssummary"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/registry/networkpolicy/addressgroup"
@@ -55,7 +58,9 @@ import (
 	controllernetworkpolicy "github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
 	"github.com/vmware-tanzu/antrea/pkg/controller/querier"
 	"github.com/vmware-tanzu/antrea/pkg/controller/stats"
+	"github.com/vmware-tanzu/antrea/pkg/controller/traceflow"
 	"github.com/vmware-tanzu/antrea/pkg/features"
+	"github.com/vmware-tanzu/antrea/pkg/graphviz"
 )
 
 var (
@@ -66,6 +71,15 @@ var (
 	Codecs = serializer.NewCodecFactory(Scheme)
 	// #nosec G101: false positive triggered by variable name which includes "token"
 	TokenPath = "/var/run/antrea/apiserver/loopback-client-token"
+	// DebugAPIPaths are the paths of the APIs exposing information about the cluster network for
+	// troubleshooting, whose requests are rate limited and audited.
+	DebugAPIPaths = []string{
+		"/endpoint",
+		"/servicetopology",
+		"/span",
+		"/traceflowgraph",
+		"/apis/" + system.GroupName + "/v1beta1/supportbundles",
+	}
 )
 
 func init() {
@@ -84,10 +98,14 @@ type ExtraConfig struct {
 	networkPolicyStore            storage.Interface
 	controllerQuerier             querier.ControllerQuerier
 	endpointQuerier               controllernetworkpolicy.EndpointQuerier
+	serviceTopologyQuerier        controllernetworkpolicy.ServiceTopologyQuerier
+	spanQuerier                   controllernetworkpolicy.SpanQuerier
 	networkPolicyController       *controllernetworkpolicy.NetworkPolicyController
 	caCertController              *certificate.CACertController
 	statsAggregator               *stats.Aggregator
 	networkPolicyStatusController *controllernetworkpolicy.StatusController
+	traceflowValidator            *traceflow.Validator
+	traceflowGetter               graphviz.TraceflowGetter
 }
 
 // Config defines the config for Antrea apiserver.
@@ -104,11 +122,21 @@ type APIServer struct {
 
 func (s *APIServer) Run(stopCh <-chan struct{}) error {
 	// Make sure CACertController runs once to publish the CA cert before starting APIServer.
+	s.RunCACertController(stopCh)
+	return s.RunServer(stopCh)
+}
+
+// RunCACertController publishes the CA cert once, and then keeps it published until stopCh is closed.
+func (s *APIServer) RunCACertController(stopCh <-chan struct{}) {
 	if err := s.caCertController.RunOnce(); err != nil {
 		klog.Warningf("caCertController RunOnce failed: %v", err)
 	}
 	go s.caCertController.Run(1, stopCh)
+}
 
+// RunServer runs APIServer without publishing the CA cert. It's used when the CA cert must only be published by
+// the leader of multiple antrea-controller replicas, which calls RunCACertController once elected.
+func (s *APIServer) RunServer(stopCh <-chan struct{}) error {
 	return s.GenericAPIServer.PrepareRun().Run(stopCh)
 }
 
@@ -125,7 +153,11 @@ func NewConfig(
 	controllerQuerier querier.ControllerQuerier,
 	networkPolicyStatusController *controllernetworkpolicy.StatusController,
 	endpointQuerier controllernetworkpolicy.EndpointQuerier,
-	npController *controllernetworkpolicy.NetworkPolicyController) *Config {
+	serviceTopologyQuerier controllernetworkpolicy.ServiceTopologyQuerier,
+	spanQuerier controllernetworkpolicy.SpanQuerier,
+	npController *controllernetworkpolicy.NetworkPolicyController,
+	traceflowValidator *traceflow.Validator,
+	traceflowGetter graphviz.TraceflowGetter) *Config {
 	return &Config{
 		genericConfig: genericConfig,
 		extraConfig: ExtraConfig{
@@ -136,8 +168,12 @@ func NewConfig(
 			statsAggregator:               statsAggregator,
 			controllerQuerier:             controllerQuerier,
 			endpointQuerier:               endpointQuerier,
+			serviceTopologyQuerier:        serviceTopologyQuerier,
+			spanQuerier:                   spanQuerier,
 			networkPolicyController:       npController,
 			networkPolicyStatusController: networkPolicyStatusController,
+			traceflowValidator:            traceflowValidator,
+			traceflowGetter:               traceflowGetter,
 		},
 	}
 }
@@ -246,6 +282,9 @@ func CleanupDeprecatedAPIServices(aggregatorClient clientset.Interface) error {
 func installHandlers(c *ExtraConfig, s *genericapiserver.GenericAPIServer) {
 	s.Handler.NonGoRestfulMux.HandleFunc("/loglevel", loglevel.HandleFunc())
 	s.Handler.NonGoRestfulMux.HandleFunc("/endpoint", endpoint.HandleFunc(c.endpointQuerier))
+	s.Handler.NonGoRestfulMux.HandleFunc("/servicetopology", servicetopology.HandleFunc(c.serviceTopologyQuerier))
+	s.Handler.NonGoRestfulMux.HandleFunc("/span", span.HandleFunc(c.spanQuerier))
+	s.Handler.NonGoRestfulMux.HandleFunc("/baselinestatus", baselinestatus.HandleFunc(c.networkPolicyController))
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		// Get new NetworkPolicyMutator
 		m := controllernetworkpolicy.NewNetworkPolicyMutator(c.networkPolicyController)
@@ -266,4 +305,10 @@ func installHandlers(c *ExtraConfig, s *genericapiserver.GenericAPIServer) {
 			return nil
 		})
 	}
+	// The Traceflow validating webhook is registered regardless of the Traceflow feature gate, so the handler must
+	// always be installed, otherwise the admission of Traceflows would fail.
+	s.Handler.NonGoRestfulMux.HandleFunc("/validate/traceflow", webhook.HandleValidationTraceflow(c.traceflowValidator))
+	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
+		s.Handler.NonGoRestfulMux.HandleFunc("/traceflowgraph", graphviz.NewTraceflowGraphHandler(c.traceflowGetter))
+	}
 }
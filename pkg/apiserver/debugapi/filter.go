@@ -0,0 +1,230 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debugapi limits the rate of the requests to the debug APIs of
+// antrea-agent and antrea-controller, e.g. the APIs dumping OVS flows or
+// collecting support bundles, and records these requests in an audit log, as
+// they expose sensitive information about the network.
+package debugapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog"
+)
+
+const (
+	// Default rate limits of the requests to the debug APIs.
+	DefaultQPS          = 10
+	DefaultBurst        = 20
+	DefaultPerUserQPS   = 2
+	DefaultPerUserBurst = 10
+)
+
+const (
+	// anonymousUser is the identity of the requests without authenticated user.
+	anonymousUser = "system:anonymous"
+	// retryAfterSeconds is the value of the Retry-After header of the rejected requests.
+	retryAfterSeconds = "1"
+
+	auditLogMaxSizeMB  = 100
+	auditLogMaxBackups = 3
+)
+
+// Config configures the rate limiting and the audit log of the debug APIs.
+type Config struct {
+	// QPS and Burst limit the rate of all the requests to the debug APIs. The rate is not limited if
+	// QPS is not positive.
+	QPS   float32
+	Burst int
+	// PerUserQPS and PerUserBurst limit the rate of the requests to the debug APIs of each
+	// authenticated user. The rate is not limited if PerUserQPS is not positive.
+	PerUserQPS   float32
+	PerUserBurst int
+	// AuditLogFile is the path of the file to which the audit records are written. The records are
+	// written to the log of the component if empty.
+	AuditLogFile string
+}
+
+// auditRecord is the audit log entry of a request to a debug API.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Groups   []string  `json:"groups,omitempty"`
+	SourceIP string    `json:"sourceIP"`
+	Method   string    `json:"method"`
+	URI      string    `json:"uri"`
+	Code     int       `json:"code"`
+	Latency  string    `json:"latency"`
+}
+
+// Filter limits the rate of the requests to the debug APIs and records them in an audit log.
+type Filter struct {
+	// paths are the paths of the debug APIs, which include their sub-paths.
+	paths        []string
+	limiter      *rate.Limiter
+	perUserQPS   float32
+	perUserBurst int
+	// userLimiters are the rate limiters of the users, indexed by user name. The number of users
+	// accessing the debug APIs is expected to be small, hence the limiters are never removed.
+	userLimiters map[string]*rate.Limiter
+	mutex        sync.Mutex
+	// auditWriter is the writer of the audit records, or nil if the records are logged with klog.
+	auditWriter io.Writer
+	auditMutex  sync.Mutex
+}
+
+// NewFilter returns a Filter applying config to the requests to the provided paths and their
+// sub-paths.
+func NewFilter(paths []string, config Config) *Filter {
+	f := &Filter{
+		paths:        paths,
+		perUserQPS:   config.PerUserQPS,
+		perUserBurst: config.PerUserBurst,
+		userLimiters: map[string]*rate.Limiter{},
+	}
+	if config.QPS > 0 {
+		f.limiter = rate.NewLimiter(rate.Limit(config.QPS), config.Burst)
+	}
+	if config.AuditLogFile != "" {
+		f.auditWriter = &lumberjack.Logger{
+			Filename:   config.AuditLogFile,
+			MaxSize:    auditLogMaxSizeMB,
+			MaxBackups: auditLogMaxBackups,
+			Compress:   true,
+		}
+	}
+	return f
+}
+
+// Wrap returns a handler which applies the Filter to the debug API requests before passing them to
+// handler. It must be called within the authentication filter, so that the users of the requests
+// are known.
+func (f *Filter) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !f.isDebugAPI(r.URL.Path) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		user, groups := anonymousUser, []string(nil)
+		if u, ok := request.UserFrom(r.Context()); ok {
+			user, groups = u.GetName(), u.GetGroups()
+		}
+		recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		if f.allow(user) {
+			handler.ServeHTTP(recorder, r)
+		} else {
+			w.Header().Set("Retry-After", retryAfterSeconds)
+			http.Error(w, "too many requests to the debug APIs, please try again later", http.StatusTooManyRequests)
+			recorder.code = http.StatusTooManyRequests
+		}
+		f.audit(&auditRecord{
+			Time:     start,
+			User:     user,
+			Groups:   groups,
+			SourceIP: r.RemoteAddr,
+			Method:   r.Method,
+			URI:      r.URL.RequestURI(),
+			Code:     recorder.code,
+			Latency:  time.Since(start).String(),
+		})
+	})
+}
+
+func (f *Filter) isDebugAPI(path string) bool {
+	for _, p := range f.paths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// allow returns whether a request of the user is allowed by both the global and the per-user rate
+// limits. A token is reserved from each limiter, and the reservations are cancelled if either limiter
+// rejects the request, so that a rejected request does not consume the quota of the other limiter.
+func (f *Filter) allow(user string) bool {
+	var userLimiter *rate.Limiter
+	if f.perUserQPS > 0 {
+		f.mutex.Lock()
+		var ok bool
+		userLimiter, ok = f.userLimiters[user]
+		if !ok {
+			userLimiter = rate.NewLimiter(rate.Limit(f.perUserQPS), f.perUserBurst)
+			f.userLimiters[user] = userLimiter
+		}
+		f.mutex.Unlock()
+	}
+	now := time.Now()
+	var reservations []*rate.Reservation
+	for _, limiter := range []*rate.Limiter{f.limiter, userLimiter} {
+		if limiter == nil {
+			continue
+		}
+		r := limiter.ReserveN(now, 1)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			r.CancelAt(now)
+			for _, reserved := range reservations {
+				reserved.CancelAt(now)
+			}
+			return false
+		}
+		reservations = append(reservations, r)
+	}
+	return true
+}
+
+func (f *Filter) audit(record *auditRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		klog.Errorf("Error when encoding debug API audit record: %v", err)
+		return
+	}
+	if f.auditWriter == nil {
+		klog.Infof("Debug API audit: %s", data)
+		return
+	}
+	f.auditMutex.Lock()
+	defer f.auditMutex.Unlock()
+	if _, err := f.auditWriter.Write(append(data, '\n')); err != nil {
+		klog.Errorf("Error when writing debug API audit record: %v", err)
+	}
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher, as the support bundles are streamed to the clients.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
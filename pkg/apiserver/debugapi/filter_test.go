@@ -0,0 +1,108 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestFilter(t *testing.T) {
+	f := NewFilter([]string{"/ovsflows", "/apis/system.antrea.tanzu.vmware.com"}, Config{
+		QPS:          0.001,
+		Burst:        3,
+		PerUserQPS:   0.001,
+		PerUserBurst: 2,
+	})
+	var auditLog bytes.Buffer
+	f.auditWriter = &auditLog
+	handler := f.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(path, userName string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: userName}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// The requests to the other APIs are neither limited nor audited.
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, serve("/healthz", "user1"))
+	}
+	assert.Equal(t, 0, auditLog.Len())
+
+	assert.Equal(t, http.StatusOK, serve("/ovsflows?pod=pod1", "user1"))
+	assert.Equal(t, http.StatusOK, serve("/apis/system.antrea.tanzu.vmware.com/v1beta1/supportbundles/agent", "user1"))
+	// The quota of user1 is exhausted.
+	assert.Equal(t, http.StatusTooManyRequests, serve("/ovsflows", "user1"))
+	assert.Equal(t, http.StatusOK, serve("/ovsflows", "user2"))
+	// The global quota is exhausted.
+	assert.Equal(t, http.StatusTooManyRequests, serve("/ovsflows", "user3"))
+
+	lines := strings.Split(strings.TrimSpace(auditLog.String()), "\n")
+	require.Equal(t, 5, len(lines))
+	var record auditRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "user1", record.User)
+	assert.Equal(t, http.MethodGet, record.Method)
+	assert.Equal(t, "/ovsflows?pod=pod1", record.URI)
+	assert.Equal(t, http.StatusOK, record.Code)
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+	assert.Equal(t, http.StatusTooManyRequests, record.Code)
+}
+
+func TestFilterRejectedRequestsDoNotConsumeQuota(t *testing.T) {
+	f := NewFilter([]string{"/ovsflows"}, Config{
+		QPS:          0.001,
+		Burst:        2,
+		PerUserQPS:   0.001,
+		PerUserBurst: 2,
+	})
+	f.auditWriter = &bytes.Buffer{}
+	handler := f.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(userName string) int {
+		req := httptest.NewRequest(http.MethodGet, "/ovsflows", nil)
+		req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: userName}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("user1"))
+	assert.Equal(t, http.StatusOK, serve("user2"))
+	// The global quota is exhausted, the quota of user1 must not be consumed.
+	assert.Equal(t, http.StatusTooManyRequests, serve("user1"))
+	f.limiter = rate.NewLimiter(rate.Limit(0.001), 2)
+	assert.Equal(t, http.StatusOK, serve("user1"))
+	// The quota of user1 is exhausted, the global quota must not be consumed.
+	assert.Equal(t, http.StatusTooManyRequests, serve("user1"))
+	assert.Equal(t, http.StatusOK, serve("user3"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("user4"))
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baselinestatus
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+)
+
+// HandleFunc creates a http.HandlerFunc which uses a BaselineStatusQuerier to
+// report the baseline mode of a Namespace and the violations of its baseline
+// Antrea NetworkPolicies observed in a window, which defaults to the maximum
+// window.
+func HandleFunc(q networkpolicy.BaselineStatusQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace := r.URL.Query().Get("namespace")
+		if namespace == "" {
+			http.Error(w, "namespace must be provided", http.StatusBadRequest)
+			return
+		}
+		window := networkpolicy.MaxBaselineStatusWindow
+		if wd := r.URL.Query().Get("window"); wd != "" {
+			var err error
+			if window, err = time.ParseDuration(wd); err != nil {
+				http.Error(w, "invalid window "+strconv.Quote(wd), http.StatusBadRequest)
+				return
+			}
+		}
+		status, err := q.QueryBaselineStatus(namespace, window)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if status == nil {
+			http.Error(w, "could not find Namespace "+namespace, http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(*status); err != nil {
+			http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
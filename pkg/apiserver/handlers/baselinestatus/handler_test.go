@@ -0,0 +1,102 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baselinestatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	queriermock "github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/testing"
+)
+
+func TestBaselineStatusQuery(t *testing.T) {
+	status := &networkpolicy.BaselineStatus{
+		Namespace:     "ns1",
+		Mode:          networkpolicy.BaselineModeObserve,
+		ObservedSince: "2021-03-01T10:00:00Z",
+		Window:        "2h0m0s",
+		Violations:    3,
+	}
+	tests := []struct {
+		name             string
+		query            string
+		expectedWindow   time.Duration
+		queryResponse    *networkpolicy.BaselineStatus
+		queryErr         error
+		expectedStatus   int
+		expectedResponse *networkpolicy.BaselineStatus
+	}{
+		{
+			name:             "found",
+			query:            "?namespace=ns1&window=2h",
+			expectedWindow:   2 * time.Hour,
+			queryResponse:    status,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: status,
+		},
+		{
+			name:           "default-window",
+			query:          "?namespace=ns1",
+			expectedWindow: networkpolicy.MaxBaselineStatusWindow,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "no-namespace",
+			query:          "?window=2h",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid-window",
+			query:          "?namespace=ns1&window=2",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "window-too-long",
+			query:          "?namespace=ns1&window=48h",
+			expectedWindow: 48 * time.Hour,
+			queryErr:       fmt.Errorf("window must be between 0 and 24h0m0s"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			q := queriermock.NewMockBaselineStatusQuerier(mockCtrl)
+			if tt.expectedWindow != 0 {
+				q.EXPECT().QueryBaselineStatus("ns1", tt.expectedWindow).Return(tt.queryResponse, tt.queryErr)
+			}
+			req, err := http.NewRequest(http.MethodGet, tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			HandleFunc(q).ServeHTTP(recorder, req)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedResponse != nil {
+				var received networkpolicy.BaselineStatus
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+				assert.Equal(t, *tt.expectedResponse, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicetopology
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+)
+
+// HandleFunc creates a http.HandlerFunc which uses a ServiceTopologyQuerier to
+// report the distribution of the endpoints of a Service across zones and Nodes,
+// and whether the NetworkPolicies applied to them allow traffic to them.
+func HandleFunc(q networkpolicy.ServiceTopologyQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serviceName := r.URL.Query().Get("service")
+		namespace := r.URL.Query().Get("namespace")
+		port := r.URL.Query().Get("port")
+		if namespace == "" {
+			namespace = "default"
+		}
+		if serviceName == "" {
+			http.Error(w, "service must be provided", http.StatusBadRequest)
+			return
+		}
+		response, err := q.QueryServiceTopology(namespace, serviceName, port)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if response == nil {
+			http.Error(w, "could not find Service "+namespace+"/"+serviceName, http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(*response); err != nil {
+			http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
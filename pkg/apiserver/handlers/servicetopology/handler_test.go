@@ -0,0 +1,98 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicetopology
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	queriermock "github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/testing"
+)
+
+func TestServiceTopologyQuery(t *testing.T) {
+	response := &networkpolicy.ServiceTopologyResponse{
+		Namespace: "ns1",
+		Name:      "svc1",
+		Port:      "http",
+		Zones: []networkpolicy.ZoneTopology{
+			{Zone: "zone1", Endpoints: 1, ReachableEndpoints: 0},
+		},
+		Warnings: []string{"All 1 endpoints in zone zone1 are denied by NetworkPolicies"},
+	}
+	tests := []struct {
+		name             string
+		query            string
+		expectedArgs     []string
+		queryResponse    *networkpolicy.ServiceTopologyResponse
+		queryErr         error
+		expectedStatus   int
+		expectedResponse *networkpolicy.ServiceTopologyResponse
+	}{
+		{
+			name:             "found",
+			query:            "?namespace=ns1&service=svc1&port=http",
+			expectedArgs:     []string{"ns1", "svc1", "http"},
+			queryResponse:    response,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: response,
+		},
+		{
+			name:           "default-namespace",
+			query:          "?service=svc1",
+			expectedArgs:   []string{"default", "svc1", ""},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "no-service",
+			query:          "?namespace=ns1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid-port",
+			query:          "?namespace=ns1&service=svc1&port=foo",
+			expectedArgs:   []string{"ns1", "svc1", "foo"},
+			queryErr:       fmt.Errorf("Service ns1/svc1 has no port foo"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			q := queriermock.NewMockServiceTopologyQuerier(mockCtrl)
+			if tt.expectedArgs != nil {
+				q.EXPECT().QueryServiceTopology(tt.expectedArgs[0], tt.expectedArgs[1], tt.expectedArgs[2]).Return(tt.queryResponse, tt.queryErr)
+			}
+			req, err := http.NewRequest(http.MethodGet, tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			HandleFunc(q).ServeHTTP(recorder, req)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedResponse != nil {
+				var received networkpolicy.ServiceTopologyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+				assert.Equal(t, *tt.expectedResponse, received)
+			}
+		})
+	}
+}
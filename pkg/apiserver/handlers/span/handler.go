@@ -0,0 +1,66 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package span
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	"github.com/vmware-tanzu/antrea/pkg/querier"
+)
+
+// From user shorthand input to cpv1beta.NetworkPolicyType.
+var mapToNetworkPolicyType = map[string]cpv1beta.NetworkPolicyType{
+	"NP":    cpv1beta.K8sNetworkPolicy,
+	"K8SNP": cpv1beta.K8sNetworkPolicy,
+	"ACNP":  cpv1beta.AntreaClusterNetworkPolicy,
+	"ANP":   cpv1beta.AntreaNetworkPolicy,
+}
+
+// HandleFunc creates a http.HandlerFunc which uses a SpanQuerier to report
+// the internal NetworkPolicies, the AppliedToGroups and AddressGroups they
+// refer to, and the Nodes to which each of them is sent.
+func HandleFunc(q networkpolicy.SpanQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		strSourceType := strings.ToUpper(query.Get("type"))
+		sourceType, ok := mapToNetworkPolicyType[strSourceType]
+		if strSourceType != "" && !ok {
+			http.Error(w, "invalid reference type. It should be K8sNP, ACNP or ANP", http.StatusBadRequest)
+			return
+		}
+		filter := &querier.NetworkPolicyQueryFilter{
+			Name:       query.Get("name"),
+			SourceName: query.Get("source"),
+			Namespace:  query.Get("namespace"),
+			SourceType: sourceType,
+		}
+		if filter.Name != "" && (filter.SourceName != "" || filter.Namespace != "" || filter.SourceType != "") {
+			http.Error(w, "with a name, none of the other fields can be set", http.StatusBadRequest)
+			return
+		}
+		response := q.QuerySpans(filter)
+		if filter.Name != "" && len(response.Policies) == 0 {
+			http.Error(w, "could not find NetworkPolicy "+filter.Name, http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(*response); err != nil {
+			http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
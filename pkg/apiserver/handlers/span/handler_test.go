@@ -0,0 +1,107 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package span
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	queriermock "github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/testing"
+	"github.com/vmware-tanzu/antrea/pkg/querier"
+)
+
+func TestSpanQuery(t *testing.T) {
+	response := &networkpolicy.SpanQueryResponse{
+		Policies: []networkpolicy.PolicySpan{
+			{
+				Name:            "uid1",
+				Source:          "K8sNetworkPolicy:ns1/web",
+				Nodes:           []string{"node1"},
+				AppliedToGroups: []networkpolicy.GroupSpan{{Name: "atg1", Members: 1, Nodes: []string{"node1"}}},
+			},
+		},
+	}
+	emptyResponse := &networkpolicy.SpanQueryResponse{Policies: []networkpolicy.PolicySpan{}}
+	tests := []struct {
+		name             string
+		query            string
+		expectedFilter   *querier.NetworkPolicyQueryFilter
+		queryResponse    *networkpolicy.SpanQueryResponse
+		expectedStatus   int
+		expectedResponse *networkpolicy.SpanQueryResponse
+	}{
+		{
+			name:             "source",
+			query:            "?source=web&namespace=ns1&type=k8snp",
+			expectedFilter:   &querier.NetworkPolicyQueryFilter{SourceName: "web", Namespace: "ns1", SourceType: cpv1beta.K8sNetworkPolicy},
+			queryResponse:    response,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: response,
+		},
+		{
+			name:             "all",
+			query:            "",
+			expectedFilter:   &querier.NetworkPolicyQueryFilter{},
+			queryResponse:    emptyResponse,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: emptyResponse,
+		},
+		{
+			name:           "name-not-found",
+			query:          "?name=uid2",
+			expectedFilter: &querier.NetworkPolicyQueryFilter{Name: "uid2"},
+			queryResponse:  emptyResponse,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "name-and-source",
+			query:          "?name=uid1&source=web",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid-type",
+			query:          "?type=foo",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			q := queriermock.NewMockSpanQuerier(mockCtrl)
+			if tt.expectedFilter != nil {
+				q.EXPECT().QuerySpans(tt.expectedFilter).Return(tt.queryResponse)
+			}
+			req, err := http.NewRequest(http.MethodGet, tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			HandleFunc(q).ServeHTTP(recorder, req)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedResponse != nil {
+				var received networkpolicy.SpanQueryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+				assert.Equal(t, *tt.expectedResponse, received)
+			}
+		})
+	}
+}
@@ -24,11 +24,22 @@ import (
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	"github.com/vmware-tanzu/antrea/pkg/controller/traceflow"
 )
 
 func HandleValidationNetworkPolicy(v *networkpolicy.NetworkPolicyValidator) http.HandlerFunc {
+	return handleValidation("Antrea Policy/Tier", v.Validate)
+}
+
+func HandleValidationTraceflow(v *traceflow.Validator) http.HandlerFunc {
+	return handleValidation("Traceflow", v.Validate)
+}
+
+// handleValidation returns a HandlerFunc which decodes the AdmissionReview in the
+// request, validates it with the provided function and writes the response.
+func handleValidation(kind string, validate func(ar *admv1.AdmissionReview) *admv1.AdmissionResponse) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		klog.V(2).Info("Received request to validate Antrea Policy/Tier CRD")
+		klog.V(2).Infof("Received request to validate %s CRD", kind)
 		var reqBody []byte
 		if r.Body != nil {
 			reqBody, _ = ioutil.ReadAll(r.Body)
@@ -53,7 +64,7 @@ func HandleValidationNetworkPolicy(v *networkpolicy.NetworkPolicyValidator) http
 			klog.Errorf("CRD validation received incorrect body")
 			admissionResponse = networkpolicy.GetAdmissionResponseForErr(err)
 		} else {
-			admissionResponse = v.Validate(&ar)
+			admissionResponse = validate(&ar)
 		}
 		aReview := admv1.AdmissionReview{}
 		aReview.TypeMeta.Kind = "AdmissionReview"
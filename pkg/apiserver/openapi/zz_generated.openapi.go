@@ -2763,6 +2763,20 @@ func schema_pkg_apis_controlplane_v1beta2_NetworkPolicyRule(ref common.Reference
 							},
 						},
 					},
+					"connectionStates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConnectionStates restricts the rule to packets of connections in one of these states. An empty list matches packets of connections in any state.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"enableLogging"},
 			},
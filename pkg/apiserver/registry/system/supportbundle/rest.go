@@ -259,7 +259,9 @@ func (r *supportBundleREST) collectAgent(ctx context.Context) (*systemv1beta1.Su
 		dumper.DumpNetworkPolicyResources,
 		dumper.DumpAgentInfo,
 		dumper.DumpHeapPprof,
+		dumper.DumpGoroutinePprof,
 		dumper.DumpOVSPorts,
+		dumper.DumpOVSDB,
 	)
 }
 
@@ -271,6 +273,7 @@ func (r *supportBundleREST) collectController(ctx context.Context) (*systemv1bet
 		dumper.DumpNetworkPolicyResources,
 		dumper.DumpControllerInfo,
 		dumper.DumpHeapPprof,
+		dumper.DumpGoroutinePprof,
 	)
 }
 
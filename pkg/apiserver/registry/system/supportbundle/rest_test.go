@@ -15,13 +15,21 @@
 package supportbundle
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/exec"
 	exectesting "k8s.io/utils/exec/testing"
@@ -81,3 +89,90 @@ func TestClean(t *testing.T) {
 		})
 	}
 }
+
+// readBundle returns the contents of the files of a bundle by name.
+func readBundle(t *testing.T, bundlePath string) map[string]string {
+	f, err := defaultFS.Open(bundlePath)
+	require.NoError(t, err)
+	defer f.Close()
+	gzReader, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+	return files
+}
+
+func TestCollectController(t *testing.T) {
+	defaultFS = afero.NewMemMapFs()
+	defaultExecutor = new(testExec)
+	defer func() {
+		defaultFS = afero.NewOsFs()
+		defaultExecutor = exec.New()
+	}()
+
+	storage := NewControllerStorage()
+	bundle, err := storage.SupportBundle.collectController(context.Background())
+	require.NoError(t, err)
+	defer defaultFS.Remove(bundle.Filepath)
+	assert.Equal(t, system.SupportBundleStatusCollected, bundle.Status)
+	data, err := afero.ReadFile(defaultFS, bundle.Filepath)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(data)), bundle.Sum)
+	assert.Equal(t, uint32(len(data)), bundle.Size)
+
+	files := readBundle(t, bundle.Filepath)
+	for _, name := range []string{"controllerinfo", "networkpolicies", "appliedtogroups", "addressgroups"} {
+		assert.Equal(t, "antctl -oyaml get "+name, files[name])
+	}
+	assert.Contains(t, files, "memprofile")
+	assert.Contains(t, files["goroutinestacks"], "goroutine ")
+}
+
+func TestCollect(t *testing.T) {
+	defaultFS = afero.NewMemMapFs()
+	defer func() {
+		defaultFS = afero.NewOsFs()
+	}()
+	writeDumper := func(name, content string) func(string) error {
+		return func(basedir string) error {
+			return afero.WriteFile(defaultFS, filepath.Join(basedir, name), []byte(content), 0644)
+		}
+	}
+	storage := NewControllerStorage()
+
+	bundle, err := storage.SupportBundle.collect(context.Background(), writeDumper("file1", "foo"), writeDumper("file2", "bar"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"file1": "foo", "file2": "bar"}, readBundle(t, bundle.Filepath))
+	require.NoError(t, defaultFS.Remove(bundle.Filepath))
+
+	// Collecting stops at the first failing dumper.
+	failingDumper := func(string) error { return fmt.Errorf("dump failed") }
+	var calledAfterFailure bool
+	_, err = storage.SupportBundle.collect(context.Background(), writeDumper("file1", "foo"), failingDumper, func(string) error {
+		calledAfterFailure = true
+		return nil
+	})
+	assert.Error(t, err)
+	assert.False(t, calledAfterFailure)
+
+	// No bundle is returned when collecting is canceled.
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	_, err = storage.SupportBundle.collect(ctx, writeDumper("file1", "foo"))
+	assert.Error(t, err)
+
+	// The temporary directories and canceled bundles are removed.
+	entries, err := afero.ReadDir(defaultFS, os.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -24,7 +24,9 @@ import (
 
 type CoreV1alpha2Interface interface {
 	RESTClient() rest.Interface
+	BandwidthQuotasGetter
 	ClusterGroupsGetter
+	EgressesGetter
 	ExternalEntitiesGetter
 }
 
@@ -33,10 +35,18 @@ type CoreV1alpha2Client struct {
 	restClient rest.Interface
 }
 
+func (c *CoreV1alpha2Client) BandwidthQuotas() BandwidthQuotaInterface {
+	return newBandwidthQuotas(c)
+}
+
 func (c *CoreV1alpha2Client) ClusterGroups() ClusterGroupInterface {
 	return newClusterGroups(c)
 }
 
+func (c *CoreV1alpha2Client) Egresses() EgressInterface {
+	return newEgresses(c)
+}
+
 func (c *CoreV1alpha2Client) ExternalEntities(namespace string) ExternalEntityInterface {
 	return newExternalEntities(c, namespace)
 }
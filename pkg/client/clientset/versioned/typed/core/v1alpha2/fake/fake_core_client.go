@@ -26,10 +26,18 @@ type FakeCoreV1alpha2 struct {
 	*testing.Fake
 }
 
+func (c *FakeCoreV1alpha2) BandwidthQuotas() v1alpha2.BandwidthQuotaInterface {
+	return &FakeBandwidthQuotas{c}
+}
+
 func (c *FakeCoreV1alpha2) ClusterGroups() v1alpha2.ClusterGroupInterface {
 	return &FakeClusterGroups{c}
 }
 
+func (c *FakeCoreV1alpha2) Egresses() v1alpha2.EgressInterface {
+	return &FakeEgresses{c}
+}
+
 func (c *FakeCoreV1alpha2) ExternalEntities(namespace string) v1alpha2.ExternalEntityInterface {
 	return &FakeExternalEntities{c, namespace}
 }
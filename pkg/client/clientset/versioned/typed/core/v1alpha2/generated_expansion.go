@@ -16,6 +16,10 @@
 
 package v1alpha2
 
+type BandwidthQuotaExpansion interface{}
+
 type ClusterGroupExpansion interface{}
 
+type EgressExpansion interface{}
+
 type ExternalEntityExpansion interface{}
@@ -30,6 +30,10 @@ func (c *FakeOpsV1alpha1) Traceflows() v1alpha1.TraceflowInterface {
 	return &FakeTraceflows{c}
 }
 
+func (c *FakeOpsV1alpha1) TraceflowSets() v1alpha1.TraceflowSetInterface {
+	return &FakeTraceflowSets{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeOpsV1alpha1) RESTClient() rest.Interface {
@@ -25,6 +25,7 @@ import (
 type OpsV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	TraceflowsGetter
+	TraceflowSetsGetter
 }
 
 // OpsV1alpha1Client is used to interact with features provided by the ops.antrea.tanzu.vmware.com group.
@@ -36,6 +37,10 @@ func (c *OpsV1alpha1Client) Traceflows() TraceflowInterface {
 	return newTraceflows(c)
 }
 
+func (c *OpsV1alpha1Client) TraceflowSets() TraceflowSetInterface {
+	return newTraceflowSets(c)
+}
+
 // NewForConfig creates a new OpsV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*OpsV1alpha1Client, error) {
 	config := *c
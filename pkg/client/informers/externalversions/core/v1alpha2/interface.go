@@ -22,8 +22,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// BandwidthQuotas returns a BandwidthQuotaInformer.
+	BandwidthQuotas() BandwidthQuotaInformer
 	// ClusterGroups returns a ClusterGroupInformer.
 	ClusterGroups() ClusterGroupInformer
+	// Egresses returns a EgressInformer.
+	Egresses() EgressInformer
 	// ExternalEntities returns a ExternalEntityInformer.
 	ExternalEntities() ExternalEntityInformer
 }
@@ -39,11 +43,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// BandwidthQuotas returns a BandwidthQuotaInformer.
+func (v *version) BandwidthQuotas() BandwidthQuotaInformer {
+	return &bandwidthQuotaInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ClusterGroups returns a ClusterGroupInformer.
 func (v *version) ClusterGroups() ClusterGroupInformer {
 	return &clusterGroupInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// Egresses returns a EgressInformer.
+func (v *version) Egresses() EgressInformer {
+	return &egressInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ExternalEntities returns a ExternalEntityInformer.
 func (v *version) ExternalEntities() ExternalEntityInformer {
 	return &externalEntityInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -60,14 +60,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Clusterinformation().V1beta1().AntreaControllerInfos().Informer()}, nil
 
 		// Group=core.antrea.tanzu.vmware.com, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("bandwidthquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().BandwidthQuotas().Informer()}, nil
 	case v1alpha2.SchemeGroupVersion.WithResource("clustergroups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().ClusterGroups().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("egresses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().Egresses().Informer()}, nil
 	case v1alpha2.SchemeGroupVersion.WithResource("externalentities"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().ExternalEntities().Informer()}, nil
 
 		// Group=ops.antrea.tanzu.vmware.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("traceflows"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ops().V1alpha1().Traceflows().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("traceflowsets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ops().V1alpha1().TraceflowSets().Informer()}, nil
 
 		// Group=security.antrea.tanzu.vmware.com, Version=v1alpha1
 	case securityv1alpha1.SchemeGroupVersion.WithResource("clusternetworkpolicies"):
@@ -24,6 +24,8 @@ import (
 type Interface interface {
 	// Traceflows returns a TraceflowInformer.
 	Traceflows() TraceflowInformer
+	// TraceflowSets returns a TraceflowSetInformer.
+	TraceflowSets() TraceflowSetInformer
 }
 
 type version struct {
@@ -41,3 +43,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) Traceflows() TraceflowInformer {
 	return &traceflowInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// TraceflowSets returns a TraceflowSetInformer.
+func (v *version) TraceflowSets() TraceflowSetInformer {
+	return &traceflowSetInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
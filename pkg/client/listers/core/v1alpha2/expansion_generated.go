@@ -16,10 +16,18 @@
 
 package v1alpha2
 
+// BandwidthQuotaListerExpansion allows custom methods to be added to
+// BandwidthQuotaLister.
+type BandwidthQuotaListerExpansion interface{}
+
 // ClusterGroupListerExpansion allows custom methods to be added to
 // ClusterGroupLister.
 type ClusterGroupListerExpansion interface{}
 
+// EgressListerExpansion allows custom methods to be added to
+// EgressLister.
+type EgressListerExpansion interface{}
+
 // ExternalEntityListerExpansion allows custom methods to be added to
 // ExternalEntityLister.
 type ExternalEntityListerExpansion interface{}
@@ -19,3 +19,7 @@ package v1alpha1
 // TraceflowListerExpansion allows custom methods to be added to
 // TraceflowLister.
 type TraceflowListerExpansion interface{}
+
+// TraceflowSetListerExpansion allows custom methods to be added to
+// TraceflowSetLister.
+type TraceflowSetListerExpansion interface{}
@@ -0,0 +1,167 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/util/env"
+	ipsecutil "github.com/vmware-tanzu/antrea/pkg/util/ipsec"
+)
+
+const (
+	controllerName = "IPsecPSKRotator"
+	// LastRotationTimeAnnotationKey is the key of the Secret annotation which
+	// records the time of the last PSK rotation.
+	LastRotationTimeAnnotationKey = "ipsec.antrea.io/last-rotation-time"
+	// checkInterval is the interval at which the rotator checks if the PSK
+	// should be rotated.
+	checkInterval = time.Minute
+	// pskLength is the number of random bytes of a generated PSK.
+	pskLength = 32
+)
+
+// PSKRotator rotates the IPsec PSK stored in the antrea-ipsec Secret
+// periodically. A new rotation is only started once all the Nodes have loaded
+// the current PSK, so that the agents never need to know more than two PSKs.
+type PSKRotator struct {
+	client           clientset.Interface
+	nodeLister       corelisters.NodeLister
+	nodeListerSynced cache.InformerSynced
+	namespace        string
+	rotationInterval time.Duration
+}
+
+// NewPSKRotator creates a PSKRotator which rotates the PSK every
+// rotationInterval.
+func NewPSKRotator(client clientset.Interface, nodeInformer coreinformers.NodeInformer, rotationInterval time.Duration) *PSKRotator {
+	return &PSKRotator{
+		client:           client,
+		nodeLister:       nodeInformer.Lister(),
+		nodeListerSynced: nodeInformer.Informer().HasSynced,
+		namespace:        env.GetPodNamespace(),
+		rotationInterval: rotationInterval,
+	}
+}
+
+// Run periodically checks if the PSK should be rotated until stopCh is closed.
+func (r *PSKRotator) Run(stopCh <-chan struct{}) {
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, r.nodeListerSynced) {
+		return
+	}
+	wait.Until(func() {
+		if err := r.rotateIfNeeded(); err != nil {
+			klog.Errorf("Failed to rotate IPsec PSK: %v", err)
+		}
+	}, checkInterval, stopCh)
+}
+
+func (r *PSKRotator) rotateIfNeeded() error {
+	secret, err := r.client.CoreV1().Secrets(r.namespace).Get(context.TODO(), ipsecutil.SecretName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(2).Infof("Secret %s/%s not found, IPsec is not enabled", r.namespace, ipsecutil.SecretName)
+			return nil
+		}
+		return fmt.Errorf("error when getting Secret %s/%s: %v", r.namespace, ipsecutil.SecretName, err)
+	}
+
+	lastRotationTime := secret.CreationTimestamp.Time
+	if value, ok := secret.Annotations[LastRotationTimeAnnotationKey]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			lastRotationTime = t
+		} else {
+			klog.Warningf("Invalid annotation %s of Secret %s/%s: %v", LastRotationTimeAnnotationKey, r.namespace, ipsecutil.SecretName, err)
+		}
+	}
+	if time.Since(lastRotationTime) < r.rotationInterval {
+		return nil
+	}
+
+	pendingNodes, err := r.getPendingNodes(string(secret.Data[ipsecutil.PSKSecretKey]))
+	if err != nil {
+		return err
+	}
+	if len(pendingNodes) > 0 {
+		klog.Infof("Postponing IPsec PSK rotation as %d Node(s) have not loaded the current PSK", len(pendingNodes))
+		return nil
+	}
+
+	psk, err := generatePSK()
+	if err != nil {
+		return fmt.Errorf("error when generating PSK: %v", err)
+	}
+	secret = secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[ipsecutil.PSKSecretKey] = []byte(psk)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[LastRotationTimeAnnotationKey] = time.Now().UTC().Format(time.RFC3339)
+	if _, err := r.client.CoreV1().Secrets(r.namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error when updating Secret %s/%s: %v", r.namespace, ipsecutil.SecretName, err)
+	}
+	klog.Infof("Rotated IPsec PSK, new PSK ID: %s", ipsecutil.PSKID(psk))
+	return nil
+}
+
+// getPendingNodes returns the names of the Nodes which have not loaded the
+// provided PSK. The Nodes which do not advertise any PSK, e.g. because IPsec is
+// not supported by the agent running on them, are ignored, otherwise they would
+// block the rotations forever.
+func (r *PSKRotator) getPendingNodes(psk string) ([]string, error) {
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error when listing Nodes: %v", err)
+	}
+	pskID := ipsecutil.PSKID(psk)
+	var pendingNodes []string
+	for _, node := range nodes {
+		if ipsecutil.GetNodePSKIDs(node) == nil {
+			continue
+		}
+		if !ipsecutil.HasCurrentPSK(node, pskID) {
+			pendingNodes = append(pendingNodes, node.Name)
+		}
+	}
+	return pendingNodes, nil
+}
+
+func generatePSK() (string, error) {
+	b := make([]byte, pskLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
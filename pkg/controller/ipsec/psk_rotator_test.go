@@ -0,0 +1,103 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ipsecutil "github.com/vmware-tanzu/antrea/pkg/util/ipsec"
+)
+
+func TestRotateIfNeeded(t *testing.T) {
+	currentID := ipsecutil.PSKID("current")
+	tests := []struct {
+		name             string
+		lastRotationTime time.Time
+		nodePSKIDs       []string
+		expectRotated    bool
+	}{
+		{
+			name:             "rotation interval not elapsed",
+			lastRotationTime: time.Now().Add(-time.Minute),
+			nodePSKIDs:       []string{currentID, currentID},
+			expectRotated:    false,
+		},
+		{
+			name:             "rotation interval elapsed",
+			lastRotationTime: time.Now().Add(-2 * time.Hour),
+			nodePSKIDs:       []string{currentID, currentID},
+			expectRotated:    true,
+		},
+		{
+			name:             "previous rotation in progress",
+			lastRotationTime: time.Now().Add(-2 * time.Hour),
+			nodePSKIDs:       []string{currentID, ipsecutil.PSKID("previous")},
+			expectRotated:    false,
+		},
+		{
+			name:             "Node without PSK",
+			lastRotationTime: time.Now().Add(-2 * time.Hour),
+			nodePSKIDs:       []string{currentID, ""},
+			expectRotated:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        ipsecutil.SecretName,
+					Namespace:   "kube-system",
+					Annotations: map[string]string{LastRotationTimeAnnotationKey: tt.lastRotationTime.UTC().Format(time.RFC3339)},
+				},
+				Data: map[string][]byte{ipsecutil.PSKSecretKey: []byte("current")},
+			}
+			client := fake.NewSimpleClientset(secret)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			nodeInformer := informerFactory.Core().V1().Nodes()
+			for i, pskID := range tt.nodePSKIDs {
+				node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+					Name:        string(rune('a' + i)),
+					Annotations: map[string]string{ipsecutil.PSKIDsAnnotationKey: pskID},
+				}}
+				require.NoError(t, nodeInformer.Informer().GetIndexer().Add(node))
+			}
+			r := NewPSKRotator(client, nodeInformer, time.Hour)
+			r.namespace = "kube-system"
+
+			require.NoError(t, r.rotateIfNeeded())
+			secret, err := client.CoreV1().Secrets("kube-system").Get(context.TODO(), ipsecutil.SecretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			psk := string(secret.Data[ipsecutil.PSKSecretKey])
+			if tt.expectRotated {
+				assert.NotEqual(t, "current", psk)
+				assert.NotEmpty(t, psk)
+				lastRotationTime, err := time.Parse(time.RFC3339, secret.Annotations[LastRotationTimeAnnotationKey])
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now(), lastRotationTime, time.Minute)
+			} else {
+				assert.Equal(t, "current", psk)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package networkpolicy
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
@@ -59,7 +61,17 @@ func (n *NetworkPolicyController) updateANP(old, cur interface{}) {
 	// cause the SpanMeta member to be overridden with stale SpanMeta members
 	// from an older internal NetworkPolicy.
 	n.internalNetworkPolicyMutex.Lock()
-	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
+	oldInternalNPObj, exists, _ := n.internalNetworkPolicyStore.Get(key)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		klog.Errorf("Internal NetworkPolicy %s not found for %s", key, curInternalNP.SourceRef.ToString())
+		// Delete the groups created when processing the policy.
+		for _, atg := range curInternalNP.AppliedToGroups {
+			n.deleteDereferencedAppliedToGroup(atg)
+		}
+		n.deleteDereferencedAddressGroups(curInternalNP)
+		return
+	}
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	// Must preserve old internal NetworkPolicy Span.
 	curInternalNP.SpanMeta = oldInternalNP.SpanMeta
@@ -103,10 +115,19 @@ func (n *NetworkPolicyController) deleteANP(old interface{}) {
 	defer n.heartbeat("deleteANP")
 	klog.Infof("Processing Antrea NetworkPolicy %s/%s DELETE event", np.Namespace, np.Name)
 	key := internalNetworkPolicyKeyFunc(np)
-	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
+	// The store is locked so that the deleted internal NetworkPolicy is the latest one, which may have been updated
+	// by the reprocessing of the scheduled rules.
+	n.internalNetworkPolicyMutex.Lock()
+	oldInternalNPObj, exists, _ := n.internalNetworkPolicyStore.Get(key)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		klog.Errorf("Internal NetworkPolicy %s not found during Antrea NetworkPolicy %s delete", key, np.Name)
+		return
+	}
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	klog.V(2).Infof("Deleting internal NetworkPolicy %s for %s", oldInternalNP.Name, oldInternalNP.SourceRef.ToString())
 	err := n.internalNetworkPolicyStore.Delete(key)
+	n.internalNetworkPolicyMutex.Unlock()
 	if err != nil {
 		klog.Errorf("Error deleting internal NetworkPolicy during Antrea NetworkPolicy %s delete: %v", np.Name, err)
 		return
@@ -134,9 +155,17 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 		appliedToGroupNamesSet.Insert(n.createAppliedToGroup(
 			np.Namespace, at.PodSelector, at.NamespaceSelector, at.ExternalEntitySelector))
 	}
+	// Drop rules of baseline policies are only observed if the Namespace is being onboarded.
+	observeOnly := isBaselineTier(np.Spec.Tier) && n.getBaselineMode(np.Namespace) == BaselineModeObserve
+	policyLogging := loggingEnabledByAnnotations(np.Annotations)
+	// Rules with a schedule are only included while one of their windows is open.
+	schedules := &scheduleEvaluator{now: time.Now()}
 	rules := make([]controlplane.NetworkPolicyRule, 0, len(np.Spec.Ingress)+len(np.Spec.Egress))
 	// Compute NetworkPolicyRule for Ingress Rule.
 	for idx, ingressRule := range np.Spec.Ingress {
+		if !schedules.isActive(&ingressRule) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(ingressRule.Ports)
 		var appliedToGroupNamesForRule []string
@@ -146,18 +175,26 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 			appliedToGroupNamesForRule = append(appliedToGroupNamesForRule, atGroup)
 			appliedToGroupNamesSet.Insert(atGroup)
 		}
+		action, enableLogging := ingressRule.Action, ingressRule.EnableLogging || policyLogging
+		if observeOnly {
+			action, enableLogging = observeAction(action, enableLogging)
+		}
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionIn,
-			From:            *n.toAntreaPeerForCRD(ingressRule.From, np, controlplane.DirectionIn, namedPortExists),
-			Services:        services,
-			Action:          ingressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   ingressRule.EnableLogging,
-			AppliedToGroups: appliedToGroupNamesForRule,
+			Direction:        controlplane.DirectionIn,
+			From:             *n.toAntreaPeerForCRD(ingressRule.From, np, controlplane.DirectionIn, namedPortExists),
+			Services:         services,
+			Action:           action,
+			Priority:         int32(idx),
+			EnableLogging:    enableLogging,
+			AppliedToGroups:  appliedToGroupNamesForRule,
+			ConnectionStates: ingressRule.ConnectionStates,
 		})
 	}
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, egressRule := range np.Spec.Egress {
+		if !schedules.isActive(&egressRule) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(egressRule.Ports)
 		var appliedToGroupNamesForRule []string
@@ -167,16 +204,22 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 			appliedToGroupNamesForRule = append(appliedToGroupNamesForRule, atGroup)
 			appliedToGroupNamesSet.Insert(atGroup)
 		}
+		action, enableLogging := egressRule.Action, egressRule.EnableLogging || policyLogging
+		if observeOnly {
+			action, enableLogging = observeAction(action, enableLogging)
+		}
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionOut,
-			To:              *n.toAntreaPeerForCRD(egressRule.To, np, controlplane.DirectionOut, namedPortExists),
-			Services:        services,
-			Action:          egressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   egressRule.EnableLogging,
-			AppliedToGroups: appliedToGroupNamesForRule,
+			Direction:        controlplane.DirectionOut,
+			To:               *n.toAntreaPeerForCRD(egressRule.To, np, controlplane.DirectionOut, namedPortExists),
+			Services:         services,
+			Action:           action,
+			Priority:         int32(idx),
+			EnableLogging:    enableLogging,
+			AppliedToGroups:  appliedToGroupNamesForRule,
+			ConnectionStates: egressRule.ConnectionStates,
 		})
 	}
+	n.scheduleReprocess(scheduledPolicy{namespace: np.Namespace, name: np.Name}, schedules.next)
 	tierPriority := n.getTierPriority(np.Spec.Tier)
 	internalNetworkPolicy := &antreatypes.NetworkPolicy{
 		SourceRef: &controlplane.NetworkPolicyReference{
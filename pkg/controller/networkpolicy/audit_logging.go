@@ -0,0 +1,31 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"strconv"
+)
+
+// EnableLoggingAnnotationKey is the annotation set on an Antrea NetworkPolicy or a ClusterNetworkPolicy to enable
+// audit logging for all its rules, regardless of the enableLogging field of each rule. It makes it possible to
+// toggle logging for a policy without editing its rules.
+const EnableLoggingAnnotationKey = "policy.antrea.tanzu.vmware.com/enable-logging"
+
+// loggingEnabledByAnnotations returns whether audit logging is enabled for all the rules of a policy with the
+// provided annotations. Invalid annotation values are ignored.
+func loggingEnabledByAnnotations(annotations map[string]string) bool {
+	enabled, _ := strconv.ParseBool(annotations[EnableLoggingAnnotationKey])
+	return enabled
+}
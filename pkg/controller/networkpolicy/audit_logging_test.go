@@ -0,0 +1,151 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func TestProcessClusterNetworkPolicyEnableLogging(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	tests := []struct {
+		name               string
+		annotations        map[string]string
+		expectedIngressLog bool
+		expectedEgressLog  bool
+	}{
+		{
+			name:               "no-annotation",
+			expectedIngressLog: true,
+			expectedEgressLog:  false,
+		},
+		{
+			name:               "logging-enabled",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "true"},
+			expectedIngressLog: true,
+			expectedEgressLog:  true,
+		},
+		{
+			name:               "logging-disabled",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "false"},
+			expectedIngressLog: true,
+			expectedEgressLog:  false,
+		},
+		{
+			name:               "invalid-annotation",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "yes please"},
+			expectedIngressLog: true,
+			expectedEgressLog:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newController()
+			cnp := &secv1alpha1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "cnpA", UID: "uidA", Annotations: tt.annotations},
+				Spec: secv1alpha1.ClusterNetworkPolicySpec{
+					AppliedTo: []secv1alpha1.NetworkPolicyPeer{
+						{PodSelector: &selectorA},
+					},
+					Ingress: []secv1alpha1.Rule{
+						{
+							From:          []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action:        &allowAction,
+							EnableLogging: true,
+						},
+					},
+					Egress: []secv1alpha1.Rule{
+						{
+							To:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action: &allowAction,
+						},
+					},
+				},
+			}
+			actualPolicy := c.processClusterNetworkPolicy(cnp)
+			assert.Equal(t, tt.expectedIngressLog, actualPolicy.Rules[0].EnableLogging)
+			assert.Equal(t, tt.expectedEgressLog, actualPolicy.Rules[1].EnableLogging)
+		})
+	}
+}
+
+func TestProcessAntreaNetworkPolicyEnableLogging(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	tests := []struct {
+		name               string
+		annotations        map[string]string
+		expectedIngressLog bool
+		expectedEgressLog  bool
+	}{
+		{
+			name:               "no-annotation",
+			expectedIngressLog: false,
+			expectedEgressLog:  true,
+		},
+		{
+			name:               "logging-enabled",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "true"},
+			expectedIngressLog: true,
+			expectedEgressLog:  true,
+		},
+		{
+			name:               "logging-disabled",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "false"},
+			expectedIngressLog: false,
+			expectedEgressLog:  true,
+		},
+		{
+			name:               "invalid-annotation",
+			annotations:        map[string]string{EnableLoggingAnnotationKey: "yes please"},
+			expectedIngressLog: false,
+			expectedEgressLog:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newController()
+			anp := &secv1alpha1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "npA", UID: "uidA", Annotations: tt.annotations},
+				Spec: secv1alpha1.NetworkPolicySpec{
+					AppliedTo: []secv1alpha1.NetworkPolicyPeer{
+						{PodSelector: &selectorA},
+					},
+					Ingress: []secv1alpha1.Rule{
+						{
+							From:   []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action: &allowAction,
+						},
+					},
+					Egress: []secv1alpha1.Rule{
+						{
+							To:            []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action:        &dropAction,
+							EnableLogging: true,
+						},
+					},
+				},
+			}
+			actualPolicy := c.processAntreaNetworkPolicy(anp)
+			assert.Equal(t, tt.expectedIngressLog, actualPolicy.Rules[0].EnableLogging)
+			assert.Equal(t, tt.expectedEgressLog, actualPolicy.Rules[1].EnableLogging)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+// BaselineModeAnnotationKey is the annotation set on a Namespace to control how the Antrea NetworkPolicies of the
+// Namespace in the baseline Tier are realized. It is meant to onboard Namespaces to a default-deny baseline
+// progressively: a Namespace is first put in Observe mode, in which the traffic that would be dropped is logged and
+// counted in the NetworkPolicy stats instead, then switched to Enforce mode once no violations are observed anymore.
+const BaselineModeAnnotationKey = "policy.antrea.tanzu.vmware.com/baseline-mode"
+
+// BaselineMode is the mode in which the baseline Antrea NetworkPolicies of a Namespace are realized.
+type BaselineMode string
+
+const (
+	// BaselineModeEnforce means that the baseline policies are enforced. It is the default mode.
+	BaselineModeEnforce BaselineMode = "Enforce"
+	// BaselineModeObserve means that the traffic matching the Drop rules of the baseline policies is allowed, and
+	// logged by the antrea-agents.
+	BaselineModeObserve BaselineMode = "Observe"
+)
+
+const (
+	// MaxBaselineStatusWindow is the longest period over which the violations of the baseline policies of a
+	// Namespace can be queried. The violations are recorded in hourly buckets, with an additional bucket for the
+	// current hour.
+	MaxBaselineStatusWindow  = 24 * time.Hour
+	baselineViolationBuckets = int(MaxBaselineStatusWindow/time.Hour) + 1
+)
+
+// BaselineStatusQuerier handles requests for antctl get baselinestatus.
+type BaselineStatusQuerier interface {
+	// QueryBaselineStatus returns the BaselineMode of the Namespace and the violations of its baseline policies
+	// observed in the provided window, which must not be longer than MaxBaselineStatusWindow. It returns nil if the
+	// Namespace doesn't exist.
+	QueryBaselineStatus(namespace string, window time.Duration) (*BaselineStatus, error)
+}
+
+// BaselineStatus is the reply struct for baseline status queries. The violations are the sessions matched by the
+// Drop and Reject rules of the baseline Antrea NetworkPolicies of a Namespace in Observe mode, as reported by the
+// NetworkPolicy stats of the antrea-agents.
+type BaselineStatus struct {
+	Namespace string       `json:"namespace"`
+	Mode      BaselineMode `json:"mode"`
+	// ObservedSince is the time since which the violations of the Namespace are recorded, in RFC3339 format. It is
+	// empty if the Namespace is in Enforce mode.
+	ObservedSince string `json:"observedSince,omitempty"`
+	// Window is the period over which the violations are counted, rounded up to whole hours. The violations of the
+	// current hour are counted in addition to the ones of the window.
+	Window     string `json:"window"`
+	Violations int64  `json:"violations"`
+	// LastViolationTime is the time of the last recorded violation, in RFC3339 format, if any.
+	LastViolationTime string `json:"lastViolationTime,omitempty"`
+	// ReadyToEnforce is true if the Namespace has been observed during the whole window without violations.
+	ReadyToEnforce bool `json:"readyToEnforce"`
+}
+
+// baselineViolations records the violations of the baseline policies of a Namespace in Observe mode, in hourly
+// buckets indexed by the hour since the Unix epoch modulo baselineViolationBuckets.
+type baselineViolations struct {
+	observedSince     time.Time
+	lastViolationTime time.Time
+	hours             [baselineViolationBuckets]int64
+	sessions          [baselineViolationBuckets]int64
+}
+
+func (v *baselineViolations) add(sessions int64, now time.Time) {
+	hour := now.Unix() / 3600
+	i := hour % int64(baselineViolationBuckets)
+	if v.hours[i] != hour {
+		v.hours[i] = hour
+		v.sessions[i] = 0
+	}
+	v.sessions[i] += sessions
+	v.lastViolationTime = now
+}
+
+// count returns the number of violations recorded during the current hour and the provided number of previous
+// hours.
+func (v *baselineViolations) count(hours int64, now time.Time) int64 {
+	hour := now.Unix() / 3600
+	var count int64
+	for i := range v.hours {
+		if v.hours[i] >= hour-hours && v.hours[i] <= hour {
+			count += v.sessions[i]
+		}
+	}
+	return count
+}
+
+// getBaselineMode returns the BaselineMode of the provided Namespace. Enforce is returned if the Namespace doesn't
+// exist or if the annotation value is not valid.
+func (n *NetworkPolicyController) getBaselineMode(namespace string) BaselineMode {
+	ns, err := n.namespaceLister.Get(namespace)
+	if err != nil {
+		return BaselineModeEnforce
+	}
+	return baselineModeFromAnnotations(ns.Annotations)
+}
+
+func baselineModeFromAnnotations(annotations map[string]string) BaselineMode {
+	if BaselineMode(annotations[BaselineModeAnnotationKey]) == BaselineModeObserve {
+		return BaselineModeObserve
+	}
+	return BaselineModeEnforce
+}
+
+// isBaselineTier returns whether the provided Tier name refers to the baseline Tier.
+func isBaselineTier(tier string) bool {
+	return strings.ToLower(tier) == baselineTierName
+}
+
+// observeAction returns the action and the logging setting to use for a rule of a baseline Antrea NetworkPolicy in
+// a Namespace in Observe mode: Drop and Reject rules are turned into Allow rules with logging enabled, so that
+// violations are recorded by the antrea-agents without impacting the traffic.
+func observeAction(action *secv1alpha1.RuleAction, enableLogging bool) (*secv1alpha1.RuleAction, bool) {
+	if action == nil || (*action != secv1alpha1.RuleActionDrop && *action != secv1alpha1.RuleActionReject) {
+		return action, enableLogging
+	}
+	allow := secv1alpha1.RuleActionAllow
+	return &allow, true
+}
+
+// hasObservedRules returns whether the provided Antrea NetworkPolicy has rules which are only observed in a
+// Namespace in Observe mode.
+func hasObservedRules(anp *secv1alpha1.NetworkPolicy) bool {
+	for _, rules := range [][]secv1alpha1.Rule{anp.Spec.Ingress, anp.Spec.Egress} {
+		for _, rule := range rules {
+			if action, _ := observeAction(rule.Action, false); action != rule.Action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// syncBaselineViolations starts recording the violations of the baseline policies of the Namespace when it is in
+// Observe mode, and discards them otherwise.
+func (n *NetworkPolicyController) syncBaselineViolations(namespace *v1.Namespace, deleted bool) {
+	n.baselineViolationsMutex.Lock()
+	defer n.baselineViolationsMutex.Unlock()
+	if deleted || baselineModeFromAnnotations(namespace.Annotations) != BaselineModeObserve {
+		delete(n.baselineViolations, namespace.Name)
+		return
+	}
+	if _, exists := n.baselineViolations[namespace.Name]; !exists {
+		n.baselineViolations[namespace.Name] = &baselineViolations{observedSince: time.Now()}
+	}
+}
+
+// RecordAntreaNetworkPolicySessions records the sessions reported by the antrea-agents for an Antrea NetworkPolicy.
+// They are counted as violations if the policy is a baseline policy with Drop or Reject rules in a Namespace in
+// Observe mode. As the stats are reported per policy, the sessions matched by the Allow rules of such a policy are
+// counted too, so the default-deny rules should be kept in their own policy.
+func (n *NetworkPolicyController) RecordAntreaNetworkPolicySessions(namespace, name string, sessions int64) {
+	n.recordBaselineViolations(namespace, name, sessions, time.Now())
+}
+
+func (n *NetworkPolicyController) recordBaselineViolations(namespace, name string, sessions int64, now time.Time) {
+	if sessions == 0 || n.anpLister == nil {
+		return
+	}
+	anp, err := n.anpLister.NetworkPolicies(namespace).Get(name)
+	if err != nil || !isBaselineTier(anp.Spec.Tier) || !hasObservedRules(anp) {
+		return
+	}
+	n.baselineViolationsMutex.Lock()
+	defer n.baselineViolationsMutex.Unlock()
+	// The Namespace is not in Observe mode.
+	violations, exists := n.baselineViolations[namespace]
+	if !exists {
+		return
+	}
+	violations.add(sessions, now)
+}
+
+// QueryBaselineStatus implements BaselineStatusQuerier.
+func (n *NetworkPolicyController) QueryBaselineStatus(namespace string, window time.Duration) (*BaselineStatus, error) {
+	return n.queryBaselineStatus(namespace, window, time.Now())
+}
+
+func (n *NetworkPolicyController) queryBaselineStatus(namespace string, window time.Duration, now time.Time) (*BaselineStatus, error) {
+	if window <= 0 || window > MaxBaselineStatusWindow {
+		return nil, fmt.Errorf("window must be between 0 and %v", MaxBaselineStatusWindow)
+	}
+	ns, err := n.namespaceLister.Get(namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	hours := int64((window + time.Hour - 1) / time.Hour)
+	status := &BaselineStatus{
+		Namespace: namespace,
+		Mode:      baselineModeFromAnnotations(ns.Annotations),
+		Window:    (time.Duration(hours) * time.Hour).String(),
+	}
+	if status.Mode != BaselineModeObserve {
+		return status, nil
+	}
+	n.baselineViolationsMutex.Lock()
+	defer n.baselineViolationsMutex.Unlock()
+	violations, exists := n.baselineViolations[namespace]
+	if !exists {
+		// The Namespace event has not been processed yet.
+		return status, nil
+	}
+	status.ObservedSince = violations.observedSince.UTC().Format(time.RFC3339)
+	status.Violations = violations.count(hours, now)
+	if !violations.lastViolationTime.IsZero() {
+		status.LastViolationTime = violations.lastViolationTime.UTC().Format(time.RFC3339)
+	}
+	status.ReadyToEnforce = status.Violations == 0 && !now.Before(violations.observedSince.Add(time.Duration(hours)*time.Hour))
+	return status, nil
+}
+
+// reprocessBaselineANPs re-computes the internal NetworkPolicies of the baseline Antrea NetworkPolicies in the
+// provided Namespace, after its BaselineMode changed.
+func (n *NetworkPolicyController) reprocessBaselineANPs(namespace string) {
+	if n.anpLister == nil {
+		return
+	}
+	anps, err := n.anpLister.NetworkPolicies(namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list Antrea NetworkPolicies in Namespace %s: %v", namespace, err)
+		return
+	}
+	for _, anp := range anps {
+		if !isBaselineTier(anp.Spec.Tier) {
+			continue
+		}
+		// The policy will be processed with the current mode when its ADD event is handled.
+		if _, exists, _ := n.internalNetworkPolicyStore.Get(internalNetworkPolicyKeyFunc(anp)); !exists {
+			continue
+		}
+		klog.Infof("Reprocessing baseline Antrea NetworkPolicy %s/%s after baseline mode change", anp.Namespace, anp.Name)
+		n.updateANP(anp, anp)
+	}
+}
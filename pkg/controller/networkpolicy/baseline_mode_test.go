@@ -0,0 +1,253 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func TestProcessAntreaNetworkPolicyBaselineMode(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	baselineTier := &secv1alpha1.Tier{
+		ObjectMeta: metav1.ObjectMeta{Name: baselineTierName},
+		Spec:       secv1alpha1.TierSpec{Priority: BaselineTierPriority},
+	}
+	tests := []struct {
+		name                  string
+		annotations           map[string]string
+		tier                  string
+		expectedIngressAction *secv1alpha1.RuleAction
+		expectedIngressLog    bool
+		expectedEgressAction  *secv1alpha1.RuleAction
+	}{
+		{
+			name:                  "baseline-policy-enforced-by-default",
+			tier:                  "baseline",
+			expectedIngressAction: &dropAction,
+			expectedEgressAction:  &allowAction,
+		},
+		{
+			name:                  "baseline-policy-enforced",
+			annotations:           map[string]string{BaselineModeAnnotationKey: string(BaselineModeEnforce)},
+			tier:                  "baseline",
+			expectedIngressAction: &dropAction,
+			expectedEgressAction:  &allowAction,
+		},
+		{
+			name:                  "baseline-policy-observed",
+			annotations:           map[string]string{BaselineModeAnnotationKey: string(BaselineModeObserve)},
+			tier:                  "Baseline",
+			expectedIngressAction: &allowAction,
+			expectedIngressLog:    true,
+			expectedEgressAction:  &allowAction,
+		},
+		{
+			name:                  "application-policy-not-observed",
+			annotations:           map[string]string{BaselineModeAnnotationKey: string(BaselineModeObserve)},
+			tier:                  "",
+			expectedIngressAction: &dropAction,
+			expectedEgressAction:  &allowAction,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Annotations: tt.annotations}}
+			_, c := newController()
+			c.namespaceStore.Add(ns)
+			c.tierStore.Add(baselineTier)
+			np := &secv1alpha1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "npA", UID: "uidA"},
+				Spec: secv1alpha1.NetworkPolicySpec{
+					Tier: tt.tier,
+					AppliedTo: []secv1alpha1.NetworkPolicyPeer{
+						{PodSelector: &selectorA},
+					},
+					Ingress: []secv1alpha1.Rule{
+						{
+							From:   []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action: &dropAction,
+						},
+					},
+					Egress: []secv1alpha1.Rule{
+						{
+							To:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorB}},
+							Action: &allowAction,
+						},
+					},
+				},
+			}
+			actualPolicy := c.processAntreaNetworkPolicy(np)
+			assert.Equal(t, tt.expectedIngressAction, actualPolicy.Rules[0].Action)
+			assert.Equal(t, tt.expectedIngressLog, actualPolicy.Rules[0].EnableLogging)
+			assert.Equal(t, tt.expectedEgressAction, actualPolicy.Rules[1].Action)
+			assert.False(t, actualPolicy.Rules[1].EnableLogging)
+		})
+	}
+}
+
+func TestBaselineStatus(t *testing.T) {
+	dropAction := secv1alpha1.RuleActionDrop
+	allowAction := secv1alpha1.RuleActionAllow
+	observedNS := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Annotations: map[string]string{BaselineModeAnnotationKey: string(BaselineModeObserve)}}}
+	enforcedNS := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2"}}
+	newANP := func(namespace, name, tier string, action *secv1alpha1.RuleAction) *secv1alpha1.NetworkPolicy {
+		return &secv1alpha1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: secv1alpha1.NetworkPolicySpec{
+				Tier:    tier,
+				Ingress: []secv1alpha1.Rule{{Action: action}},
+			},
+		}
+	}
+
+	_, c := newController()
+	anpStore := c.crdInformerFactory.Security().V1alpha1().NetworkPolicies().Informer().GetStore()
+	c.anpLister = c.crdInformerFactory.Security().V1alpha1().NetworkPolicies().Lister()
+	for _, anp := range []*secv1alpha1.NetworkPolicy{
+		newANP("ns1", "deny", "baseline", &dropAction),
+		newANP("ns1", "allow", "baseline", &allowAction),
+		newANP("ns1", "app", "application", &dropAction),
+		newANP("ns2", "deny", "baseline", &dropAction),
+	} {
+		require.NoError(t, anpStore.Add(anp))
+	}
+	c.namespaceStore.Add(observedNS)
+	c.namespaceStore.Add(enforcedNS)
+	c.addNamespace(observedNS)
+	c.addNamespace(enforcedNS)
+	observedSince := c.baselineViolations["ns1"].observedSince
+	start := observedSince.Truncate(time.Hour).Add(time.Hour)
+
+	// Only the sessions of the baseline policies with Drop or Reject rules are violations.
+	c.recordBaselineViolations("ns1", "deny", 2, start)
+	c.recordBaselineViolations("ns1", "allow", 5, start)
+	c.recordBaselineViolations("ns1", "app", 5, start)
+	c.recordBaselineViolations("ns2", "deny", 5, start)
+	c.recordBaselineViolations("ns1", "deny", 3, start.Add(3*time.Hour))
+
+	tests := []struct {
+		name           string
+		namespace      string
+		window         time.Duration
+		now            time.Time
+		expectedStatus *BaselineStatus
+		expectedErr    bool
+	}{
+		{
+			name:      "all-violations",
+			namespace: "ns1",
+			window:    24 * time.Hour,
+			now:       start.Add(3 * time.Hour),
+			expectedStatus: &BaselineStatus{
+				Namespace:         "ns1",
+				Mode:              BaselineModeObserve,
+				ObservedSince:     observedSince.UTC().Format(time.RFC3339),
+				Window:            "24h0m0s",
+				Violations:        5,
+				LastViolationTime: start.Add(3 * time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+		{
+			name:      "recent-violations",
+			namespace: "ns1",
+			window:    90 * time.Minute,
+			now:       start.Add(3 * time.Hour),
+			expectedStatus: &BaselineStatus{
+				Namespace:         "ns1",
+				Mode:              BaselineModeObserve,
+				ObservedSince:     observedSince.UTC().Format(time.RFC3339),
+				Window:            "2h0m0s",
+				Violations:        3,
+				LastViolationTime: start.Add(3 * time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+		{
+			name:      "ready-to-enforce",
+			namespace: "ns1",
+			window:    2 * time.Hour,
+			now:       start.Add(6 * time.Hour),
+			expectedStatus: &BaselineStatus{
+				Namespace:         "ns1",
+				Mode:              BaselineModeObserve,
+				ObservedSince:     observedSince.UTC().Format(time.RFC3339),
+				Window:            "2h0m0s",
+				LastViolationTime: start.Add(3 * time.Hour).UTC().Format(time.RFC3339),
+				ReadyToEnforce:    true,
+			},
+		},
+		{
+			name:      "not-observed-long-enough",
+			namespace: "ns1",
+			window:    24 * time.Hour,
+			now:       start.Add(6 * time.Hour),
+			expectedStatus: &BaselineStatus{
+				Namespace:         "ns1",
+				Mode:              BaselineModeObserve,
+				ObservedSince:     observedSince.UTC().Format(time.RFC3339),
+				Window:            "24h0m0s",
+				Violations:        5,
+				LastViolationTime: start.Add(3 * time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+		{
+			name:      "enforced",
+			namespace: "ns2",
+			window:    24 * time.Hour,
+			now:       start,
+			expectedStatus: &BaselineStatus{
+				Namespace: "ns2",
+				Mode:      BaselineModeEnforce,
+				Window:    "24h0m0s",
+			},
+		},
+		{
+			name:      "unknown-namespace",
+			namespace: "ns3",
+			window:    24 * time.Hour,
+			now:       start,
+		},
+		{
+			name:        "invalid-window",
+			namespace:   "ns1",
+			window:      48 * time.Hour,
+			now:         start,
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := c.queryBaselineStatus(tt.namespace, tt.window, tt.now)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+
+	// The violations are discarded when the Namespace is switched to Enforce mode.
+	c.updateNamespace(observedNS, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	assert.NotContains(t, c.baselineViolations, "ns1")
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -42,6 +44,8 @@ func (n *NetworkPolicyController) addClusterGroup(curObj interface{}) {
 	klog.V(2).Infof("Creating new internal Group %s with selector (%s)", newGroup.UID, newGroup.Selector.NormalizedName)
 	n.internalGroupStore.Create(newGroup)
 	n.enqueueInternalGroup(key)
+	// The ClusterGroup may be referred as a child by existing ClusterGroups.
+	n.enqueueParentGroups(cg.Name)
 }
 
 // updateClusterGroup is responsible for processing the UPDATE event of a ClusterGroup resource.
@@ -54,7 +58,9 @@ func (n *NetworkPolicyController) updateClusterGroup(oldObj, curObj interface{})
 	oldGroup := n.processClusterGroup(og)
 	selUpdated := newGroup.Selector.NormalizedName != oldGroup.Selector.NormalizedName
 	ipBlockUpdated := newGroup.IPBlock != oldGroup.IPBlock
-	if !selUpdated && !ipBlockUpdated {
+	svcRefUpdated := !serviceReferenceEqual(newGroup.ServiceReference, oldGroup.ServiceReference)
+	childGroupsUpdated := !sets.NewString(newGroup.ChildGroups...).Equal(sets.NewString(oldGroup.ChildGroups...))
+	if !selUpdated && !ipBlockUpdated && !svcRefUpdated && !childGroupsUpdated {
 		// No change in the selectors of the ClusterGroup. No need to enqueue for further sync.
 		return
 	}
@@ -84,6 +90,7 @@ func (n *NetworkPolicyController) deleteClusterGroup(oldObj interface{}) {
 	if err != nil {
 		klog.Errorf("Unable to delete internal Group %s from store: %v", key, err)
 	}
+	n.enqueueParentGroups(og.Name)
 }
 
 func (n *NetworkPolicyController) processClusterGroup(cg *corev1a2.ClusterGroup) *antreatypes.Group {
@@ -96,11 +103,188 @@ func (n *NetworkPolicyController) processClusterGroup(cg *corev1a2.ClusterGroup)
 		internalGroup.IPBlock = ipb
 		return &internalGroup
 	}
+	if len(cg.Spec.ChildGroups) > 0 {
+		// The members of the Group are computed from the members of its child Groups.
+		for _, childGroup := range cg.Spec.ChildGroups {
+			internalGroup.ChildGroups = append(internalGroup.ChildGroups, string(childGroup))
+		}
+		return &internalGroup
+	}
+	if cg.Spec.ServiceReference != nil {
+		// The members of the Group are computed from the Service and its Endpoints.
+		internalGroup.ServiceReference = &antreatypes.ServiceReference{
+			Name:      cg.Spec.ServiceReference.Name,
+			Namespace: cg.Spec.ServiceReference.Namespace,
+		}
+		return &internalGroup
+	}
 	groupSelector := toGroupSelector("", cg.Spec.PodSelector, cg.Spec.NamespaceSelector, nil)
 	internalGroup.Selector = *groupSelector
 	return &internalGroup
 }
 
+// serviceIndexFunc indexes the ClusterGroups by the Services they refer to.
+func serviceIndexFunc(obj interface{}) ([]string, error) {
+	cg, ok := obj.(*corev1a2.ClusterGroup)
+	if !ok || cg.Spec.ServiceReference == nil {
+		return []string{}, nil
+	}
+	return []string{k8s.NamespacedName(cg.Spec.ServiceReference.Namespace, cg.Spec.ServiceReference.Name)}, nil
+}
+
+// childGroupIndexFunc indexes the ClusterGroups by the names of their child ClusterGroups.
+func childGroupIndexFunc(obj interface{}) ([]string, error) {
+	cg, ok := obj.(*corev1a2.ClusterGroup)
+	if !ok {
+		return []string{}, nil
+	}
+	childGroups := make([]string, 0, len(cg.Spec.ChildGroups))
+	for _, childGroup := range cg.Spec.ChildGroups {
+		childGroups = append(childGroups, string(childGroup))
+	}
+	return childGroups, nil
+}
+
+func serviceReferenceEqual(a, b *antreatypes.ServiceReference) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// addService is responsible for processing the ADD event of a Service resource.
+func (n *NetworkPolicyController) addService(obj interface{}) {
+	svc := obj.(*v1.Service)
+	klog.V(2).Infof("Processing ADD event for Service %s/%s", svc.Namespace, svc.Name)
+	n.enqueueClusterGroupsForService(svc.Namespace, svc.Name)
+}
+
+// updateService is responsible for processing the UPDATE event of a Service resource.
+func (n *NetworkPolicyController) updateService(oldObj, curObj interface{}) {
+	svc := curObj.(*v1.Service)
+	oldSvc := oldObj.(*v1.Service)
+	if svc.Spec.ClusterIP == oldSvc.Spec.ClusterIP {
+		// The ClusterGroups only depend on the ClusterIP of the Service.
+		return
+	}
+	klog.V(2).Infof("Processing UPDATE event for Service %s/%s", svc.Namespace, svc.Name)
+	n.enqueueClusterGroupsForService(svc.Namespace, svc.Name)
+}
+
+// deleteService is responsible for processing the DELETE event of a Service resource.
+func (n *NetworkPolicyController) deleteService(oldObj interface{}) {
+	svc, ok := oldObj.(*v1.Service)
+	if !ok {
+		tombstone, ok := oldObj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting Service, invalid type: %v", oldObj)
+			return
+		}
+		svc, ok = tombstone.Obj.(*v1.Service)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting Service, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	klog.V(2).Infof("Processing DELETE event for Service %s/%s", svc.Namespace, svc.Name)
+	n.enqueueClusterGroupsForService(svc.Namespace, svc.Name)
+}
+
+// addEndpoints is responsible for processing the ADD event of an Endpoints resource.
+func (n *NetworkPolicyController) addEndpoints(obj interface{}) {
+	ep := obj.(*v1.Endpoints)
+	klog.V(2).Infof("Processing ADD event for Endpoints %s/%s", ep.Namespace, ep.Name)
+	n.enqueueClusterGroupsForService(ep.Namespace, ep.Name)
+}
+
+// updateEndpoints is responsible for processing the UPDATE event of an Endpoints resource.
+func (n *NetworkPolicyController) updateEndpoints(oldObj, curObj interface{}) {
+	ep := curObj.(*v1.Endpoints)
+	oldEp := oldObj.(*v1.Endpoints)
+	if apiequality.Semantic.DeepEqual(ep.Subsets, oldEp.Subsets) {
+		// The ClusterGroups only depend on the addresses of the Endpoints.
+		return
+	}
+	klog.V(2).Infof("Processing UPDATE event for Endpoints %s/%s", ep.Namespace, ep.Name)
+	n.enqueueClusterGroupsForService(ep.Namespace, ep.Name)
+}
+
+// deleteEndpoints is responsible for processing the DELETE event of an Endpoints resource.
+func (n *NetworkPolicyController) deleteEndpoints(oldObj interface{}) {
+	ep, ok := oldObj.(*v1.Endpoints)
+	if !ok {
+		tombstone, ok := oldObj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting Endpoints, invalid type: %v", oldObj)
+			return
+		}
+		ep, ok = tombstone.Obj.(*v1.Endpoints)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting Endpoints, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	klog.V(2).Infof("Processing DELETE event for Endpoints %s/%s", ep.Namespace, ep.Name)
+	n.enqueueClusterGroupsForService(ep.Namespace, ep.Name)
+}
+
+// enqueueClusterGroupsForService enqueues the internal Groups of the ClusterGroups which refer
+// to the Service, so that their members are re-computed from the Service and its Endpoints.
+func (n *NetworkPolicyController) enqueueClusterGroupsForService(namespace, name string) {
+	cgs, err := n.cgInformer.Informer().GetIndexer().ByIndex(ServiceIndex, k8s.NamespacedName(namespace, name))
+	if err != nil {
+		klog.Errorf("Error retrieving ClusterGroups corresponding to Service %s/%s: %v", namespace, name, err)
+		return
+	}
+	for _, obj := range cgs {
+		n.enqueueInternalGroup(internalGroupKeyFunc(obj.(*corev1a2.ClusterGroup)))
+	}
+}
+
+// getServiceReferenceMembers returns the ClusterIP of the referred Service and the Pods listed in
+// its Endpoints as GroupMembers, whether the Pods are ready or not. A headless Service has no
+// ClusterIP member, and there is no Pod member if the Endpoints don't exist, e.g. if the Service
+// doesn't exist yet.
+func (n *NetworkPolicyController) getServiceReferenceMembers(svcRef *antreatypes.ServiceReference) (controlplane.GroupMemberSet, error) {
+	memberSet := controlplane.GroupMemberSet{}
+	svc, err := n.serviceLister.Services(svcRef.Namespace).Get(svcRef.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != v1.ClusterIPNone {
+		memberSet.Insert(&controlplane.GroupMember{IPs: []controlplane.IPAddress{ipStrToIPAddress(svc.Spec.ClusterIP)}})
+	}
+	ep, err := n.endpointsLister.Endpoints(svcRef.Namespace).Get(svcRef.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return memberSet, nil
+		}
+		return nil, err
+	}
+	for _, subset := range ep.Subsets {
+		for _, addresses := range [][]v1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+					continue
+				}
+				pod, err := n.podLister.Pods(address.TargetRef.Namespace).Get(address.TargetRef.Name)
+				if err != nil {
+					if apierrors.IsNotFound(err) {
+						continue
+					}
+					return nil, err
+				}
+				if len(pod.Status.PodIPs) == 0 {
+					continue
+				}
+				memberSet.Insert(podToGroupMember(pod, true))
+			}
+		}
+	}
+	return memberSet, nil
+}
+
 // filterInternalGroupsForPod computes a list of internal Group keys which match the Pod's labels.
 func (n *NetworkPolicyController) filterInternalGroupsForPod(obj metav1.Object) sets.String {
 	matchingKeySet := sets.String{}
@@ -133,6 +317,19 @@ func (n *NetworkPolicyController) filterInternalGroupsForNamespace(namespace *v1
 	return matchingKeys
 }
 
+// enqueueParentGroups enqueues the internal Groups of the ClusterGroups which have the provided
+// ClusterGroup as a child, so that their members are re-computed.
+func (n *NetworkPolicyController) enqueueParentGroups(cgName string) {
+	parents, err := n.cgInformer.Informer().GetIndexer().ByIndex(ChildGroupIndex, cgName)
+	if err != nil {
+		klog.Errorf("Error retrieving parents of ClusterGroup %s: %v", cgName, err)
+		return
+	}
+	for _, obj := range parents {
+		n.enqueueInternalGroup(internalGroupKeyFunc(obj.(*corev1a2.ClusterGroup)))
+	}
+}
+
 func (n *NetworkPolicyController) enqueueInternalGroup(key string) {
 	klog.V(4).Infof("Adding new key %s to internal Group queue", key)
 	n.internalGroupQueue.Add(key)
@@ -178,7 +375,40 @@ func (n *NetworkPolicyController) syncInternalGroup(key string) error {
 		return nil
 	}
 	grp := grpObj.(*antreatypes.Group)
-	if grp.IPBlock == nil {
+	if len(grp.ChildGroups) > 0 {
+		// The members of the Group are the union of the members of its child Groups. A child
+		// Group which doesn't exist yet has no member.
+		memberSet := controlplane.GroupMemberSet{}
+		for _, childGroup := range grp.ChildGroups {
+			childObj, found, _ := n.internalGroupStore.Get(childGroup)
+			if !found {
+				continue
+			}
+			memberSet = memberSet.Union(childObj.(*antreatypes.Group).GroupMembers)
+		}
+		updatedGrp := &antreatypes.Group{
+			UID:          grp.UID,
+			Name:         grp.Name,
+			GroupMembers: memberSet,
+			ChildGroups:  grp.ChildGroups,
+		}
+		klog.V(2).Infof("Updating existing internal Group %s with %d GroupMembers from %d child Groups", key, len(memberSet), len(grp.ChildGroups))
+		n.internalGroupStore.Update(updatedGrp)
+	} else if grp.ServiceReference != nil {
+		memberSet, err := n.getServiceReferenceMembers(grp.ServiceReference)
+		if err != nil {
+			return err
+		}
+		updatedGrp := &antreatypes.Group{
+			UID:              grp.UID,
+			Name:             grp.Name,
+			GroupMembers:     memberSet,
+			ServiceReference: grp.ServiceReference,
+		}
+		klog.V(2).Infof("Updating existing internal Group %s with %d GroupMembers from Service %s/%s", key, len(memberSet), grp.ServiceReference.Namespace, grp.ServiceReference.Name)
+		n.internalGroupStore.Update(updatedGrp)
+		n.enqueueParentGroups(grp.Name)
+	} else if grp.IPBlock == nil {
 		// Find all Pods matching its selectors and update store.
 		groupSelector := grp.Selector
 		pods, _ := n.processSelector(groupSelector)
@@ -199,6 +429,7 @@ func (n *NetworkPolicyController) syncInternalGroup(key string) error {
 		}
 		klog.V(2).Infof("Updating existing internal Group %s with %d GroupMembers", key, len(memberSet))
 		n.internalGroupStore.Update(updatedGrp)
+		n.enqueueParentGroups(grp.Name)
 	}
 	// Retrieve the ClusterGroup corresponding to this key.
 	cg, err := n.cgLister.Get(grp.Name)
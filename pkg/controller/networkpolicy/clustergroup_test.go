@@ -21,6 +21,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
 	corev1a2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
@@ -102,6 +103,26 @@ func TestProcessClusterGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "cg-with-svc-reference",
+			inputGroup: &corev1a2.ClusterGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "cgE", UID: "uidE"},
+				Spec: corev1a2.GroupSpec{
+					ServiceReference: &corev1a2.ServiceReference{
+						Name:      "svc1",
+						Namespace: "nsA",
+					},
+				},
+			},
+			expectedGroup: &antreatypes.Group{
+				UID:  "uidE",
+				Name: "cgE",
+				ServiceReference: &antreatypes.ServiceReference{
+					Name:      "svc1",
+					Namespace: "nsA",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -309,6 +330,151 @@ func TestDeleteCG(t *testing.T) {
 	assert.False(t, found, "expected internal Group to be deleted")
 }
 
+func TestSyncClusterGroupsForEndpoints(t *testing.T) {
+	cg := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cgA", UID: "uidA"},
+		Spec: corev1a2.GroupSpec{
+			ServiceReference: &corev1a2.ServiceReference{
+				Name:      "svc1",
+				Namespace: "nsA",
+			},
+		},
+	}
+	podA := getPod("podA", "nsA", "node1", "1.1.1.1", false)
+	podB := getPod("podB", "nsA", "node1", "1.1.1.2", false)
+	podRef := func(pod *corev1.Pod) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}
+	}
+	// The Endpoints of a Service without selector may include addresses which are not Pods.
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "nsA"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "1.1.1.1", TargetRef: podRef(podA)},
+				{IP: "10.0.0.1"},
+			},
+			NotReadyAddresses: []corev1.EndpointAddress{
+				{IP: "1.1.1.3", TargetRef: podRef(getPod("podC", "nsA", "node1", "1.1.1.3", false))},
+			},
+		}},
+	}
+	_, npc := newController()
+	npc.cgInformer.Informer().AddIndexers(cache.Indexers{ServiceIndex: serviceIndexFunc})
+	npc.podStore.Add(podA)
+	npc.podStore.Add(podB)
+	npc.cgStore.Add(cg)
+	npc.addClusterGroup(cg)
+	key := internalGroupKeyFunc(cg)
+	npc.internalGroupQueue.Get()
+	npc.internalGroupQueue.Done(key)
+	getMembers := func() controlplane.GroupMemberSet {
+		obj, found, _ := npc.internalGroupStore.Get(key)
+		assert.True(t, found, "expected internal Group to exist")
+		return obj.(*antreatypes.Group).GroupMembers
+	}
+
+	// The Group is empty until the Endpoints are created.
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.GroupMemberSet{}, getMembers())
+
+	// The Pod podC, which doesn't exist, and the address without a Pod reference are ignored.
+	npc.endpointsStore.Add(ep)
+	npc.addEndpoints(ep)
+	assert.Equal(t, 1, npc.internalGroupQueue.Len())
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podA, true)), getMembers())
+
+	updatedEp := ep.DeepCopy()
+	updatedEp.Subsets[0].NotReadyAddresses[0].TargetRef = podRef(podB)
+	npc.endpointsStore.Update(updatedEp)
+	npc.updateEndpoints(ep, updatedEp)
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podA, true), podToGroupMember(podB, true)), getMembers())
+
+	npc.endpointsStore.Delete(updatedEp)
+	npc.deleteEndpoints(updatedEp)
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.GroupMemberSet{}, getMembers())
+
+	// The ClusterIP of the Service is a member, unless the Service is headless.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "nsA"},
+		Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+	}
+	clusterIPMember := func(ip string) *controlplane.GroupMember {
+		return &controlplane.GroupMember{IPs: []controlplane.IPAddress{ipStrToIPAddress(ip)}}
+	}
+	npc.serviceStore.Add(svc)
+	npc.addService(svc)
+	assert.Equal(t, 1, npc.internalGroupQueue.Len())
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.GroupMemberSet{}, getMembers())
+
+	updatedSvc := svc.DeepCopy()
+	updatedSvc.Spec.ClusterIP = "10.96.0.10"
+	npc.serviceStore.Update(updatedSvc)
+	npc.updateService(svc, updatedSvc)
+	npc.endpointsStore.Add(ep)
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.NewGroupMemberSet(clusterIPMember("10.96.0.10"), podToGroupMember(podA, true)), getMembers())
+
+	npc.serviceStore.Delete(updatedSvc)
+	npc.deleteService(updatedSvc)
+	npc.syncInternalGroup(key)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podA, true)), getMembers())
+}
+
+func TestSyncInternalGroupWithChildGroups(t *testing.T) {
+	selectorA := metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}}
+	selectorB := metav1.LabelSelector{MatchLabels: map[string]string{"app": "b"}}
+	cgA := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cgA", UID: "uidA"},
+		Spec:       corev1a2.GroupSpec{PodSelector: &selectorA},
+	}
+	cgB := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cgB", UID: "uidB"},
+		Spec:       corev1a2.GroupSpec{PodSelector: &selectorB},
+	}
+	parent := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cgParent", UID: "uidParent"},
+		Spec:       corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"cgA", "cgB"}},
+	}
+	podA := getPod("podA", "nsA", "node1", "1.1.1.1", false)
+	podA.Labels = selectorA.MatchLabels
+	podB := getPod("podB", "nsA", "node1", "1.1.1.2", false)
+	podB.Labels = selectorB.MatchLabels
+	_, npc := newController()
+	npc.cgInformer.Informer().AddIndexers(cache.Indexers{ChildGroupIndex: childGroupIndexFunc})
+	npc.podStore.Add(podA)
+	npc.podStore.Add(podB)
+	getMembers := func(key string) controlplane.GroupMemberSet {
+		obj, found, _ := npc.internalGroupStore.Get(key)
+		assert.True(t, found, "expected internal Group %s to exist", key)
+		return obj.(*antreatypes.Group).GroupMembers
+	}
+
+	// The child Group cgB doesn't exist yet.
+	npc.cgStore.Add(parent)
+	npc.addClusterGroup(parent)
+	npc.cgStore.Add(cgA)
+	npc.addClusterGroup(cgA)
+	npc.syncInternalGroup(cgA.Name)
+	npc.syncInternalGroup(parent.Name)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podA, true)), getMembers(parent.Name))
+
+	npc.cgStore.Add(cgB)
+	npc.addClusterGroup(cgB)
+	npc.syncInternalGroup(cgB.Name)
+	npc.syncInternalGroup(parent.Name)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podA, true), podToGroupMember(podB, true)), getMembers(parent.Name))
+
+	// The parent Group is re-computed when the members of a child Group change.
+	npc.podStore.Delete(podA)
+	npc.syncInternalGroup(cgA.Name)
+	npc.syncInternalGroup(parent.Name)
+	assert.Equal(t, controlplane.NewGroupMemberSet(podToGroupMember(podB, true)), getMembers(parent.Name))
+}
+
 func TestFilterInternalGroupsForPod(t *testing.T) {
 	selectorSpec := metav1.LabelSelector{
 		MatchLabels: map[string]string{"purpose": "test-select"},
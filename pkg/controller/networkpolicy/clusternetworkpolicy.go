@@ -15,6 +15,8 @@
 package networkpolicy
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
@@ -59,7 +61,17 @@ func (n *NetworkPolicyController) updateCNP(old, cur interface{}) {
 	// cause the SpanMeta member to be overridden with stale SpanMeta members
 	// from an older internal NetworkPolicy.
 	n.internalNetworkPolicyMutex.Lock()
-	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
+	oldInternalNPObj, exists, _ := n.internalNetworkPolicyStore.Get(key)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		klog.Errorf("Internal NetworkPolicy %s not found for %s", key, curInternalNP.SourceRef.ToString())
+		// Delete the groups created when processing the policy.
+		for _, atg := range curInternalNP.AppliedToGroups {
+			n.deleteDereferencedAppliedToGroup(atg)
+		}
+		n.deleteDereferencedAddressGroups(curInternalNP)
+		return
+	}
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	// Must preserve old internal NetworkPolicy Span.
 	curInternalNP.SpanMeta = oldInternalNP.SpanMeta
@@ -103,10 +115,19 @@ func (n *NetworkPolicyController) deleteCNP(old interface{}) {
 	defer n.heartbeat("deleteCNP")
 	klog.Infof("Processing ClusterNetworkPolicy %s DELETE event", cnp.Name)
 	key := internalNetworkPolicyKeyFunc(cnp)
-	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
+	// The store is locked so that the deleted internal NetworkPolicy is the latest one, which may have been updated
+	// by the reprocessing of the scheduled rules.
+	n.internalNetworkPolicyMutex.Lock()
+	oldInternalNPObj, exists, _ := n.internalNetworkPolicyStore.Get(key)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		klog.Errorf("Internal NetworkPolicy %s not found during ClusterNetworkPolicy %s delete", key, cnp.Name)
+		return
+	}
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	klog.V(2).Infof("Deleting internal NetworkPolicy %s for %s", oldInternalNP.Name, oldInternalNP.SourceRef.ToString())
 	err := n.internalNetworkPolicyStore.Delete(key)
+	n.internalNetworkPolicyMutex.Unlock()
 	if err != nil {
 		klog.Errorf("Error deleting internal NetworkPolicy during NetworkPolicy %s delete: %v", cnp.Name, err)
 		return
@@ -134,9 +155,15 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 		appliedToGroupNamesSet.Insert(n.createAppliedToGroup(
 			"", at.PodSelector, at.NamespaceSelector, at.ExternalEntitySelector))
 	}
+	policyLogging := loggingEnabledByAnnotations(cnp.Annotations)
+	// Rules with a schedule are only included while one of their windows is open.
+	schedules := &scheduleEvaluator{now: time.Now()}
 	rules := make([]controlplane.NetworkPolicyRule, 0, len(cnp.Spec.Ingress)+len(cnp.Spec.Egress))
 	// Compute NetworkPolicyRule for Ingress Rule.
 	for idx, ingressRule := range cnp.Spec.Ingress {
+		if !schedules.isActive(&ingressRule) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(ingressRule.Ports)
 		var appliedToGroupNamesForRule []string
@@ -147,17 +174,21 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 			appliedToGroupNamesSet.Insert(atGroup)
 		}
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionIn,
-			From:            *n.toAntreaPeerForCRD(ingressRule.From, cnp, controlplane.DirectionIn, namedPortExists),
-			Services:        services,
-			Action:          ingressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   ingressRule.EnableLogging,
-			AppliedToGroups: appliedToGroupNamesForRule,
+			Direction:        controlplane.DirectionIn,
+			From:             *n.toAntreaPeerForCRD(ingressRule.From, cnp, controlplane.DirectionIn, namedPortExists),
+			Services:         services,
+			Action:           ingressRule.Action,
+			Priority:         int32(idx),
+			EnableLogging:    ingressRule.EnableLogging || policyLogging,
+			AppliedToGroups:  appliedToGroupNamesForRule,
+			ConnectionStates: ingressRule.ConnectionStates,
 		})
 	}
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, egressRule := range cnp.Spec.Egress {
+		if !schedules.isActive(&egressRule) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(egressRule.Ports)
 		var appliedToGroupNamesForRule []string
@@ -168,15 +199,17 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 			appliedToGroupNamesSet.Insert(atGroup)
 		}
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionOut,
-			To:              *n.toAntreaPeerForCRD(egressRule.To, cnp, controlplane.DirectionOut, namedPortExists),
-			Services:        services,
-			Action:          egressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   egressRule.EnableLogging,
-			AppliedToGroups: appliedToGroupNamesForRule,
+			Direction:        controlplane.DirectionOut,
+			To:               *n.toAntreaPeerForCRD(egressRule.To, cnp, controlplane.DirectionOut, namedPortExists),
+			Services:         services,
+			Action:           egressRule.Action,
+			Priority:         int32(idx),
+			EnableLogging:    egressRule.EnableLogging || policyLogging,
+			AppliedToGroups:  appliedToGroupNamesForRule,
+			ConnectionStates: egressRule.ConnectionStates,
 		})
 	}
+	n.scheduleReprocess(scheduledPolicy{name: cnp.Name}, schedules.next)
 	tierPriority := n.getTierPriority(cnp.Spec.Tier)
 	internalNetworkPolicy := &antreatypes.NetworkPolicy{
 		Name:       internalNetworkPolicyKeyFunc(cnp),
@@ -79,6 +79,10 @@ const (
 	PriorityIndex = "priority"
 	// ClusterGroupIndex is used to index ClusterNetworkPolicies by ClusterGroup names.
 	ClusterGroupIndex = "clustergroup"
+	// ServiceIndex is used to index ClusterGroups by the Services they refer to.
+	ServiceIndex = "service"
+	// ChildGroupIndex is used to index ClusterGroups by the names of their child ClusterGroups.
+	ChildGroupIndex = "childGroup"
 )
 
 var (
@@ -132,6 +136,20 @@ type NetworkPolicyController struct {
 	// namespaceListerSynced is a function which returns true if the Namespace shared informer has been synced at least once.
 	namespaceListerSynced cache.InformerSynced
 
+	serviceInformer coreinformers.ServiceInformer
+	// serviceLister is able to list/get Services and is populated by the shared informer passed to
+	// NewNetworkPolicyController.
+	serviceLister corelisters.ServiceLister
+	// serviceListerSynced is a function which returns true if the Service shared informer has been synced at least once.
+	serviceListerSynced cache.InformerSynced
+
+	endpointsInformer coreinformers.EndpointsInformer
+	// endpointsLister is able to list/get Endpoints and is populated by the shared informer passed to
+	// NewNetworkPolicyController.
+	endpointsLister corelisters.EndpointsLister
+	// endpointsListerSynced is a function which returns true if the Endpoints shared informer has been synced at least once.
+	endpointsListerSynced cache.InformerSynced
+
 	externalEntityInformer corev1a2informers.ExternalEntityInformer
 	// externalEntityLister is able to list/get ExternalEntities and is populated by the shared informer passed to
 	// NewNetworkPolicyController.
@@ -197,11 +215,19 @@ type NetworkPolicyController struct {
 	// internalGroupQueue maintains the networkpolicy.Group objects that needs to be
 	// synced.
 	internalGroupQueue workqueue.RateLimitingInterface
+	// ruleScheduleQueue maintains the Antrea-native policies which have rules with
+	// a schedule, until the next time one of the schedule windows opens or closes.
+	ruleScheduleQueue workqueue.DelayingInterface
 
 	// internalNetworkPolicyMutex protects the internalNetworkPolicyStore from
 	// concurrent access during updates to the internal NetworkPolicy object.
 	internalNetworkPolicyMutex sync.RWMutex
 
+	// baselineViolations records the violations of the baseline policies of the Namespaces in Observe mode, keyed
+	// by Namespace name.
+	baselineViolations      map[string]*baselineViolations
+	baselineViolationsMutex sync.Mutex
+
 	// heartbeatCh is an internal channel for testing. It's used to know whether all tasks have been
 	// processed, and to count executions of each function.
 	heartbeatCh chan heartbeat
@@ -217,6 +243,8 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 	crdClient versioned.Interface,
 	podInformer coreinformers.PodInformer,
 	namespaceInformer coreinformers.NamespaceInformer,
+	serviceInformer coreinformers.ServiceInformer,
+	endpointsInformer coreinformers.EndpointsInformer,
 	externalEntityInformer corev1a2informers.ExternalEntityInformer,
 	networkPolicyInformer networkinginformers.NetworkPolicyInformer,
 	cnpInformer secinformers.ClusterNetworkPolicyInformer,
@@ -250,6 +278,8 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 		addressGroupQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "addressGroup"),
 		internalNetworkPolicyQueue: workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "internalNetworkPolicy"),
 		internalGroupQueue:         workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "internalGroup"),
+		ruleScheduleQueue:          workqueue.NewNamedDelayingQueue("ruleSchedule"),
+		baselineViolations:         map[string]*baselineViolations{},
 	}
 	// Add handlers for Pod events.
 	podInformer.Informer().AddEventHandlerWithResyncPeriod(
@@ -292,6 +322,12 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 		n.cgInformer = cgInformer
 		n.cgLister = cgInformer.Lister()
 		n.cgListerSynced = cgInformer.Informer().HasSynced
+		n.serviceInformer = serviceInformer
+		n.serviceLister = serviceInformer.Lister()
+		n.serviceListerSynced = serviceInformer.Informer().HasSynced
+		n.endpointsInformer = endpointsInformer
+		n.endpointsLister = endpointsInformer.Lister()
+		n.endpointsListerSynced = endpointsInformer.Informer().HasSynced
 		tierInformer.Informer().AddIndexers(
 			cache.Indexers{
 				PriorityIndex: func(obj interface{}) ([]string, error) {
@@ -378,6 +414,12 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 			},
 			resyncPeriod,
 		)
+		cgInformer.Informer().AddIndexers(
+			cache.Indexers{
+				ServiceIndex:    serviceIndexFunc,
+				ChildGroupIndex: childGroupIndexFunc,
+			},
+		)
 		// Add event handlers for ClusterGroup notification.
 		cgInformer.Informer().AddEventHandlerWithResyncPeriod(
 			cache.ResourceEventHandlerFuncs{
@@ -387,6 +429,24 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 			},
 			resyncPeriod,
 		)
+		// Add event handlers for Service and Endpoints notification, to update the members
+		// of the ClusterGroups which refer to Services.
+		serviceInformer.Informer().AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    n.addService,
+				UpdateFunc: n.updateService,
+				DeleteFunc: n.deleteService,
+			},
+			resyncPeriod,
+		)
+		endpointsInformer.Informer().AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    n.addEndpoints,
+				UpdateFunc: n.updateEndpoints,
+				DeleteFunc: n.deleteEndpoints,
+			},
+			resyncPeriod,
+		)
 	}
 	return n
 }
@@ -1020,6 +1080,7 @@ func (n *NetworkPolicyController) addNamespace(obj interface{}) {
 	defer n.heartbeat("addNamespace")
 	namespace := obj.(*v1.Namespace)
 	klog.V(2).Infof("Processing Namespace %s ADD event, labels: %v", namespace.Name, namespace.Labels)
+	n.syncBaselineViolations(namespace, false)
 	addressGroupKeys := n.filterAddressGroupsForNamespace(namespace)
 	groupKeys := n.filterInternalGroupsForNamespace(namespace)
 	for group := range addressGroupKeys {
@@ -1037,6 +1098,10 @@ func (n *NetworkPolicyController) updateNamespace(oldObj, curObj interface{}) {
 	oldNamespace := oldObj.(*v1.Namespace)
 	curNamespace := curObj.(*v1.Namespace)
 	klog.V(2).Infof("Processing Namespace %s UPDATE event, labels: %v", curNamespace.Name, curNamespace.Labels)
+	if baselineModeFromAnnotations(oldNamespace.Annotations) != baselineModeFromAnnotations(curNamespace.Annotations) {
+		n.syncBaselineViolations(curNamespace, false)
+		n.reprocessBaselineANPs(curNamespace.Name)
+	}
 	// No need to trigger processing of groups if there is no change in the
 	// Namespace labels.
 	if labels.Equals(labels.Set(oldNamespace.Labels), labels.Set(curNamespace.Labels)) {
@@ -1080,6 +1145,7 @@ func (n *NetworkPolicyController) deleteNamespace(old interface{}) {
 	defer n.heartbeat("deleteNamespace")
 
 	klog.V(2).Infof("Processing Namespace %s DELETE event, labels: %v", namespace.Name, namespace.Labels)
+	n.syncBaselineViolations(namespace, true)
 	// Find groups matching deleted Namespace's labels and enqueue them
 	// for further processing.
 	addressGroupKeys := n.filterAddressGroupsForNamespace(namespace)
@@ -1165,6 +1231,7 @@ func (n *NetworkPolicyController) Run(stopCh <-chan struct{}) {
 	defer n.addressGroupQueue.ShutDown()
 	defer n.internalNetworkPolicyQueue.ShutDown()
 	defer n.internalGroupQueue.ShutDown()
+	defer n.ruleScheduleQueue.ShutDown()
 
 	klog.Infof("Starting %s", controllerName)
 	defer klog.Infof("Shutting down %s", controllerName)
@@ -1172,7 +1239,7 @@ func (n *NetworkPolicyController) Run(stopCh <-chan struct{}) {
 	cacheSyncs := []cache.InformerSynced{n.podListerSynced, n.namespaceListerSynced, n.networkPolicyListerSynced}
 	// Only wait for cnpListerSynced and anpListerSynced when AntreaPolicy feature gate is enabled.
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
-		cacheSyncs = append(cacheSyncs, n.cnpListerSynced, n.anpListerSynced, n.cgListerSynced)
+		cacheSyncs = append(cacheSyncs, n.cnpListerSynced, n.anpListerSynced, n.cgListerSynced, n.serviceListerSynced, n.endpointsListerSynced)
 	}
 	if !cache.WaitForNamedCacheSync(controllerName, stopCh, cacheSyncs...) {
 		return
@@ -1184,6 +1251,9 @@ func (n *NetworkPolicyController) Run(stopCh <-chan struct{}) {
 		go wait.Until(n.internalNetworkPolicyWorker, time.Second, stopCh)
 		go wait.Until(n.internalGroupWorker, time.Second, stopCh)
 	}
+	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
+		go wait.Until(n.ruleScheduleWorker, time.Second, stopCh)
+	}
 	<-stopCh
 }
 
@@ -70,6 +70,8 @@ type networkPolicyController struct {
 	podStore                   cache.Store
 	externalEntityStore        cache.Store
 	namespaceStore             cache.Store
+	serviceStore               cache.Store
+	endpointsStore             cache.Store
 	networkPolicyStore         cache.Store
 	cnpStore                   cache.Store
 	tierStore                  cache.Store
@@ -97,6 +99,8 @@ func newController(objects ...runtime.Object) (*fake.Clientset, *networkPolicyCo
 		crdClient,
 		informerFactory.Core().V1().Pods(),
 		informerFactory.Core().V1().Namespaces(),
+		informerFactory.Core().V1().Services(),
+		informerFactory.Core().V1().Endpoints(),
 		crdInformerFactory.Core().V1alpha2().ExternalEntities(),
 		informerFactory.Networking().V1().NetworkPolicies(),
 		crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies(),
@@ -116,11 +120,19 @@ func newController(objects ...runtime.Object) (*fake.Clientset, *networkPolicyCo
 	npController.cgInformer = cgInformer
 	npController.cgLister = cgInformer.Lister()
 	npController.cgListerSynced = alwaysReady
+	npController.serviceInformer = informerFactory.Core().V1().Services()
+	npController.serviceLister = informerFactory.Core().V1().Services().Lister()
+	npController.serviceListerSynced = alwaysReady
+	npController.endpointsInformer = informerFactory.Core().V1().Endpoints()
+	npController.endpointsLister = informerFactory.Core().V1().Endpoints().Lister()
+	npController.endpointsListerSynced = alwaysReady
 	return client, &networkPolicyController{
 		npController,
 		informerFactory.Core().V1().Pods().Informer().GetStore(),
 		crdInformerFactory.Core().V1alpha2().ExternalEntities().Informer().GetStore(),
 		informerFactory.Core().V1().Namespaces().Informer().GetStore(),
+		informerFactory.Core().V1().Services().Informer().GetStore(),
+		informerFactory.Core().V1().Endpoints().Informer().GetStore(),
 		informerFactory.Networking().V1().NetworkPolicies().Informer().GetStore(),
 		crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies().Informer().GetStore(),
 		crdInformerFactory.Security().V1alpha1().Tiers().Informer().GetStore(),
@@ -0,0 +1,248 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+// daysOfWeek maps the values of ScheduleWindow.DaysOfWeek to the corresponding weekdays.
+var daysOfWeek = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// scheduledPolicy identifies an Antrea-native policy which has rules with a schedule. namespace is empty for
+// ClusterNetworkPolicies.
+type scheduledPolicy struct {
+	namespace string
+	name      string
+}
+
+// parseTimeOfDay parses a time in "HH:MM" format and returns its offset from midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, must be in HH:MM format", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseScheduleWindow returns the offset from midnight at which the window opens, and the duration of the window.
+func parseScheduleWindow(window *secv1alpha1.ScheduleWindow) (time.Duration, time.Duration, error) {
+	start, err := parseTimeOfDay(window.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimeOfDay(window.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, day := range window.DaysOfWeek {
+		if _, ok := daysOfWeek[day]; !ok {
+			return 0, 0, fmt.Errorf("invalid day of week %q", day)
+		}
+	}
+	duration := end - start
+	if duration <= 0 {
+		duration += 24 * time.Hour
+	}
+	return start, duration, nil
+}
+
+// windowOpensOn returns whether the window opens on the provided day of the week.
+func windowOpensOn(window *secv1alpha1.ScheduleWindow, weekday time.Weekday) bool {
+	if len(window.DaysOfWeek) == 0 {
+		return true
+	}
+	for _, day := range window.DaysOfWeek {
+		if daysOfWeek[day] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSchedule returns an error if the schedule cannot be evaluated.
+func validateSchedule(schedule *secv1alpha1.RuleSchedule) error {
+	if len(schedule.Windows) == 0 {
+		return fmt.Errorf("schedule must have at least one window")
+	}
+	for i := range schedule.Windows {
+		if _, _, err := parseScheduleWindow(&schedule.Windows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getScheduleState returns whether a rule with the provided schedule is active at the provided time, and the time of
+// the next opening or closing of one of its windows, at which the rule must be evaluated again. A rule without
+// schedule is always active, in which case the returned time is zero.
+func getScheduleState(schedule *secv1alpha1.RuleSchedule, now time.Time) (bool, time.Time) {
+	if schedule == nil {
+		return true, time.Time{}
+	}
+	var active bool
+	var next time.Time
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range schedule.Windows {
+		window := &schedule.Windows[i]
+		start, duration, err := parseScheduleWindow(window)
+		if err != nil {
+			// Schedules are validated by the webhook, this should not happen.
+			klog.Errorf("Ignoring invalid schedule window: %v", err)
+			continue
+		}
+		// A window which opened yesterday may still be open, and a window opens at least once a week.
+		for d := -1; d <= 7; d++ {
+			day := today.AddDate(0, 0, d)
+			if !windowOpensOn(window, day.Weekday()) {
+				continue
+			}
+			opening := day.Add(start)
+			closing := opening.Add(duration)
+			if !now.Before(opening) && now.Before(closing) {
+				active = true
+			}
+			for _, t := range []time.Time{opening, closing} {
+				if t.After(now) && (next.IsZero() || t.Before(next)) {
+					next = t
+				}
+			}
+		}
+	}
+	return active, next
+}
+
+// scheduleEvaluator evaluates the schedules of the rules of a policy at a given time, and keeps track of the earliest
+// time at which they must be evaluated again.
+type scheduleEvaluator struct {
+	now time.Time
+	// next is zero if none of the evaluated rules has a schedule.
+	next time.Time
+}
+
+// isActive returns whether the rule is active at the time of the evaluator.
+func (e *scheduleEvaluator) isActive(rule *secv1alpha1.Rule) bool {
+	active, next := getScheduleState(rule.Schedule, e.now)
+	if !next.IsZero() && (e.next.IsZero() || next.Before(e.next)) {
+		e.next = next
+	}
+	return active
+}
+
+// scheduleReprocess makes sure that the provided policy is processed again at the provided time, when the state of
+// at least one of its scheduled rules changes. It does nothing if the time is zero.
+func (n *NetworkPolicyController) scheduleReprocess(policy scheduledPolicy, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	// The queue only keeps the earliest time for a given policy, and reprocessing a policy too early is harmless.
+	n.ruleScheduleQueue.AddAfter(policy, time.Until(at))
+}
+
+func (n *NetworkPolicyController) ruleScheduleWorker() {
+	for n.processNextRuleScheduleWorkItem() {
+	}
+}
+
+// processNextRuleScheduleWorkItem re-computes the internal NetworkPolicy of a policy with scheduled rules, so that
+// the rules whose windows just opened or closed are added to or removed from it. This function returns false if
+// and only if the work queue was shutdown.
+func (n *NetworkPolicyController) processNextRuleScheduleWorkItem() bool {
+	item, quit := n.ruleScheduleQueue.Get()
+	if quit {
+		return false
+	}
+	defer n.ruleScheduleQueue.Done(item)
+
+	n.reprocessScheduledPolicy(item.(scheduledPolicy))
+	return true
+}
+
+// getScheduledPolicy gets the current version of a policy from the listers, and returns the key of its internal
+// NetworkPolicy and a function computing the internal NetworkPolicy. It returns false if the policy has been deleted.
+func (n *NetworkPolicyController) getScheduledPolicy(policy scheduledPolicy) (string, func() *antreatypes.NetworkPolicy, bool) {
+	if policy.namespace == "" {
+		cnp, err := n.cnpLister.Get(policy.name)
+		if err != nil {
+			return "", nil, false
+		}
+		return internalNetworkPolicyKeyFunc(cnp), func() *antreatypes.NetworkPolicy {
+			klog.Infof("Reprocessing ClusterNetworkPolicy %s after a schedule window of its rules opened or closed", cnp.Name)
+			return n.processClusterNetworkPolicy(cnp)
+		}, true
+	}
+	anp, err := n.anpLister.NetworkPolicies(policy.namespace).Get(policy.name)
+	if err != nil {
+		return "", nil, false
+	}
+	return internalNetworkPolicyKeyFunc(anp), func() *antreatypes.NetworkPolicy {
+		klog.Infof("Reprocessing Antrea NetworkPolicy %s/%s after a schedule window of its rules opened or closed", anp.Namespace, anp.Name)
+		return n.processAntreaNetworkPolicy(anp)
+	}, true
+}
+
+// reprocessScheduledPolicy updates the internal NetworkPolicy of a policy with scheduled rules. The internal
+// NetworkPolicy store is locked while the policy is read from the lister and processed, so that the DELETE event of
+// the policy is handled either before, in which case the policy is skipped, or after, in which case it removes the
+// updated internal NetworkPolicy. A policy whose ADD event has not been handled yet is skipped, as it will be
+// processed with the current time by the event handler.
+func (n *NetworkPolicyController) reprocessScheduledPolicy(policy scheduledPolicy) {
+	n.internalNetworkPolicyMutex.Lock()
+	key, process, exists := n.getScheduledPolicy(policy)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		return
+	}
+	oldInternalNPObj, exists, _ := n.internalNetworkPolicyStore.Get(key)
+	if !exists {
+		n.internalNetworkPolicyMutex.Unlock()
+		return
+	}
+	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
+	curInternalNP := process()
+	// Must preserve old internal NetworkPolicy Span.
+	curInternalNP.SpanMeta = oldInternalNP.SpanMeta
+	n.internalNetworkPolicyStore.Update(curInternalNP)
+	n.internalNetworkPolicyMutex.Unlock()
+	// Enqueue addressGroup keys to update their Node span.
+	for _, rule := range curInternalNP.Rules {
+		for _, addrGroupName := range rule.From.AddressGroups {
+			n.enqueueAddressGroup(addrGroupName)
+		}
+		for _, addrGroupName := range rule.To.AddressGroups {
+			n.enqueueAddressGroup(addrGroupName)
+		}
+	}
+	n.enqueueInternalNetworkPolicy(key)
+	for _, atg := range oldInternalNP.AppliedToGroups {
+		n.deleteDereferencedAppliedToGroup(atg)
+	}
+	n.deleteDereferencedAddressGroups(oldInternalNP)
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func TestGetScheduleState(t *testing.T) {
+	// 2021-03-06 is a Saturday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name           string
+		schedule       *secv1alpha1.RuleSchedule
+		now            time.Time
+		expectedActive bool
+		expectedNext   time.Time
+	}{
+		{
+			name:           "no schedule",
+			now:            at(6, 10, 0),
+			expectedActive: true,
+		},
+		{
+			name: "daily window open",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{Start: "09:00", End: "17:00"},
+			}},
+			now:            at(6, 10, 0),
+			expectedActive: true,
+			expectedNext:   at(6, 17, 0),
+		},
+		{
+			name: "daily window closed",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{Start: "09:00", End: "17:00"},
+			}},
+			now:            at(6, 17, 0),
+			expectedActive: false,
+			expectedNext:   at(7, 9, 0),
+		},
+		{
+			name: "overnight window opened yesterday",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{DaysOfWeek: []string{"Fri"}, Start: "22:00", End: "02:00"},
+			}},
+			now:            at(6, 1, 30),
+			expectedActive: true,
+			expectedNext:   at(6, 2, 0),
+		},
+		{
+			name: "window not opened on this day",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{DaysOfWeek: []string{"Mon", "Tue"}, Start: "09:00", End: "17:00"},
+			}},
+			now:            at(6, 10, 0),
+			expectedActive: false,
+			expectedNext:   at(8, 9, 0),
+		},
+		{
+			name: "whole day window",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{DaysOfWeek: []string{"Sat"}, Start: "00:00", End: "00:00"},
+			}},
+			now:            at(6, 23, 59),
+			expectedActive: true,
+			expectedNext:   at(7, 0, 0),
+		},
+		{
+			name: "earliest boundary of multiple windows",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{Start: "09:00", End: "17:00"},
+				{Start: "12:00", End: "13:00"},
+			}},
+			now:            at(6, 10, 0),
+			expectedActive: true,
+			expectedNext:   at(6, 12, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, next := getScheduleState(tt.schedule, tt.now)
+			assert.Equal(t, tt.expectedActive, active)
+			assert.Equal(t, tt.expectedNext, next)
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    *secv1alpha1.RuleSchedule
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{DaysOfWeek: []string{"Mon", "Sun"}, Start: "23:00", End: "01:30"},
+			}},
+		},
+		{
+			name:        "no window",
+			schedule:    &secv1alpha1.RuleSchedule{},
+			expectedErr: true,
+		},
+		{
+			name: "invalid time",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{Start: "24:00", End: "01:00"},
+			}},
+			expectedErr: true,
+		},
+		{
+			name: "invalid day",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+				{DaysOfWeek: []string{"Monday"}, Start: "09:00", End: "17:00"},
+			}},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedule(tt.schedule)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReprocessScheduledPolicy(t *testing.T) {
+	cnp := getCNP()
+	cnp.UID = "uidA"
+	key := internalNetworkPolicyKeyFunc(cnp)
+	_, npc := newController()
+	npc.cnpStore.Add(cnp)
+	npc.addCNP(cnp)
+	obj, _, _ := npc.internalNetworkPolicyStore.Get(key)
+	internalNP := obj.(*antreatypes.NetworkPolicy)
+	require.Len(t, internalNP.Rules, 2)
+	internalNP.SpanMeta = antreatypes.SpanMeta{NodeNames: sets.NewString("node1")}
+
+	reprocess := func() {
+		npc.ruleScheduleQueue.Add(scheduledPolicy{name: cnp.Name})
+		require.True(t, npc.processNextRuleScheduleWorkItem())
+	}
+	getInternalNP := func() *antreatypes.NetworkPolicy {
+		obj, found, _ := npc.internalNetworkPolicyStore.Get(key)
+		if !found {
+			return nil
+		}
+		return obj.(*antreatypes.NetworkPolicy)
+	}
+
+	// The egress rule gets a window which is not open today nor yesterday. The current version of the policy is read
+	// from the lister.
+	var closedDay string
+	for day, weekday := range daysOfWeek {
+		if weekday == (time.Now().UTC().Weekday()+3)%7 {
+			closedDay = day
+		}
+	}
+	updatedCNP := cnp.DeepCopy()
+	updatedCNP.Spec.Egress[0].Schedule = &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.ScheduleWindow{
+		{Start: "09:00", End: "10:00", DaysOfWeek: []string{closedDay}},
+	}}
+	npc.cnpStore.Update(updatedCNP)
+	reprocess()
+	internalNP = getInternalNP()
+	require.NotNil(t, internalNP)
+	assert.Len(t, internalNP.Rules, 1)
+	assert.Equal(t, sets.NewString("node1"), internalNP.NodeNames)
+
+	// A policy missing from the lister is skipped.
+	npc.cnpStore.Delete(updatedCNP)
+	reprocess()
+	assert.Equal(t, internalNP, getInternalNP())
+
+	// A policy whose internal NetworkPolicy has been deleted is not re-created.
+	npc.deleteCNP(updatedCNP)
+	npc.cnpStore.Add(updatedCNP)
+	reprocess()
+	assert.Nil(t, getInternalNP())
+
+	// An UPDATE event without internal NetworkPolicy is ignored.
+	npc.updateCNP(updatedCNP, updatedCNP)
+	assert.Nil(t, getInternalNP())
+	assert.Len(t, npc.appliedToGroupStore.List(), 0)
+	assert.Len(t, npc.addressGroupStore.List(), 0)
+}
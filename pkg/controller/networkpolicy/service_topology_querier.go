@@ -0,0 +1,487 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/store"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+// ReachabilityOutcome describes whether the traffic sent to a Service endpoint is allowed by the NetworkPolicies
+// applied to it.
+type ReachabilityOutcome string
+
+const (
+	// ReachabilityAllowed means that the traffic is allowed from any source.
+	ReachabilityAllowed ReachabilityOutcome = "Allowed"
+	// ReachabilityRestricted means that the traffic is only allowed from some sources.
+	ReachabilityRestricted ReachabilityOutcome = "Restricted"
+	// ReachabilityDenied means that the traffic is denied from any source.
+	ReachabilityDenied ReachabilityOutcome = "Denied"
+)
+
+// anyTopologyKey is the Service topology key which allows to fall back to any endpoint.
+const anyTopologyKey = "*"
+
+// ServiceTopologyQuerier handles requests for antctl query servicetopology.
+type ServiceTopologyQuerier interface {
+	// QueryServiceTopology returns the distribution of the endpoints of the provided Service port across zones and
+	// Nodes, along with whether the NetworkPolicies applied to each endpoint allow traffic to it. If port is empty,
+	// the first port of the Service is used.
+	QueryServiceTopology(namespace, serviceName, port string) (*ServiceTopologyResponse, error)
+}
+
+// serviceTopologyQuerier implements the ServiceTopologyQuerier interface.
+type serviceTopologyQuerier struct {
+	networkPolicyController *NetworkPolicyController
+	nodeLister              corelisters.NodeLister
+}
+
+// ServiceTopologyResponse is the reply struct for antctl servicetopology queries.
+type ServiceTopologyResponse struct {
+	Namespace    string         `json:"namespace,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	Port         string         `json:"port,omitempty"`
+	TopologyKeys []string       `json:"topologyKeys,omitempty"`
+	Zones        []ZoneTopology `json:"zones,omitempty"`
+	// Warnings lists the zones and Nodes in which the Service may have no reachable endpoint.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ZoneTopology struct {
+	Zone               string         `json:"zone,omitempty"`
+	Nodes              []NodeTopology `json:"nodes,omitempty"`
+	Endpoints          int            `json:"endpoints"`
+	ReachableEndpoints int            `json:"reachableEndpoints"`
+}
+
+type NodeTopology struct {
+	Node               string            `json:"node,omitempty"`
+	Endpoints          []ServiceEndpoint `json:"endpoints,omitempty"`
+	ReachableEndpoints int               `json:"reachableEndpoints"`
+}
+
+type ServiceEndpoint struct {
+	Namespace string              `json:"namespace,omitempty"`
+	Name      string              `json:"name,omitempty"`
+	IP        string              `json:"ip,omitempty"`
+	Port      int32               `json:"port,omitempty"`
+	Outcome   ReachabilityOutcome `json:"outcome,omitempty"`
+	// Policies lists the policies whose ingress rules match the traffic sent to the endpoint.
+	Policies []PolicyRef `json:"policies,omitempty"`
+}
+
+// NewServiceTopologyQuerier returns a new *serviceTopologyQuerier.
+func NewServiceTopologyQuerier(networkPolicyController *NetworkPolicyController, nodeInformer coreinformers.NodeInformer) *serviceTopologyQuerier {
+	return &serviceTopologyQuerier{
+		networkPolicyController: networkPolicyController,
+		nodeLister:              nodeInformer.Lister(),
+	}
+}
+
+// QueryServiceTopology computes the endpoints of the Service from its selector, like the Endpoints controller does,
+// and evaluates the ingress rules of the policies applied to each of them for the Service target port. A nil
+// response is returned if the Service doesn't exist.
+func (q *serviceTopologyQuerier) QueryServiceTopology(namespace, serviceName, port string) (*ServiceTopologyResponse, error) {
+	service, err := q.networkPolicyController.serviceLister.Services(namespace).Get(serviceName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(service.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("Service %s/%s has no selector", namespace, serviceName)
+	}
+	servicePort, err := getServicePort(service, port)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := q.networkPolicyController.podLister.Pods(namespace).List(labels.SelectorFromSet(service.Spec.Selector))
+	if err != nil {
+		return nil, err
+	}
+	zones := map[string]*ZoneTopology{}
+	nodes := map[string]*NodeTopology{}
+	getZone := func(zone string) *ZoneTopology {
+		if _, ok := zones[zone]; !ok {
+			zones[zone] = &ZoneTopology{Zone: zone}
+		}
+		return zones[zone]
+	}
+	nodeZones := map[string]string{}
+	allNodes, err := q.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range allNodes {
+		nodeZones[node.Name] = getNodeZone(node)
+		// Zones without any endpoint are reported too, as clients in them may have no endpoint to connect to.
+		getZone(nodeZones[node.Name])
+	}
+	for _, pod := range pods {
+		if !isServiceEndpoint(pod) {
+			continue
+		}
+		targetPort, targetPortName, found := resolveTargetPort(pod, servicePort)
+		if !found {
+			continue
+		}
+		outcome, refs := evaluateIngressReachability(q.getAppliedPolicies(pod), q.networkPolicyController.filterAppliedToGroupsForPodOrExternalEntity(pod),
+			servicePort.Protocol, targetPort, targetPortName)
+		nodeName := pod.Spec.NodeName
+		if _, ok := nodes[nodeName]; !ok {
+			nodes[nodeName] = &NodeTopology{Node: nodeName}
+		}
+		node := nodes[nodeName]
+		zone := getZone(nodeZones[nodeName])
+		node.Endpoints = append(node.Endpoints, ServiceEndpoint{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			IP:        pod.Status.PodIP,
+			Port:      targetPort,
+			Outcome:   outcome,
+			Policies:  refs,
+		})
+		zone.Endpoints++
+		if outcome != ReachabilityDenied {
+			node.ReachableEndpoints++
+			zone.ReachableEndpoints++
+		}
+	}
+	for nodeName, node := range nodes {
+		zone := zones[nodeZones[nodeName]]
+		zone.Nodes = append(zone.Nodes, *node)
+	}
+	response := &ServiceTopologyResponse{
+		Namespace:    namespace,
+		Name:         serviceName,
+		Port:         getServicePortName(servicePort),
+		TopologyKeys: service.Spec.TopologyKeys,
+	}
+	for _, zone := range zones {
+		sort.Slice(zone.Nodes, func(i, j int) bool {
+			return zone.Nodes[i].Node < zone.Nodes[j].Node
+		})
+		response.Zones = append(response.Zones, *zone)
+	}
+	sort.Slice(response.Zones, func(i, j int) bool {
+		return response.Zones[i].Zone < response.Zones[j].Zone
+	})
+	response.Warnings = getServiceTopologyWarnings(response)
+	return response, nil
+}
+
+// getAppliedPolicies returns the internal NetworkPolicies which apply to the provided Pod, either for the whole
+// policy or for some of its rules.
+func (q *serviceTopologyQuerier) getAppliedPolicies(pod *corev1.Pod) []*antreatypes.NetworkPolicy {
+	var policies []*antreatypes.NetworkPolicy
+	seen := sets.String{}
+	for appliedToGroupKey := range q.networkPolicyController.filterAppliedToGroupsForPodOrExternalEntity(pod) {
+		objs, _ := q.networkPolicyController.internalNetworkPolicyStore.GetByIndex(store.AppliedToGroupIndex, appliedToGroupKey)
+		for _, obj := range objs {
+			policy := obj.(*antreatypes.NetworkPolicy)
+			if seen.Has(policy.Name) {
+				continue
+			}
+			seen.Insert(policy.Name)
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// getServicePort returns the ServicePort matching the provided name or port number, or the first port of the Service
+// if port is empty.
+func getServicePort(service *corev1.Service, port string) (*corev1.ServicePort, error) {
+	if len(service.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("Service %s/%s has no port", service.Namespace, service.Name)
+	}
+	if port == "" {
+		return &service.Spec.Ports[0], nil
+	}
+	for i := range service.Spec.Ports {
+		servicePort := &service.Spec.Ports[i]
+		if servicePort.Name == port || fmt.Sprint(servicePort.Port) == port {
+			return servicePort, nil
+		}
+	}
+	return nil, fmt.Errorf("Service %s/%s has no port %s", service.Namespace, service.Name, port)
+}
+
+func getServicePortName(servicePort *corev1.ServicePort) string {
+	if servicePort.Name != "" {
+		return servicePort.Name
+	}
+	return fmt.Sprint(servicePort.Port)
+}
+
+// getNodeZone returns the zone of the Node from its well-known labels, or an empty string if it is not set.
+func getNodeZone(node *corev1.Node) string {
+	if zone, ok := node.Labels[corev1.LabelZoneFailureDomainStable]; ok {
+		return zone
+	}
+	return node.Labels[corev1.LabelZoneFailureDomain]
+}
+
+// isServiceEndpoint returns whether the Pod would be a ready endpoint of the Services selecting it.
+func isServiceEndpoint(pod *corev1.Pod) bool {
+	if pod.Spec.NodeName == "" || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// resolveTargetPort returns the port number and the port name of the Pod which the Service port targets.
+func resolveTargetPort(pod *corev1.Pod, servicePort *corev1.ServicePort) (int32, string, bool) {
+	targetPort := servicePort.TargetPort
+	if targetPort.Type == intstr.String && targetPort.StrVal != "" {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == targetPort.StrVal && port.Protocol == servicePort.Protocol {
+					return port.ContainerPort, port.Name, true
+				}
+			}
+		}
+		return 0, "", false
+	}
+	portNumber := targetPort.IntVal
+	if portNumber == 0 {
+		// The target port defaults to the Service port.
+		portNumber = servicePort.Port
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.ContainerPort == portNumber && port.Protocol == servicePort.Protocol {
+				return portNumber, port.Name, true
+			}
+		}
+	}
+	return portNumber, "", true
+}
+
+// evaluateIngressReachability evaluates the ingress rules of the provided policies which apply to the Pod, given the
+// AppliedToGroups it belongs to, for the traffic sent to the provided port. The rules are evaluated in the order they
+// are enforced in: Antrea-native policies of the Tiers before the baseline Tier, K8s NetworkPolicies and baseline
+// Antrea-native policies. Only the rules matching any source decide the outcome for all sources, the rules matching
+// some sources make the endpoint Restricted, unless the traffic from these sources is decided the same way.
+func evaluateIngressReachability(policies []*antreatypes.NetworkPolicy, appliedToGroups sets.String,
+	protocol corev1.Protocol, port int32, portName string) (ReachabilityOutcome, []PolicyRef) {
+	type ruleRef struct {
+		policy *antreatypes.NetworkPolicy
+		rule   *controlplane.NetworkPolicyRule
+	}
+	var antreaRules, k8sRules, baselineRules []ruleRef
+	for _, policy := range policies {
+		for i := range policy.Rules {
+			rule := &policy.Rules[i]
+			if rule.Direction != controlplane.DirectionIn {
+				continue
+			}
+			if policy.AppliedToPerRule && !appliedToGroups.HasAny(rule.AppliedToGroups...) {
+				continue
+			}
+			r := ruleRef{policy: policy, rule: rule}
+			if policy.Priority == nil {
+				k8sRules = append(k8sRules, r)
+			} else if policy.TierPriority != nil && *policy.TierPriority == BaselineTierPriority {
+				baselineRules = append(baselineRules, r)
+			} else {
+				antreaRules = append(antreaRules, r)
+			}
+		}
+	}
+	sortRules := func(rules []ruleRef) {
+		sort.SliceStable(rules, func(i, j int) bool {
+			pi, pj := rules[i].policy, rules[j].policy
+			if pi.TierPriority != nil && pj.TierPriority != nil && *pi.TierPriority != *pj.TierPriority {
+				return *pi.TierPriority < *pj.TierPriority
+			}
+			if *pi.Priority != *pj.Priority {
+				return *pi.Priority < *pj.Priority
+			}
+			return rules[i].rule.Priority < rules[j].rule.Priority
+		})
+	}
+	sortRules(antreaRules)
+	sortRules(baselineRules)
+
+	var refs []PolicyRef
+	seenRefs := sets.String{}
+	addRef := func(policy *antreatypes.NetworkPolicy) {
+		if seenRefs.Has(string(policy.UID)) {
+			return
+		}
+		seenRefs.Insert(string(policy.UID))
+		refs = append(refs, PolicyRef{
+			Namespace: policy.SourceRef.Namespace,
+			Name:      policy.SourceRef.Name,
+			UID:       policy.SourceRef.UID,
+		})
+	}
+	// someAllowed and someDenied record whether the traffic from some sources has been allowed or denied by a rule
+	// matching only these sources.
+	someAllowed, someDenied := false, false
+	outcome := func(allowed bool) (ReachabilityOutcome, []PolicyRef) {
+		if allowed && !someDenied {
+			return ReachabilityAllowed, refs
+		}
+		if !allowed && !someAllowed {
+			return ReachabilityDenied, refs
+		}
+		return ReachabilityRestricted, refs
+	}
+	evaluateAntreaRules := func(rules []ruleRef) (decided bool, allowed bool) {
+		for _, r := range rules {
+			if !ruleMatchesPort(r.rule, protocol, port, portName) {
+				continue
+			}
+			addRef(r.policy)
+			allow := r.rule.Action == nil || *r.rule.Action == secv1alpha1.RuleActionAllow
+			if peerMatchesAll(&r.rule.From) {
+				return true, allow
+			}
+			if allow {
+				someAllowed = true
+			} else {
+				someDenied = true
+			}
+		}
+		return false, false
+	}
+	if decided, allowed := evaluateAntreaRules(antreaRules); decided {
+		return outcome(allowed)
+	}
+	// K8s NetworkPolicies isolate the Pod as soon as one of them applies to it for ingress, only the traffic allowed
+	// by one of their rules is then allowed.
+	if len(k8sRules) > 0 {
+		for _, r := range k8sRules {
+			if !ruleMatchesPort(r.rule, protocol, port, portName) {
+				continue
+			}
+			addRef(r.policy)
+			if peerMatchesAll(&r.rule.From) {
+				return outcome(true)
+			}
+			if len(r.rule.From.AddressGroups) > 0 || len(r.rule.From.IPBlocks) > 0 {
+				someAllowed = true
+			}
+		}
+		for _, r := range k8sRules {
+			addRef(r.policy)
+		}
+		return outcome(false)
+	}
+	if decided, allowed := evaluateAntreaRules(baselineRules); decided {
+		return outcome(allowed)
+	}
+	return outcome(true)
+}
+
+// ruleMatchesPort returns whether the rule matches the traffic sent to the provided port.
+func ruleMatchesPort(rule *controlplane.NetworkPolicyRule, protocol corev1.Protocol, port int32, portName string) bool {
+	if len(rule.Services) == 0 {
+		return true
+	}
+	for _, service := range rule.Services {
+		serviceProtocol := controlplane.ProtocolTCP
+		if service.Protocol != nil {
+			serviceProtocol = *service.Protocol
+		}
+		if string(serviceProtocol) != string(protocol) {
+			continue
+		}
+		if service.Port == nil {
+			return true
+		}
+		if service.Port.Type == intstr.String {
+			if portName != "" && service.Port.StrVal == portName {
+				return true
+			}
+			continue
+		}
+		endPort := service.Port.IntVal
+		if service.EndPort != nil {
+			endPort = *service.EndPort
+		}
+		if port >= service.Port.IntVal && port <= endPort {
+			return true
+		}
+	}
+	return false
+}
+
+// peerMatchesAll returns whether the peer matches all the IP addresses of an address family.
+func peerMatchesAll(peer *controlplane.NetworkPolicyPeer) bool {
+	for _, ipBlock := range peer.IPBlocks {
+		if ipBlock.CIDR.PrefixLength == 0 && len(ipBlock.Except) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getServiceTopologyWarnings returns warnings for the zones and Nodes in which the endpoints of the Service are all
+// denied, or in which the Service has no endpoint while its topology keys prevent clients from falling back to the
+// endpoints of other zones.
+func getServiceTopologyWarnings(response *ServiceTopologyResponse) []string {
+	keys := sets.NewString(response.TopologyKeys...)
+	topologyAware := len(keys) > 0 && !keys.Has(anyTopologyKey)
+	zoneAware := topologyAware && keys.HasAny(corev1.LabelZoneFailureDomainStable, corev1.LabelZoneFailureDomain)
+	nodeAware := topologyAware && keys.Has(corev1.LabelHostname)
+	var warnings []string
+	for _, zone := range response.Zones {
+		zoneName := zone.Zone
+		if zoneName == "" {
+			zoneName = "<none>"
+		}
+		if zone.Endpoints > 0 && zone.ReachableEndpoints == 0 {
+			warning := fmt.Sprintf("All %d endpoints in zone %s are denied by NetworkPolicies", zone.Endpoints, zoneName)
+			if zoneAware {
+				warning += fmt.Sprintf(" and topology keys %v prevent clients in the zone from falling back to other zones", response.TopologyKeys)
+			}
+			warnings = append(warnings, warning)
+		} else if zone.Endpoints == 0 && zoneAware {
+			warnings = append(warnings, fmt.Sprintf("Zone %s has no endpoint and topology keys %v prevent clients in the zone from falling back to other zones", zoneName, response.TopologyKeys))
+		}
+		if !nodeAware {
+			continue
+		}
+		for _, node := range zone.Nodes {
+			if len(node.Endpoints) > 0 && node.ReachableEndpoints == 0 {
+				warnings = append(warnings, fmt.Sprintf("All %d endpoints on Node %s are denied by NetworkPolicies and topology keys %v prefer them for clients on the Node", len(node.Endpoints), node.Node, response.TopologyKeys))
+			}
+		}
+	}
+	return warnings
+}
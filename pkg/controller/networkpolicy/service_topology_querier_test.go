@@ -0,0 +1,271 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func TestEvaluateIngressReachability(t *testing.T) {
+	allow := secv1alpha1.RuleActionAllow
+	drop := secv1alpha1.RuleActionDrop
+	tierPriority := DefaultTierPriority
+	baselinePriority := BaselineTierPriority
+	port80 := intstr.FromInt(80)
+	portHTTP := intstr.FromString("http")
+	port443 := intstr.FromInt(443)
+	specificPeer := controlplane.NetworkPolicyPeer{AddressGroups: []string{"addressGroup1"}}
+
+	newPolicy := func(name string, tierPriority *int32, priority *float64, rules ...controlplane.NetworkPolicyRule) *antreatypes.NetworkPolicy {
+		return &antreatypes.NetworkPolicy{
+			UID:             types.UID(name),
+			Name:            name,
+			SourceRef:       &controlplane.NetworkPolicyReference{Name: name, Namespace: "ns1", UID: types.UID(name)},
+			TierPriority:    tierPriority,
+			Priority:        priority,
+			Rules:           rules,
+			AppliedToGroups: []string{"appliedToGroup1"},
+		}
+	}
+	p1, p2 := float64(1), float64(2)
+	antreaDropAll := newPolicy("anp-drop", &tierPriority, &p2, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      matchAllPeer,
+		Action:    &drop,
+	})
+	antreaAllowHTTP := newPolicy("anp-allow", &tierPriority, &p1, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      matchAllPeer,
+		Services:  []controlplane.Service{{Port: &portHTTP}},
+		Action:    &allow,
+	})
+	antreaAllowSpecific := newPolicy("anp-allow-specific", &tierPriority, &p1, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      specificPeer,
+		Action:    &allow,
+	})
+	k8sDenyAll := newPolicy("knp-deny", nil, nil, denyAllIngressRule)
+	k8sAllow443 := newPolicy("knp-allow-443", nil, nil, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      matchAllPeer,
+		Services:  []controlplane.Service{{Port: &port443}},
+	})
+	k8sAllow80Specific := newPolicy("knp-allow-80", nil, nil, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      specificPeer,
+		Services:  []controlplane.Service{{Port: &port80}},
+	})
+	baselineDropAll := newPolicy("acnp-baseline", &baselinePriority, &p1, controlplane.NetworkPolicyRule{
+		Direction: controlplane.DirectionIn,
+		From:      matchAllPeer,
+		Action:    &drop,
+	})
+	perRuleDrop := newPolicy("anp-per-rule", &tierPriority, &p1, controlplane.NetworkPolicyRule{
+		Direction:       controlplane.DirectionIn,
+		From:            matchAllPeer,
+		Action:          &drop,
+		AppliedToGroups: []string{"appliedToGroup2"},
+	})
+	perRuleDrop.AppliedToPerRule = true
+	perRuleDrop.AppliedToGroups = nil
+
+	tests := []struct {
+		name             string
+		policies         []*antreatypes.NetworkPolicy
+		expectedOutcome  ReachabilityOutcome
+		expectedPolicies []string
+	}{
+		{
+			name:            "no-policy",
+			expectedOutcome: ReachabilityAllowed,
+		},
+		{
+			name:             "antrea-drop-all",
+			policies:         []*antreatypes.NetworkPolicy{antreaDropAll},
+			expectedOutcome:  ReachabilityDenied,
+			expectedPolicies: []string{"anp-drop"},
+		},
+		{
+			name:             "antrea-allow-named-port-before-drop",
+			policies:         []*antreatypes.NetworkPolicy{antreaDropAll, antreaAllowHTTP},
+			expectedOutcome:  ReachabilityAllowed,
+			expectedPolicies: []string{"anp-allow"},
+		},
+		{
+			name:             "antrea-allow-specific-before-drop",
+			policies:         []*antreatypes.NetworkPolicy{antreaDropAll, antreaAllowSpecific},
+			expectedOutcome:  ReachabilityRestricted,
+			expectedPolicies: []string{"anp-allow-specific", "anp-drop"},
+		},
+		{
+			name:             "k8s-isolation",
+			policies:         []*antreatypes.NetworkPolicy{k8sDenyAll},
+			expectedOutcome:  ReachabilityDenied,
+			expectedPolicies: []string{"knp-deny"},
+		},
+		{
+			name:             "k8s-other-port-allowed",
+			policies:         []*antreatypes.NetworkPolicy{k8sAllow443},
+			expectedOutcome:  ReachabilityDenied,
+			expectedPolicies: []string{"knp-allow-443"},
+		},
+		{
+			name:             "k8s-specific-sources-allowed",
+			policies:         []*antreatypes.NetworkPolicy{k8sDenyAll, k8sAllow80Specific},
+			expectedOutcome:  ReachabilityRestricted,
+			expectedPolicies: []string{"knp-allow-80", "knp-deny"},
+		},
+		{
+			name:             "k8s-isolation-before-baseline",
+			policies:         []*antreatypes.NetworkPolicy{baselineDropAll, k8sAllow80Specific},
+			expectedOutcome:  ReachabilityRestricted,
+			expectedPolicies: []string{"knp-allow-80"},
+		},
+		{
+			name:             "baseline-drop-all",
+			policies:         []*antreatypes.NetworkPolicy{baselineDropAll},
+			expectedOutcome:  ReachabilityDenied,
+			expectedPolicies: []string{"acnp-baseline"},
+		},
+		{
+			name:            "rule-applied-to-other-group",
+			policies:        []*antreatypes.NetworkPolicy{perRuleDrop},
+			expectedOutcome: ReachabilityAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, refs := evaluateIngressReachability(tt.policies, sets.NewString("appliedToGroup1"), corev1.ProtocolTCP, 80, "http")
+			assert.Equal(t, tt.expectedOutcome, outcome)
+			var names []string
+			for _, ref := range refs {
+				names = append(names, ref.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedPolicies, names)
+		})
+	}
+}
+
+func TestGetServiceTopologyWarnings(t *testing.T) {
+	zones := []ZoneTopology{
+		{
+			Zone: "zone1",
+			Nodes: []NodeTopology{
+				{Node: "node1", Endpoints: []ServiceEndpoint{{Name: "pod1", Outcome: ReachabilityDenied}}},
+			},
+			Endpoints: 1,
+		},
+		{
+			Zone: "zone2",
+			Nodes: []NodeTopology{
+				{Node: "node2", Endpoints: []ServiceEndpoint{{Name: "pod2", Outcome: ReachabilityAllowed}}, ReachableEndpoints: 1},
+			},
+			Endpoints:          1,
+			ReachableEndpoints: 1,
+		},
+		{
+			Zone: "zone3",
+		},
+	}
+	tests := []struct {
+		name             string
+		topologyKeys     []string
+		expectedWarnings []string
+	}{
+		{
+			name: "no-topology-keys",
+			expectedWarnings: []string{
+				"All 1 endpoints in zone zone1 are denied by NetworkPolicies",
+			},
+		},
+		{
+			name:         "zone-topology-keys",
+			topologyKeys: []string{corev1.LabelZoneFailureDomainStable},
+			expectedWarnings: []string{
+				"All 1 endpoints in zone zone1 are denied by NetworkPolicies and topology keys [topology.kubernetes.io/zone] prevent clients in the zone from falling back to other zones",
+				"Zone zone3 has no endpoint and topology keys [topology.kubernetes.io/zone] prevent clients in the zone from falling back to other zones",
+			},
+		},
+		{
+			name:         "zone-topology-keys-with-fallback",
+			topologyKeys: []string{corev1.LabelZoneFailureDomainStable, "*"},
+			expectedWarnings: []string{
+				"All 1 endpoints in zone zone1 are denied by NetworkPolicies",
+			},
+		},
+		{
+			name:         "hostname-topology-keys",
+			topologyKeys: []string{corev1.LabelHostname},
+			expectedWarnings: []string{
+				"All 1 endpoints in zone zone1 are denied by NetworkPolicies",
+				"All 1 endpoints on Node node1 are denied by NetworkPolicies and topology keys [kubernetes.io/hostname] prefer them for clients on the Node",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &ServiceTopologyResponse{TopologyKeys: tt.topologyKeys, Zones: zones}
+			assert.Equal(t, tt.expectedWarnings, getServiceTopologyWarnings(response))
+		})
+	}
+}
+
+// errServiceLister is a ServiceLister which fails to get any Service.
+type errServiceLister struct {
+	err error
+}
+
+func (l errServiceLister) List(selector labels.Selector) ([]*corev1.Service, error) {
+	return nil, l.err
+}
+
+func (l errServiceLister) Services(namespace string) corelisters.ServiceNamespaceLister {
+	return l
+}
+
+func (l errServiceLister) Get(name string) (*corev1.Service, error) {
+	return nil, l.err
+}
+
+func TestQueryServiceTopologyServiceErrors(t *testing.T) {
+	_, c := newController()
+	q := NewServiceTopologyQuerier(c.NetworkPolicyController, c.informerFactory.Core().V1().Nodes())
+
+	// A missing Service is reported with a nil response.
+	response, err := q.QueryServiceTopology("ns1", "svc1", "")
+	require.NoError(t, err)
+	assert.Nil(t, response)
+
+	// Other errors are returned.
+	listerErr := errors.New("lister failed")
+	c.serviceLister = errServiceLister{err: listerErr}
+	response, err = q.QueryServiceTopology("ns1", "svc1", "")
+	assert.Equal(t, listerErr, err)
+	assert.Nil(t, response)
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+	"github.com/vmware-tanzu/antrea/pkg/querier"
+)
+
+// SpanQuerier handles requests for antctl query span.
+type SpanQuerier interface {
+	// QuerySpans returns the internal NetworkPolicies matching the filter, along with the AppliedToGroups and
+	// AddressGroups they refer to, and the Nodes to which each of them is sent. The Pod of the filter is ignored.
+	QuerySpans(filter *querier.NetworkPolicyQueryFilter) *SpanQueryResponse
+}
+
+// spanQuerier implements the SpanQuerier interface.
+type spanQuerier struct {
+	networkPolicyController *NetworkPolicyController
+}
+
+// SpanQueryResponse is the reply struct for antctl span queries.
+type SpanQueryResponse struct {
+	Policies []PolicySpan `json:"policies,omitempty"`
+}
+
+// PolicySpan describes an internal NetworkPolicy and the groups it refers to. Nodes is the span of the policy, i.e.
+// the Nodes to which it is sent. It is empty if the policy doesn't apply to any Pod or ExternalEntity.
+type PolicySpan struct {
+	Name            string      `json:"name"`
+	Source          string      `json:"source,omitempty"`
+	Nodes           []string    `json:"nodes"`
+	AppliedToGroups []GroupSpan `json:"appliedToGroups,omitempty"`
+	AddressGroups   []GroupSpan `json:"addressGroups,omitempty"`
+}
+
+// GroupSpan describes an AppliedToGroup or an AddressGroup. Selector is the normalized selector of the group, and
+// Members the number of Pods and ExternalEntities it selects. Missing is set if the group is not computed, e.g.
+// because it is being created.
+type GroupSpan struct {
+	Name     string   `json:"name"`
+	Selector string   `json:"selector,omitempty"`
+	Members  int      `json:"members"`
+	Nodes    []string `json:"nodes"`
+	Missing  bool     `json:"missing,omitempty"`
+}
+
+// NewSpanQuerier returns a new *spanQuerier.
+func NewSpanQuerier(networkPolicyController *NetworkPolicyController) *spanQuerier {
+	return &spanQuerier{networkPolicyController: networkPolicyController}
+}
+
+// QuerySpans implements SpanQuerier.
+func (q *spanQuerier) QuerySpans(filter *querier.NetworkPolicyQueryFilter) *SpanQueryResponse {
+	response := &SpanQueryResponse{Policies: []PolicySpan{}}
+	for _, obj := range q.networkPolicyController.internalNetworkPolicyStore.List() {
+		policy := obj.(*antreatypes.NetworkPolicy)
+		if !matchPolicyFilter(policy, filter) {
+			continue
+		}
+		response.Policies = append(response.Policies, q.getPolicySpan(policy))
+	}
+	sort.Slice(response.Policies, func(i, j int) bool {
+		return response.Policies[i].Name < response.Policies[j].Name
+	})
+	return response
+}
+
+func matchPolicyFilter(policy *antreatypes.NetworkPolicy, filter *querier.NetworkPolicyQueryFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Name != "" {
+		return policy.Name == filter.Name
+	}
+	if policy.SourceRef == nil {
+		return filter.SourceName == "" && filter.Namespace == "" && filter.SourceType == ""
+	}
+	if filter.SourceName != "" && policy.SourceRef.Name != filter.SourceName {
+		return false
+	}
+	if filter.Namespace != "" && policy.SourceRef.Namespace != filter.Namespace {
+		return false
+	}
+	if filter.SourceType != "" && string(policy.SourceRef.Type) != string(filter.SourceType) {
+		return false
+	}
+	return true
+}
+
+func (q *spanQuerier) getPolicySpan(policy *antreatypes.NetworkPolicy) PolicySpan {
+	span := PolicySpan{
+		Name:  policy.Name,
+		Nodes: spanNodes(policy.SpanMeta),
+	}
+	if policy.SourceRef != nil {
+		span.Source = policy.SourceRef.ToString()
+	}
+	appliedToGroups := sets.NewString(policy.AppliedToGroups...)
+	addressGroups := sets.NewString()
+	for _, rule := range policy.Rules {
+		appliedToGroups.Insert(rule.AppliedToGroups...)
+		addressGroups.Insert(rule.From.AddressGroups...)
+		addressGroups.Insert(rule.To.AddressGroups...)
+	}
+	for _, name := range appliedToGroups.List() {
+		groupSpan := GroupSpan{Name: name, Missing: true}
+		if obj, exists, _ := q.networkPolicyController.appliedToGroupStore.Get(name); exists {
+			group := obj.(*antreatypes.AppliedToGroup)
+			groupSpan = GroupSpan{Name: name, Selector: group.Selector.NormalizedName, Nodes: spanNodes(group.SpanMeta)}
+			for _, members := range group.GroupMemberByNode {
+				groupSpan.Members += len(members)
+			}
+		}
+		span.AppliedToGroups = append(span.AppliedToGroups, groupSpan)
+	}
+	for _, name := range addressGroups.List() {
+		groupSpan := GroupSpan{Name: name, Missing: true}
+		if obj, exists, _ := q.networkPolicyController.addressGroupStore.Get(name); exists {
+			group := obj.(*antreatypes.AddressGroup)
+			groupSpan = GroupSpan{Name: name, Selector: group.Selector.NormalizedName, Members: len(group.GroupMembers), Nodes: spanNodes(group.SpanMeta)}
+		}
+		span.AddressGroups = append(span.AddressGroups, groupSpan)
+	}
+	return span
+}
+
+// spanNodes returns the sorted Nodes of a span, or an empty slice if the span is empty or not computed yet.
+func spanNodes(span antreatypes.SpanMeta) []string {
+	if span.NodeNames == nil {
+		return []string{}
+	}
+	return span.NodeNames.List()
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+	"github.com/vmware-tanzu/antrea/pkg/querier"
+)
+
+func TestQuerySpans(t *testing.T) {
+	_, c := newController()
+	pod1 := &controlplane.GroupMember{Pod: &controlplane.PodReference{Name: "pod1", Namespace: "ns1"}}
+	pod2 := &controlplane.GroupMember{Pod: &controlplane.PodReference{Name: "pod2", Namespace: "ns1"}}
+	require.NoError(t, c.appliedToGroupStore.Create(&antreatypes.AppliedToGroup{
+		SpanMeta: antreatypes.SpanMeta{NodeNames: sets.NewString("node2", "node1")},
+		Name:     "atg1",
+		Selector: antreatypes.GroupSelector{NormalizedName: "namespace=ns1 And podSelector=app=web"},
+		GroupMemberByNode: map[string]controlplane.GroupMemberSet{
+			"node1": controlplane.NewGroupMemberSet(pod1),
+			"node2": controlplane.NewGroupMemberSet(pod2),
+		},
+	}))
+	require.NoError(t, c.addressGroupStore.Create(&antreatypes.AddressGroup{
+		SpanMeta:     antreatypes.SpanMeta{NodeNames: sets.NewString("node1", "node2")},
+		Name:         "ag1",
+		Selector:     antreatypes.GroupSelector{NormalizedName: "namespace=ns1 And podSelector=app=db"},
+		GroupMembers: controlplane.NewGroupMemberSet(),
+	}))
+	require.NoError(t, c.internalNetworkPolicyStore.Create(&antreatypes.NetworkPolicy{
+		SpanMeta:        antreatypes.SpanMeta{NodeNames: sets.NewString("node1", "node2")},
+		Name:            "uid1",
+		SourceRef:       &controlplane.NetworkPolicyReference{Type: controlplane.K8sNetworkPolicy, Namespace: "ns1", Name: "web"},
+		AppliedToGroups: []string{"atg1"},
+		Rules: []controlplane.NetworkPolicyRule{
+			{Direction: controlplane.DirectionOut, To: controlplane.NetworkPolicyPeer{AddressGroups: []string{"ag1", "ag2"}}},
+		},
+	}))
+	require.NoError(t, c.internalNetworkPolicyStore.Create(&antreatypes.NetworkPolicy{
+		SpanMeta:  antreatypes.SpanMeta{NodeNames: sets.NewString()},
+		Name:      "uid2",
+		SourceRef: &controlplane.NetworkPolicyReference{Type: controlplane.AntreaClusterNetworkPolicy, Name: "acnp"},
+	}))
+	q := NewSpanQuerier(c.NetworkPolicyController)
+
+	expectedPolicy1 := PolicySpan{
+		Name:   "uid1",
+		Source: "K8sNetworkPolicy:ns1/web",
+		Nodes:  []string{"node1", "node2"},
+		AppliedToGroups: []GroupSpan{
+			{Name: "atg1", Selector: "namespace=ns1 And podSelector=app=web", Members: 2, Nodes: []string{"node1", "node2"}},
+		},
+		AddressGroups: []GroupSpan{
+			{Name: "ag1", Selector: "namespace=ns1 And podSelector=app=db", Members: 0, Nodes: []string{"node1", "node2"}},
+			{Name: "ag2", Missing: true},
+		},
+	}
+	expectedPolicy2 := PolicySpan{
+		Name:   "uid2",
+		Source: "AntreaClusterNetworkPolicy:acnp",
+		Nodes:  []string{},
+	}
+	tests := []struct {
+		name     string
+		filter   *querier.NetworkPolicyQueryFilter
+		expected []PolicySpan
+	}{
+		{
+			name:     "all",
+			filter:   &querier.NetworkPolicyQueryFilter{},
+			expected: []PolicySpan{expectedPolicy1, expectedPolicy2},
+		},
+		{
+			name:     "name",
+			filter:   &querier.NetworkPolicyQueryFilter{Name: "uid2"},
+			expected: []PolicySpan{expectedPolicy2},
+		},
+		{
+			name:     "source",
+			filter:   &querier.NetworkPolicyQueryFilter{SourceName: "web", Namespace: "ns1"},
+			expected: []PolicySpan{expectedPolicy1},
+		},
+		{
+			name:     "type",
+			filter:   &querier.NetworkPolicyQueryFilter{SourceType: cpv1beta.AntreaClusterNetworkPolicy},
+			expected: []PolicySpan{expectedPolicy2},
+		},
+		{
+			name:     "no-match",
+			filter:   &querier.NetworkPolicyQueryFilter{SourceName: "web", Namespace: "ns2"},
+			expected: []PolicySpan{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, q.QuerySpans(tt.filter).Policies)
+		})
+	}
+}
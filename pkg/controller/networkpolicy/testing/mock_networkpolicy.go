@@ -14,7 +14,7 @@
 //
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy (interfaces: EndpointQuerier)
+// Source: github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy (interfaces: BaselineStatusQuerier,EndpointQuerier,ServiceTopologyQuerier,SpanQuerier)
 
 // Package testing is a generated GoMock package.
 package testing
@@ -22,9 +22,49 @@ package testing
 import (
 	gomock "github.com/golang/mock/gomock"
 	networkpolicy "github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+	querier "github.com/vmware-tanzu/antrea/pkg/querier"
 	reflect "reflect"
+	time "time"
 )
 
+// MockBaselineStatusQuerier is a mock of BaselineStatusQuerier interface
+type MockBaselineStatusQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockBaselineStatusQuerierMockRecorder
+}
+
+// MockBaselineStatusQuerierMockRecorder is the mock recorder for MockBaselineStatusQuerier
+type MockBaselineStatusQuerierMockRecorder struct {
+	mock *MockBaselineStatusQuerier
+}
+
+// NewMockBaselineStatusQuerier creates a new mock instance
+func NewMockBaselineStatusQuerier(ctrl *gomock.Controller) *MockBaselineStatusQuerier {
+	mock := &MockBaselineStatusQuerier{ctrl: ctrl}
+	mock.recorder = &MockBaselineStatusQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBaselineStatusQuerier) EXPECT() *MockBaselineStatusQuerierMockRecorder {
+	return m.recorder
+}
+
+// QueryBaselineStatus mocks base method
+func (m *MockBaselineStatusQuerier) QueryBaselineStatus(arg0 string, arg1 time.Duration) (*networkpolicy.BaselineStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBaselineStatus", arg0, arg1)
+	ret0, _ := ret[0].(*networkpolicy.BaselineStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBaselineStatus indicates an expected call of QueryBaselineStatus
+func (mr *MockBaselineStatusQuerierMockRecorder) QueryBaselineStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBaselineStatus", reflect.TypeOf((*MockBaselineStatusQuerier)(nil).QueryBaselineStatus), arg0, arg1)
+}
+
 // MockEndpointQuerier is a mock of EndpointQuerier interface
 type MockEndpointQuerier struct {
 	ctrl     *gomock.Controller
@@ -62,3 +102,78 @@ func (mr *MockEndpointQuerierMockRecorder) QueryNetworkPolicies(arg0, arg1 inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryNetworkPolicies", reflect.TypeOf((*MockEndpointQuerier)(nil).QueryNetworkPolicies), arg0, arg1)
 }
+
+// MockServiceTopologyQuerier is a mock of ServiceTopologyQuerier interface
+type MockServiceTopologyQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceTopologyQuerierMockRecorder
+}
+
+// MockServiceTopologyQuerierMockRecorder is the mock recorder for MockServiceTopologyQuerier
+type MockServiceTopologyQuerierMockRecorder struct {
+	mock *MockServiceTopologyQuerier
+}
+
+// NewMockServiceTopologyQuerier creates a new mock instance
+func NewMockServiceTopologyQuerier(ctrl *gomock.Controller) *MockServiceTopologyQuerier {
+	mock := &MockServiceTopologyQuerier{ctrl: ctrl}
+	mock.recorder = &MockServiceTopologyQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockServiceTopologyQuerier) EXPECT() *MockServiceTopologyQuerierMockRecorder {
+	return m.recorder
+}
+
+// QueryServiceTopology mocks base method
+func (m *MockServiceTopologyQuerier) QueryServiceTopology(arg0, arg1, arg2 string) (*networkpolicy.ServiceTopologyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryServiceTopology", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*networkpolicy.ServiceTopologyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryServiceTopology indicates an expected call of QueryServiceTopology
+func (mr *MockServiceTopologyQuerierMockRecorder) QueryServiceTopology(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryServiceTopology", reflect.TypeOf((*MockServiceTopologyQuerier)(nil).QueryServiceTopology), arg0, arg1, arg2)
+}
+
+// MockSpanQuerier is a mock of SpanQuerier interface
+type MockSpanQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpanQuerierMockRecorder
+}
+
+// MockSpanQuerierMockRecorder is the mock recorder for MockSpanQuerier
+type MockSpanQuerierMockRecorder struct {
+	mock *MockSpanQuerier
+}
+
+// NewMockSpanQuerier creates a new mock instance
+func NewMockSpanQuerier(ctrl *gomock.Controller) *MockSpanQuerier {
+	mock := &MockSpanQuerier{ctrl: ctrl}
+	mock.recorder = &MockSpanQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSpanQuerier) EXPECT() *MockSpanQuerierMockRecorder {
+	return m.recorder
+}
+
+// QuerySpans mocks base method
+func (m *MockSpanQuerier) QuerySpans(arg0 *querier.NetworkPolicyQueryFilter) *networkpolicy.SpanQueryResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuerySpans", arg0)
+	ret0, _ := ret[0].(*networkpolicy.SpanQueryResponse)
+	return ret0
+}
+
+// QuerySpans indicates an expected call of QuerySpans
+func (mr *MockSpanQuerierMockRecorder) QuerySpans(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySpans", reflect.TypeOf((*MockSpanQuerier)(nil).QuerySpans), arg0)
+}
@@ -284,6 +284,42 @@ func (a *antreaPolicyValidator) validatePort(ingress, egress []secv1alpha1.Rule)
 	return nil
 }
 
+// validateConnectionStates validates the connection states of the rules, if any. Packets of established connections
+// are allowed before the rules are evaluated, hence only Allow rules can be restricted to established connections.
+func (a *antreaPolicyValidator) validateConnectionStates(ingress, egress []secv1alpha1.Rule) error {
+	for _, rules := range [][]secv1alpha1.Rule{ingress, egress} {
+		for _, rule := range rules {
+			for _, state := range rule.ConnectionStates {
+				switch state {
+				case secv1alpha1.ConnectionStateNew, secv1alpha1.ConnectionStateRelated:
+				case secv1alpha1.ConnectionStateEstablished:
+					if rule.Action != nil && *rule.Action != secv1alpha1.RuleActionAllow {
+						return fmt.Errorf("connection state %s of rule %q is only supported with action Allow, as packets of established connections are always allowed", state, rule.Name)
+					}
+				default:
+					return fmt.Errorf("invalid connection state %q for rule %q", state, rule.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateSchedules validates the schedules of the rules, if any
+func (a *antreaPolicyValidator) validateSchedules(ingress, egress []secv1alpha1.Rule) error {
+	for _, rules := range [][]secv1alpha1.Rule{ingress, egress} {
+		for _, rule := range rules {
+			if rule.Schedule == nil {
+				continue
+			}
+			if err := validateSchedule(rule.Schedule); err != nil {
+				return fmt.Errorf("invalid schedule for rule %q: %v", rule.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // validateAntreaGroup validates the admission of a ClusterGroup resource
 func (v *NetworkPolicyValidator) validateAntreaGroup(curCG, oldCG *corev1a2.ClusterGroup, op admv1.Operation, userInfo authenticationv1.UserInfo) (string, bool) {
 	allowed := true
@@ -417,6 +453,12 @@ func (a *antreaPolicyValidator) createValidate(curObj interface{}, userInfo auth
 	if err := a.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
 	}
+	if err := a.validateSchedules(ingress, egress); err != nil {
+		return err.Error(), false
+	}
+	if err := a.validateConnectionStates(ingress, egress); err != nil {
+		return err.Error(), false
+	}
 	return "", true
 }
 
@@ -539,6 +581,12 @@ func (a *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userI
 	if err := a.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
 	}
+	if err := a.validateSchedules(ingress, egress); err != nil {
+		return err.Error(), false
+	}
+	if err := a.validateConnectionStates(ingress, egress); err != nil {
+		return err.Error(), false
+	}
 	return a.validateTierForPolicy(tier)
 }
 
@@ -607,8 +655,18 @@ func (t *tierValidator) deleteValidate(oldObj interface{}, userInfo authenticati
 }
 
 // validateAntreaGroupSelectors ensures that an IPBlock is not set along with namespaceSelector and/or a
-// podSelector.
+// podSelector, and that a ServiceReference or ChildGroups are not set along with any other field.
 func validateAntreaGroupSelectors(s corev1a2.GroupSpec) (string, bool) {
+	if len(s.ChildGroups) > 0 {
+		if s.NamespaceSelector != nil || s.PodSelector != nil || s.IPBlock != nil || s.ServiceReference != nil {
+			return fmt.Sprint("cluster group childGroups cannot be set with other selectors, ipBlock or serviceReference"), false
+		}
+	}
+	if s.ServiceReference != nil {
+		if s.NamespaceSelector != nil || s.PodSelector != nil || s.IPBlock != nil {
+			return fmt.Sprint("cluster group serviceReference cannot be set with other selectors or ipBlock"), false
+		}
+	}
 	if s.IPBlock != nil {
 		if s.NamespaceSelector != nil && s.PodSelector != nil {
 			return fmt.Sprint("cluster group IPBlock cannot be set with other selectors"), false
@@ -617,16 +675,48 @@ func validateAntreaGroupSelectors(s corev1a2.GroupSpec) (string, bool) {
 	return "", true
 }
 
+// validateChildGroups ensures that ClusterGroups are nested at most one level deep, and that the
+// child ClusterGroups select Pods, as the members of a ClusterGroup with childGroups are the union
+// of the members of its children. The child ClusterGroups may be created after their parents.
+func (g *groupValidator) validateChildGroups(cg *corev1a2.ClusterGroup) (string, bool) {
+	parents, err := g.networkPolicyController.cgInformer.Informer().GetIndexer().ByIndex(ChildGroupIndex, cg.Name)
+	if err != nil {
+		return fmt.Sprintf("error retrieving parents of cluster group %s: %v", cg.Name, err), false
+	}
+	if len(parents) > 0 && (len(cg.Spec.ChildGroups) > 0 || cg.Spec.IPBlock != nil) {
+		return fmt.Sprintf("cluster group %s is a child of %d cluster groups and cannot set childGroups or ipBlock", cg.Name, len(parents)), false
+	}
+	for _, childGroup := range cg.Spec.ChildGroups {
+		if string(childGroup) == cg.Name {
+			return fmt.Sprintf("cluster group %s cannot be a child of itself", cg.Name), false
+		}
+		child, err := g.networkPolicyController.cgLister.Get(string(childGroup))
+		if err != nil {
+			continue
+		}
+		if len(child.Spec.ChildGroups) > 0 || child.Spec.IPBlock != nil {
+			return fmt.Sprintf("child cluster group %s cannot set childGroups or ipBlock", child.Name), false
+		}
+	}
+	return "", true
+}
+
 // createValidate validates the CREATE events of ClusterGroup resources.
 func (g *groupValidator) createValidate(curObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
 	curCG := curObj.(*corev1a2.ClusterGroup)
-	return validateAntreaGroupSelectors(curCG.Spec)
+	if reason, allowed := validateAntreaGroupSelectors(curCG.Spec); !allowed {
+		return reason, allowed
+	}
+	return g.validateChildGroups(curCG)
 }
 
 // updateValidate validates the UPDATE events of ClusterGroup resources.
 func (g *groupValidator) updateValidate(curObj, oldObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
 	curCG := curObj.(*corev1a2.ClusterGroup)
-	return validateAntreaGroupSelectors(curCG.Spec)
+	if reason, allowed := validateAntreaGroupSelectors(curCG.Spec); !allowed {
+		return reason, allowed
+	}
+	return g.validateChildGroups(curCG)
 }
 
 // deleteValidate validates the DELETE events of ClusterGroup resources.
@@ -637,5 +727,10 @@ func (g *groupValidator) deleteValidate(oldObj interface{}, userInfo authenticat
 	if err != nil || len(cnps) > 0 {
 		return fmt.Sprintf("cluster group %s is referenced by %d Antrea ClusterNetworkPolicies", oldCG.Name, len(cnps)), false
 	}
+	// ClusterGroup referred as a child by other ClusterGroups cannot be deleted.
+	parents, err := g.networkPolicyController.cgInformer.Informer().GetIndexer().ByIndex(ChildGroupIndex, oldCG.Name)
+	if err != nil || len(parents) > 0 {
+		return fmt.Sprintf("cluster group %s is a child of %d cluster groups", oldCG.Name, len(parents)), false
+	}
 	return "", true
 }
@@ -0,0 +1,140 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	corev1a2 "github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func TestValidateConnectionStates(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	tests := []struct {
+		name        string
+		ingress     []secv1alpha1.Rule
+		egress      []secv1alpha1.Rule
+		expectedErr bool
+	}{
+		{
+			name: "no state",
+			ingress: []secv1alpha1.Rule{
+				{Action: &dropAction},
+			},
+		},
+		{
+			name: "allow established and related",
+			ingress: []secv1alpha1.Rule{
+				{Action: &allowAction, ConnectionStates: []secv1alpha1.ConnectionState{secv1alpha1.ConnectionStateEstablished, secv1alpha1.ConnectionStateRelated}},
+			},
+		},
+		{
+			name: "drop new",
+			egress: []secv1alpha1.Rule{
+				{Action: &dropAction, ConnectionStates: []secv1alpha1.ConnectionState{secv1alpha1.ConnectionStateNew}},
+			},
+		},
+		{
+			name: "drop established",
+			egress: []secv1alpha1.Rule{
+				{Action: &dropAction, ConnectionStates: []secv1alpha1.ConnectionState{secv1alpha1.ConnectionStateEstablished}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid state",
+			ingress: []secv1alpha1.Rule{
+				{Action: &allowAction, ConnectionStates: []secv1alpha1.ConnectionState{"Invalid"}},
+			},
+			expectedErr: true,
+		},
+	}
+	v := &antreaPolicyValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.validateConnectionStates(tt.ingress, tt.egress)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateChildGroups(t *testing.T) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}}
+	child := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "child"},
+		Spec:       corev1a2.GroupSpec{PodSelector: &selector},
+	}
+	ipBlockChild := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipBlockChild"},
+		Spec:       corev1a2.GroupSpec{IPBlock: &secv1alpha1.IPBlock{CIDR: "10.0.0.0/8"}},
+	}
+	parent := &corev1a2.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "parent"},
+		Spec:       corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"child"}},
+	}
+	tests := []struct {
+		name            string
+		cg              *corev1a2.ClusterGroup
+		expectedAllowed bool
+	}{
+		{
+			name:            "existing and missing children",
+			cg:              &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "cg"}, Spec: corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"child", "missing"}}},
+			expectedAllowed: true,
+		},
+		{
+			name: "child with ipBlock",
+			cg:   &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "cg"}, Spec: corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"ipBlockChild"}}},
+		},
+		{
+			name: "child with children",
+			cg:   &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "cg"}, Spec: corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"parent"}}},
+		},
+		{
+			name: "child of itself",
+			cg:   &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "cg"}, Spec: corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"cg"}}},
+		},
+		{
+			name: "child updated with children",
+			cg:   &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "child"}, Spec: corev1a2.GroupSpec{ChildGroups: []corev1a2.ClusterGroupReference{"other"}}},
+		},
+		{
+			name: "child updated with ipBlock",
+			cg:   &corev1a2.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "child"}, Spec: corev1a2.GroupSpec{IPBlock: &secv1alpha1.IPBlock{CIDR: "10.0.0.0/8"}}},
+		},
+	}
+	_, npc := newController()
+	npc.cgInformer.Informer().AddIndexers(cache.Indexers{ChildGroupIndex: childGroupIndexFunc})
+	npc.cgStore.Add(child)
+	npc.cgStore.Add(ipBlockChild)
+	npc.cgStore.Add(parent)
+	v := &groupValidator{networkPolicyController: npc.NetworkPolicyController}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, allowed := v.validateChildGroups(tt.cg)
+			assert.Equal(t, tt.expectedAllowed, allowed)
+		})
+	}
+}
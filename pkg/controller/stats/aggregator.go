@@ -37,6 +37,12 @@ const (
 	uidIndex = "uid"
 )
 
+// ANPSessionsRecorder records the sessions of the Antrea NetworkPolicies reported by the antrea-agents. It is
+// implemented by the NetworkPolicyController, which tracks the violations of the baseline policies with it.
+type ANPSessionsRecorder interface {
+	RecordAntreaNetworkPolicySessions(namespace, name string, sessions int64)
+}
+
 // Aggregator collects the stats from the antrea-agents, aggregates them, caches the result, and provides interfaces
 // for Stats API handlers to query them. It implements the following interfaces:
 // - pkg/apiserver/registry/controlplane/nodestatssummary.statsCollector
@@ -58,6 +64,9 @@ type Aggregator struct {
 	cnpListerSynced cache.InformerSynced
 	// anpListerSynced is a function which returns true if the Antrea NetworkPolicy shared informer has been synced at least once.
 	anpListerSynced cache.InformerSynced
+	// anpSessionsRecorder is notified of the sessions of the Antrea NetworkPolicies collected from each
+	// antrea-agent, if not nil.
+	anpSessionsRecorder ANPSessionsRecorder
 }
 
 // uidIndexFunc is an index function that indexes based on an object's UID.
@@ -69,11 +78,12 @@ func uidIndexFunc(obj interface{}) ([]string, error) {
 	return []string{string(meta.GetUID())}, nil
 }
 
-func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInformer, cnpInformer secvinformers.ClusterNetworkPolicyInformer, anpInformer secvinformers.NetworkPolicyInformer) *Aggregator {
+func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInformer, cnpInformer secvinformers.ClusterNetworkPolicyInformer, anpInformer secvinformers.NetworkPolicyInformer, anpSessionsRecorder ANPSessionsRecorder) *Aggregator {
 	aggregator := &Aggregator{
-		networkPolicyStats: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc, uidIndex: uidIndexFunc}),
-		dataCh:             make(chan *controlplane.NodeStatsSummary, 1000),
-		npListerSynced:     networkPolicyInformer.Informer().HasSynced,
+		networkPolicyStats:  cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc, uidIndex: uidIndexFunc}),
+		dataCh:              make(chan *controlplane.NodeStatsSummary, 1000),
+		npListerSynced:      networkPolicyInformer.Informer().HasSynced,
+		anpSessionsRecorder: anpSessionsRecorder,
 	}
 	// Add handlers for NetworkPolicy events.
 	// They are the source of truth of the NetworkPolicyStats, i.e., a NetworkPolicyStats is present only if the
@@ -359,6 +369,9 @@ func (a *Aggregator) doCollect(summary *controlplane.NodeStatsSummary) {
 				curStats := objs[0].(*statsv1alpha1.AntreaNetworkPolicyStats).DeepCopy()
 				addUp(&curStats.TrafficStats, &stats.TrafficStats)
 				a.antreaNetworkPolicyStats.Update(curStats)
+				if a.anpSessionsRecorder != nil {
+					a.anpSessionsRecorder.RecordAntreaNetworkPolicySessions(curStats.Namespace, curStats.Name, stats.TrafficStats.Sessions)
+				}
 			}
 		}
 	}
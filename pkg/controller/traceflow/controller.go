@@ -35,6 +35,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
 	opsinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/ops/v1alpha1"
 	opslisters "github.com/vmware-tanzu/antrea/pkg/client/listers/ops/v1alpha1"
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 )
 
 const (
@@ -64,12 +65,6 @@ const (
 	traceflowTimeout = "Traceflow timeout"
 )
 
-var (
-	// Traceflow timeout period.
-	timeoutDuration      = 2 * time.Minute
-	timeoutCheckInterval = timeoutDuration / 2
-)
-
 // Controller is for traceflow.
 type Controller struct {
 	client                 versioned.Interface
@@ -80,10 +75,20 @@ type Controller struct {
 	queue                  workqueue.RateLimitingInterface
 	runningTraceflowsMutex sync.Mutex
 	runningTraceflows      map[uint8]string // tag->traceflowName if tf.Status.Phase is Running.
+	// Traceflow timeout period, after which a Running Traceflow is marked as Failed.
+	timeoutDuration time.Duration
+	// Retention period of completed Traceflows, after which they are deleted.
+	retentionPeriod time.Duration
+	// notifier sends an event to the webhooks when a Traceflow completes with a dropped packet.
+	notifier webhook.EventNotifier
 }
 
 // NewTraceflowController creates a new traceflow controller and adds podIP indexer to podInformer.
-func NewTraceflowController(client versioned.Interface, podInformer coreinformers.PodInformer, traceflowInformer opsinformers.TraceflowInformer) *Controller {
+// Running Traceflows are marked as Failed after timeoutDuration, and completed Traceflows are deleted
+// after retentionPeriod, both counted from the Traceflow creation. The Traceflows which complete with a
+// dropped packet are reported to notifier, which may be nil.
+func NewTraceflowController(client versioned.Interface, podInformer coreinformers.PodInformer, traceflowInformer opsinformers.TraceflowInformer,
+	timeoutDuration time.Duration, retentionPeriod time.Duration, notifier *webhook.Notifier) *Controller {
 	c := &Controller{
 		client:                client,
 		podInformer:           podInformer,
@@ -91,7 +96,10 @@ func NewTraceflowController(client versioned.Interface, podInformer coreinformer
 		traceflowLister:       traceflowInformer.Lister(),
 		traceflowListerSynced: traceflowInformer.Informer().HasSynced,
 		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "traceflow"),
-		runningTraceflows:     make(map[uint8]string)}
+		runningTraceflows:     make(map[uint8]string),
+		timeoutDuration:       timeoutDuration,
+		retentionPeriod:       retentionPeriod,
+		notifier:              notifier}
 	// Add handlers for ClusterNetworkPolicy events.
 	traceflowInformer.Informer().AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
@@ -151,7 +159,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	}
 
 	go func() {
-		wait.Until(c.checkTraceflowTimeout, timeoutCheckInterval, stopCh)
+		wait.Until(c.checkTraceflowTimeout, c.timeoutDuration/2, stopCh)
 	}()
 
 	for i := 0; i < defaultWorkers; i++ {
@@ -237,6 +245,12 @@ func (c *Controller) processTraceflowItem() bool {
 	return true
 }
 
+// GetTraceflow returns the Traceflow with the provided name from the informer cache. It is used to serve the graph of a
+// Traceflow.
+func (c *Controller) GetTraceflow(_ context.Context, name string) (*opsv1alpha1.Traceflow, error) {
+	return c.traceflowLister.Get(name)
+}
+
 func (c *Controller) syncTraceflow(traceflowName string) error {
 	startTime := time.Now()
 	defer func() {
@@ -256,13 +270,33 @@ func (c *Controller) syncTraceflow(traceflowName string) error {
 		err = c.startTraceflow(tf)
 	case opsv1alpha1.Running:
 		err = c.checkTraceflowStatus(tf)
+	case opsv1alpha1.Succeeded:
+		err = c.checkTraceflowRetention(tf)
 	case opsv1alpha1.Failed:
 		// Deallocate tag when agent set Traceflow status to Failed.
 		c.deallocateTagForTF(tf)
+		err = c.checkTraceflowRetention(tf)
 	}
 	return err
 }
 
+// checkTraceflowRetention deletes the completed Traceflow if its retention period has expired, otherwise
+// it re-posts the Traceflow to the work queue to be checked again when the retention period expires.
+func (c *Controller) checkTraceflowRetention(tf *opsv1alpha1.Traceflow) error {
+	// CreationTimestamp is of second accuracy.
+	remaining := tf.CreationTimestamp.Add(c.retentionPeriod).Sub(time.Now())
+	if remaining > 0 {
+		c.queue.AddAfter(tf.Name, remaining)
+		return nil
+	}
+	klog.Infof("Deleting Traceflow %s as its retention period has expired", tf.Name)
+	err := c.client.OpsV1alpha1().Traceflows().Delete(context.TODO(), tf.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func (c *Controller) startTraceflow(tf *opsv1alpha1.Traceflow) error {
 	// Allocate data plane tag.
 	tag, err := c.allocateTag(tf.Name)
@@ -288,7 +322,7 @@ func (c *Controller) checkTraceflowStatus(tf *opsv1alpha1.Traceflow) error {
 			if ob.Component == opsv1alpha1.SpoofGuard {
 				sender = true
 			}
-			if ob.Action == opsv1alpha1.Delivered || ob.Action == opsv1alpha1.Dropped {
+			if ob.Action == opsv1alpha1.Delivered || ob.Action == opsv1alpha1.Dropped || ob.Action == opsv1alpha1.ForwardedOutOfOverlay {
 				receiver = true
 			}
 			if ob.TranslatedDstIP != "" {
@@ -309,10 +343,14 @@ func (c *Controller) checkTraceflowStatus(tf *opsv1alpha1.Traceflow) error {
 	}
 	if sender && receiver {
 		c.deallocateTagForTF(tf)
-		return c.updateTraceflowStatus(tf, opsv1alpha1.Succeeded, "", 0)
+		if err := c.updateTraceflowStatus(tf, opsv1alpha1.Succeeded, "", 0); err != nil {
+			return err
+		}
+		c.notifyDrop(tf)
+		return nil
 	}
 	// CreationTimestamp is of second accuracy.
-	if time.Now().Unix() > tf.CreationTimestamp.Unix()+int64(timeoutDuration.Seconds()) {
+	if time.Now().Unix() > tf.CreationTimestamp.Unix()+int64(c.timeoutDuration.Seconds()) {
 		c.deallocateTagForTF(tf)
 		return c.updateTraceflowStatus(tf, opsv1alpha1.Failed, traceflowTimeout, 0)
 	}
@@ -330,6 +368,44 @@ func (c *Controller) updateTraceflowStatus(tf *opsv1alpha1.Traceflow, phase opsv
 	return err
 }
 
+// notifyDrop sends an event to the webhooks if the packet of the succeeded Traceflow was dropped.
+func (c *Controller) notifyDrop(tf *opsv1alpha1.Traceflow) {
+	for _, nodeResult := range tf.Status.Results {
+		for _, ob := range nodeResult.Observations {
+			if ob.Action != opsv1alpha1.Dropped {
+				continue
+			}
+			details := map[string]string{
+				"traceflow":   tf.Name,
+				"source":      fmt.Sprintf("%s/%s", tf.Spec.Source.Namespace, tf.Spec.Source.Pod),
+				"destination": traceflowDestination(tf),
+				"node":        nodeResult.Node,
+				"component":   string(ob.Component),
+			}
+			message := fmt.Sprintf("Traceflow %s: packet from %s to %s was dropped on Node %s", tf.Name, details["source"], details["destination"], nodeResult.Node)
+			if ob.NetworkPolicy != "" {
+				details["networkPolicy"] = ob.NetworkPolicy
+				message = fmt.Sprintf("%s by NetworkPolicy %s", message, ob.NetworkPolicy)
+			}
+			c.notifier.Notify(webhook.Event{Type: webhook.EventTraceflowDropped, Source: "antrea-controller", Message: message, Details: details})
+			return
+		}
+	}
+}
+
+// traceflowDestination returns a human-readable description of the destination of a Traceflow.
+func traceflowDestination(tf *opsv1alpha1.Traceflow) string {
+	dst := tf.Spec.Destination
+	switch {
+	case dst.Pod != "":
+		return fmt.Sprintf("%s/%s", dst.Namespace, dst.Pod)
+	case dst.Service != "":
+		return fmt.Sprintf("Service %s/%s", dst.Namespace, dst.Service)
+	default:
+		return dst.IP
+	}
+}
+
 func (c *Controller) occupyTag(tf *opsv1alpha1.Traceflow) error {
 	tag := tf.Status.DataplaneTag
 	if tag < minTagNum || tag > maxTagNum {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -33,6 +34,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
 	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
 	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	"github.com/vmware-tanzu/antrea/pkg/util/webhook"
 )
 
 var alwaysReady = func() bool { return true }
@@ -46,14 +48,17 @@ type traceflowController struct {
 	crdInformerFactory crdinformers.SharedInformerFactory
 }
 
-func newController() *traceflowController {
+func newController(timeoutDuration, retentionPeriod time.Duration) *traceflowController {
 	client := fake.NewSimpleClientset()
 	crdClient := newCRDClientset()
 	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
 	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
 	controller := NewTraceflowController(crdClient,
 		informerFactory.Core().V1().Pods(),
-		crdInformerFactory.Ops().V1alpha1().Traceflows())
+		crdInformerFactory.Ops().V1alpha1().Traceflows(),
+		timeoutDuration,
+		retentionPeriod,
+		nil)
 	controller.traceflowListerSynced = alwaysReady
 	return &traceflowController{
 		controller,
@@ -65,10 +70,9 @@ func newController() *traceflowController {
 
 func TestTraceflow(t *testing.T) {
 	// Use shorter timeout.
-	timeoutDuration = 2 * time.Second
-	timeoutCheckInterval = timeoutDuration / 2
+	timeoutDuration := 2 * time.Second
 
-	tfc := newController()
+	tfc := newController(timeoutDuration, time.Hour)
 	stopCh := make(chan struct{})
 	tfc.crdInformerFactory.Start(stopCh)
 	go tfc.Run(stopCh)
@@ -113,6 +117,28 @@ func TestTraceflow(t *testing.T) {
 	assert.Equal(t, numRunningTraceflows(), 0)
 	tfc.client.OpsV1alpha1().Traceflows().Delete(context.TODO(), "tf1", metav1.DeleteOptions{})
 
+	// Test Controller handling of successful Traceflow to a destination outside the cluster.
+	tf2 := ops.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf2", UID: "uid2"},
+		Spec: ops.TraceflowSpec{
+			Source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+			Destination: ops.Destination{IP: "8.8.8.8"},
+		},
+	}
+	tfc.client.OpsV1alpha1().Traceflows().Create(context.TODO(), &tf2, metav1.CreateOptions{})
+	res, _ = tfc.waitForTraceflow("tf2", ops.Running, time.Second)
+	assert.NotNil(t, res)
+	res.Status.Results = []ops.NodeResult{
+		{
+			Observations: []ops.Observation{{Component: ops.SpoofGuard}, {Action: ops.ForwardedOutOfOverlay}},
+		},
+	}
+	tfc.client.OpsV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+	res, _ = tfc.waitForTraceflow("tf2", ops.Succeeded, time.Second)
+	assert.NotNil(t, res)
+	assert.Equal(t, numRunningTraceflows(), 0)
+	tfc.client.OpsV1alpha1().Traceflows().Delete(context.TODO(), "tf2", metav1.DeleteOptions{})
+
 	// Test Traceflow timeout.
 	startTime := time.Now()
 	tfc.client.OpsV1alpha1().Traceflows().Create(context.TODO(), &tf1, metav1.CreateOptions{})
@@ -128,6 +154,37 @@ func TestTraceflow(t *testing.T) {
 	close(stopCh)
 }
 
+func TestTraceflowRetention(t *testing.T) {
+	timeoutDuration := time.Second
+	retentionPeriod := 4 * time.Second
+
+	tfc := newController(timeoutDuration, retentionPeriod)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	tfc.crdInformerFactory.Start(stopCh)
+	go tfc.Run(stopCh)
+
+	tf1 := ops.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf1", UID: "uid1"},
+		Spec: ops.TraceflowSpec{
+			Source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+			Destination: ops.Destination{Namespace: "ns2", Pod: "pod2"},
+		},
+	}
+
+	// The Traceflow times out, and is deleted once its retention period has expired.
+	startTime := time.Now()
+	tfc.client.OpsV1alpha1().Traceflows().Create(context.TODO(), &tf1, metav1.CreateOptions{})
+	res, _ := tfc.waitForTraceflow("tf1", ops.Failed, timeoutDuration*3)
+	assert.NotNil(t, res)
+	err := wait.Poll(100*time.Millisecond, retentionPeriod*2, func() (bool, error) {
+		_, err := tfc.client.OpsV1alpha1().Traceflows().Get(context.TODO(), "tf1", metav1.GetOptions{})
+		return apierrors.IsNotFound(err), nil
+	})
+	assert.NoError(t, err, "Traceflow should be deleted after its retention period")
+	assert.True(t, time.Now().Sub(startTime) >= retentionPeriod)
+}
+
 func (tfc *traceflowController) waitForTraceflow(name string, phase ops.TraceflowPhase, timeout time.Duration) (*ops.Traceflow, error) {
 	var tf *ops.Traceflow
 	var err error
@@ -228,3 +285,85 @@ func Test_podIPsIndexFunc(t *testing.T) {
 		})
 	}
 }
+
+// fakeNotifier records the events instead of sending them to webhooks.
+type fakeNotifier struct {
+	events []webhook.Event
+}
+
+func (n *fakeNotifier) Notify(event webhook.Event) {
+	n.events = append(n.events, event)
+}
+
+func TestNotifyDrop(t *testing.T) {
+	newTraceflow := func(observations ...ops.Observation) *ops.Traceflow {
+		return &ops.Traceflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+			Spec: ops.TraceflowSpec{
+				Source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: ops.Destination{Namespace: "ns2", Pod: "pod2"},
+			},
+			Status: ops.TraceflowStatus{
+				Phase: ops.Succeeded,
+				Results: []ops.NodeResult{
+					{Node: "node1", Observations: []ops.Observation{{Component: ops.SpoofGuard, Action: ops.Forwarded}}},
+					{Node: "node2", Observations: observations},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		tf             *ops.Traceflow
+		expectedEvents []webhook.Event
+	}{
+		{
+			name: "delivered",
+			tf:   newTraceflow(ops.Observation{Component: ops.Forwarding, Action: ops.Delivered}),
+		},
+		{
+			name: "dropped by NetworkPolicy",
+			tf: newTraceflow(
+				ops.Observation{Component: ops.NetworkPolicy, ComponentInfo: "IngressMetric", Action: ops.Dropped, NetworkPolicy: "AntreaNetworkPolicy:ns2/deny-all"},
+				ops.Observation{Component: ops.Forwarding, Action: ops.Dropped},
+			),
+			expectedEvents: []webhook.Event{{
+				Type:    webhook.EventTraceflowDropped,
+				Source:  "antrea-controller",
+				Message: "Traceflow tf1: packet from ns1/pod1 to ns2/pod2 was dropped on Node node2 by NetworkPolicy AntreaNetworkPolicy:ns2/deny-all",
+				Details: map[string]string{
+					"traceflow":     "tf1",
+					"source":        "ns1/pod1",
+					"destination":   "ns2/pod2",
+					"node":          "node2",
+					"component":     string(ops.NetworkPolicy),
+					"networkPolicy": "AntreaNetworkPolicy:ns2/deny-all",
+				},
+			}},
+		},
+		{
+			name: "dropped by the pipeline",
+			tf:   newTraceflow(ops.Observation{Component: ops.Forwarding, Action: ops.Dropped}),
+			expectedEvents: []webhook.Event{{
+				Type:    webhook.EventTraceflowDropped,
+				Source:  "antrea-controller",
+				Message: "Traceflow tf1: packet from ns1/pod1 to ns2/pod2 was dropped on Node node2",
+				Details: map[string]string{
+					"traceflow":   "tf1",
+					"source":      "ns1/pod1",
+					"destination": "ns2/pod2",
+					"node":        "node2",
+					"component":   string(ops.Forwarding),
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			c := &Controller{notifier: notifier}
+			c.notifyDrop(tt.tf)
+			assert.Equal(t, tt.expectedEvents, notifier.events)
+		})
+	}
+}
@@ -0,0 +1,498 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	opsinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/ops/v1alpha1"
+	opslisters "github.com/vmware-tanzu/antrea/pkg/client/listers/ops/v1alpha1"
+	"github.com/vmware-tanzu/antrea/pkg/k8s"
+)
+
+const (
+	setControllerName = "TraceflowSetController"
+
+	// Default number of workers processing TraceflowSets.
+	defaultSetWorkers = 2
+
+	// Default maximum number of Traceflows of a TraceflowSet which run at the same time.
+	defaultMaxConcurrentTraceflows = 4
+
+	// Maximum number of pairs of source and destination Pods of a TraceflowSet, which bounds the size
+	// of its status.
+	maxTraceflowSetPairs = 500
+
+	// traceflowSetLabelKey is the label of the Traceflows created for a TraceflowSet, whose value is
+	// the name of the TraceflowSet.
+	traceflowSetLabelKey = "ops.antrea.tanzu.vmware.com/traceflowset"
+)
+
+// SetController runs the TraceflowSets, by creating one Traceflow per pair of source and destination Pods
+// and aggregating their results.
+type SetController struct {
+	client                   versioned.Interface
+	podLister                corelisters.PodLister
+	podListerSynced          cache.InformerSynced
+	namespaceLister          corelisters.NamespaceLister
+	namespaceListerSynced    cache.InformerSynced
+	traceflowLister          opslisters.TraceflowLister
+	traceflowListerSynced    cache.InformerSynced
+	traceflowSetLister       opslisters.TraceflowSetLister
+	traceflowSetListerSynced cache.InformerSynced
+	queue                    workqueue.RateLimitingInterface
+	// createdTraceflows are the names of the Traceflows created by the controller which are not in the
+	// informer cache yet, so that they are not mistaken for deleted Traceflows.
+	createdTraceflows      sets.String
+	createdTraceflowsMutex sync.Mutex
+	// Retention period of completed TraceflowSets, after which they are deleted along with their Traceflows.
+	retentionPeriod time.Duration
+}
+
+// NewTraceflowSetController creates a new TraceflowSet controller. Completed TraceflowSets are deleted after
+// retentionPeriod, counted from the TraceflowSet creation.
+func NewTraceflowSetController(client versioned.Interface, podInformer coreinformers.PodInformer, namespaceInformer coreinformers.NamespaceInformer,
+	traceflowInformer opsinformers.TraceflowInformer, traceflowSetInformer opsinformers.TraceflowSetInformer, retentionPeriod time.Duration) *SetController {
+	c := &SetController{
+		client:                   client,
+		podLister:                podInformer.Lister(),
+		podListerSynced:          podInformer.Informer().HasSynced,
+		namespaceLister:          namespaceInformer.Lister(),
+		namespaceListerSynced:    namespaceInformer.Informer().HasSynced,
+		traceflowLister:          traceflowInformer.Lister(),
+		traceflowListerSynced:    traceflowInformer.Informer().HasSynced,
+		traceflowSetLister:       traceflowSetInformer.Lister(),
+		traceflowSetListerSynced: traceflowSetInformer.Informer().HasSynced,
+		queue:                    workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "traceflowset"),
+		createdTraceflows:        sets.NewString(),
+		retentionPeriod:          retentionPeriod,
+	}
+	traceflowSetInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.queue.Add(obj.(*opsv1alpha1.TraceflowSet).Name)
+			},
+			UpdateFunc: func(_, curObj interface{}) {
+				c.queue.Add(curObj.(*opsv1alpha1.TraceflowSet).Name)
+			},
+		},
+		resyncPeriod,
+	)
+	// The TraceflowSet of a Traceflow is re-processed when the Traceflow is completed or deleted.
+	traceflowInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.forgetCreatedTraceflow,
+			UpdateFunc: func(_, curObj interface{}) {
+				c.enqueueTraceflowSetOf(curObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				c.forgetCreatedTraceflow(obj)
+				c.enqueueTraceflowSetOf(obj)
+			},
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+func traceflowOf(obj interface{}) (*opsv1alpha1.Traceflow, bool) {
+	tf, ok := obj.(*opsv1alpha1.Traceflow)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return nil, false
+		}
+		if tf, ok = tombstone.Obj.(*opsv1alpha1.Traceflow); !ok {
+			return nil, false
+		}
+	}
+	return tf, true
+}
+
+// forgetCreatedTraceflow is called when a Traceflow is added to or deleted from the informer cache, after
+// which the cache is authoritative for it.
+func (c *SetController) forgetCreatedTraceflow(obj interface{}) {
+	tf, ok := traceflowOf(obj)
+	if !ok {
+		return
+	}
+	c.createdTraceflowsMutex.Lock()
+	defer c.createdTraceflowsMutex.Unlock()
+	c.createdTraceflows.Delete(tf.Name)
+}
+
+func (c *SetController) enqueueTraceflowSetOf(obj interface{}) {
+	tf, ok := traceflowOf(obj)
+	if !ok {
+		return
+	}
+	if setName, ok := tf.Labels[traceflowSetLabelKey]; ok {
+		c.queue.Add(setName)
+	}
+}
+
+func (c *SetController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", setControllerName)
+	defer klog.Infof("Shutting down %s", setControllerName)
+
+	if !cache.WaitForNamedCacheSync(setControllerName, stopCh, c.podListerSynced, c.namespaceListerSynced, c.traceflowListerSynced, c.traceflowSetListerSynced) {
+		return
+	}
+
+	for i := 0; i < defaultSetWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *SetController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *SetController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+	} else if err := c.syncTraceflowSet(key); err != nil {
+		klog.Errorf("Error syncing TraceflowSet %s, requeuing. Error: %v", key, err)
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+	return true
+}
+
+func (c *SetController) syncTraceflowSet(name string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing TraceflowSet for %s. (%v)", name, time.Since(startTime))
+	}()
+
+	tfs, err := c.traceflowSetLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The Traceflows of the TraceflowSet are garbage collected.
+			return nil
+		}
+		return err
+	}
+	switch tfs.Status.Phase {
+	case "", opsv1alpha1.Pending:
+		return c.startTraceflowSet(tfs)
+	case opsv1alpha1.Running:
+		return c.runTraceflowSet(tfs)
+	default:
+		return c.checkTraceflowSetRetention(tfs)
+	}
+}
+
+// startTraceflowSet computes the pairs of source and destination Pods of the TraceflowSet, whose Traceflows
+// are created by runTraceflowSet. The pairs are not updated if the Pods change afterwards.
+func (c *SetController) startTraceflowSet(tfs *opsv1alpha1.TraceflowSet) error {
+	update := tfs.DeepCopy()
+	sources, err := c.selectPods(&tfs.Spec.Source)
+	if err != nil {
+		return c.failTraceflowSet(update, fmt.Sprintf("Invalid source: %v", err))
+	}
+	destinations, err := c.selectPods(&tfs.Spec.Destination)
+	if err != nil {
+		return c.failTraceflowSet(update, fmt.Sprintf("Invalid destination: %v", err))
+	}
+	var results []opsv1alpha1.TraceflowSetResult
+	for _, src := range sources {
+		for _, dst := range destinations {
+			if src.UID == dst.UID {
+				continue
+			}
+			results = append(results, opsv1alpha1.TraceflowSetResult{
+				Source:      k8s.NamespacedName(src.Namespace, src.Name),
+				Destination: k8s.NamespacedName(dst.Namespace, dst.Name),
+				Phase:       opsv1alpha1.Pending,
+			})
+		}
+	}
+	if len(results) == 0 {
+		return c.failTraceflowSet(update, "No pair of source and destination Pods is selected")
+	}
+	if len(results) > maxTraceflowSetPairs {
+		return c.failTraceflowSet(update, fmt.Sprintf("%d pairs of source and destination Pods are selected, more than the maximum %d", len(results), maxTraceflowSetPairs))
+	}
+	klog.Infof("Starting TraceflowSet %s with %d pairs of source and destination Pods", tfs.Name, len(results))
+	update.Status.Phase = opsv1alpha1.Running
+	update.Status.Results = results
+	_, err = c.client.OpsV1alpha1().TraceflowSets().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{})
+	return err
+}
+
+// selectPods returns the running Pods selected by selection, which are not in the host network, sorted by
+// Namespace and name.
+func (c *SetController) selectPods(selection *opsv1alpha1.PodSelection) ([]*corev1.Pod, error) {
+	nsSelector, podSelector := labels.Everything(), labels.Everything()
+	var err error
+	if selection.NamespaceSelector != nil {
+		if nsSelector, err = metav1.LabelSelectorAsSelector(selection.NamespaceSelector); err != nil {
+			return nil, err
+		}
+	}
+	if selection.PodSelector != nil {
+		if podSelector, err = metav1.LabelSelectorAsSelector(selection.PodSelector); err != nil {
+			return nil, err
+		}
+	}
+	namespaces, err := c.namespaceLister.List(nsSelector)
+	if err != nil {
+		return nil, err
+	}
+	var pods []*corev1.Pod
+	for _, ns := range namespaces {
+		nsPods, err := c.podLister.Pods(ns.Name).List(podSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range nsPods {
+			if pod.Spec.HostNetwork || pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return k8s.NamespacedName(pods[i].Namespace, pods[i].Name) < k8s.NamespacedName(pods[j].Namespace, pods[j].Name)
+	})
+	return pods, nil
+}
+
+// runTraceflowSet collects the results of the completed Traceflows of the TraceflowSet, and creates the
+// Traceflows of the pending pairs as long as the maximum number of concurrent Traceflows is not reached.
+func (c *SetController) runTraceflowSet(tfs *opsv1alpha1.TraceflowSet) error {
+	update := tfs.DeepCopy()
+	maxConcurrent := int(tfs.Spec.MaxConcurrentTraceflows)
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrentTraceflows
+	}
+	running := 0
+	for i := range update.Status.Results {
+		result := &update.Status.Results[i]
+		if result.Traceflow == "" || isCompleted(result.Phase) {
+			continue
+		}
+		tf, err := c.getTraceflow(result.Traceflow)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			result.Phase = opsv1alpha1.Failed
+			result.Reason = "Traceflow was deleted before completion"
+			continue
+		}
+		updateResult(result, tf)
+		if !isCompleted(result.Phase) {
+			running++
+		}
+	}
+	var createErr error
+	for i := range update.Status.Results {
+		if running >= maxConcurrent {
+			break
+		}
+		result := &update.Status.Results[i]
+		if result.Traceflow != "" {
+			continue
+		}
+		if createErr = c.createTraceflow(tfs, i, result); createErr != nil {
+			// Save the progress before retrying.
+			break
+		}
+		if !isCompleted(result.Phase) {
+			running++
+		}
+	}
+	update.Status.Delivered, update.Status.Dropped, update.Status.Failed = 0, 0, 0
+	completed := 0
+	for _, result := range update.Status.Results {
+		switch {
+		case result.Phase == opsv1alpha1.Failed:
+			update.Status.Failed++
+		case result.Phase != opsv1alpha1.Succeeded:
+			continue
+		case result.Action == opsv1alpha1.Dropped:
+			update.Status.Dropped++
+		default:
+			update.Status.Delivered++
+		}
+		completed++
+	}
+	if completed == len(update.Status.Results) {
+		klog.Infof("TraceflowSet %s completed: %d delivered, %d dropped, %d failed", tfs.Name, update.Status.Delivered, update.Status.Dropped, update.Status.Failed)
+		update.Status.Phase = opsv1alpha1.Succeeded
+	}
+	if !reflect.DeepEqual(update.Status, tfs.Status) {
+		if _, err := c.client.OpsV1alpha1().TraceflowSets().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return createErr
+}
+
+// getTraceflow gets a Traceflow from the informer cache. A Traceflow which was just created by the
+// controller and is not in the cache yet is returned without status, i.e. as Pending.
+func (c *SetController) getTraceflow(name string) (*opsv1alpha1.Traceflow, error) {
+	tf, err := c.traceflowLister.Get(name)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return tf, err
+	}
+	c.createdTraceflowsMutex.Lock()
+	defer c.createdTraceflowsMutex.Unlock()
+	if c.createdTraceflows.Has(name) {
+		return &opsv1alpha1.Traceflow{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+	}
+	return nil, err
+}
+
+// createTraceflow creates the Traceflow of the pair at index i of the TraceflowSet results, and records its
+// name in result. The Traceflow names are deterministic, so that the Traceflows created before a failed
+// status update are adopted when the TraceflowSet is processed again. The result is marked as Failed if
+// the Traceflow is rejected.
+func (c *SetController) createTraceflow(tfs *opsv1alpha1.TraceflowSet, i int, result *opsv1alpha1.TraceflowSetResult) error {
+	srcNamespace, srcPod := k8s.SplitNamespacedName(result.Source)
+	dstNamespace, dstPod := k8s.SplitNamespacedName(result.Destination)
+	tf := &opsv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", tfs.Name, i),
+			Labels:          map[string]string{traceflowSetLabelKey: tfs.Name},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(tfs, opsv1alpha1.SchemeGroupVersion.WithKind("TraceflowSet"))},
+		},
+		Spec: opsv1alpha1.TraceflowSpec{
+			Source:      opsv1alpha1.Source{Namespace: srcNamespace, Pod: srcPod},
+			Destination: opsv1alpha1.Destination{Namespace: dstNamespace, Pod: dstPod},
+			Packet:      *tfs.Spec.Packet.DeepCopy(),
+		},
+	}
+	// The name is recorded before the creation, as the informer may see the Traceflow before Create returns.
+	c.createdTraceflowsMutex.Lock()
+	c.createdTraceflows.Insert(tf.Name)
+	c.createdTraceflowsMutex.Unlock()
+	_, err := c.client.OpsV1alpha1().Traceflows().Create(context.TODO(), tf, metav1.CreateOptions{})
+	if err != nil {
+		c.createdTraceflowsMutex.Lock()
+		c.createdTraceflows.Delete(tf.Name)
+		c.createdTraceflowsMutex.Unlock()
+	}
+	switch {
+	case err == nil:
+		result.Traceflow = tf.Name
+	case apierrors.IsAlreadyExists(err):
+		// The existing Traceflow is processed once it is in the informer cache.
+		existing, err := c.traceflowLister.Get(tf.Name)
+		if err != nil {
+			return err
+		}
+		result.Traceflow = tf.Name
+		if existing.Labels[traceflowSetLabelKey] != tfs.Name {
+			result.Phase = opsv1alpha1.Failed
+			result.Reason = fmt.Sprintf("Traceflow %s already exists", tf.Name)
+			return nil
+		}
+		updateResult(result, existing)
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) || apierrors.IsForbidden(err):
+		result.Phase = opsv1alpha1.Failed
+		result.Reason = fmt.Sprintf("Traceflow was rejected: %v", err)
+	default:
+		return fmt.Errorf("error creating Traceflow %s: %v", tf.Name, err)
+	}
+	return nil
+}
+
+// updateResult updates the result of a pair with the status of its Traceflow.
+func updateResult(result *opsv1alpha1.TraceflowSetResult, tf *opsv1alpha1.Traceflow) {
+	result.Phase = tf.Status.Phase
+	if result.Phase == "" {
+		result.Phase = opsv1alpha1.Pending
+	}
+	switch result.Phase {
+	case opsv1alpha1.Failed:
+		result.Reason = tf.Status.Reason
+	case opsv1alpha1.Succeeded:
+		// The last Delivered, Dropped or ForwardedOutOfOverlay observation is the fate of the packet.
+		for _, nodeResult := range tf.Status.Results {
+			for _, ob := range nodeResult.Observations {
+				if ob.Action != opsv1alpha1.Delivered && ob.Action != opsv1alpha1.Dropped && ob.Action != opsv1alpha1.ForwardedOutOfOverlay {
+					continue
+				}
+				result.Action = ob.Action
+				result.Reason = ob.Reason
+				if ob.Action == opsv1alpha1.Dropped && ob.NetworkPolicy != "" {
+					result.Reason = fmt.Sprintf("Dropped by NetworkPolicy %s", ob.NetworkPolicy)
+				}
+			}
+		}
+	}
+}
+
+func isCompleted(phase opsv1alpha1.TraceflowPhase) bool {
+	return phase == opsv1alpha1.Succeeded || phase == opsv1alpha1.Failed
+}
+
+func (c *SetController) failTraceflowSet(update *opsv1alpha1.TraceflowSet, reason string) error {
+	klog.Infof("TraceflowSet %s failed: %s", update.Name, reason)
+	update.Status.Phase = opsv1alpha1.Failed
+	update.Status.Reason = reason
+	_, err := c.client.OpsV1alpha1().TraceflowSets().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{})
+	return err
+}
+
+// checkTraceflowSetRetention deletes the completed TraceflowSet if its retention period has expired, otherwise
+// it re-posts the TraceflowSet to the work queue to be checked again when the retention period expires.
+func (c *SetController) checkTraceflowSetRetention(tfs *opsv1alpha1.TraceflowSet) error {
+	remaining := tfs.CreationTimestamp.Add(c.retentionPeriod).Sub(time.Now())
+	if remaining > 0 {
+		c.queue.AddAfter(tfs.Name, remaining)
+		return nil
+	}
+	klog.Infof("Deleting TraceflowSet %s as its retention period has expired", tfs.Name)
+	err := c.client.OpsV1alpha1().TraceflowSets().Delete(context.TODO(), tfs.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ops "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+func newPod(namespace, name string, labels map[string]string, hostNetwork bool) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "/" + name), Labels: labels},
+		Spec:       corev1.PodSpec{HostNetwork: hostNetwork},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "1.1.1.1"},
+	}
+}
+
+func TestTraceflowSet(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	crdClient := newCRDClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
+	podInformer := informerFactory.Core().V1().Pods()
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	traceflowInformer := crdInformerFactory.Ops().V1alpha1().Traceflows()
+	traceflowSetInformer := crdInformerFactory.Ops().V1alpha1().TraceflowSets()
+	c := NewTraceflowSetController(crdClient, podInformer, namespaceInformer, traceflowInformer, traceflowSetInformer, time.Hour)
+
+	clientLabels := map[string]string{"role": "client"}
+	namespaceInformer.Informer().GetStore().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	namespaceInformer.Informer().GetStore().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"tier": "server"}}})
+	podInformer.Informer().GetStore().Add(newPod("ns1", "client1", clientLabels, false))
+	podInformer.Informer().GetStore().Add(newPod("ns1", "client2", clientLabels, false))
+	podInformer.Informer().GetStore().Add(newPod("ns1", "client3", clientLabels, true))
+	podInformer.Informer().GetStore().Add(newPod("ns2", "server", nil, false))
+
+	tfs := &ops.TraceflowSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfs", UID: "uid"},
+		Spec: ops.TraceflowSetSpec{
+			Source:                  ops.PodSelection{PodSelector: &metav1.LabelSelector{MatchLabels: clientLabels}},
+			Destination:             ops.PodSelection{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "server"}}},
+			MaxConcurrentTraceflows: 1,
+		},
+	}
+	crdClient.OpsV1alpha1().TraceflowSets().Create(context.TODO(), tfs, metav1.CreateOptions{})
+	traceflowSetInformer.Informer().GetStore().Add(tfs)
+
+	sync := func() *ops.TraceflowSet {
+		require.NoError(t, c.syncTraceflowSet(tfs.Name))
+		updated, err := crdClient.OpsV1alpha1().TraceflowSets().Get(context.TODO(), tfs.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		traceflowSetInformer.Informer().GetStore().Update(updated)
+		return updated
+	}
+	completeTraceflow := func(name string, phase ops.TraceflowPhase, reason string, results []ops.NodeResult) {
+		tf, err := crdClient.OpsV1alpha1().Traceflows().Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, tfs.Name, tf.Labels[traceflowSetLabelKey])
+		tf.Status = ops.TraceflowStatus{Phase: phase, Reason: reason, Results: results}
+		crdClient.OpsV1alpha1().Traceflows().UpdateStatus(context.TODO(), tf, metav1.UpdateOptions{})
+		traceflowInformer.Informer().GetStore().Update(tf)
+	}
+
+	// The pairs are computed, excluding the Pod in the host network.
+	updated := sync()
+	assert.Equal(t, ops.Running, updated.Status.Phase)
+	assert.Equal(t, []ops.TraceflowSetResult{
+		{Source: "ns1/client1", Destination: "ns2/server", Phase: ops.Pending},
+		{Source: "ns1/client2", Destination: "ns2/server", Phase: ops.Pending},
+	}, updated.Status.Results)
+
+	// Only one Traceflow runs at a time.
+	updated = sync()
+	assert.Equal(t, "tfs-0", updated.Status.Results[0].Traceflow)
+	assert.Equal(t, "", updated.Status.Results[1].Traceflow)
+	tf, err := crdClient.OpsV1alpha1().Traceflows().Get(context.TODO(), "tfs-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ops.Source{Namespace: "ns1", Pod: "client1"}, tf.Spec.Source)
+	assert.Equal(t, ops.Destination{Namespace: "ns2", Pod: "server"}, tf.Spec.Destination)
+
+	completeTraceflow("tfs-0", ops.Succeeded, "", []ops.NodeResult{
+		{Observations: []ops.Observation{{Component: ops.SpoofGuard}}},
+		{Observations: []ops.Observation{{Component: ops.NetworkPolicy, Action: ops.Dropped, NetworkPolicy: "ns2/deny"}}},
+	})
+	updated = sync()
+	assert.Equal(t, ops.TraceflowSetResult{
+		Source:      "ns1/client1",
+		Destination: "ns2/server",
+		Traceflow:   "tfs-0",
+		Phase:       ops.Succeeded,
+		Action:      ops.Dropped,
+		Reason:      "Dropped by NetworkPolicy ns2/deny",
+	}, updated.Status.Results[0])
+	assert.Equal(t, "tfs-1", updated.Status.Results[1].Traceflow)
+	assert.Equal(t, ops.Running, updated.Status.Phase)
+
+	completeTraceflow("tfs-1", ops.Failed, traceflowTimeout, nil)
+	updated = sync()
+	assert.Equal(t, ops.Succeeded, updated.Status.Phase)
+	assert.Equal(t, int32(0), updated.Status.Delivered)
+	assert.Equal(t, int32(1), updated.Status.Dropped)
+	assert.Equal(t, int32(1), updated.Status.Failed)
+	assert.Equal(t, traceflowTimeout, updated.Status.Results[1].Reason)
+}
+
+func TestTraceflowSetTooManyPairs(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	crdClient := newCRDClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
+	podInformer := informerFactory.Core().V1().Pods()
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	traceflowSetInformer := crdInformerFactory.Ops().V1alpha1().TraceflowSets()
+	c := NewTraceflowSetController(crdClient, podInformer, namespaceInformer, crdInformerFactory.Ops().V1alpha1().Traceflows(), traceflowSetInformer, time.Hour)
+
+	namespaceInformer.Informer().GetStore().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	// n Pods make n*(n-1) pairs.
+	for i := 0; i < 30; i++ {
+		podInformer.Informer().GetStore().Add(newPod("ns1", fmt.Sprintf("pod%d", i), nil, false))
+	}
+	tfs := &ops.TraceflowSet{ObjectMeta: metav1.ObjectMeta{Name: "tfs", UID: "uid"}}
+	crdClient.OpsV1alpha1().TraceflowSets().Create(context.TODO(), tfs, metav1.CreateOptions{})
+	traceflowSetInformer.Informer().GetStore().Add(tfs)
+
+	require.NoError(t, c.syncTraceflowSet(tfs.Name))
+	updated, err := crdClient.OpsV1alpha1().TraceflowSets().Get(context.TODO(), tfs.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ops.Failed, updated.Status.Phase)
+	assert.Empty(t, updated.Status.Results)
+}
+
+func TestTraceflowSetDeletedTraceflow(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	crdClient := newCRDClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
+	podInformer := informerFactory.Core().V1().Pods()
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	traceflowSetInformer := crdInformerFactory.Ops().V1alpha1().TraceflowSets()
+	c := NewTraceflowSetController(crdClient, podInformer, namespaceInformer, crdInformerFactory.Ops().V1alpha1().Traceflows(), traceflowSetInformer, time.Hour)
+
+	namespaceInformer.Informer().GetStore().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	podInformer.Informer().GetStore().Add(newPod("ns1", "pod0", nil, false))
+	podInformer.Informer().GetStore().Add(newPod("ns1", "pod1", nil, false))
+	tfs := &ops.TraceflowSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfs", UID: "uid"},
+		Spec:       ops.TraceflowSetSpec{MaxConcurrentTraceflows: 1},
+	}
+	crdClient.OpsV1alpha1().TraceflowSets().Create(context.TODO(), tfs, metav1.CreateOptions{})
+	traceflowSetInformer.Informer().GetStore().Add(tfs)
+
+	sync := func() *ops.TraceflowSet {
+		require.NoError(t, c.syncTraceflowSet(tfs.Name))
+		updated, err := crdClient.OpsV1alpha1().TraceflowSets().Get(context.TODO(), tfs.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		traceflowSetInformer.Informer().GetStore().Update(updated)
+		return updated
+	}
+	sync()
+	updated := sync()
+	require.Equal(t, "tfs-0", updated.Status.Results[0].Traceflow)
+
+	// The created Traceflow is not in the informer cache yet, and is still running.
+	updated = sync()
+	assert.Equal(t, ops.Pending, updated.Status.Results[0].Phase)
+	assert.Equal(t, "", updated.Status.Results[1].Traceflow)
+
+	// The Traceflow is deleted before the TraceflowSet sees it completed.
+	tf, err := crdClient.OpsV1alpha1().Traceflows().Get(context.TODO(), "tfs-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.forgetCreatedTraceflow(tf)
+	updated = sync()
+	assert.Equal(t, ops.Failed, updated.Status.Results[0].Phase)
+	assert.Equal(t, "Traceflow was deleted before completion", updated.Status.Results[0].Reason)
+	assert.Equal(t, "tfs-1", updated.Status.Results[1].Traceflow)
+}
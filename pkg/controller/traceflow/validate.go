@@ -0,0 +1,257 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	admv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+// Validator validates the admission of Traceflow CRDs.
+type Validator struct {
+	podLister           corelisters.PodLister
+	podListerSynced     cache.InformerSynced
+	serviceLister       corelisters.ServiceLister
+	serviceListerSynced cache.InformerSynced
+}
+
+// NewValidator returns a new *Validator, which checks that the Pods and
+// Services referred to by the Traceflows exist using the provided informers.
+func NewValidator(podInformer coreinformers.PodInformer, serviceInformer coreinformers.ServiceInformer) *Validator {
+	return &Validator{
+		podLister:           podInformer.Lister(),
+		podListerSynced:     podInformer.Informer().HasSynced,
+		serviceLister:       serviceInformer.Lister(),
+		serviceListerSynced: serviceInformer.Informer().HasSynced,
+	}
+}
+
+// Validate validates the admission of a Traceflow CRD. Most of the fields are
+// already validated by the OpenAPI schema of the CRD, but the Packet fields
+// which depend on each other can only be validated here. IPHeader is not a
+// pointer and is always present in the objects created by Go clients, so the
+// exclusivity of IPHeader and IPv6Header cannot be expressed in the schema.
+// When a Traceflow is created, its source Pod and its destination Pod or
+// Service must also exist, otherwise the Traceflow would only fail after its
+// timeout.
+func (v *Validator) Validate(ar *admv1.AdmissionReview) *admv1.AdmissionResponse {
+	if ar.Request.Operation != admv1.Create && ar.Request.Operation != admv1.Update {
+		return &admv1.AdmissionResponse{Allowed: true}
+	}
+	klog.V(2).Info("Validating Traceflow CRD")
+	var tf opsv1alpha1.Traceflow
+	if err := json.Unmarshal(ar.Request.Object.Raw, &tf); err != nil {
+		klog.Errorf("Error de-serializing current Traceflow")
+		return &admv1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+	err := validateTraceflowSpec(&tf.Spec)
+	if err == nil && ar.Request.Operation == admv1.Create {
+		err = v.validateTraceflowEndpoints(&tf.Spec)
+	}
+	if err != nil {
+		return &admv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+	return &admv1.AdmissionResponse{Allowed: true}
+}
+
+// validateTraceflowEndpoints checks that the source Pod and the destination Pod
+// or Service of the Traceflow exist. The checks are skipped until the informers
+// are synced, e.g. when this replica of antrea-controller is not the leader, as
+// the Traceflow would be wrongly rejected.
+func (v *Validator) validateTraceflowEndpoints(spec *opsv1alpha1.TraceflowSpec) error {
+	if !v.podListerSynced() || !v.serviceListerSynced() {
+		klog.V(2).Info("Skipping the validation of the Traceflow source and destination as the informers are not synced")
+		return nil
+	}
+	if spec.Source.Pod == "" || spec.Source.Namespace == "" {
+		return errors.New("source pod and namespace must be set")
+	}
+	if _, err := v.podLister.Pods(spec.Source.Namespace).Get(spec.Source.Pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("source Pod %s/%s does not exist, check the source pod and namespace fields", spec.Source.Namespace, spec.Source.Pod)
+		}
+		return fmt.Errorf("failed to get source Pod %s/%s: %v", spec.Source.Namespace, spec.Source.Pod, err)
+	}
+	dst := &spec.Destination
+	if dst.Pod != "" && dst.Service != "" {
+		return errors.New("destination pod and service cannot be set at the same time")
+	}
+	if (dst.Pod != "" || dst.Service != "") && dst.Namespace == "" {
+		return errors.New("destination namespace must be set with destination pod or service")
+	}
+	if dst.Pod != "" {
+		if _, err := v.podLister.Pods(dst.Namespace).Get(dst.Pod); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("destination Pod %s/%s does not exist, check the destination pod and namespace fields", dst.Namespace, dst.Pod)
+			}
+			return fmt.Errorf("failed to get destination Pod %s/%s: %v", dst.Namespace, dst.Pod, err)
+		}
+	}
+	if dst.Service != "" {
+		if _, err := v.serviceLister.Services(dst.Namespace).Get(dst.Service); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("destination Service %s/%s does not exist, check the destination service and namespace fields", dst.Namespace, dst.Service)
+			}
+			return fmt.Errorf("failed to get destination Service %s/%s: %v", dst.Namespace, dst.Service, err)
+		}
+	}
+	return nil
+}
+
+func validateTraceflowSpec(spec *opsv1alpha1.TraceflowSpec) error {
+	packet := &spec.Packet
+	isIPv6 := packet.IPv6Header != nil
+	if isIPv6 && packet.IPHeader != (opsv1alpha1.IPHeader{}) {
+		return errors.New("packet ipHeader and ipv6Header cannot be set at the same time")
+	}
+	if spec.Destination.IP != "" {
+		destIP := net.ParseIP(spec.Destination.IP)
+		if destIP == nil {
+			return fmt.Errorf("destination IP is not valid: %s", spec.Destination.IP)
+		}
+		if isIPv6 != (destIP.To4() == nil) {
+			return fmt.Errorf("destination IP %s does not match the IP version of the packet", spec.Destination.IP)
+		}
+	}
+	if isIPv6 {
+		if err := validateIP(packet.IPv6Header.SrcIP, true); err != nil {
+			return fmt.Errorf("packet ipv6Header srcIP is not valid: %v", err)
+		}
+		if packet.IPv6Header.NextHeader != nil {
+			if err := validateRange("ipv6Header nextHeader", *packet.IPv6Header.NextHeader, 0, 255); err != nil {
+				return err
+			}
+		}
+		if err := validateRange("ipv6Header hopLimit", packet.IPv6Header.HopLimit, 0, 255); err != nil {
+			return err
+		}
+	} else {
+		if err := validateIP(packet.IPHeader.SrcIP, false); err != nil {
+			return fmt.Errorf("packet ipHeader srcIP is not valid: %v", err)
+		}
+		if err := validateRange("ipHeader protocol", packet.IPHeader.Protocol, 0, 255); err != nil {
+			return err
+		}
+		if err := validateRange("ipHeader ttl", packet.IPHeader.TTL, 0, 255); err != nil {
+			return err
+		}
+		if err := validateRange("ipHeader flags", packet.IPHeader.Flags, 0, 7); err != nil {
+			return err
+		}
+	}
+	return validateTransportHeader(&packet.TransportHeader)
+}
+
+func validateTransportHeader(header *opsv1alpha1.TransportHeader) error {
+	count := 0
+	if header.ICMP != nil {
+		count++
+		if header.ICMP.Type != nil {
+			if err := validateRange("icmp type", *header.ICMP.Type, 0, 255); err != nil {
+				return err
+			}
+		}
+		if err := validateRange("icmp code", header.ICMP.Code, 0, 255); err != nil {
+			return err
+		}
+		if err := validateRange("icmp id", header.ICMP.ID, 0, 65535); err != nil {
+			return err
+		}
+		if err := validateRange("icmp sequence", header.ICMP.Sequence, 0, 65535); err != nil {
+			return err
+		}
+	}
+	if header.UDP != nil {
+		count++
+		if err := validatePort("udp srcPort", header.UDP.SrcPort); err != nil {
+			return err
+		}
+		if err := validatePort("udp dstPort", header.UDP.DstPort); err != nil {
+			return err
+		}
+	}
+	if header.TCP != nil {
+		count++
+		if err := validatePort("tcp srcPort", header.TCP.SrcPort); err != nil {
+			return err
+		}
+		if err := validatePort("tcp dstPort", header.TCP.DstPort); err != nil {
+			return err
+		}
+		if err := validateRange("tcp flags", header.TCP.Flags, 0, 255); err != nil {
+			return err
+		}
+	}
+	if count > 1 {
+		return errors.New("only one of packet transportHeader icmp, udp and tcp can be set")
+	}
+	return nil
+}
+
+// validateIP checks that ip is empty or is a valid address of the expected IP
+// version.
+func validateIP(ip string, isIPv6 bool) error {
+	if ip == "" {
+		return nil
+	}
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return fmt.Errorf("invalid IP %s", ip)
+	}
+	if isIPv6 && parsedIP.To4() != nil {
+		return fmt.Errorf("expect IPv6, but got IPv4 %s", ip)
+	}
+	if !isIPv6 && parsedIP.To4() == nil {
+		return fmt.Errorf("expect IPv4, but got IPv6 %s", ip)
+	}
+	return nil
+}
+
+// validatePort checks that port is in the range 1-65535. Zero means that the
+// port is not set.
+func validatePort(name string, port int32) error {
+	if port == 0 {
+		return nil
+	}
+	return validateRange(name, port, 1, 65535)
+}
+
+func validateRange(name string, value, min, max int32) error {
+	if value < min || value > max {
+		return fmt.Errorf("packet %s must be in the range %d-%d, got %d", name, min, max, value)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ops "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+func newValidator(stopCh <-chan struct{}, objects ...runtime.Object) *Validator {
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(objects...), informerDefaultResync)
+	v := NewValidator(informerFactory.Core().V1().Pods(), informerFactory.Core().V1().Services())
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	return v
+}
+
+func newAdmissionReview(t *testing.T, operation admv1.Operation, spec ops.TraceflowSpec) *admv1.AdmissionReview {
+	tf := &ops.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf"},
+		Spec:       spec,
+	}
+	raw, err := json.Marshal(tf)
+	assert.NoError(t, err)
+	return &admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	v := newValidator(stopCh, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"}})
+	nextHeader := int32(6)
+	icmpType := int32(3)
+	invalidICMPType := int32(256)
+	tests := []struct {
+		name    string
+		packet  ops.Packet
+		destIP  string
+		allowed bool
+	}{
+		{
+			name: "tcp",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{SrcIP: "10.10.0.1", Protocol: ops.TCPProtocol, TTL: 64},
+				TransportHeader: ops.TransportHeader{
+					TCP: &ops.TCPHeader{SrcPort: 10000, DstPort: 80, Flags: 2},
+				},
+			},
+			destIP:  "10.10.1.1",
+			allowed: true,
+		},
+		{
+			name: "ipv6",
+			packet: ops.Packet{
+				IPv6Header: &ops.IPv6Header{SrcIP: "fd00:10:10::1", NextHeader: &nextHeader},
+				TransportHeader: ops.TransportHeader{
+					TCP: &ops.TCPHeader{DstPort: 80},
+				},
+			},
+			destIP:  "fd00:10:10:1::1",
+			allowed: true,
+		},
+		{
+			name: "ipv4-and-ipv6-headers",
+			packet: ops.Packet{
+				IPHeader:   ops.IPHeader{Protocol: ops.TCPProtocol},
+				IPv6Header: &ops.IPv6Header{},
+			},
+			allowed: false,
+		},
+		{
+			name: "ipv6-src-ip-in-ipv4-header",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{SrcIP: "fd00:10:10::1"},
+			},
+			allowed: false,
+		},
+		{
+			name: "ipv4-dest-ip-with-ipv6-header",
+			packet: ops.Packet{
+				IPv6Header: &ops.IPv6Header{},
+			},
+			destIP:  "10.10.1.1",
+			allowed: false,
+		},
+		{
+			name: "invalid-ttl",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{TTL: 256},
+			},
+			allowed: false,
+		},
+		{
+			name: "invalid-port",
+			packet: ops.Packet{
+				TransportHeader: ops.TransportHeader{
+					UDP: &ops.UDPHeader{DstPort: 65536},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "icmp-type-and-code",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{Protocol: ops.ICMPProtocol},
+				TransportHeader: ops.TransportHeader{
+					ICMP: &ops.ICMPEchoRequestHeader{Type: &icmpType, Code: 3},
+				},
+			},
+			allowed: true,
+		},
+		{
+			name: "invalid-icmp-type",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{Protocol: ops.ICMPProtocol},
+				TransportHeader: ops.TransportHeader{
+					ICMP: &ops.ICMPEchoRequestHeader{Type: &invalidICMPType},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "invalid-icmp-code",
+			packet: ops.Packet{
+				IPHeader: ops.IPHeader{Protocol: ops.ICMPProtocol},
+				TransportHeader: ops.TransportHeader{
+					ICMP: &ops.ICMPEchoRequestHeader{Code: -1},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "tcp-and-udp-headers",
+			packet: ops.Packet{
+				TransportHeader: ops.TransportHeader{
+					TCP: &ops.TCPHeader{DstPort: 80},
+					UDP: &ops.UDPHeader{DstPort: 53},
+				},
+			},
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newAdmissionReview(t, admv1.Create, ops.TraceflowSpec{
+				Source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: ops.Destination{IP: tt.destIP},
+				Packet:      tt.packet,
+			})
+			assert.Equal(t, tt.allowed, v.Validate(ar).Allowed)
+		})
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	v := newValidator(stopCh,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "pod2"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "svc2"}},
+	)
+	tests := []struct {
+		name            string
+		operation       admv1.Operation
+		source          ops.Source
+		destination     ops.Destination
+		expectedMessage string
+	}{
+		{
+			name:        "dest-pod",
+			operation:   admv1.Create,
+			source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+			destination: ops.Destination{Namespace: "ns2", Pod: "pod2"},
+		},
+		{
+			name:        "dest-service",
+			operation:   admv1.Create,
+			source:      ops.Source{Namespace: "ns1", Pod: "pod1"},
+			destination: ops.Destination{Namespace: "ns2", Service: "svc2"},
+		},
+		{
+			name:            "missing-source-pod",
+			operation:       admv1.Create,
+			source:          ops.Source{Namespace: "ns2", Pod: "pod1"},
+			destination:     ops.Destination{IP: "10.10.1.1"},
+			expectedMessage: "source Pod ns2/pod1 does not exist, check the source pod and namespace fields",
+		},
+		{
+			name:            "no-source-namespace",
+			operation:       admv1.Create,
+			source:          ops.Source{Pod: "pod1"},
+			destination:     ops.Destination{IP: "10.10.1.1"},
+			expectedMessage: "source pod and namespace must be set",
+		},
+		{
+			name:            "missing-dest-pod",
+			operation:       admv1.Create,
+			source:          ops.Source{Namespace: "ns1", Pod: "pod1"},
+			destination:     ops.Destination{Namespace: "ns2", Pod: "pod3"},
+			expectedMessage: "destination Pod ns2/pod3 does not exist, check the destination pod and namespace fields",
+		},
+		{
+			name:            "missing-dest-service",
+			operation:       admv1.Create,
+			source:          ops.Source{Namespace: "ns1", Pod: "pod1"},
+			destination:     ops.Destination{Namespace: "ns1", Service: "svc2"},
+			expectedMessage: "destination Service ns1/svc2 does not exist, check the destination service and namespace fields",
+		},
+		{
+			name:            "dest-pod-and-service",
+			operation:       admv1.Create,
+			source:          ops.Source{Namespace: "ns1", Pod: "pod1"},
+			destination:     ops.Destination{Namespace: "ns2", Pod: "pod2", Service: "svc2"},
+			expectedMessage: "destination pod and service cannot be set at the same time",
+		},
+		{
+			// The source Pod may be deleted while the Traceflow is running.
+			name:        "update-missing-source-pod",
+			operation:   admv1.Update,
+			source:      ops.Source{Namespace: "ns1", Pod: "pod3"},
+			destination: ops.Destination{IP: "10.10.1.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newAdmissionReview(t, tt.operation, ops.TraceflowSpec{Source: tt.source, Destination: tt.destination})
+			response := v.Validate(ar)
+			if tt.expectedMessage == "" {
+				assert.True(t, response.Allowed)
+			} else {
+				assert.False(t, response.Allowed)
+				assert.Equal(t, tt.expectedMessage, response.Result.Message)
+			}
+		})
+	}
+}
@@ -62,4 +62,17 @@ type Group struct {
 	// to client's selection.
 	GroupMembers controlplane.GroupMemberSet
 	IPBlock      *controlplane.IPBlock
+	// ServiceReference is set if the internal Group selects the ClusterIP of a Service and the
+	// Pods in its Endpoints. In that case, GroupMembers are computed from the Service and its
+	// Endpoints, and Selector is empty.
+	ServiceReference *ServiceReference
+	// ChildGroups are the names of the ClusterGroups whose members are the members of this
+	// internal Group. Selector is empty if they are set.
+	ChildGroups []string
+}
+
+// ServiceReference is a reference to a Service.
+type ServiceReference struct {
+	Name      string
+	Namespace string
 }
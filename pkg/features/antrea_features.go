@@ -56,6 +56,35 @@ const (
 	// alpha: v0.13
 	// Expose Pod ports through NodePort
 	NodePortLocal featuregate.Feature = "NodePortLocal"
+
+	// alpha: v0.13
+	// Enable Egress, which allows selected Pods to use a specific SNAT IP for traffic to the external network.
+	Egress featuregate.Feature = "Egress"
+
+	// alpha: v0.13
+	// Enable NodePort Service support in AntreaProxy, so that NodePort Services are load-balanced in the OVS
+	// pipeline instead of by kube-proxy.
+	AntreaProxyNodePort featuregate.Feature = "AntreaProxyNodePort"
+
+	// alpha: v0.13
+	// Enforce the kubernetes.io/ingress-bandwidth and kubernetes.io/egress-bandwidth annotations of the Pods with
+	// OpenFlow meters, instead of relying on the bandwidth CNI plugin and tc.
+	PodBandwidth featuregate.Feature = "PodBandwidth"
+
+	// alpha: v0.13
+	// Enforce the BandwidthQuotas, which limit the aggregate egress bandwidth of the Pods of a set of Namespaces on
+	// each Node, with OpenFlow meters shared by the Pods.
+	BandwidthQuota featuregate.Feature = "BandwidthQuota"
+
+	// alpha: v0.13
+	// Send the egress traffic of the Pods leaving the OVS bridge to the OVS QoS queues of their classes, so that
+	// latency-sensitive Pods get a guaranteed bandwidth under congestion.
+	PodQoS featuregate.Feature = "PodQoS"
+
+	// alpha: v0.13
+	// Set the pod.antrea.io/network-ready condition of the Pods which have it as a readiness gate, once their flows
+	// have been installed and the NetworkPolicy rules applied to them have been realized.
+	PodReadinessGate featuregate.Feature = "PodReadinessGate"
 )
 
 var (
@@ -70,12 +99,18 @@ var (
 	// To add a new feature, define a key for it above and add it here. The features will be
 	// available throughout Antrea binaries.
 	defaultAntreaFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		AntreaPolicy:       {Default: false, PreRelease: featuregate.Alpha},
-		AntreaProxy:        {Default: true, PreRelease: featuregate.Beta},
-		Traceflow:          {Default: true, PreRelease: featuregate.Beta},
-		FlowExporter:       {Default: false, PreRelease: featuregate.Alpha},
-		NetworkPolicyStats: {Default: false, PreRelease: featuregate.Alpha},
-		NodePortLocal:      {Default: false, PreRelease: featuregate.Alpha},
+		AntreaPolicy:        {Default: false, PreRelease: featuregate.Alpha},
+		AntreaProxy:         {Default: true, PreRelease: featuregate.Beta},
+		Traceflow:           {Default: true, PreRelease: featuregate.Beta},
+		FlowExporter:        {Default: false, PreRelease: featuregate.Alpha},
+		NetworkPolicyStats:  {Default: false, PreRelease: featuregate.Alpha},
+		NodePortLocal:       {Default: false, PreRelease: featuregate.Alpha},
+		Egress:              {Default: false, PreRelease: featuregate.Alpha},
+		AntreaProxyNodePort: {Default: false, PreRelease: featuregate.Alpha},
+		PodBandwidth:        {Default: false, PreRelease: featuregate.Alpha},
+		BandwidthQuota:      {Default: false, PreRelease: featuregate.Alpha},
+		PodQoS:              {Default: false, PreRelease: featuregate.Alpha},
+		PodReadinessGate:    {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	// UnsupportedFeaturesOnWindows records the features not supported on
@@ -89,7 +124,12 @@ var (
 	// can have different FeatureSpecs between Linux and Windows, we should
 	// still define a separate defaultAntreaFeatureGates map for Windows.
 	unsupportedFeaturesOnWindows = map[featuregate.Feature]struct{}{
-		NodePortLocal: {},
+		NodePortLocal:    {},
+		Egress:           {},
+		PodBandwidth:     {},
+		BandwidthQuota:   {},
+		PodQoS:           {},
+		PodReadinessGate: {},
 	}
 )
 
@@ -0,0 +1,170 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+var (
+	flowTableRegexp     = regexp.MustCompile(`(?:^|\s)table=(\d+)`)
+	flowPacketNumRegexp = regexp.MustCompile(`n_packets=(\d+)`)
+	// The actions which send packets to another table: goto_table, resubmit, and ct with the table to which the
+	// packets are recirculated.
+	nextTableRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`goto_table:(\d+)`),
+		regexp.MustCompile(`resubmit\([^,)]*,(\d+)\)`),
+		regexp.MustCompile(`\bct\([^)]*\btable=(\d+)`),
+	}
+)
+
+// PipelineTable is a flow table of the OVS pipeline of an Antrea Agent.
+type PipelineTable struct {
+	ID uint8
+	// Name is the name of the table in the Antrea pipeline, empty if unknown.
+	Name      string
+	FlowNum   int
+	PacketNum uint64
+	// NextTables are the IDs of the tables to which the flows of the table send packets, in ascending order.
+	NextTables []uint8
+}
+
+// ParsePipelineTables parses the flows dumped with "ovs-ofctl dump-flows" and returns the tables of the pipeline in
+// ascending order of ID. Tables without flows are returned if packets are sent to them.
+func ParsePipelineTables(flows []string) ([]*PipelineTable, error) {
+	tables := map[uint8]*PipelineTable{}
+	nextTables := map[uint8]map[uint8]bool{}
+	getTable := func(id uint8) *PipelineTable {
+		table, ok := tables[id]
+		if !ok {
+			table = &PipelineTable{ID: id}
+			tables[id] = table
+			nextTables[id] = map[uint8]bool{}
+		}
+		return table
+	}
+	for _, flow := range flows {
+		flow = strings.TrimSpace(flow)
+		if flow == "" {
+			continue
+		}
+		actionsIdx := strings.LastIndex(flow, "actions=")
+		if actionsIdx < 0 {
+			return nil, fmt.Errorf("no actions in flow %s", flow)
+		}
+		// The table may be omitted for table 0.
+		var id uint8
+		if match := flowTableRegexp.FindStringSubmatch(flow[:actionsIdx]); match != nil {
+			n, err := strconv.ParseUint(match[1], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid table in flow %s: %v", flow, err)
+			}
+			id = uint8(n)
+		}
+		table := getTable(id)
+		table.FlowNum++
+		if match := flowPacketNumRegexp.FindStringSubmatch(flow[:actionsIdx]); match != nil {
+			n, _ := strconv.ParseUint(match[1], 10, 64)
+			table.PacketNum += n
+		}
+		for _, re := range nextTableRegexps {
+			for _, match := range re.FindAllStringSubmatch(flow[actionsIdx:], -1) {
+				n, err := strconv.ParseUint(match[1], 10, 8)
+				if err != nil {
+					return nil, fmt.Errorf("invalid next table in flow %s: %v", flow, err)
+				}
+				nextTables[id][uint8(n)] = true
+			}
+		}
+	}
+	for id := range nextTables {
+		for nextID := range nextTables[id] {
+			getTable(nextID)
+			tables[id].NextTables = append(tables[id].NextTables, nextID)
+		}
+		sort.Slice(tables[id].NextTables, func(i, j int) bool {
+			return tables[id].NextTables[i] < tables[id].NextTables[j]
+		})
+	}
+	result := make([]*PipelineTable, 0, len(tables))
+	for _, table := range tables {
+		result = append(result, table)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+func getPipelineTableNodeName(id uint8) string {
+	return fmt.Sprintf("table_%d", id)
+}
+
+func getPipelineTableMessage(table *PipelineTable) string {
+	str := strconv.Itoa(int(table.ID))
+	if len(table.Name) > 0 {
+		str += ": " + table.Name
+	}
+	return fmt.Sprintf("%s\nFlows: %d\nPackets: %d", str, table.FlowNum, table.PacketNum)
+}
+
+// GenPipelineGraph generates the graph of an OVS pipeline, in which the tables are the nodes, and the edges are the
+// actions of their flows which send packets to other tables. The tables without flows are rendered with a dashed
+// border, as the packets sent to them are dropped.
+func GenPipelineGraph(name string, tables []*PipelineTable) (string, error) {
+	g, _ := gographviz.ParseString(`digraph G {}`)
+	graph := gographviz.NewGraph()
+	if err := gographviz.Analyse(g, graph); err != nil {
+		return "", err
+	}
+	graph.Attrs[gographviz.Center] = "true"
+	graph.Attrs[gographviz.Label] = getWrappedStr(name)
+	graph.Attrs[gographviz.LabelLOC] = "t"
+	if err := graph.SetDir(true); err != nil {
+		return "", err
+	}
+
+	nodes := make(map[uint8]*gographviz.Node, len(tables))
+	for _, table := range tables {
+		node, err := createNodeWithDefaultStyle(graph, graph.Name, getPipelineTableNodeName(table.ID))
+		if err != nil {
+			return "", err
+		}
+		node.Attrs[gographviz.Label] = getWrappedStr(getPipelineTableMessage(table))
+		node.Attrs[gographviz.FillColor] = gainsboro
+		if table.FlowNum == 0 {
+			node.Attrs[gographviz.Style] = `"rounded,dashed"`
+		}
+		nodes[table.ID] = node
+	}
+	for _, table := range tables {
+		for _, nextID := range table.NextTables {
+			nextNode, ok := nodes[nextID]
+			if !ok {
+				return "", fmt.Errorf("table %d is not in the pipeline", nextID)
+			}
+			if _, err := createDirectedEdgeWithDefaultStyle(graph, nodes[table.ID], nextNode, true); err != nil {
+				return "", err
+			}
+		}
+	}
+	return graph.String(), nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpFlows is the output of "ovs-ofctl dump-flows" for a small pipeline. The table is omitted for the second flow of
+// table 0, as in the output of ovs-ofctl, and tables 40 and 70 have no flows.
+const dumpFlows = `
+ cookie=0x1000000000000, duration=85.420s, table=0, n_packets=10, n_bytes=980, priority=200,in_port="antrea-gw0" actions=load:0x1->NXM_NX_REG0[0..15],goto_table:10
+ cookie=0x1000000000000, duration=85.420s, n_packets=5, n_bytes=490, priority=0 actions=drop
+ cookie=0x1000000000000, duration=85.420s, table=10, n_packets=7, n_bytes=686, priority=200,ip,in_port="antrea-gw0" actions=goto_table:30
+ cookie=0x1000000000000, duration=85.420s, table=10, n_packets=3, n_bytes=294, priority=0 actions=drop
+ cookie=0x1000000000000, duration=85.420s, table=30, n_packets=12, n_bytes=1176, priority=200,ip actions=ct(table=31,zone=65520,nat)
+ cookie=0x1000000000000, duration=85.420s, table=31, n_packets=4, n_bytes=392, priority=190,ct_state=+inv+trk,ip actions=drop
+ cookie=0x1000000000000, duration=85.420s, table=31, n_packets=8, n_bytes=784, priority=0 actions=resubmit(,50),resubmit(,40)
+ cookie=0x1000000000000, duration=85.420s, table=50, n_packets=6, n_bytes=588, priority=0 actions=goto_table:70
+`
+
+func TestParsePipelineTables(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		flows    []string
+		expected []*PipelineTable
+		wantErr  bool
+	}{
+		{
+			name:  "dump-flows",
+			flows: strings.Split(dumpFlows, "\n"),
+			expected: []*PipelineTable{
+				{ID: 0, FlowNum: 2, PacketNum: 15, NextTables: []uint8{10}},
+				{ID: 10, FlowNum: 2, PacketNum: 10, NextTables: []uint8{30}},
+				{ID: 30, FlowNum: 1, PacketNum: 12, NextTables: []uint8{31}},
+				{ID: 31, FlowNum: 2, PacketNum: 12, NextTables: []uint8{40, 50}},
+				{ID: 40},
+				{ID: 50, FlowNum: 1, PacketNum: 6, NextTables: []uint8{70}},
+				{ID: 70},
+			},
+		},
+		{
+			name:     "no flows",
+			expected: []*PipelineTable{},
+		},
+		{
+			name:    "no actions",
+			flows:   []string{" cookie=0x0, table=0, n_packets=0, priority=0"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid table",
+			flows:   []string{" cookie=0x0, table=256, n_packets=0, priority=0 actions=drop"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid next table",
+			flows:   []string{" cookie=0x0, table=0, n_packets=0, priority=0 actions=goto_table:300"},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tables, err := ParsePipelineTables(tc.flows)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tables)
+		})
+	}
+}
+
+func TestGenPipelineGraph(t *testing.T) {
+	tables, err := ParsePipelineTables(strings.Split(dumpFlows, "\n"))
+	require.NoError(t, err)
+	tables[0].Name = "Classification"
+
+	output, err := GenPipelineGraph("node1", tables)
+	require.NoError(t, err)
+	ast, err := gographviz.ParseString(output)
+	require.NoError(t, err)
+	graph := gographviz.NewGraph()
+	require.NoError(t, gographviz.Analyse(ast, graph))
+
+	assert.Equal(t, `"node1"`, graph.Attrs[gographviz.Label])
+	for _, tc := range []struct {
+		node          string
+		expectedLabel string
+		expectedStyle string
+	}{
+		{node: "table_0", expectedLabel: "\"0: Classification\nFlows: 2\nPackets: 15\"", expectedStyle: `"rounded,filled,solid"`},
+		{node: "table_31", expectedLabel: "\"31\nFlows: 2\nPackets: 12\"", expectedStyle: `"rounded,filled,solid"`},
+		{node: "table_40", expectedLabel: "\"40\nFlows: 0\nPackets: 0\"", expectedStyle: `"rounded,dashed"`},
+	} {
+		node, ok := graph.Nodes.Lookup[tc.node]
+		require.True(t, ok, "Node %s not found", tc.node)
+		assert.Equal(t, tc.expectedLabel, node.Attrs[gographviz.Label])
+		assert.Equal(t, tc.expectedStyle, node.Attrs[gographviz.Style])
+	}
+
+	var edges []string
+	for src, dsts := range graph.Edges.SrcToDsts {
+		for dst := range dsts {
+			edges = append(edges, src+"->"+dst)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"table_0->table_10",
+		"table_10->table_30",
+		"table_30->table_31",
+		"table_31->table_40",
+		"table_31->table_50",
+		"table_50->table_70",
+	}, edges)
+
+	_, err = GenPipelineGraph("node1", []*PipelineTable{{ID: 0, FlowNum: 1, NextTables: []uint8{10}}})
+	assert.Error(t, err, "Generating a graph with an edge to an unknown table should fail")
+}
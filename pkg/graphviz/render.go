@@ -0,0 +1,136 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+// Format is the output format of a rendered graph.
+type Format string
+
+const (
+	FormatDOT Format = "dot"
+	FormatSVG Format = "svg"
+	FormatPNG Format = "png"
+)
+
+// dotBinary is the Graphviz binary used to lay out and render the graphs.
+const dotBinary = "dot"
+
+var contentTypes = map[Format]string{
+	FormatDOT: "text/vnd.graphviz",
+	FormatSVG: "image/svg+xml",
+	FormatPNG: "image/png",
+}
+
+// runDot runs the dot binary with the provided graph as input, and returns its output in the provided format. It is a
+// variable so that it can be replaced in tests.
+var runDot = func(ctx context.Context, graph string, format Format) ([]byte, error) {
+	path, err := exec.LookPath(dotBinary)
+	if err != nil {
+		return nil, fmt.Errorf("the dot binary of Graphviz is required to render graphs to %s: %v", format, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "-T"+string(format))
+	cmd.Stdin = strings.NewReader(graph)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error when rendering graph to %s: %v: %s", format, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ParseFormat parses a graph output format, which is case-insensitive.
+func ParseFormat(s string) (Format, error) {
+	format := Format(strings.ToLower(s))
+	if _, ok := contentTypes[format]; !ok {
+		return "", fmt.Errorf("unsupported graph format %q, supported formats are dot, svg and png", s)
+	}
+	return format, nil
+}
+
+// ContentType returns the media type of a graph output format.
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Render renders a graph in DOT format, e.g. generated by GenGraph, to the provided format. The DOT format is returned
+// as is, while the other formats are produced by the dot binary of Graphviz, which must be installed.
+func Render(ctx context.Context, graph string, format Format) ([]byte, error) {
+	if format == FormatDOT {
+		return []byte(graph), nil
+	}
+	if _, ok := contentTypes[format]; !ok {
+		return nil, fmt.Errorf("unsupported graph format %q", format)
+	}
+	return runDot(ctx, graph, format)
+}
+
+// TraceflowGetter gets a Traceflow by name.
+type TraceflowGetter func(ctx context.Context, name string) (*opsv1alpha1.Traceflow, error)
+
+// NewTraceflowGraphHandler returns an HTTP handler which serves the graph of a Traceflow as a file to download. The
+// Traceflow is specified with the "name" query parameter, and the format with the "format" query parameter, which
+// defaults to dot, as it does not require Graphviz to be installed.
+func NewTraceflowGraphHandler(getTraceflow TraceflowGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "Traceflow name must be provided", http.StatusBadRequest)
+			return
+		}
+		format := FormatDOT
+		if f := r.URL.Query().Get("format"); f != "" {
+			var err error
+			if format, err = ParseFormat(f); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		tf, err := getTraceflow(r.Context(), name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				http.Error(w, fmt.Sprintf("Traceflow %s not found", name), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		graph, err := GenGraph(tf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := Render(r.Context(), graph, format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+		w.Write(data)
+	}
+}
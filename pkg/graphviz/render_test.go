@@ -0,0 +1,129 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{input: "dot", expected: FormatDOT},
+		{input: "SVG", expected: FormatSVG},
+		{input: "png", expected: FormatPNG},
+		{input: "jpg", wantErr: true},
+	} {
+		format, err := ParseFormat(tc.input)
+		if tc.wantErr {
+			assert.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, format)
+	}
+}
+
+func TestTraceflowGraphHandler(t *testing.T) {
+	defer func(orig func(context.Context, string, Format) ([]byte, error)) { runDot = orig }(runDot)
+	runDot = func(ctx context.Context, graph string, format Format) ([]byte, error) {
+		return []byte("rendered " + string(format)), nil
+	}
+	tf := &opsv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+		Spec: opsv1alpha1.TraceflowSpec{
+			Source:      opsv1alpha1.Source{Namespace: "default", Pod: "pod1"},
+			Destination: opsv1alpha1.Destination{Namespace: "default", Pod: "pod2"},
+		},
+	}
+	handler := NewTraceflowGraphHandler(func(ctx context.Context, name string) (*opsv1alpha1.Traceflow, error) {
+		if name != tf.Name {
+			return nil, errors.NewNotFound(schema.GroupResource{Group: "ops.antrea.tanzu.vmware.com", Resource: "traceflows"}, name)
+		}
+		return tf, nil
+	})
+
+	for _, tc := range []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "default format",
+			query:               "name=tf1",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/vnd.graphviz",
+		},
+		{
+			name:                "svg format",
+			query:               "name=tf1&format=svg",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/svg+xml",
+			expectedBody:        "rendered svg",
+		},
+		{
+			name:                "png format",
+			query:               "name=tf1&format=png",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/png",
+			expectedBody:        "rendered png",
+		},
+		{
+			name:           "missing name",
+			query:          "format=png",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid format",
+			query:          "name=tf1&format=jpg",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Traceflow not found",
+			query:          "name=tf2",
+			expectedStatus: http.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/traceflowgraph?"+tc.query, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tc.expectedContentType, recorder.Header().Get("Content-Type"))
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/awalterschulze/gographviz"
 
@@ -178,6 +179,9 @@ func getTraceflowMessage(o *opsv1alpha1.Observation, spec *opsv1alpha1.Traceflow
 	if o.Component == opsv1alpha1.NetworkPolicy && len(o.NetworkPolicy) > 0 {
 		str += "\nNetpol: " + o.NetworkPolicy
 	}
+	if o.Action == opsv1alpha1.Dropped && len(o.Reason) > 0 {
+		str += "\nReason: " + o.Reason
+	}
 	if len(o.Pod) > 0 {
 		str += "\nTo: " + o.Pod
 		if len(spec.Destination.Pod) == 0 {
@@ -193,6 +197,31 @@ func getTraceflowMessage(o *opsv1alpha1.Observation, spec *opsv1alpha1.Traceflow
 	return str
 }
 
+// getTraceflowTooltip gets the tooltip of a component node in traceflow graph: the component and its sub-name, and
+// the timestamp of the Node result. All the observations of a Node are reported at the same time, with a precision of
+// one second, so the timestamp is a coarse Node timestamp rather than the time of the observation. prevTimestamp is
+// the timestamp of the previous Node on the path of the packet, set only for the first observation of a Node, and 0
+// otherwise. There is no latency between the observations of a Node, as they are all decoded from the same packet
+// received by the agent, and the time since the previous Node is clamped to 0 when the clocks of the Nodes are skewed.
+func getTraceflowTooltip(o *opsv1alpha1.Observation, result *opsv1alpha1.NodeResult, prevTimestamp int64) string {
+	str := string(o.Component)
+	if len(o.ComponentInfo) > 0 {
+		str += "\nSub-component: " + o.ComponentInfo
+	}
+	if len(result.Node) > 0 {
+		str += "\nNode: " + result.Node
+	}
+	if result.Timestamp > 0 {
+		str += "\nNode timestamp: " + time.Unix(result.Timestamp, 0).UTC().Format(time.RFC3339)
+		if prevTimestamp > result.Timestamp {
+			str += "\nTime since previous Node: 0s (Node clocks are skewed)"
+		} else if prevTimestamp > 0 {
+			str += "\nTime since previous Node: " + (time.Duration(result.Timestamp-prevTimestamp) * time.Second).String()
+		}
+	}
+	return str
+}
+
 // In Graphviz, clusters are surrounded by a pair of "{}" with string "subgraph ClusterName" before them.
 // The function finds the start and end index of specific cluster.
 func findClusterString(graphStr string, clusterName string) (startIndex int, endIndex int) {
@@ -233,6 +262,42 @@ func genOutput(graph *gographviz.Graph, isSingleCluster bool) string {
 	return str
 }
 
+// getPathObservations returns a copy of the observations of the packet on its path through the Node. The
+// observations of the return path of the reply are excluded, a dropped reply is shown in the graph label.
+func getPathObservations(result *opsv1alpha1.NodeResult) []opsv1alpha1.Observation {
+	obs := make([]opsv1alpha1.Observation, 0, len(result.Observations))
+	for _, o := range result.Observations {
+		if o.Component != opsv1alpha1.ReplyPath {
+			obs = append(obs, o)
+		}
+	}
+	return obs
+}
+
+// getDroppedObservation returns the first observation in which the packet is dropped, or nil if the packet is not dropped.
+func getDroppedObservation(tf *opsv1alpha1.Traceflow) *opsv1alpha1.Observation {
+	for i := range tf.Status.Results {
+		for j := range tf.Status.Results[i].Observations {
+			if tf.Status.Results[i].Observations[j].Action == opsv1alpha1.Dropped {
+				return &tf.Status.Results[i].Observations[j]
+			}
+		}
+	}
+	return nil
+}
+
+// getTraceflowDropMessage gets the graph label of a traceflow whose packet is dropped.
+func getTraceflowDropMessage(tf *opsv1alpha1.Traceflow, o *opsv1alpha1.Observation) string {
+	str := fmt.Sprintf("%s\nPacket dropped by %s", tf.Name, o.Component)
+	if len(o.Reason) > 0 {
+		str += ": " + o.Reason
+	}
+	if len(o.NetworkPolicy) > 0 {
+		str += " (" + o.NetworkPolicy + ")"
+	}
+	return getWrappedStr(str)
+}
+
 func getTraceflowStatusMessage(tf *opsv1alpha1.Traceflow) string {
 	switch tf.Status.Phase {
 	case opsv1alpha1.Failed:
@@ -246,8 +311,10 @@ func getTraceflowStatusMessage(tf *opsv1alpha1.Traceflow) string {
 	}
 }
 
+// genSubGraph draws the observations of a Node result in the cluster. prevTimestamp is the timestamp of the previous
+// Node result on the path of the packet, or 0 if there is none.
 func genSubGraph(graph *gographviz.Graph, cluster *gographviz.SubGraph, result *opsv1alpha1.NodeResult, spec *opsv1alpha1.TraceflowSpec,
-	endpointNodeName string, isForwardDir bool, addNodeNum int) ([]*gographviz.Node, error) {
+	endpointNodeName string, isForwardDir bool, addNodeNum int, prevTimestamp int64) ([]*gographviz.Node, error) {
 	var nodes []*gographviz.Node
 
 	// Show the name of cluster.
@@ -272,12 +339,19 @@ func genSubGraph(graph *gographviz.Graph, cluster *gographviz.SubGraph, result *
 
 	// Reorder the observations according to the direction of edges.
 	// Before that, deep copy observations to prevent possible risks of the original traceflow being modified.
-	obs := make([]opsv1alpha1.Observation, len(result.Observations))
-	copy(obs, result.Observations)
+	// The tooltips are computed first, as the time since the previous Node is only shown for the first observation of
+	// the Node on the path.
+	obs := getPathObservations(result)
+	tooltips := make([]string, len(obs))
+	for i := range obs {
+		tooltips[i] = getTraceflowTooltip(&obs[i], result, prevTimestamp)
+		prevTimestamp = 0
+	}
 	if !isForwardDir {
 		for i := len(obs)/2 - 1; i >= 0; i-- {
 			opp := len(obs) - 1 - i
 			obs[i], obs[opp] = obs[opp], obs[i]
+			tooltips[i], tooltips[opp] = tooltips[opp], tooltips[i]
 		}
 	}
 
@@ -303,20 +377,28 @@ func genSubGraph(graph *gographviz.Graph, cluster *gographviz.SubGraph, result *
 			} else {
 				edge.Attrs[gographviz.MinLen] = "1"
 			}
-			if o.Action == opsv1alpha1.Dropped && !isForwardDir {
-				edge.Attrs[gographviz.Style] = `"invis"`
+			if o.Action == opsv1alpha1.Dropped {
+				if isForwardDir {
+					edge.Attrs[gographviz.Color] = darkRed
+				} else {
+					edge.Attrs[gographviz.Style] = `"invis"`
+				}
 			}
 		}
-		// Set the pattern of node.
+		// Set the pattern of node. Dropped packets are rendered with red nodes.
 		if o.Action == opsv1alpha1.Dropped {
 			node.Attrs[gographviz.Color] = fireBrick
 			node.Attrs[gographviz.FillColor] = mistyRose
+			node.Attrs[gographviz.FontColor] = darkRed
+			node.Attrs[gographviz.PenWidth] = "2.0"
 		} else {
 			node.Attrs[gographviz.FillColor] = gainsboro
 		}
 		// Set the message shown inside node.
 		labelStr := getTraceflowMessage(&o, spec)
 		node.Attrs[gographviz.Label] = getWrappedStr(labelStr)
+		// Set the message shown when hovering the node.
+		node.Attrs[gographviz.Tooltip] = getWrappedStr(tooltips[i])
 	}
 	return nodes, nil
 }
@@ -343,6 +425,10 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 	if tf == nil || senderRst == nil || tf.Status.Phase != opsv1alpha1.Succeeded || len(senderRst.Observations) == 0 {
 		return genOutput(graph, true), nil
 	}
+	if o := getDroppedObservation(tf); o != nil {
+		graph.Attrs[gographviz.Label] = getTraceflowDropMessage(tf, o)
+		graph.Attrs[gographviz.FontColor] = darkRed
+	}
 
 	cluster1, err := createClusterWithDefaultStyle(graph, clusterSrcName)
 	if err != nil {
@@ -350,7 +436,7 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 	}
 	// Handle single node traceflow.
 	if receiverRst == nil {
-		nodes, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getSrcNodeName(tf), true, 0)
+		nodes, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getSrcNodeName(tf), true, 0, 0)
 		if err != nil {
 			return "", err
 		}
@@ -358,7 +444,8 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 		if len(nodes) == 0 {
 			return genOutput(graph, true), nil
 		}
-		switch senderRst.Observations[len(senderRst.Observations)-1].Action {
+		senderObs := getPathObservations(senderRst)
+		switch senderObs[len(senderObs)-1].Action {
 		// If the last action of the sender is FORWARDED,
 		// then the packet has been sent out by sender, implying that there is a disconnection.
 		case opsv1alpha1.Forwarded:
@@ -374,6 +461,17 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 			if err != nil {
 				return "", err
 			}
+		// If the last action of the sender is FORWARDEDOUTOFOVERLAY,
+		// then the packet has left the cluster network, and the destination is drawn outside of the Node.
+		case opsv1alpha1.ForwardedOutOfOverlay:
+			lastNode, err := createEndpointNodeWithDefaultStyle(graph, graph.Name, getDstNodeName(tf))
+			if err != nil {
+				return "", err
+			}
+			_, err = createDirectedEdgeWithDefaultStyle(graph, nodes[len(nodes)-1], lastNode, true)
+			if err != nil {
+				return "", err
+			}
 		case opsv1alpha1.Delivered:
 			lastNode, err := createEndpointNodeWithDefaultStyle(graph, cluster1.Name, getDstNodeName(tf))
 			if err != nil {
@@ -389,14 +487,15 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 
 	// Make the graph centered by balancing the difference of node numbers on two sides with the length of first edge.
 	var nodeNum int
-	if len(senderRst.Observations) > len(receiverRst.Observations) {
-		nodeNum = len(senderRst.Observations)
+	senderNum, receiverNum := len(getPathObservations(senderRst)), len(getPathObservations(receiverRst))
+	if senderNum > receiverNum {
+		nodeNum = senderNum
 	} else {
-		nodeNum = len(receiverRst.Observations)
+		nodeNum = receiverNum
 	}
 
 	// Draw the nodes for the sender.
-	nodes1, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getSrcNodeName(tf), true, nodeNum-len(senderRst.Observations))
+	nodes1, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getSrcNodeName(tf), true, nodeNum-senderNum, 0)
 	if err != nil {
 		return "", err
 	}
@@ -406,7 +505,7 @@ func GenGraph(tf *opsv1alpha1.Traceflow) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	nodes2, err := genSubGraph(graph, cluster2, receiverRst, &tf.Spec, getDstNodeName(tf), false, nodeNum-len(receiverRst.Observations))
+	nodes2, err := genSubGraph(graph, cluster2, receiverRst, &tf.Spec, getDstNodeName(tf), false, nodeNum-receiverNum, senderRst.Timestamp)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,197 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+func TestGetTraceflowTooltip(t *testing.T) {
+	result := &opsv1alpha1.NodeResult{Node: "node2", Timestamp: 1600000002}
+	o := &opsv1alpha1.Observation{Component: opsv1alpha1.Forwarding, ComponentInfo: "Classification", Action: opsv1alpha1.Received}
+	for _, tc := range []struct {
+		name          string
+		result        *opsv1alpha1.NodeResult
+		prevTimestamp int64
+		expected      string
+	}{
+		{
+			name:     "first observation",
+			result:   result,
+			expected: "Forwarding\nSub-component: Classification\nNode: node2\nNode timestamp: 2020-09-13T12:26:42Z",
+		},
+		{
+			name:          "first observation after another Node",
+			result:        result,
+			prevTimestamp: 1600000000,
+			expected:      "Forwarding\nSub-component: Classification\nNode: node2\nNode timestamp: 2020-09-13T12:26:42Z\nTime since previous Node: 2s",
+		},
+		{
+			name:          "previous Node clock ahead",
+			result:        result,
+			prevTimestamp: 1600000005,
+			expected:      "Forwarding\nSub-component: Classification\nNode: node2\nNode timestamp: 2020-09-13T12:26:42Z\nTime since previous Node: 0s (Node clocks are skewed)",
+		},
+		{
+			name:          "no timestamp",
+			result:        &opsv1alpha1.NodeResult{Node: "node2"},
+			prevTimestamp: 1600000000,
+			expected:      "Forwarding\nSub-component: Classification\nNode: node2",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getTraceflowTooltip(o, tc.result, tc.prevTimestamp))
+		})
+	}
+}
+
+func TestGenGraphTooltips(t *testing.T) {
+	tf := &opsv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+		Spec: opsv1alpha1.TraceflowSpec{
+			Source:      opsv1alpha1.Source{Namespace: "default", Pod: "pod1"},
+			Destination: opsv1alpha1.Destination{Namespace: "default", Pod: "pod2"},
+		},
+		Status: opsv1alpha1.TraceflowStatus{
+			Phase: opsv1alpha1.Succeeded,
+			Results: []opsv1alpha1.NodeResult{
+				{
+					Node:      "node1",
+					Timestamp: 1600000000,
+					Observations: []opsv1alpha1.Observation{
+						{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded},
+						{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Forwarded},
+					},
+				},
+				{
+					Node:      "node2",
+					Timestamp: 1600000001,
+					Observations: []opsv1alpha1.Observation{
+						{Component: opsv1alpha1.Forwarding, ComponentInfo: "Classification", Action: opsv1alpha1.Received},
+						{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Delivered},
+					},
+				},
+			},
+		},
+	}
+	graph, err := GenGraph(tf)
+	require.NoError(t, err)
+	assert.Contains(t, graph, `tooltip="SpoofGuard
+Node: node1
+Node timestamp: 2020-09-13T12:26:40Z"`)
+	// The receiver Node observations are drawn in reverse order, but the time since the sender Node is shown for the
+	// first observation on the path.
+	assert.Contains(t, graph, `tooltip="Forwarding
+Sub-component: Classification
+Node: node2
+Node timestamp: 2020-09-13T12:26:41Z
+Time since previous Node: 1s"`)
+	assert.Contains(t, graph, `tooltip="Forwarding
+Sub-component: Output
+Node: node2
+Node timestamp: 2020-09-13T12:26:41Z"`)
+}
+
+func TestGetDroppedObservation(t *testing.T) {
+	dropped := opsv1alpha1.Observation{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "IngressMetric", Action: opsv1alpha1.Dropped}
+	for _, tc := range []struct {
+		name     string
+		results  []opsv1alpha1.NodeResult
+		expected *opsv1alpha1.Observation
+	}{
+		{
+			name: "not dropped",
+			results: []opsv1alpha1.NodeResult{
+				{Observations: []opsv1alpha1.Observation{{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded}}},
+				{Observations: []opsv1alpha1.Observation{{Component: opsv1alpha1.Forwarding, Action: opsv1alpha1.Delivered}}},
+			},
+		},
+		{
+			name: "dropped by the receiver",
+			results: []opsv1alpha1.NodeResult{
+				{Observations: []opsv1alpha1.Observation{{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded}}},
+				{Observations: []opsv1alpha1.Observation{{Component: opsv1alpha1.Forwarding, Action: opsv1alpha1.Received}, dropped}},
+			},
+			expected: &dropped,
+		},
+		{
+			name:    "no results",
+			results: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &opsv1alpha1.Traceflow{Status: opsv1alpha1.TraceflowStatus{Results: tc.results}}
+			assert.Equal(t, tc.expected, getDroppedObservation(tf))
+		})
+	}
+}
+
+func TestGenGraphDropped(t *testing.T) {
+	tf := &opsv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf1"},
+		Spec: opsv1alpha1.TraceflowSpec{
+			Source:      opsv1alpha1.Source{Namespace: "default", Pod: "pod1"},
+			Destination: opsv1alpha1.Destination{Namespace: "default", Pod: "pod2"},
+		},
+		Status: opsv1alpha1.TraceflowStatus{
+			Phase: opsv1alpha1.Succeeded,
+			Results: []opsv1alpha1.NodeResult{
+				{
+					Node: "node1",
+					Observations: []opsv1alpha1.Observation{
+						{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded},
+						{
+							Component:     opsv1alpha1.NetworkPolicy,
+							ComponentInfo: "EgressDefaultRule",
+							Action:        opsv1alpha1.Dropped,
+							Reason:        "Dropped by default isolation of NetworkPolicy",
+							NetworkPolicy: "K8sNetworkPolicy:default/np1",
+						},
+					},
+				},
+			},
+		},
+	}
+	output, err := GenGraph(tf)
+	require.NoError(t, err)
+	ast, err := gographviz.ParseString(output)
+	require.NoError(t, err)
+	graph := gographviz.NewGraph()
+	require.NoError(t, gographviz.Analyse(ast, graph))
+
+	assert.Equal(t, `"tf1
+Packet dropped by NetworkPolicy: Dropped by default isolation of NetworkPolicy (K8sNetworkPolicy:default/np1)"`, graph.Attrs[gographviz.Label])
+	assert.Equal(t, darkRed, graph.Attrs[gographviz.FontColor])
+
+	// The first observation node follows the endpoint node of the source Pod.
+	droppedNode := graph.Nodes.Lookup[clusterSrcName+"_2"]
+	require.NotNil(t, droppedNode)
+	assert.Equal(t, fireBrick, droppedNode.Attrs[gographviz.Color])
+	assert.Equal(t, mistyRose, droppedNode.Attrs[gographviz.FillColor])
+	edges := graph.Edges.SrcToDsts[clusterSrcName+"_1"][droppedNode.Name]
+	require.Len(t, edges, 1)
+	assert.Equal(t, darkRed, edges[0].Attrs[gographviz.Color])
+	// The edge of a forwarded packet keeps the default color.
+	edges = graph.Edges.SrcToDsts[`"default/pod1"`][clusterSrcName+"_1"]
+	require.Len(t, edges, 1)
+	assert.Equal(t, silver, edges[0].Attrs[gographviz.Color])
+}
@@ -14,6 +14,8 @@
 
 package k8s
 
+import "strings"
+
 // NamespacedName generates the conventional K8s resource name,
 // which connects namespace and name with "/".
 func NamespacedName(namespace, name string) string {
@@ -23,3 +25,12 @@ func NamespacedName(namespace, name string) string {
 	}
 	return namespace + "/" + name
 }
+
+// SplitNamespacedName splits a name generated by NamespacedName into its
+// namespace and name.
+func SplitNamespacedName(namespacedName string) (namespace, name string) {
+	if i := strings.Index(namespacedName, "/"); i >= 0 {
+		return namespacedName[:i], namespacedName[i+1:]
+	}
+	return "", namespacedName
+}
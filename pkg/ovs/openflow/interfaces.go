@@ -24,6 +24,7 @@ import (
 type Protocol string
 type TableIDType uint8
 type GroupIDType uint32
+type MeterIDType uint32
 
 type MissActionType uint32
 type Range [2]uint32
@@ -46,6 +47,7 @@ const (
 	ProtocolSCTPv6 Protocol = "sctpv6"
 	ProtocolICMP   Protocol = "icmp"
 	ProtocolICMPv6 Protocol = "icmpv6"
+	ProtocolIGMP   Protocol = "igmp"
 )
 
 const (
@@ -69,6 +71,8 @@ const (
 	NxmFieldTunMetadata = "NXM_NX_TUN_METADATA"
 	NxmFieldIPToS       = "NXM_OF_IP_TOS"
 	NxmFieldXXReg       = "NXM_NX_XXREG"
+	NxmFieldPktMark     = "NXM_NX_PKT_MARK"
+	NxmFieldTunIPv4Dst  = "NXM_NX_TUN_IPV4_DST"
 )
 
 const (
@@ -81,12 +85,29 @@ const (
 type Bridge interface {
 	CreateTable(id, next TableIDType, missAction MissActionType) Table
 	DeleteTable(id TableIDType) bool
+	// CreateGroup creates a Group of type "select" with the provided ID, in which a single bucket is selected for each
+	// packet, e.g. to select an Endpoint or a path. The Group must be installed with Add.
 	CreateGroup(id GroupIDType) Group
+	// CreateGroupTypeAll creates a Group of type "all" with the provided ID, in which all the buckets are executed for
+	// each packet, e.g. to replicate packets to multiple destinations. The Group must be installed with Add. If the ID
+	// is already used by a Group of another type, installing the returned Group fails.
+	CreateGroupTypeAll(id GroupIDType) Group
 	DeleteGroup(id GroupIDType) bool
+	// CreateMeter creates a Meter with the provided ID and flags. The Meter must be installed with Add.
+	CreateMeter(id MeterIDType, flags ofctrl.MeterFlag) Meter
+	// DeleteMeter removes the Meter with the provided ID from the OFSwitch. The flows using the Meter are removed
+	// together by OVS.
+	DeleteMeter(id MeterIDType) bool
 	DumpTableStatus() []TableStatus
 	// DumpFlows queries the Openflow entries from OFSwitch. The filter of the query is Openflow cookieID; the result is
 	// a map from flow cookieID to FlowStates.
 	DumpFlows(cookieID, cookieMask uint64) (map[uint64]*FlowStates, error)
+	// DumpTableStats queries the Openflow entries matching the cookieID from OFSwitch, and aggregates their
+	// statistics per table. The result is a map from table ID to FlowStatsSummary.
+	DumpTableStats(cookieID, cookieMask uint64) (map[TableIDType]*FlowStatsSummary, error)
+	// DumpCookieStats queries the Openflow entries matching the cookieID from OFSwitch, and aggregates their
+	// statistics per cookie. The result is a map from flow cookieID to FlowStatsSummary.
+	DumpCookieStats(cookieID, cookieMask uint64) (map[uint64]*FlowStatsSummary, error)
 	// DeleteFlowsByCookie removes Openflow entries from OFSwitch. The removed Openflow entries use the specific CookieID.
 	DeleteFlowsByCookie(cookieID, cookieMask uint64) error
 	// AddFlowsInBundle syncs multiple Openflow entries in a single transaction. This operation could add new flows in
@@ -124,6 +145,13 @@ type TableStatus struct {
 	UpdateTime time.Time `json:"updateTime"`
 }
 
+// FlowStatsSummary aggregates the statistics of a set of Openflow entries, e.g. all the entries of a table.
+type FlowStatsSummary struct {
+	FlowCount   uint64
+	PacketCount uint64
+	ByteCount   uint64
+}
+
 type Table interface {
 	GetID() TableIDType
 	BuildFlow(priority uint16) FlowBuilder
@@ -137,6 +165,7 @@ type EntryType string
 const (
 	FlowEntry  EntryType = "FlowEntry"
 	GroupEntry EntryType = "GroupEntry"
+	MeterEntry EntryType = "MeterEntry"
 )
 
 type OFEntry interface {
@@ -171,6 +200,7 @@ type Action interface {
 	LoadARPOperation(value uint16) FlowBuilder
 	LoadRegRange(regID int, value uint32, to Range) FlowBuilder
 	LoadRange(name string, addr uint64, to Range) FlowBuilder
+	LoadPktMarkRange(value uint32, to Range) FlowBuilder
 	Move(from, to string) FlowBuilder
 	MoveRange(fromName, toName string, from, to Range) FlowBuilder
 	Resubmit(port uint16, table TableIDType) FlowBuilder
@@ -190,10 +220,13 @@ type Action interface {
 	SetSrcIP(addr net.IP) FlowBuilder
 	SetDstIP(addr net.IP) FlowBuilder
 	SetTunnelDst(addr net.IP) FlowBuilder
+	SetIPDscp(dscp uint8) FlowBuilder
 	DecTTL() FlowBuilder
 	Normal() FlowBuilder
 	Conjunction(conjID uint32, clauseID uint8, nClause uint8) FlowBuilder
 	Group(id GroupIDType) FlowBuilder
+	Meter(id MeterIDType) FlowBuilder
+	SetQueue(queueID uint32) FlowBuilder
 	Learn(id TableIDType, priority uint16, idleTimeout, hardTimeout uint16, cookieID uint64) LearnAction
 	GotoTable(table TableIDType) FlowBuilder
 	SendToController(reason uint8) FlowBuilder
@@ -234,6 +267,7 @@ type FlowBuilder interface {
 	MatchICMPv6Type(icmp6Type byte) FlowBuilder
 	MatchICMPv6Code(icmp6Code byte) FlowBuilder
 	MatchTunMetadata(index int, data uint32) FlowBuilder
+	MatchTunnelDst(dstIP net.IP) FlowBuilder
 	// MatchCTSrcIP matches the source IPv4 address of the connection tracker original direction tuple.
 	MatchCTSrcIP(ip net.IP) FlowBuilder
 	// MatchCTSrcIPNet matches the source IPv4 address of the connection tracker original direction tuple with IP masking.
@@ -289,9 +323,28 @@ type BucketBuilder interface {
 	LoadXXReg(regID int, data []byte) BucketBuilder
 	LoadRegRange(regID int, data uint32, rng Range) BucketBuilder
 	ResubmitToTable(tableID TableIDType) BucketBuilder
+	// OutputPort outputs the packet to the provided port, e.g. in the buckets of a Group of type "all" replicating
+	// the packet to several ports.
+	OutputPort(port uint32) BucketBuilder
 	Done() Group
 }
 
+type Meter interface {
+	OFEntry
+	ResetMeterBands() Meter
+	MeterBand() MeterBandBuilder
+}
+
+type MeterBandBuilder interface {
+	MeterType(meterType ofctrl.MeterType) MeterBandBuilder
+	// Rate sets the rate of the band, in kb/s or in packets/s depending on the flags of the Meter.
+	Rate(rate uint32) MeterBandBuilder
+	// Burst sets the burst size of the band, in kb or in packets depending on the flags of the Meter. It is only
+	// used if the Meter has the ofctrl.MeterBurst flag.
+	Burst(burst uint32) MeterBandBuilder
+	Done() Meter
+}
+
 type CTAction interface {
 	LoadToMark(value uint32) CTAction
 	LoadToLabelRange(value uint64, rng *Range) CTAction
@@ -321,15 +374,19 @@ type PacketOutBuilder interface {
 	SetTCPSrcPort(port uint16) PacketOutBuilder
 	SetTCPDstPort(port uint16) PacketOutBuilder
 	SetTCPFlags(flags uint8) PacketOutBuilder
+	SetTCPSeqNum(seqNum uint32) PacketOutBuilder
+	SetTCPAckNum(ackNum uint32) PacketOutBuilder
 	SetUDPSrcPort(port uint16) PacketOutBuilder
 	SetUDPDstPort(port uint16) PacketOutBuilder
 	SetICMPType(icmpType uint8) PacketOutBuilder
 	SetICMPCode(icmpCode uint8) PacketOutBuilder
 	SetICMPID(id uint16) PacketOutBuilder
 	SetICMPSequence(seq uint16) PacketOutBuilder
+	SetICMPData(data []byte) PacketOutBuilder
 	SetInport(inPort uint32) PacketOutBuilder
 	SetOutport(outport uint32) PacketOutBuilder
 	AddLoadAction(name string, data uint64, rng Range) PacketOutBuilder
+	AddResubmitAction(inPort *uint16, table *uint8) PacketOutBuilder
 	Done() *ofctrl.PacketOut
 }
 
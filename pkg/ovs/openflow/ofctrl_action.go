@@ -214,6 +214,12 @@ func (a *ofFlowAction) SetTunnelDst(addr net.IP) FlowBuilder {
 	return a.builder
 }
 
+// SetIPDscp is an action to modify the DSCP field of the IP header, i.e. the 6 most significant bits of the IPv4 ToS or
+// of the IPv6 Traffic Class, which OVS presents as "nw_tos". The flow must match IPv4 or IPv6 packets.
+func (a *ofFlowAction) SetIPDscp(dscp uint8) FlowBuilder {
+	return a.LoadRange(NxmFieldIPToS, uint64(dscp), Range{2, 7})
+}
+
 // LoadARPOperation is an action to Load data to NXM_OF_ARP_OP field.
 func (a *ofFlowAction) LoadARPOperation(value uint16) FlowBuilder {
 	loadAct, _ := ofctrl.NewNXLoadAction(NxmFieldARPOp, uint64(value), openflow13.NewNXRange(0, 15))
@@ -231,6 +237,11 @@ func (a *ofFlowAction) LoadRange(name string, value uint64, rng Range) FlowBuild
 	return a.builder
 }
 
+// LoadPktMarkRange is an action to load data into pkt_mark at specified range.
+func (a *ofFlowAction) LoadPktMarkRange(value uint32, rng Range) FlowBuilder {
+	return a.LoadRange(NxmFieldPktMark, uint64(value), rng)
+}
+
 // LoadRegRange is an action to Load data to the target register at specified range.
 func (a *ofFlowAction) LoadRegRange(regID int, value uint32, rng Range) FlowBuilder {
 	name := fmt.Sprintf("%s%d", NxmFieldReg, regID)
@@ -301,6 +312,33 @@ func (a *ofFlowAction) Group(id GroupIDType) FlowBuilder {
 	return a.builder
 }
 
+// Meter is an action to meter packets with the specified Meter, which must be installed before the flow.
+func (a *ofFlowAction) Meter(id MeterIDType) FlowBuilder {
+	meterAction := ofctrl.NewMeterAction(uint32(id))
+	a.builder.ApplyAction(meterAction)
+	return a.builder
+}
+
+// SetQueue is an action to send packets to the specified queue of the QoS of the port to which they are output.
+func (a *ofFlowAction) SetQueue(queueID uint32) FlowBuilder {
+	a.builder.ApplyAction(&setQueueAction{queueID: queueID})
+	return a.builder
+}
+
+// setQueueAction implements ofctrl.OFAction for the set_queue action of OpenFlow 1.3, which is not provided by
+// ofctrl.
+type setQueueAction struct {
+	queueID uint32
+}
+
+func (a *setQueueAction) GetActionMessage() openflow13.Action {
+	return openflow13.NewActionSetQueue(a.queueID)
+}
+
+func (a *setQueueAction) GetActionType() string {
+	return "setQueue"
+}
+
 // Note annotates the OpenFlow entry. The notes are presented as hex digits in the OpenFlow entry, and it will be
 // padded on the right to make the total number of bytes 6 more than a multiple of 8.
 func (a *ofFlowAction) Note(notes string) FlowBuilder {
@@ -148,12 +148,19 @@ type OFBridge struct {
 }
 
 func (b *OFBridge) CreateGroup(id GroupIDType) Group {
-	ofctrlGroup, err := b.ofSwitch.NewGroup(uint32(id), ofctrl.GroupSelect)
+	return b.createGroupWithType(id, ofctrl.GroupSelect)
+}
+
+func (b *OFBridge) CreateGroupTypeAll(id GroupIDType) Group {
+	return b.createGroupWithType(id, ofctrl.GroupAll)
+}
+
+func (b *OFBridge) createGroupWithType(id GroupIDType, groupType ofctrl.GroupType) Group {
+	ofctrlGroup, err := b.ofSwitch.NewGroup(uint32(id), groupType)
 	if err != nil {
 		ofctrlGroup = b.ofSwitch.GetGroup(uint32(id))
 	}
-	g := &ofGroup{bridge: b, ofctrl: ofctrlGroup}
-	return g
+	return newOFGroup(b, ofctrlGroup, groupType)
 }
 
 func (b *OFBridge) DeleteGroup(id GroupIDType) bool {
@@ -167,6 +174,26 @@ func (b *OFBridge) DeleteGroup(id GroupIDType) bool {
 	return true
 }
 
+func (b *OFBridge) CreateMeter(id MeterIDType, flags ofctrl.MeterFlag) Meter {
+	ofctrlMeter, err := b.ofSwitch.NewMeter(uint32(id), flags)
+	if err != nil {
+		ofctrlMeter = b.ofSwitch.GetMeter(uint32(id))
+	}
+	m := &ofMeter{bridge: b, ofctrl: ofctrlMeter}
+	return m
+}
+
+func (b *OFBridge) DeleteMeter(id MeterIDType) bool {
+	m := b.ofSwitch.GetMeter(uint32(id))
+	if m == nil {
+		return true
+	}
+	if err := m.Delete(); err != nil {
+		return false
+	}
+	return true
+}
+
 func (b *OFBridge) CreateTable(id, next TableIDType, missAction MissActionType) Table {
 	t := newOFTable(id, next, missAction)
 
@@ -222,6 +249,7 @@ func (b *OFBridge) SwitchConnected(sw *ofctrl.OFSwitch) {
 	b.ofSwitch = sw
 	b.ofSwitch.EnableMonitor()
 	b.initialize()
+	metrics.OVSConnectionStatus.Set(1)
 	go func() {
 		// b.connected is nil if it is an automatic reconnection but not triggered by OFSwitch.Connect.
 		if b.connected != nil {
@@ -238,6 +266,7 @@ func (b *OFBridge) MultipartReply(sw *ofctrl.OFSwitch, rep *openflow13.Multipart
 
 func (b *OFBridge) SwitchDisconnected(sw *ofctrl.OFSwitch) {
 	klog.Infof("OFSwitch is disconnected: %v", sw.DPID())
+	metrics.OVSConnectionStatus.Set(0)
 }
 
 // initialize creates ofctrl.Table for each table in the tableCache.
@@ -317,6 +346,47 @@ func (b *OFBridge) DumpFlows(cookieID, cookieMask uint64) (map[uint64]*FlowState
 	return flowStats, nil
 }
 
+// DumpTableStats queries the Openflow entries matching the cookieID from OFSwitch, and aggregates their statistics
+// per table. The result is a map from table ID to FlowStatsSummary.
+func (b *OFBridge) DumpTableStats(cookieID, cookieMask uint64) (map[TableIDType]*FlowStatsSummary, error) {
+	ofStats, err := b.ofSwitch.DumpFlowStats(cookieID, &cookieMask, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tableStats := make(map[TableIDType]*FlowStatsSummary)
+	for _, stat := range ofStats {
+		tableID := TableIDType(stat.TableId)
+		if _, ok := tableStats[tableID]; !ok {
+			tableStats[tableID] = &FlowStatsSummary{}
+		}
+		tableStats[tableID].add(stat.PacketCount, stat.ByteCount)
+	}
+	return tableStats, nil
+}
+
+// DumpCookieStats queries the Openflow entries matching the cookieID from OFSwitch, and aggregates their statistics
+// per cookie. The result is a map from flow cookieID to FlowStatsSummary.
+func (b *OFBridge) DumpCookieStats(cookieID, cookieMask uint64) (map[uint64]*FlowStatsSummary, error) {
+	ofStats, err := b.ofSwitch.DumpFlowStats(cookieID, &cookieMask, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	cookieStats := make(map[uint64]*FlowStatsSummary)
+	for _, stat := range ofStats {
+		if _, ok := cookieStats[stat.Cookie]; !ok {
+			cookieStats[stat.Cookie] = &FlowStatsSummary{}
+		}
+		cookieStats[stat.Cookie].add(stat.PacketCount, stat.ByteCount)
+	}
+	return cookieStats, nil
+}
+
+func (s *FlowStatsSummary) add(packetCount, byteCount uint64) {
+	s.FlowCount++
+	s.PacketCount += packetCount
+	s.ByteCount += byteCount
+}
+
 // DeleteFlowsByCookie removes Openflow entries from OFSwitch. The removed Openflow entries use the specific CookieID.
 func (b *OFBridge) DeleteFlowsByCookie(cookieID, cookieMask uint64) error {
 	flowMod := openflow13.NewFlowMod()
@@ -355,14 +425,12 @@ func (b *OFBridge) AddFlowsInBundle(addflows []Flow, modFlows []Flow, delFlows [
 			// returned by function "Complete".
 			flowMod, err := ofFlow.Flow.GetBundleMessage(operation)
 			if err != nil {
+				abortBundle(tx)
 				return err
 			}
 			if err := tx.AddMessage(flowMod); err != nil {
 				// Close the bundle and cancel it if there is error when adding the FlowMod message.
-				_, err := tx.Complete()
-				if err == nil {
-					tx.Abort()
-				}
+				abortBundle(tx)
 				return err
 			}
 		}
@@ -420,7 +488,7 @@ func (b *OFBridge) AddOFEntriesInBundle(addEntries []OFEntry, modEntries []OFEnt
 		entry     OFEntry
 		operation OFOperation
 	}
-	var flowSet, groupSet []entryOperation
+	var flowSet, groupSet, meterSet []entryOperation
 	// Classify the entries according to the EntryType, and set a correct operation type.
 	checkMessages := func(entries []OFEntry, operation OFOperation) {
 		for _, entry := range entries {
@@ -437,6 +505,12 @@ func (b *OFBridge) AddOFEntriesInBundle(addEntries []OFEntry, modEntries []OFEnt
 					entry:     group,
 					operation: operation,
 				})
+			case MeterEntry:
+				meter := entry.(*ofMeter)
+				meterSet = append(meterSet, entryOperation{
+					entry:     meter,
+					operation: operation,
+				})
 			}
 		}
 	}
@@ -460,6 +534,7 @@ func (b *OFBridge) AddOFEntriesInBundle(addEntries []OFEntry, modEntries []OFEnt
 		for _, e := range entrySet {
 			msg, err := e.entry.GetBundleMessage(e.operation)
 			if err != nil {
+				abortBundle(tx)
 				return err
 			}
 			// "AddMessage" operation is async, the function only returns error which occur when constructing and sending
@@ -468,24 +543,22 @@ func (b *OFBridge) AddOFEntriesInBundle(addEntries []OFEntry, modEntries []OFEnt
 			// returned by function "Complete".
 			if err := tx.AddMessage(msg); err != nil {
 				// Close the bundle and cancel it if there is error when adding the FlowMod message.
-				_, err := tx.Complete()
-				if err == nil {
-					tx.Abort()
-				}
+				abortBundle(tx)
 				return err
 			}
 		}
 		return nil
 	}
 
-	// Add Group modification messages in advance of Flow modification messages, so it can ensure the dependent Group
-	// exists when adding a new Flow entry. When OVS is deleting the Group, the corresponding Flow entry is removed
-	// together. It doesn't return an error when OVS is deleting a non-existing Flow entry.
+	// Add Group and Meter modification messages in advance of Flow modification messages, so it can ensure the
+	// dependent Group or Meter exists when adding a new Flow entry. When OVS is deleting the Group or the Meter, the
+	// corresponding Flow entry is removed together. It doesn't return an error when OVS is deleting a non-existing Flow
+	// entry.
 	for _, entries := range [][]entryOperation{
-		groupSet, flowSet,
+		groupSet, meterSet, flowSet,
 	} {
 		if err := addMessage(entries); err != nil {
-			return nil
+			return err
 		}
 	}
 
@@ -519,6 +592,17 @@ func (b *OFBridge) AddOFEntriesInBundle(addEntries []OFEntry, modEntries []OFEnt
 	return nil
 }
 
+// abortBundle closes the bundle opened by the transaction and cancels it, so that none of the messages already added
+// to the bundle is realized. It is used when an error occurs before the bundle is committed: the caller must return
+// the original error, as the messages of the bundle are applied all together or not at all.
+func abortBundle(tx *ofctrl.Transaction) {
+	if _, err := tx.Complete(); err != nil {
+		klog.Errorf("Failed to close the bundle before cancelling it: %v", err)
+		return
+	}
+	tx.Abort()
+}
+
 func (b *OFBridge) SubscribePacketIn(reason uint8, ch chan *ofctrl.PacketIn) error {
 	_, exist := b.pktConsumers.Load(reason)
 	if exist {
@@ -24,6 +24,18 @@ import (
 type ofGroup struct {
 	ofctrl *ofctrl.Group
 	bridge *OFBridge
+	// err is set if the Group cannot be installed, e.g. because its ID is already used by a Group of another type. It
+	// is returned when the Group is added or modified.
+	err error
+}
+
+// newOFGroup returns the ofGroup of the ofctrl Group created or retrieved for the provided type.
+func newOFGroup(bridge *OFBridge, ofctrlGroup *ofctrl.Group, groupType ofctrl.GroupType) *ofGroup {
+	g := &ofGroup{bridge: bridge, ofctrl: ofctrlGroup}
+	if ofctrlGroup.GroupType != groupType {
+		g.err = fmt.Errorf("group %d already exists with type %d, cannot use it with type %d", ofctrlGroup.ID, ofctrlGroup.GroupType, groupType)
+	}
+	return g
 }
 
 func (g *ofGroup) Reset() {
@@ -31,10 +43,16 @@ func (g *ofGroup) Reset() {
 }
 
 func (g *ofGroup) Add() error {
+	if g.err != nil {
+		return g.err
+	}
 	return g.ofctrl.Install()
 }
 
 func (g *ofGroup) Modify() error {
+	if g.err != nil {
+		return g.err
+	}
 	return g.ofctrl.Install()
 }
 
@@ -67,6 +85,9 @@ func (f *ofGroup) GetBundleMessage(entryOper OFOperation) (ofctrl.OpenFlowModMes
 	case DeleteMessage:
 		operation = openflow13.OFPGC_DELETE
 	}
+	if f.err != nil && entryOper != DeleteMessage {
+		return nil, f.err
+	}
 	message := f.ofctrl.GetBundleMessage(operation)
 	return message, nil
 }
@@ -107,6 +128,12 @@ func (b *bucketBuilder) ResubmitToTable(tableID TableIDType) BucketBuilder {
 	return b
 }
 
+// OutputPort is an action to output the packet to the specified port when the bucket is executed.
+func (b *bucketBuilder) OutputPort(port uint32) BucketBuilder {
+	b.bucket.AddAction(openflow13.NewActionOutput(port))
+	return b
+}
+
 // Weight sets the weight of a bucket.
 func (b *bucketBuilder) Weight(val uint16) BucketBuilder {
 	b.bucket.Weight = val
@@ -0,0 +1,51 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTypeAllBuckets(t *testing.T) {
+	g := newOFGroup(nil, &ofctrl.Group{ID: 10, GroupType: ofctrl.GroupAll}, ofctrl.GroupAll)
+	require.NoError(t, g.err)
+
+	g.ResetBuckets().
+		Bucket().OutputPort(3).Done().
+		Bucket().LoadReg(0, 1).OutputPort(4).Done()
+	require.Len(t, g.ofctrl.Buckets, 2)
+	for i, port := range []uint32{3, 4} {
+		actions := g.ofctrl.Buckets[i].Actions
+		output, ok := actions[len(actions)-1].(*openflow13.ActionOutput)
+		require.True(t, ok, "The last action of bucket %d must be an output", i)
+		assert.Equal(t, port, output.Port)
+	}
+}
+
+func TestGroupTypeMismatch(t *testing.T) {
+	// The ID is already used by a Group of type select.
+	g := newOFGroup(nil, &ofctrl.Group{ID: 10, GroupType: ofctrl.GroupSelect}, ofctrl.GroupAll)
+	assert.Error(t, g.Add())
+	assert.Error(t, g.Modify())
+	_, err := g.GetBundleMessage(AddMessage)
+	assert.Error(t, err)
+	_, err = g.GetBundleMessage(ModifyMessage)
+	assert.Error(t, err)
+}
@@ -25,9 +25,12 @@ import (
 )
 
 type ofPacketOutBuilder struct {
-	pktOut  *ofctrl.PacketOut
-	icmpID  *uint16
-	icmpSeq *uint16
+	pktOut    *ofctrl.PacketOut
+	icmpID    *uint16
+	icmpSeq   *uint16
+	icmpData  []byte
+	tcpSeqNum *uint32
+	tcpAckNum *uint32
 }
 
 // SetSrcMAC sets the packet's source MAC with the provided value.
@@ -103,6 +106,9 @@ func (b *ofPacketOutBuilder) SetIPProtocol(proto Protocol) PacketOutBuilder {
 		b.pktOut.IPHeader.Protocol = 0x84
 	case ProtocolICMP:
 		b.pktOut.IPHeader.Protocol = protocol.Type_ICMP
+	case ProtocolIGMP:
+		// The IGMP message is set with the ICMP setters, as IGMPv2 messages have the same layout as ICMP messages.
+		b.pktOut.IPHeader.Protocol = 0x02
 	default:
 		b.pktOut.IPHeader.Protocol = 0xff
 	}
@@ -160,6 +166,26 @@ func (b *ofPacketOutBuilder) SetTCPFlags(flags uint8) PacketOutBuilder {
 	return b
 }
 
+// SetTCPSeqNum sets the sequence number in the packet's TCP header. A random
+// number is used if it is not set.
+func (b *ofPacketOutBuilder) SetTCPSeqNum(seqNum uint32) PacketOutBuilder {
+	if b.pktOut.TCPHeader == nil {
+		b.pktOut.TCPHeader = new(protocol.TCP)
+	}
+	b.tcpSeqNum = &seqNum
+	return b
+}
+
+// SetTCPAckNum sets the acknowledgment number in the packet's TCP header. A
+// random number is used if it is not set.
+func (b *ofPacketOutBuilder) SetTCPAckNum(ackNum uint32) PacketOutBuilder {
+	if b.pktOut.TCPHeader == nil {
+		b.pktOut.TCPHeader = new(protocol.TCP)
+	}
+	b.tcpAckNum = &ackNum
+	return b
+}
+
 // SetUDPSrcPort sets the source port in the packet's UDP header.
 func (b *ofPacketOutBuilder) SetUDPSrcPort(port uint16) PacketOutBuilder {
 	if b.pktOut.UDPHeader == nil {
@@ -214,6 +240,17 @@ func (b *ofPacketOutBuilder) SetICMPSequence(seq uint16) PacketOutBuilder {
 	return b
 }
 
+// SetICMPData sets the data following the type, code and checksum fields in
+// the packet's ICMP header. It takes precedence over the identifier and the
+// sequence number, which are only meaningful for echo messages.
+func (b *ofPacketOutBuilder) SetICMPData(data []byte) PacketOutBuilder {
+	if b.pktOut.ICMPHeader == nil {
+		b.pktOut.ICMPHeader = new(protocol.ICMP)
+	}
+	b.icmpData = data
+	return b
+}
+
 // SetInport sets the in_port field of the packetOut message.
 func (b *ofPacketOutBuilder) SetInport(inPort uint32) PacketOutBuilder {
 	b.pktOut.InPort = inPort
@@ -234,6 +271,14 @@ func (b *ofPacketOutBuilder) AddLoadAction(name string, data uint64, rng Range)
 	return b
 }
 
+// AddResubmitAction resubmits the packet to the specified table when it is received by OVS Switch, so that it goes
+// through the pipeline from that table. If inPort is nil, the in_port field is not changed.
+func (b *ofPacketOutBuilder) AddResubmitAction(inPort *uint16, table *uint8) PacketOutBuilder {
+	act := ofctrl.NewResubmit(inPort, table)
+	b.pktOut.Actions = append(b.pktOut.Actions, act)
+	return b
+}
+
 func (b *ofPacketOutBuilder) Done() *ofctrl.PacketOut {
 	if b.pktOut.IPHeader != nil && b.pktOut.IPv6Header != nil {
 		klog.Errorf("Invalid PacketOutBuilder: IP header and IPv6 header are not allowed to exist at the same time")
@@ -246,10 +291,7 @@ func (b *ofPacketOutBuilder) Done() *ofctrl.PacketOut {
 			b.pktOut.IPHeader.Length = 20 + b.pktOut.ICMPHeader.Len()
 		} else if b.pktOut.TCPHeader != nil {
 			b.pktOut.TCPHeader.HdrLen = 5
-			// #nosec G404: random number generator not used for security purposes
-			b.pktOut.TCPHeader.SeqNum = rand.Uint32()
-			// #nosec G404: random number generator not used for security purposes
-			b.pktOut.TCPHeader.AckNum = rand.Uint32()
+			b.setTCPNums()
 			b.pktOut.TCPHeader.Checksum = b.tcpHeaderChecksum()
 			b.pktOut.IPHeader.Length = 20 + b.pktOut.TCPHeader.Len()
 		} else if b.pktOut.UDPHeader != nil {
@@ -269,10 +311,7 @@ func (b *ofPacketOutBuilder) Done() *ofctrl.PacketOut {
 			b.pktOut.IPv6Header.Length = b.pktOut.ICMPHeader.Len()
 		} else if b.pktOut.TCPHeader != nil {
 			b.pktOut.TCPHeader.HdrLen = 5
-			// #nosec G404: random number generator not used for security purposes
-			b.pktOut.TCPHeader.SeqNum = rand.Uint32()
-			// #nosec G404: random number generator not used for security purposes
-			b.pktOut.TCPHeader.AckNum = rand.Uint32()
+			b.setTCPNums()
 			b.pktOut.TCPHeader.Checksum = b.tcpHeaderChecksum()
 			b.pktOut.IPv6Header.Length = b.pktOut.TCPHeader.Len()
 		} else if b.pktOut.UDPHeader != nil {
@@ -286,7 +325,26 @@ func (b *ofPacketOutBuilder) Done() *ofctrl.PacketOut {
 	return b.pktOut
 }
 
+func (b *ofPacketOutBuilder) setTCPNums() {
+	if b.tcpSeqNum != nil {
+		b.pktOut.TCPHeader.SeqNum = *b.tcpSeqNum
+	} else {
+		// #nosec G404: random number generator not used for security purposes
+		b.pktOut.TCPHeader.SeqNum = rand.Uint32()
+	}
+	if b.tcpAckNum != nil {
+		b.pktOut.TCPHeader.AckNum = *b.tcpAckNum
+	} else {
+		// #nosec G404: random number generator not used for security purposes
+		b.pktOut.TCPHeader.AckNum = rand.Uint32()
+	}
+}
+
 func (b *ofPacketOutBuilder) setICMPData() {
+	if b.icmpData != nil {
+		b.pktOut.ICMPHeader.Data = b.icmpData
+		return
+	}
 	data := make([]byte, 4)
 	if b.icmpID != nil {
 		binary.BigEndian.PutUint16(data, *b.icmpID)
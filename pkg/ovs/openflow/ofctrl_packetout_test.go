@@ -380,6 +380,24 @@ func Test_ofPacketOutBuilder_SetIPProtocol(t *testing.T) {
 				icmpSeq: nil,
 			},
 		},
+		{
+			name: "ProtocolIGMP",
+			fields: fields{
+				pktOut:  &ofctrl.PacketOut{},
+				icmpID:  nil,
+				icmpSeq: nil,
+			},
+			args: args{proto: ProtocolIGMP},
+			want: &ofPacketOutBuilder{
+				pktOut: &ofctrl.PacketOut{
+					IPHeader: &protocol.IPv4{
+						Protocol: 0x02,
+					},
+				},
+				icmpID:  nil,
+				icmpSeq: nil,
+			},
+		},
 		{
 			name: "ProtocolUnknown",
 			fields: fields{
@@ -587,6 +605,15 @@ func Test_ofPacketOutBuilder_SetIPFlags(t *testing.T) {
 	}
 }
 
+func Test_ofPacketOutBuilder_AddResubmitAction(t *testing.T) {
+	table := uint8(30)
+	b := &ofPacketOutBuilder{pktOut: new(ofctrl.PacketOut)}
+	b.AddResubmitAction(nil, &table)
+	if len(b.pktOut.Actions) != 1 || !reflect.DeepEqual(b.pktOut.Actions[0], ofctrl.NewResubmit(nil, &table)) {
+		t.Errorf("AddResubmitAction() actions = %v, want a resubmit to table %d", b.pktOut.Actions, table)
+	}
+}
+
 func Test_ofPacketOutBuilder_Done(t *testing.T) {
 	type fields struct {
 		pktOut  *ofctrl.PacketOut
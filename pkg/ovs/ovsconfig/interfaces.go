@@ -28,6 +28,13 @@ const (
 	OVSDatapathNetdev OVSDatapathType = "netdev"
 )
 
+// QueueConfig is the configuration of a queue of the QoS of an OVS port, with rates in bits per second. A rate of 0
+// means that it is not set.
+type QueueConfig struct {
+	MinRate uint64
+	MaxRate uint64
+}
+
 type OVSBridgeClient interface {
 	Create() Error
 	Delete() Error
@@ -41,12 +48,15 @@ type OVSBridgeClient interface {
 	CreateTunnelPort(name string, tunnelType TunnelType, ofPortRequest int32) (string, Error)
 	CreateTunnelPortExt(name string, tunnelType TunnelType, ofPortRequest int32, csum bool, localIP string, remoteIP string, psk string, externalIDs map[string]interface{}) (string, Error)
 	CreateUplinkPort(name string, ofPortRequest int32, externalIDs map[string]interface{}) (string, Error)
+	CreateVhostUserClientPort(name, socketPath string, externalIDs map[string]interface{}) (string, Error)
 	DeletePort(portUUID string) Error
 	DeletePorts(portUUIDList []string) Error
 	GetOFPort(ifName string) (int32, Error)
 	GetPortData(portUUID, ifName string) (*OVSPortData, Error)
 	GetPortList() ([]OVSPortData, Error)
 	SetInterfaceMTU(name string, MTU int) error
+	SetPortQoS(portName string, maxRate uint64, queues map[uint32]QueueConfig) Error
+	DeletePortQoS(portName string) Error
 	GetOVSVersion() (string, Error)
 	AddOVSOtherConfig(configs map[string]interface{}) Error
 	GetOVSOtherConfig() (map[string]string, Error)
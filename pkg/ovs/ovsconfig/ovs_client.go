@@ -511,6 +511,17 @@ func (br *OVSBridge) CreateUplinkPort(name string, ofPortRequest int32, external
 	return br.createPort(name, name, "", ofPortRequest, externalIDs, nil)
 }
 
+// CreateVhostUserClientPort creates a port with the specified name on the
+// bridge, and connects a dpdkvhostuserclient interface to it. OVS is the
+// vhost-user client and connects to the server socket at socketPath, which is
+// created by the DPDK application. It requires the netdev datapath with DPDK.
+// If externalIDs is not empty, the map key/value pairs will be set to the
+// port's external_ids.
+func (br *OVSBridge) CreateVhostUserClientPort(name, socketPath string, externalIDs map[string]interface{}) (string, Error) {
+	options := map[string]interface{}{"vhost-server-path": socketPath}
+	return br.createPort(name, name, "dpdkvhostuserclient", 0, externalIDs, options)
+}
+
 // CreatePort creates a port with the specified name on the bridge, and connects
 // the interface specified by ifDev to the port.
 // If externalIDs is not empty, the map key/value pairs will be set to the
@@ -769,6 +780,150 @@ func (br *OVSBridge) SetInterfaceMTU(name string, MTU int) error {
 	return nil
 }
 
+// SetPortQoS sets a linux-htb QoS with the provided queues to the port, replacing the QoS previously set to it.
+// maxRate is the maximum rate shared by all the queues, in bits per second, and queues maps the queue IDs to their
+// configurations. The traffic which is not assigned to a queue by the OpenFlow pipeline is sent to queue 0.
+func (br *OVSBridge) SetPortQoS(portName string, maxRate uint64, queues map[uint32]QueueConfig) Error {
+	qosUUID, queueUUIDs, err := br.getPortQoS(portName)
+	if err != nil {
+		return err
+	}
+
+	tx := br.ovsdb.Transaction(openvSwitchSchema)
+	queueMap := make([]interface{}, 0, len(queues))
+	for queueID, queue := range queues {
+		otherConfig := map[string]interface{}{}
+		if queue.MinRate > 0 {
+			otherConfig["min-rate"] = strconv.FormatUint(queue.MinRate, 10)
+		}
+		if queue.MaxRate > 0 {
+			otherConfig["max-rate"] = strconv.FormatUint(queue.MaxRate, 10)
+		}
+		queueNamedUUID := tx.Insert(dbtransaction.Insert{
+			Table: "Queue",
+			Row:   Queue{OtherConfig: helpers.MakeOVSDBMap(otherConfig)},
+		})
+		queueMap = append(queueMap, []interface{}{queueID, []interface{}{"named-uuid", queueNamedUUID}})
+	}
+	qos := QoS{
+		Type: "linux-htb",
+		OtherConfig: helpers.MakeOVSDBMap(map[string]interface{}{
+			"max-rate": strconv.FormatUint(maxRate, 10),
+		}),
+		Queues: []interface{}{"map", queueMap},
+	}
+	qosNamedUUID := tx.Insert(dbtransaction.Insert{
+		Table: "QoS",
+		Row:   qos,
+	})
+	tx.Update(dbtransaction.Update{
+		Table: "Port",
+		Where: [][]interface{}{{"name", "==", portName}},
+		Row: map[string]interface{}{
+			"qos": []interface{}{"named-uuid", qosNamedUUID},
+		},
+	})
+	// The QoS and Queue tables are root tables, so the rows of the previous QoS must be deleted explicitly.
+	deleteQoS(tx, qosUUID, queueUUIDs)
+
+	_, txErr, temporary := tx.Commit()
+	if txErr != nil {
+		klog.Error("Transaction failed: ", txErr)
+		return NewTransactionError(txErr, temporary)
+	}
+	return nil
+}
+
+// DeletePortQoS removes the QoS set to the port by SetPortQoS. It does nothing if no QoS is set to the port.
+func (br *OVSBridge) DeletePortQoS(portName string) Error {
+	qosUUID, queueUUIDs, err := br.getPortQoS(portName)
+	if err != nil {
+		return err
+	}
+	if qosUUID == "" {
+		return nil
+	}
+
+	tx := br.ovsdb.Transaction(openvSwitchSchema)
+	tx.Update(dbtransaction.Update{
+		Table: "Port",
+		Where: [][]interface{}{{"name", "==", portName}},
+		Row: map[string]interface{}{
+			"qos": makeOVSDBSetFromList([]string{}),
+		},
+	})
+	deleteQoS(tx, qosUUID, queueUUIDs)
+
+	_, txErr, temporary := tx.Commit()
+	if txErr != nil {
+		klog.Error("Transaction failed: ", txErr)
+		return NewTransactionError(txErr, temporary)
+	}
+	return nil
+}
+
+// getPortQoS returns the UUIDs of the QoS set to the port and of its queues. The QoS UUID is empty if no QoS is set
+// to the port.
+func (br *OVSBridge) getPortQoS(portName string) (string, []string, Error) {
+	tx := br.ovsdb.Transaction(openvSwitchSchema)
+	tx.Select(dbtransaction.Select{
+		Table:   "Port",
+		Columns: []string{"qos"},
+		Where:   [][]interface{}{{"name", "==", portName}},
+	})
+	res, err, temporary := tx.Commit()
+	if err != nil {
+		klog.Error("Transaction failed: ", err)
+		return "", nil, NewTransactionError(err, temporary)
+	}
+	if len(res[0].Rows) == 0 {
+		return "", nil, NewTransactionError(fmt.Errorf("port %s not found", portName), false)
+	}
+	// An optional reference is represented by an empty set when it is not set.
+	qosRes := res[0].Rows[0].(map[string]interface{})["qos"].([]interface{})
+	if qosRes[0] != "uuid" {
+		return "", nil, nil
+	}
+	qosUUID := qosRes[1].(string)
+
+	tx = br.ovsdb.Transaction(openvSwitchSchema)
+	tx.Select(dbtransaction.Select{
+		Table:   "QoS",
+		Columns: []string{"queues"},
+		Where:   [][]interface{}{{"_uuid", "==", []string{"uuid", qosUUID}}},
+	})
+	res, err, temporary = tx.Commit()
+	if err != nil {
+		klog.Error("Transaction failed: ", err)
+		return "", nil, NewTransactionError(err, temporary)
+	}
+	var queueUUIDs []string
+	if len(res[0].Rows) > 0 {
+		queuesRes := res[0].Rows[0].(map[string]interface{})["queues"].([]interface{})
+		for _, pair := range queuesRes[1].([]interface{}) {
+			queueRef := pair.([]interface{})[1].([]interface{})
+			queueUUIDs = append(queueUUIDs, queueRef[1].(string))
+		}
+	}
+	return qosUUID, queueUUIDs, nil
+}
+
+func deleteQoS(tx *dbtransaction.Transaction, qosUUID string, queueUUIDs []string) {
+	if qosUUID == "" {
+		return
+	}
+	tx.Delete(dbtransaction.Delete{
+		Table: "QoS",
+		Where: [][]interface{}{{"_uuid", "==", []string{"uuid", qosUUID}}},
+	})
+	for _, queueUUID := range queueUUIDs {
+		tx.Delete(dbtransaction.Delete{
+			Table: "Queue",
+			Where: [][]interface{}{{"_uuid", "==", []string{"uuid", queueUUID}}},
+		})
+	}
+}
+
 // GetOVSVersion either returns the version of OVS, or an error.
 func (br *OVSBridge) GetOVSVersion() (string, Error) {
 	tx := br.ovsdb.Transaction(openvSwitchSchema)
@@ -32,3 +32,13 @@ type Interface struct {
 	OFPortRequest int32         `json:"ofport_request,omitempty"`
 	Options       []interface{} `json:"options,omitempty"`
 }
+
+type QoS struct {
+	Type        string        `json:"type"`
+	OtherConfig []interface{} `json:"other_config,omitempty"`
+	Queues      []interface{} `json:"queues,omitempty"`
+}
+
+type Queue struct {
+	OtherConfig []interface{} `json:"other_config,omitempty"`
+}
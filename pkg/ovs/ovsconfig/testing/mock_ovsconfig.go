@@ -151,6 +151,21 @@ func (mr *MockOVSBridgeClientMockRecorder) CreateUplinkPort(arg0, arg1, arg2 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUplinkPort", reflect.TypeOf((*MockOVSBridgeClient)(nil).CreateUplinkPort), arg0, arg1, arg2)
 }
 
+// CreateVhostUserClientPort mocks base method
+func (m *MockOVSBridgeClient) CreateVhostUserClientPort(arg0, arg1 string, arg2 map[string]interface{}) (string, ovsconfig.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVhostUserClientPort", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(ovsconfig.Error)
+	return ret0, ret1
+}
+
+// CreateVhostUserClientPort indicates an expected call of CreateVhostUserClientPort
+func (mr *MockOVSBridgeClientMockRecorder) CreateVhostUserClientPort(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVhostUserClientPort", reflect.TypeOf((*MockOVSBridgeClient)(nil).CreateVhostUserClientPort), arg0, arg1, arg2)
+}
+
 // Delete mocks base method
 func (m *MockOVSBridgeClient) Delete() ovsconfig.Error {
 	m.ctrl.T.Helper()
@@ -193,6 +208,20 @@ func (mr *MockOVSBridgeClientMockRecorder) DeletePort(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePort", reflect.TypeOf((*MockOVSBridgeClient)(nil).DeletePort), arg0)
 }
 
+// DeletePortQoS mocks base method
+func (m *MockOVSBridgeClient) DeletePortQoS(arg0 string) ovsconfig.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePortQoS", arg0)
+	ret0, _ := ret[0].(ovsconfig.Error)
+	return ret0
+}
+
+// DeletePortQoS indicates an expected call of DeletePortQoS
+func (mr *MockOVSBridgeClientMockRecorder) DeletePortQoS(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePortQoS", reflect.TypeOf((*MockOVSBridgeClient)(nil).DeletePortQoS), arg0)
+}
+
 // DeletePorts mocks base method
 func (m *MockOVSBridgeClient) DeletePorts(arg0 []string) ovsconfig.Error {
 	m.ctrl.T.Helper()
@@ -409,3 +438,17 @@ func (mr *MockOVSBridgeClientMockRecorder) SetInterfaceOptions(arg0, arg1 interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInterfaceOptions", reflect.TypeOf((*MockOVSBridgeClient)(nil).SetInterfaceOptions), arg0, arg1)
 }
+
+// SetPortQoS mocks base method
+func (m *MockOVSBridgeClient) SetPortQoS(arg0 string, arg1 uint64, arg2 map[uint32]ovsconfig.QueueConfig) ovsconfig.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPortQoS", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ovsconfig.Error)
+	return ret0
+}
+
+// SetPortQoS indicates an expected call of SetPortQoS
+func (mr *MockOVSBridgeClientMockRecorder) SetPortQoS(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPortQoS", reflect.TypeOf((*MockOVSBridgeClient)(nil).SetPortQoS), arg0, arg1, arg2)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 )
 
@@ -168,3 +169,82 @@ func (c *ovsCtlClient) RunAppctlCmd(cmd string, needsBridge bool, args ...string
 	}
 	return out, nil
 }
+
+func (c *ovsCtlClient) DumpDatapathFeatures() (map[string]string, error) {
+	out, execErr := c.RunAppctlCmd("dpif/show-dp-features", true)
+	if execErr != nil {
+		return nil, fmt.Errorf("error dumping datapath features: %v, output: %s", execErr, execErr.GetErrorOutput())
+	}
+	return parseDatapathFeatures(string(out)), nil
+}
+
+// parseDatapathFeatures parses the output of "ovs-appctl dpif/show-dp-features", which has one "<feature>: <value>"
+// line per feature, e.g. "CT state NAT: Yes" or "Max VLAN headers: 2".
+func parseDatapathFeatures(output string) map[string]string {
+	features := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		i := strings.LastIndex(line, ":")
+		if i <= 0 {
+			continue
+		}
+		features[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return features
+}
+
+func (c *ovsCtlClient) DumpDatapathFlows() ([]DatapathFlow, error) {
+	// The offload status of the flows is only printed with the verbose option.
+	out, execErr := c.RunAppctlCmd("dpctl/dump-flows", false, "-m")
+	if execErr != nil {
+		return nil, fmt.Errorf("error dumping datapath flows: %v, output: %s", execErr, execErr.GetErrorOutput())
+	}
+	return parseDatapathFlows(string(out))
+}
+
+// parseDatapathFlows parses the output of "ovs-appctl dpctl/dump-flows -m", which has one line per flow, e.g.:
+// ufid:1b0e..., recirc_id(0),in_port(2),eth_type(0x0800),ipv4(frag=no), packets:10, bytes:980, used:0.550s,
+// offloaded:yes, dp:tc, actions:ct(zone=65520),recirc(0x1)
+// The offloaded field is omitted for the flows which are not offloaded.
+func parseDatapathFlows(output string) ([]DatapathFlow, error) {
+	var flows []DatapathFlow
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		statsIndex := strings.Index(line, ", packets:")
+		actionsIndex := strings.LastIndex(line, ", actions:")
+		if statsIndex < 0 || actionsIndex < statsIndex {
+			continue
+		}
+		flow := DatapathFlow{
+			Match:     line[:statsIndex],
+			Actions:   line[actionsIndex+len(", actions:"):],
+			Offloaded: "no",
+		}
+		if strings.HasPrefix(flow.Match, "ufid:") {
+			if i := strings.Index(flow.Match, ", "); i >= 0 {
+				flow.Match = flow.Match[i+2:]
+			}
+		}
+		for _, field := range strings.Split(line[statsIndex+2:actionsIndex], ", ") {
+			kv := strings.SplitN(field, ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			var err error
+			switch kv[0] {
+			case "packets":
+				flow.Packets, err = strconv.ParseUint(kv[1], 10, 64)
+			case "bytes":
+				flow.Bytes, err = strconv.ParseUint(kv[1], 10, 64)
+			case "offloaded":
+				flow.Offloaded = kv[1]
+			case "dp":
+				flow.Datapath = kv[1]
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid counter in datapath flow %q: %v", line, err)
+			}
+		}
+		flows = append(flows, flow)
+	}
+	return flows, nil
+}
@@ -45,6 +45,20 @@ type OVSCtlClient interface {
 	DumpPortsDesc() ([][]string, error)
 	// RunOfctlCmd executes "ovs-ofctl" command and returns the outputs.
 	RunOfctlCmd(cmd string, args ...string) ([]byte, error)
+	// SupportsOFVersion returns whether the bridge accepts OpenFlow connections with the given version, e.g.
+	// "OpenFlow15".
+	SupportsOFVersion(version string) bool
+	// GetMaxMeters returns the maximum number of OpenFlow meters supported by the bridge. 0 means that the bridge
+	// doesn't support meters.
+	GetMaxMeters() (uint32, error)
+	// DumpMeterStats returns the statistics of the OpenFlow meters of the bridge, keyed by meter ID.
+	DumpMeterStats() (map[uint32]*MeterStats, error)
+	// DumpDatapathFeatures returns the features supported by the datapath of the bridge, as reported by
+	// "ovs-appctl dpif/show-dp-features", e.g. "CT state NAT" -> "Yes".
+	DumpDatapathFeatures() (map[string]string, error)
+	// DumpDatapathFlows returns the flows of the datapath, as reported by "ovs-appctl dpctl/dump-flows", along with
+	// their hardware offload status.
+	DumpDatapathFlows() ([]DatapathFlow, error)
 	// SetPortNoFlood sets the given port with config "no-flood". This configuration must work with OpenFlow10.
 	SetPortNoFlood(ofport int) error
 	// Trace executes "ovs-appctl ofproto/trace" to perform OVS packet tracing.
@@ -54,6 +68,31 @@ type OVSCtlClient interface {
 	RunAppctlCmd(cmd string, needsBridge bool, args ...string) ([]byte, *ExecError)
 }
 
+// MeterStats are the statistics of an OpenFlow meter.
+type MeterStats struct {
+	// Packets and Bytes count the traffic processed by the meter, including the dropped traffic.
+	Packets uint64
+	Bytes   uint64
+	// DroppedPackets and DroppedBytes count the traffic exceeding the rate of the bands of the meter.
+	DroppedPackets uint64
+	DroppedBytes   uint64
+}
+
+// DatapathFlow is a flow of the OVS datapath.
+type DatapathFlow struct {
+	// Match is the flow key and mask of the flow, e.g. "recirc_id(0),in_port(2),eth_type(0x0800),ipv4(frag=no)".
+	Match   string
+	Actions string
+	Packets uint64
+	Bytes   uint64
+	// Offloaded is "yes" if the flow is offloaded to the hardware, "partial" if only its match is offloaded, or
+	// "no".
+	Offloaded string
+	// Datapath is the layer which holds the flow, e.g. "ovs" for the kernel or userspace datapath, or "tc" for the
+	// flows offloaded with TC.
+	Datapath string
+}
+
 type BadRequestError string
 
 func (e BadRequestError) Error() string {
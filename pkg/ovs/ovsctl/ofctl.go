@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -119,6 +120,92 @@ func (c *ovsCtlClient) DumpPortsDesc() ([][]string, error) {
 	return rawPortDescItems, nil
 }
 
+func (c *ovsCtlClient) SupportsOFVersion(version string) bool {
+	// The version negotiation fails if the version is not enabled in the "protocols" column of the bridge.
+	_, err := c.runOfctlCmdWithVersion(version, "show")
+	return err == nil
+}
+
+func (c *ovsCtlClient) GetMaxMeters() (uint32, error) {
+	out, err := c.RunOfctlCmd("meter-features")
+	if err != nil {
+		return 0, err
+	}
+	return parseMaxMeters(string(out))
+}
+
+// parseMaxMeters parses the output of "ovs-ofctl meter-features", e.g.:
+// OFPST_METER_FEATURES reply (OF1.3) (xid=0x2):
+// max_meter:200000 max_bands:1 max_color:0
+func parseMaxMeters(output string) (uint32, error) {
+	for _, field := range strings.Fields(output) {
+		if !strings.HasPrefix(field, "max_meter:") {
+			continue
+		}
+		maxMeters, err := strconv.ParseUint(strings.TrimPrefix(field, "max_meter:"), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max_meter in meter features %q: %v", output, err)
+		}
+		return uint32(maxMeters), nil
+	}
+	return 0, fmt.Errorf("max_meter not found in meter features %q", output)
+}
+
+func (c *ovsCtlClient) DumpMeterStats() (map[uint32]*MeterStats, error) {
+	out, err := c.RunOfctlCmd("meter-stats")
+	if err != nil {
+		return nil, err
+	}
+	return parseMeterStats(string(out))
+}
+
+// parseMeterStats parses the output of "ovs-ofctl meter-stats", e.g.:
+// OFPST_METER reply (OF1.3) (xid=0x2):
+// meter:1 flow_count:2 packet_in_count:10 byte_in_count:980 duration:12.345s bands:
+// 0: packet_count:3 byte_count:294
+// The counters of the bands follow the meter they belong to.
+func parseMeterStats(output string) (map[uint32]*MeterStats, error) {
+	stats := map[uint32]*MeterStats{}
+	var current *MeterStats
+	for _, field := range strings.Fields(output) {
+		kv := strings.SplitN(field, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if kv[0] == "meter" {
+			id, err := strconv.ParseUint(kv[1], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid meter ID in meter stats %q: %v", field, err)
+			}
+			current = &MeterStats{}
+			stats[uint32(id)] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		var counter *uint64
+		switch kv[0] {
+		case "packet_in_count":
+			counter = &current.Packets
+		case "byte_in_count":
+			counter = &current.Bytes
+		case "packet_count":
+			counter = &current.DroppedPackets
+		case "byte_count":
+			counter = &current.DroppedBytes
+		default:
+			continue
+		}
+		value, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter in meter stats %q: %v", field, err)
+		}
+		*counter += value
+	}
+	return stats, nil
+}
+
 func (c *ovsCtlClient) SetPortNoFlood(ofport int) error {
 	cmdStr := fmt.Sprintf("ovs-ofctl mod-port %s %d no-flood", c.bridge, ofport)
 	cmd := getOVSCommand(cmdStr)
@@ -133,7 +220,11 @@ func (c *ovsCtlClient) SetPortNoFlood(ofport int) error {
 }
 
 func (c *ovsCtlClient) RunOfctlCmd(cmd string, args ...string) ([]byte, error) {
-	cmdStr := fmt.Sprintf("ovs-ofctl -O Openflow13 %s %s", cmd, c.bridge)
+	return c.runOfctlCmdWithVersion("Openflow13", cmd, args...)
+}
+
+func (c *ovsCtlClient) runOfctlCmdWithVersion(version, cmd string, args ...string) ([]byte, error) {
+	cmdStr := fmt.Sprintf("ovs-ofctl -O %s %s %s", version, cmd, c.bridge)
 	cmdStr = cmdStr + " " + strings.Join(args, " ")
 	out, err := getOVSCommand(cmdStr).Output()
 	if err != nil {
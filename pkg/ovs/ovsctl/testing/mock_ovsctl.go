@@ -48,6 +48,36 @@ func (m *MockOVSCtlClient) EXPECT() *MockOVSCtlClientMockRecorder {
 	return m.recorder
 }
 
+// DumpDatapathFeatures mocks base method
+func (m *MockOVSCtlClient) DumpDatapathFeatures() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpDatapathFeatures")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpDatapathFeatures indicates an expected call of DumpDatapathFeatures
+func (mr *MockOVSCtlClientMockRecorder) DumpDatapathFeatures() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpDatapathFeatures", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpDatapathFeatures))
+}
+
+// DumpDatapathFlows mocks base method
+func (m *MockOVSCtlClient) DumpDatapathFlows() ([]ovsctl.DatapathFlow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpDatapathFlows")
+	ret0, _ := ret[0].([]ovsctl.DatapathFlow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpDatapathFlows indicates an expected call of DumpDatapathFlows
+func (mr *MockOVSCtlClientMockRecorder) DumpDatapathFlows() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpDatapathFlows", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpDatapathFlows))
+}
+
 // DumpFlows mocks base method
 func (m *MockOVSCtlClient) DumpFlows(arg0 ...string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +131,21 @@ func (mr *MockOVSCtlClientMockRecorder) DumpMatchedFlow(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpMatchedFlow", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpMatchedFlow), arg0)
 }
 
+// DumpMeterStats mocks base method
+func (m *MockOVSCtlClient) DumpMeterStats() (map[uint32]*ovsctl.MeterStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpMeterStats")
+	ret0, _ := ret[0].(map[uint32]*ovsctl.MeterStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpMeterStats indicates an expected call of DumpMeterStats
+func (mr *MockOVSCtlClientMockRecorder) DumpMeterStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpMeterStats", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpMeterStats))
+}
+
 // DumpPortsDesc mocks base method
 func (m *MockOVSCtlClient) DumpPortsDesc() ([][]string, error) {
 	m.ctrl.T.Helper()
@@ -131,6 +176,21 @@ func (mr *MockOVSCtlClientMockRecorder) DumpTableFlows(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpTableFlows", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpTableFlows), arg0)
 }
 
+// GetMaxMeters mocks base method
+func (m *MockOVSCtlClient) GetMaxMeters() (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxMeters")
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxMeters indicates an expected call of GetMaxMeters
+func (mr *MockOVSCtlClientMockRecorder) GetMaxMeters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxMeters", reflect.TypeOf((*MockOVSCtlClient)(nil).GetMaxMeters))
+}
+
 // RunAppctlCmd mocks base method
 func (m *MockOVSCtlClient) RunAppctlCmd(arg0 string, arg1 bool, arg2 ...string) ([]byte, *ovsctl.ExecError) {
 	m.ctrl.T.Helper()
@@ -185,6 +245,20 @@ func (mr *MockOVSCtlClientMockRecorder) SetPortNoFlood(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPortNoFlood", reflect.TypeOf((*MockOVSCtlClient)(nil).SetPortNoFlood), arg0)
 }
 
+// SupportsOFVersion mocks base method
+func (m *MockOVSCtlClient) SupportsOFVersion(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupportsOFVersion", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// SupportsOFVersion indicates an expected call of SupportsOFVersion
+func (mr *MockOVSCtlClientMockRecorder) SupportsOFVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportsOFVersion", reflect.TypeOf((*MockOVSCtlClient)(nil).SupportsOFVersion), arg0)
+}
+
 // Trace mocks base method
 func (m *MockOVSCtlClient) Trace(arg0 *ovsctl.TracingRequest) (string, error) {
 	m.ctrl.T.Helper()
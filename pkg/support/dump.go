@@ -44,9 +44,15 @@ type AgentDumper interface {
 	DumpNetworkPolicyResources(basedir string) error
 	// DumpHeapPprof should create a pprof file of heap usage of the agent.
 	DumpHeapPprof(basedir string) error
+	// DumpGoroutinePprof should create a file that contains the stack traces
+	// of all the goroutines of the agent.
+	DumpGoroutinePprof(basedir string) error
 
 	// DumpOVSPorts should create file that contains OF port descriptions under the basedir.
 	DumpOVSPorts(basedir string) error
+	// DumpOVSDB should create a file that contains the contents of the OVSDB
+	// database under the basedir.
+	DumpOVSDB(basedir string) error
 }
 
 // ControllerDumper is the interface for dumping runtime information of the
@@ -63,6 +69,9 @@ type ControllerDumper interface {
 	DumpNetworkPolicyResources(basedir string) error
 	// DumpHeapPprof should create a pprof file of the heap usage of the controller.
 	DumpHeapPprof(basedir string) error
+	// DumpGoroutinePprof should create a file that contains the stack traces
+	// of all the goroutines of the controller.
+	DumpGoroutinePprof(basedir string) error
 }
 
 func DumpHeapPprof(fs afero.Fs, basedir string) error {
@@ -74,6 +83,17 @@ func DumpHeapPprof(fs afero.Fs, basedir string) error {
 	return pprof.WriteHeapProfile(f)
 }
 
+func DumpGoroutinePprof(fs afero.Fs, basedir string) error {
+	f, err := fs.Create(filepath.Join(basedir, "goroutinestacks"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// debug=2 prints the stack of each goroutine in the same format as an
+	// unrecovered panic, which is easier to read than the aggregated profile.
+	return pprof.Lookup("goroutine").WriteTo(f, 2)
+}
+
 func dumpAntctlGet(fs afero.Fs, executor exec.Interface, name, basedir string) error {
 	output, err := executor.Command("antctl", "-oyaml", "get", name).CombinedOutput()
 	if err != nil {
@@ -167,6 +187,10 @@ func (d *controllerDumper) DumpHeapPprof(basedir string) error {
 	return DumpHeapPprof(d.fs, basedir)
 }
 
+func (d *controllerDumper) DumpGoroutinePprof(basedir string) error {
+	return DumpGoroutinePprof(d.fs, basedir)
+}
+
 func NewControllerDumper(fs afero.Fs, executor exec.Interface) ControllerDumper {
 	return &controllerDumper{
 		fs:       fs,
@@ -197,7 +221,7 @@ func (d *agentDumper) DumpAgentInfo(basedir string) error {
 
 func (d *agentDumper) DumpNetworkPolicyResources(basedir string) error {
 	dump := func(o interface{}, name string) error {
-		f, err := d.fs.Create(filepath.Join(basedir, "agentinfo"))
+		f, err := d.fs.Create(filepath.Join(basedir, name))
 		if err != nil {
 			return err
 		}
@@ -227,6 +251,18 @@ func (d *agentDumper) DumpHeapPprof(basedir string) error {
 	return DumpHeapPprof(d.fs, basedir)
 }
 
+func (d *agentDumper) DumpGoroutinePprof(basedir string) error {
+	return DumpGoroutinePprof(d.fs, basedir)
+}
+
+func (d *agentDumper) DumpOVSDB(basedir string) error {
+	output, err := d.executor.Command("ovsdb-client", "-f", "list", "dump").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error when dumping OVSDB: %w", err)
+	}
+	return writeFile(d.fs, filepath.Join(basedir, "ovsdb"), "OVSDB", output)
+}
+
 func (d *agentDumper) DumpOVSPorts(basedir string) error {
 	portsDesc, err := d.ovsCtlClient.DumpPortsDesc()
 	if err != nil {
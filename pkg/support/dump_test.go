@@ -0,0 +1,118 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/exec"
+	exectesting "k8s.io/utils/exec/testing"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	queriertest "github.com/vmware-tanzu/antrea/pkg/querier/testing"
+)
+
+func TestAgentDumpNetworkPolicyResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	npq := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
+	npq.EXPECT().GetAddressGroups().Return([]v1beta2.AddressGroup{{ObjectMeta: metav1.ObjectMeta{Name: "ag1"}}})
+	npq.EXPECT().GetNetworkPolicies(gomock.Any()).Return([]v1beta2.NetworkPolicy{{ObjectMeta: metav1.ObjectMeta{Name: "np1"}}})
+	npq.EXPECT().GetAppliedToGroups().Return([]v1beta2.AppliedToGroup{{ObjectMeta: metav1.ObjectMeta{Name: "atg1"}}})
+	fs := afero.NewMemMapFs()
+	dumper := NewAgentDumper(fs, nil, nil, nil, npq)
+	require.NoError(t, dumper.DumpNetworkPolicyResources("/basedir"))
+
+	// Each resource is dumped to its own file.
+	for file, expectedName := range map[string]string{
+		"addressgroups":   "ag1",
+		"networkpolicies": "np1",
+		"appliedtogroups": "atg1",
+	} {
+		data, err := afero.ReadFile(fs, filepath.Join("/basedir", file))
+		require.NoError(t, err)
+		var objs []metav1.PartialObjectMetadata
+		require.NoError(t, json.Unmarshal(data, &objs))
+		require.Len(t, objs, 1)
+		assert.Equal(t, expectedName, objs[0].Name)
+	}
+}
+
+func TestDumpGoroutinePprof(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, DumpGoroutinePprof(fs, "/basedir"))
+	data, err := afero.ReadFile(fs, "/basedir/goroutinestacks")
+	require.NoError(t, err)
+	// The stack of each goroutine is printed, including the one running the test.
+	assert.Contains(t, string(data), "goroutine ")
+	assert.Contains(t, string(data), "TestDumpGoroutinePprof")
+}
+
+func TestAgentDumpOVSDB(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		output   string
+		err      error
+		wantFile bool
+	}{
+		{
+			name:     "success",
+			output:   "Bridge table\n_uuid name\n...",
+			wantFile: true,
+		},
+		{
+			name: "command failure",
+			err:  fmt.Errorf("ovsdb-client failed"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var cmdArgs []string
+			fakeExec := &exectesting.FakeExec{
+				CommandScript: []exectesting.FakeCommandAction{
+					func(cmd string, args ...string) exec.Cmd {
+						cmdArgs = append([]string{cmd}, args...)
+						return &exectesting.FakeCmd{
+							CombinedOutputScript: []exectesting.FakeAction{
+								func() ([]byte, []byte, error) { return []byte(tc.output), nil, tc.err },
+							},
+						}
+					},
+				},
+			}
+			fs := afero.NewMemMapFs()
+			dumper := NewAgentDumper(fs, fakeExec, nil, nil, nil)
+			err := dumper.DumpOVSDB("/basedir")
+			assert.Equal(t, []string{"ovsdb-client", "-f", "list", "dump"}, cmdArgs)
+			exists, _ := afero.Exists(fs, "/basedir/ovsdb")
+			assert.Equal(t, tc.wantFile, exists)
+			if !tc.wantFile {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			data, err := afero.ReadFile(fs, "/basedir/ovsdb")
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, string(data))
+		})
+	}
+}
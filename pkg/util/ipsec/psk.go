@@ -0,0 +1,67 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SecretName is the name of the Secret which stores the IPsec PSK.
+	SecretName = "antrea-ipsec"
+	// PSKSecretKey is the key of the IPsec PSK in the Secret.
+	PSKSecretKey = "psk"
+	// PSKIDsAnnotationKey is the key of the Node annotation which advertises
+	// the IDs of the IPsec PSKs loaded by the antrea-agent running on the Node,
+	// as a comma-separated list. The first ID is the one of the current PSK, and
+	// the second one, if present, is the one of the previous PSK.
+	PSKIDsAnnotationKey = "node.antrea.io/ipsec-psk-ids"
+
+	pskIDLength = 16
+)
+
+// PSKID returns the ID of a PSK, which can be shared with other Nodes without
+// disclosing the PSK. It is the hex-encoded prefix of the SHA-256 hash of the
+// PSK.
+func PSKID(psk string) string {
+	sum := sha256.Sum256([]byte(psk))
+	return hex.EncodeToString(sum[:])[:pskIDLength]
+}
+
+// GetNodePSKIDs returns the IDs of the PSKs advertised by the Node, or nil if
+// the Node does not advertise any PSK (e.g. IPsec is not enabled or it runs an
+// older version of antrea-agent).
+func GetNodePSKIDs(node *corev1.Node) []string {
+	value := node.Annotations[PSKIDsAnnotationKey]
+	if value == "" {
+		return nil
+	}
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		ids = append(ids, strings.TrimSpace(id))
+	}
+	return ids
+}
+
+// HasCurrentPSK returns whether the current PSK advertised by the Node has the
+// provided ID.
+func HasCurrentPSK(node *corev1.Node, pskID string) bool {
+	ids := GetNodePSKIDs(node)
+	return len(ids) > 0 && ids[0] == pskID
+}
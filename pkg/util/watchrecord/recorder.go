@@ -0,0 +1,113 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watchrecord provides the ability to record watch streams to a file
+// and to replay them later, e.g. to reproduce the NetworkPolicy churn of a
+// production cluster against an antrea-agent running in a dev environment.
+package watchrecord
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog"
+)
+
+// Event is a recorded watch event. The recording is a sequence of JSON-encoded
+// Events, one per line.
+type Event struct {
+	// Time is the time at which the event was received.
+	Time time.Time `json:"time"`
+	// Resource is the type of the watched objects, e.g. "NetworkPolicy".
+	Resource string `json:"resource"`
+	// Type is the type of the event.
+	Type watch.EventType `json:"type"`
+	// Object is the JSON-encoded object of the event.
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+// Recorder writes the events of the watch streams wrapped by it to a writer.
+type Recorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewRecorder creates a Recorder which writes the recorded events to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{encoder: json.NewEncoder(w)}
+}
+
+// Wrap returns a watch.Interface which forwards the events of w and records
+// them as events of the provided resource.
+func (r *Recorder) Wrap(resource string, w watch.Interface) watch.Interface {
+	rw := &recordingWatcher{
+		source: w,
+		result: make(chan watch.Event),
+		stopCh: make(chan struct{}),
+	}
+	go rw.run(func(event watch.Event) {
+		r.record(resource, event)
+	})
+	return rw
+}
+
+func (r *Recorder) record(resource string, event watch.Event) {
+	e := Event{Time: time.Now(), Resource: resource, Type: event.Type}
+	if event.Object != nil {
+		data, err := json.Marshal(event.Object)
+		if err != nil {
+			klog.Errorf("Failed to encode %s event object: %v", resource, err)
+			return
+		}
+		e.Object = data
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err := r.encoder.Encode(&e); err != nil {
+		klog.Errorf("Failed to record %s event: %v", resource, err)
+	}
+}
+
+type recordingWatcher struct {
+	source   watch.Interface
+	result   chan watch.Event
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *recordingWatcher) run(record func(event watch.Event)) {
+	defer close(w.result)
+	for event := range w.source.ResultChan() {
+		record(event)
+		select {
+		case w.result <- event:
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+func (w *recordingWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		w.source.Stop()
+	})
+}
+
+func (w *recordingWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchrecord
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog"
+)
+
+// Replayer replays recorded watch streams. The events of all the resources are
+// replayed on the same timeline, starting when the first stream is watched, so
+// that the relative order and timing of the recorded events are preserved.
+// When the stream of a resource is watched again, the replay resumes after the
+// last replayed event, and the remaining events are delayed by the time during
+// which the stream was not watched.
+type Replayer struct {
+	events map[string][]Event
+	// firstEventTime is the time of the first recorded event.
+	firstEventTime time.Time
+	// speed is the factor by which the replay is accelerated. If it is 0, the
+	// events are replayed without delay.
+	speed float64
+	clock clock.Clock
+
+	startOnce sync.Once
+	startTime time.Time
+
+	// mutex protects streams.
+	mutex   sync.Mutex
+	streams map[string]*replayStream
+}
+
+// replayStream is the replay state of the stream of a resource.
+type replayStream struct {
+	// position is the number of replayed events.
+	position int
+	// delay is the total time during which the stream was not watched after
+	// its first watch.
+	delay time.Duration
+	// stopTime is the time at which the last watch of the stream was stopped.
+	stopTime time.Time
+	// done is closed when the replay of the last watch of the stream returns.
+	// It is protected by the mutex of the Replayer, while the other fields are
+	// only accessed by the replay of the last watch.
+	done chan struct{}
+}
+
+// NewReplayer creates a Replayer from the events recorded by a Recorder.
+func NewReplayer(r io.Reader, speed float64) (*Replayer, error) {
+	if speed < 0 {
+		return nil, fmt.Errorf("invalid replay speed %v, it cannot be negative", speed)
+	}
+	p := &Replayer{
+		events:  map[string][]Event{},
+		speed:   speed,
+		clock:   clock.RealClock{},
+		streams: map[string]*replayStream{},
+	}
+	scanner := bufio.NewScanner(r)
+	// Events may contain large groups, allow lines of up to 64MB.
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid event at line %d: %v", line, err)
+		}
+		if p.firstEventTime.IsZero() || e.Time.Before(p.firstEventTime) {
+			p.firstEventTime = e.Time
+		}
+		p.events[e.Resource] = append(p.events[e.Resource], e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Watch returns a watch.Interface which replays the recorded events of the
+// provided resource. newObject must return an empty object of the type of the
+// resource, into which the recorded objects are decoded. The result channel is
+// kept open after all the events have been replayed, until Stop is called.
+// If the resource was watched before, the previous watch must have been
+// stopped: like a new watch of the apiserver, the resumed watch first sends an
+// Added event for each existing object and a Bookmark event, then it replays
+// the events which were not replayed yet. Watch never blocks: the resumed
+// watch only starts sending events once the replay of the previous watch has
+// returned.
+func (p *Replayer) Watch(resource string, newObject func() runtime.Object) watch.Interface {
+	p.startOnce.Do(func() {
+		p.startTime = p.clock.Now()
+	})
+	p.mutex.Lock()
+	s, resumed := p.streams[resource]
+	if !resumed {
+		s = &replayStream{}
+		p.streams[resource] = s
+	}
+	previousDone := s.done
+	done := make(chan struct{})
+	s.done = done
+	p.mutex.Unlock()
+	w := &replayWatcher{
+		result: make(chan watch.Event),
+		stopCh: make(chan struct{}),
+	}
+	go w.run(p, resource, s, previousDone, done, newObject)
+	return w
+}
+
+// getReplayTime returns the time at which the provided event should be replayed.
+func (p *Replayer) getReplayTime(e *Event) time.Time {
+	if p.speed == 0 {
+		return p.startTime
+	}
+	offset := float64(e.Time.Sub(p.firstEventTime)) / p.speed
+	return p.startTime.Add(time.Duration(offset))
+}
+
+type replayWatcher struct {
+	result   chan watch.Event
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// run replays the events of the stream. previousDone is closed when the replay
+// of the previous watch of the stream returns, or nil if the stream was not
+// watched before, and done is closed when run returns.
+func (w *replayWatcher) run(p *Replayer, resource string, s *replayStream, previousDone <-chan struct{}, done chan struct{}, newObject func() runtime.Object) {
+	defer func() {
+		s.stopTime = p.clock.Now()
+		close(w.result)
+		close(done)
+	}()
+	if previousDone != nil {
+		<-previousDone
+		s.delay += p.clock.Since(s.stopTime)
+	}
+	events := p.events[resource]
+	if s.position > 0 {
+		klog.Infof("Resuming replay of recorded events for %s after %d events", resource, s.position)
+		for _, e := range getExistingObjects(events[:s.position]) {
+			obj, err := decodeObject(&e, newObject)
+			if err != nil {
+				klog.Errorf("Failed to decode recorded %s object: %v", resource, err)
+				continue
+			}
+			if !w.send(watch.Event{Type: watch.Added, Object: obj}) {
+				return
+			}
+		}
+		if !w.send(watch.Event{Type: watch.Bookmark, Object: newObject()}) {
+			return
+		}
+	}
+	for ; s.position < len(events); s.position++ {
+		e := &events[s.position]
+		if delay := p.getReplayTime(e).Add(s.delay).Sub(p.clock.Now()); delay > 0 {
+			select {
+			case <-p.clock.After(delay):
+			case <-w.stopCh:
+				return
+			}
+		}
+		obj, err := decodeObject(e, newObject)
+		if err != nil {
+			klog.Errorf("Failed to decode recorded %s event: %v", resource, err)
+			continue
+		}
+		if !w.send(watch.Event{Type: e.Type, Object: obj}) {
+			return
+		}
+	}
+	klog.Infof("Replayed all %d recorded events for %s", len(events), resource)
+	<-w.stopCh
+}
+
+// send sends the event to the result channel. It returns false if the watcher
+// is stopped before the event is received.
+func (w *replayWatcher) send(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.stopCh:
+		return false
+	}
+}
+
+func decodeObject(e *Event, newObject func() runtime.Object) (runtime.Object, error) {
+	obj := newObject()
+	if len(e.Object) > 0 {
+		if err := json.Unmarshal(e.Object, obj); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// getExistingObjects returns the last recorded event of each object which
+// exists after the provided events, in the order in which the objects were
+// first added.
+func getExistingObjects(events []Event) []Event {
+	var keys []string
+	existing := map[string]Event{}
+	for _, e := range events {
+		if e.Type != watch.Added && e.Type != watch.Modified && e.Type != watch.Deleted {
+			continue
+		}
+		var meta metav1.PartialObjectMetadata
+		if err := json.Unmarshal(e.Object, &meta); err != nil {
+			klog.Errorf("Failed to decode metadata of recorded %s object: %v", e.Resource, err)
+			continue
+		}
+		key := meta.Namespace + "/" + meta.Name
+		if e.Type == watch.Deleted {
+			delete(existing, key)
+			continue
+		}
+		if _, ok := existing[key]; !ok {
+			keys = append(keys, key)
+		}
+		existing[key] = e
+	}
+	objects := make([]Event, 0, len(existing))
+	for _, key := range keys {
+		if e, ok := existing[key]; ok {
+			objects = append(objects, e)
+			// A deleted and re-added object is only returned once.
+			delete(existing, key)
+		}
+	}
+	return objects
+}
+
+func (w *replayWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+func (w *replayWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchrecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+func receiveEvent(t *testing.T, w watch.Interface) watch.Event {
+	select {
+	case event := <-w.ResultChan():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return watch.Event{}
+}
+
+// stepClock advances the fake clock once the replay is waiting for it.
+func stepClock(t *testing.T, fakeClock *clock.FakeClock, d time.Duration) {
+	require.NoError(t, wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}), "Timed out waiting for the replay to wait for the clock")
+	fakeClock.Step(d)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	policy1 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}}
+	policy2 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy2"}}
+	group1 := &v1beta2.AddressGroup{ObjectMeta: metav1.ObjectMeta{Name: "group1"}}
+	expectedPolicyEvents := []watch.Event{
+		{Type: watch.Added, Object: policy1},
+		{Type: watch.Bookmark, Object: &v1beta2.NetworkPolicy{}},
+		{Type: watch.Added, Object: policy2},
+		{Type: watch.Deleted, Object: policy1},
+	}
+	expectedGroupEvents := []watch.Event{
+		{Type: watch.Bookmark, Object: &v1beta2.AddressGroup{}},
+		{Type: watch.Added, Object: group1},
+	}
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	policySource, groupSource := watch.NewFake(), watch.NewFake()
+	policyWatcher := recorder.Wrap("NetworkPolicy", policySource)
+	groupWatcher := recorder.Wrap("AddressGroup", groupSource)
+	for _, event := range expectedPolicyEvents {
+		go policySource.Action(event.Type, event.Object)
+		assert.Equal(t, event, receiveEvent(t, policyWatcher))
+	}
+	for _, event := range expectedGroupEvents {
+		go groupSource.Action(event.Type, event.Object)
+		assert.Equal(t, event, receiveEvent(t, groupWatcher))
+	}
+	policyWatcher.Stop()
+	groupWatcher.Stop()
+
+	replayer, err := NewReplayer(&buf, 0)
+	require.NoError(t, err)
+	policyWatcher = replayer.Watch("NetworkPolicy", func() runtime.Object { return &v1beta2.NetworkPolicy{} })
+	defer policyWatcher.Stop()
+	groupWatcher = replayer.Watch("AddressGroup", func() runtime.Object { return &v1beta2.AddressGroup{} })
+	defer groupWatcher.Stop()
+	for _, event := range expectedPolicyEvents {
+		assert.Equal(t, event, receiveEvent(t, policyWatcher))
+	}
+	for _, event := range expectedGroupEvents {
+		assert.Equal(t, event, receiveEvent(t, groupWatcher))
+	}
+	// The result channel is kept open after all events have been replayed.
+	select {
+	case event := <-policyWatcher.ResultChan():
+		t.Fatalf("Unexpected event %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGetReplayTime(t *testing.T) {
+	firstEventTime := time.Now()
+	startTime := firstEventTime.Add(time.Hour)
+	e := &Event{Time: firstEventTime.Add(10 * time.Second)}
+
+	p := &Replayer{firstEventTime: firstEventTime, startTime: startTime, speed: 1}
+	assert.Equal(t, startTime.Add(10*time.Second), p.getReplayTime(e))
+	p.speed = 2
+	assert.Equal(t, startTime.Add(5*time.Second), p.getReplayTime(e))
+	p.speed = 0
+	assert.Equal(t, startTime, p.getReplayTime(e))
+}
+
+func TestReplayResume(t *testing.T) {
+	policy1 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}}
+	policy2 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy2"}}
+	policy3 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy3"}}
+	firstEventTime := time.Now().Add(-time.Hour)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i, event := range []watch.Event{
+		{Type: watch.Added, Object: policy1},
+		{Type: watch.Bookmark, Object: &v1beta2.NetworkPolicy{}},
+		{Type: watch.Added, Object: policy2},
+		{Type: watch.Deleted, Object: policy1},
+		{Type: watch.Added, Object: policy3},
+	} {
+		data, err := json.Marshal(event.Object)
+		require.NoError(t, err)
+		// The events are recorded every second, and replayed every 100ms.
+		eventTime := firstEventTime
+		if i > 1 {
+			eventTime = firstEventTime.Add(time.Duration(i-1) * time.Second)
+		}
+		require.NoError(t, encoder.Encode(&Event{Time: eventTime, Resource: "NetworkPolicy", Type: event.Type, Object: data}))
+	}
+	newObject := func() runtime.Object { return &v1beta2.NetworkPolicy{} }
+
+	replayer, err := NewReplayer(&buf, 10)
+	require.NoError(t, err)
+	fakeClock := clock.NewFakeClock(time.Now())
+	replayer.clock = fakeClock
+	w := replayer.Watch("NetworkPolicy", newObject)
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy1}, receiveEvent(t, w))
+	assert.Equal(t, watch.Event{Type: watch.Bookmark, Object: &v1beta2.NetworkPolicy{}}, receiveEvent(t, w))
+	stepClock(t, fakeClock, 100*time.Millisecond)
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy2}, receiveEvent(t, w))
+	w.Stop()
+	for range w.ResultChan() {
+	}
+	// The remaining events would have been replayed while the stream is not watched.
+	fakeClock.Step(300 * time.Millisecond)
+
+	w = replayer.Watch("NetworkPolicy", newObject)
+	defer w.Stop()
+	// The resumed watch starts with the existing objects.
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy1}, receiveEvent(t, w))
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy2}, receiveEvent(t, w))
+	assert.Equal(t, watch.Event{Type: watch.Bookmark, Object: &v1beta2.NetworkPolicy{}}, receiveEvent(t, w))
+	// The remaining events are replayed with the recorded pacing, instead of all at once.
+	stepClock(t, fakeClock, 50*time.Millisecond)
+	select {
+	case event := <-w.ResultChan():
+		t.Fatalf("Unexpected event %v", event)
+	default:
+	}
+	stepClock(t, fakeClock, 50*time.Millisecond)
+	assert.Equal(t, watch.Event{Type: watch.Deleted, Object: policy1}, receiveEvent(t, w))
+	stepClock(t, fakeClock, 100*time.Millisecond)
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy3}, receiveEvent(t, w))
+}
+
+func TestWatchNotBlocking(t *testing.T) {
+	policy1 := &v1beta2.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}}
+	data, err := json.Marshal(policy1)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(&Event{Time: time.Now(), Resource: "NetworkPolicy", Type: watch.Added, Object: data}))
+	newObject := func() runtime.Object { return &v1beta2.NetworkPolicy{} }
+
+	replayer, err := NewReplayer(&buf, 0)
+	require.NoError(t, err)
+	w1 := replayer.Watch("NetworkPolicy", newObject)
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy1}, receiveEvent(t, w1))
+	// Watch returns while the previous watch is still running, but the resumed
+	// watch only sends events once the previous watch is stopped.
+	w2 := replayer.Watch("NetworkPolicy", newObject)
+	defer w2.Stop()
+	select {
+	case event := <-w2.ResultChan():
+		t.Fatalf("Unexpected event %v", event)
+	default:
+	}
+	w1.Stop()
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: policy1}, receiveEvent(t, w2))
+	assert.Equal(t, watch.Event{Type: watch.Bookmark, Object: &v1beta2.NetworkPolicy{}}, receiveEvent(t, w2))
+}
+
+func TestNewReplayerNegativeSpeed(t *testing.T) {
+	_, err := NewReplayer(&bytes.Buffer{}, -1)
+	assert.Error(t, err)
+}
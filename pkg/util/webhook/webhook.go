@@ -0,0 +1,216 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook notifies external systems of the events of the Antrea components, e.g. Slack or
+// PagerDuty, by sending HTTP POST requests with a JSON body rendered from a template.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// The types of the events sent to the webhooks.
+const (
+	// EventTraceflowDropped is sent when a Traceflow completes and the packet was dropped.
+	EventTraceflowDropped = "TraceflowDropped"
+	// EventPolicyDropRateExceeded is sent when the rate of the packets dropped by the NetworkPolicies
+	// on a Node exceeds the configured threshold.
+	EventPolicyDropRateExceeded = "PolicyDropRateExceeded"
+)
+
+const (
+	// requestTimeout is the timeout of the requests sent to the webhooks.
+	requestTimeout = 10 * time.Second
+	// eventQueueSize is the maximum number of events waiting to be sent. The events are discarded when
+	// the queue is full, so that a slow webhook never blocks the components.
+	eventQueueSize = 100
+)
+
+// SinkConfig is the configuration of a webhook.
+type SinkConfig struct {
+	// URL to which the events are sent with HTTP POST requests.
+	URL string `yaml:"url"`
+	// Types of the events sent to the webhook. All the events are sent if empty.
+	Events []string `yaml:"events,omitempty"`
+	// Go template of the JSON body of the requests, executed with the Event. The "json" function
+	// encodes a value as JSON, e.g. '{"text": {{ json .Message }}}'. The Event is encoded as JSON if
+	// empty.
+	Template string `yaml:"template,omitempty"`
+}
+
+// Event is an event sent to the webhooks.
+type Event struct {
+	// Type is the type of the event, e.g. EventTraceflowDropped.
+	Type string `json:"type"`
+	// Time is the time at which the event occurred.
+	Time time.Time `json:"time"`
+	// Source is the component reporting the event, e.g. "antrea-controller" or the name of a Node.
+	Source string `json:"source"`
+	// Message is a human-readable description of the event.
+	Message string `json:"message"`
+	// Details contains the attributes of the event, which depend on its type.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+type sink struct {
+	url string
+	// redactedURL is the URL logged instead of url, which often includes a secret token, e.g. for
+	// Slack webhooks.
+	redactedURL string
+	events      map[string]bool
+	template    *template.Template
+}
+
+// EventNotifier reports events to the webhooks. It is implemented by Notifier.
+type EventNotifier interface {
+	Notify(event Event)
+}
+
+// Notifier sends the events to the configured webhooks. The events are sent asynchronously by Run.
+// A nil Notifier discards all the events, so that it can be used when no webhook is configured.
+type Notifier struct {
+	sinks   []*sink
+	client  *http.Client
+	eventCh chan Event
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewNotifier returns a Notifier sending the events to the webhooks described by configs, or nil if
+// configs is empty.
+func NewNotifier(configs []SinkConfig) (*Notifier, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	n := &Notifier{
+		client:  &http.Client{Timeout: requestTimeout},
+		eventCh: make(chan Event, eventQueueSize),
+	}
+	for i, config := range configs {
+		u, err := url.Parse(config.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook %d: invalid URL, it must be an http or https URL with a host", i)
+		}
+		s := &sink{url: config.URL, redactedURL: redactURL(u)}
+		if len(config.Events) > 0 {
+			s.events = make(map[string]bool, len(config.Events))
+			for _, event := range config.Events {
+				if event != EventTraceflowDropped && event != EventPolicyDropRateExceeded {
+					return nil, fmt.Errorf("webhook %d: unknown event type %q", i, event)
+				}
+				s.events[event] = true
+			}
+		}
+		if config.Template != "" {
+			s.template, err = template.New(fmt.Sprintf("webhook%d", i)).Funcs(templateFuncs).Parse(config.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %d: invalid template: %v", i, err)
+			}
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	return n, nil
+}
+
+// Notify queues the event to be sent to the webhooks. It never blocks: the event is discarded if too
+// many events are waiting to be sent.
+func (n *Notifier) Notify(event Event) {
+	if n == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case n.eventCh <- event:
+	default:
+		klog.Warningf("Too many webhook events waiting to be sent, discarding %s event: %s", event.Type, event.Message)
+	}
+}
+
+// Run sends the queued events to the webhooks until stopCh is closed.
+func (n *Notifier) Run(stopCh <-chan struct{}) {
+	klog.Info("Starting webhook notifier")
+	defer klog.Info("Shutting down webhook notifier")
+
+	for {
+		select {
+		case event := <-n.eventCh:
+			for _, s := range n.sinks {
+				if s.events != nil && !s.events[event.Type] {
+					continue
+				}
+				if err := n.send(s, event); err != nil {
+					klog.Errorf("Failed to send %s event to webhook %s: %v", event.Type, s.redactedURL, err)
+				}
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (n *Notifier) send(s *sink, event Event) error {
+	body, err := s.render(event)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			// The error includes the URL of the request.
+			urlErr.URL = s.redactedURL
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// redactURL returns the URL without its user info, path and query, which may include secrets.
+func redactURL(u *url.URL) string {
+	redacted := &url.URL{Scheme: u.Scheme, Host: u.Host}
+	if u.User != nil || u.Path != "" || u.RawQuery != "" {
+		redacted.Path = "/REDACTED"
+	}
+	return redacted.String()
+}
+
+// render returns the body of the request sending the event to the webhook.
+func (s *sink) render(event Event) ([]byte, error) {
+	if s.template == nil {
+		return json.Marshal(event)
+	}
+	var buf bytes.Buffer
+	if err := s.template.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -15,14 +15,15 @@
 package main
 
 import (
-	"context"
+	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/vmware-tanzu/octant/pkg/plugin/service"
 	"github.com/vmware-tanzu/octant/pkg/view/component"
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -60,14 +61,20 @@ func (p *antreaOctantPlugin) agentHandler(request service.Request) (component.Co
 
 // getControllerTable gets the table for displaying Controller information
 func (p *antreaOctantPlugin) getControllerTable(request service.Request) *component.Table {
-	controllers, err := p.client.ClusterinformationV1beta1().AntreaControllerInfos().List(context.TODO(), v1.ListOptions{
-		ResourceVersion: "0",
-	})
+	controllerCols := component.NewTableCols(versionCol, podCol, nodeCol, serviceCol, clusterInfoCrdCol, heartbeatCol)
+	if p.clientErr != nil {
+		return component.NewTableWithRows(controllerTitle, p.getClientErrText(), controllerCols, nil)
+	}
+	controllers, err := p.controllerInfoLister.List(labels.Everything())
 	if err != nil {
-		log.Fatalf("Failed to get AntreaControllerInfos %v", err)
+		log.Printf("Failed to get AntreaControllerInfos %v", err)
+		return component.NewTableWithRows(controllerTitle, fmt.Sprintf("Failed to get AntreaControllerInfos: %v", err), controllerCols, nil)
 	}
+	sort.Slice(controllers, func(i, j int) bool {
+		return controllers[i].Name < controllers[j].Name
+	})
 	controllerRows := make([]component.TableRow, 0)
-	for _, controller := range controllers.Items {
+	for _, controller := range controllers {
 		controllerRows = append(controllerRows, component.TableRow{
 			versionCol: component.NewText(controller.Version),
 			podCol: component.NewLink(controller.PodRef.Name, controller.PodRef.Name,
@@ -81,20 +88,25 @@ func (p *antreaOctantPlugin) getControllerTable(request service.Request) *compon
 			heartbeatCol: component.NewText(controller.ControllerConditions[0].LastHeartbeatTime.String()),
 		})
 	}
-	controllerCols := component.NewTableCols(versionCol, podCol, nodeCol, serviceCol, clusterInfoCrdCol, heartbeatCol)
 	return component.NewTableWithRows(controllerTitle, "We couldn't find any Antrea controllers!", controllerCols, controllerRows)
 }
 
 // getAgentTable gets the table for displaying Agent information.
 func (p *antreaOctantPlugin) getAgentTable(request service.Request) *component.Table {
-	agents, err := p.client.ClusterinformationV1beta1().AntreaAgentInfos().List(context.TODO(), v1.ListOptions{
-		ResourceVersion: "0",
-	})
+	agentCols := component.NewTableCols(versionCol, podCol, nodeCol, subnetsCol, bridgeCol, podNumCol, clusterInfoCrdCol, heartbeatCol)
+	if p.clientErr != nil {
+		return component.NewTableWithRows(agentTitle, p.getClientErrText(), agentCols, nil)
+	}
+	agents, err := p.agentInfoLister.List(labels.Everything())
 	if err != nil {
-		log.Fatalf("Failed to get AntreaAgentInfos %v", err)
+		log.Printf("Failed to get AntreaAgentInfos %v", err)
+		return component.NewTableWithRows(agentTitle, fmt.Sprintf("Failed to get AntreaAgentInfos: %v", err), agentCols, nil)
 	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Name < agents[j].Name
+	})
 	agentRows := make([]component.TableRow, 0)
-	for _, agent := range agents.Items {
+	for _, agent := range agents {
 		agentRows = append(agentRows, component.TableRow{
 			versionCol: component.NewText(agent.Version),
 			podCol: component.NewLink(agent.PodRef.Name, agent.PodRef.Name,
@@ -109,6 +121,5 @@ func (p *antreaOctantPlugin) getAgentTable(request service.Request) *component.T
 			heartbeatCol: component.NewText(agent.AgentConditions[0].LastHeartbeatTime.String()),
 		})
 	}
-	agentCols := component.NewTableCols(versionCol, podCol, nodeCol, subnetsCol, bridgeCol, podNumCol, clusterInfoCrdCol, heartbeatCol)
 	return component.NewTableWithRows(agentTitle, "We couldn't find any Antrea agents!", agentCols, agentRows)
 }
@@ -0,0 +1,225 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	clusterinformationv1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/clusterinformation/v1beta1"
+)
+
+const (
+	dashboardTitle       = "Dashboard"
+	controllerCardTitle  = "Antrea Controller"
+	agentsCardTitle      = "Antrea Agents"
+	policiesCardTitle    = "NetworkPolicies"
+	agentVersionsTitle   = "Agent Versions"
+	unhealthyAgentsTitle = "Unhealthy Agents"
+
+	agentNumCol = "Agent Num"
+	issuesCol   = "Issues"
+
+	// heartbeatTimeout is the duration after which a component whose monitoring CRD has not been updated is
+	// considered unhealthy. The monitoring CRDs are updated every minute.
+	heartbeatTimeout = 3 * time.Minute
+)
+
+// getAgentHeartbeatTime returns the last heartbeat time of the monitoring CRD of an Antrea Agent, or the zero time
+// if it has never been reported.
+func getAgentHeartbeatTime(agent *clusterinformationv1beta1.AntreaAgentInfo) time.Time {
+	for _, condition := range agent.AgentConditions {
+		if condition.Type == clusterinformationv1beta1.AgentHealthy {
+			return condition.LastHeartbeatTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// getAgentIssues returns the reasons why an Antrea Agent is considered unhealthy. An empty slice is returned if the
+// Agent is healthy.
+func getAgentIssues(agent *clusterinformationv1beta1.AntreaAgentInfo, now time.Time) []string {
+	var issues []string
+	if heartbeat := getAgentHeartbeatTime(agent); now.Sub(heartbeat) > heartbeatTimeout {
+		issues = append(issues, "no heartbeat for more than "+heartbeatTimeout.String())
+	}
+	for _, condition := range agent.AgentConditions {
+		switch condition.Type {
+		case clusterinformationv1beta1.ControllerConnectionUp,
+			clusterinformationv1beta1.OVSDBConnectionUp,
+			clusterinformationv1beta1.OpenflowConnectionUp:
+			if condition.Status != corev1.ConditionTrue {
+				issues = append(issues, fmt.Sprintf("%s is %s", condition.Type, condition.Status))
+			}
+		case clusterinformationv1beta1.MemoryPressure:
+			if condition.Status == corev1.ConditionTrue {
+				issues = append(issues, fmt.Sprintf("%s is %s", condition.Type, condition.Status))
+			}
+		}
+	}
+	return issues
+}
+
+// isControllerHealthy returns whether the Antrea Controller has reported a heartbeat recently.
+func isControllerHealthy(controller *clusterinformationv1beta1.AntreaControllerInfo, now time.Time) bool {
+	for _, condition := range controller.ControllerConditions {
+		if condition.Type == clusterinformationv1beta1.ControllerHealthy {
+			return condition.Status == corev1.ConditionTrue && now.Sub(condition.LastHeartbeatTime.Time) <= heartbeatTimeout
+		}
+	}
+	return false
+}
+
+// newSummaryCard creates a card which displays a short summary in Markdown.
+func newSummaryCard(title string, lines ...string) *component.Card {
+	card := component.NewCard(component.TitleFromString(title))
+	card.SetBody(component.NewMarkdownText(strings.Join(lines, "\n\n")))
+	return card
+}
+
+// getSummaryCards gets the cards summarizing the state of the Antrea components.
+func getSummaryCards(controllers []*clusterinformationv1beta1.AntreaControllerInfo,
+	agents []*clusterinformationv1beta1.AntreaAgentInfo, now time.Time) []*component.Card {
+	controllerCard := newSummaryCard(controllerCardTitle, "No Antrea Controller found")
+	policiesCard := newSummaryCard(policiesCardTitle, "Unknown")
+	if len(controllers) > 0 {
+		controller := controllers[0]
+		status := "Healthy"
+		if !isControllerHealthy(controller, now) {
+			status = "**Unhealthy**"
+		}
+		controllerCard = newSummaryCard(controllerCardTitle,
+			"Status: "+status,
+			"Version: "+controller.Version,
+			"Connected Agents: "+strconv.Itoa(int(controller.ConnectedAgentNum)))
+		npInfo := controller.NetworkPolicyControllerInfo
+		policiesCard = newSummaryCard(policiesCardTitle,
+			"NetworkPolicies: "+strconv.Itoa(int(npInfo.NetworkPolicyNum)),
+			"AddressGroups: "+strconv.Itoa(int(npInfo.AddressGroupNum)),
+			"AppliedToGroups: "+strconv.Itoa(int(npInfo.AppliedToGroupNum)))
+	}
+	unhealthy := 0
+	for _, agent := range agents {
+		if len(getAgentIssues(agent, now)) > 0 {
+			unhealthy++
+		}
+	}
+	agentsCard := newSummaryCard(agentsCardTitle,
+		"Agents: "+strconv.Itoa(len(agents)),
+		"Healthy: "+strconv.Itoa(len(agents)-unhealthy),
+		"Unhealthy: "+strconv.Itoa(unhealthy))
+	return []*component.Card{controllerCard, agentsCard, policiesCard}
+}
+
+// getAgentVersionTable gets the table displaying the number of Antrea Agents running each version.
+func getAgentVersionTable(agents []*clusterinformationv1beta1.AntreaAgentInfo) *component.Table {
+	cols := component.NewTableCols(versionCol, agentNumCol)
+	versions := map[string]int{}
+	for _, agent := range agents {
+		versions[agent.Version]++
+	}
+	sortedVersions := make([]string, 0, len(versions))
+	for version := range versions {
+		sortedVersions = append(sortedVersions, version)
+	}
+	sort.Strings(sortedVersions)
+	rows := make([]component.TableRow, 0, len(sortedVersions))
+	for _, version := range sortedVersions {
+		rows = append(rows, component.TableRow{
+			versionCol:  component.NewText(version),
+			agentNumCol: component.NewText(strconv.Itoa(versions[version])),
+		})
+	}
+	return component.NewTableWithRows(agentVersionsTitle, "We couldn't find any Antrea agents!", cols, rows)
+}
+
+// getUnhealthyAgentTable gets the table displaying the Antrea Agents which are unhealthy and why.
+func getUnhealthyAgentTable(agents []*clusterinformationv1beta1.AntreaAgentInfo, now time.Time) *component.Table {
+	cols := component.NewTableCols(nodeCol, podCol, issuesCol, heartbeatCol)
+	rows := make([]component.TableRow, 0)
+	for _, agent := range agents {
+		issues := getAgentIssues(agent, now)
+		if len(issues) == 0 {
+			continue
+		}
+		heartbeat := "Never"
+		if t := getAgentHeartbeatTime(agent); !t.IsZero() {
+			heartbeat = t.String()
+		}
+		rows = append(rows, component.TableRow{
+			nodeCol: component.NewLink(agent.NodeRef.Name, agent.NodeRef.Name,
+				"/cluster-overview/nodes/"+agent.NodeRef.Name),
+			podCol: component.NewLink(agent.PodRef.Name, agent.PodRef.Name,
+				"/overview/namespace/"+agent.PodRef.Namespace+"/workloads/pods/"+agent.PodRef.Name),
+			issuesCol:    component.NewText(strings.Join(issues, ", ")),
+			heartbeatCol: component.NewText(heartbeat),
+		})
+	}
+	return component.NewTableWithRows(unhealthyAgentsTitle, "All Antrea agents are healthy.", cols, rows)
+}
+
+// dashboardHandler handles the layout of the Antrea Dashboard page, which summarizes the health of the Antrea
+// components based on their monitoring CRDs.
+func (p *antreaOctantPlugin) dashboardHandler(request service.Request) (component.ContentResponse, error) {
+	if p.clientErr != nil {
+		return component.ContentResponse{
+			Title:      component.TitleFromString(dashboardTitle),
+			Components: []component.Component{component.NewText(p.getClientErrText())},
+		}, nil
+	}
+	controllers, err := p.controllerInfoLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to get AntreaControllerInfos %v", err)
+		return component.EmptyContentResponse, err
+	}
+	agents, err := p.agentInfoLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to get AntreaAgentInfos %v", err)
+		return component.EmptyContentResponse, err
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Name < agents[j].Name
+	})
+	now := time.Now()
+
+	layout := flexlayout.New()
+	cardSection := layout.AddSection()
+	for _, card := range getSummaryCards(controllers, agents, now) {
+		if err := cardSection.Add(card, component.WidthThird); err != nil {
+			log.Printf("Failed to load a card in dashboard page, err: %v", err)
+			return component.EmptyContentResponse, err
+		}
+	}
+	tableSection := layout.AddSection()
+	for _, table := range []*component.Table{getUnhealthyAgentTable(agents, now), getAgentVersionTable(agents)} {
+		if err := tableSection.Add(table, component.WidthFull); err != nil {
+			log.Printf("Failed to load a table in dashboard page, err: %v", err)
+			return component.EmptyContentResponse, err
+		}
+	}
+	resp := component.NewContentResponse(component.TitleFromString(dashboardTitle))
+	resp.Components = append(resp.Components, layout.ToComponent(dashboardTitle))
+	return *resp, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build demo
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	crdv1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/clusterinformation/v1beta1"
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+)
+
+// demoTraceflowDelay is how long a Traceflow created in demo mode keeps running before it succeeds.
+const demoTraceflowDelay = 3 * time.Second
+
+var demoNodes = []string{"demo-node-1", "demo-node-2"}
+
+// demoFlows are the synthetic flows of the OVS bridge served for all Nodes in demo mode.
+var demoFlows = []string{
+	"cookie=0x1000000000000, table=0, n_packets=1520, n_bytes=112480, priority=200,in_port=2 actions=load:0x2->NXM_NX_REG0[0..15],goto_table:10",
+	"cookie=0x1000000000000, table=0, n_packets=870, n_bytes=64380, priority=190,in_port=3 actions=load:0x2->NXM_NX_REG0[0..15],goto_table:10",
+	"cookie=0x1000000000000, table=0, n_packets=0, n_bytes=0, priority=0 actions=drop",
+	"cookie=0x1000000000000, table=10, n_packets=2390, n_bytes=176860, priority=200,ip,in_port=3,dl_src=6e:58:d3:7a:3b:0a,nw_src=10.10.0.2 actions=goto_table:30",
+	"cookie=0x1000000000000, table=10, n_packets=0, n_bytes=0, priority=0 actions=drop",
+	"cookie=0x1000000000000, table=30, n_packets=2390, n_bytes=176860, priority=200,ip actions=ct(table=31,zone=65520,nat)",
+	"cookie=0x1000000000000, table=31, n_packets=0, n_bytes=0, priority=190,ct_state=+inv+trk,ip actions=drop",
+	"cookie=0x1000000000000, table=31, n_packets=2390, n_bytes=176860, priority=0 actions=resubmit(,40)",
+	"cookie=0x1000000000000, table=40, n_packets=2390, n_bytes=176860, priority=0 actions=resubmit(,50)",
+	"cookie=0x1000000000000, table=50, n_packets=2390, n_bytes=176860, priority=0 actions=resubmit(,70)",
+	"cookie=0x1000000000000, table=70, n_packets=2390, n_bytes=176860, priority=0 actions=resubmit(,80)",
+	"cookie=0x1000000000000, table=80, n_packets=2390, n_bytes=176860, priority=200,dl_dst=6e:58:d3:7a:3b:0a actions=load:0x3->NXM_NX_REG1[],load:0x1->NXM_NX_REG0[16],resubmit(,90)",
+	"cookie=0x1000000000000, table=90, n_packets=2390, n_bytes=176860, priority=0 actions=resubmit(,105)",
+	"cookie=0x1000000000000, table=105, n_packets=2390, n_bytes=176860, priority=190,ct_state=+new+trk,ip actions=ct(commit,table=110,zone=65520)",
+	"cookie=0x1000000000000, table=110, n_packets=2390, n_bytes=176860, priority=200,ip,reg0=0x10000/0x10000 actions=output:NXM_NX_REG1[]",
+}
+
+// newDemoClients creates fake clients serving synthetic Antrea components, Pods and Traceflows. Traceflows created
+// through the returned client are completed with synthetic results after demoTraceflowDelay.
+func newDemoClients() (clientset.Interface, kubernetes.Interface, error) {
+	now := v1.Now()
+	var k8sObjects, crdObjects []runtime.Object
+	crdObjects = append(crdObjects, &crdv1beta1.AntreaControllerInfo{
+		ObjectMeta: v1.ObjectMeta{Name: "antrea-controller"},
+		Version:    "demo",
+		PodRef:     corev1.ObjectReference{Namespace: "kube-system", Name: "antrea-controller-demo"},
+		NodeRef:    corev1.ObjectReference{Name: demoNodes[0]},
+		ServiceRef: corev1.ObjectReference{Namespace: "kube-system", Name: "antrea"},
+		ControllerConditions: []crdv1beta1.ControllerCondition{
+			{Type: crdv1beta1.ControllerHealthy, Status: corev1.ConditionTrue, LastHeartbeatTime: now},
+		},
+	})
+	for i, node := range demoNodes {
+		crdObjects = append(crdObjects, &crdv1beta1.AntreaAgentInfo{
+			ObjectMeta:  v1.ObjectMeta{Name: node},
+			Version:     "demo",
+			PodRef:      corev1.ObjectReference{Namespace: "kube-system", Name: "antrea-agent-" + node},
+			NodeRef:     corev1.ObjectReference{Name: node},
+			NodeSubnets: []string{fmt.Sprintf("10.10.%d.0/24", i)},
+			OVSInfo:     crdv1beta1.OVSInfo{BridgeName: "br-int"},
+			LocalPodNum: 1,
+			AgentConditions: []crdv1beta1.AgentCondition{
+				{Type: crdv1beta1.AgentHealthy, Status: corev1.ConditionTrue, LastHeartbeatTime: now},
+			},
+		})
+		k8sObjects = append(k8sObjects, &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("web-%d", i)},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{PodIP: fmt.Sprintf("10.10.%d.2", i)},
+		})
+	}
+	k8sObjects = append(k8sObjects,
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "kube-system"}},
+	)
+	demoTf := &opsv1alpha1.Traceflow{
+		ObjectMeta: v1.ObjectMeta{Name: "demo-traceflow", CreationTimestamp: now},
+		Spec: opsv1alpha1.TraceflowSpec{
+			Source:      opsv1alpha1.Source{Namespace: "default", Pod: "web-0"},
+			Destination: opsv1alpha1.Destination{Namespace: "default", Pod: "web-1"},
+			Packet:      opsv1alpha1.Packet{IPHeader: opsv1alpha1.IPHeader{Protocol: opsv1alpha1.TCPProtocol}},
+		},
+	}
+	demoTf.Status = getDemoTraceflowStatus(demoTf)
+	crdObjects = append(crdObjects, demoTf)
+
+	client := fake.NewSimpleClientset(crdObjects...)
+	client.PrependReactor("create", "traceflows", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		tf := action.(k8stesting.CreateAction).GetObject().(*opsv1alpha1.Traceflow)
+		tf.CreationTimestamp = v1.Now()
+		tf.Status.Phase = opsv1alpha1.Running
+		go completeDemoTraceflow(client, tf.Name)
+		// Let the default reactor store the Traceflow.
+		return false, nil, nil
+	})
+	return client, k8sfake.NewSimpleClientset(k8sObjects...), nil
+}
+
+// getDemoFlows gets the synthetic flows served for all Nodes in demo mode.
+func getDemoFlows() ([]string, error) {
+	return demoFlows, nil
+}
+
+// completeDemoTraceflow sets synthetic results in the status of the provided Traceflow after demoTraceflowDelay.
+func completeDemoTraceflow(client clientset.Interface, name string) {
+	time.Sleep(demoTraceflowDelay)
+	tf, err := client.OpsV1alpha1().Traceflows().Get(context.TODO(), name, v1.GetOptions{})
+	if err != nil {
+		log.Printf("Failed to get demo traceflow \"%s\", err: %s", name, err)
+		return
+	}
+	tf.Status = getDemoTraceflowStatus(tf)
+	if _, err := client.OpsV1alpha1().Traceflows().UpdateStatus(context.TODO(), tf, v1.UpdateOptions{}); err != nil {
+		log.Printf("Failed to update demo traceflow \"%s\", err: %s", name, err)
+	}
+}
+
+// getDemoTraceflowStatus gets the synthetic status of a succeeded Traceflow. The packet is delivered to the other
+// Node, unless the destination is an IP, in which case it is forwarded out of the overlay by the source Node.
+func getDemoTraceflowStatus(tf *opsv1alpha1.Traceflow) opsv1alpha1.TraceflowStatus {
+	senderResult := opsv1alpha1.NodeResult{
+		Node:      demoNodes[0],
+		Timestamp: time.Now().Unix(),
+		Observations: []opsv1alpha1.Observation{
+			{Component: opsv1alpha1.SpoofGuard, Action: opsv1alpha1.Forwarded},
+			{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "EgressRule", Action: opsv1alpha1.Forwarded},
+		},
+	}
+	if tf.Spec.Destination.IP != "" {
+		senderResult.Observations = append(senderResult.Observations, opsv1alpha1.Observation{
+			Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.ForwardedOutOfOverlay,
+		})
+		return opsv1alpha1.TraceflowStatus{
+			Phase:   opsv1alpha1.Succeeded,
+			Results: []opsv1alpha1.NodeResult{senderResult},
+		}
+	}
+	senderResult.Observations = append(senderResult.Observations, opsv1alpha1.Observation{
+		Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Forwarded, TunnelDstIP: "192.168.77.102",
+	})
+	receiverResult := opsv1alpha1.NodeResult{
+		Node:      demoNodes[1],
+		Timestamp: time.Now().Unix(),
+		Observations: []opsv1alpha1.Observation{
+			{Component: opsv1alpha1.Forwarding, ComponentInfo: "Classification", Action: opsv1alpha1.Received},
+			{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "IngressRule", Action: opsv1alpha1.Forwarded},
+			{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Delivered},
+		},
+	}
+	return opsv1alpha1.TraceflowStatus{
+		Phase:   opsv1alpha1.Succeeded,
+		Results: []opsv1alpha1.NodeResult{senderResult, receiverResult},
+	}
+}
@@ -15,18 +15,29 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/vmware-tanzu/octant/pkg/navigation"
 	"github.com/vmware-tanzu/octant/pkg/plugin"
 	"github.com/vmware-tanzu/octant/pkg/plugin/service"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 
 	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
 	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	clusterinformationlisters "github.com/vmware-tanzu/antrea/pkg/client/listers/clusterinformation/v1beta1"
+	opslisters "github.com/vmware-tanzu/antrea/pkg/client/listers/ops/v1alpha1"
 )
 
 var (
@@ -35,45 +46,132 @@ var (
 
 const (
 	title = "Antrea"
+	// demoModeEnvKey is the environment variable which enables the demo mode when set to "true". In demo mode, the
+	// plugin serves synthetic data and does not need a cluster, which is useful to iterate on UI changes offline.
+	demoModeEnvKey = "ANTREA_OCTANT_PLUGIN_DEMO"
 )
 
 type antreaOctantPlugin struct {
-	client *clientset.Clientset
+	client    clientset.Interface
+	k8sClient kubernetes.Interface
+	// kubeconfig is used to create the clients of the Antrea Agent APIs. It is nil in demo mode.
+	kubeconfig *rest.Config
+	// clientErr is set when the clients could not be created, in which case the
+	// plugin runs in a degraded state and only displays the error.
+	clientErr error
+	// The listers are backed by shared informer caches, so that the pages
+	// and the Traceflow form do not list the resources from the K8s
+	// apiserver every time they are displayed.
+	informerFactory      crdinformers.SharedInformerFactory
+	k8sInformerFactory   informers.SharedInformerFactory
+	tfLister             opslisters.TraceflowLister
+	controllerInfoLister clusterinformationlisters.AntreaControllerInfoLister
+	agentInfoLister      clusterinformationlisters.AntreaAgentInfoLister
+	namespaceLister      corelisters.NamespaceLister
+	podLister            corelisters.PodLister
+	// mutex protects graph and lastTf, which are updated both by the action
+	// handlers and by the Traceflow event handlers, pipelineGraph, and the
+	// selected Namespaces.
+	mutex  sync.Mutex
 	graph  string
 	lastTf *opsv1alpha1.Traceflow
+	// srcNamespace and dstNamespace are the Namespaces selected for the
+	// source and destination Pods of the Traceflow form.
+	srcNamespace string
+	dstNamespace string
+	// pipelineGraph is the graph of the OVS pipeline of the Node selected in
+	// the OVS Pipeline page.
+	pipelineGraph string
 }
 
-func newAntreaOctantPlugin() *antreaOctantPlugin {
+// newAntreaOctantPlugin creates the plugin with the provided clients. If clientErr is not nil, the plugin runs in
+// degraded state and the clients are not used.
+func newAntreaOctantPlugin(client clientset.Interface, k8sClient kubernetes.Interface, kubeconfig *rest.Config, clientErr error) *antreaOctantPlugin {
+	a := &antreaOctantPlugin{
+		client:     client,
+		k8sClient:  k8sClient,
+		kubeconfig: kubeconfig,
+		clientErr:  clientErr,
+		graph:      "",
+		lastTf: &opsv1alpha1.Traceflow{
+			ObjectMeta: v1.ObjectMeta{Name: ""},
+		},
+		srcNamespace: v1.NamespaceDefault,
+		dstNamespace: v1.NamespaceDefault,
+	}
+	if clientErr != nil {
+		return a
+	}
+
+	// Watch Traceflows so that their status and the graph of the selected
+	// Traceflow are refreshed as soon as they are updated.
+	a.informerFactory = crdinformers.NewSharedInformerFactory(a.client, 0)
+	tfInformer := a.informerFactory.Ops().V1alpha1().Traceflows()
+	a.tfLister = tfInformer.Lister()
+	tfInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: a.updateTraceflow,
+	})
+	a.controllerInfoLister = a.informerFactory.Clusterinformation().V1beta1().AntreaControllerInfos().Lister()
+	a.agentInfoLister = a.informerFactory.Clusterinformation().V1beta1().AntreaAgentInfos().Lister()
+	a.k8sInformerFactory = informers.NewSharedInformerFactory(a.k8sClient, 0)
+	a.namespaceLister = a.k8sInformerFactory.Core().V1().Namespaces().Lister()
+	a.podLister = a.k8sInformerFactory.Core().V1().Pods().Lister()
+	return a
+}
+
+// createClients creates the clients from the kubeconfig file provided with the KUBECONFIG environment variable,
+// or from the default kubeconfig file. The parsed kubeconfig is returned too.
+func createClients() (clientset.Interface, kubernetes.Interface, *rest.Config, error) {
 	kubeconfig := os.Getenv("KUBECONFIG")
 	if kubeconfig == "" {
 		kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
 	}
-	// Create a k8s client.
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
-		log.Fatalf("Failed to build kubeConfig %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to build kubeConfig: %v", err)
 	}
 	client, err := clientset.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Failed to create K8s client for %s: %v", pluginName, err)
+		return nil, nil, nil, err
 	}
-
-	return &antreaOctantPlugin{
-		client: client,
-		graph:  "",
-		lastTf: &opsv1alpha1.Traceflow{
-			ObjectMeta: v1.ObjectMeta{Name: ""},
-		},
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	return client, k8sClient, config, nil
+}
+
+// getClientErrText gets the text displayed in place of the cluster resources when the plugin runs in degraded state.
+func (p *antreaOctantPlugin) getClientErrText() string {
+	return fmt.Sprintf("Failed to connect to the cluster, check the kubeconfig file of Octant: %v", p.clientErr)
 }
 
 func main() {
 	// Remove the prefix from the go logger since Octant will print logs with timestamps.
 	log.SetPrefix("")
-	a := newAntreaOctantPlugin()
+	var client clientset.Interface
+	var k8sClient kubernetes.Interface
+	var kubeconfig *rest.Config
+	var err error
+	if os.Getenv(demoModeEnvKey) == "true" {
+		log.Printf("%s is running in demo mode, synthetic data is served", pluginName)
+		client, k8sClient, err = newDemoClients()
+	} else {
+		client, k8sClient, kubeconfig, err = createClients()
+	}
+	if err != nil {
+		log.Printf("Failed to create K8s clients for %s, running in degraded state: %v", pluginName, err)
+	}
+	a := newAntreaOctantPlugin(client, k8sClient, kubeconfig, err)
+	// The caches are not waited for, so that the plugin is registered without delay.
+	// Resources are displayed once they have been listed.
+	if a.informerFactory != nil {
+		a.informerFactory.Start(wait.NeverStop)
+		a.k8sInformerFactory.Start(wait.NeverStop)
+	}
 
 	capabilities := &plugin.Capabilities{
-		ActionNames: []string{addTfAction, showGraphAction},
+		ActionNames: []string{addTfAction, showGraphAction, deleteTfAction, rerunTfAction, selectNamespacesAction, showPipelineAction},
 		IsModule:    true,
 	}
 
@@ -104,6 +202,11 @@ func (p *antreaOctantPlugin) handleNavigation(request *service.NavigationRequest
 				Path:     request.GeneratePath("components/overview"),
 				IconName: "folder",
 			},
+			{
+				Title:    "Dashboard",
+				Path:     request.GeneratePath("components/dashboard"),
+				IconName: "folder",
+			},
 			{
 				Title:    "Controller Info",
 				Path:     request.GeneratePath("components/controller"),
@@ -119,6 +222,11 @@ func (p *antreaOctantPlugin) handleNavigation(request *service.NavigationRequest
 				Path:     request.GeneratePath("components/traceflow"),
 				IconName: "folder",
 			},
+			{
+				Title:    "OVS Pipeline",
+				Path:     request.GeneratePath("components/pipeline"),
+				IconName: "folder",
+			},
 		},
 		IconName: "cloud",
 	}, nil
@@ -130,6 +238,9 @@ func (p *antreaOctantPlugin) initRoutes(router *service.Router) {
 	router.HandleFunc("", p.overviewHandler)
 	router.HandleFunc("/components/overview", p.overviewHandler)
 
+	// Click on navigation child named Dashboard to display a summary of the health of Antrea components.
+	router.HandleFunc("/components/dashboard", p.dashboardHandler)
+
 	// Click on navigation child named Controller Info to display Controller information.
 	router.HandleFunc("/components/controller", p.controllerHandler)
 
@@ -138,4 +249,7 @@ func (p *antreaOctantPlugin) initRoutes(router *service.Router) {
 
 	// Click on navigation child named "Antrea Traceflow"/"Tracelist" to display Antrea Traceflow information.
 	router.HandleFunc("/components/traceflow", p.traceflowHandler)
+
+	// Click on navigation child named OVS Pipeline to display the OVS pipeline of a Node.
+	router.HandleFunc("/components/pipeline", p.pipelineHandler)
 }
@@ -0,0 +1,37 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !demo
+
+package main
+
+import (
+	"errors"
+
+	"k8s.io/client-go/kubernetes"
+
+	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+)
+
+// errDemoModeNotBuilt is returned when the demo mode is enabled but the fake clients were not compiled in, so that
+// release binaries do not embed them.
+var errDemoModeNotBuilt = errors.New("demo mode requires antrea-octant-plugin to be built with -tags demo")
+
+func newDemoClients() (clientset.Interface, kubernetes.Interface, error) {
+	return nil, nil, errDemoModeNotBuilt
+}
+
+func getDemoFlows() ([]string, error) {
+	return nil, errDemoModeNotBuilt
+}
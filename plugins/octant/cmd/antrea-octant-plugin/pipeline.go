@@ -0,0 +1,200 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovsflows"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/noderoute"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/graphviz"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+var (
+	showPipelineAction = "pipeline/showPipeline"
+)
+
+const (
+	pipelineTitle = "OVS Pipeline"
+)
+
+// getNodeChoices gets the choices of Nodes running an Antrea Agent.
+func (p *antreaOctantPlugin) getNodeChoices() []component.InputChoice {
+	if p.clientErr != nil {
+		return nil
+	}
+	agents, err := p.agentInfoLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to list AntreaAgentInfos: %v", err)
+		return nil
+	}
+	names := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		names = append(names, agent.NodeRef.Name)
+	}
+	sort.Strings(names)
+	choices := make([]component.InputChoice, len(names))
+	for i, name := range names {
+		choices[i] = component.InputChoice{
+			Label:   name,
+			Value:   name,
+			Checked: false,
+		}
+	}
+	return choices
+}
+
+// getAgentFlows gets the flows of the OVS bridge of a Node from the API of the Antrea Agent running on the Node. The
+// Agent API is reached through the IP of the Node, with the credentials of the kubeconfig.
+func (p *antreaOctantPlugin) getAgentFlows(ctx context.Context, nodeName string) ([]string, error) {
+	if p.kubeconfig == nil {
+		return getDemoFlows()
+	}
+	agent, err := p.agentInfoLister.Get(nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the AntreaAgentInfo of Node %s: %v", nodeName, err)
+	}
+	node, err := p.k8sClient.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Node %s: %v", nodeName, err)
+	}
+	nodeIP, err := noderoute.GetNodeAddr(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the IP of Node %s: %v", nodeName, err)
+	}
+	cfg := rest.CopyConfig(p.kubeconfig)
+	cfg.Host = net.JoinHostPort(nodeIP.String(), strconv.Itoa(agent.APIPort))
+	// The Agent API is served with a self-signed certificate.
+	cfg.Insecure = true
+	cfg.CAFile = ""
+	cfg.CAData = nil
+	cfg.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	client, err := rest.UnversionedRESTClientFor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the client of the Antrea Agent on Node %s: %v", nodeName, err)
+	}
+	data, err := client.Get().AbsPath("/ovsflows").DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the flows of Node %s: %v", nodeName, err)
+	}
+	var resps []ovsflows.Response
+	if err := json.Unmarshal(data, &resps); err != nil {
+		return nil, fmt.Errorf("failed to decode the flows of Node %s: %v", nodeName, err)
+	}
+	flows := make([]string, len(resps))
+	for i := range resps {
+		flows[i] = resps[i].Flow
+	}
+	return flows, nil
+}
+
+// genPipelineGraph generates the graph of the OVS pipeline of a Node from its flows.
+func genPipelineGraph(nodeName string, flows []string) (string, error) {
+	tables, err := graphviz.ParsePipelineTables(flows)
+	if err != nil {
+		return "", err
+	}
+	for _, table := range tables {
+		table.Name = openflow.GetFlowTableName(binding.TableIDType(table.ID))
+	}
+	return graphviz.GenPipelineGraph(nodeName, tables)
+}
+
+// showPipeline handles the action of the "Show Pipeline" button, and generates the graph of the OVS pipeline of the
+// chosen Node.
+func (p *antreaOctantPlugin) showPipeline(request *service.ActionRequest) {
+	sendErrorAlert := func(message string) {
+		alert := action.CreateAlert(action.AlertTypeError, message, action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+	}
+	nodeName, err := getSelectedChoice(request.Payload, nodeCol)
+	if err != nil {
+		log.Printf("Failed to get Node choice: %s", err)
+		sendErrorAlert("Invalid Node choice, please check your input and submit again.")
+		return
+	}
+	flows, err := p.getAgentFlows(request.Context(), nodeName)
+	if err != nil {
+		log.Printf("Failed to get OVS flows: %s", err)
+		sendErrorAlert(fmt.Sprintf("Failed to get OVS flows, err: %s", err))
+		return
+	}
+	graph, err := genPipelineGraph(nodeName, flows)
+	if err != nil {
+		log.Printf("Failed to generate OVS pipeline graph of Node \"%s\", err: %s", nodeName, err)
+		sendErrorAlert(fmt.Sprintf("Failed to generate OVS pipeline graph, err: %s", err))
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pipelineGraph = graph
+}
+
+// pipelineHandler handlers the layout of OVS Pipeline page.
+func (p *antreaOctantPlugin) pipelineHandler(request service.Request) (component.ContentResponse, error) {
+	layout := flexlayout.New()
+	card := component.NewCard(component.TitleFromString(pipelineTitle))
+	form := component.Form{Fields: []component.FormField{
+		component.NewFormFieldSelect(nodeCol, nodeCol, p.getNodeChoices(), false),
+		component.NewFormFieldHidden("action", showPipelineAction),
+	}}
+	card.SetBody(component.NewText("The graph shows the flow tables of the OVS bridge of a Node, with their number of flows " +
+		"and packets, and the tables to which their flows send packets."))
+	card.AddAction(component.Action{
+		Name:  "Show Pipeline",
+		Title: "Show Pipeline",
+		Form:  form,
+	})
+
+	p.mutex.Lock()
+	graph := p.pipelineGraph
+	p.mutex.Unlock()
+	listSection := layout.AddSection()
+	if err := listSection.Add(card, component.WidthFull); err != nil {
+		log.Printf("Failed to add card to section: %s", err)
+		return component.EmptyContentResponse, nil
+	}
+	if graph != "" {
+		graphCard := component.NewCard(component.TitleFromString("OVS Pipeline Graph"))
+		graphCard.SetBody(component.NewGraphviz(graph))
+		if err := listSection.Add(graphCard, component.WidthFull); err != nil {
+			log.Printf("Failed to add graphCard to section: %s", err)
+			return component.EmptyContentResponse, nil
+		}
+	}
+	return component.ContentResponse{
+		Title: component.TitleFromString(pipelineTitle),
+		Components: []component.Component{
+			layout.ToComponent(pipelineTitle),
+		},
+	}, nil
+}
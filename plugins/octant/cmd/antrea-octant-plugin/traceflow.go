@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"net"
 	"sort"
 	"strconv"
@@ -29,14 +30,19 @@ import (
 	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
 
 	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
 	"github.com/vmware-tanzu/antrea/pkg/graphviz"
 )
 
 var (
-	addTfAction     = "traceflow/addTf"
-	showGraphAction = "traceflow/showGraphAction"
+	addTfAction            = "traceflow/addTf"
+	showGraphAction        = "traceflow/showGraphAction"
+	deleteTfAction         = "traceflow/deleteTf"
+	rerunTfAction          = "traceflow/rerunTf"
+	selectNamespacesAction = "traceflow/selectNamespaces"
 )
 
 const (
@@ -50,10 +56,15 @@ const (
 	srcPortCol      = "Source Port"
 	dstTypeCol      = "Destination Type"
 	dstNamespaceCol = "Destination Namespace"
+	dstPodCol       = "Destination Pod"
 	dstCol          = "Destination"
 	dstPortCol      = "Destination Port"
 	protocolCol     = "Protocol"
+	tcpFlagsCol     = "TCP Flags"
+	icmpTypeCol     = "ICMP Type"
+	icmpCodeCol     = "ICMP Code"
 	phaseCol        = "Phase"
+	resultCol       = "Result"
 	ageCol          = "Age"
 	traceNameCol    = "Trace Name"
 
@@ -88,48 +99,144 @@ func getDstType(tf *opsv1alpha1.Traceflow) string {
 	return ""
 }
 
-// actionHandler handlers clicks and actions from "Start New Trace" and "Generate Trace Graph" buttons.
+// getSelectedChoice gets the value chosen by users in a dropdown field of a form.
+func getSelectedChoice(payload action.Payload, key string) (string, error) {
+	values, err := payload.StringSlice(key)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 || values[0] == "" {
+		return "", fmt.Errorf("no value is chosen for %s", key)
+	}
+	return values[0], nil
+}
+
+// getSelectedPod gets the Namespace and name of the Pod chosen by users in a dropdown field of a form.
+func getSelectedPod(payload action.Payload, key string) (string, string, error) {
+	value, err := getSelectedChoice(payload, key)
+	if err != nil {
+		return "", "", err
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(value)
+	if err != nil {
+		return "", "", err
+	}
+	if namespace == "" {
+		return "", "", fmt.Errorf("invalid Pod choice %s for %s", value, key)
+	}
+	return namespace, name, nil
+}
+
+// getOptionalUint8 gets the value of an optional number field of a form, which must fit in one byte. false is returned
+// if the field is empty.
+func getOptionalUint8(payload action.Payload, key string) (uint8, bool, error) {
+	value, err := payload.Uint16(key)
+	if err != nil {
+		return 0, false, nil
+	}
+	if value > math.MaxUint8 {
+		return 0, false, fmt.Errorf("value %d of %s is larger than %d", value, key, math.MaxUint8)
+	}
+	return uint8(value), true, nil
+}
+
+// getNamespaceChoices gets the choices of Namespaces existing in the cluster. The selected Namespace is checked.
+func (p *antreaOctantPlugin) getNamespaceChoices(selected string) []component.InputChoice {
+	if p.clientErr != nil {
+		return nil
+	}
+	namespaces, err := p.namespaceLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to list Namespaces: %v", err)
+		return nil
+	}
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	choices := make([]component.InputChoice, len(names))
+	for i, name := range names {
+		choices[i] = component.InputChoice{
+			Label:   name,
+			Value:   name,
+			Checked: name == selected,
+		}
+	}
+	return choices
+}
+
+// getPodChoices gets the choices of Pods existing in the Namespace. The value of a choice is in "namespace/name"
+// format, so that the Pod can be checked against the chosen Namespace when the form is submitted. Pods which are
+// not assigned an IP yet and hostNetwork Pods are skipped as they cannot be used in a Traceflow.
+func (p *antreaOctantPlugin) getPodChoices(namespace string) []component.InputChoice {
+	if p.clientErr != nil {
+		return nil
+	}
+	pods, err := p.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to list Pods in Namespace %s: %v", namespace, err)
+		return nil
+	}
+	keys := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || pod.Status.PodIP == "" {
+			continue
+		}
+		keys = append(keys, pod.Namespace+"/"+pod.Name)
+	}
+	sort.Strings(keys)
+	choices := make([]component.InputChoice, len(keys))
+	for i, key := range keys {
+		choices[i] = component.InputChoice{
+			Label:   key,
+			Value:   key,
+			Checked: false,
+		}
+	}
+	return choices
+}
+
+// actionHandler handlers clicks and actions from "Start New Trace" and "Generate Trace Graph" buttons, and from the
+// "Show Pipeline" button of the OVS Pipeline page.
 func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error {
 	actionName, err := request.Payload.String("action")
 	if err != nil {
 		log.Printf("Failed to get input at string: %s", err)
 		return nil
 	}
+	if p.clientErr != nil {
+		alert := action.CreateAlert(action.AlertTypeError, p.getClientErrText(), action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+		return nil
+	}
 
 	switch actionName {
 	case addTfAction:
-		srcNamespace, err := request.Payload.String(srcNamespaceCol)
+		srcNamespace, err := getSelectedChoice(request.Payload, srcNamespaceCol)
 		if err != nil {
 			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"failed to get srcNamespace as string: %s", err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get source namespace as "+
-				"string: %s", err), action.DefaultAlertExpiration)
-			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
-			return nil
-		}
-		if errs := validation.ValidateNamespaceName(srcNamespace, false); len(errs) != 0 {
-			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"failed to validate source namespace string %s, errs: %#v", srcNamespace, errs)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid source namespace string, "+
+				"failed to get srcNamespace choice: %s", err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid source namespace choice, "+
 				"please check your input and submit again."), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
 
-		srcPod, err := request.Payload.String(srcPodCol)
+		srcPodNamespace, srcPod, err := getSelectedPod(request.Payload, srcPodCol)
 		if err != nil {
 			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"failed to get srcPod as string: %s", err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get source pod as "+
-				"string: %s", err), action.DefaultAlertExpiration)
+				"failed to get srcPod choice: %s", err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid source pod choice, "+
+				"please check your input and submit again."), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		if errs := validation.NameIsDNSSubdomain(srcPod, false); len(errs) != 0 {
+		if srcPodNamespace != srcNamespace {
 			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"failed to validate source pod string %s, errs: %#v", srcPod, errs)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid source pod string, "+
-				"please check your input and submit again."), action.DefaultAlertExpiration)
+				"source pod %s is not in source namespace %s", srcPod, srcNamespace)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Source pod %s is not in namespace %s, "+
+				"please check your input and submit again.", srcPod, srcNamespace), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
@@ -144,7 +251,7 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		dst, err := request.Payload.String(dstCol)
+		dst, err := request.Payload.OptionalString(dstCol)
 		if err != nil {
 			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
 				"failed to get dst as string: %s", err)
@@ -153,34 +260,29 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		dstNamespace, err := request.Payload.OptionalString(dstNamespaceCol)
-		if err != nil {
-			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"failed to get dstNamespace as string: %s", err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get destination namespace as "+
-				"string: %s", err), action.DefaultAlertExpiration)
-			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
-			return nil
-		}
+		// The destination Namespace is not required when the destination is an IP.
+		dstNamespace, _ := getSelectedChoice(request.Payload, dstNamespaceCol)
 		var destination opsv1alpha1.Destination
 		switch dstType[0] {
 		case opsv1alpha1.DstTypePod:
-			if errs := validation.NameIsDNSSubdomain(dst, false); len(errs) != 0 {
+			dstPodNamespace, dstPod, err := getSelectedPod(request.Payload, dstPodCol)
+			if err != nil {
 				log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-					"failed to validate destination pod string %s, errs: %#v", dst, errs)
-				alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid destination pod string, "+
+					"failed to get dstPod choice: %s", err)
+				alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid destination pod choice, "+
 					"please check your input and submit again."), action.DefaultAlertExpiration)
 				request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 				return nil
 			}
-			if errs := validation.ValidateNamespaceName(dstNamespace, false); len(errs) != 0 {
+			if dstPodNamespace != dstNamespace {
 				log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-					"failed to validate destination namespace string %s, errs: %#v", dstNamespace, errs)
-				alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid destination namespace string, "+
-					"please check your input and submit again."), action.DefaultAlertExpiration)
+					"destination pod %s is not in destination namespace %s", dstPod, dstNamespace)
+				alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Destination pod %s is not in namespace %s, "+
+					"please check your input and submit again.", dstPod, dstNamespace), action.DefaultAlertExpiration)
 				request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 				return nil
 			}
+			dst = dstPod
 			destination = opsv1alpha1.Destination{
 				Namespace: dstNamespace,
 				Pod:       dst,
@@ -240,6 +342,23 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 			hasDstPort = false
 		}
 
+		// The TCP flags and the ICMP type and code are optional too.
+		var headerValues [3]uint8
+		var hasHeaderValues [3]bool
+		for i, col := range []string{tcpFlagsCol, icmpTypeCol, icmpCodeCol} {
+			headerValues[i], hasHeaderValues[i], err = getOptionalUint8(request.Payload, col)
+			if err != nil {
+				log.Printf("Invalid user input, CRD creation or Traceflow request may fail: %s", err)
+				alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid %s, "+
+					"please check your input and submit again.", col), action.DefaultAlertExpiration)
+				request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+				return nil
+			}
+		}
+		tcpFlags, hasTCPFlags := headerValues[0], hasHeaderValues[0]
+		icmpType, hasICMPType := headerValues[1], hasHeaderValues[1]
+		icmpCode := headerValues[2]
+
 		protocol, err := request.Payload.StringSlice(protocolCol)
 		if err != nil || len(protocol) == 0 {
 			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
@@ -250,24 +369,7 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 			return nil
 		}
 
-		// Judge whether the name of trace flow is duplicated.
-		// If it is, then the user creates more than one traceflows in one second, which is not allowed.
-		tfName := srcPod + "-" + dst + "-" + time.Now().Format(TIME_FORMAT_YYYYMMDD_HHMMSS)
-		ctx := context.Background()
-		tfOld, _ := p.client.OpsV1alpha1().Traceflows().Get(ctx, tfName, v1.GetOptions{})
-		if tfOld.Name == tfName {
-			log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
-				"duplicate traceflow \"%s\": same source pod and destination pod in less than one second: %+v. ", tfName, tfOld)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Duplicate traceflow: same source pod "+
-				"and destination pod in less than one second"), action.DefaultAlertExpiration)
-			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
-			return nil
-		}
-
 		tf := &opsv1alpha1.Traceflow{
-			ObjectMeta: v1.ObjectMeta{
-				Name: tfName,
-			},
 			Spec: opsv1alpha1.TraceflowSpec{
 				Source: opsv1alpha1.Source{
 					Namespace: srcNamespace,
@@ -288,6 +390,9 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 				tf.Spec.Packet.TransportHeader.TCP = &opsv1alpha1.TCPHeader{
 					Flags: 2,
 				}
+				if hasTCPFlags {
+					tf.Spec.Packet.TransportHeader.TCP.Flags = int32(tcpFlags)
+				}
 				if hasSrcPort {
 					tf.Spec.Packet.TransportHeader.TCP.SrcPort = int32(srcPort)
 				}
@@ -310,55 +415,70 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 				tf.Spec.Packet.TransportHeader.ICMP = &opsv1alpha1.ICMPEchoRequestHeader{
 					ID:       0,
 					Sequence: 0,
+					Code:     int32(icmpCode),
+				}
+				if hasICMPType {
+					t := int32(icmpType)
+					tf.Spec.Packet.TransportHeader.ICMP.Type = &t
 				}
 			}
 		}
 		log.Printf("Get user input successfully, traceflow: %+v", tf)
-		tf, err = p.client.OpsV1alpha1().Traceflows().Create(ctx, tf, v1.CreateOptions{})
+		p.createTraceflow(request, tf)
+		return nil
+	case showGraphAction:
+		name, err := request.Payload.String(traceNameCol)
 		if err != nil {
-			log.Printf("Failed to create traceflow CRD \"%s\", err: %s", tfName, err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to create traceflow CRD, "+
-				"err: %s", err), action.DefaultAlertExpiration)
+			log.Printf("Failed to get name at string: %s", err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get graph name as "+
+				"string: %s", err), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		log.Printf("Create traceflow CRD \"%s\" successfully, Traceflow Results: %+v", tfName, tf)
-		alert := action.CreateAlert(action.AlertTypeSuccess, fmt.Sprintf("Traceflow \"%s\" is created successfully",
-			tfName), action.DefaultAlertExpiration)
-		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
-		// Automatically delete the traceflow CRD after created for 300s(5min).
-		go func(tfName string) {
-			age := time.Second * 300
-			time.Sleep(age)
-			err := p.client.OpsV1alpha1().Traceflows().Delete(context.Background(), tfName, v1.DeleteOptions{})
-			if err != nil {
-				log.Printf("Failed to delete traceflow CRD \"%s\", err: %s", tfName, err)
-				return
-			}
-			log.Printf("Deleted traceflow CRD \"%s\" successfully after %.0f seconds", tfName, age.Seconds())
-		}(tf.Name)
-		p.lastTf = tf
-		p.graph, err = graphviz.GenGraph(p.lastTf)
+		// Invoke GenGraph to show. GenGraph may modify the Traceflow, so the
+		// object from the informer cache must be copied.
+		tf, err := p.tfLister.Get(name)
 		if err != nil {
-			log.Printf("Failed to generate traceflow graph \"%s\", err: %s", tfName, err)
+			log.Printf("Failed to get traceflow CRD \"%s\", err: %s ", name, err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get traceflow CRD, "+
+				"err: %s ", err), action.DefaultAlertExpiration)
+			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+			return nil
+		}
+		log.Printf("Get traceflow CRD \"%s\" successfully, Traceflow Results: %+v", name, tf)
+		if err := p.selectTraceflow(tf.DeepCopy()); err != nil {
+			log.Printf("Failed to generate traceflow graph \"%s\", err: %s", name, err)
 			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to generate traceflow graph, "+
 				"err: %s", err), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
 		return nil
-	case showGraphAction:
+	case deleteTfAction:
 		name, err := request.Payload.String(traceNameCol)
 		if err != nil {
 			log.Printf("Failed to get name at string: %s", err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get graph name as "+
-				"string: %s", err), action.DefaultAlertExpiration)
+			return nil
+		}
+		if err := p.client.OpsV1alpha1().Traceflows().Delete(context.Background(), name, v1.DeleteOptions{}); err != nil {
+			log.Printf("Failed to delete traceflow CRD \"%s\", err: %s", name, err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to delete traceflow CRD, "+
+				"err: %s", err), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		// Invoke GenGraph to show
-		ctx := context.Background()
-		tf, err := p.client.OpsV1alpha1().Traceflows().Get(ctx, name, v1.GetOptions{})
+		log.Printf("Deleted traceflow CRD \"%s\" successfully", name)
+		alert := action.CreateAlert(action.AlertTypeSuccess, fmt.Sprintf("Traceflow \"%s\" is deleted successfully",
+			name), action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+		return nil
+	case rerunTfAction:
+		name, err := request.Payload.String(traceNameCol)
+		if err != nil {
+			log.Printf("Failed to get name at string: %s", err)
+			return nil
+		}
+		tfOld, err := p.tfLister.Get(name)
 		if err != nil {
 			log.Printf("Failed to get traceflow CRD \"%s\", err: %s ", name, err)
 			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to get traceflow CRD, "+
@@ -366,16 +486,39 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
-		log.Printf("Get traceflow CRD \"%s\" successfully, Traceflow Results: %+v", name, tf)
-		p.lastTf = tf
-		p.graph, err = graphviz.GenGraph(p.lastTf)
+		// A new Traceflow is created with the same spec, as the spec of a
+		// Traceflow cannot be updated.
+		tf := &opsv1alpha1.Traceflow{
+			Spec: *tfOld.Spec.DeepCopy(),
+		}
+		log.Printf("Re-running traceflow \"%s\": %+v", name, tf)
+		p.createTraceflow(request, tf)
+		return nil
+	case showPipelineAction:
+		p.showPipeline(request)
+		return nil
+	case selectNamespacesAction:
+		// The Pod choices of the Traceflow form are filtered by the selected Namespaces.
+		srcNamespace, err := getSelectedChoice(request.Payload, srcNamespaceCol)
 		if err != nil {
-			log.Printf("Failed to generate traceflow graph \"%s\", err: %s", name, err)
-			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to generate traceflow graph, "+
-				"err: %s", err), action.DefaultAlertExpiration)
+			log.Printf("Failed to get srcNamespace choice: %s", err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid source namespace choice, "+
+				"please check your input and submit again."), action.DefaultAlertExpiration)
+			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+			return nil
+		}
+		dstNamespace, err := getSelectedChoice(request.Payload, dstNamespaceCol)
+		if err != nil {
+			log.Printf("Failed to get dstNamespace choice: %s", err)
+			alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Invalid destination namespace choice, "+
+				"please check your input and submit again."), action.DefaultAlertExpiration)
 			request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
 			return nil
 		}
+		p.mutex.Lock()
+		p.srcNamespace = srcNamespace
+		p.dstNamespace = dstNamespace
+		p.mutex.Unlock()
 		return nil
 	default:
 		log.Fatalf("Failed to find defined handler after receiving action request for %s", pluginName)
@@ -383,6 +526,53 @@ func (p *antreaOctantPlugin) actionHandler(request *service.ActionRequest) error
 	}
 }
 
+// createTraceflow creates the Traceflow, whose name is generated from its source and destination, and selects it
+// so that its graph is displayed. The Traceflow is deleted automatically after 5 minutes.
+func (p *antreaOctantPlugin) createTraceflow(request *service.ActionRequest, tf *opsv1alpha1.Traceflow) {
+	// Judge whether the name of trace flow is duplicated.
+	// If it is, then the user creates more than one traceflows in one second, which is not allowed.
+	tfName := tf.Spec.Source.Pod + "-" + getDstName(tf) + "-" + time.Now().Format(TIME_FORMAT_YYYYMMDD_HHMMSS)
+	if tfOld, err := p.tfLister.Get(tfName); err == nil {
+		log.Printf("Invalid user input, CRD creation or Traceflow request may fail: "+
+			"duplicate traceflow \"%s\": same source pod and destination pod in less than one second: %+v. ", tfName, tfOld)
+		alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Duplicate traceflow: same source pod "+
+			"and destination pod in less than one second"), action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+		return
+	}
+	tf.Name = tfName
+
+	tf, err := p.client.OpsV1alpha1().Traceflows().Create(context.Background(), tf, v1.CreateOptions{})
+	if err != nil {
+		log.Printf("Failed to create traceflow CRD \"%s\", err: %s", tfName, err)
+		alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to create traceflow CRD, "+
+			"err: %s", err), action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+		return
+	}
+	log.Printf("Create traceflow CRD \"%s\" successfully, Traceflow Results: %+v", tfName, tf)
+	alert := action.CreateAlert(action.AlertTypeSuccess, fmt.Sprintf("Traceflow \"%s\" is created successfully",
+		tfName), action.DefaultAlertExpiration)
+	request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+	// Automatically delete the traceflow CRD after created for 300s(5min).
+	go func(tfName string) {
+		age := time.Second * 300
+		time.Sleep(age)
+		err := p.client.OpsV1alpha1().Traceflows().Delete(context.Background(), tfName, v1.DeleteOptions{})
+		if err != nil {
+			log.Printf("Failed to delete traceflow CRD \"%s\", err: %s", tfName, err)
+			return
+		}
+		log.Printf("Deleted traceflow CRD \"%s\" successfully after %.0f seconds", tfName, age.Seconds())
+	}(tf.Name)
+	if err := p.selectTraceflow(tf); err != nil {
+		log.Printf("Failed to generate traceflow graph \"%s\", err: %s", tfName, err)
+		alert := action.CreateAlert(action.AlertTypeError, fmt.Sprintf("Failed to generate traceflow graph, "+
+			"err: %s", err), action.DefaultAlertExpiration)
+		request.DashboardClient.SendAlert(request.Context(), request.ClientID, alert)
+	}
+}
+
 // traceflowHandler handlers the layout of Traceflow page.
 func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (component.ContentResponse, error) {
 	layout := flexlayout.New()
@@ -418,15 +608,29 @@ func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (componen
 		i++
 	}
 
+	// Construct the available values of Namespaces and Pods from the cluster, so that only existing
+	// Namespaces and Pods can be chosen. As the choices of a form cannot depend on each other, the
+	// Namespaces are selected with a separate action, and only the Pods in the selected Namespaces
+	// are offered.
+	p.mutex.Lock()
+	srcNamespace, dstNamespace := p.srcNamespace, p.dstNamespace
+	p.mutex.Unlock()
+	srcNamespaceSelect := p.getNamespaceChoices(srcNamespace)
+	dstNamespaceSelect := p.getNamespaceChoices(dstNamespace)
+
 	form := component.Form{Fields: []component.FormField{
-		component.NewFormFieldText(srcNamespaceCol, srcNamespaceCol, ""),
-		component.NewFormFieldText(srcPodCol, srcPodCol, ""),
+		component.NewFormFieldSelect(srcNamespaceCol, srcNamespaceCol, srcNamespaceSelect, false),
+		component.NewFormFieldSelect(srcPodCol, srcPodCol, p.getPodChoices(srcNamespace), false),
 		component.NewFormFieldNumber(srcPortCol, srcPortCol, ""),
 		component.NewFormFieldSelect(dstTypeCol, dstTypeCol, dstTypeSelect, false),
-		component.NewFormFieldText(dstNamespaceCol+" (Not required when destination is an IP)", dstNamespaceCol, ""),
-		component.NewFormFieldText(dstCol, dstCol, ""),
+		component.NewFormFieldSelect(dstNamespaceCol+" (Not required when destination is an IP)", dstNamespaceCol, dstNamespaceSelect, false),
+		component.NewFormFieldSelect(dstPodCol+" (Only required when destination is a Pod)", dstPodCol, p.getPodChoices(dstNamespace), false),
+		component.NewFormFieldText(dstCol+" (Service name or IP, not required when destination is a Pod)", dstCol, ""),
 		component.NewFormFieldNumber(dstPortCol, dstPortCol, ""),
 		component.NewFormFieldSelect(protocolCol, protocolCol, protocolSelect, false),
+		component.NewFormFieldNumber(tcpFlagsCol+" (Only for TCP, default is 2 - SYN)", tcpFlagsCol, ""),
+		component.NewFormFieldNumber(icmpTypeCol+" (Only for ICMP, default is echo request)", icmpTypeCol, ""),
+		component.NewFormFieldNumber(icmpCodeCol+" (Only for ICMP, default is 0)", icmpCodeCol, ""),
 		component.NewFormFieldHidden("action", addTfAction),
 	}}
 	addTf := component.Action{
@@ -434,6 +638,16 @@ func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (componen
 		Title: "Start New Trace",
 		Form:  form,
 	}
+	namespacesForm := component.Form{Fields: []component.FormField{
+		component.NewFormFieldSelect(srcNamespaceCol, srcNamespaceCol, srcNamespaceSelect, false),
+		component.NewFormFieldSelect(dstNamespaceCol, dstNamespaceCol, dstNamespaceSelect, false),
+		component.NewFormFieldHidden("action", selectNamespacesAction),
+	}}
+	selectNamespaces := component.Action{
+		Name:  "Select Namespaces",
+		Title: "Select Namespaces of Pods",
+		Form:  namespacesForm,
+	}
 	graphForm := component.Form{Fields: []component.FormField{
 		component.NewFormFieldText(traceNameCol, traceNameCol, ""),
 		component.NewFormFieldHidden("action", showGraphAction),
@@ -443,37 +657,19 @@ func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (componen
 		Title: "Generate Trace Graph",
 		Form:  graphForm,
 	}
-	card.SetBody(component.NewText(""))
+	card.SetBody(component.NewText(fmt.Sprintf("Source Pods are in Namespace %s and destination Pods are in Namespace %s.", srcNamespace, dstNamespace)))
+	card.AddAction(selectNamespaces)
 	card.AddAction(addTf)
 	card.AddAction(genGraph)
 
 	graphCard := component.NewCard(component.TitleFromString("Antrea Traceflow Graph"))
-	if p.lastTf.Name != "" {
-		// Invoke GenGraph to show
-		log.Printf("Generating content from CRD...")
-		ctx := context.Background()
-		tf, err := p.client.OpsV1alpha1().Traceflows().Get(ctx, p.lastTf.Name, v1.GetOptions{})
-		if err != nil {
-			log.Printf("Failed to get latest CRD, using traceflow results cache, last traceflow name: %s, err: %s", p.lastTf.Name, err)
-			p.graph, err = graphviz.GenGraph(p.lastTf)
-			if err != nil {
-				log.Printf("Failed to generate traceflow graph \"%s\", err: %s", p.lastTf.Name, err)
-				return component.EmptyContentResponse, nil
-			}
-			log.Printf("Generated content from CRD cache successfully, last traceflow name: %s", p.lastTf.Name)
-		} else {
-			p.lastTf = tf
-			p.graph, err = graphviz.GenGraph(p.lastTf)
-			if err != nil {
-				log.Printf("Failed to generate traceflow graph \"%s\", err: %s", p.lastTf.Name, err)
-				return component.EmptyContentResponse, nil
-			}
-			log.Printf("Generated content from latest CRD successfully, last traceflow name %s", p.lastTf.Name)
-		}
-		log.Printf("Traceflow Results: %+v", p.lastTf)
-	}
-	if p.graph != "" {
-		graphCard.SetBody(component.NewGraphviz(p.graph))
+	// The graph of the selected Traceflow is regenerated by updateTraceflow
+	// whenever the Traceflow is updated, so it can be displayed as is.
+	p.mutex.Lock()
+	graph := p.graph
+	p.mutex.Unlock()
+	if graph != "" {
+		graphCard.SetBody(component.NewGraphviz(graph))
 	} else {
 		graphCard.SetBody(component.NewText(""))
 	}
@@ -483,7 +679,7 @@ func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (componen
 		log.Printf("Failed to add card to section: %s", err)
 		return component.EmptyContentResponse, nil
 	}
-	if p.graph != "" {
+	if graph != "" {
 		err = listSection.Add(graphCard, component.WidthFull)
 		if err != nil {
 			log.Printf("Failed to add graphCard to section: %s", err)
@@ -505,31 +701,110 @@ func (p *antreaOctantPlugin) traceflowHandler(request service.Request) (componen
 	return resp, nil
 }
 
+// selectTraceflow sets the Traceflow whose graph is displayed and generates its graph.
+func (p *antreaOctantPlugin) selectTraceflow(tf *opsv1alpha1.Traceflow) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.lastTf = tf
+	graph, err := graphviz.GenGraph(tf)
+	if err != nil {
+		return err
+	}
+	p.graph = graph
+	return nil
+}
+
+// updateTraceflow regenerates the graph of the selected Traceflow when it is updated, e.g. when
+// its observations are populated, so that users do not need to regenerate it manually.
+func (p *antreaOctantPlugin) updateTraceflow(oldObj, newObj interface{}) {
+	// GenGraph may modify the Traceflow, so the object from the informer cache must be copied.
+	tf := newObj.(*opsv1alpha1.Traceflow).DeepCopy()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if tf.Name != p.lastTf.Name || tf.ResourceVersion == p.lastTf.ResourceVersion {
+		return
+	}
+	graph, err := graphviz.GenGraph(tf)
+	if err != nil {
+		log.Printf("Failed to generate traceflow graph \"%s\", err: %s", tf.Name, err)
+		return
+	}
+	p.lastTf = tf
+	p.graph = graph
+	log.Printf("Regenerated graph of updated traceflow \"%s\", phase: %s", tf.Name, tf.Status.Phase)
+}
+
+// getTraceflowResult gets a short description of the result of a Traceflow.
+func getTraceflowResult(tf *opsv1alpha1.Traceflow) string {
+	switch tf.Status.Phase {
+	case opsv1alpha1.Failed:
+		return tf.Status.Reason
+	case opsv1alpha1.Succeeded:
+		var last *opsv1alpha1.Observation
+		for i := range tf.Status.Results {
+			for j := range tf.Status.Results[i].Observations {
+				o := &tf.Status.Results[i].Observations[j]
+				if o.Action == opsv1alpha1.Dropped {
+					return fmt.Sprintf("%s by %s", o.Action, o.Component)
+				}
+				if o.Action == opsv1alpha1.Delivered || o.Action == opsv1alpha1.ForwardedOutOfOverlay {
+					last = o
+				}
+			}
+		}
+		if last != nil {
+			return string(last.Action)
+		}
+	}
+	return ""
+}
+
 // getTfTable gets the table for displaying Traceflow information
 func (p *antreaOctantPlugin) getTfTable(request service.Request) *component.Table {
-	ctx := context.Background()
-	tfs, err := p.client.OpsV1alpha1().Traceflows().List(ctx, v1.ListOptions{ResourceVersion: "0"})
+	tfCols := component.NewTableCols(tfNameCol, srcNamespaceCol, srcPodCol, dstNamespaceCol, dstTypeCol, dstCol, protocolCol, phaseCol, resultCol, ageCol)
+	if p.clientErr != nil {
+		return component.NewTableWithRows(traceflowTitle, p.getClientErrText(), tfCols, nil)
+	}
+	tfs, err := p.tfLister.List(labels.Everything())
 	if err != nil {
-		log.Fatalf("Failed to get Traceflows %v", err)
-		return nil
+		log.Printf("Failed to list Traceflows: %v", err)
+		tfs = nil
 	}
-	sort.Slice(tfs.Items, func(p, q int) bool {
-		return tfs.Items[p].CreationTimestamp.Unix() > tfs.Items[q].CreationTimestamp.Unix()
+	sort.Slice(tfs, func(p, q int) bool {
+		return tfs[p].CreationTimestamp.Unix() > tfs[q].CreationTimestamp.Unix()
 	})
 	tfRows := make([]component.TableRow, 0)
-	for _, tf := range tfs.Items {
-		tfRows = append(tfRows, component.TableRow{
+	for _, tf := range tfs {
+		tfRow := component.TableRow{
 			tfNameCol:       component.NewLink(tf.Name, tf.Name, octantTraceflowCRDPath+tf.Name),
 			srcNamespaceCol: component.NewText(tf.Spec.Source.Namespace),
 			srcPodCol:       component.NewText(tf.Spec.Source.Pod),
 			dstNamespaceCol: component.NewText(tf.Spec.Destination.Namespace),
-			dstTypeCol:      component.NewText(getDstType(&tf)),
-			dstCol:          component.NewText(getDstName(&tf)),
+			dstTypeCol:      component.NewText(getDstType(tf)),
+			dstCol:          component.NewText(getDstName(tf)),
 			protocolCol:     component.NewText(opsv1alpha1.ProtocolsToString[tf.Spec.Packet.IPHeader.Protocol]),
 			phaseCol:        component.NewText(string(tf.Status.Phase)),
+			resultCol:       component.NewText(getTraceflowResult(tf)),
 			ageCol:          component.NewTimestamp(tf.CreationTimestamp.Time),
+		}
+		// Row actions to re-run the trace with the same input and to delete it.
+		tfRow.AddAction(component.GridAction{
+			Name:       "Re-run",
+			ActionPath: rerunTfAction,
+			Payload:    action.Payload{"action": rerunTfAction, traceNameCol: tf.Name},
+			Type:       component.GridActionPrimary,
+		})
+		tfRow.AddAction(component.GridAction{
+			Name:       "Delete",
+			ActionPath: deleteTfAction,
+			Payload:    action.Payload{"action": deleteTfAction, traceNameCol: tf.Name},
+			Confirmation: &component.Confirmation{
+				Title: "Delete Traceflow",
+				Body:  fmt.Sprintf("Are you sure you want to delete Traceflow %s?", tf.Name),
+			},
+			Type: component.GridActionDanger,
 		})
+		tfRows = append(tfRows, tfRow)
 	}
-	tfCols := component.NewTableCols(tfNameCol, srcNamespaceCol, srcPodCol, dstNamespaceCol, dstTypeCol, dstCol, protocolCol, phaseCol, ageCol)
 	return component.NewTableWithRows(traceflowTitle, "We couldn't find any traceflows!", tfCols, tfRows)
 }
@@ -0,0 +1,90 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
+)
+
+func TestGetTraceflowResult(t *testing.T) {
+	forwarded := opsv1alpha1.Observation{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Forwarded}
+	delivered := opsv1alpha1.Observation{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.Delivered}
+	dropped := opsv1alpha1.Observation{Component: opsv1alpha1.NetworkPolicy, ComponentInfo: "IngressRule", Action: opsv1alpha1.Dropped}
+	outOfOverlay := opsv1alpha1.Observation{Component: opsv1alpha1.Forwarding, ComponentInfo: "Output", Action: opsv1alpha1.ForwardedOutOfOverlay}
+	tests := []struct {
+		name     string
+		status   opsv1alpha1.TraceflowStatus
+		expected string
+	}{
+		{
+			name:     "running",
+			status:   opsv1alpha1.TraceflowStatus{Phase: opsv1alpha1.Running},
+			expected: "",
+		},
+		{
+			name:     "failed",
+			status:   opsv1alpha1.TraceflowStatus{Phase: opsv1alpha1.Failed, Reason: "traceflow timeout"},
+			expected: "traceflow timeout",
+		},
+		{
+			name: "delivered",
+			status: opsv1alpha1.TraceflowStatus{
+				Phase: opsv1alpha1.Succeeded,
+				Results: []opsv1alpha1.NodeResult{
+					{Node: "node1", Observations: []opsv1alpha1.Observation{forwarded}},
+					{Node: "node2", Observations: []opsv1alpha1.Observation{delivered}},
+				},
+			},
+			expected: "Delivered",
+		},
+		{
+			name: "forwarded out of overlay",
+			status: opsv1alpha1.TraceflowStatus{
+				Phase:   opsv1alpha1.Succeeded,
+				Results: []opsv1alpha1.NodeResult{{Node: "node1", Observations: []opsv1alpha1.Observation{outOfOverlay}}},
+			},
+			expected: "ForwardedOutOfOverlay",
+		},
+		{
+			name: "dropped",
+			status: opsv1alpha1.TraceflowStatus{
+				Phase: opsv1alpha1.Succeeded,
+				Results: []opsv1alpha1.NodeResult{
+					{Node: "node1", Observations: []opsv1alpha1.Observation{forwarded}},
+					{Node: "node2", Observations: []opsv1alpha1.Observation{dropped}},
+				},
+			},
+			expected: "Dropped by NetworkPolicy",
+		},
+		{
+			name: "no final observation",
+			status: opsv1alpha1.TraceflowStatus{
+				Phase:   opsv1alpha1.Succeeded,
+				Results: []opsv1alpha1.NodeResult{{Node: "node1", Observations: []opsv1alpha1.Observation{forwarded}}},
+			},
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf := &opsv1alpha1.Traceflow{Status: tt.status}
+			assert.Equal(t, tt.expected, getTraceflowResult(tf))
+		})
+	}
+}
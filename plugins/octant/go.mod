@@ -3,6 +3,7 @@ module github.com/vmware-tanzu/antrea/plugins/octant
 go 1.15
 
 require (
+	github.com/stretchr/testify v1.6.1
 	github.com/vmware-tanzu/antrea v0.0.0
 	github.com/vmware-tanzu/octant v0.16.1
 	k8s.io/apimachinery v0.19.0-beta.2
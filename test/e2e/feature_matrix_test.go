@@ -0,0 +1,170 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/features"
+)
+
+// featureCombination is a set of features which can be enabled or disabled in
+// an existing cluster. Whether the cluster is dual-stack is not part of it, as
+// it is decided when the cluster is created: the scenarios are run for each IP
+// family supported by the cluster instead.
+type featureCombination struct {
+	antreaPolicy bool
+	antreaProxy  bool
+	egress       bool
+	ipsec        bool
+}
+
+func (c featureCombination) String() string {
+	onOff := func(enabled bool) string {
+		if enabled {
+			return "on"
+		}
+		return "off"
+	}
+	return fmt.Sprintf("AntreaPolicy=%s,AntreaProxy=%s,Egress=%s,IPsec=%s",
+		onOff(c.antreaPolicy), onOff(c.antreaProxy), onOff(c.egress), onOff(c.ipsec))
+}
+
+// featureGates returns the feature gates to set in the Antrea configuration for
+// the combination.
+func (c featureCombination) featureGates() map[string]bool {
+	return map[string]bool{
+		string(features.AntreaPolicy): c.antreaPolicy,
+		string(features.AntreaProxy):  c.antreaProxy,
+		string(features.Egress):       c.egress,
+	}
+}
+
+// generateFeatureMatrix returns all the combinations of features. Combinations
+// with IPsec enabled are only included if IPsec is supported by the cluster,
+// and they come last so that Antrea only needs to be redeployed once.
+func generateFeatureMatrix(ipsecSupported bool) []featureCombination {
+	var matrix []featureCombination
+	for i := 0; i < 16; i++ {
+		c := featureCombination{
+			antreaPolicy: i&1 != 0,
+			antreaProxy:  i&2 != 0,
+			egress:       i&4 != 0,
+			ipsec:        i&8 != 0,
+		}
+		if c.ipsec && !ipsecSupported {
+			continue
+		}
+		matrix = append(matrix, c)
+	}
+	return matrix
+}
+
+// setFeatureGates sets the provided feature gates in the Antrea configuration,
+// whether they are commented out or not. Feature gates which are not present in
+// the configuration are ignored, as not all of them apply to both components.
+func setFeatureGates(conf string, gates map[string]bool) string {
+	for name, enabled := range gates {
+		re := regexp.MustCompile(fmt.Sprintf(`(?m)^#?  %s: (true|false)$`, name))
+		conf = re.ReplaceAllString(conf, fmt.Sprintf("  %s: %t", name, enabled))
+	}
+	return conf
+}
+
+// TestFeatureMatrix runs the core datapath scenarios for each combination of
+// features, as many issues come from interactions between features which are
+// not covered by the tests of each individual feature. As Antrea needs to be
+// restarted for each combination, it is only run when the --feature-matrix flag
+// is provided.
+func TestFeatureMatrix(t *testing.T) {
+	skipIfNotFeatureMatrixTest(t)
+
+	data, err := setupTest(t)
+	if err != nil {
+		t.Fatalf("Error when setting up test: %v", err)
+	}
+	defer teardownTest(t, data)
+	// Restore the default configuration once all the combinations have been tested.
+	defer data.redeployAntrea(t, false)
+
+	ipsecSupported := testOptions.providerName != "kind" && clusterInfo.podV6NetworkCIDR == "" && clusterInfo.numNodes >= 2
+	ipsecEnabled := false
+	for _, c := range generateFeatureMatrix(ipsecSupported) {
+		t.Run(c.String(), func(t *testing.T) {
+			if c.ipsec != ipsecEnabled {
+				t.Logf("Redeploy Antrea with IPsec tunnel enabled: %t", c.ipsec)
+				data.redeployAntrea(t, c.ipsec)
+				ipsecEnabled = c.ipsec
+			}
+			if err := data.mutateAntreaConfigMap(func(data map[string]string) {
+				gates := c.featureGates()
+				data["antrea-agent.conf"] = setFeatureGates(data["antrea-agent.conf"], gates)
+				data["antrea-controller.conf"] = setFeatureGates(data["antrea-controller.conf"], gates)
+			}, true, true); err != nil {
+				t.Fatalf("Failed to configure feature gates: %v", err)
+			}
+			data.testCoreDatapathScenarios(t)
+		})
+	}
+}
+
+// testCoreDatapathScenarios runs the scenarios which are expected to succeed
+// regardless of the features enabled in the cluster.
+func (data *TestData) testCoreDatapathScenarios(t *testing.T) {
+	t.Run("PodConnectivitySameNode", func(t *testing.T) {
+		data.testPodConnectivitySameNode(t)
+	})
+	t.Run("PodConnectivityDifferentNodes", func(t *testing.T) {
+		skipIfNumNodesLessThan(t, 2)
+		data.testPodConnectivityDifferentNodes(t)
+	})
+	t.Run("ClusterIPService", func(t *testing.T) {
+		if clusterInfo.podV4NetworkCIDR != "" {
+			ipFamily := corev1.IPv4Protocol
+			data.testClusterIPServiceConnectivity(t, &ipFamily)
+		}
+		if clusterInfo.podV6NetworkCIDR != "" {
+			ipFamily := corev1.IPv6Protocol
+			data.testClusterIPServiceConnectivity(t, &ipFamily)
+		}
+	})
+	t.Run("NetworkPolicy", func(t *testing.T) {
+		checkFn, cleanupFn := data.setupDifferentNamedPorts(t)
+		defer cleanupFn()
+		checkFn()
+	})
+}
+
+// testClusterIPServiceConnectivity checks that a Pod can reach a ClusterIP
+// Service whose Endpoint is on a different Node (if there are several Nodes).
+func (data *TestData) testClusterIPServiceConnectivity(t *testing.T, ipFamily *corev1.IPFamily) {
+	_, _, cleanupServer := createAndWaitForPod(t, data, data.createNginxPodOnNode, "test-server-", nodeName(clusterInfo.numNodes-1))
+	defer cleanupServer()
+	svc, err := data.createNginxClusterIPService("", false, ipFamily)
+	if err != nil {
+		t.Fatalf("Error when creating nginx Service: %v", err)
+	}
+	defer data.deleteServiceAndWait(defaultTimeout, svc.Name)
+
+	clientName, _, cleanupClient := createAndWaitForPod(t, data, data.createBusyboxPodOnNode, "test-client-", nodeName(0))
+	defer cleanupClient()
+	if err := data.runNetcatCommandFromTestPod(clientName, svc.Spec.ClusterIP, 80); err != nil {
+		t.Errorf("Pod %s should be able to connect to Service %s (%s), but was not able to connect: %v", clientName, svc.Name, svc.Spec.ClusterIP, err)
+	}
+}
@@ -29,6 +29,12 @@ func skipIfNotBenchmarkTest(tb testing.TB) {
 	}
 }
 
+func skipIfNotFeatureMatrixTest(tb testing.TB) {
+	if !testOptions.withFeatureMatrix {
+		tb.Skipf("Skipping feature matrix test: %s", tb.Name())
+	}
+}
+
 func skipIfProviderIs(tb testing.TB, name string, reason string) {
 	if testOptions.providerName == name {
 		tb.Skipf("Skipping test for the '%s' provider: %s", name, reason)
@@ -125,6 +125,7 @@ type TestOptions struct {
 	logsExportDir       string
 	logsExportOnSuccess bool
 	withBench           bool
+	withFeatureMatrix   bool
 	enableCoverage      bool
 	coverageDir         string
 }
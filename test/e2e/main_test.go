@@ -80,6 +80,7 @@ func testMain(m *testing.M) int {
 	flag.StringVar(&testOptions.logsExportDir, "logs-export-dir", "", "Export directory for test logs")
 	flag.BoolVar(&testOptions.logsExportOnSuccess, "logs-export-on-success", false, "Export logs even when a test is successful")
 	flag.BoolVar(&testOptions.withBench, "benchtest", false, "Run tests include benchmark tests")
+	flag.BoolVar(&testOptions.withFeatureMatrix, "feature-matrix", false, "Run the core datapath tests for each combination of features")
 	flag.BoolVar(&testOptions.enableCoverage, "coverage", false, "Run tests and measure coverage")
 	flag.StringVar(&testOptions.coverageDir, "coverage-dir", "", "Directory for coverage data files")
 	flag.Parse()
@@ -561,6 +561,21 @@ func (tester *cmdAddDelTester) cmdDelTest(tc testCase, dataDir string) {
 	}
 }
 
+// newEntityUpdatesChannel returns a channel for CNIServer to send entity
+// updates to, and acts as the NetworkPolicy controller by notifying the
+// realization of the rules applied to the entities immediately.
+func newEntityUpdatesChannel() chan antreatypes.EntityReference {
+	ch := make(chan antreatypes.EntityReference, 100)
+	go func() {
+		for entity := range ch {
+			if entity.Realized != nil {
+				close(entity.Realized)
+			}
+		}
+	}()
+	return ch
+}
+
 func newTester() *cmdAddDelTester {
 	tester := &cmdAddDelTester{}
 	ifaceStore := interfacestore.NewInterfaceStore()
@@ -573,7 +588,7 @@ func newTester() *cmdAddDelTester {
 		false,
 		nil,
 		tester.networkReadyCh)
-	tester.server.Initialize(ovsServiceMock, ofServiceMock, ifaceStore, make(chan antreatypes.EntityReference, 100))
+	tester.server.Initialize(ovsServiceMock, ofServiceMock, ifaceStore, newEntityUpdatesChannel())
 	ctx := context.Background()
 	tester.ctx = ctx
 	return tester
@@ -792,7 +807,7 @@ func TestCNIServerChaining(t *testing.T) {
 			ifaceStore := interfacestore.NewInterfaceStore()
 			ovsServiceMock.EXPECT().IsHardwareOffloadEnabled().Return(false).AnyTimes()
 			ovsServiceMock.EXPECT().GetOVSDatapathType().Return(ovsconfig.OVSDatapathSystem).AnyTimes()
-			err = server.Initialize(ovsServiceMock, ofServiceMock, ifaceStore, make(chan antreatypes.EntityReference, 100))
+			err = server.Initialize(ovsServiceMock, ofServiceMock, ifaceStore, newEntityUpdatesChannel())
 			testRequire.Nil(err)
 		}
 
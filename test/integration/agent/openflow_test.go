@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"net"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/containernetworking/plugins/pkg/ip"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -108,7 +110,7 @@ func TestConnectivityFlows(t *testing.T) {
 		antrearuntime.WindowsOS = runtime.GOOS
 	}
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 	defer func() {
@@ -135,7 +137,7 @@ func TestConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsConnectivityFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -162,7 +164,7 @@ func TestReplayFlowsConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsNetworkPolicyFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -331,11 +333,149 @@ func testUninstallPodFlows(t *testing.T, config *testConfig) {
 	}
 }
 
+// TestPodQoSFlows checks that the egress traffic of a Pod, and not its ingress traffic, is sent to the QoS queue of
+// its class on the uplink port, and marked with its DSCP on each egress port.
+func TestPodQoSFlows(t *testing.T) {
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
+	err := ofTestUtils.PrepareOVSBridge(br)
+	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
+
+	_, err = c.Initialize(roundInfo, &config1.NodeConfig{PodIPv4CIDR: podIPv4CIDR, PodIPv6CIDR: podIPv6CIDR}, config1.TrafficEncapModeEncap)
+	require.Nil(t, err, "Failed to initialize OFClient")
+
+	defer func() {
+		err = c.Disconnect()
+		assert.Nil(t, err, fmt.Sprintf("Error while disconnecting from OVS bridge: %v", err))
+		err = ofTestUtils.DeleteOVSBridge(br)
+		assert.Nil(t, err, fmt.Sprintf("Error while deleting OVS bridge: %v", err))
+	}()
+
+	podOFPort := uint32(4)
+	outPorts := []uint32{config1.DefaultTunOFPort, config1.HostGatewayOFPort, config1.UplinkOFPort}
+	queuePorts := []uint32{config1.UplinkOFPort}
+	expectedFlows := []*ofTestUtils.ExpectFlow{
+		{
+			MatchStr: fmt.Sprintf("priority=201,reg0=0x10000/0x10000,reg1=0x%x,in_port=%d", config1.UplinkOFPort, podOFPort),
+			ActStr:   fmt.Sprintf("set_queue:2,output:%d", config1.UplinkOFPort),
+		},
+	}
+	require.Nil(t, c.InstallPodQoSFlows("pod1", podOFPort, 2, 0, outPorts, queuePorts), "Failed to install QoS flows")
+	flowList := ofTestUtils.CheckFlowExists(t, ovsCtlClient, uint8(ofClient.L2ForwardingOutTable), true, expectedFlows)
+	// The traffic received by the Pod, e.g. from the gateway, must not be sent to the queues, and the queues are not
+	// used for the ports without a QoS.
+	for _, flow := range flowList {
+		for _, ofPort := range []uint32{podOFPort, config1.DefaultTunOFPort, config1.HostGatewayOFPort} {
+			assert.False(t, strings.Contains(flow, fmt.Sprintf("set_queue:2,output:%d", ofPort)), "Unexpected QoS flow: %s", flow)
+		}
+	}
+
+	require.Nil(t, c.UninstallPodQoSFlows("pod1"), "Failed to uninstall QoS flows")
+	ofTestUtils.CheckFlowExists(t, ovsCtlClient, uint8(ofClient.L2ForwardingOutTable), false, expectedFlows)
+
+	// With a DSCP, the DSCP is set in the IP header of the egress traffic before it is output to each egress port,
+	// including the tunnel.
+	dscp := uint8(10)
+	var dscpFlows []*ofTestUtils.ExpectFlow
+	for _, outPort := range outPorts {
+		actStr := fmt.Sprintf("load:0x%x->NXM_OF_IP_TOS[2..7],output:%d", dscp, outPort)
+		if outPort == config1.UplinkOFPort {
+			actStr = fmt.Sprintf("load:0x%x->NXM_OF_IP_TOS[2..7],set_queue:2,output:%d", dscp, outPort)
+		}
+		for _, proto := range []string{"ip", "ipv6"} {
+			dscpFlows = append(dscpFlows, &ofTestUtils.ExpectFlow{
+				MatchStr: fmt.Sprintf("priority=201,%s,reg0=0x10000/0x10000,reg1=0x%x,in_port=%d", proto, outPort, podOFPort),
+				ActStr:   actStr,
+			})
+		}
+	}
+	require.Nil(t, c.InstallPodQoSFlows("pod1", podOFPort, 2, dscp, outPorts, queuePorts), "Failed to install QoS flows")
+	ofTestUtils.CheckFlowExists(t, ovsCtlClient, uint8(ofClient.L2ForwardingOutTable), true, dscpFlows)
+	require.Nil(t, c.UninstallPodQoSFlows("pod1"), "Failed to uninstall QoS flows")
+	ofTestUtils.CheckFlowExists(t, ovsCtlClient, uint8(ofClient.L2ForwardingOutTable), false, dscpFlows)
+}
+
+// TestPipelineReplay replays packets through the pipeline programmed by the
+// openflow client, and checks their fate, so that regressions of the pipeline
+// are detected even when the flows are individually as expected.
+func TestPipelineReplay(t *testing.T) {
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
+	err := ofTestUtils.PrepareOVSBridge(br)
+	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
+	defer func() {
+		err = c.Disconnect()
+		assert.Nil(t, err, fmt.Sprintf("Error while disconnecting from OVS bridge: %v", err))
+		err = ofTestUtils.DeleteOVSBridge(br)
+		assert.Nil(t, err, fmt.Sprintf("Error while deleting OVS bridge: %v", err))
+	}()
+
+	config := prepareConfiguration()
+	pod := config.localPods[0]
+	for name, ofPort := range map[string]uint32{
+		"tun0":   config1.DefaultTunOFPort,
+		"gw0":    config1.HostGatewayOFPort,
+		pod.name: pod.ofPort,
+	} {
+		require.NoError(t, ofTestUtils.AddOVSInternalPort(br, name, ofPort))
+	}
+	for _, f := range []func(t *testing.T, config *testConfig){
+		testInitialize,
+		testInstallGatewayFlows,
+		testInstallServiceFlows,
+		testInstallTunnelFlows,
+		testInstallNodeFlows,
+		testInstallPodFlows,
+	} {
+		f(t, config)
+	}
+
+	gwMAC := config.nodeConfig.GatewayConfig.MAC
+	peer := config.peers[0]
+	peerPodIP := ip.NextIP(ip.NextIP(peer.gateway))
+	tcs := []struct {
+		name            string
+		packet          *ofTestUtils.ReplayPacket
+		expectedVerdict ofTestUtils.Verdict
+		expectedPorts   []uint32
+	}{
+		{
+			name: "gateway to local Pod",
+			packet: &ofTestUtils.ReplayPacket{
+				InPort: strconv.Itoa(config1.HostGatewayOFPort),
+				Flow:   fmt.Sprintf("tcp,dl_src=%s,dl_dst=%s,nw_src=%s,nw_dst=%s,tp_dst=80", gwMAC, pod.mac, config.nodeConfig.GatewayConfig.IPv4, pod.ips[0]),
+			},
+			expectedVerdict: ofTestUtils.VerdictOutput,
+			expectedPorts:   []uint32{pod.ofPort},
+		},
+		{
+			name: "local Pod to remote Pod",
+			packet: &ofTestUtils.ReplayPacket{
+				InPort: strconv.Itoa(int(pod.ofPort)),
+				Flow:   fmt.Sprintf("tcp,dl_src=%s,dl_dst=%s,nw_src=%s,nw_dst=%s,tp_dst=80", pod.mac, gwMAC, pod.ips[0], peerPodIP),
+			},
+			expectedVerdict: ofTestUtils.VerdictOutput,
+			expectedPorts:   []uint32{config1.DefaultTunOFPort},
+		},
+		{
+			name: "local Pod with spoofed IP",
+			packet: &ofTestUtils.ReplayPacket{
+				InPort: strconv.Itoa(int(pod.ofPort)),
+				Flow:   fmt.Sprintf("tcp,dl_src=%s,dl_dst=%s,nw_src=%s,nw_dst=%s,tp_dst=80", pod.mac, gwMAC, ip.NextIP(pod.ips[0]), peerPodIP),
+			},
+			expectedVerdict: ofTestUtils.VerdictDrop,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ofTestUtils.CheckReplay(t, ovsCtlClient, tc.packet, tc.expectedVerdict, tc.expectedPorts...)
+		})
+	}
+}
+
 func TestNetworkPolicyFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
@@ -445,7 +585,7 @@ func TestIPv6ConnectivityFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -476,7 +616,7 @@ type svcConfig struct {
 }
 
 func TestProxyServiceFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
@@ -30,12 +30,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/net/nettest"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/ipset"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 )
 
@@ -99,6 +101,7 @@ func TestInitialize(t *testing.T) {
 		// variations
 		networkConfig        *config.NetworkConfig
 		noSNAT               bool
+		nodePortEnabled      bool
 		xtablesHoldDuration  time.Duration
 		expectNoTrackRules   bool
 		expectUDPPortInRules int
@@ -115,6 +118,7 @@ func TestInitialize(t *testing.T) {
 				TunnelType:       ovsconfig.GeneveTunnel,
 			},
 			noSNAT:               true,
+			nodePortEnabled:      true,
 			expectNoTrackRules:   true,
 			expectUDPPortInRules: 6081,
 		},
@@ -123,6 +127,7 @@ func TestInitialize(t *testing.T) {
 				TrafficEncapMode: config.TrafficEncapModeEncap,
 				TunnelType:       ovsconfig.VXLANTunnel,
 			},
+			nodePortEnabled:      true,
 			expectNoTrackRules:   true,
 			expectUDPPortInRules: 4789,
 		},
@@ -137,7 +142,8 @@ func TestInitialize(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Logf("Running Initialize test with mode %s node config %s", tc.networkConfig.TrafficEncapMode, nodeConfig)
-		routeClient, err := route.NewClient(serviceCIDR, tc.networkConfig, tc.noSNAT)
+		resetNodePortGate := featuregatetesting.SetFeatureGateDuringTest(t, features.DefaultFeatureGate, features.AntreaProxyNodePort, tc.nodePortEnabled)
+		routeClient, err := route.NewClient(serviceCIDR, tc.networkConfig, tc.noSNAT, nil)
 		assert.NoError(t, err)
 
 		var xtablesReleasedTime, initializedTime time.Time
@@ -187,6 +193,26 @@ func TestInitialize(t *testing.T) {
 		assert.Contains(t, entries, podCIDR.String(), "entry should be in ipset")
 
 		// verify iptables
+		natRules := `:ANTREA-OUTPUT - [0:0]
+:ANTREA-POSTROUTING - [0:0]
+:ANTREA-PREROUTING - [0:0]
+-A PREROUTING -m comment --comment "Antrea: jump to Antrea prerouting rules" -j ANTREA-PREROUTING
+-A OUTPUT -m comment --comment "Antrea: jump to Antrea output rules" -j ANTREA-OUTPUT
+-A POSTROUTING -m comment --comment "Antrea: jump to Antrea postrouting rules" -j ANTREA-POSTROUTING
+`
+		if tc.nodePortEnabled {
+			natRules += `-A ANTREA-OUTPUT -m comment --comment "Antrea: DNAT NodePort Service traffic to the virtual NodePort IP" -m set --match-set ANTREA-NODEPORT-IP dst,dst -j DNAT --to-destination 169.254.169.110
+-A ANTREA-POSTROUTING -d 169.254.169.110/32 -o antrea-gw0 -m comment --comment "Antrea: masquerade NodePort Service traffic" -j MASQUERADE
+`
+		}
+		if !tc.noSNAT {
+			natRules += `-A ANTREA-POSTROUTING -s 10.10.10.0/24 -m comment --comment "Antrea: masquerade pod to external packets" -m set ! --match-set ANTREA-POD-IP dst -j MASQUERADE
+`
+		}
+		if tc.nodePortEnabled {
+			natRules += `-A ANTREA-PREROUTING -m comment --comment "Antrea: DNAT NodePort Service traffic to the virtual NodePort IP" -m set --match-set ANTREA-NODEPORT-IP dst,dst -j DNAT --to-destination 169.254.169.110
+`
+		}
 		expectedIPTables := map[string]string{
 			"raw": `:ANTREA-OUTPUT - [0:0]
 :ANTREA-PREROUTING - [0:0]
@@ -198,18 +224,12 @@ func TestInitialize(t *testing.T) {
 -A ANTREA-FORWARD -i antrea-gw0 -m comment --comment "Antrea: accept packets from local pods" -j ACCEPT
 -A ANTREA-FORWARD -o antrea-gw0 -m comment --comment "Antrea: accept packets to local pods" -j ACCEPT
 `,
-			"mangle": `:ANTREA-MANGLE - [0:0]
+			"mangle": `:ANTREA-FORWARD - [0:0]
+:ANTREA-MANGLE - [0:0]
 -A PREROUTING -m comment --comment "Antrea: jump to Antrea mangle rules" -j ANTREA-MANGLE
+-A FORWARD -m comment --comment "Antrea: jump to Antrea forwarding rules" -j ANTREA-FORWARD
 `,
-			"nat": `:ANTREA-POSTROUTING - [0:0]
--A POSTROUTING -m comment --comment "Antrea: jump to Antrea postrouting rules" -j ANTREA-POSTROUTING
--A ANTREA-POSTROUTING -s 10.10.10.0/24 -m comment --comment "Antrea: masquerade pod to external packets" -m set ! --match-set ANTREA-POD-IP dst -j MASQUERADE
-`}
-
-		if tc.noSNAT {
-			expectedIPTables["nat"] = `:ANTREA-POSTROUTING - [0:0]
--A POSTROUTING -m comment --comment "Antrea: jump to Antrea postrouting rules" -j ANTREA-POSTROUTING
-`
+			"nat": natRules,
 		}
 
 		if tc.expectNoTrackRules {
@@ -230,6 +250,7 @@ func TestInitialize(t *testing.T) {
 			assert.NoError(t, err, "error executing iptables-save")
 			assert.Equal(t, expectedData, string(actualData), "mismatch iptables data in table %s", table)
 		}
+		resetNodePortGate()
 	}
 }
 
@@ -238,7 +259,7 @@ func TestIpTablesSync(t *testing.T) {
 	gwLink := createDummyGW(t)
 	defer netlink.LinkDel(gwLink)
 
-	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false)
+	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false, nil)
 	assert.Nil(t, err)
 
 	inited := make(chan struct{})
@@ -278,6 +299,29 @@ func TestIpTablesSync(t *testing.T) {
 	close(stopCh)
 }
 
+func TestSetDrainMode(t *testing.T) {
+	skipIfNotInContainer(t)
+	gwLink := createDummyGW(t)
+	defer netlink.LinkDel(gwLink)
+
+	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false, nil)
+	assert.Nil(t, err)
+	err = routeClient.Initialize(nodeConfig, func() {})
+	assert.NoError(t, err)
+
+	drainRule := `-A ANTREA-FORWARD ! -i antrea-gw0 -m comment --comment "Antrea: drop new external Service connections in drain mode" -m conntrack --ctstate NEW -m conntrack --ctstate DNAT -j DROP`
+	getMangleRules := func() string {
+		// #nosec G204: ignore in test code
+		actualData, err := exec.Command("bash", "-c", "iptables-save -t mangle | grep -i antrea").Output()
+		assert.NoError(t, err, "error executing iptables-save")
+		return string(actualData)
+	}
+	require.NoError(t, routeClient.SetDrainMode(true))
+	assert.Contains(t, getMangleRules(), drainRule)
+	require.NoError(t, routeClient.SetDrainMode(false))
+	assert.NotContains(t, getMangleRules(), drainRule)
+}
+
 func TestAddAndDeleteRoutes(t *testing.T) {
 	skipIfNotInContainer(t)
 
@@ -301,7 +345,7 @@ func TestAddAndDeleteRoutes(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Logf("Running test with mode %s peer cidr %s peer ip %s node config %s", tc.mode, tc.peerCIDR, tc.peerIP, nodeConfig)
-		routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: tc.mode}, false)
+		routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: tc.mode}, false, nil)
 		assert.NoError(t, err)
 		err = routeClient.Initialize(nodeConfig, func() {})
 		assert.NoError(t, err)
@@ -397,7 +441,7 @@ func TestReconcile(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Logf("Running test with mode %s added routes %v desired routes %v", tc.mode, tc.addedRoutes, tc.desiredPeerCIDRs)
-		routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: tc.mode}, false)
+		routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: tc.mode}, false, nil)
 		assert.NoError(t, err)
 		err = routeClient.Initialize(nodeConfig, func() {})
 		assert.NoError(t, err)
@@ -435,7 +479,7 @@ func TestRouteTablePolicyOnly(t *testing.T) {
 	gwLink := createDummyGW(t)
 	defer netlink.LinkDel(gwLink)
 
-	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeNetworkPolicyOnly}, false)
+	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeNetworkPolicyOnly}, false, nil)
 	assert.NoError(t, err)
 	err = routeClient.Initialize(nodeConfig, func() {})
 	assert.NoError(t, err)
@@ -494,7 +538,7 @@ func TestIPv6RoutesAndNeighbors(t *testing.T) {
 	gwLink := createDummyGW(t)
 	defer netlink.LinkDel(gwLink)
 
-	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false)
+	routeClient, err := route.NewClient(serviceCIDR, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false, nil)
 	assert.Nil(t, err)
 	_, ipv6Subnet, _ := net.ParseCIDR("fd74:ca9b:172:19::/64")
 	gwIPv6 := net.ParseIP("fd74:ca9b:172:19::1")
@@ -555,6 +555,59 @@ func TestBundleWithGroupAndFlow(t *testing.T) {
 	CheckGroupExists(t, ovsCtlClient, groupID, "select", expectedGroupBuckets, false)
 }
 
+// Verify that an entry which cannot be realized aborts the whole Bundle, and that none of the other entries is installed.
+func TestBundleAbortedOnFailure(t *testing.T) {
+	br := "br12"
+	err := PrepareOVSBridge(br)
+	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
+	defer DeleteOVSBridge(br)
+
+	bridge := newOFBridge(br)
+	table = bridge.CreateTable(2, 3, binding.TableMissActionNext)
+
+	err = bridge.Connect(maxRetry, make(chan struct{}))
+	require.Nil(t, err, "Failed to start OFService")
+	defer bridge.Disconnect()
+
+	ovsCtlClient := ovsctl.NewClient(br)
+
+	flow := table.BuildFlow(priorityNormal).
+		Cookie(getCookieID()).
+		MatchProtocol(binding.ProtocolTCP).
+		MatchDstIP(net.ParseIP("10.96.0.10")).
+		Action().GotoTable(table.GetNext()).Done()
+	expectedFlows := []*ExpectFlow{
+		{
+			MatchStr: "priority=200,tcp,nw_dst=10.96.0.10",
+			ActStr:   "goto_table:3",
+		},
+	}
+
+	// The message of the Group cannot be built, as the Group already exists with another type, so the Bundle is
+	// cancelled before being committed.
+	groupID := binding.GroupIDType(5)
+	bridge.CreateGroup(groupID)
+	group := bridge.CreateGroupTypeAll(groupID).Bucket().ResubmitToTable(table.GetNext()).Done()
+	err = bridge.AddOFEntriesInBundle([]binding.OFEntry{flow, group}, nil, nil)
+	require.NotNil(t, err)
+	CheckFlowExists(t, ovsCtlClient, uint8(table.GetID()), false, expectedFlows)
+
+	// The Flow referring to a missing Group is rejected by OVS when the Bundle is committed.
+	invalidFlow := table.BuildFlow(priorityNormal).
+		Cookie(getCookieID()).
+		MatchProtocol(binding.ProtocolUDP).
+		MatchDstIP(net.ParseIP("10.96.0.10")).
+		Action().Group(binding.GroupIDType(6)).Done()
+	err = bridge.AddOFEntriesInBundle([]binding.OFEntry{flow, invalidFlow}, nil, nil)
+	require.NotNil(t, err)
+	CheckFlowExists(t, ovsCtlClient, uint8(table.GetID()), false, expectedFlows)
+
+	// The valid Flow can be installed once the failing entry is removed from the Bundle.
+	err = bridge.AddOFEntriesInBundle([]binding.OFEntry{flow}, nil, nil)
+	require.Nil(t, err)
+	CheckFlowExists(t, ovsCtlClient, uint8(table.GetID()), true, expectedFlows)
+}
+
 func TestPacketOutIn(t *testing.T) {
 	br := "br09"
 	err := PrepareOVSBridge(br)
@@ -19,7 +19,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -260,6 +262,81 @@ func TestTunnelOptionCsum(t *testing.T) {
 	}
 }
 
+// TestPortQoS checks the QoS and queues set to a port in OVSDB, and the linux-htb qdisc and classes configured by OVS
+// for them on the interface of the port.
+func TestPortQoS(t *testing.T) {
+	data := &testData{}
+	data.setup(t)
+	defer data.teardown(t)
+
+	name := "qos0"
+	err := exec.Command("ip", "link", "add", name, "type", "veth", "peer", "name", name+"-peer").Run()
+	require.Nil(t, err, "Failed to create veth pair")
+	defer exec.Command("ip", "link", "del", name).Run()
+	_, err = data.br.CreatePort(name, name, nil)
+	require.Nil(t, err, "Failed to create port")
+
+	vsctl := func(args ...string) string {
+		out, err := exec.Command("ovs-vsctl", append([]string{"--bare"}, args...)...).Output()
+		require.Nil(t, err, "Failed to run ovs-vsctl %v", args)
+		return strings.TrimSpace(string(out))
+	}
+	// The qdisc and classes are configured by ovs-vswitchd after the OVSDB transaction.
+	checkTC := func(object string, expected []string, unexpected []string) {
+		var out string
+		for retry := 0; retry < 5; retry++ {
+			output, err := exec.Command("tc", object, "show", "dev", name).Output()
+			require.Nil(t, err, "Failed to show the tc %s of %s", object, name)
+			out = string(output)
+			found := true
+			for _, s := range expected {
+				found = found && strings.Contains(out, s)
+			}
+			for _, s := range unexpected {
+				found = found && !strings.Contains(out, s)
+			}
+			if found {
+				return
+			}
+			time.Sleep(time.Second)
+		}
+		t.Errorf("Unexpected tc %s of %s, expected %v and not %v:\n%s", object, name, expected, unexpected, out)
+	}
+
+	queues := map[uint32]ovsconfig.QueueConfig{
+		0: {MaxRate: 1000000000},
+		1: {MinRate: 100000000, MaxRate: 500000000},
+	}
+	err = data.br.SetPortQoS(name, 1000000000, queues)
+	require.Nil(t, err, "Failed to set port QoS")
+	qosUUID := vsctl("get", "Port", name, "qos")
+	require.NotEmpty(t, qosUUID)
+	assert.Equal(t, "linux-htb", vsctl("get", "QoS", qosUUID, "type"))
+	assert.Equal(t, `"1000000000"`, vsctl("get", "QoS", qosUUID, "other_config:max-rate"))
+	queueUUID := vsctl("get", "QoS", qosUUID, "queues:1")
+	assert.Equal(t, `"100000000"`, vsctl("get", "Queue", queueUUID, "other_config:min-rate"))
+	assert.Equal(t, `"500000000"`, vsctl("get", "Queue", queueUUID, "other_config:max-rate"))
+	assert.Len(t, strings.Fields(vsctl("--columns=_uuid", "list", "Queue")), 2)
+	// OVS uses class 1:(N+1) for queue N, under the root class 1:fffe.
+	checkTC("qdisc", []string{"qdisc htb 1: root"}, nil)
+	checkTC("class", []string{"class htb 1:1 parent 1:fffe", "class htb 1:2 parent 1:fffe", "rate 100Mbit ceil 500Mbit"}, nil)
+
+	// The queues of the previous QoS are replaced.
+	delete(queues, 1)
+	err = data.br.SetPortQoS(name, 1000000000, queues)
+	require.Nil(t, err, "Failed to set port QoS")
+	assert.Len(t, strings.Fields(vsctl("--columns=_uuid", "list", "QoS")), 1)
+	assert.Len(t, strings.Fields(vsctl("--columns=_uuid", "list", "Queue")), 1)
+	checkTC("class", []string{"class htb 1:1 parent 1:fffe"}, []string{"class htb 1:2 "})
+
+	err = data.br.DeletePortQoS(name)
+	require.Nil(t, err, "Failed to delete port QoS")
+	assert.Empty(t, vsctl("get", "Port", name, "qos"))
+	assert.Empty(t, vsctl("--columns=_uuid", "list", "QoS"))
+	assert.Empty(t, vsctl("--columns=_uuid", "list", "Queue"))
+	checkTC("qdisc", nil, []string{"qdisc htb"})
+}
+
 func deleteAllPorts(t *testing.T, br *ovsconfig.OVSBridge) {
 	portList, err := br.GetPortUUIDList()
 	require.Nil(t, err, "Error when retrieving port list")
@@ -0,0 +1,229 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
+)
+
+// Verdict is the fate of a packet replayed through the OVS pipeline.
+type Verdict string
+
+const (
+	// VerdictOutput means that the packet is output to one or more OpenFlow ports.
+	VerdictOutput Verdict = "Output"
+	// VerdictController means that the packet is only sent to the OpenFlow controller, i.e. antrea-agent.
+	VerdictController Verdict = "Controller"
+	// VerdictDrop means that the packet is dropped.
+	VerdictDrop Verdict = "Drop"
+)
+
+var (
+	// outputActionRegex matches the output actions with a literal port in the trace, e.g. "output:11".
+	outputActionRegex = regexp.MustCompile(`(?m)^\s+output:(\d+)\s*$`)
+	// outputPortRegex matches the port of the output actions with a register in the trace, e.g. "output:NXM_NX_REG1[]"
+	// is followed by "-> output port is 11".
+	outputPortRegex = regexp.MustCompile(`(?m)-> output port is (\d+)`)
+	// controllerActionRegex matches the actions sending the packet to the OpenFlow controller in the trace.
+	controllerActionRegex = regexp.MustCompile(`(?m)^\s+(controller|CONTROLLER)[:(]`)
+)
+
+// ReplayPacket is a packet replayed through the OVS pipeline. It is described either by a synthetic flow or by the
+// raw bytes of an Ethernet frame, e.g. read from a pcap file.
+type ReplayPacket struct {
+	// InPort is the OpenFlow port, number or name, on which the packet is received.
+	InPort string
+	// Flow describes the packet in the flow syntax of ovs-ofctl(8), e.g. "tcp,nw_src=10.0.0.1,nw_dst=10.0.0.2,tp_dst=80".
+	// The fields which are not specified get a default value. It is ignored if Data is set.
+	Flow string
+	// Data is the raw Ethernet frame of the packet.
+	Data []byte
+}
+
+func (p *ReplayPacket) String() string {
+	if p.Data != nil {
+		return fmt.Sprintf("in_port=%s,packet=%s", p.InPort, hex.EncodeToString(p.Data))
+	}
+	return fmt.Sprintf("in_port=%s,%s", p.InPort, p.Flow)
+}
+
+// ReplayResult is the result of replaying a packet through the OVS pipeline.
+type ReplayResult struct {
+	Verdict Verdict
+	// OutPorts are the OpenFlow ports to which the packet is output, in increasing order.
+	OutPorts []uint32
+	// Trace is the full output of "ovs-appctl ofproto/trace", to troubleshoot unexpected results.
+	Trace string
+}
+
+// Replay replays the packet through the flows installed in the OVS bridge of the client, using
+// "ovs-appctl ofproto/trace", and returns its verdict. The packet is not actually sent, so the ports of the bridge
+// do not need to be connected to anything.
+func Replay(ovsCtlClient ovsctl.OVSCtlClient, packet *ReplayPacket) (*ReplayResult, error) {
+	args := []string{"in_port=" + packet.InPort}
+	if packet.Data != nil {
+		// The headers of the packet are parsed from its bytes, and the metadata from the flow.
+		args = append(args, hex.EncodeToString(packet.Data))
+	} else if packet.Flow != "" {
+		args[0] += "," + packet.Flow
+	}
+	out, execErr := ovsCtlClient.RunAppctlCmd("ofproto/trace", true, args...)
+	if execErr != nil {
+		return nil, fmt.Errorf("error tracing packet %s: %v, output: %s", packet, execErr, execErr.GetErrorOutput())
+	}
+	return parseTrace(string(bytes.ReplaceAll(out, []byte("\r"), []byte("")))), nil
+}
+
+// parseTrace gets the verdict of a packet from the output of "ovs-appctl ofproto/trace". The verdict is based on the
+// OpenFlow actions executed by the pipeline, rather than on the datapath actions, so that it does not depend on the
+// datapath port numbers.
+func parseTrace(trace string) *ReplayResult {
+	result := &ReplayResult{Verdict: VerdictDrop, Trace: trace}
+	ports := map[uint32]bool{}
+	for _, regex := range []*regexp.Regexp{outputActionRegex, outputPortRegex} {
+		for _, match := range regex.FindAllStringSubmatch(trace, -1) {
+			port, _ := strconv.ParseUint(match[1], 10, 32)
+			ports[uint32(port)] = true
+		}
+	}
+	for port := range ports {
+		result.OutPorts = append(result.OutPorts, port)
+	}
+	sort.Slice(result.OutPorts, func(i, j int) bool {
+		return result.OutPorts[i] < result.OutPorts[j]
+	})
+	if len(result.OutPorts) > 0 {
+		result.Verdict = VerdictOutput
+	} else if controllerActionRegex.MatchString(trace) {
+		result.Verdict = VerdictController
+	}
+	return result
+}
+
+// CheckReplay replays the packet through the OVS pipeline and checks that it gets the expected verdict and, if the
+// expected verdict is VerdictOutput, that it is output to the expected ports.
+func CheckReplay(t *testing.T, ovsCtlClient ovsctl.OVSCtlClient, packet *ReplayPacket, verdict Verdict, outPorts ...uint32) {
+	result, err := Replay(ovsCtlClient, packet)
+	if err != nil {
+		t.Errorf("Failed to replay packet: %v", err)
+		return
+	}
+	if result.Verdict != verdict {
+		t.Errorf("Unexpected verdict for packet %s: expected %s, got %s\nTrace:\n%s", packet, verdict, result.Verdict, result.Trace)
+		return
+	}
+	if verdict != VerdictOutput {
+		return
+	}
+	sorted := append([]uint32(nil), outPorts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	if fmt.Sprint(sorted) != fmt.Sprint(result.OutPorts) {
+		t.Errorf("Unexpected output ports for packet %s: expected %v, got %v\nTrace:\n%s", packet, sorted, result.OutPorts, result.Trace)
+	}
+}
+
+// ReplayPcapFile replays all the packets of a pcap file through the OVS pipeline, as if they were received on the
+// provided port, and returns their results in the same order.
+func ReplayPcapFile(ovsCtlClient ovsctl.OVSCtlClient, inPort, path string) ([]*ReplayResult, error) {
+	frames, err := ReadPcapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*ReplayResult, 0, len(frames))
+	for _, frame := range frames {
+		result, err := Replay(ovsCtlClient, &ReplayPacket{InPort: inPort, Data: frame})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReadPcapFile reads the Ethernet frames of a pcap file, e.g. captured with "tcpdump -w". Only the classic pcap
+// format is supported, not pcapng.
+func ReadPcapFile(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readPcap(f)
+}
+
+const (
+	pcapMagic         = 0xa1b2c3d4
+	pcapMagicNanosec  = 0xa1b23c4d
+	pcapLinkTypeEther = 1
+)
+
+func readPcap(r io.Reader) ([][]byte, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error reading pcap header: %v", err)
+	}
+	var byteOrder binary.ByteOrder
+	switch magic := binary.LittleEndian.Uint32(header[0:4]); magic {
+	case pcapMagic, pcapMagicNanosec:
+		byteOrder = binary.LittleEndian
+	default:
+		if magic = binary.BigEndian.Uint32(header[0:4]); magic != pcapMagic && magic != pcapMagicNanosec {
+			return nil, fmt.Errorf("unsupported pcap magic number %#x", magic)
+		}
+		byteOrder = binary.BigEndian
+	}
+	if linkType := byteOrder.Uint32(header[20:24]); linkType != pcapLinkTypeEther {
+		return nil, fmt.Errorf("unsupported pcap link type %d, only Ethernet is supported", linkType)
+	}
+	var frames [][]byte
+	recordHeader := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, recordHeader); err == io.EOF {
+			return frames, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading pcap record header: %v", err)
+		}
+		frame := make([]byte, byteOrder.Uint32(recordHeader[8:12]))
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return nil, fmt.Errorf("error reading pcap record: %v", err)
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// AddOVSInternalPort adds an internal port with the provided OpenFlow port number to the bridge, so that packets can
+// be replayed as if they were received on it.
+func AddOVSInternalPort(brName, portName string, ofPort uint32) error {
+	cmdStr := fmt.Sprintf("ovs-vsctl --may-exist add-port %s %s -- set Interface %s type=internal ofport_request=%d", brName, portName, portName, ofPort)
+	if out, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput(); err != nil {
+		return fmt.Errorf("error adding port %s: %v, output: %s", portName, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
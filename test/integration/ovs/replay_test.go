@@ -0,0 +1,118 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrace(t *testing.T) {
+	tests := []struct {
+		name             string
+		trace            string
+		expectedVerdict  Verdict
+		expectedOutPorts []uint32
+	}{
+		{
+			name: "output with register",
+			trace: `Flow: ip,in_port=2,vlan_tci=0x0000,dl_src=aa:aa:aa:aa:aa:11,dl_dst=aa:aa:aa:aa:aa:13,nw_src=10.10.10.1,nw_dst=192.168.1.3
+
+bridge("br01")
+--------------
+ 0. ip,in_port=2, priority 200, cookie 0x1000000000000
+    load:0x1->NXM_NX_REG0[0..3]
+    goto_table:10
+80. dl_dst=aa:aa:aa:aa:aa:13, priority 200, cookie 0x1030000000000
+    load:0xb->NXM_NX_REG1[]
+    load:0x1->NXM_NX_REG0[16]
+    goto_table:105
+110. ip,reg0=0x10000/0x10000, priority 200, cookie 0x1000000000000
+    output:NXM_NX_REG1[]
+     -> output port is 11
+
+Final flow: unchanged
+Datapath actions: 4`,
+			expectedVerdict:  VerdictOutput,
+			expectedOutPorts: []uint32{11},
+		},
+		{
+			name: "flood to literal ports",
+			trace: ` 0. arp, priority 190
+    output:2
+    output:1
+
+Datapath actions: 3,1`,
+			expectedVerdict:  VerdictOutput,
+			expectedOutPorts: []uint32{1, 2},
+		},
+		{
+			name: "controller",
+			trace: ` 100. ip, priority 0
+    controller(reason=no_match,userdata=01.01)
+
+Datapath actions: userspace(pid=0,controller(reason=1))`,
+			expectedVerdict: VerdictController,
+		},
+		{
+			name: "drop",
+			trace: ` 10. in_port=11, priority 190
+    drop
+
+Final flow: unchanged
+Datapath actions: drop`,
+			expectedVerdict: VerdictDrop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseTrace(tt.trace)
+			assert.Equal(t, tt.expectedVerdict, result.Verdict)
+			assert.Equal(t, tt.expectedOutPorts, result.OutPorts)
+		})
+	}
+}
+
+func newPcap(byteOrder binary.ByteOrder, linkType uint32, frames ...[]byte) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, byteOrder, []uint32{pcapMagic, 0x00040002, 0, 0, 65535, linkType})
+	for _, frame := range frames {
+		binary.Write(buf, byteOrder, []uint32{0, 0, uint32(len(frame)), uint32(len(frame))})
+		buf.Write(frame)
+	}
+	return buf.Bytes()
+}
+
+func TestReadPcap(t *testing.T) {
+	frame1 := []byte{0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0x13, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0x11, 0x08, 0x00}
+	frame2 := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0x11, 0x08, 0x06}
+	for _, byteOrder := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		frames, err := readPcap(bytes.NewReader(newPcap(byteOrder, pcapLinkTypeEther, frame1, frame2)))
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{frame1, frame2}, frames)
+	}
+
+	_, err := readPcap(bytes.NewReader(newPcap(binary.LittleEndian, 113, frame1)))
+	assert.Error(t, err, "Linux cooked captures should not be supported")
+	_, err = readPcap(bytes.NewReader([]byte{0x0a, 0x0d, 0x0d, 0x0a}))
+	assert.Error(t, err, "Truncated or pcapng files should not be supported")
+	truncated := newPcap(binary.LittleEndian, pcapLinkTypeEther, frame1)
+	_, err = readPcap(bytes.NewReader(truncated[:len(truncated)-1]))
+	assert.Error(t, err)
+}